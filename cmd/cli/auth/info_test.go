@@ -12,6 +12,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/lib/concurrency"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi/apimodels"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi/client/v2"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,6 +30,10 @@ func (m *mockAPI) Auth() *client.Auth {
 	return &client.Auth{}
 }
 
+func (m *mockAPI) Blobs() *client.Blobs {
+	return &client.Blobs{}
+}
+
 func (m *mockAPI) Jobs() *client.Jobs {
 	return &client.Jobs{}
 }
@@ -76,6 +81,10 @@ func (m *mockClient) Dial(ctx context.Context, path string, req apimodels.Reques
 	return nil, nil
 }
 
+func (m *mockClient) DialConn(ctx context.Context, path string, req apimodels.Request) (*websocket.Conn, error) {
+	return nil, nil
+}
+
 // TestInfo_NoSSOSupport tests when the server doesn't support any auth methods
 func TestInfo_NoSSOSupport(t *testing.T) {
 	// Create a mock client that returns an error
@@ -15,5 +15,6 @@ func NewCmd() *cobra.Command {
 	}
 	configCmd.AddCommand(newSetCmd())
 	configCmd.AddCommand(newListCmd())
+	configCmd.AddCommand(newSecretCmd())
 	return configCmd
 }
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bacalhau-project/bacalhau/cmd/util"
+	"github.com/bacalhau-project/bacalhau/cmd/util/hook"
+	"github.com/bacalhau-project/bacalhau/cmd/util/templates"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/env"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+var secretExample = templates.Examples(`
+# Store a secret that jobs on this compute node can reference as secret:file/db-password
+echo -n "hunter2" | bacalhau config secret set db-password
+
+# Store a secret on the orchestrator that jobs can reference as secret:orchestrator/api-token
+bacalhau config secret set api-token --orchestrator < token.txt
+
+# List the names of the stored secrets
+bacalhau config secret list
+`)
+
+type secretOptions struct {
+	orchestrator bool
+}
+
+func newSecretCmd() *cobra.Command {
+	o := &secretOptions{}
+	secretCmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Manage the encrypted secret store of the local node.",
+		Long: `Manage the encrypted secret store of the local node.
+Secrets stored on a compute node are referenced from task environment variables, webhook publisher headers,
+and the headers and tokens of URL and git inputs as secret:file/<name>, and require Compute.Env.Secrets.File.Enabled.
+Secrets stored on an orchestrator are referenced as secret:orchestrator/<name> and are delivered
+to compute nodes sealed to their keys. They require Orchestrator.Secrets.Enabled.`,
+		Example:  secretExample,
+		PreRunE:  hook.ClientPreRunHooks,
+		PostRunE: hook.ClientPostRunHooks,
+	}
+	secretCmd.PersistentFlags().BoolVar(&o.orchestrator, "orchestrator", false,
+		"Manage the orchestrator's secret store instead of the compute node's")
+
+	secretCmd.AddCommand(&cobra.Command{
+		Use:          "set <name>",
+		Short:        "Store a secret read from stdin.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := o.store(cmd)
+			if err != nil {
+				return err
+			}
+			value, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return fmt.Errorf("failed to read secret from stdin: %w", err)
+			}
+			return store.Put(args[0], strings.TrimRight(string(value), "\r\n"))
+		},
+	})
+
+	secretCmd.AddCommand(&cobra.Command{
+		Use:          "delete <name>",
+		Short:        "Delete a secret.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := o.store(cmd)
+			if err != nil {
+				return err
+			}
+			return store.Delete(args[0])
+		},
+	})
+
+	secretCmd.AddCommand(&cobra.Command{
+		Use:          "list",
+		Short:        "List the names of the stored secrets.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := o.store(cmd)
+			if err != nil {
+				return err
+			}
+			names, err := store.List()
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				cmd.Println(name)
+			}
+			return nil
+		},
+	})
+	return secretCmd
+}
+
+// store opens the secret store of the local compute node or orchestrator
+func (o *secretOptions) store(cmd *cobra.Command) (*env.FileSecretStore, error) {
+	cfg, err := util.SetupConfig(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup config: %w", err)
+	}
+
+	params := env.FileSecretStoreParams{}
+	if o.orchestrator {
+		params.Name = models.SecretBackendOrchestrator
+		params.Path, params.KeyFile, err = cfg.OrchestratorSecretsPaths()
+	} else {
+		params.Path, params.KeyFile, err = cfg.ComputeSecretsPaths()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return env.NewFileSecretStore(params)
+}
@@ -2,6 +2,8 @@ package docker
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -51,6 +53,13 @@ var (
 type DockerRunOptions struct {
 	Entrypoint       []string
 	WorkingDirectory string
+	User             string
+	ReadOnly         bool
+	ShmSize          string
+	Tmpfs            []string
+	Ulimits          []string
+	CapAdd           []string
+	CapDrop          []string
 
 	JobSettings     *cliflags.JobSettings
 	TaskSettings    *cliflags.TaskSettings
@@ -111,6 +120,20 @@ func newDockerRunCmd() *cobra.Command {
 		`Working directory inside the container. Overrides the working directory shipped with the image (e.g. via WORKDIR in Dockerfile).`)
 	dockerFlags.StringSliceVar(&opts.Entrypoint, "entrypoint", opts.Entrypoint,
 		`Override the default ENTRYPOINT of the image`)
+	dockerFlags.StringVarP(&opts.User, "user", "u", opts.User,
+		`User the container process runs as, in the form user[:group] (e.g. 1000:1000)`)
+	dockerFlags.BoolVar(&opts.ReadOnly, "read-only", opts.ReadOnly,
+		`Mount the container's root filesystem as read only`)
+	dockerFlags.StringVar(&opts.ShmSize, "shm-size", opts.ShmSize,
+		`Size of /dev/shm (e.g. 1Gi)`)
+	dockerFlags.StringArrayVar(&opts.Tmpfs, "tmpfs", opts.Tmpfs,
+		`Mount a tmpfs directory, in the form path[:size] (e.g. /scratch:512Mi)`)
+	dockerFlags.StringArrayVar(&opts.Ulimits, "ulimit", opts.Ulimits,
+		`Ulimit of the container process, in the form name=soft[:hard] where -1 is unlimited (e.g. nofile=1024:4096)`)
+	dockerFlags.StringSliceVar(&opts.CapAdd, "cap-add", opts.CapAdd,
+		`Add Linux capabilities (e.g. SYS_PTRACE)`)
+	dockerFlags.StringSliceVar(&opts.CapDrop, "cap-drop", opts.CapDrop,
+		`Drop Linux capabilities (e.g. ALL)`)
 
 	dockerRunCmd.Flags().AddFlagSet(dockerFlags)
 
@@ -140,6 +163,10 @@ func run(cmd *cobra.Command, args []string, cfg types.Bacalhau, opts *DockerRunO
 		return fmt.Errorf("failed to create api client: %w", err)
 	}
 
+	if err = util.UploadLocalInputs(ctx, api, job); err != nil {
+		return err
+	}
+
 	resp, err := api.Jobs().Put(ctx, &apimodels.PutJobRequest{Job: job})
 	if err != nil {
 		return bacerrors.Wrap(err, "failed to submit job")
@@ -162,10 +189,20 @@ func run(cmd *cobra.Command, args []string, cfg types.Bacalhau, opts *DockerRunO
 func build(args []string, opts *DockerRunOptions) (*models.Job, error) {
 	image := args[0]
 	parameters := args[1:]
+	ulimits, err := parseUlimits(opts.Ulimits)
+	if err != nil {
+		return nil, err
+	}
 	engineSpec, err := engine_docker.NewDockerEngineBuilder(image).
 		WithParameters(parameters...).
 		WithWorkingDirectory(opts.WorkingDirectory).
 		WithEntrypoint(opts.Entrypoint...).
+		WithUser(opts.User).
+		WithReadOnlyRootfs(opts.ReadOnly).
+		WithShmSize(opts.ShmSize).
+		WithTmpfs(parseTmpfs(opts.Tmpfs)...).
+		WithUlimits(ulimits...).
+		WithCapabilities(opts.CapAdd, opts.CapDrop).
 		Build()
 	if err != nil {
 		return nil, err
@@ -173,3 +210,37 @@ func build(args []string, opts *DockerRunOptions) (*models.Job, error) {
 
 	return helpers.BuildJobFromFlags(engineSpec, opts.JobSettings, opts.TaskSettings)
 }
+
+// parseTmpfs parses tmpfs mounts in the form path[:size]
+func parseTmpfs(values []string) []engine_docker.TmpfsMount {
+	mounts := make([]engine_docker.TmpfsMount, 0, len(values))
+	for _, value := range values {
+		target, size, _ := strings.Cut(value, ":")
+		mounts = append(mounts, engine_docker.TmpfsMount{Target: target, Size: size})
+	}
+	return mounts
+}
+
+// parseUlimits parses ulimits in the form name=soft[:hard]. The hard limit defaults to the soft limit.
+func parseUlimits(values []string) ([]engine_docker.Ulimit, error) {
+	ulimits := make([]engine_docker.Ulimit, 0, len(values))
+	for _, value := range values {
+		name, limits, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid ulimit %q: expected name=soft[:hard]", value)
+		}
+		softValue, hardValue, hasHard := strings.Cut(limits, ":")
+		soft, err := strconv.ParseInt(softValue, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ulimit %q: soft limit must be a number", value)
+		}
+		hard := soft
+		if hasHard {
+			if hard, err = strconv.ParseInt(hardValue, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid ulimit %q: hard limit must be a number", value)
+			}
+		}
+		ulimits = append(ulimits, engine_docker.Ulimit{Name: name, Soft: soft, Hard: hard})
+	}
+	return ulimits, nil
+}
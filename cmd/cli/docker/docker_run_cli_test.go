@@ -101,6 +101,34 @@ func TestJobFlagParsing(t *testing.T) {
 			},
 			expectedError: false,
 		},
+		{
+			name: "with container options",
+			flags: []string{"image:tag", "--user=1000:1000", "--read-only", "--shm-size=1Gi",
+				"--tmpfs=/scratch:512Mi", "--tmpfs=/cache", "--ulimit=nofile=1024:4096", "--ulimit=memlock=-1",
+				"--cap-add=SYS_PTRACE", "--cap-drop=ALL"},
+			assertJob: func(t *testing.T, j *models.Job) {
+				defaultJobAssertions(t, j)
+				ds, err := dm.DecodeSpec(j.Task().Engine)
+				require.NoError(t, err)
+
+				assert.Equal(t, "1000:1000", ds.User)
+				assert.True(t, ds.ReadOnlyRootfs)
+				assert.Equal(t, "1Gi", ds.ShmSize)
+				assert.Equal(t, []dm.TmpfsMount{{Target: "/scratch", Size: "512Mi"}, {Target: "/cache"}}, ds.Tmpfs)
+				assert.Equal(t, []dm.Ulimit{
+					{Name: "nofile", Soft: 1024, Hard: 4096},
+					{Name: "memlock", Soft: -1, Hard: -1},
+				}, ds.Ulimits)
+				assert.Equal(t, []string{"SYS_PTRACE"}, ds.CapAdd)
+				assert.Equal(t, []string{"ALL"}, ds.CapDrop)
+			},
+			expectedError: false,
+		},
+		{
+			name:          "with invalid ulimit",
+			flags:         []string{"image:tag", "--ulimit=nofile"},
+			expectedError: true,
+		},
 		{
 			name:          "with invalid working dir",
 			flags:         []string{"image:tag", "--workdir=dir"},
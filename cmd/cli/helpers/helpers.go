@@ -39,7 +39,9 @@ func BuildJobFromFlags(
 			TotalTimeout: taskSettings.Timeout,
 			QueueTimeout: taskSettings.QueueTimeout,
 		},
-		Env: models.EnvVarsFromStringsMap(taskSettings.EnvironmentVariables),
+		Env:         models.EnvVarsFromStringsMap(taskSettings.EnvironmentVariables),
+		StopSignal:  taskSettings.StopSignal,
+		KillTimeout: taskSettings.KillTimeout,
 	}
 
 	// only set networking if it's not the default so we don't assume the user explicitly wants it,
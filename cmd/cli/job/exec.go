@@ -0,0 +1,235 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/bacalhau-project/bacalhau/cmd/util"
+	"github.com/bacalhau-project/bacalhau/cmd/util/templates"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/apimodels"
+	clientv2 "github.com/bacalhau-project/bacalhau/pkg/publicapi/client/v2"
+)
+
+// execStdinBufferSize is the maximum size of a single frame of input sent to a session
+const execStdinBufferSize = 32 * 1024
+
+var (
+	execShort = `Run a command inside a running execution of a job`
+
+	execLong = templates.LongDesc(`
+		Run a command inside a running execution of a job, or attach to the output of its main process.
+
+		The job must have a running execution on a compute node whose executor supports interactive
+		sessions, such as Docker. If the job has more than one running execution, select one with --execution-id.
+		Requires the exec:job capability when authorization is enabled.
+`)
+
+	execExample = templates.Examples(`
+		# Open an interactive shell inside a running service job
+		bacalhau job exec j-51225160-807e-48b8-88c9-28311c7899e1 -it -- sh
+
+		# Run a single command inside a specific execution
+		bacalhau job exec my-service --execution-id e-1d3b3c4f -- ls -la /outputs
+
+		# Follow the output of the main process of the job
+		bacalhau job exec my-service --attach
+`)
+)
+
+type ExecOptions struct {
+	ExecutionID string
+	Namespace   string
+	Stdin       bool
+	TTY         bool
+	Attach      bool
+}
+
+func NewExecCmd() *cobra.Command {
+	o := &ExecOptions{}
+
+	execCmd := &cobra.Command{
+		Use:           "exec [id] -- [command] [args...]",
+		Short:         execShort,
+		Long:          execLong,
+		Example:       execExample,
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// initialize a new or open an existing repo merging any config file(s) it contains into cfg.
+			cfg, err := util.SetupRepoConfig(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to setup repo: %w", err)
+			}
+			// create an api client
+			api, err := util.NewAPIClientManager(cmd, cfg).GetAuthenticatedAPIClient()
+			if err != nil {
+				return fmt.Errorf("failed to create api client: %w", err)
+			}
+			return o.run(cmd, args, api)
+		},
+	}
+
+	execCmd.Flags().StringVarP(&o.ExecutionID, "execution-id", "e", "",
+		"The execution to open the session into. Required if the job has more than one running execution.")
+	execCmd.Flags().StringVar(&o.Namespace, "namespace", o.Namespace,
+		`Job Namespace. If not provided, default namespace will be used.`)
+	execCmd.Flags().BoolVarP(&o.Stdin, "stdin", "i", false,
+		"Pass the standard input to the command.")
+	execCmd.Flags().BoolVarP(&o.TTY, "tty", "t", false,
+		"Allocate a terminal for the command.")
+	execCmd.Flags().BoolVar(&o.Attach, "attach", false,
+		"Attach to the output of the main process of the execution instead of running a command.")
+	return execCmd
+}
+
+func (o *ExecOptions) run(cmd *cobra.Command, args []string, api clientv2.API) error {
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	jobIDOrName := args[0]
+	command := args[1:]
+	if o.Attach && len(command) > 0 {
+		return fmt.Errorf("a command cannot be used with --attach")
+	}
+	if !o.Attach && len(command) == 0 {
+		return fmt.Errorf("a command is required, e.g. bacalhau job exec %s -- sh", jobIDOrName)
+	}
+
+	executionID, err := o.resolveExecution(ctx, api, jobIDOrName)
+	if err != nil {
+		return err
+	}
+
+	request := &apimodels.ExecRequest{
+		JobID:       jobIDOrName,
+		ExecutionID: executionID,
+		Command:     command,
+		Attach:      o.Attach,
+		TTY:         o.TTY,
+		Stdin:       o.Stdin && !o.Attach,
+	}
+	request.Namespace = o.Namespace
+
+	// put the local terminal in raw mode so keystrokes are sent to the remote terminal as typed
+	stdinFd := int(os.Stdin.Fd())
+	stdoutFd := int(os.Stdout.Fd())
+	if o.TTY && term.IsTerminal(stdoutFd) {
+		if width, height, sizeErr := term.GetSize(stdoutFd); sizeErr == nil {
+			request.Width, request.Height = uint(width), uint(height) //nolint:gosec // terminal sizes are positive
+		}
+	}
+	if o.TTY && request.Stdin && term.IsTerminal(stdinFd) {
+		state, rawErr := term.MakeRaw(stdinFd)
+		if rawErr != nil {
+			return fmt.Errorf("failed to set terminal to raw mode: %w", rawErr)
+		}
+		defer func() { _ = term.Restore(stdinFd, state) }()
+	}
+
+	session, err := api.Jobs().Exec(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to open session into job %s: %w", jobIDOrName, err)
+	}
+	defer func() { _ = session.Close() }()
+
+	if request.Stdin {
+		go forwardStdin(ctx, cmd.InOrStdin(), session)
+	}
+	if o.TTY && term.IsTerminal(stdoutFd) {
+		notifyResize(ctx, func() {
+			if width, height, sizeErr := term.GetSize(stdoutFd); sizeErr == nil {
+				_ = session.Send(models.ExecInput{
+					Width:  uint(width),  //nolint:gosec // terminal sizes are positive
+					Height: uint(height), //nolint:gosec // terminal sizes are positive
+				})
+			}
+		})
+	}
+
+	for result := range session.Output() {
+		if result.Err != nil {
+			return result.Err
+		}
+		switch result.Value.Type {
+		case models.ExecOutputTypeSTDOUT:
+			_, err = cmd.OutOrStdout().Write(result.Value.Data)
+		case models.ExecOutputTypeSTDERR:
+			_, err = cmd.ErrOrStderr().Write(result.Value.Data)
+		case models.ExecOutputTypeExit:
+			if result.Value.ExitCode > 0 {
+				return fmt.Errorf("command terminated with exit code %d", result.Value.ExitCode)
+			}
+			return nil
+		default:
+			// session started or unknown frames carry no output
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveExecution returns the requested execution, or the only running execution of the job
+func (o *ExecOptions) resolveExecution(ctx context.Context, api clientv2.API, jobIDOrName string) (string, error) {
+	if o.ExecutionID != "" {
+		return o.ExecutionID, nil
+	}
+	request := &apimodels.ListJobExecutionsRequest{
+		JobIDOrName: jobIDOrName,
+		BaseVersionedListRequest: apimodels.BaseVersionedListRequest{
+			AllJobVersions: true,
+		},
+	}
+	request.Namespace = o.Namespace
+	response, err := api.Jobs().Executions(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("failed to list executions of job %s: %w", jobIDOrName, err)
+	}
+
+	var running []string
+	for _, execution := range response.Items {
+		if execution.ComputeState.StateType == models.ExecutionStateRunning {
+			running = append(running, execution.ID)
+		}
+	}
+	switch len(running) {
+	case 0:
+		return "", fmt.Errorf("job %s has no running executions", jobIDOrName)
+	case 1:
+		return running[0], nil
+	default:
+		return "", fmt.Errorf("job %s has %d running executions, select one with --execution-id: %s",
+			jobIDOrName, len(running), strings.Join(running, ", "))
+	}
+}
+
+// forwardStdin sends the local standard input to the session until it is exhausted
+func forwardStdin(ctx context.Context, stdin io.Reader, session *clientv2.ExecSession) {
+	buf := make([]byte, execStdinBufferSize)
+	for ctx.Err() == nil {
+		n, err := stdin.Read(buf)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			if sendErr := session.Send(models.ExecInput{Data: data}); sendErr != nil {
+				return
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				_ = session.Send(models.ExecInput{CloseStdin: true})
+			}
+			return
+		}
+	}
+}
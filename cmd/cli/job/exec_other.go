@@ -0,0 +1,8 @@
+//go:build !unix
+
+package job
+
+import "context"
+
+// notifyResize is a no-op on platforms without terminal resize signals
+func notifyResize(ctx context.Context, onResize func()) {}
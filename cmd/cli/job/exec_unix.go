@@ -0,0 +1,27 @@
+//go:build unix
+
+package job
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize calls onResize whenever the local terminal is resized, until the context is done
+func notifyResize(ctx context.Context, onResize func()) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGWINCH)
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				onResize()
+			}
+		}
+	}()
+}
@@ -12,12 +12,16 @@ import (
 	"github.com/bacalhau-project/bacalhau/cmd/util/flags/cliflags"
 	"github.com/bacalhau-project/bacalhau/cmd/util/hook"
 	"github.com/bacalhau-project/bacalhau/pkg/config/types"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/apimodels"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi/client/v2"
 )
 
 var (
 	getLong = templates.LongDesc(`
 		Get the results of the job, including stdout and stderr.
+
+		Results are verified against their manifest, which lists the checksums of the result files
+		and is signed by the compute node that produced them.
 `)
 
 	getExample = templates.Examples(`
@@ -29,11 +33,31 @@ var (
 
 		# Get the results of a job, with a short ID.
 		bacalhau job get ebd9bf2f
+
+		# Get the results of a job published without a manifest, skipping verification.
+		bacalhau job get --verify=false ebd9bf2f
+
+		# List the files of the results of a job without downloading them.
+		bacalhau job get --list ebd9bf2f
+
+		# Get the results of a single execution, or of the executions of a partition.
+		bacalhau job get --execution e-0f2a3c8d ebd9bf2f
+		bacalhau job get --partition 0 --partition 3 ebd9bf2f
+
+		# Get only the CSV files of the outputs, excluding temporary files.
+		bacalhau job get --include 'outputs/**/*.csv' --exclude '**/tmp/**' ebd9bf2f
+
+		# Write a single file of the results to stdout.
+		bacalhau job get --stdout ebd9bf2f/outputs/result.csv > result.csv
 `)
 )
 
 type GetOptions struct {
 	Namespace        string
+	ExecutionIDs     []string
+	Partitions       []int
+	List             bool
+	Stdout           bool
 	DownloadSettings *cliflags.DownloaderSettings
 }
 
@@ -73,6 +97,18 @@ func NewGetCmd() *cobra.Command {
 	getCmd.PersistentFlags().StringVar(&OG.Namespace, "namespace", OG.Namespace,
 		`Job Namespace. If not provided, default namespace will be used.`,
 	)
+	getCmd.PersistentFlags().StringSliceVar(&OG.ExecutionIDs, "execution", OG.ExecutionIDs,
+		`Only get the results of the executions with these IDs or ID prefixes.`,
+	)
+	getCmd.PersistentFlags().IntSliceVar(&OG.Partitions, "partition", OG.Partitions,
+		`Only get the results of the executions of these partitions.`,
+	)
+	getCmd.PersistentFlags().BoolVar(&OG.List, "list", OG.List,
+		`List the files of the results without downloading them.`,
+	)
+	getCmd.PersistentFlags().BoolVar(&OG.Stdout, "stdout", OG.Stdout,
+		`Write a single file of the results, given as <job>/<path>, to stdout.`,
+	)
 	getCmd.PersistentFlags().AddFlagSet(cliflags.NewDownloadFlags(OG.DownloadSettings))
 
 	return getCmd
@@ -93,22 +129,33 @@ func get(cmd *cobra.Command, cmdArgs []string, api client.API, cfg types.Bacalha
 	// Split the jobIDOrName on / to see if the request is for a single file or for the
 	// entire jobid.
 	// TODO: Enforce certain syntax for JobName - only DNS compatible names should be allowed
+	var filePath string
 	parts := strings.SplitN(jobIDOrName, "/", 2)
 	if len(parts) == 2 {
-		jobIDOrName, OG.DownloadSettings.SingleFile = parts[0], parts[1]
+		jobIDOrName, filePath = parts[0], parts[1]
 	}
 
-	if err := util.DownloadResultsHandler(
-		ctx,
-		cmd,
-		cfg,
-		api,
-		jobIDOrName,
-		OG.Namespace,
-		OG.DownloadSettings,
-	); err != nil {
-		return err
+	if OG.DownloadSettings.Raw && (len(OG.DownloadSettings.Include) > 0 || len(OG.DownloadSettings.Exclude) > 0) {
+		return fmt.Errorf("--include and --exclude cannot be used with --raw")
 	}
 
-	return nil
+	request := &apimodels.ListJobResultsRequest{
+		JobID:        jobIDOrName,
+		ExecutionIDs: OG.ExecutionIDs,
+		Partitions:   OG.Partitions,
+	}
+	request.Namespace = OG.Namespace
+
+	switch {
+	case OG.List:
+		return util.ListResultsHandler(ctx, cmd, cfg, api, request)
+	case OG.Stdout:
+		if filePath == "" {
+			return fmt.Errorf("--stdout requires a file of the results, given as <job>/<path>")
+		}
+		return util.StreamResultFileHandler(ctx, cmd, cfg, api, request, filePath, OG.DownloadSettings)
+	default:
+		OG.DownloadSettings.SingleFile = filePath
+		return util.DownloadResultsHandler(ctx, cmd, cfg, api, request, OG.DownloadSettings)
+	}
 }
@@ -19,6 +19,7 @@ func NewCmd() *cobra.Command {
 	cliflags.RegisterProfileFlag(cmd)
 
 	cmd.AddCommand(NewDescribeCmd())
+	cmd.AddCommand(NewExecCmd())
 	cmd.AddCommand(NewExecutionCmd())
 	cmd.AddCommand(NewHistoryCmd())
 	cmd.AddCommand(NewVersionsCmd())
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/bacalhau-project/bacalhau/cmd/util/opts"
 	"github.com/bacalhau-project/bacalhau/cmd/util/output"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
 	"github.com/spf13/cobra"
@@ -35,7 +36,8 @@ var (
 		# Run a new job from an already executed job
 		bacalhau job describe 6e51df50 | bacalhau job run
 
-		# Download the
+		# Run a job with a local directory uploaded as an input
+		bacalhau job run ./job.yaml --input ./data:/inputs
 		`)
 )
 
@@ -46,6 +48,7 @@ type RunOptions struct {
 	Force                  bool
 	TemplateVars           map[string]string
 	TemplateEnvVarsPattern string
+	InputSources           opts.StorageSpecConfigOpt // Inputs added to the job's task, such as local directories to upload
 }
 
 func NewRunOptions() *RunOptions {
@@ -87,6 +90,9 @@ func NewRunCmd() *cobra.Command {
 	runCmd.Flags().BoolVar(&o.Force, "force", false, "force run even if job spec did not change")
 	runCmd.Flags().StringToStringVarP(&o.TemplateVars, "template-vars", "V", nil,
 		"Replace a placeholder in the job spec with a value. e.g. --template-vars foo=bar")
+	runCmd.Flags().VarP(&o.InputSources, "input", "i",
+		"Mount an input in the job's task, such as a local directory that is uploaded with the job. "+
+			"Can be specified multiple times. e.g. --input ./data:/inputs")
 	runCmd.Flags().StringVarP(&o.TemplateEnvVarsPattern, "template-envs", "E", "",
 		"Specify a regular expression pattern for selecting environment variables to be included as template variables in the job spec."+
 			"\ne.g. --template-envs \".*\" will include all environment variables.")
@@ -122,6 +128,13 @@ func (o *RunOptions) run(cmd *cobra.Command, args []string, api client.API) erro
 		return fmt.Errorf("%s: %w", userstrings.JobSpecBad, err)
 	}
 
+	if inputs := o.InputSources.Values(); len(inputs) > 0 {
+		if len(j.Tasks) != 1 {
+			return fmt.Errorf("%s: inputs can only be added to jobs with a single task", userstrings.JobSpecBad)
+		}
+		j.Task().InputSources = append(j.Task().InputSources, inputs...)
+	}
+
 	// Validate the job spec
 	err = j.ValidateSubmission()
 	if err != nil {
@@ -132,6 +145,10 @@ func (o *RunOptions) run(cmd *cobra.Command, args []string, api client.API) erro
 		return o.dryRun(cmd, j, api, ctx)
 	}
 
+	if err = util.UploadLocalInputs(ctx, api, j); err != nil {
+		return err
+	}
+
 	// Submit the job
 	resp, err := api.Jobs().Put(ctx, &apimodels.PutJobRequest{
 		Job:   j,
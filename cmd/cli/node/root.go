@@ -21,6 +21,7 @@ func NewCmd() *cobra.Command {
 
 	cmd.AddCommand(NewDescribeCmd())
 	cmd.AddCommand(NewListCmd())
+	cmd.AddCommand(NewVolumeCmd())
 
 	// Approve Action
 	cmd.AddCommand(NewActionCmd(apimodels.NodeActionApprove))
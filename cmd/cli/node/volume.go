@@ -0,0 +1,159 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/bacalhau-project/bacalhau/cmd/util"
+	"github.com/bacalhau-project/bacalhau/cmd/util/flags/cliflags"
+	"github.com/bacalhau-project/bacalhau/cmd/util/output"
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/apimodels"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/client/v2"
+	"github.com/bacalhau-project/bacalhau/pkg/util/idgen"
+)
+
+var volumeColumns = []output.TableColumn[models.VolumeInfo]{
+	{
+		ColumnConfig: table.ColumnConfig{Name: "job"},
+		Value:        func(v models.VolumeInfo) string { return idgen.ShortUUID(v.JobID) },
+	},
+	{
+		ColumnConfig: table.ColumnConfig{Name: "namespace"},
+		Value:        func(v models.VolumeInfo) string { return v.Namespace },
+	},
+	{
+		ColumnConfig: table.ColumnConfig{Name: "name"},
+		Value:        func(v models.VolumeInfo) string { return v.Name },
+	},
+	{
+		ColumnConfig: table.ColumnConfig{Name: "size"},
+		Value: func(v models.VolumeInfo) string {
+			if v.MaxSize == 0 {
+				return humanize.Bytes(v.Size)
+			}
+			return fmt.Sprintf("%s / %s", humanize.Bytes(v.Size), humanize.Bytes(v.MaxSize))
+		},
+	},
+	{
+		ColumnConfig: table.ColumnConfig{Name: "last used"},
+		Value:        func(v models.VolumeInfo) string { return humanize.Time(v.LastUsedTime) },
+	},
+}
+
+func NewVolumeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "volume",
+		Short: "Commands to list and delete the persistent volumes of jobs on compute nodes.",
+	}
+	cmd.AddCommand(NewVolumeListCmd())
+	cmd.AddCommand(NewVolumeDeleteCmd())
+	return cmd
+}
+
+// VolumeListOptions is a struct to support node volume list command
+type VolumeListOptions struct {
+	output.OutputOptions
+}
+
+// NewVolumeListOptions returns initialized Options
+func NewVolumeListOptions() *VolumeListOptions {
+	return &VolumeListOptions{
+		OutputOptions: output.OutputOptions{Format: output.TableFormat},
+	}
+}
+
+func NewVolumeListCmd() *cobra.Command {
+	o := NewVolumeListOptions()
+
+	cmd := &cobra.Command{
+		Use:           "list [node-id]",
+		Short:         "List the persistent volumes of jobs held by a compute node.",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// initialize a new or open an existing repo merging any config file(s) it contains into cfg.
+			cfg, err := util.SetupRepoConfig(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to setup repo: %w", err)
+			}
+			// create an api client
+			api, err := util.NewAPIClientManager(cmd, cfg).GetAuthenticatedAPIClient()
+			if err != nil {
+				return fmt.Errorf("failed to create api client: %w", err)
+			}
+			return o.run(cmd, args, api)
+		},
+	}
+
+	cmd.Flags().AddFlagSet(cliflags.OutputFormatFlags(&o.OutputOptions))
+	return cmd
+}
+
+func (o *VolumeListOptions) run(cmd *cobra.Command, args []string, api client.API) error {
+	ctx := cmd.Context()
+	nodeID := args[0]
+	response, err := api.Nodes().ListVolumes(ctx, &apimodels.ListNodeVolumesRequest{
+		NodeID: nodeID,
+	})
+	if err != nil {
+		return bacerrors.Wrapf(err, "failed to list volumes of node %s", nodeID)
+	}
+
+	if err = output.Output(cmd, volumeColumns, o.OutputOptions, response.Volumes); err != nil {
+		return fmt.Errorf("failed to output: %w", err)
+	}
+	return nil
+}
+
+func NewVolumeDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete [node-id] [job-id] [name]",
+		Short: "Delete the persistent volumes of a job on a compute node.",
+		Long: `Delete the persistent volumes of a job on a compute node, or only the volume with the given name.
+The node deletes the volumes on its next heartbeat, unless they are used by running executions of the job.
+The volumes of a job are deleted on all nodes when the job is stopped.`,
+		Args:          cobra.RangeArgs(2, 3),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// initialize a new or open an existing repo merging any config file(s) it contains into cfg.
+			cfg, err := util.SetupRepoConfig(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to setup repo: %w", err)
+			}
+			// create an api client
+			api, err := util.NewAPIClientManager(cmd, cfg).GetAuthenticatedAPIClient()
+			if err != nil {
+				return fmt.Errorf("failed to create api client: %w", err)
+			}
+			return runVolumeDelete(cmd, args, api)
+		},
+	}
+	return cmd
+}
+
+func runVolumeDelete(cmd *cobra.Command, args []string, api client.API) error {
+	ctx := cmd.Context()
+	request := &apimodels.DeleteNodeVolumesRequest{
+		NodeID: args[0],
+		JobID:  args[1],
+	}
+	if len(args) > 2 {
+		request.Name = args[2]
+	}
+
+	response, err := api.Nodes().DeleteVolumes(ctx, request)
+	if err != nil {
+		return bacerrors.Wrapf(err, "failed to delete volumes of job %s on node %s", request.JobID, request.NodeID)
+	}
+	for _, volume := range response.Volumes {
+		cmd.Printf("Requested deletion of volume %s of job %s\n", volume.Name, volume.JobID)
+	}
+	return nil
+}
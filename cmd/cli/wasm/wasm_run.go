@@ -38,6 +38,9 @@ var (
 		- Storage spec: s3://bucket/main.wasm:/app/custom.wasm
 
 		Import modules must be added via the --input flag and referenced by their target paths.
+
+		The entry module can also be a WebAssembly component using WASI 0.2, such as one built for the
+		wasm32-wasip2 target. Components can't use import modules.
 		`)
 
 	wasmRunExample = templates.Examples(`
@@ -64,6 +67,10 @@ type WasmRunOptions struct {
 	ImportModules []string
 	// The name of the WASM function to call in the entry module
 	Entrypoint string
+	// The budget of fuel the execution can consume. Fuel is not metered if zero.
+	Fuel uint64
+	// The scope of the keys the job can access in the key-value store
+	KeyValueScope string
 
 	JobSettings     *cliflags.JobSettings
 	TaskSettings    *cliflags.TaskSettings
@@ -126,7 +133,17 @@ func newRunCmd() *cobra.Command {
 	)
 	wasmFlags.StringVar(&opts.Entrypoint, "entry-point", opts.Entrypoint,
 		`The name of the WASM function in the entry module to call. This should be a zero-parameter zero-result function that
-		will execute the job.`,
+		will execute the job. For WebAssembly components, _start runs the run function of their wasi:cli/run export.`,
+	)
+
+	wasmFlags.Uint64Var(&opts.Fuel, "fuel", opts.Fuel,
+		`The budget of fuel the execution can consume, where entering a function and every iteration of a loop consume
+		one unit of fuel each. The execution fails once its budget is exhausted, deterministically on every node. Fuel is
+		not metered if zero.`,
+	)
+	wasmFlags.StringVar(&opts.KeyValueScope, "kv-scope", opts.KeyValueScope,
+		`The scope of the keys the job can access in the key-value store when networking is enabled. Either "job", to only
+		access the job's own keys, or "namespace", to share keys with all jobs of the namespace. Defaults to "job".`,
 	)
 
 	wasmRunCmd.Flags().AddFlagSet(wasmFlags)
@@ -156,6 +173,10 @@ func run(cmd *cobra.Command, args []string, cfg types.Bacalhau, opts *WasmRunOpt
 		return fmt.Errorf("failed to create api client: %w", err)
 	}
 
+	if err = util.UploadLocalInputs(ctx, api, job); err != nil {
+		return err
+	}
+
 	resp, err := api.Jobs().Put(ctx, &apimodels.PutJobRequest{Job: job})
 	if err != nil {
 		return fmt.Errorf("failed to submit job: %w", err)
@@ -178,9 +199,10 @@ func run(cmd *cobra.Command, args []string, cfg types.Bacalhau, opts *WasmRunOpt
 // parseWasmModule handles the entry module path and returns an InputSource if it's a local file or storage spec.
 // If it's a target path, it returns nil and the path should be treated as-is.
 func parseWasmModule(ctx context.Context, in string, defaultTarget string) (*models.InputSource, error) {
-	// Try interpreting this as a storage spec (http://, s3://, etc.)
+	// Try interpreting this as a storage spec (http://, s3://, etc.). Local paths are handled below,
+	// as they may also be paths of modules within other inputs.
 	spec, err := opts.ParseStorageSpec(in, defaultTarget)
-	if err == nil {
+	if err == nil && !opts.IsLocalUpload(spec) {
 		return spec, nil
 	}
 
@@ -264,6 +286,8 @@ func build(ctx context.Context, args []string, opts *WasmRunOptions) (*models.Jo
 		WithParameters(args[1:]...).
 		WithEntrypoint(opts.Entrypoint).
 		WithImportModules(importModulePaths).
+		WithFuel(opts.Fuel).
+		WithKeyValueScope(opts.KeyValueScope).
 		Build()
 	if err != nil {
 		return nil, err
@@ -79,6 +79,32 @@ func TestJobFlagParsing(t *testing.T) {
 			},
 			expectedError: false,
 		},
+		{
+			name:  "local module with fuel budget",
+			flags: []string{"--fuel", "1000", "../../../testdata/wasm/noop/main.wasm"},
+			assertJob: func(t *testing.T, j *models.Job) {
+				defaultJobAssertions(t, j)
+				task := j.Task()
+				defaultTaskAssertions(t, task)
+
+				assert.Equal(t, models.EngineWasm, task.Engine.Type)
+				assert.EqualValues(t, 1000, task.Engine.Params["Fuel"])
+			},
+			expectedError: false,
+		},
+		{
+			name:  "local module with namespace key-value scope",
+			flags: []string{"--kv-scope", "namespace", "../../../testdata/wasm/noop/main.wasm"},
+			assertJob: func(t *testing.T, j *models.Job) {
+				defaultJobAssertions(t, j)
+				task := j.Task()
+				defaultTaskAssertions(t, task)
+
+				assert.Equal(t, models.EngineWasm, task.Engine.Type)
+				assert.Equal(t, "namespace", task.Engine.Params["KeyValueScope"])
+			},
+			expectedError: false,
+		},
 		{
 			name:  "local module with custom target",
 			flags: []string{"../../../testdata/wasm/noop/main.wasm:/app/custom.wasm"},
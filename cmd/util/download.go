@@ -5,10 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"github.com/bacalhau-project/bacalhau/cmd/util/flags/cliflags"
 	"github.com/bacalhau-project/bacalhau/pkg/config/types"
@@ -23,31 +27,137 @@ func DownloadResultsHandler(
 	cmd *cobra.Command,
 	cfg types.Bacalhau,
 	apiV2 clientv2.API,
-	jobIDOrName string,
-	namespace string,
+	request *apimodels.ListJobResultsRequest,
 	downloadSettings *cliflags.DownloaderSettings,
 ) error {
-	cmd.PrintErrf("Fetching results of job '%s'...\n", jobIDOrName)
+	results, downloaderProvider, err := fetchResults(ctx, cmd, cfg, apiV2, request)
+	if err != nil || len(results.Items) == 0 {
+		return err
+	}
+
+	processedDownloadSettings, err := processDownloadSettings(
+		downloadSettings,
+		request.JobID,
+		request.Namespace,
+	)
+	if err != nil {
+		return err
+	}
 
-	request := &apimodels.ListJobResultsRequest{
-		JobID: jobIDOrName,
+	if err = downloadResults(ctx, cmd, apiV2, results, downloaderProvider, processedDownloadSettings); err != nil {
+		return err
+	}
+
+	cmd.Printf("Results for job '%s' have been written to...\n", request.JobID)
+	cmd.Printf("%s\n", processedDownloadSettings.OutputDir)
+
+	return nil
+}
+
+// StreamResultFileHandler writes a single file of the results of a job to stdout.
+// The results are downloaded to a temporary directory, limited to the file.
+func StreamResultFileHandler(
+	ctx context.Context,
+	cmd *cobra.Command,
+	cfg types.Bacalhau,
+	apiV2 clientv2.API,
+	request *apimodels.ListJobResultsRequest,
+	filePath string,
+	downloadSettings *cliflags.DownloaderSettings,
+) error {
+	results, downloaderProvider, err := fetchResults(ctx, cmd, cfg, apiV2, request)
+	if err != nil {
+		return err
+	}
+	if len(results.Items) == 0 {
+		return fmt.Errorf("no results found for job %s", request.JobID)
+	}
+
+	outputDir, err := os.MkdirTemp("", "bacalhau-get-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(outputDir); err != nil {
+			cmd.PrintErrf("failed to remove temporary directory %s: %s\n", outputDir, err)
+		}
+	}()
+
+	settings := *downloadSettings
+	settings.OutputDir = outputDir
+	settings.SingleFile = ""
+	settings.Include = []string{globEscaper.Replace(filePath)}
+	settings.Exclude = nil
+	if err = downloadResults(ctx, cmd, apiV2, results, downloaderProvider, &settings); err != nil {
+		return err
+	}
+
+	file, err := os.Open(filepath.Join(outputDir, filepath.FromSlash(filePath)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("file %s not found in the results of job %s", filePath, request.JobID)
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(cmd.OutOrStdout(), file)
+	return err
+}
+
+// ListResultsHandler prints the tree of the files of the results of a job without downloading them
+func ListResultsHandler(
+	ctx context.Context,
+	cmd *cobra.Command,
+	cfg types.Bacalhau,
+	apiV2 clientv2.API,
+	request *apimodels.ListJobResultsRequest,
+) error {
+	results, downloaderProvider, err := fetchResults(ctx, cmd, cfg, apiV2, request)
+	if err != nil || len(results.Items) == 0 {
+		return err
+	}
+	listings, err := downloader.ListResults(ctx, results.Items, downloaderProvider)
+	if err != nil {
+		return err
+	}
+	for i, listing := range listings {
+		if listing.ExecutionID != "" {
+			cmd.Printf("Execution %s on node %s\n", listing.ExecutionID, listing.NodeID)
+		} else {
+			cmd.Printf("Result %d\n", i+1)
+		}
+		printFileTree(cmd.OutOrStdout(), listing.Files)
 	}
-	request.Namespace = namespace
+	return nil
+}
+
+// fetchResults returns the published results of the job with the executions that published them,
+// and the downloaders to fetch them. It returns no results if the job has none, or if they cannot be downloaded.
+func fetchResults(
+	ctx context.Context,
+	cmd *cobra.Command,
+	cfg types.Bacalhau,
+	apiV2 clientv2.API,
+	request *apimodels.ListJobResultsRequest,
+) (*apimodels.ListJobResultsResponse, downloader.DownloaderProvider, error) {
+	cmd.PrintErrf("Fetching results of job '%s'...\n", request.JobID)
+
 	response, err := apiV2.Jobs().Results(ctx, request)
 	if err != nil {
-		return errors.New(err.Error())
+		return nil, nil, errors.New(err.Error())
 	}
+	noResults := &apimodels.ListJobResultsResponse{}
 
 	if len(response.Items) == 0 {
 		// No results doesn't mean error, so we should print out a message and return nil
 		cmd.Println("No results found")
 		cmd.Println("You can check the logged output of the job using the logs command.")
-		cmd.Printf("\n  bacalhau job logs %s\n", jobIDOrName)
-		return nil
+		cmd.Printf("\n  bacalhau job logs %s\n", request.JobID)
+		return noResults, nil, nil
 	}
 	downloaderProvider, err := util.NewStandardDownloaders(ctx, cfg.ResultDownloaders)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// check if we don't support downloading the results
@@ -57,38 +167,58 @@ func DownloadResultsHandler(
 				"No supported downloader found for the published results. You will have to download the results differently.")
 			b, err := json.MarshalIndent(response.Items, "", "    ")
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 			cmd.PrintErrln(string(b))
-			return nil
+			return noResults, nil, nil
 		}
 	}
+	return response, downloaderProvider, nil
+}
 
-	processedDownloadSettings, err := processDownloadSettings(
-		downloadSettings,
-		jobIDOrName,
-		namespace,
-	)
-	if err != nil {
-		return err
+// downloadResults downloads the results, verifying them against the keys of the nodes that
+// published them, and displaying the progress of downloads if stderr is a terminal
+func downloadResults(
+	ctx context.Context,
+	cmd *cobra.Command,
+	apiV2 clientv2.API,
+	results *apimodels.ListJobResultsResponse,
+	downloaderProvider downloader.DownloaderProvider,
+	settings *cliflags.DownloaderSettings,
+) error {
+	if settings.Verify && settings.NodeKeys == nil {
+		settings.NodeKeys = nodeKeyResolver(apiV2)
 	}
-
-	err = downloader.DownloadResults(
+	if settings.Progress == nil {
+		if out, ok := cmd.ErrOrStderr().(*os.File); ok && term.IsTerminal(int(out.Fd())) { //nolint:gosec // G115: fds fit in int
+			progress := newDownloadProgress(out)
+			defer progress.done()
+			settings.Progress = progress.report
+		}
+	}
+	return downloader.DownloadResults(
 		ctx,
-		response.Items,
+		results.Items,
+		results.Origins,
 		downloaderProvider,
-		(*downloader.DownloaderSettings)(processedDownloadSettings),
+		(*downloader.DownloaderSettings)(settings),
 	)
+}
 
-	if err != nil {
-		return err
+// nodeKeyResolver returns the keys advertised by compute nodes, which must have signed the manifests of their results
+func nodeKeyResolver(apiV2 clientv2.API) downloader.NodeKeyResolver {
+	return func(ctx context.Context, nodeID string) (string, error) {
+		response, err := apiV2.Nodes().Get(ctx, &apimodels.GetNodeRequest{NodeID: nodeID})
+		if err != nil {
+			return "", err
+		}
+		if response.Node == nil || response.Node.Info.ComputeNodeInfo.PublicKey == "" {
+			return "", fmt.Errorf("node %s does not advertise a public key", nodeID)
+		}
+		return response.Node.Info.ComputeNodeInfo.PublicKey, nil
 	}
-
-	cmd.Printf("Results for job '%s' have been written to...\n", jobIDOrName)
-	cmd.Printf("%s\n", processedDownloadSettings.OutputDir)
-
-	return nil
 }
+
 func processDownloadSettings(
 	settings *cliflags.DownloaderSettings,
 	jobIDOrName string,
@@ -106,6 +236,9 @@ func processDownloadSettings(
 
 const AutoDownloadFolderPerm = 0755
 
+// globEscaper escapes the glob meta characters of paths, so that they match themselves as patterns
+var globEscaper = strings.NewReplacer("\\", "\\\\", "*", "\\*", "?", "\\?", "[", "\\[", "{", "\\{")
+
 // if the user does not supply a value for "download results to here"
 // then we default to making a folder in the current directory
 func ensureDefaultDownloadLocation(jobIDOrName, namespace string) (string, error) {
@@ -0,0 +1,101 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/manifest"
+)
+
+// progressInterval is the minimum interval between updates of the download progress
+const progressInterval = 200 * time.Millisecond
+
+// downloadProgress displays the progress of concurrent downloads on a single line
+type downloadProgress struct {
+	mu         sync.Mutex
+	out        io.Writer
+	files      map[string]fileProgress
+	lastUpdate time.Time
+}
+
+type fileProgress struct {
+	downloaded int64
+	total      int64
+}
+
+func newDownloadProgress(out io.Writer) *downloadProgress {
+	return &downloadProgress{out: out, files: make(map[string]fileProgress)}
+}
+
+// report records the progress of a file, and refreshes the display if it was not recently refreshed
+func (p *downloadProgress) report(name string, downloaded, total int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files[name] = fileProgress{downloaded: downloaded, total: total}
+	if time.Since(p.lastUpdate) < progressInterval && downloaded != total {
+		return
+	}
+	p.lastUpdate = time.Now()
+
+	var downloadedBytes, totalBytes uint64
+	totalKnown := true
+	for _, file := range p.files {
+		downloadedBytes += uint64(file.downloaded) //nolint:gosec // G115: sizes are never negative
+		if file.total < 0 {
+			totalKnown = false
+		}
+		totalBytes += uint64(max(file.total, 0))
+	}
+	if totalKnown && totalBytes > 0 {
+		_, _ = fmt.Fprintf(p.out, "\rDownloading %d results: %s / %s (%d%%)\033[K",
+			len(p.files), humanize.Bytes(downloadedBytes), humanize.Bytes(totalBytes), downloadedBytes*100/totalBytes) //nolint:mnd
+	} else {
+		_, _ = fmt.Fprintf(p.out, "\rDownloading %d results: %s\033[K", len(p.files), humanize.Bytes(downloadedBytes))
+	}
+}
+
+// done ends the progress line, if any progress was displayed
+func (p *downloadProgress) done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.lastUpdate.IsZero() {
+		_, _ = fmt.Fprintln(p.out)
+	}
+}
+
+// printFileTree prints the files as a tree of directories, with their sizes if known
+func printFileTree(out io.Writer, files []manifest.File) {
+	files = slices.SortedFunc(slices.Values(files), func(a, b manifest.File) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	var printedDirs []string
+	for _, file := range files {
+		parts := strings.Split(file.Path, "/")
+		// print the directories of the file that were not printed with previous files
+		for depth := range len(parts) - 1 {
+			if depth < len(printedDirs) && printedDirs[depth] == parts[depth] {
+				continue
+			}
+			printedDirs = append(printedDirs[:depth], parts[depth])
+			_, _ = fmt.Fprintf(out, "%s%s/\n", treeIndent(depth), parts[depth])
+		}
+		printedDirs = printedDirs[:len(parts)-1]
+
+		depth := len(parts) - 1
+		if file.Size < 0 {
+			_, _ = fmt.Fprintf(out, "%s%s\n", treeIndent(depth), parts[depth])
+		} else {
+			_, _ = fmt.Fprintf(out, "%s%s (%s)\n", treeIndent(depth), parts[depth], humanize.Bytes(uint64(file.Size)))
+		}
+	}
+}
+
+func treeIndent(depth int) string {
+	return strings.Repeat("  ", depth+1)
+}
@@ -0,0 +1,31 @@
+//go:build unit || !integration
+
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/manifest"
+)
+
+func TestPrintFileTree(t *testing.T) {
+	var out bytes.Buffer
+	printFileTree(&out, []manifest.File{
+		{Path: "stdout", Size: 5},
+		{Path: "outputs/data/b.csv", Size: 2048},
+		{Path: "outputs/data/a.csv", Size: 10},
+		{Path: "outputs/readme", Size: -1},
+		{Path: "exitCode", Size: 1},
+	})
+	require.Equal(t, `  exitCode (1 B)
+  outputs/
+    data/
+      a.csv (10 B)
+      b.csv (2.0 kB)
+    readme
+  stdout (5 B)
+`, out.String())
+}
@@ -11,16 +11,24 @@ func NewDefaultDownloaderSettings() *DownloaderSettings {
 	return &DownloaderSettings{
 		Timeout: downloader.DefaultDownloadTimeout,
 		// we leave this blank so the CLI will auto-create a job folder in pwd
-		SingleFile: "",
-		OutputDir:  "",
+		SingleFile:  "",
+		OutputDir:   "",
+		Verify:      true,
+		Parallelism: downloader.DefaultDownloadParallelism,
 	}
 }
 
 type DownloaderSettings struct {
-	Timeout    time.Duration
-	OutputDir  string
-	SingleFile string
-	Raw        bool
+	Timeout     time.Duration
+	OutputDir   string
+	SingleFile  string
+	Raw         bool
+	Verify      bool
+	NodeKeys    downloader.NodeKeyResolver
+	Include     []string
+	Exclude     []string
+	Parallelism int
+	Progress    downloader.ProgressFunc
 }
 
 func NewDownloadFlags(settings *DownloaderSettings) *pflag.FlagSet {
@@ -31,5 +39,13 @@ func NewDownloadFlags(settings *DownloaderSettings) *pflag.FlagSet {
 		settings.Timeout, "Timeout duration for IPFS downloads.")
 	flags.StringVar(&settings.OutputDir, "output-dir",
 		settings.OutputDir, "Directory to write the output to.")
+	flags.BoolVar(&settings.Verify, "verify",
+		settings.Verify, "Verify the signed manifest of the results. Set to false to download results without a manifest.")
+	flags.StringSliceVar(&settings.Include, "include",
+		settings.Include, "Only write the result files whose paths match any of the glob patterns, e.g. 'outputs/**/*.csv'.")
+	flags.StringSliceVar(&settings.Exclude, "exclude",
+		settings.Exclude, "Do not write the result files whose paths match any of the glob patterns.")
+	flags.IntVar(&settings.Parallelism, "parallel",
+		settings.Parallelism, "Number of results downloaded in parallel.")
 	return flags
 }
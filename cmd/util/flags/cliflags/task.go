@@ -14,7 +14,7 @@ import (
 const (
 	NameUsageMsg = `The name to refer to this task by`
 
-	PublisherInputUsageMsg = `Mount URIs as inputs to the job. Can be specified multiple times. Format: src=URI,dst=PATH[,opt=key=value]
+	PublisherInputUsageMsg = `Mount URIs as inputs to the job. Can be specified multiple times. Format: src=URI,dst=PATH[,opt=key=value][,overlay=true|overlay-publish=NAME]
 Examples:
 # Mount IPFS CID to /inputs directory
 -i ipfs://QmeZRGhe4PmjctYVSVHuEiA9oSXnqmYa4kQubSHgWbjv72
@@ -22,6 +22,22 @@ Examples:
 -i s3://bucket/key,dst=/my/input/path
 # Mount S3 object with specific endpoint and region
 -i src=s3://bucket/key,dst=/my/input/path,opt=endpoint=https://s3.example.com,opt=region=us-east-1
+# Download and extract an archive from a URL, falling back to a mirror, after verifying its checksum
+-i "src=https://example.com/data.tgz,dst=/my/input/path,opt=sha256=<hex>,opt=mirrors=https://mirror.example.com/data.tgz,opt=extract=true,opt=extractedSize=1GB"
+# Download a file with an authorization header read from a secret
+-i src=https://example.com/data.csv,dst=/my/input/path,opt=header.Authorization=secret:file/api-token
+# Mount an OCI artifact by tag or digest
+-i src=oci://ghcr.io/my-org/dataset:v1,dst=/my/input/path
+# Mount a git repository checked out at a branch, with a token read from a secret
+-i src=git+https://github.com/my-org/repo.git,dst=/my/input/path,opt=ref=main,opt=depth=1,opt=token=secret:file/git-token
+# Mount the results of each partition of a completed job in /my/input/path/partition-<index>
+-i src=job://j-7e3f9d2c,dst=/my/input/path,opt=executions=all
+# Upload a local directory with the job and mount it to /inputs
+-i ./data:/inputs
+# Mount an allow-listed directory of the compute node writable, with the changes kept private to the execution
+-i src=file:///data/dataset,dst=/inputs,overlay=true
+# Mount an input writable and publish the files the job changed as the "changes" result
+-i src=file:///data/dataset,dst=/inputs,overlay-publish=changes
 `
 
 	ResultPathUsageMsg = "name:path of the output data volumes"
@@ -47,6 +63,8 @@ type TaskSettings struct {
 	Network              NetworkSettings
 	Timeout              int64
 	QueueTimeout         int64
+	StopSignal           string
+	KillTimeout          int64
 }
 
 type ResourceSettings struct {
@@ -105,6 +123,14 @@ func RegisterTaskFlags(cmd *cobra.Command, s *TaskSettings) {
 	fs.Int64Var(&s.QueueTimeout, "queue-timeout", s.QueueTimeout,
 		`Job queue timeout in seconds (e.g. 300 for 5 minutes). 
 zero timeout means no queueing is enabled and jobs will fail if they cannot be scheduled immediately`,
+	)
+	fs.StringVar(&s.StopSignal, "stop-signal", s.StopSignal,
+		`Signal sent to the job to ask it to terminate gracefully when it is stopped (e.g. SIGINT). Defaults to SIGTERM. 
+Docker jobs only`,
+	)
+	fs.Int64Var(&s.KillTimeout, "kill-timeout", s.KillTimeout,
+		`Grace period in seconds the job has to terminate after the stop signal before it is killed (e.g. 30). 
+zero means the job is killed immediately. Docker jobs only`,
 	)
 	cmd.Flags().AddFlagSet(fs)
 }
@@ -13,6 +13,9 @@ import (
 	publisher_local "github.com/bacalhau-project/bacalhau/pkg/publisher/local"
 )
 
+// webhookHeaderOptionPrefix is the prefix of webhook publisher options setting request headers
+const webhookHeaderOptionPrefix = "header."
+
 // compile-time check to ensure type implements the flag.Value interface
 var _ flag.Value = &PublisherSpecConfigOpt{}
 
@@ -124,9 +127,66 @@ func publisherStringToSpecConfig(destinationURI string, options map[string]strin
 		}, nil
 	case "local":
 		res = publisher_local.NewSpecConfig()
+	case "oci":
+		return ociSpecConfig(destinationURI, options), nil
+	case "webhook", "webhook+http", "webhook+https":
+		return webhookSpecConfig(destinationURI, parsedURI, options), nil
 	default:
 		return nil, fmt.Errorf("unknown publisher type: %s", parsedURI.Scheme)
 	}
 
 	return res, nil
 }
+
+// ociSpecConfig parses oci publisher options, where the repository and optional tag
+// are given as oci://registry/repository[:tag] or with the repository and tag options
+func ociSpecConfig(destinationURI string, options map[string]string) *models.SpecConfig {
+	params := map[string]interface{}{}
+	for k, v := range options {
+		params[k] = v
+	}
+
+	// parse the repository and tag from URI if not provided in options
+	if repository := strings.TrimPrefix(destinationURI, "oci://"); repository != "oci" {
+		if _, ok := params["repository"]; !ok {
+			// a colon after the last slash separates the tag, while others belong to the registry port
+			if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+				if _, ok := params["tag"]; !ok {
+					params["tag"] = repository[i+1:]
+				}
+				repository = repository[:i]
+			}
+			params["repository"] = repository
+		}
+	}
+	return &models.SpecConfig{
+		Type:   models.PublisherOCI,
+		Params: params,
+	}
+}
+
+// webhookSpecConfig parses webhook publisher options, where the upload URL is given as
+// webhook+https://host/path or with the url option, and headers with header.<name> options
+func webhookSpecConfig(destinationURI string, parsedURI *url.URL, options map[string]string) *models.SpecConfig {
+	params := map[string]interface{}{}
+	headers := map[string]string{}
+	for k, v := range options {
+		if name, ok := strings.CutPrefix(k, webhookHeaderOptionPrefix); ok {
+			headers[name] = v
+			continue
+		}
+		params[k] = v
+	}
+	if len(headers) > 0 {
+		params["headers"] = headers
+	}
+
+	// parse the url from URI if not provided in options
+	if _, ok := params["url"]; !ok && parsedURI.Host != "" {
+		params["url"] = strings.TrimPrefix(destinationURI, "webhook+")
+	}
+	return &models.SpecConfig{
+		Type:   models.PublisherWebhook,
+		Params: params,
+	}
+}
@@ -74,6 +74,52 @@ func TestParsePublisherSpecConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "webhook",
+			input: "webhook+https://example.com/results/{jobID},opt=encoding=plain,opt=chunkSize=8MiB",
+			expected: &models.SpecConfig{
+				Type: models.PublisherWebhook,
+				Params: map[string]interface{}{
+					"url":       "https://example.com/results/{jobID}",
+					"encoding":  "plain",
+					"chunkSize": "8MiB",
+				},
+			},
+		},
+		{
+			name:  "webhook with headers",
+			input: "webhook,opt=url=http://127.0.0.1:8080/upload,opt=header.Authorization=secret:vault/kv/data/app#token",
+			expected: &models.SpecConfig{
+				Type: models.PublisherWebhook,
+				Params: map[string]interface{}{
+					"url": "http://127.0.0.1:8080/upload",
+					"headers": map[string]string{
+						"Authorization": "secret:vault/kv/data/app#token",
+					},
+				},
+			},
+		},
+		{
+			name:  "oci",
+			input: "oci://localhost:5000/results:{jobID}",
+			expected: &models.SpecConfig{
+				Type: models.PublisherOCI,
+				Params: map[string]interface{}{
+					"repository": "localhost:5000/results",
+					"tag":        "{jobID}",
+				},
+			},
+		},
+		{
+			name:  "oci without tag",
+			input: "oci://ghcr.io/my-org/results",
+			expected: &models.SpecConfig{
+				Type: models.PublisherOCI,
+				Params: map[string]interface{}{
+					"repository": "ghcr.io/my-org/results",
+				},
+			},
+		},
 		{
 			name:  "empty",
 			input: "",
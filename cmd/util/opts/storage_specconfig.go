@@ -8,15 +8,26 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/dustin/go-humanize"
 	flag "github.com/spf13/pflag"
 
 	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
+	storage_git "github.com/bacalhau-project/bacalhau/pkg/storage/git"
 	storage_ipfs "github.com/bacalhau-project/bacalhau/pkg/storage/ipfs"
+	storage_job "github.com/bacalhau-project/bacalhau/pkg/storage/job"
 	storage_local "github.com/bacalhau-project/bacalhau/pkg/storage/local"
 	storage_s3 "github.com/bacalhau-project/bacalhau/pkg/storage/s3"
 	storage_url "github.com/bacalhau-project/bacalhau/pkg/storage/url/urldownload"
 )
 
+const (
+	// StorageSourceLocalUpload is the type of the placeholder of local inputs, such as ./data:/inputs.
+	// It is only used by the CLI, which replaces it with the storage the input is uploaded to.
+	StorageSourceLocalUpload = "localUpload"
+	localUploadPathParam     = "Path"
+)
+
 // compile-time check to ensure type implements the flag.Value interface
 var _ flag.Value = &StorageSpecConfigOpt{}
 
@@ -41,6 +52,7 @@ func ParseStorageSpec(value string, defaultDestination string) (*models.InputSou
 	var sourceURI string
 	destination := defaultDestination
 	options := make(map[string]string)
+	var overlay *models.InputOverlay
 
 	for i, field := range fields {
 		key, val, ok := strings.Cut(field, "=")
@@ -52,14 +64,20 @@ func ParseStorageSpec(value string, defaultDestination string) (*models.InputSou
 				if err != nil {
 					return nil, err
 				}
-				// find the last colon, excluding the schema part
+				// find the last colon, excluding the schema part. Local paths have no schema.
 				schema := parsedURI.Scheme
-				trimmedURI := strings.TrimPrefix(field, schema+"://")
+				trimmedURI := field
+				if schema != "" {
+					trimmedURI = strings.TrimPrefix(field, schema+"://")
+				}
 				index := strings.LastIndex(trimmedURI, ":")
 				if index == -1 {
 					sourceURI = field
 				} else {
-					sourceURI = schema + "://" + trimmedURI[:index]
+					sourceURI = trimmedURI[:index]
+					if schema != "" {
+						sourceURI = schema + "://" + sourceURI
+					}
 					destination = trimmedURI[index+1:]
 				}
 				continue
@@ -79,12 +97,29 @@ func ParseStorageSpec(value string, defaultDestination string) (*models.InputSou
 			if k != "" {
 				options[k] = v
 			}
+		case "overlay":
+			enabled, parseErr := strconv.ParseBool(val)
+			if parseErr != nil {
+				return nil, fmt.Errorf("failed to parse overlay option: %s", parseErr)
+			}
+			if enabled && overlay == nil {
+				overlay = &models.InputOverlay{}
+			} else if !enabled {
+				overlay = nil
+			}
+		case "overlay-publish", "overlay_publish":
+			overlay = &models.InputOverlay{Publish: val}
 		default:
 			return nil, fmt.Errorf("unexpected key %s in field %s", key, field)
 		}
 	}
 	alias := sourceURI
-	return storageStringToSpecConfig(sourceURI, destination, alias, options)
+	input, err := storageStringToSpecConfig(sourceURI, destination, alias, options)
+	if err != nil {
+		return nil, err
+	}
+	input.Overlay = overlay
+	return input, nil
 }
 
 func (o *StorageSpecConfigOpt) Set(value string) error {
@@ -130,7 +165,7 @@ func storageStringToSpecConfig(sourceURI, destinationPath, alias string, options
 			return nil, err
 		}
 	case "http", "https":
-		sc, err = storage_url.NewSpecConfig(sourceURI)
+		sc, err = httpSpecConfig(sourceURI, parsedURI, options)
 		if err != nil {
 			return nil, err
 		}
@@ -180,7 +215,34 @@ func storageStringToSpecConfig(sourceURI, destinationPath, alias string, options
 		if err != nil {
 			return nil, err
 		}
-	case "git", "gitlfs":
+	case "oci":
+		if len(options) > 0 {
+			return nil, fmt.Errorf("storage %s does not support options", parsedURI.Scheme)
+		}
+		sc, err = oci.NewSourceSpecConfig(strings.TrimPrefix(sourceURI, "oci://"))
+		if err != nil {
+			return nil, err
+		}
+	case "git", "git+http", "git+https", "git+ssh", "git+file":
+		sc, err = gitSpecConfig(sourceURI, parsedURI, options)
+		if err != nil {
+			return nil, err
+		}
+	case "job":
+		sc, err = jobSpecConfig(parsedURI, options)
+		if err != nil {
+			return nil, err
+		}
+	case "":
+		// local files and directories are uploaded by the client before the job is submitted
+		if len(options) > 0 {
+			return nil, fmt.Errorf("local input %s does not support options", sourceURI)
+		}
+		sc, err = localUploadSpecConfig(sourceURI)
+		if err != nil {
+			return nil, err
+		}
+	case "gitlfs":
 		return nil, fmt.Errorf("unsupported type: %s", parsedURI.Scheme)
 	default:
 		return nil, fmt.Errorf("unknown storage schema: %s", parsedURI.Scheme)
@@ -192,3 +254,111 @@ func storageStringToSpecConfig(sourceURI, destinationPath, alias string, options
 		Target: destinationPath,
 	}, nil
 }
+
+// localUploadSpecConfig returns the placeholder of a local file or directory to upload, such as ./data
+func localUploadSpecConfig(path string) (*models.SpecConfig, error) {
+	if path == "" {
+		return nil, fmt.Errorf("storage source cannot be empty")
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SpecConfig{
+		Type:   StorageSourceLocalUpload,
+		Params: map[string]interface{}{localUploadPathParam: absPath},
+	}, nil
+}
+
+// IsLocalUpload returns true if the input is a local file or directory that must be uploaded
+// before the job is submitted
+func IsLocalUpload(input *models.InputSource) bool {
+	return input != nil && input.Source != nil && input.Source.IsType(StorageSourceLocalUpload)
+}
+
+// LocalUploadPath returns the absolute path of a local input to upload
+func LocalUploadPath(input *models.InputSource) string {
+	path, _ := input.Source.Params[localUploadPathParam].(string)
+	return path
+}
+
+// httpSpecConfig parses http and https sources, with the sha256, mirrors, extract, extractedSize
+// and header.<name> options
+func httpSpecConfig(sourceURI string, parsedURI *url.URL, options map[string]string) (*models.SpecConfig, error) {
+	source := storage_url.Source{URL: sourceURI}
+	for key, value := range options {
+		switch {
+		case key == "sha256":
+			source.SHA256 = value
+		case key == "mirrors", key == "mirror":
+			source.Mirrors = strings.Split(value, ",")
+		case key == "extract":
+			extract, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse extract option: %s", err)
+			}
+			source.Extract = extract
+		case key == "extractedSize":
+			size, err := humanize.ParseBytes(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse extractedSize option: %s", err)
+			}
+			source.ExtractedSize = size
+		case strings.HasPrefix(key, "header."):
+			if source.Headers == nil {
+				source.Headers = make(map[string]string)
+			}
+			source.Headers[strings.TrimPrefix(key, "header.")] = value
+		default:
+			return nil, fmt.Errorf("unknown option %q for storage %s", key, parsedURI.Scheme)
+		}
+	}
+	return storage_url.NewSpecConfigFromSource(source)
+}
+
+// gitSpecConfig parses git repository sources given as git+https://host/repo.git, git+ssh://host/repo.git
+// or git://host/repo.git, with the ref, commit, depth, sparse, token and sshKey options
+func gitSpecConfig(sourceURI string, parsedURI *url.URL, options map[string]string) (*models.SpecConfig, error) {
+	source := storage_git.SourceSpec{URL: strings.TrimPrefix(sourceURI, "git+")}
+	for key, value := range options {
+		switch key {
+		case "ref", "branch", "tag":
+			source.Ref = value
+		case "commit":
+			source.Commit = value
+		case "depth":
+			depth, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse depth option: %s", err)
+			}
+			source.Depth = depth
+		case "sparse":
+			source.SparsePaths = strings.Split(value, ",")
+		case "token":
+			source.Token = value
+		case "sshKey", "ssh-key", "ssh_key":
+			source.SSHKey = value
+		default:
+			return nil, fmt.Errorf("unknown option %q for storage %s", key, parsedURI.Scheme)
+		}
+	}
+	return storage_git.NewSpecConfig(source)
+}
+
+// jobSpecConfig parses inputs referencing the results of other jobs given as job://<job-id>/<path>,
+// with the executions option selecting the executions whose results are used
+func jobSpecConfig(parsedURI *url.URL, options map[string]string) (*models.SpecConfig, error) {
+	source := storage_job.SourceSpec{
+		JobID: parsedURI.Host,
+		Path:  strings.TrimPrefix(parsedURI.Path, "/"),
+	}
+	for key, value := range options {
+		switch key {
+		case "executions", "execution", "selector":
+			source.ExecutionSelector = value
+		default:
+			return nil, fmt.Errorf("unknown option %q for storage %s", key, parsedURI.Scheme)
+		}
+	}
+	return storage_job.NewSpecConfig(source)
+}
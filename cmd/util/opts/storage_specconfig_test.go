@@ -3,10 +3,12 @@
 package opts
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/bacalhau-project/bacalhau/pkg/models"
+	storage_local "github.com/bacalhau-project/bacalhau/pkg/storage/local"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -177,6 +179,159 @@ func TestParseInputSourceSpecConfig(t *testing.T) {
 				Target: "/mount/path",
 			},
 		},
+		{
+			name: "http with options",
+			input: `src=https://example.com/data.tgz,dst=/data,opt=sha256=` + strings.Repeat("ab", 32) +
+				`,"opt=mirrors=https://mirror-1.example.com/data.tgz,https://mirror-2.example.com/data.tgz"` +
+				`,opt=extract=true,opt=header.Authorization=secret:file/token`,
+			expected: &models.InputSource{
+				Source: &models.SpecConfig{
+					Type: models.StorageSourceURL,
+					Params: map[string]interface{}{
+						"URL":     "https://example.com/data.tgz",
+						"SHA256":  strings.Repeat("ab", 32),
+						"Mirrors": []string{"https://mirror-1.example.com/data.tgz", "https://mirror-2.example.com/data.tgz"},
+						"Extract": true,
+						"Headers": map[string]string{"Authorization": "secret:file/token"},
+					},
+				},
+				Alias:  "https://example.com/data.tgz",
+				Target: "/data",
+			},
+		},
+		{
+			name:  "http with invalid checksum",
+			input: "src=https://example.com/data.tgz,opt=sha256=abc",
+			error: true,
+		},
+		{
+			name:  "http with unknown option",
+			input: "src=https://example.com/data.tgz,opt=unknown=value",
+			error: true,
+		},
+		{
+			name:  "oci",
+			input: "src=oci://ghcr.io/my-org/dataset:v1,dst=/mount/path",
+			expected: &models.InputSource{
+				Source: &models.SpecConfig{
+					Type: models.StorageSourceOCI,
+					Params: map[string]interface{}{
+						"Reference": "ghcr.io/my-org/dataset:v1",
+					},
+				},
+				Alias:  "oci://ghcr.io/my-org/dataset:v1",
+				Target: "/mount/path",
+			},
+		},
+		{
+			name:  "git",
+			input: `src=git+https://github.com/my-org/repo.git,dst=/src,opt=ref=main,opt=depth=1,"opt=sparse=docs,src",opt=token=secret:file/git-token`,
+			expected: &models.InputSource{
+				Source: &models.SpecConfig{
+					Type: models.StorageSourceGit,
+					Params: map[string]interface{}{
+						"URL":         "https://github.com/my-org/repo.git",
+						"Ref":         "main",
+						"Commit":      "",
+						"Depth":       1,
+						"SparsePaths": []string{"docs", "src"},
+						"Token":       "secret:file/git-token",
+						"SSHKey":      "",
+					},
+				},
+				Alias:  "git+https://github.com/my-org/repo.git",
+				Target: "/src",
+			},
+		},
+		{
+			name:  "git with plain token",
+			input: "src=git+https://github.com/my-org/repo.git,opt=token=my-token",
+			error: true,
+		},
+		{
+			name:  "job",
+			input: "job://j-7e3f9d2c/outputs/,dst=/upstream,opt=executions=all",
+			expected: &models.InputSource{
+				Source: &models.SpecConfig{
+					Type: models.StorageSourceJob,
+					Params: map[string]interface{}{
+						"JobID":             "j-7e3f9d2c",
+						"ExecutionSelector": "all",
+						"Path":              "outputs/",
+					},
+				},
+				Alias:  "job://j-7e3f9d2c/outputs/",
+				Target: "/upstream",
+			},
+		},
+		{
+			name:  "local directory",
+			input: "/data/dir:/inputs/dir",
+			expected: &models.InputSource{
+				Source: &models.SpecConfig{
+					Type:   StorageSourceLocalUpload,
+					Params: map[string]interface{}{"Path": "/data/dir"},
+				},
+				Alias:  "/data/dir",
+				Target: "/inputs/dir",
+			},
+		},
+		{
+			name:  "local file with explicit src and dst",
+			input: "src=/data/file.csv,dst=/inputs/file.csv",
+			expected: &models.InputSource{
+				Source: &models.SpecConfig{
+					Type:   StorageSourceLocalUpload,
+					Params: map[string]interface{}{"Path": "/data/file.csv"},
+				},
+				Alias:  "/data/file.csv",
+				Target: "/inputs/file.csv",
+			},
+		},
+		{
+			name:  "file with overlay",
+			input: "src=file:///data/dataset,dst=/inputs,overlay=true",
+			expected: &models.InputSource{
+				Source: &models.SpecConfig{
+					Type: models.StorageSourceLocal,
+					Params: map[string]interface{}{
+						"SourcePath": "/data/dataset",
+						"ReadWrite":  false,
+						"CreateAs":   storage_local.CreateStrategy(""),
+					},
+				},
+				Alias:   "file:///data/dataset",
+				Target:  "/inputs",
+				Overlay: &models.InputOverlay{},
+			},
+		},
+		{
+			name:  "file with published overlay",
+			input: "src=file:///data/dataset,dst=/inputs,overlay-publish=changes",
+			expected: &models.InputSource{
+				Source: &models.SpecConfig{
+					Type: models.StorageSourceLocal,
+					Params: map[string]interface{}{
+						"SourcePath": "/data/dataset",
+						"ReadWrite":  false,
+						"CreateAs":   storage_local.CreateStrategy(""),
+					},
+				},
+				Alias:   "file:///data/dataset",
+				Target:  "/inputs",
+				Overlay: &models.InputOverlay{Publish: "changes"},
+			},
+		},
+		{
+			name:  "invalid overlay",
+			input: "src=file:///data/dataset,dst=/inputs,overlay=maybe",
+			error: true,
+		},
+		{
+			name:  "local with options",
+			input: "/data/dir,opt=rw=true",
+			error: true,
+		},
 		{
 			name:  "empty",
 			input: "",
@@ -201,6 +356,17 @@ func TestParseInputSourceSpecConfig(t *testing.T) {
 	}
 }
 
+func TestParseRelativeLocalInput(t *testing.T) {
+	opt := StorageSpecConfigOpt{}
+	require.NoError(t, opt.Set("./data:/inputs"))
+	input := opt.Values()[0]
+	require.True(t, IsLocalUpload(input))
+	expected, err := filepath.Abs("data")
+	require.NoError(t, err)
+	assert.Equal(t, expected, LocalUploadPath(input))
+	assert.Equal(t, "/inputs", input.Target)
+}
+
 func TestParseMultipleStorageInputSources(t *testing.T) {
 	opt := StorageSpecConfigOpt{}
 	require.NoError(t, opt.Set("ipfs://QmXJ3wT1C27W8Vvc21NjLEb7VdNk9oM8zJYtDkG1yH2fnA"))
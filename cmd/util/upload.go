@@ -0,0 +1,140 @@
+package util
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bacalhau-project/bacalhau/cmd/util/opts"
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/blobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/apimodels"
+	clientv2 "github.com/bacalhau-project/bacalhau/pkg/publicapi/client/v2"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/blob"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/inline"
+)
+
+// InlineInputMaxSize is the maximum size of local files that are embedded in the job spec,
+// rather than uploaded to the orchestrator
+const InlineInputMaxSize = 64 * 1024
+
+// UploadLocalInputs uploads the local files and directories passed as inputs of the job, such as
+// --input ./data:/inputs, and replaces them with the storage they were uploaded to. Small files are
+// embedded in the job spec. Other inputs are archived and uploaded in chunks to the blob store of the
+// orchestrator, unless an identical archive was already uploaded.
+func UploadLocalInputs(ctx context.Context, api clientv2.API, job *models.Job) error {
+	for _, task := range job.Tasks {
+		for _, input := range task.InputSources {
+			if !opts.IsLocalUpload(input) {
+				continue
+			}
+			path := opts.LocalUploadPath(input)
+			source, err := uploadLocalInput(ctx, api.Blobs(), path)
+			if err != nil {
+				return fmt.Errorf("failed to upload input %s: %w", path, err)
+			}
+			input.Source = source
+		}
+	}
+	return nil
+}
+
+func uploadLocalInput(ctx context.Context, blobs *clientv2.Blobs, path string) (*models.SpecConfig, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().IsRegular() && info.Size() <= InlineInputMaxSize {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path provided by the user
+		if err != nil {
+			return nil, err
+		}
+		source := inline.NewStorage().StoreBytes(data)
+		return &source, nil
+	}
+
+	size, err := inputSize(path)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := os.CreateTemp("", "bacalhau-input-*.tar.gz")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = archive.Close()
+		_ = os.Remove(archive.Name())
+	}()
+	hash := sha256.New()
+	if err = gzip.CompressToWriter(path, io.MultiWriter(archive, hash)); err != nil {
+		return nil, fmt.Errorf("failed to archive input: %w", err)
+	}
+	digest := blobstore.FormatDigest(hash.Sum(nil))
+
+	// identical inputs are only uploaded once
+	_, err = blobs.Get(ctx, &apimodels.GetBlobRequest{Digest: digest})
+	if bacerrors.IsErrorWithCode(err, bacerrors.NotFoundError) {
+		err = uploadBlob(ctx, blobs, digest, archive)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	source := blob.SourceSpec{Digest: digest, Size: size}
+	if !info.IsDir() {
+		source.FileName = filepath.Base(path)
+	}
+	return blob.NewSpecConfig(source)
+}
+
+// uploadBlob uploads the archive in chunks, and commits it
+func uploadBlob(ctx context.Context, blobs *clientv2.Blobs, digest string, archive *os.File) error {
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	buf := make([]byte, blobstore.ChunkSize)
+	chunks := 0
+	for {
+		n, err := io.ReadFull(archive, buf)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+		if _, err = blobs.PutChunk(ctx, &apimodels.PutBlobChunkRequest{
+			Digest: digest,
+			Index:  chunks,
+			Data:   buf[:n],
+		}); err != nil {
+			return fmt.Errorf("failed to upload chunk %d: %w", chunks, err)
+		}
+		chunks++
+	}
+	_, err := blobs.Commit(ctx, &apimodels.CommitBlobRequest{Digest: digest, Chunks: chunks})
+	return err
+}
+
+// inputSize returns the total size of the regular files of an input
+func inputSize(path string) (uint64, error) {
+	var size uint64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += uint64(info.Size()) //nolint:gosec // G115: file sizes are never negative
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,199 @@
+//go:build unit || !integration
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/cmd/util/opts"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/blobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	natsutil "github.com/bacalhau-project/bacalhau/pkg/nats"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/apimodels"
+	clientv2 "github.com/bacalhau-project/bacalhau/pkg/publicapi/client/v2"
+	orchestrator_endpoint "github.com/bacalhau-project/bacalhau/pkg/publicapi/endpoint/orchestrator"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/middleware"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/blob"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/inline"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+// writeCountingClient counts the write requests sent to the API
+type writeCountingClient struct {
+	clientv2.Client
+	writes int
+}
+
+func (c *writeCountingClient) Put(
+	ctx context.Context, endpoint string, in apimodels.PutRequest, out apimodels.PutResponse) error {
+	c.writes++
+	return c.Client.Put(ctx, endpoint, in, out)
+}
+
+func (c *writeCountingClient) Post(
+	ctx context.Context, endpoint string, in apimodels.PutRequest, out apimodels.PutResponse) error {
+	c.writes++
+	return c.Client.Post(ctx, endpoint, in, out)
+}
+
+// UploadLocalInputsSuite uploads local inputs through the API of an orchestrator backed by an embedded
+// NATS server, and fetches them as a compute node would
+type UploadLocalInputsSuite struct {
+	suite.Suite
+	ctx     context.Context
+	nats    *server.Server
+	store   *blobstore.NATSStore
+	api     clientv2.API
+	apiURL  string
+	storage *blob.StorageProvider
+}
+
+func TestUploadLocalInputsSuite(t *testing.T) {
+	suite.Run(t, new(UploadLocalInputsSuite))
+}
+
+func (s *UploadLocalInputsSuite) SetupTest() {
+	s.ctx = context.Background()
+	natsOpts := natsserver.DefaultTestOptions
+	natsOpts.Port = -1
+	natsOpts.JetStream = true
+	natsOpts.StoreDir = s.T().TempDir()
+	s.nats = natsserver.RunServer(&natsOpts)
+
+	var err error
+	s.store, err = blobstore.NewNATSStore(blobstore.NATSStoreParams{
+		ClientFactory: natsutil.ClientFactoryFunc(func(context.Context) (*nats.Conn, error) {
+			return nats.Connect(s.nats.ClientURL())
+		}),
+	})
+	s.Require().NoError(err)
+
+	router := echo.New()
+	router.Binder = publicapi.NewNormalizeBinder()
+	router.Validator = publicapi.NewCustomValidator()
+	router.HTTPErrorHandler = middleware.CustomHTTPErrorHandler
+	orchestrator_endpoint.NewEndpoint(orchestrator_endpoint.EndpointParams{Router: router, BlobStore: s.store})
+	apiServer := httptest.NewServer(router)
+	s.T().Cleanup(apiServer.Close)
+
+	s.apiURL = apiServer.URL
+	s.api = clientv2.New(s.apiURL)
+	s.storage = blob.NewStorage(blob.StorageProviderParams{Store: s.store})
+}
+
+func (s *UploadLocalInputsSuite) TearDownTest() {
+	s.Require().NoError(s.store.Close(s.ctx))
+	s.nats.Shutdown()
+}
+
+// job returns a job with a local input of path
+func (s *UploadLocalInputsSuite) job(path string) *models.Job {
+	input, err := opts.ParseStorageSpec(path+":/inputs", "/inputs")
+	s.Require().NoError(err)
+	job := mock.Job()
+	job.Task().InputSources = []*models.InputSource{input}
+	return job
+}
+
+func (s *UploadLocalInputsSuite) writeFile(path string, size int) []byte {
+	data := make([]byte, size)
+	_, err := rand.Read(data)
+	s.Require().NoError(err)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	s.Require().NoError(os.WriteFile(path, data, 0644))
+	return data
+}
+
+func (s *UploadLocalInputsSuite) TestUploadDirectory() {
+	dir := s.T().TempDir()
+	// random data does not compress, so the archive is uploaded in several chunks
+	large := s.writeFile(filepath.Join(dir, "large.bin"), blobstore.ChunkSize+1024)
+	small := s.writeFile(filepath.Join(dir, "nested", "small.bin"), 10)
+
+	job := s.job(dir)
+	s.Require().NoError(UploadLocalInputs(s.ctx, s.api, job))
+	input := job.Task().InputSources[0]
+	s.Require().Equal(models.StorageSourceBlob, input.Source.Type)
+	source, err := blob.DecodeSpec(input.Source)
+	s.Require().NoError(err)
+	s.Equal(uint64(len(large)+len(small)), source.Size)
+	s.Empty(source.FileName)
+
+	volume, err := s.storage.PrepareStorage(s.ctx, s.T().TempDir(), mock.Execution(), *input)
+	s.Require().NoError(err)
+	for name, expected := range map[string][]byte{"large.bin": large, "nested/small.bin": small} {
+		data, err := os.ReadFile(filepath.Join(volume.Source, name))
+		s.Require().NoError(err)
+		s.True(bytes.Equal(expected, data), name)
+	}
+}
+
+func (s *UploadLocalInputsSuite) TestUploadLargeFile() {
+	path := filepath.Join(s.T().TempDir(), "data.bin")
+	expected := s.writeFile(path, InlineInputMaxSize+1)
+
+	job := s.job(path)
+	s.Require().NoError(UploadLocalInputs(s.ctx, s.api, job))
+	input := job.Task().InputSources[0]
+	s.Require().Equal(models.StorageSourceBlob, input.Source.Type)
+
+	volume, err := s.storage.PrepareStorage(s.ctx, s.T().TempDir(), mock.Execution(), *input)
+	s.Require().NoError(err)
+	s.Equal("data.bin", filepath.Base(volume.Source))
+	data, err := os.ReadFile(volume.Source)
+	s.Require().NoError(err)
+	s.True(bytes.Equal(expected, data))
+}
+
+func (s *UploadLocalInputsSuite) TestSmallFileIsInline() {
+	path := filepath.Join(s.T().TempDir(), "script.sh")
+	s.Require().NoError(os.WriteFile(path, []byte("echo hello"), 0644))
+
+	job := s.job(path)
+	s.Require().NoError(UploadLocalInputs(s.ctx, s.api, job))
+	input := job.Task().InputSources[0]
+	s.Require().Equal(models.StorageSourceInline, input.Source.Type)
+
+	volume, err := inline.NewStorage().PrepareStorage(s.ctx, s.T().TempDir(), mock.Execution(), *input)
+	s.Require().NoError(err)
+	data, err := os.ReadFile(volume.Source)
+	s.Require().NoError(err)
+	s.Equal("echo hello", string(data))
+}
+
+func (s *UploadLocalInputsSuite) TestIdenticalInputsAreUploadedOnce() {
+	dir := s.T().TempDir()
+	s.writeFile(filepath.Join(dir, "data.bin"), InlineInputMaxSize)
+
+	first := s.job(dir)
+	s.Require().NoError(UploadLocalInputs(s.ctx, s.api, first))
+	source, err := blob.DecodeSpec(first.Task().InputSources[0].Source)
+	s.Require().NoError(err)
+
+	// the blob is found by digest, so nothing is uploaded for the second job
+	client := &writeCountingClient{Client: clientv2.NewHTTPClient(s.apiURL)}
+	second := s.job(dir)
+	s.Require().NoError(UploadLocalInputs(s.ctx, clientv2.NewAPI(client), second))
+	s.Equal(first.Task().InputSources[0].Source, second.Task().InputSources[0].Source)
+	s.Zero(client.writes)
+	_, err = s.store.Stat(s.ctx, source.Digest)
+	s.Require().NoError(err)
+}
+
+func (s *UploadLocalInputsSuite) TestMissingInput() {
+	job := s.job(filepath.Join(s.T().TempDir(), "missing"))
+	s.Require().Error(UploadLocalInputs(s.ctx, s.api, job))
+}
@@ -2,6 +2,8 @@ package authz
 
 import (
 	"net/http"
+	"regexp"
+	"strings"
 
 	"github.com/bacalhau-project/bacalhau/pkg/config/types"
 )
@@ -23,8 +25,17 @@ const (
 	ResourceTypeJob   ResourceType = "job"
 	ResourceTypeAgent ResourceType = "agent"
 	ResourceTypeOpen  ResourceType = "open"
+	// ResourceTypeExec covers interactive sessions into running executions
+	ResourceTypeExec ResourceType = "exec"
 )
 
+// ExecJobCapability is the capability required to open interactive sessions into running executions.
+// It is deliberately not granted by the write:* wildcard, as it gives shell access to job workloads.
+const ExecJobCapability = "exec:job"
+
+// execEndpointPattern matches the endpoint of interactive sessions, which sits under the job endpoints
+var execEndpointPattern = regexp.MustCompile(`^/api/v1/orchestrator/jobs/[^/]+/executions/[^/]+/exec/?$`)
+
 // GetRequiredCapability determines the required capability for a specific resource type and HTTP method
 func (c *CapabilityChecker) GetRequiredCapability(resourceType ResourceType, method string) string {
 	isReadOperation := method == http.MethodGet
@@ -45,6 +56,9 @@ func (c *CapabilityChecker) GetRequiredCapability(resourceType ResourceType, met
 			return "read:agent"
 		}
 		return "write:agent"
+	case ResourceTypeExec:
+		// sessions are opened with a GET websocket upgrade, but are never read-only
+		return ExecJobCapability
 	default:
 		// If no resource type matched, default to requiring node admin for safety
 		return "write:node"
@@ -61,6 +75,7 @@ func (c *CapabilityChecker) HasRequiredCapability(user types.AuthUser, requiredC
 	// Determine if it's a read operation based on the required capability
 	// Only consider it a read operation if it explicitly starts with "read:"
 	isReadOperation := len(requiredCapability) >= 5 && requiredCapability[:5] == "read:"
+	isExecOperation := strings.HasPrefix(requiredCapability, "exec:")
 
 	// Check against the exact list of allowed capabilities
 	for _, capability := range user.Capabilities {
@@ -80,8 +95,13 @@ func (c *CapabilityChecker) HasRequiredCapability(user types.AuthUser, requiredC
 				return true
 			}
 
-			// Write wildcard - matches any non-read: capability
-			if !isReadOperation && action == "write:*" {
+			// Write wildcard - matches any non-read: capability, except exec: capabilities
+			if !isReadOperation && !isExecOperation && action == "write:*" {
+				return true
+			}
+
+			// Exec wildcard
+			if isExecOperation && action == "exec:*" {
 				return true
 			}
 		}
@@ -118,6 +138,11 @@ func MapEndpointToResourceType(path string, endpointsPermissions map[string]stri
 		}
 	}
 
+	// Interactive sessions sit under the job endpoints, but require their own capability
+	if ResourceType(matchedResourceType) == ResourceTypeJob && execEndpointPattern.MatchString(path) {
+		return ResourceTypeExec
+	}
+
 	// Return the resource type for the longest match (or empty if no match)
 	return ResourceType(matchedResourceType)
 }
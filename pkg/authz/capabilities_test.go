@@ -346,6 +346,44 @@ func TestResourceTypeAgent(t *testing.T) {
 	assert.Equal(t, "write:agent", requiredWriteCapability)
 }
 
+// TestResourceTypeExec verifies that interactive sessions require the exec capability
+func TestResourceTypeExec(t *testing.T) {
+	checker := NewCapabilityChecker()
+	permissions := GetDefaultEndpointPermissions()
+
+	execPath := "/api/v1/orchestrator/jobs/j-123/executions/e-456/exec"
+	assert.Equal(t, ResourceTypeExec, MapEndpointToResourceType(execPath, permissions))
+	assert.Equal(t, ResourceTypeJob, MapEndpointToResourceType("/api/v1/orchestrator/jobs/j-123/executions", permissions))
+	assert.Equal(t, ResourceTypeJob, MapEndpointToResourceType("/api/v1/orchestrator/jobs/exec", permissions))
+
+	// sessions are opened with GET, but still require the exec capability
+	assert.Equal(t, ExecJobCapability, checker.GetRequiredCapability(ResourceTypeExec, http.MethodGet))
+
+	req := httptest.NewRequest(http.MethodGet, execPath, nil)
+	testCases := []struct {
+		name     string
+		actions  []string
+		expected bool
+	}{
+		{name: "exec capability", actions: []string{"exec:job"}, expected: true},
+		{name: "exec wildcard", actions: []string{"exec:*"}, expected: true},
+		{name: "universal wildcard", actions: []string{"*"}, expected: true},
+		{name: "write wildcard", actions: []string{"write:*", "read:*"}, expected: false},
+		{name: "job capabilities", actions: []string{"read:job", "write:job"}, expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := types.AuthUser{
+				Alias:        "exec_user",
+				Capabilities: []types.Capability{{Actions: tc.actions}},
+			}
+			hasAccess, requiredCapability := checker.CheckUserAccess(user, ResourceTypeExec, req)
+			assert.Equal(t, tc.expected, hasAccess)
+			assert.Equal(t, ExecJobCapability, requiredCapability)
+		})
+	}
+}
+
 // TestEdgeCaseEmptyCapabilities tests the capability checker with empty capabilities
 func TestEdgeCaseEmptyCapabilities(t *testing.T) {
 	checker := NewCapabilityChecker()
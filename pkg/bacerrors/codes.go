@@ -21,6 +21,7 @@ const (
 	DatastoreFailure   ErrorCode = "DatastoreFailure"
 	RequestCancelled   ErrorCode = "RequestCancelled"
 	IOError            ErrorCode = "IOError"
+	ChecksumMismatch   ErrorCode = "ChecksumMismatch"
 	UnknownError       ErrorCode = "UnknownError"
 )
 
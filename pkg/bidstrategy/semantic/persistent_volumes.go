@@ -0,0 +1,62 @@
+package semantic
+
+import (
+	"context"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+)
+
+type PersistentVolumesStrategyParams struct {
+	// Disabled rejects jobs with persistent volumes
+	Disabled bool
+	// MaxSize is the maximum size in bytes of a persistent volume on the node. Unlimited if 0.
+	MaxSize uint64
+}
+
+// Compile-time check of interface implementation
+var _ bidstrategy.SemanticBidStrategy = (*PersistentVolumesStrategy)(nil)
+
+// PersistentVolumesStrategy rejects jobs with persistent volumes if the node doesn't support them,
+// or if their volumes are larger than the node allows
+type PersistentVolumesStrategy struct {
+	disabled bool
+	maxSize  uint64
+}
+
+func NewPersistentVolumesStrategy(params PersistentVolumesStrategyParams) *PersistentVolumesStrategy {
+	return &PersistentVolumesStrategy{
+		disabled: params.Disabled,
+		maxSize:  params.MaxSize,
+	}
+}
+
+const (
+	noPersistentVolumesReason = "run jobs without persistent volumes"
+	persistentVolumesReason   = "accept jobs with persistent volumes"
+	volumeMaxSizeReason       = "accept persistent volumes larger than %s, such as %q of %s"
+)
+
+func (s *PersistentVolumesStrategy) ShouldBid(
+	ctx context.Context,
+	request bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	volumes := request.Job.Task().Volumes
+	if len(volumes) == 0 {
+		return bidstrategy.NewBidResponse(true, noPersistentVolumesReason), nil
+	}
+	if s.disabled {
+		return bidstrategy.NewBidResponse(false, persistentVolumesReason), nil
+	}
+	for _, volume := range volumes {
+		maxSize, err := volume.GetMaxSize()
+		if err != nil {
+			return bidstrategy.BidStrategyResponse{}, err
+		}
+		if s.maxSize > 0 && maxSize > s.maxSize {
+			return bidstrategy.NewBidResponse(false, volumeMaxSizeReason,
+				humanize.Bytes(s.maxSize), volume.Name, humanize.Bytes(maxSize)), nil
+		}
+	}
+	return bidstrategy.NewBidResponse(true, persistentVolumesReason), nil
+}
@@ -0,0 +1,48 @@
+//go:build unit || !integration
+
+package semantic_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy/semantic"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+func TestPersistentVolumesStrategy(t *testing.T) {
+	withVolume := func(maxSize string) *models.Task {
+		task := mock.Task()
+		task.Volumes = []*models.PersistentVolume{{Name: "data", Target: "/data", MaxSize: maxSize}}
+		return task
+	}
+
+	testCases := []struct {
+		name     string
+		task     *models.Task
+		params   semantic.PersistentVolumesStrategyParams
+		expected bool
+	}{
+		{"no volumes", mock.Task(), semantic.PersistentVolumesStrategyParams{Disabled: true}, true},
+		{"volumes disabled", withVolume(""), semantic.PersistentVolumesStrategyParams{Disabled: true}, false},
+		{"default size", withVolume(""), semantic.PersistentVolumesStrategyParams{MaxSize: 1000}, true},
+		{"within max size", withVolume("1KB"), semantic.PersistentVolumesStrategyParams{MaxSize: 1000}, true},
+		{"exceeds max size", withVolume("2KB"), semantic.PersistentVolumesStrategyParams{MaxSize: 1000}, false},
+		{"unlimited max size", withVolume("2KB"), semantic.PersistentVolumesStrategyParams{}, true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			strategy := semantic.NewPersistentVolumesStrategy(testCase.params)
+			result, err := strategy.ShouldBid(context.Background(), bidstrategy.BidStrategyRequest{
+				Job: models.Job{Tasks: []*models.Task{testCase.task}},
+			})
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, result.ShouldBid, "Reason: %q", result.Reason)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package compute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+// checkpointMarkerPollInterval is how often the checkpoint directory is checked for the
+// marker the task creates once it has written its checkpoint
+const checkpointMarkerPollInterval = 500 * time.Millisecond
+
+// startCheckpoints periodically checkpoints a running execution if its task has checkpoints enabled.
+// Each checkpoint signals the task to write its state into the checkpoint directory, publishes the
+// directory through the task's publisher and records it on the execution, which reports it to the
+// orchestrator. The returned function stops checkpointing and waits for an in-flight checkpoint to end.
+func (e *BaseExecutor) startCheckpoints(ctx context.Context, execution *models.Execution) func() {
+	config := execution.Job.Task().Checkpoint
+	if !config.IsEnabled() || execution.Job.Task().Publisher.IsEmpty() {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(config.GetInterval())
+		defer ticker.Stop()
+
+		var sequence uint64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sequence++
+				err := e.checkpoint(ctx, execution, sequence)
+				if err == nil {
+					continue
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				if bacerrors.IsErrorWithCode(err, executor.CheckpointNotSupported) {
+					log.Ctx(ctx).Warn().Err(err).Msg("execution does not support checkpoints")
+					return
+				}
+				log.Ctx(ctx).Warn().Err(err).Uint64("sequence", sequence).Msg("failed to checkpoint execution")
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// checkpoint asks the execution to write a checkpoint, then publishes and records it.
+func (e *BaseExecutor) checkpoint(ctx context.Context, execution *models.Execution, sequence uint64) error {
+	config := execution.Job.Task().Checkpoint
+	checkpointDir := ExecutionCheckpointDir(e.resultsPath.ExecutionOutputDir(execution.ID))
+	marker := filepath.Join(checkpointDir, models.CheckpointCompleteMarker)
+
+	// remove the marker of the previous checkpoint so that we only publish once the task is done writing
+	if err := os.Remove(marker); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to reset checkpoint marker: %w", err)
+	}
+
+	jobExecutor, err := e.executors.Get(ctx, execution.Job.Task().Engine.Type)
+	if err != nil {
+		return fmt.Errorf("failed to get executor %s: %w", execution.Job.Task().Engine, err)
+	}
+	if err = jobExecutor.Checkpoint(ctx, &executor.CheckpointRequest{
+		ExecutionID: execution.ID,
+		Signal:      config.GetSignal(),
+	}); err != nil {
+		return err
+	}
+
+	if err = waitForCheckpointMarker(ctx, marker, config.GetTimeout()); err != nil {
+		return err
+	}
+
+	result, err := e.publish(ctx, checkpointExecution(execution, sequence), checkpointDir)
+	if err != nil {
+		return err
+	}
+
+	checkpoint := &models.Checkpoint{
+		Sequence:   sequence,
+		Result:     result,
+		CreateTime: time.Now().UTC().UnixNano(),
+	}
+	return e.store.UpdateExecutionState(ctx, store.UpdateExecutionRequest{
+		ExecutionID: execution.ID,
+		Condition: store.UpdateExecutionCondition{
+			ExpectedStates: []models.ExecutionStateType{models.ExecutionStateRunning},
+		},
+		NewValues: models.Execution{
+			Checkpoint: checkpoint,
+		},
+		Events: []*models.Event{ExecCheckpointedEvent(checkpoint)},
+	})
+}
+
+// checkpointExecution returns a copy of the execution identified as its checkpoint of the sequence.
+// Publishers name the destination of results after the execution, such as <executionID>.tar.gz,
+// so checkpoints are published as checkpoint-<sequence> of the execution to not overwrite its
+// results or its other checkpoints.
+func checkpointExecution(execution *models.Execution, sequence uint64) *models.Execution {
+	checkpoint := execution.Copy()
+	checkpoint.ID = fmt.Sprintf("%s-checkpoint-%d", execution.ID, sequence)
+	return checkpoint
+}
+
+// waitForCheckpointMarker waits until the task created the checkpoint marker, or the timeout elapses.
+func waitForCheckpointMarker(ctx context.Context, marker string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(checkpointMarkerPollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := os.Stat(marker); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("task did not complete its checkpoint within %s", timeout)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,24 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+func TestCheckpointExecution(t *testing.T) {
+	execution := mock.Execution()
+	executionID := execution.ID
+
+	first := checkpointExecution(execution, 1)
+	second := checkpointExecution(execution, 2)
+	require.Equal(t, executionID+"-checkpoint-1", first.ID)
+	require.Equal(t, executionID+"-checkpoint-2", second.ID)
+	require.Equal(t, execution.JobID, first.JobID)
+	require.Equal(t, execution.NodeID, first.NodeID)
+	require.Equal(t, executionID, execution.ID, "the execution should not be modified")
+}
@@ -27,7 +27,7 @@ func NewBaseEndpoint(params BaseEndpointParams) BaseEndpoint {
 
 func (s BaseEndpoint) AskForBid(
 	ctx context.Context, request legacy.AskForBidRequest) (legacy.AskForBidResponse, error) {
-	log.Ctx(ctx).Debug().Msgf("asked to bid on: %+v", request)
+	log.Ctx(ctx).Debug().Msgf("asked to bid on: %+v", request.Execution.Redacted())
 	jobsReceived.Add(ctx, 1)
 
 	// Create the execution in the store. The bidder will asynchronously handle the bid request.
@@ -1,6 +1,15 @@
 package env
 
+import "github.com/bacalhau-project/bacalhau/pkg/models"
+
 const (
 	// PrefixDelimiter is used to separate prefix from value in environment variables
 	PrefixDelimiter = ":"
 )
+
+const (
+	// HostPrefix is the prefix of values referencing host environment variables
+	HostPrefix = "env"
+	// SecretPrefix is the prefix of values referencing secrets
+	SecretPrefix = models.EnvVarSecretScheme
+)
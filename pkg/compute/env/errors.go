@@ -20,3 +20,37 @@ func newErrNotFound(name string) bacerrors.Error {
 		WithComponent(errComponent).
 		WithHint("Check the host environment variables")
 }
+
+func newErrSecretBackendNotFound(backend string) bacerrors.Error {
+	return bacerrors.Newf("secret backend '%s' is not configured on this node", backend).
+		WithCode(bacerrors.NotFoundError).
+		WithComponent(errComponent).
+		WithHint("Check the secret backends of the compute node's configuration")
+}
+
+func newErrInvalidSecretRef(name string) bacerrors.Error {
+	return bacerrors.Newf("environment variable '%s' has an invalid secret reference", name).
+		WithCode(bacerrors.ValidationError).
+		WithComponent(errComponent).
+		WithHint("Secret references must have the form secret:<backend>/<key>")
+}
+
+func newErrSecretNotFound(backend, key string) bacerrors.Error {
+	return bacerrors.Newf("secret '%s' not found in backend '%s'", key, backend).
+		WithCode(bacerrors.NotFoundError).
+		WithComponent(errComponent)
+}
+
+func newErrSecretBackend(backend string, err error) bacerrors.Error {
+	return bacerrors.Wrapf(err, "failed to read secret from backend '%s'", backend).
+		WithCode(bacerrors.ServiceUnavailable).
+		WithComponent(errComponent).
+		WithRetryable()
+}
+
+func newErrSealedSecret() bacerrors.Error {
+	return bacerrors.New("sealed secret could not be opened by this node").
+		WithCode(bacerrors.UnauthorizedError).
+		WithComponent(errComponent).
+		WithHint("The secret may have been sealed for a different node")
+}
@@ -17,7 +17,7 @@ func NewHostResolver(allowList []string) *HostResolver {
 }
 
 func (h *HostResolver) Prefix() string {
-	return "env"
+	return HostPrefix
 }
 
 // Validate checks if the value is allowed
@@ -16,14 +16,20 @@ type ResolverParams struct {
 	// AllowList specifies which host environment variables can be forwarded to jobs.
 	// Supports glob patterns (e.g., "AWS_*", "API_*")
 	AllowList []string
+
+	// SecretBackends are the backends available to resolve secret references
+	// of the form "secret:<backend>/<key>"
+	SecretBackends []SecretBackend
 }
 
 // NewResolver creates a new resolver map with configured resolvers
 func NewResolver(params ResolverParams) *ResolverMap {
 	hostResolver := NewHostResolver(params.AllowList)
+	secretResolver := NewSecretResolver(params.SecretBackends...)
 	return &ResolverMap{
 		resolvers: map[string]compute.EnvVarResolver{
-			"env": hostResolver,
+			HostPrefix:   hostResolver,
+			SecretPrefix: secretResolver,
 		},
 	}
 }
@@ -0,0 +1,268 @@
+package env
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/maps"
+)
+
+const (
+	// FileSecretBackendName is the name of the encrypted file secret backend
+	FileSecretBackendName = "file"
+
+	secretFileKeySize = 32
+	secretFilePerms   = 0o600
+	secretDirPerms    = 0o700
+)
+
+// FileSecretStore is a secret backend that keeps secrets in a local file
+// encrypted with AES-256-GCM. The encryption key is stored in a separate key file,
+// which is created on first use if it does not exist.
+// The secrets file is re-read on every lookup so that secrets can be
+// rotated without restarting the node.
+type FileSecretStore struct {
+	name    string
+	path    string
+	keyFile string
+	mu      sync.RWMutex
+}
+
+// FileSecretStoreParams contains the configuration of a FileSecretStore
+type FileSecretStoreParams struct {
+	// Name overrides the backend name. Defaults to FileSecretBackendName.
+	Name string
+	// Path is the path of the encrypted secrets file
+	Path string
+	// KeyFile is the path of the file holding the hex encoded encryption key
+	KeyFile string
+}
+
+// NewFileSecretStore creates a new file secret store
+func NewFileSecretStore(params FileSecretStoreParams) (*FileSecretStore, error) {
+	if params.Path == "" {
+		return nil, fmt.Errorf("secrets file path is required")
+	}
+	if params.KeyFile == "" {
+		return nil, fmt.Errorf("secrets key file path is required")
+	}
+	if params.Name == "" {
+		params.Name = FileSecretBackendName
+	}
+	s := &FileSecretStore{
+		name:    params.Name,
+		path:    params.Path,
+		keyFile: params.KeyFile,
+	}
+	// make sure the key exists so that failures surface at startup
+	if _, err := s.loadOrCreateKey(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSecretStore) Name() string {
+	return s.name
+}
+
+// Get returns the decrypted secret stored under key
+func (s *FileSecretStore) Get(key string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secrets, err := s.read()
+	if err != nil {
+		return "", newErrSecretBackend(s.name, err)
+	}
+	encrypted, ok := secrets[key]
+	if !ok {
+		return "", newErrSecretNotFound(s.name, key)
+	}
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", newErrSecretBackend(s.name, err)
+	}
+	value, err := decrypt(gcm, encrypted)
+	if err != nil {
+		return "", newErrSecretBackend(s.name, fmt.Errorf("decrypting secret %s: %w", key, err))
+	}
+	return value, nil
+}
+
+// Put encrypts and stores a secret under key, replacing any existing value
+func (s *FileSecretStore) Put(key string, value string) error {
+	if key == "" || strings.ContainsAny(key, " \t\n") {
+		return fmt.Errorf("invalid secret name %q", key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+	gcm, err := s.cipher()
+	if err != nil {
+		return err
+	}
+	encrypted, err := encrypt(gcm, value)
+	if err != nil {
+		return err
+	}
+	secrets[key] = encrypted
+	return s.write(secrets)
+}
+
+// Delete removes the secret stored under key
+func (s *FileSecretStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok {
+		return newErrSecretNotFound(s.name, key)
+	}
+	delete(secrets, key)
+	return s.write(secrets)
+}
+
+// List returns the sorted names of the stored secrets
+func (s *FileSecretStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secrets, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	names := maps.Keys(secrets)
+	slices.Sort(names)
+	return names, nil
+}
+
+func (s *FileSecretStore) read() (map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]string), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading secrets file: %w", err)
+	}
+	secrets := make(map[string]string)
+	if err = json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("parsing secrets file: %w", err)
+	}
+	return secrets, nil
+}
+
+func (s *FileSecretStore) write(secrets map[string]string) error {
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(s.path), secretDirPerms); err != nil {
+		return fmt.Errorf("creating secrets directory: %w", err)
+	}
+	// write to a temporary file first so readers never observe a partial file
+	tmp := s.path + ".tmp"
+	if err = writeSynced(tmp, data, secretFilePerms); err != nil {
+		return fmt.Errorf("writing secrets file: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// writeSynced writes data to the file and flushes it to disk, so that it is complete once renamed
+func writeSynced(path string, data []byte, perm os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm) //nolint:gosec // G304: path of the secrets file
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (s *FileSecretStore) cipher() (cipher.AEAD, error) {
+	key, err := s.loadOrCreateKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *FileSecretStore) loadOrCreateKey() ([]byte, error) {
+	data, err := os.ReadFile(s.keyFile)
+	if err == nil {
+		key, decodeErr := hex.DecodeString(strings.TrimSpace(string(data)))
+		if decodeErr != nil || len(key) != secretFileKeySize {
+			return nil, fmt.Errorf("secrets key file %s must contain a hex encoded %d byte key", s.keyFile, secretFileKeySize)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading secrets key file: %w", err)
+	}
+
+	key := make([]byte, secretFileKeySize)
+	if _, err = rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating secrets key: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(s.keyFile), secretDirPerms); err != nil {
+		return nil, fmt.Errorf("creating secrets key directory: %w", err)
+	}
+	if err = os.WriteFile(s.keyFile, []byte(hex.EncodeToString(key)), secretFilePerms); err != nil {
+		return nil, fmt.Errorf("writing secrets key file: %w", err)
+	}
+	return key, nil
+}
+
+func encrypt(gcm cipher.AEAD, value string) (string, error) {
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), nil)), nil
+}
+
+func decrypt(gcm cipher.AEAD, encrypted string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("ciphertext is too short")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// compile-time check for interface implementation
+var _ SecretBackend = &FileSecretStore{}
@@ -0,0 +1,95 @@
+//go:build unit || !integration
+
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FileSecretStoreSuite struct {
+	suite.Suite
+	dir   string
+	store *FileSecretStore
+}
+
+func TestFileSecretStoreSuite(t *testing.T) {
+	suite.Run(t, new(FileSecretStoreSuite))
+}
+
+func (s *FileSecretStoreSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	var err error
+	s.store, err = NewFileSecretStore(FileSecretStoreParams{
+		Path:    filepath.Join(s.dir, "secrets.json"),
+		KeyFile: filepath.Join(s.dir, "secrets.key"),
+	})
+	s.Require().NoError(err)
+}
+
+func (s *FileSecretStoreSuite) TestPutGet() {
+	s.Require().NoError(s.store.Put("db-password", "hunter2"))
+
+	value, err := s.store.Get("db-password")
+	s.Require().NoError(err)
+	s.Equal("hunter2", value)
+
+	// secrets are not stored in plain text
+	data, err := os.ReadFile(filepath.Join(s.dir, "secrets.json"))
+	s.Require().NoError(err)
+	s.NotContains(string(data), "hunter2")
+
+	_, err = s.store.Get("missing")
+	s.ErrorContains(err, "not found")
+}
+
+func (s *FileSecretStoreSuite) TestReopen() {
+	s.Require().NoError(s.store.Put("token", "abc"))
+
+	reopened, err := NewFileSecretStore(FileSecretStoreParams{
+		Path:    filepath.Join(s.dir, "secrets.json"),
+		KeyFile: filepath.Join(s.dir, "secrets.key"),
+	})
+	s.Require().NoError(err)
+
+	value, err := reopened.Get("token")
+	s.Require().NoError(err)
+	s.Equal("abc", value)
+}
+
+func (s *FileSecretStoreSuite) TestWrongKey() {
+	s.Require().NoError(s.store.Put("token", "abc"))
+
+	other, err := NewFileSecretStore(FileSecretStoreParams{
+		Path:    filepath.Join(s.dir, "secrets.json"),
+		KeyFile: filepath.Join(s.dir, "other.key"),
+	})
+	s.Require().NoError(err)
+
+	_, err = other.Get("token")
+	s.Error(err)
+}
+
+func (s *FileSecretStoreSuite) TestListDelete() {
+	s.Require().NoError(s.store.Put("b", "2"))
+	s.Require().NoError(s.store.Put("a", "1"))
+
+	names, err := s.store.List()
+	s.Require().NoError(err)
+	s.Equal([]string{"a", "b"}, names)
+
+	s.Require().NoError(s.store.Delete("a"))
+	s.Error(s.store.Delete("a"))
+
+	names, err = s.store.List()
+	s.Require().NoError(err)
+	s.Equal([]string{"b"}, names)
+}
+
+func (s *FileSecretStoreSuite) TestInvalidName() {
+	s.Error(s.store.Put("", "value"))
+	s.Error(s.store.Put("with space", "value"))
+}
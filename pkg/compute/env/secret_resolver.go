@@ -0,0 +1,78 @@
+package env
+
+import (
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+// SecretBackend is a source of secrets that can be referenced from task environment variables
+// using the form "secret:<backend>/<key>".
+type SecretBackend interface {
+	// Name returns the backend name used in secret references
+	Name() string
+
+	// Get returns the secret stored under key
+	Get(key string) (string, error)
+}
+
+// SecretResolver handles secret references by delegating to the registered backends
+type SecretResolver struct {
+	backends map[string]SecretBackend
+}
+
+// NewSecretResolver creates a new secret resolver with the given backends
+func NewSecretResolver(backends ...SecretBackend) *SecretResolver {
+	r := &SecretResolver{
+		backends: make(map[string]SecretBackend),
+	}
+	for _, backend := range backends {
+		r.Register(backend)
+	}
+	return r
+}
+
+// Register adds a backend to the resolver, replacing any backend with the same name
+func (r *SecretResolver) Register(backend SecretBackend) {
+	r.backends[backend.Name()] = backend
+}
+
+func (r *SecretResolver) Prefix() string {
+	return SecretPrefix
+}
+
+// Validate checks if the secret reference is well formed and its backend is configured
+func (r *SecretResolver) Validate(name string, value string) error {
+	_, err := r.backend(name, value)
+	return err
+}
+
+// Value returns the secret from the referenced backend
+func (r *SecretResolver) Value(value string) (string, error) {
+	// the value is not included in errors as it may carry a sealed secret
+	backend, err := r.backend(SecretPrefix, value)
+	if err != nil {
+		return "", err
+	}
+	_, key, _ := splitSecretRef(value)
+	return backend.Get(key)
+}
+
+func (r *SecretResolver) backend(name string, value string) (SecretBackend, error) {
+	backendName, key, ok := splitSecretRef(value)
+	if !ok || key == "" {
+		return nil, newErrInvalidSecretRef(name)
+	}
+	backend, exists := r.backends[backendName]
+	if !exists {
+		return nil, newErrSecretBackendNotFound(backendName)
+	}
+	return backend, nil
+}
+
+// splitSecretRef splits the part of a secret reference following the prefix into backend and key
+func splitSecretRef(value string) (backend string, key string, ok bool) {
+	return models.EnvVarValue(SecretPrefix + PrefixDelimiter + value).SecretRef()
+}
+
+// compile-time check for interface implementation
+var _ compute.EnvVarResolver = &SecretResolver{}
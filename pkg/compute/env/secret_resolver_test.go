@@ -0,0 +1,152 @@
+//go:build unit || !integration
+
+package env
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/crypto"
+)
+
+// staticSecretBackend is an in-memory backend used in tests
+type staticSecretBackend struct {
+	name    string
+	secrets map[string]string
+}
+
+func (b *staticSecretBackend) Name() string {
+	return b.name
+}
+
+func (b *staticSecretBackend) Get(key string) (string, error) {
+	value, ok := b.secrets[key]
+	if !ok {
+		return "", newErrSecretNotFound(b.name, key)
+	}
+	return value, nil
+}
+
+type SecretResolverSuite struct {
+	suite.Suite
+	key      *rsa.PrivateKey
+	resolver *ResolverMap
+}
+
+func TestSecretResolverSuite(t *testing.T) {
+	suite.Run(t, new(SecretResolverSuite))
+}
+
+func (s *SecretResolverSuite) SetupSuite() {
+	var err error
+	s.key, err = rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+}
+
+func (s *SecretResolverSuite) SetupTest() {
+	s.resolver = NewResolver(ResolverParams{
+		SecretBackends: []SecretBackend{
+			&staticSecretBackend{name: "static", secrets: map[string]string{"db/password": "hunter2"}},
+			NewSealedSecretBackend(s.key),
+		},
+	})
+}
+
+func (s *SecretResolverSuite) TestValidate() {
+	tests := []struct {
+		name      string
+		value     string
+		shouldErr bool
+	}{
+		{
+			name:  "configured backend",
+			value: "secret:static/db/password",
+		},
+		{
+			name:      "unknown backend",
+			value:     "secret:vault/kv/data/app",
+			shouldErr: true,
+		},
+		{
+			name:      "missing key",
+			value:     "secret:static",
+			shouldErr: true,
+		},
+		{
+			name:      "empty key",
+			value:     "secret:static/",
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			err := s.resolver.Validate("JOB_VAR", tt.value)
+			if tt.shouldErr {
+				s.Error(err)
+			} else {
+				s.NoError(err)
+			}
+		})
+	}
+}
+
+func (s *SecretResolverSuite) TestValue() {
+	sealed, err := crypto.Seal(&s.key.PublicKey, []byte("sealed-value"))
+	s.Require().NoError(err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	sealedForOther, err := crypto.Seal(&otherKey.PublicKey, []byte("sealed-value"))
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		errContains string
+	}{
+		{
+			name:     "static secret",
+			value:    "secret:static/db/password",
+			expected: "hunter2",
+		},
+		{
+			name:     "sealed secret",
+			value:    "secret:sealed/" + sealed,
+			expected: "sealed-value",
+		},
+		{
+			name:        "sealed for another node",
+			value:       "secret:sealed/" + sealedForOther,
+			errContains: "could not be opened",
+		},
+		{
+			name:        "missing secret",
+			value:       "secret:static/db/user",
+			errContains: "not found",
+		},
+		{
+			name:        "unknown backend",
+			value:       "secret:orchestrator/token",
+			errContains: "not configured",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			val, err := s.resolver.Value(tt.value)
+			if tt.errContains != "" {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tt.errContains)
+				s.NotContains(err.Error(), sealedForOther)
+			} else {
+				s.NoError(err)
+				s.Equal(tt.expected, val)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package env
+
+import (
+	"crypto/rsa"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/crypto"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+// SealedSecretBackend opens secrets delivered by the orchestrator encrypted to this node's key.
+// The orchestrator replaces "secret:orchestrator/<name>" references with "secret:sealed/<payload>"
+// when it assigns an execution to the node, so the key of a sealed reference is the payload itself.
+type SealedSecretBackend struct {
+	key *rsa.PrivateKey
+}
+
+// NewSealedSecretBackend creates a new sealed secret backend using the node's private key
+func NewSealedSecretBackend(key *rsa.PrivateKey) *SealedSecretBackend {
+	return &SealedSecretBackend{key: key}
+}
+
+func (s *SealedSecretBackend) Name() string {
+	return models.SecretBackendSealed
+}
+
+// Get decrypts the sealed payload
+func (s *SealedSecretBackend) Get(key string) (string, error) {
+	value, err := crypto.Unseal(s.key, key)
+	if err != nil {
+		// the payload is deliberately left out of the error
+		return "", newErrSealedSecret()
+	}
+	return string(value), nil
+}
+
+// compile-time check for interface implementation
+var _ SecretBackend = &SealedSecretBackend{}
@@ -0,0 +1,135 @@
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// VaultSecretBackendName is the name of the Vault secret backend
+	VaultSecretBackendName = "vault"
+
+	// vaultFieldDelimiter separates the secret path from the field to read, e.g. kv/data/app#password
+	vaultFieldDelimiter = "#"
+	// vaultDefaultField is the field read when a reference does not name one
+	vaultDefaultField = "value"
+
+	vaultTokenHeader     = "X-Vault-Token" //nolint:gosec // G101: header name, not a credential
+	vaultNamespaceHeader = "X-Vault-Namespace"
+	vaultDefaultTimeout  = 10 * time.Second
+	vaultMaxResponseSize = 1 << 20
+)
+
+// VaultSecretBackend reads secrets from a HashiCorp Vault compatible HTTP API.
+// Secret keys have the form "<path>[#<field>]", where path is the API path of the secret
+// without the /v1/ prefix, such as "secret/data/app#password".
+// Both KV version 1 and version 2 responses are supported.
+type VaultSecretBackend struct {
+	address   string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// VaultSecretBackendParams contains the configuration of a VaultSecretBackend
+type VaultSecretBackendParams struct {
+	// Address is the base URL of the Vault server, e.g. https://vault.example.com:8200
+	Address string
+	// Token is the Vault token used to authenticate requests
+	Token string
+	// Namespace is the optional Vault enterprise namespace
+	Namespace string
+	// Timeout bounds each request to Vault. Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+// NewVaultSecretBackend creates a new Vault secret backend
+func NewVaultSecretBackend(params VaultSecretBackendParams) (*VaultSecretBackend, error) {
+	if _, err := url.ParseRequestURI(params.Address); err != nil {
+		return nil, fmt.Errorf("invalid vault address %q: %w", params.Address, err)
+	}
+	if params.Timeout <= 0 {
+		params.Timeout = vaultDefaultTimeout
+	}
+	return &VaultSecretBackend{
+		address:   strings.TrimSuffix(params.Address, "/"),
+		token:     params.Token,
+		namespace: params.Namespace,
+		client:    &http.Client{Timeout: params.Timeout},
+	}, nil
+}
+
+func (v *VaultSecretBackend) Name() string {
+	return VaultSecretBackendName
+}
+
+// Get reads the referenced field of a secret from Vault
+func (v *VaultSecretBackend) Get(key string) (string, error) {
+	path, field, found := strings.Cut(key, vaultFieldDelimiter)
+	if !found || field == "" {
+		field = vaultDefaultField
+	}
+	path = strings.Trim(path, "/")
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, v.address+"/v1/"+path, nil)
+	if err != nil {
+		return "", newErrSecretBackend(v.Name(), err)
+	}
+	if v.token != "" {
+		req.Header.Set(vaultTokenHeader, v.token)
+	}
+	if v.namespace != "" {
+		req.Header.Set(vaultNamespaceHeader, v.namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", newErrSecretBackend(v.Name(), err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", newErrSecretNotFound(v.Name(), key)
+	case resp.StatusCode != http.StatusOK:
+		return "", newErrSecretBackend(v.Name(), fmt.Errorf("unexpected status %s reading %s", resp.Status, path))
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err = json.NewDecoder(io.LimitReader(resp.Body, vaultMaxResponseSize)).Decode(&body); err != nil {
+		return "", newErrSecretBackend(v.Name(), fmt.Errorf("decoding response for %s: %w", path, err))
+	}
+
+	data := body.Data
+	// KV version 2 nests the secret data under data.data
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+
+	value, ok := data[field]
+	if !ok || value == nil {
+		return "", newErrSecretNotFound(v.Name(), key)
+	}
+	if s, isString := value.(string); isString {
+		return s, nil
+	}
+	// non-string fields are passed to the task as JSON
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", newErrSecretBackend(v.Name(), err)
+	}
+	return string(encoded), nil
+}
+
+// compile-time check for interface implementation
+var _ SecretBackend = &VaultSecretBackend{}
@@ -0,0 +1,119 @@
+//go:build unit || !integration
+
+package env
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type VaultSecretBackendSuite struct {
+	suite.Suite
+	server  *httptest.Server
+	backend *VaultSecretBackend
+}
+
+func TestVaultSecretBackendSuite(t *testing.T) {
+	suite.Run(t, new(VaultSecretBackendSuite))
+}
+
+// SetupTest starts a minimal stand-in for the Vault KV HTTP API
+func (s *VaultSecretBackendSuite) SetupTest() {
+	secrets := map[string]any{
+		// KV version 2
+		"/v1/secret/data/app": map[string]any{
+			"data":     map[string]any{"password": "hunter2", "port": 5432},
+			"metadata": map[string]any{"version": 1},
+		},
+		// KV version 1
+		"/v1/kv/legacy": map[string]any{"value": "legacy-value"},
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(vaultTokenHeader) != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		data, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	s.T().Cleanup(s.server.Close)
+
+	var err error
+	s.backend, err = NewVaultSecretBackend(VaultSecretBackendParams{
+		Address: s.server.URL,
+		Token:   "test-token",
+	})
+	s.Require().NoError(err)
+}
+
+func (s *VaultSecretBackendSuite) TestGet() {
+	tests := []struct {
+		name        string
+		key         string
+		expected    string
+		errContains string
+	}{
+		{
+			name:     "kv v2 field",
+			key:      "secret/data/app#password",
+			expected: "hunter2",
+		},
+		{
+			name:     "non string field",
+			key:      "secret/data/app#port",
+			expected: "5432",
+		},
+		{
+			name:     "kv v1 default field",
+			key:      "kv/legacy",
+			expected: "legacy-value",
+		},
+		{
+			name:        "missing field",
+			key:         "secret/data/app#user",
+			errContains: "not found",
+		},
+		{
+			name:        "missing path",
+			key:         "secret/data/other#password",
+			errContains: "not found",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			val, err := s.backend.Get(tt.key)
+			if tt.errContains != "" {
+				s.ErrorContains(err, tt.errContains)
+			} else {
+				s.NoError(err)
+				s.Equal(tt.expected, val)
+			}
+		})
+	}
+}
+
+func (s *VaultSecretBackendSuite) TestUnauthorized() {
+	backend, err := NewVaultSecretBackend(VaultSecretBackendParams{
+		Address: s.server.URL,
+		Token:   "wrong-token",
+	})
+	s.Require().NoError(err)
+
+	_, err = backend.Get("secret/data/app#password")
+	s.ErrorContains(err, "403")
+}
+
+func (s *VaultSecretBackendSuite) TestInvalidAddress() {
+	_, err := NewVaultSecretBackend(VaultSecretBackendParams{Address: "not a url"})
+	s.Error(err)
+}
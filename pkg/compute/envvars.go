@@ -54,6 +54,14 @@ func GetExecutionEnvVars(execution *models.Execution, resolver EnvVarResolver) (
 		sysEnv[models.EnvVarPrefix+"PARTITION_INDEX"] = fmt.Sprintf("%d", execution.PartitionIndex)
 		sysEnv[models.EnvVarPrefix+"PARTITION_COUNT"] = fmt.Sprintf("%d", execution.Job.Count)
 
+		// Add checkpoint-related environment variables
+		if execution.Job.Task().Checkpoint.IsEnabled() {
+			sysEnv[models.EnvVarPrefix+"CHECKPOINT_DIR"] = models.CheckpointPath
+		}
+		if execution.RestoreFrom != nil {
+			sysEnv[models.EnvVarPrefix+"RESTORE_DIR"] = execution.RestoreFrom.Target
+		}
+
 		// Add port-related environment variables
 		if execution.Job.Task().Network != nil {
 			for _, port := range execution.Job.Task().Network.Ports {
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dustin/go-humanize"
+
 	"github.com/bacalhau-project/bacalhau/pkg/models"
 )
 
@@ -31,3 +33,64 @@ func (e ErrExecTimeout) Details() map[string]string {
 		models.DetailsKeyHint: "Increase the task timeout or allocate more resources",
 	}
 }
+
+// ErrResultsTooLarge is an error that is returned when the results of an execution exceed
+// the max size of its output policy.
+type ErrResultsTooLarge struct {
+	Size    int64
+	MaxSize uint64
+}
+
+func NewErrResultsTooLarge(size int64, maxSize uint64) ErrResultsTooLarge {
+	return ErrResultsTooLarge{
+		Size:    size,
+		MaxSize: maxSize,
+	}
+}
+
+func (e ErrResultsTooLarge) Error() string {
+	return fmt.Sprintf("Execution results of %s exceed the max size of %s",
+		humanize.IBytes(uint64(e.Size)), humanize.IBytes(e.MaxSize)) //nolint:gosec // G115: sizes are never negative
+}
+
+func (e ErrResultsTooLarge) Retryable() bool {
+	return false
+}
+
+func (e ErrResultsTooLarge) Details() map[string]string {
+	return map[string]string{
+		models.DetailsKeyHint: "Filter the results with the output policy of the task, raise its max size, " +
+			"or truncate or publish partial results when it is exceeded",
+	}
+}
+
+// ErrVolumeTooLarge is an error that is returned when a persistent volume of an execution exceeds
+// its max size when the execution starts or while it runs.
+type ErrVolumeTooLarge struct {
+	Name    string
+	Size    uint64
+	MaxSize uint64
+}
+
+func NewErrVolumeTooLarge(name string, size uint64, maxSize uint64) ErrVolumeTooLarge {
+	return ErrVolumeTooLarge{
+		Name:    name,
+		Size:    size,
+		MaxSize: maxSize,
+	}
+}
+
+func (e ErrVolumeTooLarge) Error() string {
+	return fmt.Sprintf("Persistent volume %q of %s exceeds its max size of %s",
+		e.Name, humanize.IBytes(e.Size), humanize.IBytes(e.MaxSize))
+}
+
+func (e ErrVolumeTooLarge) Retryable() bool {
+	return false
+}
+
+func (e ErrVolumeTooLarge) Details() map[string]string {
+	return map[string]string{
+		models.DetailsKeyHint: "Raise the max size of the volume, or delete the volume with 'bacalhau node volume delete'",
+	}
+}
@@ -1,7 +1,13 @@
 package compute
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+
 	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
 )
 
 const (
@@ -11,6 +17,7 @@ const (
 	EventTopicExecutionPreparing   models.EventTopic = "Preparing Environment"
 	EventTopicExecutionRunning     models.EventTopic = "Running Execution"
 	EventTopicExecutionPublishing  models.EventTopic = "Publishing Results"
+	EventTopicExecutionCheckpoint  models.EventTopic = "Checkpoint"
 	EventTopicRestart              models.EventTopic = "Restart"
 )
 
@@ -18,12 +25,48 @@ const (
 	execCompletedMessage        = "Completed successfully"
 	execRunningMessage          = "Running"
 	execFailingDueToNodeRestart = "Failing due to node restart"
+	execCheckpointedMessage     = "Published checkpoint %d"
+	execInputResolvedMessage    = "Resolved %s input at %s"
+	execResultsExcludedMessage  = "Excluded %d result files not matching the output policy"
+	execResultsTruncatedMessage = "Truncated results to the max size of %s, dropping %d files"
 )
 
 func ExecCompletedEvent() *models.Event {
 	return models.NewEvent(EventTopicExecution).WithMessage(execCompletedMessage)
 }
 
+// ExecCheckpointedEvent returns an event indicating that the execution published a checkpoint of its state
+func ExecCheckpointedEvent(checkpoint *models.Checkpoint) *models.Event {
+	return models.NewEvent(EventTopicExecutionCheckpoint).
+		WithMessage(fmt.Sprintf(execCheckpointedMessage, checkpoint.Sequence)).
+		WithDetail("Sequence", fmt.Sprintf("%d", checkpoint.Sequence))
+}
+
+// ExecInputResolvedEvent returns an event recording how an input of the execution was resolved,
+// such as the commit a git ref resolved to, so that the execution can be reproduced
+func ExecInputResolvedEvent(input storage.PreparedStorage) *models.Event {
+	return models.NewEvent(EventTopicExecutionDownloading).
+		WithMessage(fmt.Sprintf(execInputResolvedMessage, input.InputSource.Source.Type, input.Volume.Target)).
+		WithDetails(input.Volume.Details)
+}
+
+// ExecResultsFilteredEvent returns an event recording the result files the output policy of the task
+// excluded, and whether results were truncated to its max size
+func ExecResultsFilteredEvent(outcome outputPolicyOutcome, policy *models.OutputPolicy) *models.Event {
+	var messages []string
+	if outcome.excluded > 0 {
+		messages = append(messages, fmt.Sprintf(execResultsExcludedMessage, outcome.excluded))
+	}
+	if outcome.truncated {
+		messages = append(messages, fmt.Sprintf(execResultsTruncatedMessage, policy.MaxSize, outcome.dropped))
+	}
+	return models.NewEvent(EventTopicExecutionPublishing).
+		WithMessage(strings.Join(messages, ". ")).
+		WithDetail("ResultsSize", humanize.IBytes(uint64(outcome.size))). //nolint:gosec // G115: sizes are never negative
+		WithDetail("ResultsExcluded", fmt.Sprintf("%d", outcome.excluded)).
+		WithDetail("ResultsDropped", fmt.Sprintf("%d", outcome.dropped))
+}
+
 func ExecRunningEvent() *models.Event {
 	return models.NewEvent(EventTopicExecution).WithMessage(execRunningMessage)
 }
@@ -0,0 +1,23 @@
+package execstream
+
+import (
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+const errComponent = "ExecStream"
+
+func newErrExecutionNotRunning(executionID string, state models.ExecutionStateType) bacerrors.Error {
+	return bacerrors.Newf("execution %s is %s, and sessions can only be opened into running executions",
+		executionID, state).
+		WithCode(executor.ExecutionNotRunning).
+		WithHTTPStatusCode(http.StatusConflict).
+		WithComponent(errComponent)
+}
+
+func newErrSessionFailed(executionID string, err error) bacerrors.Error {
+	return bacerrors.Wrapf(err, "failed to open session into execution %s", executionID)
+}
@@ -0,0 +1,200 @@
+package execstream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/concurrency"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/models/messages"
+)
+
+const (
+	// defaultBuffer is the default size of the output channel of a session
+	defaultBuffer = 64
+
+	// readBufferSize is the maximum size of a single output frame
+	readBufferSize = 32 * 1024
+)
+
+type ServerParams struct {
+	ExecutionStore store.ExecutionStore
+	Executors      executor.ExecProvider
+	Buffer         int // If not set (0), defaultBuffer will be used.
+}
+
+type server struct {
+	executionStore store.ExecutionStore
+	executors      executor.ExecProvider
+	buffer         int
+}
+
+// NewServer creates a new server of interactive sessions into the executions of this compute node
+func NewServer(params ServerParams) Server {
+	if params.Buffer <= 0 {
+		params.Buffer = defaultBuffer
+	}
+	return &server{
+		executionStore: params.ExecutionStore,
+		executors:      params.Executors,
+		buffer:         params.Buffer,
+	}
+}
+
+// Exec opens an interactive session into a running execution
+func (s *server) Exec(ctx context.Context, request messages.ExecRequest, input <-chan models.ExecInput) (
+	<-chan *concurrency.AsyncResult[models.ExecOutput], error) {
+	execution, err := s.executionStore.GetExecution(ctx, request.ExecutionID)
+	if err != nil {
+		return nil, err
+	}
+	if execution.ComputeState.StateType != models.ExecutionStateRunning {
+		return nil, newErrExecutionNotRunning(execution.ID, execution.ComputeState.StateType)
+	}
+
+	jobExecutor, err := s.executors.Get(ctx, execution.Job.Task().Engine.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	execRequest := &executor.ExecRequest{
+		ExecutionID: execution.ID,
+		Command:     request.Command,
+		TTY:         request.TTY,
+		Stdin:       request.Stdin,
+		Height:      request.Height,
+		Width:       request.Width,
+	}
+	var session executor.ExecSession
+	if request.Attach {
+		session, err = jobExecutor.Attach(ctx, execRequest)
+	} else {
+		session, err = jobExecutor.Exec(ctx, execRequest)
+	}
+	if err != nil {
+		return nil, newErrSessionFailed(execution.ID, err)
+	}
+
+	log.Ctx(ctx).Info().
+		Str("execution", execution.ID).
+		Str("session", request.SessionID).
+		Bool("attach", request.Attach).
+		Msg("opened interactive session")
+
+	out := make(chan *concurrency.AsyncResult[models.ExecOutput], s.buffer)
+	out <- &concurrency.AsyncResult[models.ExecOutput]{Value: models.ExecOutput{Type: models.ExecOutputTypeStarted}}
+
+	// Close the session when the caller goes away, which unblocks the output readers
+	go func() {
+		<-ctx.Done()
+		_ = session.Close()
+	}()
+	go s.applyInput(ctx, session, input)
+	go s.streamOutput(ctx, request.SessionID, session, out)
+	return out, nil
+}
+
+// applyInput writes the input frames to the session until the input is exhausted
+func (s *server) applyInput(ctx context.Context, session executor.ExecSession, input <-chan models.ExecInput) {
+	stdin := session.Stdin()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case frame, ok := <-input:
+			if !ok {
+				if stdin != nil {
+					_ = stdin.Close()
+				}
+				return
+			}
+			if frame.Height > 0 && frame.Width > 0 {
+				if err := session.Resize(ctx, frame.Height, frame.Width); err != nil {
+					log.Ctx(ctx).Debug().Err(err).Msg("failed to resize session terminal")
+				}
+			}
+			if stdin == nil {
+				continue
+			}
+			if len(frame.Data) > 0 {
+				if _, err := stdin.Write(frame.Data); err != nil {
+					log.Ctx(ctx).Debug().Err(err).Msg("failed to write session input")
+					return
+				}
+			}
+			if frame.CloseStdin {
+				_ = stdin.Close()
+				stdin = nil
+			}
+		}
+	}
+}
+
+// streamOutput forwards the output of the session, followed by its exit code
+func (s *server) streamOutput(
+	ctx context.Context, sessionID string, session executor.ExecSession,
+	out chan<- *concurrency.AsyncResult[models.ExecOutput],
+) {
+	defer close(out)
+	defer func() { _ = session.Close() }()
+
+	send := func(result *concurrency.AsyncResult[models.ExecOutput]) bool {
+		select {
+		case out <- result:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var wg sync.WaitGroup
+	pump := func(reader io.Reader, outputType models.ExecOutputType) {
+		defer wg.Done()
+		buf := make([]byte, readBufferSize)
+		for {
+			n, err := reader.Read(buf)
+			if n > 0 {
+				data := make([]byte, n)
+				copy(data, buf[:n])
+				if !send(&concurrency.AsyncResult[models.ExecOutput]{
+					Value: models.ExecOutput{Type: outputType, Data: data},
+				}) {
+					return
+				}
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+					log.Ctx(ctx).Debug().Err(err).Str("session", sessionID).Msg("session output ended with error")
+				}
+				return
+			}
+		}
+	}
+
+	wg.Add(1)
+	go pump(session.Stdout(), models.ExecOutputTypeSTDOUT)
+	if stderr := session.Stderr(); stderr != nil {
+		wg.Add(1)
+		go pump(stderr, models.ExecOutputTypeSTDERR)
+	}
+	wg.Wait()
+
+	exitCode, err := session.Wait(ctx)
+	if err != nil {
+		send(&concurrency.AsyncResult[models.ExecOutput]{Err: err})
+		return
+	}
+	log.Ctx(ctx).Info().Str("session", sessionID).Int("exit_code", exitCode).Msg("interactive session ended")
+	send(&concurrency.AsyncResult[models.ExecOutput]{
+		Value: models.ExecOutput{Type: models.ExecOutputTypeExit, ExitCode: exitCode},
+	})
+}
+
+// compile time check
+var _ Server = &server{}
@@ -0,0 +1,182 @@
+//go:build unit || !integration
+
+package execstream
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store/boltdb"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/noop"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/provider"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/models/messages"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+type ServerSuite struct {
+	suite.Suite
+	store     store.ExecutionStore
+	executor  *fakeExecutor
+	server    Server
+	execution *models.Execution
+}
+
+func TestServerSuite(t *testing.T) {
+	suite.Run(t, new(ServerSuite))
+}
+
+func (s *ServerSuite) SetupTest() {
+	ctx := context.Background()
+	execStore, err := boltdb.NewStore(ctx, filepath.Join(s.T().TempDir(), "execstream-test.db"))
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = execStore.Close(ctx) })
+	s.store = execStore
+
+	s.executor = &fakeExecutor{NoopExecutor: noop.NewNoopExecutor()}
+	s.execution = mock.Execution()
+	s.Require().NoError(s.store.CreateExecution(ctx, *s.execution))
+	s.setState(models.ExecutionStateRunning)
+	s.server = NewServer(ServerParams{
+		ExecutionStore: s.store,
+		Executors: provider.NewMappedProvider(map[string]executor.Executor{
+			s.execution.Job.Task().Engine.Type: s.executor,
+		}),
+	})
+}
+
+func (s *ServerSuite) setState(state models.ExecutionStateType) {
+	s.Require().NoError(s.store.UpdateExecutionState(context.Background(), store.UpdateExecutionRequest{
+		ExecutionID: s.execution.ID,
+		NewValues:   models.Execution{ComputeState: models.NewExecutionState(state)},
+	}))
+}
+
+func (s *ServerSuite) TestExec() {
+	session := newFakeSession("hello", "oops", 3)
+	s.executor.session = session
+
+	input := make(chan models.ExecInput, 1)
+	input <- models.ExecInput{Data: []byte("ls\n"), CloseStdin: true}
+
+	output, err := s.server.Exec(context.Background(), messages.ExecRequest{
+		ExecutionID: s.execution.ID,
+		Command:     []string{"sh"},
+		Stdin:       true,
+	}, input)
+	s.Require().NoError(err)
+
+	var stdout, stderr bytes.Buffer
+	var frames []models.ExecOutput
+	for result := range output {
+		s.Require().NoError(result.Err)
+		frames = append(frames, result.Value)
+		switch result.Value.Type {
+		case models.ExecOutputTypeSTDOUT:
+			stdout.Write(result.Value.Data)
+		case models.ExecOutputTypeSTDERR:
+			stderr.Write(result.Value.Data)
+		default:
+		}
+	}
+
+	s.Require().NotEmpty(frames)
+	s.Equal(models.ExecOutputTypeStarted, frames[0].Type)
+	s.Equal(models.ExecOutput{Type: models.ExecOutputTypeExit, ExitCode: 3}, frames[len(frames)-1])
+	s.Equal("hello", stdout.String())
+	s.Equal("oops", stderr.String())
+	s.Equal([]string{"sh"}, s.executor.request.Command)
+	s.Equal("ls\n", session.stdinData())
+}
+
+func (s *ServerSuite) TestExecNotRunning() {
+	s.setState(models.ExecutionStateCompleted)
+
+	_, err := s.server.Exec(context.Background(), messages.ExecRequest{
+		ExecutionID: s.execution.ID,
+		Command:     []string{"sh"},
+	}, nil)
+	s.Require().Error(err)
+	s.True(bacerrors.IsErrorWithCode(err, executor.ExecutionNotRunning))
+}
+
+func (s *ServerSuite) TestAttachNotSupported() {
+	_, err := s.server.Exec(context.Background(), messages.ExecRequest{
+		ExecutionID: s.execution.ID,
+		Attach:      true,
+	}, nil)
+	s.Require().Error(err)
+	s.True(bacerrors.IsErrorWithCode(err, executor.ExecNotSupported))
+}
+
+// fakeExecutor serves exec sessions from a canned session, and relies on the noop executor otherwise
+type fakeExecutor struct {
+	*noop.NoopExecutor
+	session *fakeSession
+	request *executor.ExecRequest
+}
+
+func (e *fakeExecutor) Exec(ctx context.Context, request *executor.ExecRequest) (executor.ExecSession, error) {
+	e.request = request
+	return e.session, nil
+}
+
+type fakeSession struct {
+	stdinWriter *io.PipeWriter
+	stdin       bytes.Buffer
+	stdinDone   chan struct{}
+	mu          sync.Mutex
+	stdout      io.Reader
+	stderr      io.Reader
+	exitCode    int
+}
+
+func newFakeSession(stdout, stderr string, exitCode int) *fakeSession {
+	reader, writer := io.Pipe()
+	session := &fakeSession{
+		stdinWriter: writer,
+		stdinDone:   make(chan struct{}),
+		stdout:      strings.NewReader(stdout),
+		stderr:      strings.NewReader(stderr),
+		exitCode:    exitCode,
+	}
+	go func() {
+		defer close(session.stdinDone)
+		data, _ := io.ReadAll(reader)
+		session.mu.Lock()
+		session.stdin.Write(data)
+		session.mu.Unlock()
+	}()
+	return session
+}
+
+func (f *fakeSession) stdinData() string {
+	select {
+	case <-f.stdinDone:
+	case <-time.After(time.Second):
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stdin.String()
+}
+
+func (f *fakeSession) Stdin() io.WriteCloser { return f.stdinWriter }
+func (f *fakeSession) Stdout() io.Reader     { return f.stdout }
+func (f *fakeSession) Stderr() io.Reader     { return f.stderr }
+
+func (f *fakeSession) Resize(ctx context.Context, height, width uint) error { return nil }
+
+func (f *fakeSession) Wait(ctx context.Context) (int, error) { return f.exitCode, nil }
+
+func (f *fakeSession) Close() error { return nil }
@@ -0,0 +1,19 @@
+package execstream
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/concurrency"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/models/messages"
+)
+
+// Server is an interface for interactive sessions into running executions
+type Server interface {
+	// Exec opens an interactive session into a running execution.
+	// Input frames are applied to the session until the input channel is closed or the context is done.
+	// The returned stream starts with an ExecOutputTypeStarted frame once the session is established,
+	// and ends with an ExecOutputTypeExit frame carrying the exit code of the command.
+	Exec(ctx context.Context, request messages.ExecRequest, input <-chan models.ExecInput) (
+		<-chan *concurrency.AsyncResult[models.ExecOutput], error)
+}
@@ -2,9 +2,12 @@ package compute
 
 import (
 	"context"
+	"crypto/rsa"
 	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -16,6 +19,7 @@ import (
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/manifest"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 )
@@ -35,7 +39,14 @@ type BaseExecutorParams struct {
 	Publishers             publisher.PublisherProvider
 	FailureInjectionConfig models.FailureInjectionConfig
 	EnvResolver            EnvVarResolver
+	SecretRedactor         *SecretRedactor
 	PortAllocator          PortAllocator
+	// ManifestKey signs the manifests written with published results. Optional.
+	// Results are published without a manifest if not set.
+	ManifestKey *rsa.PrivateKey
+	// Volumes manages the persistent volumes of long running jobs. Optional.
+	// Executions with persistent volumes fail if not set.
+	Volumes *VolumeManager
 
 	// TODO: this is a temporary solution and should be replaced with a more generic
 	//  solution to populate jobs with default resources and network config.
@@ -55,7 +66,10 @@ type BaseExecutor struct {
 	resultsPath        ResultsPath
 	failureInjection   models.FailureInjectionConfig
 	envResolver        EnvVarResolver
+	secretRedactor     *SecretRedactor
 	portAllocator      PortAllocator
+	manifestKey        *rsa.PrivateKey
+	volumes            *VolumeManager
 	defaultNetworkType models.Network
 }
 
@@ -70,7 +84,10 @@ func NewBaseExecutor(params BaseExecutorParams) *BaseExecutor {
 		failureInjection:   params.FailureInjectionConfig,
 		resultsPath:        params.ResultsPath,
 		envResolver:        params.EnvResolver,
+		secretRedactor:     params.SecretRedactor,
 		portAllocator:      params.PortAllocator,
+		manifestKey:        params.ManifestKey,
+		volumes:            params.Volumes,
 		defaultNetworkType: params.DefaultNetworkType,
 	}
 }
@@ -79,8 +96,13 @@ func (e *BaseExecutor) prepareInputVolumes(
 	ctx context.Context,
 	execution *models.Execution,
 ) ([]storage.PreparedStorage, func(context.Context) error, error) {
+	inputSources := execution.Job.Task().InputSources
+	if execution.RestoreFrom != nil {
+		// mount the checkpoint of the previous execution alongside the task's own inputs
+		inputSources = append(slices.Clip(inputSources), execution.RestoreFrom)
+	}
 	inputVolumes, err := storage.ParallelPrepareStorage(
-		ctx, e.Storages, e.storageDirectory, execution, execution.Job.Task().InputSources...)
+		ctx, e.Storages, e.storageDirectory, execution, inputSources...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -89,6 +111,70 @@ func (e *BaseExecutor) prepareInputVolumes(
 	}, nil
 }
 
+// mountVolumes mounts the persistent volumes of the execution's task, and returns a function
+// releasing them when the execution ends
+func (e *BaseExecutor) mountVolumes(
+	ctx context.Context,
+	execution *models.Execution,
+) ([]storage.StorageVolume, func(context.Context) error, error) {
+	if len(execution.Job.Task().Volumes) == 0 {
+		return nil, func(context.Context) error { return nil }, nil
+	}
+	if e.volumes == nil {
+		return nil, nil, bacerrors.New("persistent volumes are not supported by this node").
+			WithCode(bacerrors.NotImplemented)
+	}
+	volumes, err := e.volumes.MountVolumes(ctx, execution)
+	if err != nil {
+		return nil, nil, err
+	}
+	return volumes, func(ctx context.Context) error {
+		e.volumes.ReleaseVolumes(ctx, execution)
+		return nil
+	}, nil
+}
+
+// startVolumeChecks periodically measures the persistent volumes of a running execution, and cancels the
+// execution once one of them exceeds its maximum size. The returned function stops the checks and returns
+// the error of the volume that exceeded its maximum size, if any.
+func (e *BaseExecutor) startVolumeChecks(ctx context.Context, execution *models.Execution) func() error {
+	if len(execution.Job.Task().Volumes) == 0 || e.volumes == nil {
+		return func() error { return nil }
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	var volumeErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(e.volumes.CheckInterval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if volumeErr = e.volumes.MeasureVolumes(ctx, execution); volumeErr == nil {
+					continue
+				}
+				log.Ctx(ctx).Warn().Err(volumeErr).Msg("stopping execution with persistent volume above its max size")
+				if err := e.Cancel(ctx, execution); err != nil {
+					log.Ctx(ctx).Error().Err(err).Msg("failed to stop execution with persistent volume above its max size")
+				}
+				return
+			}
+		}
+	}()
+
+	return func() error {
+		cancel()
+		wg.Wait()
+		return volumeErr
+	}
+}
+
 // InputCleanupFn is a function type that defines the contract for cleaning up
 // resources associated with input volume data after the job execution has either completed
 // or failed to start. The function is expected to take a context.Context as an argument,
@@ -114,6 +200,21 @@ func (e *BaseExecutor) PrepareRunArguments(
 	}
 	cleanupFuncs = append(cleanupFuncs, inputCleanup)
 
+	inputVolumes, err = prepareInputOverlays(inputVolumes, executionDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanupFuncs = append(cleanupFuncs, func(context.Context) error {
+		// unpublished writable layers are discarded as soon as the execution ends
+		return os.RemoveAll(ExecutionOverlaysDir(executionDir))
+	})
+
+	volumes, volumesCleanup, err := e.mountVolumes(ctx, execution)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanupFuncs = append(cleanupFuncs, volumesCleanup)
+
 	// Allocate ports
 	portMappings, err := e.portAllocator.AllocatePorts(execution)
 	if err != nil {
@@ -131,6 +232,17 @@ func (e *BaseExecutor) PrepareRunArguments(
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to resolve environment variables: %w", err)
 	}
+	if e.secretRedactor != nil {
+		e.secretRedactor.Track(execution.ID, SecretEnvValues(execution, env)...)
+	}
+
+	var checkpointDir string
+	if execution.Job.Task().Checkpoint.IsEnabled() {
+		checkpointDir = ExecutionCheckpointDir(executionDir)
+		if err = os.MkdirAll(checkpointDir, StorageDirectoryPerms); err != nil {
+			return nil, nil, fmt.Errorf("failed to create checkpoint dir for execution: %w", err)
+		}
+	}
 
 	networkConfig := execution.Job.Task().Network
 	if networkConfig.Type == models.NetworkDefault {
@@ -140,6 +252,7 @@ func (e *BaseExecutor) PrepareRunArguments(
 	return &executor.RunCommandRequest{
 			JobID:        execution.Job.ID,
 			ExecutionID:  execution.ID,
+			Namespace:    execution.Job.Namespace,
 			Resources:    execution.TotalAllocatedResources(),
 			Network:      networkConfig,
 			Outputs:      execution.Job.Task().ResultPaths,
@@ -153,6 +266,10 @@ func (e *BaseExecutor) PrepareRunArguments(
 				MaxStderrFileLength:   system.MaxStderrFileLength,
 				MaxStderrReturnLength: system.MaxStderrReturnLength,
 			},
+			CheckpointDir: checkpointDir,
+			Volumes:       volumes,
+			StopSignal:    execution.Job.Task().GetStopSignal(),
+			KillTimeout:   execution.Job.Task().GetKillTimeout(),
 		}, func(ctx context.Context) error {
 			var cleanupErr error
 			for _, cleanupFunc := range cleanupFuncs {
@@ -164,9 +281,22 @@ func (e *BaseExecutor) PrepareRunArguments(
 		}, nil
 }
 
+// inputResolvedEvents returns the events recording how inputs were resolved, for inputs with details
+func inputResolvedEvents(inputs []storage.PreparedStorage) []*models.Event {
+	var events []*models.Event
+	for _, input := range inputs {
+		if len(input.Volume.Details) > 0 {
+			events = append(events, ExecInputResolvedEvent(input))
+		}
+	}
+	return events
+}
+
 type StartResult struct {
 	cleanup InputCleanupFn
-	Err     error
+	// inputs are the prepared inputs of the execution, recorded in the manifest of its results
+	inputs []storage.PreparedStorage
+	Err    error
 }
 
 func (r *StartResult) Cleanup(ctx context.Context) error {
@@ -196,6 +326,7 @@ func (e *BaseExecutor) Start(ctx context.Context, execution *models.Execution) *
 		result.Err = fmt.Errorf("preparing arguments: %w", err)
 		return result
 	}
+	result.inputs = args.Inputs
 
 	if err = e.store.UpdateExecutionState(ctx, store.UpdateExecutionRequest{
 		ExecutionID: execution.ID,
@@ -208,6 +339,7 @@ func (e *BaseExecutor) Start(ctx context.Context, execution *models.Execution) *
 		NewValues: models.Execution{
 			ComputeState: models.NewExecutionState(models.ExecutionStateRunning),
 		},
+		Events: inputResolvedEvents(args.Inputs),
 	}); err != nil {
 		result.Err = fmt.Errorf("updating execution state from expected: %s to: %s",
 			models.ExecutionStateBidAccepted, models.ExecutionStateRunning)
@@ -287,6 +419,9 @@ func (e *BaseExecutor) Run(ctx context.Context, execution *models.Execution) (er
 					return
 				}
 				log.Debug().Str("path", executionOutputDir).Msg("removed execution results dir")
+				if e.secretRedactor != nil {
+					e.secretRedactor.Forget(execution.ID)
+				}
 			}
 		}()
 
@@ -312,7 +447,20 @@ func (e *BaseExecutor) Run(ctx context.Context, execution *models.Execution) (er
 		}
 	}
 
+	stopCheckpoints := e.startCheckpoints(ctx, execution)
+	stopVolumeChecks := e.startVolumeChecks(ctx, execution)
 	result, err := e.Wait(ctx, execution)
+	stopCheckpoints()
+	if volumeErr := stopVolumeChecks(); volumeErr != nil {
+		// the execution was stopped for exceeding the max size of one of its volumes
+		return volumeErr
+	}
+	if err == nil && e.secretRedactor != nil {
+		// the run output is reported to the orchestrator, so it must not carry secrets the task printed
+		result.STDOUT = e.secretRedactor.Redact(execution.ID, result.STDOUT)
+		result.STDERR = e.secretRedactor.Redact(execution.ID, result.STDERR)
+		result.ErrorMsg = e.secretRedactor.Redact(execution.ID, result.ErrorMsg)
+	}
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			// TODO(forrest) [correctness]:
@@ -330,6 +478,10 @@ func (e *BaseExecutor) Run(ctx context.Context, execution *models.Execution) (er
 		}
 		return err
 	}
+	if e.isCancelled(ctx, execution) {
+		e.publishCancelledResults(ctx, execution, res.inputs, result)
+		return executor.NewExecutorError(executor.ExecutionAlreadyCancelled, "execution already cancelled")
+	}
 	if result.ErrorMsg != "" {
 		return fmt.Errorf("%s", result.ErrorMsg)
 	}
@@ -341,6 +493,12 @@ func (e *BaseExecutor) Run(ctx context.Context, execution *models.Execution) (er
 	// publish if the job has a publisher defined
 	if !execution.Job.Task().Publisher.IsEmpty() {
 		topic = EventTopicExecutionPublishing
+		resultsDir := ExecutionResultsDir(e.resultsPath.ExecutionOutputDir(execution.ID))
+		// the output policy is enforced before the manifest is written, so that it only lists published files
+		outputPolicyEvents, err := enforceOutputPolicy(execution.Job.Task(), resultsDir, result)
+		if err != nil {
+			return err
+		}
 		if err = e.store.UpdateExecutionState(ctx, store.UpdateExecutionRequest{
 			ExecutionID: execution.ID,
 			Condition: store.UpdateExecutionCondition{
@@ -350,13 +508,16 @@ func (e *BaseExecutor) Run(ctx context.Context, execution *models.Execution) (er
 				ComputeState: models.NewExecutionState(models.ExecutionStatePublishing),
 				RunOutput:    result,
 			},
+			Events: outputPolicyEvents,
 		}); err != nil {
 			return err
 		}
 
 		expectedState = models.ExecutionStatePublishing
 
-		resultsDir := ExecutionResultsDir(e.resultsPath.ExecutionOutputDir(execution.ID))
+		if err = e.writeManifest(execution, resultsDir, res.inputs); err != nil {
+			return err
+		}
 		publishedResult, err = e.publish(ctx, execution, resultsDir)
 		if err != nil {
 			return err
@@ -415,6 +576,105 @@ func (e *BaseExecutor) publish(ctx context.Context, execution *models.Execution,
 	return &publishedResult, nil
 }
 
+// writeManifest writes the signed manifest of the results at the root of the results directory,
+// so that it is published with the results whatever the publisher. Checkpoints are published
+// without a manifest, as they are restored into the checkpoint directory of later executions.
+func (e *BaseExecutor) writeManifest(
+	execution *models.Execution, resultsDir string, inputs []storage.PreparedStorage) error {
+	if e.manifestKey == nil {
+		return nil
+	}
+	engineDigest, err := manifest.Digest(execution.Job.Task().Engine)
+	if err != nil {
+		return err
+	}
+	m := &manifest.Manifest{
+		Version:          manifest.Version,
+		JobID:            execution.JobID,
+		ExecutionID:      execution.ID,
+		NodeID:           e.ID,
+		EngineSpecDigest: engineDigest,
+		Inputs:           make([]manifest.Input, 0, len(inputs)),
+	}
+	for _, input := range inputs {
+		sourceDigest, err := manifest.Digest(input.InputSource.Source)
+		if err != nil {
+			return err
+		}
+		m.Inputs = append(m.Inputs, manifest.Input{
+			Type:    input.InputSource.Source.Type,
+			Alias:   input.InputSource.Alias,
+			Target:  input.InputSource.Target,
+			Digest:  sourceDigest,
+			Details: input.Volume.Details,
+		})
+	}
+	if m.Files, err = manifest.ListFiles(resultsDir); err != nil {
+		return err
+	}
+	if err = m.Sign(e.manifestKey); err != nil {
+		return bacerrors.Wrap(err, "failed to sign results manifest")
+	}
+	return m.Write(resultsDir)
+}
+
+// isCancelled returns true if the execution was cancelled while it was running
+func (e *BaseExecutor) isCancelled(ctx context.Context, execution *models.Execution) bool {
+	current, err := e.store.GetExecution(ctx, execution.ID)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to get execution state")
+		return false
+	}
+	return current.ComputeState.StateType == models.ExecutionStateCancelled
+}
+
+// publishCancelledResults publishes the results that a cancelled execution wrote during its
+// graceful termination, if its task has a kill timeout and a publisher, and records them on the
+// execution. Failing to publish does not fail the execution as it was already cancelled.
+func (e *BaseExecutor) publishCancelledResults(ctx context.Context,
+	execution *models.Execution, inputs []storage.PreparedStorage, result *models.RunCommandResult) {
+	task := execution.Job.Task()
+	if task.GetKillTimeout() <= 0 || task.Publisher.IsEmpty() {
+		return
+	}
+
+	resultsDir := ExecutionResultsDir(e.resultsPath.ExecutionOutputDir(execution.ID))
+	defer func() {
+		if err := os.RemoveAll(resultsDir); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to remove results directory at %s", resultsDir)
+		}
+	}()
+
+	outputPolicyEvents, err := enforceOutputPolicy(task, resultsDir, result)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to apply output policy to cancelled execution results")
+		return
+	}
+	if err = e.writeManifest(execution, resultsDir, inputs); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to write manifest of cancelled execution results")
+		return
+	}
+	publishedResult, err := e.publish(ctx, execution, resultsDir)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to publish results of cancelled execution")
+		return
+	}
+	if err = e.store.UpdateExecutionState(ctx, store.UpdateExecutionRequest{
+		ExecutionID: execution.ID,
+		Condition: store.UpdateExecutionCondition{
+			ExpectedStates: []models.ExecutionStateType{models.ExecutionStateCancelled},
+		},
+		NewValues: models.Execution{
+			ComputeState:    models.NewExecutionState(models.ExecutionStateCancelled),
+			PublishedResult: publishedResult,
+			RunOutput:       result,
+		},
+		Events: outputPolicyEvents,
+	}); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to record results of cancelled execution")
+	}
+}
+
 // Cancel the execution.
 func (e *BaseExecutor) Cancel(ctx context.Context, execution *models.Execution) error {
 	log.Ctx(ctx).Debug().Str("Execution", execution.ID).Msg("Canceling execution")
@@ -433,7 +693,7 @@ func (e *BaseExecutor) handleFailure(ctx context.Context, execution *models.Exec
 		NewValues: models.Execution{
 			ComputeState: models.NewExecutionState(models.ExecutionStateFailed).WithMessage(err.Error()),
 		},
-		Events: []*models.Event{models.NewEvent(topic).WithError(err)},
+		Events: []*models.Event{models.EventFromError(topic, err)},
 	})
 
 	if updateError != nil {
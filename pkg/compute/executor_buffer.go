@@ -2,15 +2,16 @@ package compute
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/rs/zerolog/log"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
-	"github.com/bacalhau-project/bacalhau/pkg/lib/collections"
 	"github.com/bacalhau-project/bacalhau/pkg/logger"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
 	"github.com/bacalhau-project/bacalhau/pkg/telemetry"
@@ -19,12 +20,15 @@ import (
 type bufferTask struct {
 	execution  *models.Execution
 	enqueuedAt time.Time
+	// sequence is the arrival order of the task, used to break ties between tasks enqueued at the same time
+	sequence uint64
 }
 
-func newBufferTask(execution *models.Execution) *bufferTask {
+func newBufferTask(execution *models.Execution, enqueuedAt time.Time, sequence uint64) *bufferTask {
 	return &bufferTask{
 		execution:  execution,
-		enqueuedAt: time.Now(),
+		enqueuedAt: enqueuedAt,
+		sequence:   sequence,
 	}
 }
 
@@ -34,38 +38,67 @@ type ExecutorBufferParams struct {
 	Store                  store.ExecutionStore
 	RunningCapacityTracker capacity.Tracker
 	EnqueuedUsageTracker   capacity.UsageTracker
+	// QueuePolicy determines the order in which enqueued executions are started. Defaults to DefaultQueuePolicy.
+	QueuePolicy QueuePolicy
+	// AgingInterval is the waiting time after which the priority of an enqueued execution is raised by one
+	// under QueuePolicyAging. Zero disables aging.
+	AgingInterval time.Duration
+	// ReservationThreshold is the waiting time after which an execution that does not fit in the available
+	// capacity gets a capacity reservation under QueuePolicyAging. Zero disables reservations.
+	ReservationThreshold time.Duration
+	Clock                clock.Clock
 }
 
 // ExecutorBuffer is a backend.Executor implementation that buffers executions locally until enough capacity is
 // available to be able to run them. The buffer accepts a delegate backend.Executor that will be used to run the jobs.
-// The buffer is implemented as a FIFO queue, where the order of the executions is determined by the order in which
-// they were enqueued. However, an execution with high resource usage requirements might be skipped if there are newer
-// jobs with lower resource usage requirements that can be executed immediately. This is done to improve utilization
-// of compute nodes, though it might result in starvation and should be re-evaluated in the future.
+// Enqueued executions are ordered by job priority and then by the order in which they were enqueued, and the
+// QueuePolicy decides what happens when the execution at the head of the queue does not fit in the available
+// capacity. With QueuePolicyFIFO it blocks the executions behind it, while QueuePolicyBackfill skips it for smaller
+// executions that can run immediately, improving utilization at the risk of starving large executions.
+// QueuePolicyAging avoids that starvation by raising priorities with waiting time, and by reserving capacity for an
+// execution that has waited past the reservation threshold. While the reservation is held, later executions only
+// start if they leave the reserved execution enough of every kind of resource they use, so that they can still
+// backfill the resources the reserved execution doesn't compete for.
 type ExecutorBuffer struct {
-	ID               string
-	runningCapacity  capacity.Tracker
-	enqueuedCapacity capacity.UsageTracker
-	delegateService  Executor
-	store            store.ExecutionStore
-	running          map[string]*bufferTask
-	queuedTasks      *collections.HashedPriorityQueue[string, *bufferTask]
-	mu               sync.Mutex
+	ID                   string
+	runningCapacity      capacity.Tracker
+	enqueuedCapacity     capacity.UsageTracker
+	delegateService      Executor
+	store                store.ExecutionStore
+	policy               QueuePolicy
+	agingInterval        time.Duration
+	reservationThreshold time.Duration
+	clock                clock.Clock
+	running              map[string]*bufferTask
+	queuedTasks          map[string]*bufferTask
+	enqueuedCount        uint64
+	// reservation is the enqueued task that capacity is currently reserved for, if any
+	reservation *bufferTask
+	mu          sync.Mutex
 }
 
 func NewExecutorBuffer(params ExecutorBufferParams) *ExecutorBuffer {
-	indexer := func(b *bufferTask) string {
-		return b.execution.ID
+	policy := params.QueuePolicy
+	if policy == "" {
+		policy = DefaultQueuePolicy
+	}
+	clk := params.Clock
+	if clk == nil {
+		clk = clock.New()
 	}
 
 	r := &ExecutorBuffer{
-		ID:               params.ID,
-		runningCapacity:  params.RunningCapacityTracker,
-		enqueuedCapacity: params.EnqueuedUsageTracker,
-		delegateService:  params.DelegateExecutor,
-		store:            params.Store,
-		running:          make(map[string]*bufferTask),
-		queuedTasks:      collections.NewHashedPriorityQueue[string, *bufferTask](indexer),
+		ID:                   params.ID,
+		runningCapacity:      params.RunningCapacityTracker,
+		enqueuedCapacity:     params.EnqueuedUsageTracker,
+		delegateService:      params.DelegateExecutor,
+		store:                params.Store,
+		policy:               policy,
+		agingInterval:        params.AgingInterval,
+		reservationThreshold: params.ReservationThreshold,
+		clock:                clk,
+		running:              make(map[string]*bufferTask),
+		queuedTasks:          make(map[string]*bufferTask),
 	}
 
 	return r
@@ -100,7 +133,7 @@ func (s *ExecutorBuffer) Run(ctx context.Context, execution *models.Execution) e
 		return err
 	}
 
-	if s.queuedTasks.Contains(execution.ID) {
+	if _, ok := s.queuedTasks[execution.ID]; ok {
 		err = bacerrors.Newf("execution %s already enqueued", execution.ID)
 		return err
 	}
@@ -109,7 +142,8 @@ func (s *ExecutorBuffer) Run(ctx context.Context, execution *models.Execution) e
 		return err
 	}
 	s.enqueuedCapacity.Add(ctx, *execution.TotalAllocatedResources())
-	s.queuedTasks.Enqueue(newBufferTask(execution), int64(execution.Job.Priority))
+	s.enqueuedCount++
+	s.queuedTasks[execution.ID] = newBufferTask(execution, s.clock.Now(), s.enqueuedCount)
 	s.deque()
 	return err
 }
@@ -148,52 +182,96 @@ func (s *ExecutorBuffer) doRun(ctx context.Context, task *bufferTask) {
 	s.deque()
 }
 
-// deque tries to run the next execution in the queue if there is enough capacity.
+// deque tries to run the enqueued executions in queue order if there is enough capacity.
 // It is called every time a job is finished or enqueued, where a lock is already held.
 // TODO: We loop through the queue every time a job runs or finishes, which is not very efficient.
 func (s *ExecutorBuffer) deque() {
 	ctx := context.Background()
+	now := s.clock.Now()
 
-	// There are at most max matches, so try at most that many times
-	max := s.queuedTasks.Len()
-	for i := 0; i < max; i++ {
-		qItem := s.queuedTasks.DequeueWhere(func(task *bufferTask) bool {
-			// If we don't have enough resources to run this task, then we will skip it
-			queuedResources := task.execution.TotalAllocatedResources()
-			allocatedResources := s.runningCapacity.AddIfHasCapacity(ctx, *queuedResources)
-			if allocatedResources == nil {
-				return false
-			}
-
-			// Update the execution to include all the resources that have
-			// actually been allocated
-			task.execution.AllocateResources(
-				task.execution.Job.Task().Name,
-				*allocatedResources,
-			)
+	// the reservation is re-evaluated on every pass, as aging might have changed the order of the queue
+	s.reservation = nil
+	for _, task := range s.orderedTasks(now) {
+		queuedResources := task.execution.TotalAllocatedResources()
 
-			// Claim the resources now so that we don't count queued resources
-			s.enqueuedCapacity.Remove(ctx, *queuedResources)
-			return true
-		})
+		// Only start executions that don't take capacity the reserved execution is waiting for
+		if s.reservation != nil && !s.fitsAlongsideReservation(ctx, *queuedResources) {
+			continue
+		}
 
-		if qItem == nil {
-			// We didn't find anything in the queue that matches our resource availability so we will
-			// break out of this look as there is nothing else to find
-			break
+		allocatedResources := s.runningCapacity.AddIfHasCapacity(ctx, *queuedResources)
+		if allocatedResources == nil {
+			if s.policy == QueuePolicyFIFO {
+				// executions behind the head of the queue have to wait for it
+				return
+			}
+			if s.policy == QueuePolicyAging && s.reservation == nil &&
+				s.reservationThreshold > 0 && now.Sub(task.enqueuedAt) >= s.reservationThreshold {
+				s.reservation = task
+			}
+			continue
 		}
 
-		task := qItem.Value
+		// Update the execution to include all the resources that have
+		// actually been allocated
+		task.execution.AllocateResources(
+			task.execution.Job.Task().Name,
+			*allocatedResources,
+		)
 
-		// Move the execution to the running list and remove from the list of enqueued IDs
+		// Claim the resources now so that we don't count queued resources
+		s.enqueuedCapacity.Remove(ctx, *queuedResources)
+
+		// Move the execution to the running list and remove it from the queue
 		// before we actually run the task
 		execID := task.execution.ID
+		delete(s.queuedTasks, execID)
 		s.running[execID] = task
 
 		go s.doRun(logger.ContextWithNodeIDLogger(context.Background(), s.ID), task)
 	}
 }
 
+// fitsAlongsideReservation returns true if the given resources leave enough capacity for the reserved execution.
+// For every kind of resource they use, the available capacity must cover them together with the resources of the
+// reserved execution, while the kinds they don't use, such as a GPU the reserved execution waits for, don't matter.
+func (s *ExecutorBuffer) fitsAlongsideReservation(ctx context.Context, resources models.Resources) bool {
+	reserved := s.reservation.execution.TotalAllocatedResources()
+	available := s.runningCapacity.GetAvailableCapacity(ctx)
+	return (resources.CPU == 0 || resources.CPU+reserved.CPU <= available.CPU) &&
+		(resources.Memory == 0 || resources.Memory+reserved.Memory <= available.Memory) &&
+		(resources.Disk == 0 || resources.Disk+reserved.Disk <= available.Disk) &&
+		(resources.GPU == 0 || resources.GPU+reserved.GPU <= available.GPU)
+}
+
+// effectivePriority returns the priority of the task after aging it with its waiting time.
+func (s *ExecutorBuffer) effectivePriority(task *bufferTask, now time.Time) int64 {
+	priority := int64(task.execution.Job.Priority)
+	if s.policy == QueuePolicyAging && s.agingInterval > 0 {
+		priority += int64(now.Sub(task.enqueuedAt) / s.agingInterval)
+	}
+	return priority
+}
+
+// orderedTasks returns the enqueued tasks ordered by their effective priority,
+// and then by the order in which they were enqueued.
+func (s *ExecutorBuffer) orderedTasks(now time.Time) []*bufferTask {
+	tasks := make([]*bufferTask, 0, len(s.queuedTasks))
+	priorities := make(map[string]int64, len(s.queuedTasks))
+	for id, task := range s.queuedTasks {
+		tasks = append(tasks, task)
+		priorities[id] = s.effectivePriority(task, now)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if priorities[a.execution.ID] != priorities[b.execution.ID] {
+			return priorities[a.execution.ID] > priorities[b.execution.ID]
+		}
+		return a.sequence < b.sequence
+	})
+	return tasks
+}
+
 func (s *ExecutorBuffer) Cancel(_ context.Context, execution *models.Execution) error {
 	// TODO: Enqueue cancel tasks
 	go func() {
@@ -221,7 +299,58 @@ func (s *ExecutorBuffer) RunningExecutions() []*models.Execution {
 
 // EnqueuedExecutionsCount return number of items enqueued
 func (s *ExecutorBuffer) EnqueuedExecutionsCount() int {
-	return s.queuedTasks.Len()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queuedTasks)
+}
+
+// QueueState returns the enqueued executions in the order they will be considered for running,
+// along with the capacity reserved for a long-waiting execution, if any.
+func (s *ExecutorBuffer) QueueState() models.ComputeQueueState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	tasks := s.orderedTasks(now)
+	state := models.ComputeQueueState{
+		Policy:     string(s.policy),
+		Executions: make([]models.QueuedExecution, 0, len(tasks)),
+	}
+	for i, task := range tasks {
+		reserved := task == s.reservation
+		state.Executions = append(state.Executions, models.QueuedExecution{
+			ExecutionID:       task.execution.ID,
+			JobID:             task.execution.JobID,
+			Position:          i + 1,
+			Priority:          task.execution.Job.Priority,
+			EffectivePriority: s.effectivePriority(task, now),
+			EnqueuedAt:        task.enqueuedAt.UTC(),
+			Resources:         *task.execution.TotalAllocatedResources(),
+			Reserved:          reserved,
+		})
+		if reserved {
+			state.ReservedExecutionID = task.execution.ID
+			state.ReservedCapacity = task.execution.TotalAllocatedResources().Copy()
+		}
+	}
+	return state
+}
+
+// QueueSummary returns the length of the queue and the execution capacity is reserved for, if any,
+// which unlike QueueState is small enough to be shared with every node info update.
+func (s *ExecutorBuffer) QueueSummary() models.ComputeQueueSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := models.ComputeQueueSummary{
+		Policy: string(s.policy),
+		Length: len(s.queuedTasks),
+	}
+	if task := s.reservation; task != nil && s.queuedTasks[task.execution.ID] == task {
+		summary.ReservedExecutionID = task.execution.ID
+		summary.ReservedWaitTime = s.clock.Now().Sub(task.enqueuedAt)
+	}
+	return summary
 }
 
 func (s *ExecutorBuffer) mapValues(m map[string]*bufferTask) []*models.Execution {
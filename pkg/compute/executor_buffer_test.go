@@ -0,0 +1,295 @@
+//go:build unit || !integration
+
+package compute_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+// blockingExecutor is a delegate executor whose executions run until they are released
+type blockingExecutor struct {
+	started  chan string
+	mu       sync.Mutex
+	releases map[string]chan struct{}
+}
+
+func newBlockingExecutor() *blockingExecutor {
+	return &blockingExecutor{
+		started:  make(chan string, 10),
+		releases: make(map[string]chan struct{}),
+	}
+}
+
+func (e *blockingExecutor) releaseChannel(executionID string) chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.releases[executionID]; !ok {
+		e.releases[executionID] = make(chan struct{})
+	}
+	return e.releases[executionID]
+}
+
+func (e *blockingExecutor) Run(ctx context.Context, execution *models.Execution) error {
+	release := e.releaseChannel(execution.ID)
+	e.started <- execution.ID
+	select {
+	case <-release:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (e *blockingExecutor) Cancel(_ context.Context, execution *models.Execution) error {
+	close(e.releaseChannel(execution.ID))
+	return nil
+}
+
+func (e *blockingExecutor) finish(execution *models.Execution) {
+	close(e.releaseChannel(execution.ID))
+}
+
+type ExecutorBufferTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	clock    *clock.Mock
+	delegate *blockingExecutor
+}
+
+func TestExecutorBufferTestSuite(t *testing.T) {
+	suite.Run(t, new(ExecutorBufferTestSuite))
+}
+
+func (s *ExecutorBufferTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.clock = clock.NewMock()
+	s.delegate = newBlockingExecutor()
+}
+
+func (s *ExecutorBufferTestSuite) newBuffer(policy compute.QueuePolicy) *compute.ExecutorBuffer {
+	return s.newBufferWithCapacity(policy, models.Resources{CPU: 4})
+}
+
+func (s *ExecutorBufferTestSuite) newBufferWithCapacity(
+	policy compute.QueuePolicy, maxCapacity models.Resources,
+) *compute.ExecutorBuffer {
+	return compute.NewExecutorBuffer(compute.ExecutorBufferParams{
+		ID:               "node",
+		DelegateExecutor: s.delegate,
+		RunningCapacityTracker: capacity.NewLocalTracker(capacity.LocalTrackerParams{
+			MaxCapacity: maxCapacity,
+		}),
+		EnqueuedUsageTracker: capacity.NewLocalUsageTracker(),
+		QueuePolicy:          policy,
+		AgingInterval:        time.Minute,
+		ReservationThreshold: 10 * time.Minute,
+		Clock:                s.clock,
+	})
+}
+
+func (s *ExecutorBufferTestSuite) newExecution(cpu float64, priority int) *models.Execution {
+	execution := mock.Execution()
+	execution.Job.Priority = priority
+	execution.AllocateResources(execution.Job.Task().Name, models.Resources{CPU: cpu})
+	return execution
+}
+
+func (s *ExecutorBufferTestSuite) run(buffer *compute.ExecutorBuffer, execution *models.Execution) {
+	s.Require().NoError(buffer.Run(s.ctx, execution))
+}
+
+func (s *ExecutorBufferTestSuite) assertStarted(execution *models.Execution) {
+	select {
+	case id := <-s.delegate.started:
+		s.Equal(execution.ID, id)
+	case <-time.After(time.Second):
+		s.Failf("execution not started", "execution %s was not started", execution.ID)
+	}
+}
+
+func (s *ExecutorBufferTestSuite) assertNothingStarted() {
+	select {
+	case id := <-s.delegate.started:
+		s.Failf("unexpected execution started", "execution %s was started", id)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func (s *ExecutorBufferTestSuite) queuedIDs(buffer *compute.ExecutorBuffer) []string {
+	var ids []string
+	for _, queued := range buffer.QueueState().Executions {
+		ids = append(ids, queued.ExecutionID)
+	}
+	return ids
+}
+
+func (s *ExecutorBufferTestSuite) TestFIFOBlocksBehindHeadOfQueue() {
+	buffer := s.newBuffer(compute.QueuePolicyFIFO)
+	running, large, small := s.newExecution(3, 0), s.newExecution(4, 0), s.newExecution(1, 0)
+
+	s.run(buffer, running)
+	s.assertStarted(running)
+	s.run(buffer, large)
+	s.run(buffer, small)
+	s.assertNothingStarted()
+	s.Equal([]string{large.ID, small.ID}, s.queuedIDs(buffer))
+
+	s.delegate.finish(running)
+	s.assertStarted(large)
+	s.assertNothingStarted()
+}
+
+func (s *ExecutorBufferTestSuite) TestBackfillSkipsExecutionsThatDoNotFit() {
+	buffer := s.newBuffer(compute.QueuePolicyBackfill)
+	running, large, small := s.newExecution(3, 0), s.newExecution(4, 0), s.newExecution(1, 0)
+
+	s.run(buffer, running)
+	s.assertStarted(running)
+	s.run(buffer, large)
+	s.clock.Add(time.Hour)
+	s.run(buffer, small)
+	s.assertStarted(small)
+
+	state := buffer.QueueState()
+	s.Equal(string(compute.QueuePolicyBackfill), state.Policy)
+	s.Equal([]string{large.ID}, s.queuedIDs(buffer))
+	s.Empty(state.ReservedExecutionID)
+}
+
+func (s *ExecutorBufferTestSuite) TestAgingBackfillsBeforeReservationThreshold() {
+	buffer := s.newBuffer(compute.QueuePolicyAging)
+	running, large, small := s.newExecution(3, 0), s.newExecution(4, 0), s.newExecution(1, 0)
+
+	s.run(buffer, running)
+	s.assertStarted(running)
+	s.run(buffer, large)
+	s.clock.Add(5 * time.Minute)
+	s.run(buffer, small)
+	s.assertStarted(small)
+	s.Empty(buffer.QueueState().ReservedExecutionID)
+}
+
+func (s *ExecutorBufferTestSuite) TestAgingReservesCapacityForStarvingExecution() {
+	buffer := s.newBuffer(compute.QueuePolicyAging)
+	running, large, small := s.newExecution(3, 0), s.newExecution(4, 0), s.newExecution(1, 5)
+
+	s.run(buffer, running)
+	s.assertStarted(running)
+	s.run(buffer, large)
+	s.clock.Add(11 * time.Minute)
+
+	// the small execution has a higher priority, but it would delay the reserved execution
+	s.run(buffer, small)
+	s.assertNothingStarted()
+
+	state := buffer.QueueState()
+	s.Equal(large.ID, state.ReservedExecutionID)
+	s.Require().NotNil(state.ReservedCapacity)
+	s.Equal(float64(4), state.ReservedCapacity.CPU)
+	s.Require().Len(state.Executions, 2)
+	s.Equal(large.ID, state.Executions[0].ExecutionID)
+	s.Equal(1, state.Executions[0].Position)
+	s.Equal(int64(11), state.Executions[0].EffectivePriority)
+	s.True(state.Executions[0].Reserved)
+	s.False(state.Executions[1].Reserved)
+
+	summary := buffer.QueueSummary()
+	s.Equal(models.ComputeQueueSummary{
+		Policy:              string(compute.QueuePolicyAging),
+		Length:              2,
+		ReservedExecutionID: large.ID,
+		ReservedWaitTime:    11 * time.Minute,
+	}, summary)
+
+	s.delegate.finish(running)
+	s.assertStarted(large)
+	s.Equal([]string{small.ID}, s.queuedIDs(buffer))
+	s.Empty(buffer.QueueState().ReservedExecutionID)
+	s.Equal(models.ComputeQueueSummary{Policy: string(compute.QueuePolicyAging), Length: 1}, buffer.QueueSummary())
+}
+
+func (s *ExecutorBufferTestSuite) TestAgingBackfillsAlongsideReservation() {
+	buffer := s.newBufferWithCapacity(compute.QueuePolicyAging, models.Resources{CPU: 4, Memory: 4})
+	newExecution := func(resources models.Resources) *models.Execution {
+		execution := mock.Execution()
+		execution.AllocateResources(execution.Job.Task().Name, resources)
+		return execution
+	}
+	running := newExecution(models.Resources{CPU: 1, Memory: 3})
+	large := newExecution(models.Resources{CPU: 2, Memory: 2})
+
+	s.run(buffer, running)
+	s.assertStarted(running)
+	s.run(buffer, large)
+	s.clock.Add(11 * time.Minute)
+
+	// the large execution waits for memory, which doesn't stop an execution that only uses spare CPU
+	cpuOnly := newExecution(models.Resources{CPU: 1})
+	s.run(buffer, cpuOnly)
+	s.assertStarted(cpuOnly)
+	s.Equal(large.ID, buffer.QueueState().ReservedExecutionID)
+
+	// executions that would take CPU or memory the reserved execution needs have to wait for it
+	moreCPU, memory := newExecution(models.Resources{CPU: 1}), newExecution(models.Resources{Memory: 1})
+	s.run(buffer, moreCPU)
+	s.run(buffer, memory)
+	s.assertNothingStarted()
+
+	s.delegate.finish(running)
+	var started []string
+	for range 3 {
+		select {
+		case id := <-s.delegate.started:
+			started = append(started, id)
+		case <-time.After(time.Second):
+			s.FailNow("executions not started")
+		}
+	}
+	s.ElementsMatch([]string{large.ID, moreCPU.ID, memory.ID}, started)
+	s.Empty(buffer.QueueState().ReservedExecutionID)
+}
+
+func (s *ExecutorBufferTestSuite) TestAgingRaisesPriorityWithWaitingTime() {
+	buffer := s.newBuffer(compute.QueuePolicyAging)
+	running := s.newExecution(4, 0)
+	old, recent, urgent := s.newExecution(1, 0), s.newExecution(1, 2), s.newExecution(1, 5)
+
+	s.run(buffer, running)
+	s.assertStarted(running)
+	s.run(buffer, old)
+	s.clock.Add(3 * time.Minute)
+
+	// the old execution waited long enough to overtake a newer execution with a higher priority,
+	// but not one with a much higher priority
+	s.run(buffer, recent)
+	s.run(buffer, urgent)
+	s.Equal([]string{urgent.ID, old.ID, recent.ID}, s.queuedIDs(buffer))
+
+	state := buffer.QueueState()
+	s.Equal(0, state.Executions[1].Priority)
+	s.Equal(int64(3), state.Executions[1].EffectivePriority)
+	s.Equal(2, state.Executions[1].Position)
+}
+
+func (s *ExecutorBufferTestSuite) TestParseQueuePolicy() {
+	policy, err := compute.ParseQueuePolicy("")
+	s.Require().NoError(err)
+	s.Equal(compute.DefaultQueuePolicy, policy)
+
+	policy, err = compute.ParseQueuePolicy(" FIFO ")
+	s.Require().NoError(err)
+	s.Equal(compute.QueuePolicyFIFO, policy)
+
+	_, err = compute.ParseQueuePolicy("random")
+	s.Error(err)
+}
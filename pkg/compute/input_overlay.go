@@ -0,0 +1,85 @@
+package compute
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+)
+
+const (
+	overlayUpperDirName = "upper"
+	overlayWorkDirName  = "work"
+)
+
+// prepareInputOverlays returns the inputs with a private writable layer for each input mounted with an overlay.
+// The writable layer of an input is created in the results of the execution if it is published, and in the
+// execution's overlays directory otherwise. Directory inputs are mounted by the executors as an overlay of the
+// writable layer over the read-only input, while file inputs are copied into their writable layer as there is
+// nothing to share. The prepared inputs are not modified, as they are still needed to clean up the inputs.
+func prepareInputOverlays(inputs []storage.PreparedStorage, executionDir string) ([]storage.PreparedStorage, error) {
+	prepared := make([]storage.PreparedStorage, len(inputs))
+	for i, input := range inputs {
+		prepared[i] = input
+		overlay := input.InputSource.Overlay
+		if overlay == nil {
+			continue
+		}
+
+		layerDir := filepath.Join(ExecutionOverlaysDir(executionDir), strconv.Itoa(i))
+		upperDir := filepath.Join(layerDir, overlayUpperDirName)
+		if overlay.Publish != "" {
+			upperDir = filepath.Join(ExecutionResultsDir(executionDir), overlay.Publish)
+		}
+		if err := os.MkdirAll(upperDir, StorageDirectoryPerms); err != nil {
+			return nil, fmt.Errorf("failed to create overlay of input %s: %w", input.InputSource.Target, err)
+		}
+
+		stat, err := os.Stat(input.Volume.Source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat input %s: %w", input.InputSource.Target, err)
+		}
+		if !stat.IsDir() {
+			source := filepath.Join(upperDir, filepath.Base(input.Volume.Source))
+			if err = copyFile(input.Volume.Source, source, stat.Mode().Perm()); err != nil {
+				return nil, fmt.Errorf("failed to copy input %s into its overlay: %w", input.InputSource.Target, err)
+			}
+			prepared[i].Volume.Source = source
+			prepared[i].Volume.ReadOnly = false
+			continue
+		}
+
+		// the work directory must be on the same filesystem as the upper directory, but not inside it
+		workDir := filepath.Join(layerDir, overlayWorkDirName)
+		if err = os.MkdirAll(workDir, StorageDirectoryPerms); err != nil {
+			return nil, fmt.Errorf("failed to create overlay of input %s: %w", input.InputSource.Target, err)
+		}
+		prepared[i].Volume.ReadOnly = false
+		prepared[i].Volume.Overlay = &storage.OverlayLayer{
+			UpperDir: upperDir,
+			WorkDir:  workDir,
+		}
+	}
+	return prepared, nil
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src) //nolint:gosec // G304: src is a prepared input of the execution
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm) //nolint:gosec // G304: dst is in the execution dir
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
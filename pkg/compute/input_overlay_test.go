@@ -0,0 +1,93 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+)
+
+type InputOverlaySuite struct {
+	suite.Suite
+	inputDir     string
+	inputFile    string
+	executionDir string
+}
+
+func TestInputOverlaySuite(t *testing.T) {
+	suite.Run(t, new(InputOverlaySuite))
+}
+
+func (s *InputOverlaySuite) SetupTest() {
+	s.inputDir = s.T().TempDir()
+	s.inputFile = filepath.Join(s.T().TempDir(), "input.csv")
+	s.Require().NoError(os.WriteFile(s.inputFile, []byte("a,b"), 0o600))
+	s.executionDir = s.T().TempDir()
+	s.Require().NoError(os.MkdirAll(ExecutionResultsDir(s.executionDir), StorageDirectoryPerms))
+}
+
+func (s *InputOverlaySuite) input(source string, overlay *models.InputOverlay) storage.PreparedStorage {
+	return storage.PreparedStorage{
+		InputSource: models.InputSource{Target: "/inputs", Overlay: overlay},
+		Volume: storage.StorageVolume{
+			Type:     storage.StorageVolumeConnectorBind,
+			ReadOnly: true,
+			Source:   source,
+			Target:   "/inputs",
+		},
+	}
+}
+
+func (s *InputOverlaySuite) TestWithoutOverlay() {
+	inputs := []storage.PreparedStorage{s.input(s.inputDir, nil)}
+	prepared, err := prepareInputOverlays(inputs, s.executionDir)
+	s.Require().NoError(err)
+	s.Equal(inputs, prepared)
+	s.NoDirExists(ExecutionOverlaysDir(s.executionDir))
+}
+
+func (s *InputOverlaySuite) TestDirectoryOverlay() {
+	inputs := []storage.PreparedStorage{s.input(s.inputDir, &models.InputOverlay{})}
+	prepared, err := prepareInputOverlays(inputs, s.executionDir)
+	s.Require().NoError(err)
+
+	volume := prepared[0].Volume
+	s.Equal(s.inputDir, volume.Source)
+	s.False(volume.ReadOnly)
+	s.Require().NotNil(volume.Overlay)
+	s.DirExists(volume.Overlay.UpperDir)
+	s.DirExists(volume.Overlay.WorkDir)
+	s.Equal(ExecutionOverlaysDir(s.executionDir), filepath.Dir(filepath.Dir(volume.Overlay.UpperDir)))
+	s.Nil(inputs[0].Volume.Overlay, "prepared inputs should not be modified")
+}
+
+func (s *InputOverlaySuite) TestPublishedOverlay() {
+	inputs := []storage.PreparedStorage{s.input(s.inputDir, &models.InputOverlay{Publish: "changes"})}
+	prepared, err := prepareInputOverlays(inputs, s.executionDir)
+	s.Require().NoError(err)
+
+	overlay := prepared[0].Volume.Overlay
+	s.Require().NotNil(overlay)
+	s.Equal(filepath.Join(ExecutionResultsDir(s.executionDir), "changes"), overlay.UpperDir)
+	s.NotContains(overlay.WorkDir, ExecutionResultsDir(s.executionDir), "work dir should not be published")
+}
+
+func (s *InputOverlaySuite) TestFileOverlay() {
+	inputs := []storage.PreparedStorage{s.input(s.inputFile, &models.InputOverlay{})}
+	prepared, err := prepareInputOverlays(inputs, s.executionDir)
+	s.Require().NoError(err)
+
+	volume := prepared[0].Volume
+	s.Nil(volume.Overlay)
+	s.False(volume.ReadOnly)
+	s.NotEqual(s.inputFile, volume.Source)
+	data, err := os.ReadFile(volume.Source)
+	s.Require().NoError(err)
+	s.Equal("a,b", string(data))
+}
@@ -35,12 +35,15 @@ type ServerParams struct {
 	Executors      executor.ExecProvider
 	Buffer         int // If not set (0), defaultBuffer will be used.
 	ResultsPath    compute.ResultsPath
+	// SecretRedactor, if set, scrubs secret values resolved for an execution from its logs
+	SecretRedactor *compute.SecretRedactor
 }
 
 type server struct {
 	executionStore store.ExecutionStore
 	buffer         int
 	resultsPath    compute.ResultsPath
+	secretRedactor *compute.SecretRedactor
 }
 
 // NewServer creates a new log stream server
@@ -53,6 +56,7 @@ func NewServer(params ServerParams) Server {
 		executionStore: params.ExecutionStore,
 		buffer:         params.Buffer,
 		resultsPath:    params.ResultsPath,
+		secretRedactor: params.SecretRedactor,
 	}
 }
 
@@ -86,7 +90,32 @@ func (s *server) GetLogStream(ctx context.Context, request messages.ExecutionLog
 		Buffer: s.buffer,
 	})
 
-	return streamer.Stream(ctx), nil
+	stream := streamer.Stream(ctx)
+	if s.secretRedactor == nil {
+		return stream, nil
+	}
+	return s.redact(ctx, execution.ID, stream), nil
+}
+
+// redact scrubs the execution's secret values from each log line of the stream
+func (s *server) redact(
+	ctx context.Context, executionID string, stream <-chan *concurrency.AsyncResult[models.ExecutionLog],
+) <-chan *concurrency.AsyncResult[models.ExecutionLog] {
+	out := make(chan *concurrency.AsyncResult[models.ExecutionLog], s.buffer)
+	go func() {
+		defer close(out)
+		for result := range stream {
+			if result != nil && result.Err == nil {
+				result.Value.Line = s.secretRedactor.Redact(executionID, result.Value.Line)
+			}
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
 }
 
 // Best-effort attempt to find an execution with the given ID. A request for logs can arrive before the execution
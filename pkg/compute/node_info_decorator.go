@@ -19,6 +19,11 @@ type NodeInfoDecoratorParams struct {
 	ExecutorBuffer         *ExecutorBuffer
 	MaxJobRequirements     models.Resources
 	AdvertisedAddress      string
+	PublicKey              string
+	// ImageCache advertises the images cached on the node. Optional.
+	ImageCache executor.ImageCache
+	// Volumes advertises the persistent volumes held by the node. Optional.
+	Volumes *VolumeManager
 }
 
 type NodeInfoDecorator struct {
@@ -30,6 +35,9 @@ type NodeInfoDecorator struct {
 	executorBuffer         *ExecutorBuffer
 	maxJobRequirements     models.Resources
 	advertisedAddress      string
+	publicKey              string
+	imageCache             executor.ImageCache
+	volumes                *VolumeManager
 }
 
 func NewNodeInfoDecorator(params NodeInfoDecoratorParams) *NodeInfoDecorator {
@@ -42,6 +50,9 @@ func NewNodeInfoDecorator(params NodeInfoDecoratorParams) *NodeInfoDecorator {
 		executorBuffer:         params.ExecutorBuffer,
 		maxJobRequirements:     params.MaxJobRequirements,
 		advertisedAddress:      params.AdvertisedAddress,
+		publicKey:              params.PublicKey,
+		imageCache:             params.ImageCache,
+		volumes:                params.Volumes,
 	}
 }
 
@@ -49,6 +60,7 @@ func (n *NodeInfoDecorator) DecorateNodeInfo(ctx context.Context, nodeInfo model
 	// TODO(forrest): this method takes 10 seconds to run: https://github.com/bacalhau-project/bacalhau/issues/4153
 	// because the Keys() methods are slow when s3 is considered since we need to check for credentials.
 	nodeInfo.NodeType = models.NodeTypeCompute
+	queueSummary := n.executorBuffer.QueueSummary()
 	nodeInfo.ComputeNodeInfo = models.ComputeNodeInfo{
 		ExecutionEngines:   n.executors.Keys(ctx),
 		Publishers:         n.publishers.Keys(ctx),
@@ -60,6 +72,14 @@ func (n *NodeInfoDecorator) DecorateNodeInfo(ctx context.Context, nodeInfo model
 		RunningExecutions:  len(n.executorBuffer.RunningExecutions()),
 		EnqueuedExecutions: n.executorBuffer.EnqueuedExecutionsCount(),
 		Address:            n.advertisedAddress,
+		PublicKey:          n.publicKey,
+		Queue:              &queueSummary,
+	}
+	if n.imageCache != nil {
+		nodeInfo.ComputeNodeInfo.CachedImages = n.imageCache.CachedImages()
+	}
+	if n.volumes != nil {
+		nodeInfo.ComputeNodeInfo.Volumes = n.volumes.Volumes()
 	}
 	return nodeInfo
 }
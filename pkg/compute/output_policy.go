@@ -0,0 +1,125 @@
+package compute
+
+import (
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+// outputPolicyOutcome is what applying the output policy of a task did to its results
+type outputPolicyOutcome struct {
+	// size is the total size of the results left to publish
+	size int64
+	// excluded is the number of files removed as they did not match the policy's globs
+	excluded int
+	// dropped is the number of files removed as they exceeded the max size
+	dropped int
+	// truncated is true if results were truncated or dropped as they exceeded the max size
+	truncated bool
+}
+
+// stdioResultFiles are the files recording the stdout, stderr and exit code of the task, which the globs
+// of the policy never remove as getting the job's logs and exit code relies on them
+var stdioResultFiles = map[string]bool{
+	models.DownloadFilenameStdout:   true,
+	models.DownloadFilenameStderr:   true,
+	models.DownloadFilenameExitCode: true,
+}
+
+// resultFile is a result file left to publish after filtering
+type resultFile struct {
+	path    string
+	size    int64
+	regular bool
+}
+
+// enforceOutputPolicy applies the output policy of the task to its results before they are published,
+// and records the outcome in the run result. It returns the events recording what the policy did, if anything.
+func enforceOutputPolicy(
+	task *models.Task, resultsDir string, result *models.RunCommandResult) ([]*models.Event, error) {
+	if !task.OutputPolicy.IsEnabled() {
+		return nil, nil
+	}
+	outcome, err := applyOutputPolicy(resultsDir, task.OutputPolicy)
+	if err != nil {
+		return nil, err
+	}
+	result.ResultsSize = outcome.size
+	result.ResultsExcluded = outcome.excluded
+	result.ResultsTruncated = outcome.truncated
+	if outcome.excluded == 0 && !outcome.truncated {
+		return nil, nil
+	}
+	return []*models.Event{ExecResultsFilteredEvent(outcome, task.OutputPolicy)}, nil
+}
+
+// applyOutputPolicy removes the result files that do not match the policy's globs, except for the stdio files,
+// and then enforces its max size over the remaining files in lexical order of their paths.
+func applyOutputPolicy(resultsDir string, policy *models.OutputPolicy) (outputPolicyOutcome, error) {
+	var outcome outputPolicyOutcome
+	maxSize, err := policy.GetMaxSize()
+	if err != nil {
+		return outcome, bacerrors.Wrap(err, "invalid output policy")
+	}
+
+	var files []resultFile
+	err = filepath.WalkDir(resultsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(resultsDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if !stdioResultFiles[relPath] && !policy.Matches(relPath) {
+			outcome.excluded++
+			return os.Remove(path)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, resultFile{path: path, size: info.Size(), regular: d.Type().IsRegular()})
+		outcome.size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return outcome, bacerrors.Wrap(err, "failed to apply output policy to results")
+	}
+
+	if maxSize == 0 || uint64(outcome.size) <= maxSize { //nolint:gosec // G115: sizes are never negative
+		return outcome, nil
+	}
+	if policy.GetOnExceeded() == models.OutputLimitFail {
+		return outcome, NewErrResultsTooLarge(outcome.size, maxSize)
+	}
+
+	outcome.truncated = true
+	outcome.size = 0
+	remaining := int64(min(maxSize, math.MaxInt64)) //nolint:gosec // G115: bounded by math.MaxInt64
+	for _, file := range files {
+		switch {
+		case file.size <= remaining:
+			remaining -= file.size
+			outcome.size += file.size
+		case policy.GetOnExceeded() == models.OutputLimitTruncate && file.regular && remaining > 0:
+			// only regular files are truncated, as truncating a symlink would truncate its target
+			if err = os.Truncate(file.path, remaining); err != nil {
+				return outcome, bacerrors.Wrap(err, "failed to truncate results")
+			}
+			outcome.size += remaining
+			remaining = 0
+		default:
+			if err = os.Remove(file.path); err != nil {
+				return outcome, bacerrors.Wrap(err, "failed to drop results")
+			}
+			outcome.dropped++
+		}
+	}
+	return outcome, nil
+}
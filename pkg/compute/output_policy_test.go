@@ -0,0 +1,165 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+type OutputPolicySuite struct {
+	suite.Suite
+	resultsDir string
+}
+
+func TestOutputPolicySuite(t *testing.T) {
+	suite.Run(t, new(OutputPolicySuite))
+}
+
+func (s *OutputPolicySuite) SetupTest() {
+	s.resultsDir = s.T().TempDir()
+	s.writeFile("exitCode", 1)
+	s.writeFile("stdout", 10)
+	s.writeFile("outputs/a.csv", 100)
+	s.writeFile("outputs/b.csv", 100)
+	s.writeFile("outputs/c.log", 50)
+	s.writeFile("outputs/tmp/d.csv", 100)
+}
+
+func (s *OutputPolicySuite) writeFile(name string, size int) {
+	path := filepath.Join(s.resultsDir, name)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	s.Require().NoError(os.WriteFile(path, []byte(strings.Repeat("x", size)), 0644))
+}
+
+// files returns the sizes of the result files left to publish
+func (s *OutputPolicySuite) files() map[string]int64 {
+	files := make(map[string]int64)
+	s.Require().NoError(filepath.Walk(s.resultsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(s.resultsDir, path)
+		files[filepath.ToSlash(relPath)] = info.Size()
+		return err
+	}))
+	return files
+}
+
+func (s *OutputPolicySuite) TestFilter() {
+	outcome, err := applyOutputPolicy(s.resultsDir, &models.OutputPolicy{
+		Include: []string{"outputs/**/*.csv"},
+		Exclude: []string{"outputs/tmp/**"},
+	})
+	s.Require().NoError(err)
+	s.Equal(outputPolicyOutcome{size: 211, excluded: 2}, outcome)
+	s.Equal(map[string]int64{"exitCode": 1, "stdout": 10, "outputs/a.csv": 100, "outputs/b.csv": 100}, s.files())
+}
+
+func (s *OutputPolicySuite) TestFilterKeepsStdioFiles() {
+	s.writeFile("stderr", 5)
+	outcome, err := applyOutputPolicy(s.resultsDir, &models.OutputPolicy{
+		Include: []string{"*.csv"},
+		Exclude: []string{"std*", "exitCode"},
+	})
+	s.Require().NoError(err)
+	s.Equal(outputPolicyOutcome{size: 16, excluded: 4}, outcome)
+	s.Equal(map[string]int64{"exitCode": 1, "stdout": 10, "stderr": 5}, s.files())
+}
+
+func (s *OutputPolicySuite) TestWithinMaxSize() {
+	outcome, err := applyOutputPolicy(s.resultsDir, &models.OutputPolicy{MaxSize: "361B"})
+	s.Require().NoError(err)
+	s.Equal(outputPolicyOutcome{size: 361}, outcome)
+	s.Len(s.files(), 6)
+}
+
+func (s *OutputPolicySuite) TestFailWhenExceeded() {
+	before := s.files()
+	_, err := applyOutputPolicy(s.resultsDir, &models.OutputPolicy{MaxSize: "300B"})
+	var tooLarge ErrResultsTooLarge
+	s.Require().True(errors.As(err, &tooLarge))
+	s.Equal(NewErrResultsTooLarge(361, 300), tooLarge)
+	s.Equal(before, s.files())
+}
+
+func (s *OutputPolicySuite) TestTruncateWhenExceeded() {
+	// files are kept in lexical order until the limit, which falls within outputs/c.log
+	outcome, err := applyOutputPolicy(s.resultsDir, &models.OutputPolicy{
+		MaxSize:    "231B",
+		OnExceeded: models.OutputLimitTruncate,
+	})
+	s.Require().NoError(err)
+	s.Equal(outputPolicyOutcome{size: 231, dropped: 2, truncated: true}, outcome)
+	s.Equal(map[string]int64{
+		"exitCode":      1,
+		"outputs/a.csv": 100,
+		"outputs/b.csv": 100,
+		"outputs/c.log": 30,
+	}, s.files())
+}
+
+func (s *OutputPolicySuite) TestPublishPartialWhenExceeded() {
+	// whole files that fit within the limit are kept, even after a file that does not fit
+	outcome, err := applyOutputPolicy(s.resultsDir, &models.OutputPolicy{
+		MaxSize:    "231B",
+		OnExceeded: models.OutputLimitPublishPartial,
+	})
+	s.Require().NoError(err)
+	s.Equal(outputPolicyOutcome{size: 211, dropped: 2, truncated: true}, outcome)
+	s.Equal(map[string]int64{
+		"exitCode":      1,
+		"outputs/a.csv": 100,
+		"outputs/b.csv": 100,
+		"stdout":        10,
+	}, s.files())
+}
+
+func (s *OutputPolicySuite) TestSymlinksAreNotTruncated() {
+	target := filepath.Join(s.T().TempDir(), "input")
+	s.Require().NoError(os.WriteFile(target, []byte(strings.Repeat("x", 100)), 0644))
+	s.Require().NoError(os.Symlink(target, filepath.Join(s.resultsDir, "link")))
+
+	_, err := applyOutputPolicy(s.resultsDir, &models.OutputPolicy{
+		MaxSize:    "1B",
+		OnExceeded: models.OutputLimitTruncate,
+	})
+	s.Require().NoError(err)
+	s.NotContains(s.files(), "link")
+	info, err := os.Stat(target)
+	s.Require().NoError(err)
+	s.Equal(int64(100), info.Size())
+}
+
+func (s *OutputPolicySuite) TestEnforceRecordsOutcome() {
+	task := &models.Task{OutputPolicy: &models.OutputPolicy{
+		MaxSize:    "211B",
+		Exclude:    []string{"outputs/tmp/**"},
+		OnExceeded: models.OutputLimitPublishPartial,
+	}}
+	result := models.NewRunCommandResult()
+	events, err := enforceOutputPolicy(task, s.resultsDir, result)
+	s.Require().NoError(err)
+	s.Equal(int64(211), result.ResultsSize)
+	s.Equal(1, result.ResultsExcluded)
+	s.True(result.ResultsTruncated)
+	s.Require().Len(events, 1)
+	s.Equal(EventTopicExecutionPublishing, events[0].Topic)
+	s.Equal("Excluded 1 result files not matching the output policy. "+
+		"Truncated results to the max size of 211B, dropping 1 files", events[0].Message)
+	s.Equal("1", events[0].Details["ResultsDropped"])
+
+	// without a policy, results are left as they are
+	result = models.NewRunCommandResult()
+	events, err = enforceOutputPolicy(&models.Task{}, s.resultsDir, result)
+	s.Require().NoError(err)
+	s.Empty(events)
+	s.Zero(result.ResultsSize)
+}
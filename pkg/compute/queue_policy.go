@@ -0,0 +1,40 @@
+package compute
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QueuePolicy determines the order in which the ExecutorBuffer starts executions that are waiting for capacity.
+type QueuePolicy string
+
+const (
+	// QueuePolicyFIFO starts executions strictly in priority and arrival order. An execution that does not
+	// fit in the available capacity blocks the executions behind it until enough capacity is released.
+	QueuePolicyFIFO QueuePolicy = "fifo"
+
+	// QueuePolicyBackfill starts any execution that fits in the available capacity, skipping larger executions
+	// at the head of the queue. It maximizes utilization, but can starve executions with large requirements.
+	QueuePolicyBackfill QueuePolicy = "backfill"
+
+	// QueuePolicyAging backfills smaller executions like QueuePolicyBackfill, but raises the priority of executions
+	// with their waiting time, and reserves capacity for an execution that has waited past the reservation threshold.
+	// Smaller executions then only jump ahead of it if they leave it enough of every kind of resource they use.
+	QueuePolicyAging QueuePolicy = "aging"
+
+	// DefaultQueuePolicy is the queue policy used when none is configured
+	DefaultQueuePolicy = QueuePolicyAging
+)
+
+// ParseQueuePolicy parses a queue policy, defaulting to DefaultQueuePolicy if empty.
+func ParseQueuePolicy(policy string) (QueuePolicy, error) {
+	switch p := QueuePolicy(strings.ToLower(strings.TrimSpace(policy))); p {
+	case "":
+		return DefaultQueuePolicy, nil
+	case QueuePolicyFIFO, QueuePolicyBackfill, QueuePolicyAging:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown queue policy %q. supported policies are: %s, %s, %s",
+			policy, QueuePolicyFIFO, QueuePolicyBackfill, QueuePolicyAging)
+	}
+}
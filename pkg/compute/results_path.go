@@ -14,6 +14,8 @@ const (
 	OutputDir            = "output"
 	LogsDir              = "logs"
 	ResultsDir           = "results"
+	CheckpointDir        = "checkpoint"
+	OverlaysDir          = "overlays"
 	ExecutionLogFileName = "raw_container_logs"
 )
 
@@ -27,6 +29,16 @@ func ExecutionResultsDir(executionOutputDir string) string {
 	return filepath.Join(executionOutputDir, ResultsDir)
 }
 
+// Returns the path do the sub-directory in which execution checkpoints are written
+func ExecutionCheckpointDir(executionOutputDir string) string {
+	return filepath.Join(executionOutputDir, CheckpointDir)
+}
+
+// Returns the path do the sub-directory in which the unpublished writable layers of inputs mounted with an overlay are stored
+func ExecutionOverlaysDir(executionOutputDir string) string {
+	return filepath.Join(executionOutputDir, OverlaysDir)
+}
+
 // Execution results folder structure
 //
 //	→ rootDir
@@ -34,6 +46,8 @@ func ExecutionResultsDir(executionOutputDir string) string {
 //			→ $execution_id						<- execution output directory
 //				→ LogsDir
 //				→ ResultsDir
+//				→ CheckpointDir					<- only if the task has checkpoints enabled
+//				→ OverlaysDir					<- only if the task has inputs mounted with an overlay
 type ResultsPath struct {
 	OutputDir string
 }
@@ -0,0 +1,66 @@
+package compute
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+// SecretRedactor tracks the secret values resolved for each execution so they can be
+// scrubbed from the execution logs served by the compute node.
+// Secret values are only held in memory and are forgotten once the execution's
+// results directory is removed.
+type SecretRedactor struct {
+	mu      sync.RWMutex
+	secrets map[string][]string
+}
+
+// NewSecretRedactor creates a new SecretRedactor
+func NewSecretRedactor() *SecretRedactor {
+	return &SecretRedactor{
+		secrets: make(map[string][]string),
+	}
+}
+
+// Track records secret values resolved for an execution
+func (r *SecretRedactor) Track(executionID string, values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, value := range values {
+		if value != "" {
+			r.secrets[executionID] = append(r.secrets[executionID], value)
+		}
+	}
+}
+
+// Forget drops the secret values recorded for an execution
+func (r *SecretRedactor) Forget(executionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.secrets, executionID)
+}
+
+// Redact replaces the secret values of an execution found in s with models.RedactedValue
+func (r *SecretRedactor) Redact(executionID string, s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, value := range r.secrets[executionID] {
+		s = strings.ReplaceAll(s, value, models.RedactedValue)
+	}
+	return s
+}
+
+// SecretEnvValues returns the resolved values of the task environment variables that reference secrets
+func SecretEnvValues(execution *models.Execution, resolved map[string]string) []string {
+	if execution == nil || execution.Job == nil || execution.Job.Task() == nil {
+		return nil
+	}
+	var values []string
+	for name, value := range execution.Job.Task().Env {
+		if value.IsSecret() {
+			values = append(values, resolved[name])
+		}
+	}
+	return values
+}
@@ -0,0 +1,49 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+type SecretRedactorSuite struct {
+	suite.Suite
+}
+
+func TestSecretRedactorSuite(t *testing.T) {
+	suite.Run(t, new(SecretRedactorSuite))
+}
+
+func (s *SecretRedactorSuite) TestRedact() {
+	redactor := NewSecretRedactor()
+	redactor.Track("exec-1", "hunter2", "")
+	redactor.Track("exec-2", "other")
+
+	s.Equal("password is [REDACTED], other", redactor.Redact("exec-1", "password is hunter2, other"))
+	s.Equal("password is hunter2", redactor.Redact("exec-2", "password is hunter2"))
+
+	redactor.Forget("exec-1")
+	s.Equal("password is hunter2", redactor.Redact("exec-1", "password is hunter2"))
+}
+
+func (s *SecretRedactorSuite) TestSecretEnvValues() {
+	execution := mock.Execution()
+	execution.Job.Task().Env = map[string]models.EnvVarValue{
+		"PASSWORD": "secret:file/db-password",
+		"HOST_VAR": "env:TEST_VAR",
+		"LITERAL":  "value",
+	}
+	resolved := map[string]string{
+		"PASSWORD": "hunter2",
+		"HOST_VAR": "host-value",
+		"LITERAL":  "value",
+	}
+
+	s.Equal([]string{"hunter2"}, SecretEnvValues(execution, resolved))
+	s.Empty(SecretEnvValues(nil, resolved))
+}
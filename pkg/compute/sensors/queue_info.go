@@ -0,0 +1,37 @@
+package sensors
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+type QueueInfoProviderParams struct {
+	Name          string
+	BackendBuffer *compute.ExecutorBuffer
+}
+
+// QueueInfoProvider provides DebugInfo about the executions waiting for capacity, including their
+// position in the queue, their aged priority and any capacity reserved for a long-waiting execution.
+type QueueInfoProvider struct {
+	name          string
+	backendBuffer *compute.ExecutorBuffer
+}
+
+func NewQueueInfoProvider(params QueueInfoProviderParams) *QueueInfoProvider {
+	return &QueueInfoProvider{
+		name:          params.Name,
+		backendBuffer: params.BackendBuffer,
+	}
+}
+
+func (q QueueInfoProvider) GetDebugInfo(ctx context.Context) (models.DebugInfo, error) {
+	return models.DebugInfo{
+		Component: q.name,
+		Info:      q.backendBuffer.QueueState(),
+	}, nil
+}
+
+// compile-time check that we implement the interface
+var _ models.DebugInfoProvider = (*QueueInfoProvider)(nil)
@@ -396,7 +396,9 @@ func (s *Store) UpdateExecutionState(ctx context.Context, request store.UpdateEx
 		if err = request.Condition.Validate(existingExecution); err != nil {
 			return err
 		}
-		if existingExecution.IsTerminalComputeState() {
+		// terminal executions can only be updated to record the results a cancelled execution
+		// published while it was terminating
+		if existingExecution.IsTerminalComputeState() && !existingExecution.AcceptsTerminalUpdate(request.NewValues) {
 			return store.NewErrExecutionAlreadyTerminal(
 				request.ExecutionID, existingExecution.ComputeState.StateType, request.NewValues.ComputeState.StateType)
 		}
@@ -122,6 +122,49 @@ func (s *StoreSuite) TestUpdateExecution() {
 	s.verifyWatcherExecutionEvent(eventsResp.Events[1], watcher.OperationUpdate, updatedExecution, *createdExecution)
 }
 
+func (s *StoreSuite) TestUpdateTerminalExecution() {
+	s.Require().NoError(s.executionStore.CreateExecution(s.ctx, *s.execution))
+	s.Require().NoError(s.executionStore.UpdateExecutionState(s.ctx, store.UpdateExecutionRequest{
+		ExecutionID: s.execution.ID,
+		NewValues: models.Execution{
+			ComputeState: models.NewExecutionState(models.ExecutionStateCancelled),
+		},
+	}))
+
+	// the state of a terminal execution cannot change
+	err := s.executionStore.UpdateExecutionState(s.ctx, store.UpdateExecutionRequest{
+		ExecutionID: s.execution.ID,
+		NewValues: models.Execution{
+			ComputeState: models.NewExecutionState(models.ExecutionStateFailed),
+		},
+	})
+	s.ErrorAs(err, &store.ErrExecutionAlreadyTerminal{})
+
+	// nor its other values
+	err = s.executionStore.UpdateExecutionState(s.ctx, store.UpdateExecutionRequest{
+		ExecutionID: s.execution.ID,
+		NewValues: models.Execution{
+			ComputeState: models.NewExecutionState(models.ExecutionStateCancelled),
+			DesiredState: models.NewExecutionDesiredState(models.ExecutionDesiredStateRunning),
+		},
+	})
+	s.ErrorAs(err, &store.ErrExecutionAlreadyTerminal{})
+
+	// but the results it published while terminating can be recorded
+	result := &models.SpecConfig{Type: "myResult"}
+	s.Require().NoError(s.executionStore.UpdateExecutionState(s.ctx, store.UpdateExecutionRequest{
+		ExecutionID: s.execution.ID,
+		NewValues: models.Execution{
+			ComputeState:    models.NewExecutionState(models.ExecutionStateCancelled),
+			PublishedResult: result,
+		},
+	}))
+	updatedExecution, err := s.executionStore.GetExecution(s.ctx, s.execution.ID)
+	s.Require().NoError(err)
+	s.Equal(models.ExecutionStateCancelled, updatedExecution.ComputeState.StateType)
+	s.Equal(result, updatedExecution.PublishedResult)
+}
+
 func (s *StoreSuite) TestGetExecutionCount() {
 	states := []models.ExecutionStateType{
 		models.ExecutionStateBidAccepted,
@@ -0,0 +1,75 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/provider"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+// cancelRecorder is an executor that records the executions it is asked to cancel
+type cancelRecorder struct {
+	executor.Executor
+	cancelled chan string
+}
+
+func (r *cancelRecorder) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (r *cancelRecorder) Cancel(_ context.Context, executionID string) error {
+	r.cancelled <- executionID
+	return nil
+}
+
+func TestVolumeChecksStopExecution(t *testing.T) {
+	ctx := context.Background()
+	volumes, err := NewVolumeManager(VolumeManagerParams{
+		Dir:           t.TempDir(),
+		CheckInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	recorder := &cancelRecorder{cancelled: make(chan string, 1)}
+
+	job := mock.Job()
+	job.Type = models.JobTypeService
+	job.Task().Volumes = []*models.PersistentVolume{{Name: "data", Target: "/data", MaxSize: "1KB"}}
+	execution := mock.ExecutionForJob(job)
+	e := &BaseExecutor{
+		executors: provider.NewMappedProvider(map[string]executor.Executor{
+			job.Task().Engine.Type: recorder,
+		}),
+		volumes: volumes,
+	}
+
+	mounts, err := volumes.MountVolumes(ctx, execution)
+	require.NoError(t, err)
+	stopVolumeChecks := e.startVolumeChecks(ctx, execution)
+	require.NoError(t, os.WriteFile(filepath.Join(mounts[0].Source, "state"), make([]byte, 1500), 0o600))
+
+	select {
+	case executionID := <-recorder.cancelled:
+		require.Equal(t, execution.ID, executionID)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "execution was not stopped")
+	}
+	var tooLarge ErrVolumeTooLarge
+	require.True(t, errors.As(stopVolumeChecks(), &tooLarge))
+	require.Equal(t, "data", tooLarge.Name)
+}
+
+func TestVolumeChecksWithoutVolumes(t *testing.T) {
+	e := &BaseExecutor{}
+	require.NoError(t, e.startVolumeChecks(context.Background(), mock.Execution())())
+}
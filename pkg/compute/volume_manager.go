@@ -0,0 +1,359 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/validate"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+)
+
+const (
+	// volumeDataDirName is the directory of a volume holding the data mounted into executions
+	volumeDataDirName = "data"
+	// volumeInfoFileName is the file of a volume holding its metadata, outside the data mounted into executions
+	volumeInfoFileName = "volume.json"
+	// DefaultVolumeCheckInterval is how often the volumes of running executions are measured by default
+	DefaultVolumeCheckInterval = 30 * time.Second
+)
+
+type VolumeManagerParams struct {
+	// Dir is the directory where the volumes are stored, with a directory per job and volume
+	Dir string
+	// MaxSize is the maximum size in bytes of a volume, and the size of volumes that do not specify one
+	MaxSize uint64
+	// CheckInterval is how often the volumes of running executions are measured to enforce their max size
+	CheckInterval time.Duration
+	Clock         clock.Clock
+}
+
+// VolumeManager manages the persistent volumes that long running jobs keep on the compute node across
+// their executions, such as when a service job is updated or restarted. Each volume is a directory that
+// is mounted read-write into the executions of its job, and whose size is measured when an execution
+// using it starts and ends, and periodically while it runs. Volumes are only deleted on request, such as
+// when their job is stopped.
+type VolumeManager struct {
+	dir           string
+	maxSize       uint64
+	checkInterval time.Duration
+	clock         clock.Clock
+
+	mu      sync.Mutex
+	volumes map[models.VolumeRef]*models.VolumeInfo
+	// inUse tracks the executions using each volume, which prevents the volume from being deleted
+	inUse map[models.VolumeRef]map[string]bool
+}
+
+// NewVolumeManager creates a new volume manager, loading the volumes already stored in the directory
+func NewVolumeManager(params VolumeManagerParams) (*VolumeManager, error) {
+	if err := validate.NotBlank(params.Dir, "volumes directory cannot be blank"); err != nil {
+		return nil, err
+	}
+	if params.Clock == nil {
+		params.Clock = clock.New()
+	}
+	if params.CheckInterval <= 0 {
+		params.CheckInterval = DefaultVolumeCheckInterval
+	}
+	m := &VolumeManager{
+		dir:           params.Dir,
+		maxSize:       params.MaxSize,
+		checkInterval: params.CheckInterval,
+		clock:         params.Clock,
+		volumes:       make(map[models.VolumeRef]*models.VolumeInfo),
+		inUse:         make(map[models.VolumeRef]map[string]bool),
+	}
+	if err := m.load(); err != nil {
+		return nil, fmt.Errorf("failed to load volumes from %s: %w", params.Dir, err)
+	}
+	return m, nil
+}
+
+// load reads the metadata of the volumes stored in the directory
+func (m *VolumeManager) load() error {
+	infoFiles, err := filepath.Glob(filepath.Join(m.dir, "*", "*", volumeInfoFileName))
+	if err != nil {
+		return err
+	}
+	for _, infoFile := range infoFiles {
+		data, err := os.ReadFile(infoFile)
+		if err != nil {
+			return err
+		}
+		info := new(models.VolumeInfo)
+		if err = json.Unmarshal(data, info); err != nil {
+			log.Warn().Err(err).Str("path", infoFile).Msg("Skipping volume with invalid metadata")
+			continue
+		}
+		m.volumes[info.Ref()] = info
+	}
+	return nil
+}
+
+// MaxSize returns the maximum size in bytes of a volume on the node
+func (m *VolumeManager) MaxSize() uint64 {
+	return m.maxSize
+}
+
+// CheckInterval returns how often the volumes of running executions are measured
+func (m *VolumeManager) CheckInterval() time.Duration {
+	return m.checkInterval
+}
+
+// MountVolumes creates the persistent volumes of the execution's task if they don't exist yet, and returns
+// them to be mounted read-write into the execution. It fails if a volume exceeds its maximum size.
+// The volumes must be released with ReleaseVolumes when the execution ends.
+func (m *VolumeManager) MountVolumes(ctx context.Context, execution *models.Execution) ([]storage.StorageVolume, error) {
+	taskVolumes := execution.Job.Task().Volumes
+	if len(taskVolumes) == 0 {
+		return nil, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mounts := make([]storage.StorageVolume, 0, len(taskVolumes))
+	for _, volume := range taskVolumes {
+		mount, err := m.mountVolume(ctx, execution, volume)
+		if err != nil {
+			m.releaseLocked(ctx, execution)
+			return nil, err
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts, nil
+}
+
+// mountVolume creates the volume if it doesn't exist yet and marks it as used by the execution.
+// It must be called with mu held.
+func (m *VolumeManager) mountVolume(
+	ctx context.Context, execution *models.Execution, volume *models.PersistentVolume) (storage.StorageVolume, error) {
+	maxSize, err := volume.GetMaxSize()
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	if maxSize == 0 {
+		maxSize = m.maxSize
+	}
+	if m.maxSize > 0 && maxSize > m.maxSize {
+		return storage.StorageVolume{}, fmt.Errorf("max size of persistent volume %q exceeds the node's max size of %d bytes",
+			volume.Name, m.maxSize)
+	}
+
+	ref := models.VolumeRef{JobID: execution.JobID, Name: volume.Name}
+	dataDir := m.dataDir(ref)
+	if err = os.MkdirAll(dataDir, StorageDirectoryPerms); err != nil {
+		return storage.StorageVolume{}, fmt.Errorf("failed to create persistent volume %q: %w", volume.Name, err)
+	}
+
+	now := m.clock.Now().UTC()
+	info, ok := m.volumes[ref]
+	if !ok {
+		info = &models.VolumeInfo{
+			Namespace:  execution.Job.Namespace,
+			JobID:      execution.JobID,
+			Name:       volume.Name,
+			CreateTime: now,
+		}
+		m.volumes[ref] = info
+		log.Ctx(ctx).Debug().Str("volume", volume.Name).Msg("Created persistent volume")
+	}
+	if info.Size, err = dirSize(dataDir); err != nil {
+		return storage.StorageVolume{}, fmt.Errorf("failed to measure persistent volume %q: %w", volume.Name, err)
+	}
+	info.MaxSize = maxSize
+	info.LastUsedTime = now
+	m.saveLocked(ctx, info)
+	if maxSize > 0 && info.Size > maxSize {
+		return storage.StorageVolume{}, NewErrVolumeTooLarge(volume.Name, info.Size, maxSize)
+	}
+
+	if m.inUse[ref] == nil {
+		m.inUse[ref] = make(map[string]bool)
+	}
+	m.inUse[ref][execution.ID] = true
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: dataDir,
+		Target: volume.Target,
+	}, nil
+}
+
+// MeasureVolumes records the size of the volumes used by the running execution, and fails
+// if one of them exceeds its maximum size
+func (m *VolumeManager) MeasureVolumes(ctx context.Context, execution *models.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var mErr error
+	for ref, executions := range m.inUse {
+		if !executions[execution.ID] {
+			continue
+		}
+		info, ok := m.volumes[ref]
+		if !ok {
+			continue
+		}
+		if err := m.measureLocked(ctx, info); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("volume", ref.Name).Msg("Failed to measure persistent volume")
+			continue
+		}
+		if info.MaxSize > 0 && info.Size > info.MaxSize {
+			mErr = errors.Join(mErr, NewErrVolumeTooLarge(info.Name, info.Size, info.MaxSize))
+		}
+	}
+	return mErr
+}
+
+// ReleaseVolumes records the size of the execution's volumes after it ended, and allows them to be deleted
+// if no other execution uses them
+func (m *VolumeManager) ReleaseVolumes(ctx context.Context, execution *models.Execution) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.releaseLocked(ctx, execution)
+}
+
+// releaseLocked releases the volumes used by the execution. It must be called with mu held.
+func (m *VolumeManager) releaseLocked(ctx context.Context, execution *models.Execution) {
+	for ref, executions := range m.inUse {
+		if !executions[execution.ID] {
+			continue
+		}
+		delete(executions, execution.ID)
+		if len(executions) == 0 {
+			delete(m.inUse, ref)
+		}
+		info, ok := m.volumes[ref]
+		if !ok {
+			continue
+		}
+		if err := m.measureLocked(ctx, info); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("volume", ref.Name).Msg("Failed to measure persistent volume")
+			continue
+		}
+		if info.MaxSize > 0 && info.Size > info.MaxSize {
+			log.Ctx(ctx).Warn().Str("volume", ref.Name).Uint64("size", info.Size).Uint64("max_size", info.MaxSize).
+				Msg("Persistent volume exceeds its max size and will fail the next execution of the job on this node")
+		}
+	}
+}
+
+// measureLocked records the current size of the volume. It must be called with mu held.
+func (m *VolumeManager) measureLocked(ctx context.Context, info *models.VolumeInfo) error {
+	size, err := dirSize(m.dataDir(info.Ref()))
+	if err != nil {
+		return err
+	}
+	info.Size = size
+	info.LastUsedTime = m.clock.Now().UTC()
+	m.saveLocked(ctx, info)
+	return nil
+}
+
+// Volumes returns the volumes held by the node, sorted by job and name
+func (m *VolumeManager) Volumes() []models.VolumeInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	volumes := make([]models.VolumeInfo, 0, len(m.volumes))
+	for _, info := range m.volumes {
+		volumes = append(volumes, *info)
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		if volumes[i].JobID != volumes[j].JobID {
+			return volumes[i].JobID < volumes[j].JobID
+		}
+		return volumes[i].Name < volumes[j].Name
+	})
+	return volumes
+}
+
+// DeleteVolumes deletes the referenced volumes and their data. Volumes in use by running executions
+// are skipped, and deleted when requested again after the executions ended.
+func (m *VolumeManager) DeleteVolumes(ctx context.Context, refs []models.VolumeRef) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for ref := range m.volumes {
+		if !matchesAny(refs, ref) {
+			continue
+		}
+		if len(m.inUse[ref]) > 0 {
+			log.Ctx(ctx).Debug().Str("job", ref.JobID).Str("volume", ref.Name).
+				Msg("Skipping deletion of persistent volume in use")
+			continue
+		}
+		if err := os.RemoveAll(m.volumeDir(ref)); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("job", ref.JobID).Str("volume", ref.Name).
+				Msg("Failed to delete persistent volume")
+			continue
+		}
+		delete(m.volumes, ref)
+		// remove the directory of the job once it holds no more volumes
+		_ = os.Remove(filepath.Join(m.dir, ref.JobID))
+		log.Ctx(ctx).Info().Str("job", ref.JobID).Str("volume", ref.Name).Msg("Deleted persistent volume")
+	}
+}
+
+// saveLocked writes the metadata of the volume. It must be called with mu held.
+func (m *VolumeManager) saveLocked(ctx context.Context, info *models.VolumeInfo) {
+	data, err := json.Marshal(info)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(m.volumeDir(info.Ref()), volumeInfoFileName), data, 0o600)
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("volume", info.Name).Msg("Failed to save persistent volume metadata")
+	}
+}
+
+func (m *VolumeManager) volumeDir(ref models.VolumeRef) string {
+	return filepath.Join(m.dir, ref.JobID, ref.Name)
+}
+
+func (m *VolumeManager) dataDir(ref models.VolumeRef) string {
+	return filepath.Join(m.volumeDir(ref), volumeDataDirName)
+}
+
+func matchesAny(refs []models.VolumeRef, ref models.VolumeRef) bool {
+	for _, r := range refs {
+		if r.Matches(ref.JobID, ref.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// dirSize returns the total size of the regular files in the directory
+func dirSize(dir string) (uint64, error) {
+	var size uint64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// files may be removed by a running execution while walking
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		size += uint64(info.Size()) //nolint:gosec // G115: file sizes are never negative
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,152 @@
+//go:build unit || !integration
+
+package compute_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+type VolumeManagerTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	dir     string
+	manager *compute.VolumeManager
+	job     *models.Job
+}
+
+func TestVolumeManagerTestSuite(t *testing.T) {
+	suite.Run(t, new(VolumeManagerTestSuite))
+}
+
+func (s *VolumeManagerTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.dir = s.T().TempDir()
+	s.manager = s.newManager()
+	s.job = mock.Job()
+	s.job.Type = models.JobTypeService
+	s.job.Task().Volumes = []*models.PersistentVolume{
+		{Name: "data", Target: "/data", MaxSize: "1KB"},
+		{Name: "cache", Target: "/cache"},
+	}
+}
+
+func (s *VolumeManagerTestSuite) newManager() *compute.VolumeManager {
+	manager, err := compute.NewVolumeManager(compute.VolumeManagerParams{
+		Dir:     s.dir,
+		MaxSize: 2000,
+	})
+	s.Require().NoError(err)
+	return manager
+}
+
+func (s *VolumeManagerTestSuite) TestNoVolumes() {
+	mounts, err := s.manager.MountVolumes(s.ctx, mock.Execution())
+	s.Require().NoError(err)
+	s.Empty(mounts)
+	s.Empty(s.manager.Volumes())
+}
+
+func (s *VolumeManagerTestSuite) TestDataPersistsAcrossExecutions() {
+	first := mock.ExecutionForJob(s.job)
+	mounts, err := s.manager.MountVolumes(s.ctx, first)
+	s.Require().NoError(err)
+	s.Require().Len(mounts, 2)
+	s.Equal("/data", mounts[0].Target)
+	s.False(mounts[0].ReadOnly)
+	s.Require().NoError(os.WriteFile(filepath.Join(mounts[0].Source, "state"), []byte("hello"), 0o600))
+	s.manager.ReleaseVolumes(s.ctx, first)
+
+	volumes := s.manager.Volumes()
+	s.Require().Len(volumes, 2)
+	s.Equal("cache", volumes[0].Name)
+	s.Equal(uint64(2000), volumes[0].MaxSize, "volumes without a max size default to the node's")
+	s.Equal("data", volumes[1].Name)
+	s.Equal(uint64(5), volumes[1].Size)
+	s.Equal(uint64(1000), volumes[1].MaxSize)
+	s.Equal(s.job.Namespace, volumes[1].Namespace)
+
+	// a new execution of the job, such as after an update, on a restarted node finds the data
+	second := mock.ExecutionForJob(s.job)
+	mounts, err = s.newManager().MountVolumes(s.ctx, second)
+	s.Require().NoError(err)
+	data, err := os.ReadFile(filepath.Join(mounts[0].Source, "state"))
+	s.Require().NoError(err)
+	s.Equal("hello", string(data))
+}
+
+func (s *VolumeManagerTestSuite) TestVolumeTooLarge() {
+	execution := mock.ExecutionForJob(s.job)
+	mounts, err := s.manager.MountVolumes(s.ctx, execution)
+	s.Require().NoError(err)
+	s.Require().NoError(os.WriteFile(filepath.Join(mounts[0].Source, "state"), make([]byte, 1500), 0o600))
+	s.manager.ReleaseVolumes(s.ctx, execution)
+
+	_, err = s.manager.MountVolumes(s.ctx, mock.ExecutionForJob(s.job))
+	var tooLarge compute.ErrVolumeTooLarge
+	s.Require().True(errors.As(err, &tooLarge), "expected volume too large error, got %v", err)
+	s.Equal("data", tooLarge.Name)
+	s.False(tooLarge.Retryable())
+
+	// the failed execution doesn't hold the volumes, which can be deleted
+	s.manager.DeleteVolumes(s.ctx, []models.VolumeRef{{JobID: s.job.ID}})
+	s.Empty(s.manager.Volumes())
+}
+
+func (s *VolumeManagerTestSuite) TestMeasureVolumes() {
+	execution := mock.ExecutionForJob(s.job)
+	mounts, err := s.manager.MountVolumes(s.ctx, execution)
+	s.Require().NoError(err)
+	s.Require().NoError(s.manager.MeasureVolumes(s.ctx, execution))
+
+	// the running execution exceeds the max size of the volume
+	s.Require().NoError(os.WriteFile(filepath.Join(mounts[0].Source, "state"), make([]byte, 1500), 0o600))
+	err = s.manager.MeasureVolumes(s.ctx, execution)
+	var tooLarge compute.ErrVolumeTooLarge
+	s.Require().True(errors.As(err, &tooLarge), "expected volume too large error, got %v", err)
+	s.Equal("data", tooLarge.Name)
+	s.Equal(uint64(1500), tooLarge.Size)
+	s.Equal(uint64(1500), s.manager.Volumes()[1].Size)
+
+	// volumes of other executions are not measured
+	s.Require().NoError(s.manager.MeasureVolumes(s.ctx, mock.ExecutionForJob(s.job)))
+}
+
+func (s *VolumeManagerTestSuite) TestMaxSizeExceedsNodeMaxSize() {
+	s.job.Task().Volumes[0].MaxSize = "1MB"
+	_, err := s.manager.MountVolumes(s.ctx, mock.ExecutionForJob(s.job))
+	s.Require().ErrorContains(err, "exceeds the node's max size")
+}
+
+func (s *VolumeManagerTestSuite) TestDeleteVolumes() {
+	execution := mock.ExecutionForJob(s.job)
+	mounts, err := s.manager.MountVolumes(s.ctx, execution)
+	s.Require().NoError(err)
+
+	// volumes in use are not deleted
+	s.manager.DeleteVolumes(s.ctx, []models.VolumeRef{{JobID: s.job.ID, Name: "data"}})
+	s.Len(s.manager.Volumes(), 2)
+	s.DirExists(mounts[0].Source)
+
+	s.manager.ReleaseVolumes(s.ctx, execution)
+	s.manager.DeleteVolumes(s.ctx, []models.VolumeRef{{JobID: s.job.ID, Name: "data"}})
+	volumes := s.manager.Volumes()
+	s.Require().Len(volumes, 1)
+	s.Equal("cache", volumes[0].Name)
+	s.NoDirExists(mounts[0].Source)
+
+	// deleting all the volumes of the job removes its directory
+	s.manager.DeleteVolumes(s.ctx, []models.VolumeRef{{JobID: s.job.ID}})
+	s.Empty(s.manager.Volumes())
+	s.NoDirExists(filepath.Join(s.dir, s.job.ID))
+	s.Empty(s.newManager().Volumes())
+}
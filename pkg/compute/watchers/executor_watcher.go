@@ -51,6 +51,10 @@ func (h *ExecutionUpsertHandler) HandleEvent(ctx context.Context, event watcher.
 			logger.Error().Err(err).Msg("failed to run execution")
 		}
 	case models.ExecutionStateCancelled:
+		if !upsert.HasStateChange() {
+			// e.g. the results of the cancelled execution were recorded
+			return nil
+		}
 		err = h.executor.Cancel(ctx, execution)
 		if err != nil {
 			compute.ExecutionCancelErrors.Add(ctx, 1)
@@ -57,3 +57,20 @@ func TestExecutionUpsertHandlerPropagatesExecutorErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestExecutionUpsertHandlerCancelsOnStateChangeOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	executor := compute.NewMockExecutor(ctrl)
+	previous := mock.Execution()
+	previous.ComputeState = models.NewExecutionState(models.ExecutionStateCancelled)
+
+	// recording the results of a cancelled execution doesn't cancel it again
+	execution := previous.Copy()
+	execution.PublishedResult = &models.SpecConfig{Type: "myResult"}
+
+	handler := NewExecutionUpsertHandler(executor, compute.Bidder{})
+	err := handler.HandleEvent(context.Background(), watcher.Event{
+		Object: models.ExecutionUpsert{Current: execution, Previous: previous},
+	})
+	require.NoError(t, err)
+}
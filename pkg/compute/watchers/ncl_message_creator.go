@@ -55,6 +55,24 @@ func (d *NCLMessageCreator) CreateMessage(event watcher.Event) (*envelope.Messag
 			PublishResult:    execution.PublishedResult,
 			RunCommandResult: execution.RunOutput,
 		}).WithMetadataValue(envelope.KeyMessageType, messages.RunResultMessageType)
+	case models.ExecutionStateRunning:
+		if upsert.HasNewCheckpoint() {
+			log.Debug().Msgf("Execution %s published checkpoint %d", execution.ID, execution.Checkpoint.Sequence)
+			message = envelope.NewMessage(messages.CheckpointResult{
+				BaseResponse: baseResponse,
+				Checkpoint:   execution.Checkpoint,
+			}).WithMetadataValue(envelope.KeyMessageType, messages.CheckpointMessageType)
+		}
+	case models.ExecutionStateCancelled:
+		if upsert.HasNewPublishedResult() {
+			log.Debug().Msgf("Execution %s published results after being cancelled", execution.ID)
+			message = envelope.NewMessage(messages.RunResult{
+				BaseResponse:     baseResponse,
+				PublishResult:    execution.PublishedResult,
+				RunCommandResult: execution.RunOutput,
+				Cancelled:        true,
+			}).WithMetadataValue(envelope.KeyMessageType, messages.RunResultMessageType)
+		}
 	case models.ExecutionStateFailed:
 		log.Debug().Msgf("Execution %s failed", execution.ID)
 		message = envelope.NewMessage(messages.ComputeError{BaseResponse: baseResponse}).
@@ -169,6 +169,52 @@ func (s *NCLMessageCreatorTestSuite) TestCreateMessage_ExecutionFailed() {
 	s.Equal(execution.Job.Type, result.JobType)
 }
 
+func (s *NCLMessageCreatorTestSuite) TestCreateMessage_Checkpoint() {
+	previous := mock.Execution()
+	previous.Job.Meta[models.MetaOrchestratorProtocol] = models.ProtocolNCLV1.String()
+	previous.ComputeState = models.NewExecutionState(models.ExecutionStateRunning)
+
+	execution := previous.Copy()
+	execution.Checkpoint = &models.Checkpoint{Sequence: 1, Result: &models.SpecConfig{Type: "myCheckpoint"}}
+
+	msg, err := s.creator.CreateMessage(watcher.Event{
+		Object: models.ExecutionUpsert{
+			Current:  execution,
+			Previous: previous,
+		},
+	})
+
+	s.Require().NoError(err)
+	s.Require().NotNil(msg)
+
+	s.Equal(messages.CheckpointMessageType, msg.Metadata.Get(envelope.KeyMessageType))
+
+	payload, ok := msg.GetPayload(messages.CheckpointResult{})
+	s.Require().True(ok)
+	result := payload.(messages.CheckpointResult)
+
+	s.Equal(execution.ID, result.ExecutionID)
+	s.Equal(uint64(1), result.Checkpoint.Sequence)
+	s.Equal("myCheckpoint", result.Checkpoint.Result.Type)
+}
+
+func (s *NCLMessageCreatorTestSuite) TestCreateMessage_RunningWithoutNewCheckpoint() {
+	previous := mock.Execution()
+	previous.Job.Meta[models.MetaOrchestratorProtocol] = models.ProtocolNCLV1.String()
+	previous.ComputeState = models.NewExecutionState(models.ExecutionStateRunning)
+	previous.Checkpoint = &models.Checkpoint{Sequence: 1, Result: &models.SpecConfig{Type: "myCheckpoint"}}
+
+	msg, err := s.creator.CreateMessage(watcher.Event{
+		Object: models.ExecutionUpsert{
+			Current:  previous.Copy(),
+			Previous: previous,
+		},
+	})
+
+	s.NoError(err)
+	s.Nil(msg)
+}
+
 func (s *NCLMessageCreatorTestSuite) TestCreateMessage_UnhandledState() {
 	execution := mock.Execution()
 	execution.Job.Meta[models.MetaOrchestratorProtocol] = models.ProtocolNCLV1.String()
@@ -185,3 +231,42 @@ func (s *NCLMessageCreatorTestSuite) TestCreateMessage_UnhandledState() {
 	s.NoError(err)
 	s.Nil(msg)
 }
+
+func (s *NCLMessageCreatorTestSuite) TestCreateMessage_CancelledExecutionPublishedResult() {
+	previous := mock.Execution()
+	previous.Job.Meta[models.MetaOrchestratorProtocol] = models.ProtocolNCLV1.String()
+	previous.ComputeState = models.NewExecutionState(models.ExecutionStateCancelled)
+	previous.PublishedResult = nil
+
+	// no message when the execution is cancelled
+	msg, err := s.creator.CreateMessage(watcher.Event{
+		Object: models.ExecutionUpsert{Current: previous},
+	})
+	s.Require().NoError(err)
+	s.Nil(msg)
+
+	execution := previous.Copy()
+	execution.PublishedResult = &models.SpecConfig{Type: "myResult"}
+	execution.RunOutput = &models.RunCommandResult{ExitCode: 143}
+
+	msg, err = s.creator.CreateMessage(watcher.Event{
+		Object: models.ExecutionUpsert{
+			Current:  execution,
+			Previous: previous,
+		},
+	})
+
+	s.Require().NoError(err)
+	s.Require().NotNil(msg)
+
+	s.Equal(messages.RunResultMessageType, msg.Metadata.Get(envelope.KeyMessageType))
+
+	payload, ok := msg.GetPayload(messages.RunResult{})
+	s.Require().True(ok)
+	result := payload.(messages.RunResult)
+
+	s.True(result.Cancelled)
+	s.Equal(execution.ID, result.ExecutionID)
+	s.Equal("myResult", result.PublishResult.Type)
+	s.Equal(143, result.RunCommandResult.ExitCode)
+}
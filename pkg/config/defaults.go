@@ -62,6 +62,15 @@ var Default = types.Bacalhau{
 			Disk:   "80%",
 			GPU:    "100%",
 		},
+		Queue: types.ComputeQueueConfig{
+			Policy:               "aging",
+			AgingInterval:        types.Minute,
+			ReservationThreshold: 10 * types.Minute,
+		},
+		Volumes: types.ComputeVolumesConfig{
+			MaxSize:       "10GB",
+			CheckInterval: 30 * types.Second,
+		},
 	},
 	JobDefaults: types.JobDefaults{
 		Batch: types.BatchJobDefaultsConfig{
@@ -112,7 +121,23 @@ var Default = types.Bacalhau{
 					Size:    1000,
 					TTL:     types.Duration(1 * time.Hour),
 					Refresh: types.Duration(1 * time.Hour),
-				}},
+				},
+				Policy: types.DockerPolicy{
+					AllowedUlimits: []string{"core", "memlock", "nofile", "nproc", "stack"},
+				},
+				ImageCache: types.DockerImageCache{
+					CleanupInterval: types.Duration(10 * time.Minute),
+				},
+			},
+			WASM: types.WASM{
+				CompilationCache: types.WASMCompilationCache{
+					MaxSize: "1Gi",
+				},
+				KeyValue: types.WASMKeyValue{
+					Bucket:       "wasm_kv",
+					MaxValueSize: "1Mi",
+				},
+			},
 		},
 	},
 	Publishers: types.PublishersConfig{
@@ -17,6 +17,38 @@ type Compute struct {
 	TLS ComputeTLS `yaml:"TLS,omitempty" json:"TLS,omitempty"`
 	// Env specifies environment variable configuration for the compute node
 	Env EnvConfig `yaml:"Env,omitempty" json:"Env,omitempty"`
+	// Queue specifies how executions waiting for capacity on the compute node are ordered
+	Queue ComputeQueueConfig `yaml:"Queue,omitempty" json:"Queue,omitempty"`
+	// Volumes specifies the persistent volumes that long running jobs keep on the compute node
+	Volumes ComputeVolumesConfig `yaml:"Volumes,omitempty" json:"Volumes,omitempty"`
+}
+
+// ComputeVolumesConfig specifies the persistent volumes that long running jobs keep on the compute node
+// across their executions
+type ComputeVolumesConfig struct {
+	// Disabled specifies whether the compute node rejects jobs with persistent volumes.
+	Disabled bool `yaml:"Disabled,omitempty" json:"Disabled,omitempty"`
+	// MaxSize specifies the maximum size of a persistent volume, such as "10GB". It is also the size
+	// of the volumes that do not specify a maximum size.
+	MaxSize string `yaml:"MaxSize,omitempty" json:"MaxSize,omitempty"`
+	// CheckInterval specifies how often the persistent volumes of running executions are measured. Executions
+	// are stopped once one of their volumes exceeds its maximum size.
+	CheckInterval Duration `yaml:"CheckInterval,omitempty" json:"CheckInterval,omitempty"`
+}
+
+// ComputeQueueConfig specifies how executions waiting for capacity on the compute node are ordered
+type ComputeQueueConfig struct {
+	// Policy specifies the queueing policy: "fifo" runs executions strictly in order, "backfill" lets smaller
+	// executions skip ahead of ones that do not fit, and "aging" backfills until an execution waits past the
+	// reservation threshold, after which only executions that leave it enough capacity skip ahead of it.
+	Policy string `yaml:"Policy,omitempty" json:"Policy,omitempty"`
+	// AgingInterval specifies the waiting time after which an enqueued execution's priority is raised by one
+	// under the aging policy.
+	AgingInterval Duration `yaml:"AgingInterval,omitempty" json:"AgingInterval,omitempty"`
+	// ReservationThreshold specifies the waiting time after which capacity is reserved for an execution that
+	// does not fit under the aging policy, so that executions can only skip ahead of it if they leave it enough
+	// of every kind of resource they use.
+	ReservationThreshold Duration `yaml:"ReservationThreshold,omitempty" json:"ReservationThreshold,omitempty"`
 }
 
 type ComputeAuth struct {
@@ -46,6 +78,40 @@ type EnvConfig struct {
 	// AllowList specifies which host environment variables can be forwarded to jobs.
 	// Supports glob patterns (e.g., "AWS_*", "API_*")
 	AllowList []string `yaml:"AllowList,omitempty" json:"AllowList,omitempty"`
+	// Secrets configures the backends used to resolve secret references in task environment variables.
+	// References have the form "secret:<backend>/<key>", e.g. "secret:vault/secret/data/app#password".
+	Secrets SecretsConfig `yaml:"Secrets,omitempty" json:"Secrets,omitempty"`
+}
+
+// SecretsConfig specifies the secret backends available on the compute node
+type SecretsConfig struct {
+	// File configures an encrypted secret store on the local node, referenced as "secret:file/<name>".
+	File FileSecretsConfig `yaml:"File,omitempty" json:"File,omitempty"`
+	// Vault configures a HashiCorp Vault compatible secret backend, referenced as "secret:vault/<path>#<field>".
+	Vault VaultSecretsConfig `yaml:"Vault,omitempty" json:"Vault,omitempty"`
+}
+
+// FileSecretsConfig specifies an encrypted secret store on the local file system
+type FileSecretsConfig struct {
+	// Enabled indicates whether the encrypted file secret store is enabled.
+	Enabled bool `yaml:"Enabled,omitempty" json:"Enabled,omitempty"`
+	// Path specifies the encrypted secrets file. Defaults to secrets.json in the node's compute or orchestrator directory.
+	Path string `yaml:"Path,omitempty" json:"Path,omitempty"`
+	// KeyFile specifies the file holding the key used to encrypt the secrets file.
+	// It is created if it does not exist. Defaults to secrets.key next to the secrets file.
+	KeyFile string `yaml:"KeyFile,omitempty" json:"KeyFile,omitempty"`
+}
+
+// VaultSecretsConfig specifies a HashiCorp Vault compatible secret backend
+type VaultSecretsConfig struct {
+	// Address specifies the URL of the Vault server. The backend is disabled if empty.
+	Address string `yaml:"Address,omitempty" json:"Address,omitempty"`
+	// Token specifies the token used to authenticate with Vault.
+	Token string `yaml:"Token,omitempty" json:"Token,omitempty"`
+	// Namespace specifies the Vault enterprise namespace to read secrets from.
+	Namespace string `yaml:"Namespace,omitempty" json:"Namespace,omitempty"`
+	// Timeout specifies the maximum duration of a request to Vault.
+	Timeout Duration `yaml:"Timeout,omitempty" json:"Timeout,omitempty"`
 }
 
 // NetworkConfig specifies networking configuration for the compute node
@@ -17,6 +17,7 @@ type ResultDownloaders struct {
 
 type ResultDownloadersTypes struct {
 	IPFS IpfsDownloader `yaml:"IPFS,omitempty" json:"IPFS,omitempty"`
+	OCI  OCIRegistry    `yaml:"OCI,omitempty" json:"OCI,omitempty"`
 }
 
 func (r ResultDownloaders) IsNotDisabled(kind string) bool {
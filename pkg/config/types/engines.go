@@ -28,6 +28,65 @@ func (e EngineConfig) IsNotDisabled(kind string) bool {
 type Docker struct {
 	// ManifestCache specifies the settings for the Docker manifest cache.
 	ManifestCache DockerManifestCache `yaml:"ManifestCache,omitempty" json:"ManifestCache,omitempty"`
+	// Policy restricts the container options that jobs can request.
+	Policy DockerPolicy `yaml:"Policy,omitempty" json:"Policy,omitempty"`
+	// ImagePolicy restricts the images that jobs can run.
+	ImagePolicy DockerImagePolicy `yaml:"ImagePolicy,omitempty" json:"ImagePolicy,omitempty"`
+	// ImageCache specifies the images kept pulled on this compute node.
+	ImageCache DockerImageCache `yaml:"ImageCache,omitempty" json:"ImageCache,omitempty"`
+}
+
+// DockerImageCache configures the images that the compute node keeps pulled, so that executions don't wait
+// for cold image pulls. Besides the configured images, the node pulls the images of recently popular jobs
+// hinted by the orchestrator, and advertises the digests of its cached images so that jobs are preferably
+// scheduled on nodes that already have their image.
+type DockerImageCache struct {
+	// Disabled disables pre-pulling, advertising and removing images.
+	Disabled bool `yaml:"Disabled,omitempty" json:"Disabled,omitempty"`
+	// PrePull specifies images that are pulled when the node starts, and are never removed by the node.
+	PrePull []string `yaml:"PrePull,omitempty" json:"PrePull,omitempty"`
+	// MaxSize specifies the disk budget of the images pulled by the node, e.g. "50Gi". The least recently
+	// used images that are not used by any container are removed when the budget is exceeded.
+	// Images are never removed if empty.
+	MaxSize string `yaml:"MaxSize,omitempty" json:"MaxSize,omitempty"`
+	// CleanupInterval specifies how often the images pulled by the node are checked against the disk budget.
+	CleanupInterval Duration `yaml:"CleanupInterval,omitempty" json:"CleanupInterval,omitempty"`
+}
+
+// DockerImagePolicy restricts the images that docker jobs can run on this compute node.
+// Jobs with images that don't satisfy the policy are rejected when bidding, before anything is pulled.
+type DockerImagePolicy struct {
+	// AllowedImages specifies glob patterns of the images jobs can run, matched against the registry and
+	// repository of the image, e.g. "docker.io/library/*" or "ghcr.io/my-org/**". All images are allowed if empty.
+	AllowedImages []string `yaml:"AllowedImages,omitempty" json:"AllowedImages,omitempty"`
+	// DeniedImages specifies glob patterns of the images jobs cannot run, even if they are allowed.
+	DeniedImages []string `yaml:"DeniedImages,omitempty" json:"DeniedImages,omitempty"`
+	// RequireDigest pins every image to a digest. Images referenced by tag are resolved to their digest
+	// when bidding, and the resolved digest is run and recorded in the execution.
+	RequireDigest bool `yaml:"RequireDigest,omitempty" json:"RequireDigest,omitempty"`
+	// PublicKeys specifies the paths of PEM encoded cosign public keys. When set, images must have a cosign
+	// signature that is verified by one of the keys.
+	PublicKeys []string `yaml:"PublicKeys,omitempty" json:"PublicKeys,omitempty"`
+	// InsecureRegistries specifies the registries, e.g. "localhost:5000", that are accessed over plain HTTP
+	// when resolving digests and verifying signatures.
+	InsecureRegistries []string `yaml:"InsecureRegistries,omitempty" json:"InsecureRegistries,omitempty"`
+}
+
+// DockerPolicy restricts the container options that docker jobs can request on this compute node.
+// Jobs that exceed the policy are rejected when bidding.
+type DockerPolicy struct {
+	// MaxShmSize specifies the largest /dev/shm size a job can request, e.g. "2Gi". No limit if empty.
+	MaxShmSize string `yaml:"MaxShmSize,omitempty" json:"MaxShmSize,omitempty"`
+	// MaxTmpfsSize specifies the largest total size of the tmpfs mounts of a job, e.g. "4Gi".
+	// When set, every tmpfs mount must specify its size. No limit if empty.
+	MaxTmpfsSize string `yaml:"MaxTmpfsSize,omitempty" json:"MaxTmpfsSize,omitempty"`
+	// AllowedCapabilities specifies the Linux capabilities jobs can add, e.g. "SYS_PTRACE".
+	// Jobs cannot add any other capability, while dropping capabilities is always allowed.
+	AllowedCapabilities []string `yaml:"AllowedCapabilities,omitempty" json:"AllowedCapabilities,omitempty"`
+	// AllowedUlimits specifies the ulimits jobs can set, e.g. "nofile".
+	AllowedUlimits []string `yaml:"AllowedUlimits,omitempty" json:"AllowedUlimits,omitempty"`
+	// RequireNonRootUser rejects jobs that do not run as a non-root user.
+	RequireNonRootUser bool `yaml:"RequireNonRootUser,omitempty" json:"RequireNonRootUser,omitempty"`
 }
 
 // DockerManifestCache represents the configuration settings for the Docker manifest cache.
@@ -41,4 +100,52 @@ type DockerManifestCache struct {
 }
 
 type WASM struct {
+	// CompilationCache specifies the settings for the on-disk cache of compiled WASM modules.
+	CompilationCache WASMCompilationCache `yaml:"CompilationCache,omitempty" json:"CompilationCache,omitempty"`
+	// KeyValue specifies the settings of the key-value store available to WASM jobs.
+	KeyValue WASMKeyValue `yaml:"KeyValue,omitempty" json:"KeyValue,omitempty"`
+	// ObjectStore specifies the object store buckets available to WASM jobs.
+	ObjectStore WASMObjectStore `yaml:"ObjectStore,omitempty" json:"ObjectStore,omitempty"`
+}
+
+// WASMKeyValue represents the configuration settings for the key-value store of WASM jobs, which is stored
+// on the orchestrator. Jobs can only access the store if their network configuration is not disabled.
+type WASMKeyValue struct {
+	// Disabled specifies whether WASM jobs can use the key-value store.
+	Disabled bool `yaml:"Disabled,omitempty" json:"Disabled,omitempty"`
+	// Bucket specifies the name of the key-value bucket on the orchestrator that stores the keys of WASM jobs.
+	Bucket string `yaml:"Bucket,omitempty" json:"Bucket,omitempty"`
+	// MaxValueSize specifies the maximum size of a value, e.g. "1Mi".
+	MaxValueSize string `yaml:"MaxValueSize,omitempty" json:"MaxValueSize,omitempty"`
+}
+
+// WASMObjectStore represents the configuration settings for the object store buckets of WASM jobs.
+type WASMObjectStore struct {
+	// Buckets specifies the S3 buckets WASM jobs can read and write, using the credentials of the compute node.
+	// Jobs can only access a bucket if their network configuration allows them to reach its host.
+	Buckets []WASMBucket `yaml:"Buckets,omitempty" json:"Buckets,omitempty"`
+}
+
+// WASMBucket represents an S3 bucket available to WASM jobs.
+type WASMBucket struct {
+	// Name specifies the name of the bucket.
+	Name string `yaml:"Name,omitempty" json:"Name,omitempty"`
+	// Region specifies the region of the bucket.
+	Region string `yaml:"Region,omitempty" json:"Region,omitempty"`
+	// Endpoint specifies the endpoint of S3 compatible object stores.
+	Endpoint string `yaml:"Endpoint,omitempty" json:"Endpoint,omitempty"`
+	// ReadOnly specifies whether jobs are prevented from writing to the bucket.
+	ReadOnly bool `yaml:"ReadOnly,omitempty" json:"ReadOnly,omitempty"`
+}
+
+// WASMCompilationCache represents the configuration settings for the cache of compiled WASM modules,
+// which is shared by all WASM executions on the node.
+type WASMCompilationCache struct {
+	// Disabled specifies whether compiled WASM modules are cached.
+	Disabled bool `yaml:"Disabled,omitempty" json:"Disabled,omitempty"`
+	// Dir specifies the directory of the cache. Defaults to a directory in the compute node's data directory.
+	Dir string `yaml:"Dir,omitempty" json:"Dir,omitempty"`
+	// MaxSize specifies the maximum size of the cache, e.g. "1Gi". The least recently used modules are evicted
+	// once the cache grows past it. No limit if empty.
+	MaxSize string `yaml:"MaxSize,omitempty" json:"MaxSize,omitempty"`
 }
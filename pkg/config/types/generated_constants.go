@@ -33,6 +33,13 @@ const ComputeAllowListedLocalPathsKey = "Compute.AllowListedLocalPaths"
 const ComputeAuthTokenKey = "Compute.Auth.Token" //nolint:gosec // G101: Not a credential, just a config key name
 const ComputeEnabledKey = "Compute.Enabled"
 const ComputeEnvAllowListKey = "Compute.Env.AllowList"
+const ComputeEnvSecretsFileEnabledKey = "Compute.Env.Secrets.File.Enabled"
+const ComputeEnvSecretsFileKeyFileKey = "Compute.Env.Secrets.File.KeyFile"
+const ComputeEnvSecretsFilePathKey = "Compute.Env.Secrets.File.Path"
+const ComputeEnvSecretsVaultAddressKey = "Compute.Env.Secrets.Vault.Address"
+const ComputeEnvSecretsVaultNamespaceKey = "Compute.Env.Secrets.Vault.Namespace"
+const ComputeEnvSecretsVaultTimeoutKey = "Compute.Env.Secrets.Vault.Timeout"
+const ComputeEnvSecretsVaultTokenKey = "Compute.Env.Secrets.Vault.Token" //nolint:gosec // G101: Not a credential, just a config key name
 const ComputeHeartbeatInfoUpdateIntervalKey = "Compute.Heartbeat.InfoUpdateInterval"
 const ComputeHeartbeatIntervalKey = "Compute.Heartbeat.Interval"
 const ComputeHeartbeatResourceUpdateIntervalKey = "Compute.Heartbeat.ResourceUpdateInterval"
@@ -40,18 +47,47 @@ const ComputeNetworkAdvertisedAddressKey = "Compute.Network.AdvertisedAddress"
 const ComputeNetworkPortRangeEndKey = "Compute.Network.PortRangeEnd"
 const ComputeNetworkPortRangeStartKey = "Compute.Network.PortRangeStart"
 const ComputeOrchestratorsKey = "Compute.Orchestrators"
+const ComputeQueueAgingIntervalKey = "Compute.Queue.AgingInterval"
+const ComputeQueuePolicyKey = "Compute.Queue.Policy"
+const ComputeQueueReservationThresholdKey = "Compute.Queue.ReservationThreshold"
 const ComputeTLSCACertKey = "Compute.TLS.CACert"
 const ComputeTLSRequireTLSKey = "Compute.TLS.RequireTLS"
+const ComputeVolumesCheckIntervalKey = "Compute.Volumes.CheckInterval"
+const ComputeVolumesDisabledKey = "Compute.Volumes.Disabled"
+const ComputeVolumesMaxSizeKey = "Compute.Volumes.MaxSize"
 const DataDirKey = "DataDir"
 const DisableAnalyticsKey = "DisableAnalytics"
 const EnginesDisabledKey = "Engines.Disabled"
+const EnginesTypesDockerImageCacheCleanupIntervalKey = "Engines.Types.Docker.ImageCache.CleanupInterval"
+const EnginesTypesDockerImageCacheDisabledKey = "Engines.Types.Docker.ImageCache.Disabled"
+const EnginesTypesDockerImageCacheMaxSizeKey = "Engines.Types.Docker.ImageCache.MaxSize"
+const EnginesTypesDockerImageCachePrePullKey = "Engines.Types.Docker.ImageCache.PrePull"
+const EnginesTypesDockerImagePolicyAllowedImagesKey = "Engines.Types.Docker.ImagePolicy.AllowedImages"
+const EnginesTypesDockerImagePolicyDeniedImagesKey = "Engines.Types.Docker.ImagePolicy.DeniedImages"
+const EnginesTypesDockerImagePolicyInsecureRegistriesKey = "Engines.Types.Docker.ImagePolicy.InsecureRegistries"
+const EnginesTypesDockerImagePolicyPublicKeysKey = "Engines.Types.Docker.ImagePolicy.PublicKeys"
+const EnginesTypesDockerImagePolicyRequireDigestKey = "Engines.Types.Docker.ImagePolicy.RequireDigest"
 const EnginesTypesDockerManifestCacheRefreshKey = "Engines.Types.Docker.ManifestCache.Refresh"
 const EnginesTypesDockerManifestCacheSizeKey = "Engines.Types.Docker.ManifestCache.Size"
 const EnginesTypesDockerManifestCacheTTLKey = "Engines.Types.Docker.ManifestCache.TTL"
+const EnginesTypesDockerPolicyAllowedCapabilitiesKey = "Engines.Types.Docker.Policy.AllowedCapabilities"
+const EnginesTypesDockerPolicyAllowedUlimitsKey = "Engines.Types.Docker.Policy.AllowedUlimits"
+const EnginesTypesDockerPolicyMaxShmSizeKey = "Engines.Types.Docker.Policy.MaxShmSize"
+const EnginesTypesDockerPolicyMaxTmpfsSizeKey = "Engines.Types.Docker.Policy.MaxTmpfsSize"
+const EnginesTypesDockerPolicyRequireNonRootUserKey = "Engines.Types.Docker.Policy.RequireNonRootUser"
+const EnginesTypesWASMCompilationCacheDirKey = "Engines.Types.WASM.CompilationCache.Dir"
+const EnginesTypesWASMCompilationCacheDisabledKey = "Engines.Types.WASM.CompilationCache.Disabled"
+const EnginesTypesWASMCompilationCacheMaxSizeKey = "Engines.Types.WASM.CompilationCache.MaxSize"
+const EnginesTypesWASMKeyValueBucketKey = "Engines.Types.WASM.KeyValue.Bucket"
+const EnginesTypesWASMKeyValueDisabledKey = "Engines.Types.WASM.KeyValue.Disabled"
+const EnginesTypesWASMKeyValueMaxValueSizeKey = "Engines.Types.WASM.KeyValue.MaxValueSize"
+const EnginesTypesWASMObjectStoreBucketsKey = "Engines.Types.WASM.ObjectStore.Buckets"
 const InputSourcesDisabledKey = "InputSources.Disabled"
 const InputSourcesMaxRetryCountKey = "InputSources.MaxRetryCount"
 const InputSourcesReadTimeoutKey = "InputSources.ReadTimeout"
+const InputSourcesTypesGitMirrorDirKey = "InputSources.Types.Git.MirrorDir"
 const InputSourcesTypesIPFSEndpointKey = "InputSources.Types.IPFS.Endpoint"
+const InputSourcesTypesOCIInsecureRegistriesKey = "InputSources.Types.OCI.InsecureRegistries"
 const JobAdmissionControlLocalityKey = "JobAdmissionControl.Locality"
 const JobAdmissionControlProbeExecKey = "JobAdmissionControl.ProbeExec"
 const JobAdmissionControlProbeHTTPKey = "JobAdmissionControl.ProbeHTTP"
@@ -108,6 +144,9 @@ const OrchestratorSchedulerHousekeepingIntervalKey = "Orchestrator.Scheduler.Hou
 const OrchestratorSchedulerHousekeepingTimeoutKey = "Orchestrator.Scheduler.HousekeepingTimeout"
 const OrchestratorSchedulerQueueBackoffKey = "Orchestrator.Scheduler.QueueBackoff"
 const OrchestratorSchedulerWorkerCountKey = "Orchestrator.Scheduler.WorkerCount"
+const OrchestratorSecretsEnabledKey = "Orchestrator.Secrets.Enabled"
+const OrchestratorSecretsKeyFileKey = "Orchestrator.Secrets.KeyFile"
+const OrchestratorSecretsPathKey = "Orchestrator.Secrets.Path"
 const OrchestratorSupportReverseProxyKey = "Orchestrator.SupportReverseProxy"
 const OrchestratorTLSCACertKey = "Orchestrator.TLS.CACert"
 const OrchestratorTLSServerCertKey = "Orchestrator.TLS.ServerCert"
@@ -117,16 +156,24 @@ const PublishersDisabledKey = "Publishers.Disabled"
 const PublishersTypesIPFSEndpointKey = "Publishers.Types.IPFS.Endpoint"
 const PublishersTypesLocalAddressKey = "Publishers.Types.Local.Address"
 const PublishersTypesLocalPortKey = "Publishers.Types.Local.Port"
+const PublishersTypesOCIInsecureRegistriesKey = "Publishers.Types.OCI.InsecureRegistries"
 const PublishersTypesS3PreSignedURLDisabledKey = "Publishers.Types.S3.PreSignedURLDisabled"
 const PublishersTypesS3PreSignedURLExpirationKey = "Publishers.Types.S3.PreSignedURLExpiration"
+const PublishersTypesS3UploadConcurrencyKey = "Publishers.Types.S3.Upload.Concurrency"
+const PublishersTypesS3UploadMaxRetriesKey = "Publishers.Types.S3.Upload.MaxRetries"
+const PublishersTypesS3UploadPartSizeKey = "Publishers.Types.S3.Upload.PartSize"
 const PublishersTypesS3ManagedBucketKey = "Publishers.Types.S3Managed.Bucket"
 const PublishersTypesS3ManagedEndpointKey = "Publishers.Types.S3Managed.Endpoint"
 const PublishersTypesS3ManagedKeyKey = "Publishers.Types.S3Managed.Key"
 const PublishersTypesS3ManagedPreSignedURLExpirationKey = "Publishers.Types.S3Managed.PreSignedURLExpiration"
 const PublishersTypesS3ManagedRegionKey = "Publishers.Types.S3Managed.Region"
+const PublishersTypesS3ManagedUploadConcurrencyKey = "Publishers.Types.S3Managed.Upload.Concurrency"
+const PublishersTypesS3ManagedUploadMaxRetriesKey = "Publishers.Types.S3Managed.Upload.MaxRetries"
+const PublishersTypesS3ManagedUploadPartSizeKey = "Publishers.Types.S3Managed.Upload.PartSize"
 const ResultDownloadersDisabledKey = "ResultDownloaders.Disabled"
 const ResultDownloadersTimeoutKey = "ResultDownloaders.Timeout"
 const ResultDownloadersTypesIPFSEndpointKey = "ResultDownloaders.Types.IPFS.Endpoint"
+const ResultDownloadersTypesOCIInsecureRegistriesKey = "ResultDownloaders.Types.OCI.InsecureRegistries"
 const StrictVersionMatchKey = "StrictVersionMatch"
 const UpdateConfigIntervalKey = "UpdateConfig.Interval"
 const WebUIBackendKey = "WebUI.Backend"
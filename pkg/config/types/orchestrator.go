@@ -17,6 +17,10 @@ type Orchestrator struct {
 	NodeManager      NodeManager      `yaml:"NodeManager,omitempty" json:"NodeManager,omitempty"`
 	Scheduler        Scheduler        `yaml:"Scheduler,omitempty" json:"Scheduler,omitempty"`
 	EvaluationBroker EvaluationBroker `yaml:"EvaluationBroker,omitempty" json:"EvaluationBroker,omitempty"`
+	// Secrets configures the encrypted secret store holding secrets referenced by jobs as
+	// "secret:orchestrator/<name>". Referenced secrets are sealed to the key of the compute node
+	// an execution is assigned to.
+	Secrets FileSecretsConfig `yaml:"Secrets,omitempty" json:"Secrets,omitempty"`
 	// SupportReverseProxy configures the orchestrator node to run behind a reverse proxy
 	SupportReverseProxy bool `yaml:"SupportReverseProxy,omitempty" json:"SupportReverseProxy,omitempty"`
 }
@@ -107,6 +107,20 @@ func (b Bacalhau) ExecutionDir() (string, error) {
 	return path, nil
 }
 
+const VolumesDirName = "volumes"
+
+// VolumesDir returns the directory of the persistent volumes of jobs on the compute node
+func (b Bacalhau) VolumesDir() (string, error) {
+	if b.DataDir == "" {
+		return "", fmt.Errorf("data dir not set")
+	}
+	path := filepath.Join(b.DataDir, ComputeDirName, VolumesDirName)
+	if err := ensureDir(path); err != nil {
+		return "", fmt.Errorf("getting volumes path: %w", err)
+	}
+	return path, nil
+}
+
 const ResultsStorageDir = "results"
 
 func (b Bacalhau) ResultsStorageDir() (string, error) {
@@ -120,6 +134,53 @@ func (b Bacalhau) ResultsStorageDir() (string, error) {
 	return path, nil
 }
 
+const WASMCompilationCacheDirName = "wasm-cache"
+
+// WASMCompilationCacheDir returns the directory of the compiled WASM module cache,
+// defaulting to a directory in the compute directory
+func (b Bacalhau) WASMCompilationCacheDir() (string, error) {
+	path := b.Engines.Types.WASM.CompilationCache.Dir
+	if path == "" {
+		if b.DataDir == "" {
+			return "", fmt.Errorf("data dir not set")
+		}
+		path = filepath.Join(b.DataDir, ComputeDirName, WASMCompilationCacheDirName)
+	}
+	if err := ensureDir(path); err != nil {
+		return "", fmt.Errorf("getting wasm compilation cache path: %w", err)
+	}
+	return path, nil
+}
+
+const GitMirrorDirName = "git-mirrors"
+
+// GitMirrorDir returns the directory where the git input source caches repository mirrors,
+// defaulting to a directory in the compute directory
+func (b Bacalhau) GitMirrorDir() (string, error) {
+	path := b.InputSources.Types.Git.MirrorDir
+	if path == "" {
+		if b.DataDir == "" {
+			return "", fmt.Errorf("data dir not set")
+		}
+		path = filepath.Join(b.DataDir, ComputeDirName, GitMirrorDirName)
+	}
+	if err := ensureDir(path); err != nil {
+		return "", fmt.Errorf("getting git mirror path: %w", err)
+	}
+	return path, nil
+}
+
+const DockerImageCacheFileName = "docker_images.json"
+
+// DockerImageCacheFilePath returns the path of the file recording the docker images pulled by the compute node
+func (b Bacalhau) DockerImageCacheFilePath() (string, error) {
+	dir, err := b.ComputeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting docker image cache path: %w", err)
+	}
+	return filepath.Join(dir, DockerImageCacheFileName), nil
+}
+
 const ExecutionStoreFileName = "state_boltdb.db"
 
 func (b Bacalhau) ExecutionStoreFilePath() (string, error) {
@@ -131,3 +192,41 @@ func (b Bacalhau) ExecutionStoreFilePath() (string, error) {
 	}
 	return filepath.Join(b.DataDir, ComputeDirName, ExecutionStoreFileName), nil
 }
+
+const (
+	SecretsFileName    = "secrets.json"
+	SecretsKeyFileName = "secrets.key"
+)
+
+// ComputeSecretsPaths returns the paths of the compute node's encrypted secrets file and its key file
+func (b Bacalhau) ComputeSecretsPaths() (string, string, error) {
+	dir, err := b.ComputeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("getting compute secrets path: %w", err)
+	}
+	path, keyFile := b.Compute.Env.Secrets.File.paths(dir)
+	return path, keyFile, nil
+}
+
+// OrchestratorSecretsPaths returns the paths of the orchestrator's encrypted secrets file and its key file
+func (b Bacalhau) OrchestratorSecretsPaths() (string, string, error) {
+	dir, err := b.OrchestratorDir()
+	if err != nil {
+		return "", "", fmt.Errorf("getting orchestrator secrets path: %w", err)
+	}
+	path, keyFile := b.Orchestrator.Secrets.paths(dir)
+	return path, keyFile, nil
+}
+
+// paths returns the configured secrets file and key file, defaulting to files in dir
+func (c FileSecretsConfig) paths(dir string) (string, string) {
+	path := c.Path
+	if path == "" {
+		path = filepath.Join(dir, SecretsFileName)
+	}
+	keyFile := c.KeyFile
+	if keyFile == "" {
+		keyFile = filepath.Join(filepath.Dir(path), SecretsKeyFileName)
+	}
+	return path, keyFile
+}
@@ -18,6 +18,7 @@ type PublisherTypes struct {
 	S3        S3Publisher        `yaml:"S3,omitempty" json:"S3,omitempty"`
 	S3Managed S3ManagedPublisher `yaml:"S3Managed,omitempty" json:"S3Managed,omitempty"`
 	Local     LocalPublisher     `yaml:"Local,omitempty" json:"Local,omitempty"`
+	OCI       OCIRegistry        `yaml:"OCI,omitempty" json:"OCI,omitempty"`
 }
 
 func (p PublishersConfig) IsNotDisabled(kind string) bool {
@@ -36,6 +37,20 @@ type S3Publisher struct {
 	PreSignedURLDisabled bool `yaml:"PreSignedURLDisabled,omitempty" json:"PreSignedURLDisabled,omitempty"`
 	// PreSignedURLExpiration specifies the duration before a pre-signed URL expires.
 	PreSignedURLExpiration Duration `yaml:"PreSignedURLExpiration,omitempty" json:"PreSignedURLExpiration,omitempty"`
+	// Upload specifies how compute nodes upload results to S3.
+	Upload S3Upload `yaml:"Upload,omitempty" json:"Upload,omitempty"`
+}
+
+// S3Upload configures the multipart uploads of results to S3
+type S3Upload struct {
+	// PartSize specifies the size of the parts of multipart uploads, e.g. "16Mi". Must be at least 5Mi.
+	// Results are limited to 10000 parts. Defaults to 16Mi.
+	PartSize string `yaml:"PartSize,omitempty" json:"PartSize,omitempty"`
+	// Concurrency specifies the number of parts, or of files of results published as a directory,
+	// uploaded in parallel. Defaults to 4.
+	Concurrency int `yaml:"Concurrency,omitempty" json:"Concurrency,omitempty"`
+	// MaxRetries specifies how many times a failed part is retried. Defaults to 5.
+	MaxRetries int `yaml:"MaxRetries,omitempty" json:"MaxRetries,omitempty"`
 }
 
 type S3ManagedPublisher struct {
@@ -49,6 +64,8 @@ type S3ManagedPublisher struct {
 	Endpoint string `yaml:"Endpoint,omitempty" json:"Endpoint,omitempty"`
 	// PreSignedURLExpiration specifies the duration before a pre-signed URL expires.
 	PreSignedURLExpiration Duration `yaml:"PreSignedURLExpiration,omitempty" json:"PreSignedURLExpiration,omitempty"`
+	// Upload specifies how compute nodes upload results to the managed bucket.
+	Upload S3Upload `yaml:"Upload,omitempty" json:"Upload,omitempty"`
 }
 
 // IsConfigured returns true if ANY specific configuration has been provided,
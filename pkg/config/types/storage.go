@@ -18,7 +18,9 @@ type InputSourcesConfig struct {
 }
 
 type InputSourcesTypes struct {
+	Git  GitStorage  `yaml:"Git,omitempty" json:"Git,omitempty"`
 	IPFS IPFSStorage `yaml:"IPFS,omitempty" json:"IPFS,omitempty"`
+	OCI  OCIRegistry `yaml:"OCI,omitempty" json:"OCI,omitempty"`
 }
 
 func (i InputSourcesConfig) IsNotDisabled(kind string) bool {
@@ -27,7 +29,20 @@ func (i InputSourcesConfig) IsNotDisabled(kind string) bool {
 	})
 }
 
+type GitStorage struct {
+	// MirrorDir specifies the directory where repository mirrors are cached across executions.
+	// Defaults to a directory in the compute node's data directory.
+	MirrorDir string `yaml:"MirrorDir,omitempty" json:"MirrorDir,omitempty"`
+}
+
 type IPFSStorage struct {
 	// Endpoint specifies the multi-address to connect to for IPFS. e.g /ip4/127.0.0.1/tcp/5001
 	Endpoint string `yaml:"Endpoint,omitempty" json:"Endpoint,omitempty"`
 }
+
+// OCIRegistry configures access to the container registries OCI artifacts are pushed to and pulled from.
+// Registry credentials are read from the OCI_REGISTRY_USERNAME and OCI_REGISTRY_PASSWORD environment variables.
+type OCIRegistry struct {
+	// InsecureRegistries specifies the registries, e.g. "localhost:5000", that are accessed over plain HTTP.
+	InsecureRegistries []string `yaml:"InsecureRegistries,omitempty" json:"InsecureRegistries,omitempty"`
+}
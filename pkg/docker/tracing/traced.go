@@ -101,6 +101,69 @@ func (c TracedClient) ContainerStop(ctx context.Context, containerID string, tim
 	}))
 }
 
+func (c TracedClient) ContainerKill(ctx context.Context, containerID, signal string) error {
+	ctx, span := c.span(ctx, "container.kill")
+	defer span.End()
+
+	return telemetry.RecordErrorOnSpan(span)(c.client.ContainerKill(ctx, containerID, signal))
+}
+
+func (c TracedClient) ContainerExecCreate(
+	ctx context.Context,
+	containerID string,
+	options container.ExecOptions,
+) (container.ExecCreateResponse, error) {
+	ctx, span := c.span(ctx, "container.exec.create")
+	defer span.End()
+
+	return telemetry.RecordErrorOnSpanTwo[container.ExecCreateResponse](span)(
+		c.client.ContainerExecCreate(ctx, containerID, options),
+	)
+}
+
+func (c TracedClient) ContainerExecAttach(
+	ctx context.Context,
+	execID string,
+	options container.ExecAttachOptions,
+) (types.HijackedResponse, error) {
+	ctx, span := c.span(ctx, "container.exec.attach")
+	defer span.End()
+
+	return telemetry.RecordErrorOnSpanTwo[types.HijackedResponse](span)(c.client.ContainerExecAttach(ctx, execID, options))
+}
+
+func (c TracedClient) ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error) {
+	ctx, span := c.span(ctx, "container.exec.inspect")
+	defer span.End()
+
+	return telemetry.RecordErrorOnSpanTwo[container.ExecInspect](span)(c.client.ContainerExecInspect(ctx, execID))
+}
+
+func (c TracedClient) ContainerExecResize(ctx context.Context, execID string, options container.ResizeOptions) error {
+	ctx, span := c.span(ctx, "container.exec.resize")
+	defer span.End()
+
+	return telemetry.RecordErrorOnSpan(span)(c.client.ContainerExecResize(ctx, execID, options))
+}
+
+func (c TracedClient) ContainerAttach(
+	ctx context.Context,
+	containerID string,
+	options container.AttachOptions,
+) (types.HijackedResponse, error) {
+	ctx, span := c.span(ctx, "container.attach")
+	defer span.End()
+
+	return telemetry.RecordErrorOnSpanTwo[types.HijackedResponse](span)(c.client.ContainerAttach(ctx, containerID, options))
+}
+
+func (c TracedClient) ContainerResize(ctx context.Context, containerID string, options container.ResizeOptions) error {
+	ctx, span := c.span(ctx, "container.resize")
+	defer span.End()
+
+	return telemetry.RecordErrorOnSpan(span)(c.client.ContainerResize(ctx, containerID, options))
+}
+
 func (c TracedClient) ContainerWait(
 	ctx context.Context,
 	containerID string,
@@ -128,6 +191,20 @@ func (c TracedClient) ImageInspect(ctx context.Context, imageID string) (image.I
 	return telemetry.RecordErrorOnSpanTwo[image.InspectResponse](span)(c.client.ImageInspect(ctx, imageID))
 }
 
+func (c TracedClient) ImageList(ctx context.Context, options image.ListOptions) ([]image.Summary, error) {
+	ctx, span := c.span(ctx, "image.ls")
+	defer span.End()
+
+	return telemetry.RecordErrorOnSpanTwo[[]image.Summary](span)(c.client.ImageList(ctx, options))
+}
+
+func (c TracedClient) ImageRemove(ctx context.Context, imageID string, options image.RemoveOptions) ([]image.DeleteResponse, error) {
+	ctx, span := c.span(ctx, "image.rm")
+	defer span.End()
+
+	return telemetry.RecordErrorOnSpanTwo[[]image.DeleteResponse](span)(c.client.ImageRemove(ctx, imageID, options))
+}
+
 func (c TracedClient) DistributionInspect(ctx context.Context, imageID string, authToken string) (registry.DistributionInspect, error) {
 	ctx, span := c.span(ctx, "distribution.inspect")
 	defer span.End()
@@ -6,13 +6,19 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/manifest"
 )
 
 // specialFiles - i.e. anything that is not a volume
@@ -21,6 +27,8 @@ var specialFiles = map[string]bool{
 	DownloadFilenameStdout:   true,
 	DownloadFilenameStderr:   true,
 	DownloadFilenameExitCode: true,
+	// manifests describe a single result, and are not kept when merging results
+	manifest.FileName: false,
 }
 
 // DownloadResults downloads published results from a storage source and saves
@@ -39,9 +47,13 @@ var specialFiles = map[string]bool{
 // * iterate over each output volume
 // * make new folder for output volume
 // * iterate over each result and merge files in output folder to results dir
+//
+// origins are the executions expected to have published the results, in the order of publishedResults.
+// They are required to verify the results, as manifests must be signed by the node of their execution.
 func DownloadResults( //nolint:funlen
 	ctx context.Context,
 	publishedResults []*models.SpecConfig,
+	origins []models.ResultOrigin,
 	downloadProvider DownloaderProvider,
 	settings *DownloaderSettings,
 ) error {
@@ -52,6 +64,10 @@ func DownloadResults( //nolint:funlen
 		log.Ctx(ctx).Debug().Msg("No results to download")
 		return nil
 	}
+	if settings.Verify && len(origins) != len(publishedResults) {
+		return bacerrors.New("cannot verify results without the executions that published them").
+			WithHint("Upgrade the orchestrator, or download the results without verification")
+	}
 
 	// this is the full path to the top level folder we are writing our results
 	// to. We have already processed this in the case of a default
@@ -75,28 +91,57 @@ func DownloadResults( //nolint:funlen
 		return err
 	}
 
+	filter, err := newFileFilter(settings.Include, settings.Exclude)
+	if err != nil {
+		return err
+	}
+
 	log.Ctx(ctx).Info().Msgf("Downloading %d results to: %s.", len(publishedResults), resultsOutputDir)
-	downloadedResults := make(map[string]struct{})
-	for _, publishedResult := range publishedResults {
+	// downloaded results are mapped to the executions that published them, if known
+	downloadedResults := make(map[string]*models.ResultOrigin)
+	var downloadedResultsMu sync.Mutex
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(max(settings.Parallelism, 1))
+	for i, publishedResult := range publishedResults {
 		downloader, err := downloadProvider.Get(ctx, publishedResult.Type)
 		if err != nil {
 			return err
 		}
-		resultPath, err := downloader.FetchResult(ctx, DownloadItem{
-			Result:     publishedResult,
-			SingleFile: settings.SingleFile,
-			ParentPath: rawParentDir,
-		})
-		if err != nil {
-			return err
+		var origin *models.ResultOrigin
+		if i < len(origins) {
+			origin = &origins[i]
 		}
-		downloadedResults[filepath.Clean(resultPath)] = struct{}{}
+		group.Go(func() error {
+			resultPath, err := downloader.FetchResult(groupCtx, DownloadItem{
+				Result:     publishedResult,
+				SingleFile: settings.SingleFile,
+				ParentPath: rawParentDir,
+				Progress:   settings.Progress,
+			})
+			if err != nil {
+				return err
+			}
+			downloadedResultsMu.Lock()
+			defer downloadedResultsMu.Unlock()
+			downloadedResults[filepath.Clean(resultPath)] = origin
+			return nil
+		})
+	}
+	if err = group.Wait(); err != nil {
+		return err
 	}
 
 	if settings.Raw {
+		if settings.Verify {
+			for resultPath, origin := range downloadedResults {
+				if err = verifyRawResult(ctx, resultPath, *origin, settings); err != nil {
+					return err
+				}
+			}
+		}
 		return nil
 	}
-	for resultPath := range downloadedResults {
+	for resultPath, origin := range downloadedResults {
 		log.Ctx(ctx).Debug().
 			Str("Source", resultPath).
 			Str("Target", resultsOutputDir).
@@ -124,7 +169,13 @@ func DownloadResults( //nolint:funlen
 			resultPath = newResultPath
 		}
 
-		err = moveData(ctx, resultPath, resultsOutputDir, len(downloadedResults) > 1)
+		if settings.Verify {
+			if err = verifyResult(ctx, resultPath, *origin, settings); err != nil {
+				return err
+			}
+		}
+
+		err = moveData(ctx, resultPath, resultsOutputDir, len(downloadedResults) > 1, filter)
 		if err != nil {
 			return err
 		}
@@ -133,11 +184,97 @@ func DownloadResults( //nolint:funlen
 	return os.RemoveAll(rawParentDir)
 }
 
+// verifyResult verifies the signed manifest of a downloaded result against its files and the execution
+// expected to have published it, and against the key advertised by the node of the execution if node
+// keys are resolvable. Single files downloaded from results cannot be verified as the manifest covers
+// all the results.
+func verifyResult(ctx context.Context, resultPath string, origin models.ResultOrigin, settings *DownloaderSettings) error {
+	if settings.SingleFile != "" {
+		log.Ctx(ctx).Warn().Msgf("Skipping verification of %s, as only the whole results can be verified", settings.SingleFile)
+		return nil
+	}
+	m, err := manifest.Verify(resultPath)
+	if err != nil {
+		return bacerrors.Wrap(err, "failed to verify results").
+			WithHint("Results published by nodes that do not sign manifests can be downloaded without verification")
+	}
+	// the manifest could be copied from the results of another execution, so it must be
+	// signed for the execution that published the result
+	if m.JobID != origin.JobID || m.ExecutionID != origin.ExecutionID || m.NodeID != origin.NodeID {
+		return bacerrors.Newf("results of execution %s of job %s on node %s have the manifest of execution %s of job %s on node %s",
+			origin.ExecutionID, origin.JobID, origin.NodeID, m.ExecutionID, m.JobID, m.NodeID)
+	}
+	if settings.NodeKeys != nil {
+		nodeKey, err := settings.NodeKeys(ctx, origin.NodeID)
+		if err != nil {
+			return bacerrors.Wrapf(err, "failed to get the key of node %s that signed the results", origin.NodeID)
+		}
+		if nodeKey != m.PublicKey {
+			return bacerrors.Newf("results of execution %s are not signed by the key of node %s", origin.ExecutionID, origin.NodeID)
+		}
+	}
+	log.Ctx(ctx).Debug().
+		Str("Execution", m.ExecutionID).
+		Str("Node", m.NodeID).
+		Int("Files", len(m.Files)).
+		Msg("Verified results manifest")
+	return nil
+}
+
+// verifyRawResult verifies a result that is not extracted into the output dir,
+// extracting archives into a temporary directory to verify their content
+func verifyRawResult(ctx context.Context, resultPath string, origin models.ResultOrigin, settings *DownloaderSettings) error {
+	if !strings.HasSuffix(resultPath, ".tar.gz") && !strings.HasSuffix(resultPath, ".tgz") {
+		return verifyResult(ctx, resultPath, origin, settings)
+	}
+	tempDir, err := os.MkdirTemp("", "bacalhau-verify-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(tempDir); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msgf("failed to remove %s", tempDir)
+		}
+	}()
+	if err = gzip.Decompress(resultPath, tempDir); err != nil {
+		return err
+	}
+	return verifyResult(ctx, tempDir, origin, settings)
+}
+
+// ListResults lists the files of published results without downloading them. Only results
+// fetched by downloaders implementing Lister can be listed.
+func ListResults(
+	ctx context.Context,
+	publishedResults []*models.SpecConfig,
+	downloadProvider DownloaderProvider,
+) ([]*manifest.Manifest, error) {
+	listings := make([]*manifest.Manifest, 0, len(publishedResults))
+	for _, publishedResult := range publishedResults {
+		downloader, err := downloadProvider.Get(ctx, publishedResult.Type)
+		if err != nil {
+			return nil, err
+		}
+		lister, ok := downloader.(Lister)
+		if !ok {
+			return nil, bacerrors.Newf("listing results published with %s is not supported", publishedResult.Type).
+				WithCode(bacerrors.NotImplemented)
+		}
+		listing, err := lister.ListResult(ctx, DownloadItem{Result: publishedResult})
+		if err != nil {
+			return nil, err
+		}
+		listings = append(listings, listing)
+	}
+	return listings, nil
+}
+
 func moveData(
 	ctx context.Context,
 	fromFolder string,
 	toFolder string,
 	appendMode bool,
+	filter fileFilter,
 ) error {
 	log.Ctx(ctx).Debug().Msgf("Moving data from %s to %s", fromFolder, toFolder)
 	// the recursive function that will scan our source volume folder
@@ -173,11 +310,24 @@ func moveData(
 		shouldAppendLogs, isSpecialFile := specialFiles[basePath]
 
 		if d.IsDir() {
+			// directories are created along with the files they contain when filtering,
+			// so that directories without selected files are left out
+			if filter != nil {
+				return nil
+			}
 			err = os.MkdirAll(globalTargetPath, DownloadFolderPerm)
 			if err != nil {
 				return err
 			}
 		} else {
+			if filter != nil {
+				if !filter(filepath.ToSlash(basePath)) {
+					return nil
+				}
+				if err = os.MkdirAll(filepath.Dir(globalTargetPath), DownloadFolderPerm); err != nil {
+					return err
+				}
+			}
 			// if it's not a special file then we move it into the global dir
 			if !appendMode || !isSpecialFile {
 				if err = moveFile(path, globalTargetPath); err != nil {
@@ -200,6 +350,33 @@ func moveData(
 	return filepath.WalkDir(fromFolder, moveFunc)
 }
 
+// fileFilter returns true if the file at the slash separated path relative to the result root is kept
+type fileFilter func(path string) bool
+
+// newFileFilter returns a filter keeping files matching any of the include patterns, if any, and none
+// of the exclude patterns, or nil if there are no patterns
+func newFileFilter(include, exclude []string) (fileFilter, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	for _, pattern := range slices.Concat(include, exclude) {
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, bacerrors.Newf("invalid file pattern %q", pattern).WithCode(bacerrors.ValidationError)
+		}
+	}
+	matchesAny := func(patterns []string, path string) bool {
+		return slices.ContainsFunc(patterns, func(pattern string) bool {
+			return doublestar.MatchUnvalidated(pattern, path)
+		})
+	}
+	return func(path string) bool {
+		if len(include) > 0 && !matchesAny(include, path) {
+			return false
+		}
+		return !matchesAny(exclude, path)
+	}, nil
+}
+
 // read data from sourcePath and append it to targetPath
 // the same as "cat $sourcePath >> $targetPath"
 func appendFile(sourcePath, targetPath string) error {
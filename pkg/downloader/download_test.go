@@ -235,6 +235,7 @@ func (ds *DownloaderSuite) TestNoExpectedResults() {
 	err := downloader.DownloadResults(
 		ds.Ctx,
 		[]*models.SpecConfig{},
+		nil,
 		ds.downloadProvider,
 		ds.downloadSettings,
 	)
@@ -246,6 +247,7 @@ func (ds *DownloaderSuite) download(results ...*models.SpecConfig) error {
 	return downloader.DownloadResults(
 		ds.Ctx,
 		results,
+		nil,
 		ds.downloadProvider,
 		ds.downloadSettings,
 	)
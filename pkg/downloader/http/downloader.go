@@ -1,22 +1,31 @@
 package http
 
 import (
+	"archive/tar"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/bacalhau-project/bacalhau/pkg/downloader"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/manifest"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/url/urldownload"
 	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
 )
 
+// partialFileSuffix is the suffix of files being downloaded
+const partialFileSuffix = ".part"
+
 // Replace slashes with some other character that is valid for filenames in most operating systems
 var urlSanitizer = strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
 
@@ -61,22 +70,30 @@ func (httpDownloader *Downloader) FetchResult(ctx context.Context, item download
 		return localPath, nil
 	}
 
-	return localPath, httpDownloader.fetch(ctx, sourceSpec.URL, localPath)
+	return localPath, httpDownloader.Fetch(ctx, sourceSpec.URL, localPath, item.Progress)
 }
 
-// fetch makes an HTTP GET request to the given URL and writes the response to the given filepath.
-func (httpDownloader *Downloader) fetch(ctx context.Context, url string, filepath string) error {
-	//nolint:gosec // G304: filepath validated by caller
-	out, err := os.OpenFile(filepath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, downloader.DownloadFilePerm)
-	if err != nil {
+// Fetch makes an HTTP GET request to the given URL and writes the response to the given filepath.
+// The response is first written to a partial file, which is only renamed to filepath once complete,
+// so that an interrupted download is resumed with a range request the next time it is fetched.
+// Resuming assumes the content at the URL does not change, as is the case of published results.
+func (httpDownloader *Downloader) Fetch(
+	ctx context.Context, url string, filepath string, progress downloader.ProgressFunc) error {
+	partialPath := filepath + partialFileSuffix
+	var offset int64
+	if info, err := os.Stat(partialPath); err == nil {
+		offset = info.Size()
+	} else if !os.IsNotExist(err) {
 		return err
 	}
-	defer closer.CloseWithLogOnError("file", out)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return err
 	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
 
 	response, err := httpDownloader.httpClient.Do(req)
 	if err != nil {
@@ -84,16 +101,129 @@ func (httpDownloader *Downloader) fetch(ctx context.Context, url string, filepat
 	}
 	defer closer.DrainAndCloseWithLogOnError(ctx, "http response", response.Body)
 
-	if err = checkHTTPResponse(response, url); err != nil {
-		return err
+	flags := os.O_WRONLY | os.O_CREATE
+	total := response.ContentLength
+	switch {
+	case offset > 0 && response.StatusCode == http.StatusPartialContent && rangeStart(response) == offset:
+		log.Ctx(ctx).Debug().Str("URL", url).Int64("Offset", offset).Msg("Resuming download")
+		flags |= os.O_APPEND
+		if total >= 0 {
+			total += offset
+		}
+	case offset > 0 && response.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// the partial file is not a prefix of the content, which is downloaded again
+		if err = os.Remove(partialPath); err != nil {
+			return err
+		}
+		return httpDownloader.Fetch(ctx, url, filepath, progress)
+	default:
+		if err = checkHTTPResponse(response, url); err != nil {
+			return err
+		}
+		// servers ignoring the range send the whole content
+		flags |= os.O_TRUNC
+		offset = 0
 	}
 
-	_, err = io.Copy(out, response.Body)
+	//nolint:gosec // G304: filepath validated by caller
+	out, err := os.OpenFile(partialPath, flags, downloader.DownloadFilePerm)
 	if err != nil {
 		return err
 	}
+	defer closer.CloseWithLogOnError("file", out)
+
+	var writer io.Writer = out
+	if progress != nil {
+		writer = &progressWriter{
+			writer:     out,
+			name:       path.Base(req.URL.Path),
+			downloaded: offset,
+			total:      total,
+			progress:   progress,
+		}
+	}
+	if _, err = io.Copy(writer, response.Body); err != nil {
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(partialPath, filepath)
+}
+
+// rangeStart returns the first byte of the content range of a partial response, or -1 if it is invalid
+func rangeStart(response *http.Response) int64 {
+	var start int64
+	if _, err := fmt.Sscanf(response.Header.Get("Content-Range"), "bytes %d-", &start); err != nil {
+		return -1
+	}
+	return start
+}
+
+// progressWriter reports the progress of a download as it is written
+type progressWriter struct {
+	writer     io.Writer
+	name       string
+	downloaded int64
+	total      int64
+	progress   downloader.ProgressFunc
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.downloaded += int64(n)
+	w.progress(w.name, w.downloaded, w.total)
+	return n, err
+}
+
+// ListResult lists the files of an archived result by reading the archive until its manifest,
+// which lists all the files of the result, without writing the archive to disk.
+// The manifest is not verified, as the rest of the archive is not read.
+func (httpDownloader *Downloader) ListResult(ctx context.Context, item downloader.DownloadItem) (*manifest.Manifest, error) {
+	sourceSpec, err := urldownload.DecodeSpec(item.Result)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceSpec.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := httpDownloader.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	// the rest of the archive is not drained, as it can be large
+	defer closer.CloseWithLogOnError("http response", response.Body)
+	if err = checkHTTPResponse(response, sourceSpec.URL); err != nil {
+		return nil, err
+	}
 
-	return nil
+	gzipReader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("only archived results can be listed: %w", err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	listing := &manifest.Manifest{Files: make([]manifest.File, 0)}
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return listing, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := path.Clean(header.Name)
+		if name == manifest.FileName {
+			m := new(manifest.Manifest)
+			if err = json.NewDecoder(tarReader).Decode(m); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", manifest.FileName, err)
+			}
+			return m, nil
+		}
+		if header.Typeflag == tar.TypeReg {
+			listing.Files = append(listing.Files, manifest.File{Path: name, Size: header.Size})
+		}
+	}
 }
 
 func checkHTTPResponse(resp *http.Response, url string) error {
@@ -128,3 +258,7 @@ func SanitizeFileName(fullURL string) (string, error) {
 	urlPath := parsedURL.Host + parsedURL.Path
 	return urlSanitizer.Replace(urlPath), nil
 }
+
+// compile-time check for interface implementation
+var _ downloader.Downloader = (*Downloader)(nil)
+var _ downloader.Lister = (*Downloader)(nil)
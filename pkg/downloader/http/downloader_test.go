@@ -0,0 +1,134 @@
+//go:build unit || !integration
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/downloader"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/manifest"
+)
+
+type DownloaderTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	downloader *Downloader
+	content    []byte
+	ranges     []string
+	server     *httptest.Server
+}
+
+func TestDownloaderTestSuite(t *testing.T) {
+	suite.Run(t, new(DownloaderTestSuite))
+}
+
+func (s *DownloaderTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.downloader = NewHTTPDownloader()
+	s.content = []byte(strings.Repeat("0123456789", 100))
+	s.ranges = nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.ranges = append(s.ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "results", time.Time{}, bytes.NewReader(s.content))
+	}))
+	s.T().Cleanup(s.server.Close)
+}
+
+func (s *DownloaderTestSuite) TestFetch() {
+	path := filepath.Join(s.T().TempDir(), "results")
+	var downloaded, total int64
+	s.Require().NoError(s.downloader.Fetch(s.ctx, s.server.URL+"/results", path, func(_ string, d, t int64) {
+		downloaded, total = d, t
+	}))
+
+	data, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Equal(s.content, data)
+	s.Equal([]string{""}, s.ranges)
+	s.Equal(int64(len(s.content)), downloaded)
+	s.Equal(int64(len(s.content)), total)
+	s.NoFileExists(path + partialFileSuffix)
+}
+
+func (s *DownloaderTestSuite) TestFetchResumesPartialDownload() {
+	path := filepath.Join(s.T().TempDir(), "results")
+	s.Require().NoError(os.WriteFile(path+partialFileSuffix, s.content[:300], downloader.DownloadFilePerm))
+
+	var downloaded, total int64
+	s.Require().NoError(s.downloader.Fetch(s.ctx, s.server.URL+"/results", path, func(_ string, d, t int64) {
+		downloaded, total = d, t
+	}))
+
+	data, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Equal(s.content, data)
+	s.Equal([]string{"bytes=300-"}, s.ranges)
+	s.Equal(int64(len(s.content)), downloaded)
+	s.Equal(int64(len(s.content)), total)
+}
+
+func (s *DownloaderTestSuite) TestFetchRestartsUnsatisfiableDownload() {
+	path := filepath.Join(s.T().TempDir(), "results")
+	s.Require().NoError(os.WriteFile(path+partialFileSuffix, bytes.Repeat([]byte("x"), 2000), downloader.DownloadFilePerm))
+
+	s.Require().NoError(s.downloader.Fetch(s.ctx, s.server.URL+"/results", path, nil))
+
+	data, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Equal(s.content, data)
+	s.Equal([]string{"bytes=2000-", ""}, s.ranges)
+}
+
+func (s *DownloaderTestSuite) TestListResult() {
+	resultsDir := s.T().TempDir()
+	s.Require().NoError(os.MkdirAll(filepath.Join(resultsDir, "outputs"), 0755))
+	s.Require().NoError(os.WriteFile(filepath.Join(resultsDir, "stdout"), []byte("hello"), 0644))
+	s.Require().NoError(os.WriteFile(filepath.Join(resultsDir, "outputs", "data.csv"), []byte("a,b"), 0644))
+
+	list := func() *manifest.Manifest {
+		archivePath := filepath.Join(s.T().TempDir(), "results.tar.gz")
+		archive, err := os.Create(archivePath)
+		s.Require().NoError(err)
+		s.Require().NoError(gzip.Compress(resultsDir, archive))
+		s.Require().NoError(archive.Close())
+		s.content, err = os.ReadFile(archivePath)
+		s.Require().NoError(err)
+		listing, err := s.downloader.ListResult(s.ctx, downloader.DownloadItem{
+			Result: &models.SpecConfig{
+				Type:   models.StorageSourceURL,
+				Params: map[string]interface{}{"URL": s.server.URL + "/results.tar.gz"},
+			},
+		})
+		s.Require().NoError(err)
+		return listing
+	}
+
+	// without a manifest, the files of the archive are listed without checksums
+	listing := list()
+	s.ElementsMatch([]manifest.File{
+		{Path: "stdout", Size: 5},
+		{Path: "outputs/data.csv", Size: 3},
+	}, listing.Files)
+
+	// with a manifest, the manifest is returned as is
+	m := manifest.Manifest{Version: manifest.Version, ExecutionID: "e-1", Files: []manifest.File{{Path: "stdout", Size: 5}}}
+	data, err := json.Marshal(m)
+	s.Require().NoError(err)
+	s.Require().NoError(os.WriteFile(filepath.Join(resultsDir, manifest.FileName), data, 0644))
+	listing = list()
+	s.Equal("e-1", listing.ExecutionID)
+	s.Equal(m.Files, listing.Files)
+}
@@ -0,0 +1,93 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/downloader"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+)
+
+// referenceSanitizer replaces the characters of references that are not valid in file names
+var referenceSanitizer = strings.NewReplacer("/", "_", ":", "_", "@", "_")
+
+type DownloaderParams struct {
+	Client *oci.Client
+}
+
+// Downloader fetches results pushed as OCI artifacts by the oci publisher
+type Downloader struct {
+	client *oci.Client
+}
+
+func NewDownloader(params DownloaderParams) *Downloader {
+	return &Downloader{
+		client: params.Client,
+	}
+}
+
+func (d *Downloader) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+// FetchResult downloads the result archive of the artifact to a file ending with .tar.gz,
+// so that it gets decompressed
+func (d *Downloader) FetchResult(ctx context.Context, item downloader.DownloadItem) (string, error) {
+	if item.SingleFile != "" {
+		return "", errors.New("oci downloader does not support single file downloads")
+	}
+
+	sourceSpec, err := oci.DecodeSourceSpec(item.Result)
+	if err != nil {
+		return "", err
+	}
+	ref, err := oci.ParseReference(sourceSpec.Reference)
+	if err != nil {
+		return "", err
+	}
+
+	localPath := filepath.Join(item.ParentPath, referenceSanitizer.Replace(ref.String())+".tar.gz")
+	alreadyExists, err := downloader.IsAlreadyDownloaded(localPath)
+	if err != nil {
+		return "", err
+	}
+	if alreadyExists {
+		log.Ctx(ctx).Debug().Str("Reference", ref.String()).Msg("Artifact already downloaded.")
+		return localPath, nil
+	}
+
+	manifest, _, err := d.client.Manifest(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	layers := oci.ArchiveLayers(manifest)
+	if len(layers) != 1 {
+		return "", fmt.Errorf("expected a single tar+gzip layer in %s, but found %d",
+			reference.FamiliarString(ref), len(layers))
+	}
+
+	//nolint:gosec // G304: localPath within the download directory, application controlled
+	out, err := os.OpenFile(localPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, downloader.DownloadFilePerm)
+	if err != nil {
+		return "", err
+	}
+	defer closer.CloseWithLogOnError("file", out)
+
+	if err = d.client.FetchBlob(ctx, ref, layers[0], out); err != nil {
+		// remove partial downloads, so they are not mistaken for complete ones
+		_ = os.Remove(localPath)
+		return "", err
+	}
+	return localPath, nil
+}
+
+// compile-time check for interface implementation
+var _ downloader.Downloader = (*Downloader)(nil)
@@ -0,0 +1,106 @@
+//go:build unit || !integration
+
+package oci
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/downloader"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
+	ocipublisher "github.com/bacalhau-project/bacalhau/pkg/publisher/oci"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+type DownloaderTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	registry   *oci.TestRegistry
+	publisher  *ocipublisher.Publisher
+	downloader *Downloader
+}
+
+func TestDownloaderTestSuite(t *testing.T) {
+	suite.Run(t, new(DownloaderTestSuite))
+}
+
+func (s *DownloaderTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.registry = oci.NewTestRegistry(s.T())
+	client := oci.NewClient(oci.ClientParams{InsecureRegistries: []string{s.registry.Host()}})
+	s.publisher = ocipublisher.NewPublisher(ocipublisher.PublisherParams{
+		LocalDir: s.T().TempDir(),
+		Client:   client,
+	})
+	s.downloader = NewDownloader(DownloaderParams{Client: client})
+}
+
+func (s *DownloaderTestSuite) publish() *models.SpecConfig {
+	resultDir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(resultDir, "stdout"), []byte("hello"), 0644))
+
+	execution := mock.Execution()
+	execution.Job.Task().Publisher = &models.SpecConfig{
+		Type:   models.PublisherOCI,
+		Params: oci.PublisherSpec{Repository: s.registry.Host() + "/results"}.ToMap(),
+	}
+	result, err := s.publisher.PublishResult(s.ctx, execution, resultDir)
+	s.Require().NoError(err)
+	return &result
+}
+
+func (s *DownloaderTestSuite) TestFetchResult() {
+	result := s.publish()
+	parentPath := s.T().TempDir()
+	downloadPath, err := s.downloader.FetchResult(s.ctx, downloader.DownloadItem{
+		Result:     result,
+		ParentPath: parentPath,
+	})
+	s.Require().NoError(err)
+	s.True(strings.HasSuffix(downloadPath, ".tar.gz"), downloadPath)
+	s.Equal(parentPath, filepath.Dir(downloadPath))
+
+	target := s.T().TempDir()
+	s.Require().NoError(gzip.Decompress(downloadPath, target))
+	content, err := os.ReadFile(filepath.Join(target, "stdout"))
+	s.Require().NoError(err)
+	s.Equal("hello", string(content))
+
+	// downloading again reuses the archive
+	again, err := s.downloader.FetchResult(s.ctx, downloader.DownloadItem{
+		Result:     result,
+		ParentPath: parentPath,
+	})
+	s.Require().NoError(err)
+	s.Equal(downloadPath, again)
+}
+
+func (s *DownloaderTestSuite) TestFetchSingleFile() {
+	_, err := s.downloader.FetchResult(s.ctx, downloader.DownloadItem{
+		Result:     s.publish(),
+		SingleFile: "stdout",
+		ParentPath: s.T().TempDir(),
+	})
+	s.Require().Error(err)
+}
+
+func (s *DownloaderTestSuite) TestFetchMissingResult() {
+	spec, err := oci.NewSourceSpecConfig(s.registry.Host() + "/results:missing")
+	s.Require().NoError(err)
+	parentPath := s.T().TempDir()
+	_, err = s.downloader.FetchResult(s.ctx, downloader.DownloadItem{
+		Result:     spec,
+		ParentPath: parentPath,
+	})
+	s.Require().Error(err)
+	entries, err := os.ReadDir(parentPath)
+	s.Require().NoError(err)
+	s.Empty(entries)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/downloader"
 	"github.com/bacalhau-project/bacalhau/pkg/downloader/http"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/manifest"
 	"github.com/bacalhau-project/bacalhau/pkg/s3"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/url/urldownload"
 )
@@ -34,10 +35,28 @@ func (d *Downloader) FetchResult(ctx context.Context, item downloader.DownloadIt
 		return "", errors.New("s3signed downloader does not support single file downloads")
 	}
 
-	sourceSpec, err := s3.DecodePreSignedResultSpec(item.Result)
+	httpItem, err := urlItem(item)
 	if err != nil {
 		return "", err
 	}
+	return d.httpDownloader.FetchResult(ctx, httpItem)
+}
+
+// ListResult lists the files of the result archive
+func (d *Downloader) ListResult(ctx context.Context, item downloader.DownloadItem) (*manifest.Manifest, error) {
+	httpItem, err := urlItem(item)
+	if err != nil {
+		return nil, err
+	}
+	return d.httpDownloader.ListResult(ctx, httpItem)
+}
+
+// urlItem returns the item downloading the result from its pre-signed URL with the http downloader
+func urlItem(item downloader.DownloadItem) (downloader.DownloadItem, error) {
+	sourceSpec, err := s3.DecodePreSignedResultSpec(item.Result)
+	if err != nil {
+		return downloader.DownloadItem{}, err
+	}
 
 	urlSourceSpec := &models.SpecConfig{
 		Type: models.StorageSourceURL,
@@ -46,9 +65,14 @@ func (d *Downloader) FetchResult(ctx context.Context, item downloader.DownloadIt
 		}.ToMap(),
 	}
 
-	return d.httpDownloader.FetchResult(ctx, downloader.DownloadItem{
+	return downloader.DownloadItem{
 		Result:     urlSourceSpec,
 		SingleFile: item.SingleFile,
 		ParentPath: item.ParentPath,
-	})
+		Progress:   item.Progress,
+	}, nil
 }
+
+// compile-time check for interface implementation
+var _ downloader.Downloader = (*Downloader)(nil)
+var _ downloader.Lister = (*Downloader)(nil)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/bacalhau-project/bacalhau/pkg/lib/provider"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/manifest"
 )
 
 const (
@@ -16,6 +17,8 @@ const (
 	DownloadFolderPerm       = 0755
 	DownloadFilePerm         = 0644
 	DefaultDownloadTimeout   = 5 * time.Minute
+	// DefaultDownloadParallelism is the default number of results downloaded in parallel by clients
+	DefaultDownloadParallelism = 4
 )
 
 type Downloader interface {
@@ -25,19 +28,47 @@ type Downloader interface {
 	FetchResult(ctx context.Context, item DownloadItem) (string, error)
 }
 
+// Lister is implemented by downloaders that can list the files of results without downloading them
+type Lister interface {
+	// ListResult returns the manifest of the result. Results without a manifest are listed with
+	// a manifest only holding their files, without checksums.
+	ListResult(ctx context.Context, item DownloadItem) (*manifest.Manifest, error)
+}
+
 type DownloaderProvider interface {
 	provider.Provider[Downloader]
 }
 
+// ProgressFunc reports the bytes downloaded so far of a file, out of its total size, which is -1 if unknown
+type ProgressFunc func(name string, downloaded, total int64)
+
+// NodeKeyResolver returns the base64 encoded PKIX public key advertised by a compute node
+type NodeKeyResolver func(ctx context.Context, nodeID string) (string, error)
+
 type DownloaderSettings struct {
 	Timeout    time.Duration
 	OutputDir  string
 	SingleFile string
 	Raw        bool
+	// Verify verifies the signed manifest of each result before it is written to the output dir
+	Verify bool
+	// NodeKeys resolves the keys of the nodes that signed the manifests, which must match the keys
+	// in the manifests. Optional. Manifests are only checked against their own keys if not set.
+	NodeKeys NodeKeyResolver
+	// Include keeps the files of merged results whose paths match any of the glob patterns. Optional.
+	Include []string
+	// Exclude drops the files of merged results whose paths match any of the glob patterns. Optional.
+	Exclude []string
+	// Parallelism is the number of results downloaded concurrently, and defaults to one
+	Parallelism int
+	// Progress reports the progress of downloads. Optional.
+	Progress ProgressFunc
 }
 
 type DownloadItem struct {
 	Result     *models.SpecConfig
 	SingleFile string
 	ParentPath string
+	// Progress reports the progress of the download. Optional.
+	Progress ProgressFunc
 }
@@ -7,10 +7,13 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/downloader"
 	"github.com/bacalhau-project/bacalhau/pkg/downloader/http"
 	"github.com/bacalhau-project/bacalhau/pkg/downloader/ipfs"
+	ocidownloader "github.com/bacalhau-project/bacalhau/pkg/downloader/oci"
 	"github.com/bacalhau-project/bacalhau/pkg/downloader/s3signed"
+	"github.com/bacalhau-project/bacalhau/pkg/downloader/webhook"
 	ipfs_client "github.com/bacalhau-project/bacalhau/pkg/ipfs"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/provider"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
 )
 
 func NewStandardDownloaders(ctx context.Context, cfg types.ResultDownloaders) (downloader.DownloaderProvider, error) {
@@ -22,6 +25,21 @@ func NewStandardDownloaders(ctx context.Context, cfg types.ResultDownloaders) (d
 		})
 	}
 
+	if cfg.IsNotDisabled(models.StorageSourceWebhook) {
+		providers[models.StorageSourceWebhook] = webhook.NewDownloader(webhook.DownloaderParams{
+			HTTPDownloader: http.NewHTTPDownloader(),
+		})
+	}
+
+	if cfg.IsNotDisabled(models.StorageSourceOCI) {
+		providers[models.StorageSourceOCI] = ocidownloader.NewDownloader(ocidownloader.DownloaderParams{
+			Client: oci.NewClient(oci.ClientParams{
+				Credentials:        oci.GetCredentials(),
+				InsecureRegistries: cfg.Types.OCI.InsecureRegistries,
+			}),
+		})
+	}
+
 	if cfg.IsNotDisabled(models.StorageSourceURL) {
 		providers[models.StorageSourceURL] = http.NewHTTPDownloader()
 	}
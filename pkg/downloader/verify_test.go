@@ -0,0 +1,187 @@
+//go:build unit || !integration
+
+package downloader_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/downloader"
+	baccrypto "github.com/bacalhau-project/bacalhau/pkg/lib/crypto"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/provider"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/manifest"
+)
+
+const archiveResultType = "archive"
+
+type VerifySuite struct {
+	suite.Suite
+	ctx        context.Context
+	key        *rsa.PrivateKey
+	resultsDir string
+	settings   *downloader.DownloaderSettings
+	provider   downloader.DownloaderProvider
+	origin     models.ResultOrigin
+}
+
+func TestVerifySuite(t *testing.T) {
+	suite.Run(t, new(VerifySuite))
+}
+
+func (s *VerifySuite) SetupSuite() {
+	var err error
+	s.key, err = rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+}
+
+func (s *VerifySuite) SetupTest() {
+	s.ctx = context.Background()
+	s.resultsDir = s.T().TempDir()
+	s.settings = &downloader.DownloaderSettings{
+		Timeout:   time.Minute,
+		OutputDir: s.T().TempDir(),
+		Verify:    true,
+	}
+	s.provider = provider.NewMappedProvider(map[string]downloader.Downloader{
+		archiveResultType: archiveDownloader{resultsDir: s.resultsDir},
+	})
+	s.origin = models.ResultOrigin{JobID: "j-1", ExecutionID: "e-1", NodeID: "node-1"}
+
+	s.Require().NoError(os.WriteFile(filepath.Join(s.resultsDir, "stdout"), []byte("hello"), 0644))
+	m := &manifest.Manifest{Version: manifest.Version, JobID: "j-1", ExecutionID: "e-1", NodeID: "node-1"}
+	files, err := manifest.ListFiles(s.resultsDir)
+	s.Require().NoError(err)
+	m.Files = files
+	s.Require().NoError(m.Sign(s.key))
+	s.Require().NoError(m.Write(s.resultsDir))
+}
+
+func (s *VerifySuite) download() error {
+	return downloader.DownloadResults(
+		s.ctx, []*models.SpecConfig{{Type: archiveResultType}}, []models.ResultOrigin{s.origin}, s.provider, s.settings)
+}
+
+func (s *VerifySuite) TestVerifiedResults() {
+	s.Require().NoError(s.download())
+	s.FileExists(filepath.Join(s.settings.OutputDir, "stdout"))
+	s.FileExists(filepath.Join(s.settings.OutputDir, manifest.FileName))
+}
+
+func (s *VerifySuite) TestModifiedResults() {
+	s.Require().NoError(os.WriteFile(filepath.Join(s.resultsDir, "stdout"), []byte("HELLO"), 0644))
+	s.Require().Error(s.download())
+	s.NoFileExists(filepath.Join(s.settings.OutputDir, "stdout"))
+
+	s.settings.Raw = true
+	s.Require().Error(s.download())
+
+	s.settings.Verify = false
+	s.Require().NoError(s.download())
+}
+
+func (s *VerifySuite) TestResultsWithoutManifest() {
+	s.Require().NoError(os.Remove(filepath.Join(s.resultsDir, manifest.FileName)))
+	s.Require().ErrorIs(s.download(), manifest.ErrNotFound)
+
+	s.settings.Verify = false
+	s.Require().NoError(s.download())
+	s.FileExists(filepath.Join(s.settings.OutputDir, "stdout"))
+}
+
+func (s *VerifySuite) TestResultsOfOtherExecution() {
+	for _, origin := range []models.ResultOrigin{
+		{JobID: "j-2", ExecutionID: "e-1", NodeID: "node-1"},
+		{JobID: "j-1", ExecutionID: "e-2", NodeID: "node-1"},
+		{JobID: "j-1", ExecutionID: "e-1", NodeID: "node-2"},
+	} {
+		s.origin = origin
+		s.Require().ErrorContains(s.download(), "have the manifest of execution e-1")
+		s.NoFileExists(filepath.Join(s.settings.OutputDir, "stdout"))
+
+		s.settings.Raw = true
+		s.Require().ErrorContains(s.download(), "have the manifest of execution e-1")
+		s.settings.Raw = false
+	}
+}
+
+func (s *VerifySuite) TestResultsWithoutOrigin() {
+	err := downloader.DownloadResults(s.ctx, []*models.SpecConfig{{Type: archiveResultType}}, nil, s.provider, s.settings)
+	s.Require().ErrorContains(err, "cannot verify results without the executions that published them")
+
+	s.settings.Verify = false
+	err = downloader.DownloadResults(s.ctx, []*models.SpecConfig{{Type: archiveResultType}}, nil, s.provider, s.settings)
+	s.Require().NoError(err)
+}
+
+func (s *VerifySuite) TestNodeKeys() {
+	nodeKey, err := baccrypto.EncodePublicKey(&s.key.PublicKey)
+	s.Require().NoError(err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	otherKey, err := baccrypto.EncodePublicKey(&other.PublicKey)
+	s.Require().NoError(err)
+
+	keys := map[string]string{"node-1": nodeKey}
+	s.settings.NodeKeys = func(_ context.Context, nodeID string) (string, error) {
+		key, ok := keys[nodeID]
+		if !ok {
+			return "", errors.New("node not found")
+		}
+		return key, nil
+	}
+	s.Require().NoError(s.download())
+
+	keys["node-1"] = otherKey
+	s.Require().ErrorContains(s.download(), "not signed by the key of node node-1")
+
+	delete(keys, "node-1")
+	s.Require().ErrorContains(s.download(), "node not found")
+}
+
+func (s *VerifySuite) TestIncludeExclude() {
+	s.Require().NoError(os.MkdirAll(filepath.Join(s.resultsDir, "outputs", "tmp"), 0755))
+	for _, name := range []string{"outputs/a.csv", "outputs/b.txt", "outputs/tmp/c.csv"} {
+		s.Require().NoError(os.WriteFile(filepath.Join(s.resultsDir, name), []byte(name), 0644))
+	}
+	s.settings.Verify = false
+	s.settings.Include = []string{"outputs/**/*.csv"}
+	s.settings.Exclude = []string{"**/tmp/**"}
+	s.Require().NoError(s.download())
+
+	s.FileExists(filepath.Join(s.settings.OutputDir, "outputs", "a.csv"))
+	s.NoFileExists(filepath.Join(s.settings.OutputDir, "outputs", "b.txt"))
+	s.NoDirExists(filepath.Join(s.settings.OutputDir, "outputs", "tmp"))
+	s.NoFileExists(filepath.Join(s.settings.OutputDir, "stdout"))
+
+	s.settings.Include = []string{"outputs/[a"}
+	s.Require().Error(s.download())
+}
+
+// archiveDownloader downloads the results directory as an archive
+type archiveDownloader struct {
+	resultsDir string
+}
+
+func (d archiveDownloader) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (d archiveDownloader) FetchResult(_ context.Context, item downloader.DownloadItem) (string, error) {
+	path := filepath.Join(item.ParentPath, "results.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return path, gzip.Compress(d.resultsDir, f)
+}
@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/downloader"
+	"github.com/bacalhau-project/bacalhau/pkg/downloader/http"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/manifest"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/webhook"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/url/urldownload"
+)
+
+type DownloaderParams struct {
+	HTTPDownloader *http.Downloader
+}
+
+// Downloader fetches results published by the webhook publisher
+type Downloader struct {
+	httpDownloader *http.Downloader
+}
+
+func NewDownloader(params DownloaderParams) *Downloader {
+	return &Downloader{
+		httpDownloader: params.HTTPDownloader,
+	}
+}
+
+func (d *Downloader) IsInstalled(ctx context.Context) (bool, error) {
+	return d.httpDownloader.IsInstalled(ctx)
+}
+
+func (d *Downloader) FetchResult(ctx context.Context, item downloader.DownloadItem) (string, error) {
+	sourceSpec, err := webhook.DecodeResultSpec(item.Result)
+	if err != nil {
+		return "", err
+	}
+
+	if sourceSpec.Encoding == webhook.EncodingPlain {
+		return d.fetchFiles(ctx, sourceSpec, item)
+	}
+
+	if item.SingleFile != "" {
+		return "", errors.New("webhook downloader does not support single file downloads of archived results")
+	}
+
+	// the archive is downloaded to a file ending with .tar.gz, so that it gets decompressed
+	return d.httpDownloader.FetchResult(ctx, archiveItem(sourceSpec, item))
+}
+
+// ListResult lists the files uploaded individually, or the files of the archive
+func (d *Downloader) ListResult(ctx context.Context, item downloader.DownloadItem) (*manifest.Manifest, error) {
+	sourceSpec, err := webhook.DecodeResultSpec(item.Result)
+	if err != nil {
+		return nil, err
+	}
+	if sourceSpec.Encoding != webhook.EncodingPlain {
+		return d.httpDownloader.ListResult(ctx, archiveItem(sourceSpec, item))
+	}
+	listing := &manifest.Manifest{Files: make([]manifest.File, 0, len(sourceSpec.Files))}
+	for _, file := range sourceSpec.Files {
+		// the sizes of files uploaded individually are not recorded
+		listing.Files = append(listing.Files, manifest.File{Path: file, Size: -1})
+	}
+	return listing, nil
+}
+
+// archiveItem returns the item downloading the archive of the result with the http downloader
+func archiveItem(sourceSpec webhook.ResultSpec, item downloader.DownloadItem) downloader.DownloadItem {
+	return downloader.DownloadItem{
+		Result: &models.SpecConfig{
+			Type: models.StorageSourceURL,
+			Params: urldownload.Source{
+				URL: sourceSpec.URL,
+			}.ToMap(),
+		},
+		ParentPath: item.ParentPath,
+		Progress:   item.Progress,
+	}
+}
+
+// fetchFiles downloads files uploaded individually into a directory named after their URL prefix
+func (d *Downloader) fetchFiles(
+	ctx context.Context, sourceSpec webhook.ResultSpec, item downloader.DownloadItem) (string, error) {
+	files := sourceSpec.Files
+	if item.SingleFile != "" {
+		if !slices.Contains(files, item.SingleFile) {
+			return "", fmt.Errorf("failed to find %s in published results", item.SingleFile)
+		}
+		files = []string{item.SingleFile}
+	}
+
+	dirName, err := http.SanitizeFileName(sourceSpec.URL)
+	if err != nil {
+		return "", err
+	}
+	resultPath := filepath.Join(item.ParentPath, dirName)
+
+	for _, file := range files {
+		localPath, err := localFilePath(resultPath, file)
+		if err != nil {
+			return "", err
+		}
+		alreadyExists, err := downloader.IsAlreadyDownloaded(localPath)
+		if err != nil {
+			return "", err
+		}
+		if alreadyExists {
+			log.Ctx(ctx).Debug().Str("File", file).Msg("File already downloaded.")
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(localPath), downloader.DownloadFolderPerm); err != nil {
+			return "", err
+		}
+		fileURL, err := webhook.JoinURL(sourceSpec.URL, file)
+		if err != nil {
+			return "", err
+		}
+		if err = d.httpDownloader.Fetch(ctx, fileURL, localPath, item.Progress); err != nil {
+			return "", err
+		}
+	}
+	return resultPath, nil
+}
+
+// localFilePath returns where a published file is downloaded, making sure it stays within the result directory
+func localFilePath(resultPath string, file string) (string, error) {
+	localPath := filepath.Join(resultPath, filepath.FromSlash(file))
+	rel, err := filepath.Rel(resultPath, localPath)
+	if err != nil || rel == "." || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("invalid published file path: %s", file)
+	}
+	return localPath, nil
+}
+
+// compile-time check for interface implementation
+var _ downloader.Downloader = (*Downloader)(nil)
+var _ downloader.Lister = (*Downloader)(nil)
@@ -0,0 +1,174 @@
+//go:build unit || !integration
+
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/downloader"
+	bachttp "github.com/bacalhau-project/bacalhau/pkg/downloader/http"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/webhook"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+// store keeps uploaded content in memory and serves it back
+type store struct {
+	mu      sync.Mutex
+	content map[string][]byte
+}
+
+func (s *store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		s.content[r.URL.Path] = body
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		body, ok := s.content[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(body)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+type DownloaderTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	server     *httptest.Server
+	publisher  *webhook.Publisher
+	downloader *Downloader
+	resultDir  string
+}
+
+func TestDownloaderTestSuite(t *testing.T) {
+	suite.Run(t, new(DownloaderTestSuite))
+}
+
+func (s *DownloaderTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.server = httptest.NewServer(&store{content: make(map[string][]byte)})
+	s.T().Cleanup(s.server.Close)
+
+	s.publisher = webhook.NewPublisher(webhook.PublisherParams{
+		LocalDir:   s.T().TempDir(),
+		HTTPClient: s.server.Client(),
+	})
+	s.downloader = NewDownloader(DownloaderParams{
+		HTTPDownloader: bachttp.NewHTTPDownloader(),
+	})
+
+	s.resultDir = s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(s.resultDir, "stdout"), []byte("hello"), 0644))
+	s.Require().NoError(os.MkdirAll(filepath.Join(s.resultDir, "outputs"), 0755))
+	s.Require().NoError(os.WriteFile(filepath.Join(s.resultDir, "outputs", "data.txt"), []byte("data"), 0644))
+}
+
+func (s *DownloaderTestSuite) publish(encoding webhook.Encoding) *models.SpecConfig {
+	execution := mock.Execution()
+	execution.Job.Task().Publisher = &models.SpecConfig{
+		Type: models.PublisherWebhook,
+		Params: webhook.PublisherSpec{
+			URL:      s.server.URL + "/results/{executionID}",
+			Encoding: encoding,
+		}.ToMap(),
+	}
+	result, err := s.publisher.PublishResult(s.ctx, execution, s.resultDir)
+	s.Require().NoError(err)
+	return &result
+}
+
+func (s *DownloaderTestSuite) TestIsInstalled() {
+	res, err := s.downloader.IsInstalled(s.ctx)
+	s.Require().NoError(err)
+	s.True(res)
+}
+
+func (s *DownloaderTestSuite) TestDownloadArchive() {
+	result := s.publish(webhook.EncodingGzip)
+	downloadPath, err := s.downloader.FetchResult(s.ctx, downloader.DownloadItem{
+		Result:     result,
+		ParentPath: s.T().TempDir(),
+	})
+	s.Require().NoError(err)
+	s.True(strings.HasSuffix(downloadPath, ".tar.gz"), downloadPath)
+	s.FileExists(downloadPath)
+}
+
+func (s *DownloaderTestSuite) TestDownloadArchiveSingleFile() {
+	result := s.publish(webhook.EncodingGzip)
+	_, err := s.downloader.FetchResult(s.ctx, downloader.DownloadItem{
+		Result:     result,
+		SingleFile: "stdout",
+		ParentPath: s.T().TempDir(),
+	})
+	s.Require().Error(err)
+}
+
+func (s *DownloaderTestSuite) TestDownloadFiles() {
+	result := s.publish(webhook.EncodingPlain)
+	downloadPath, err := s.downloader.FetchResult(s.ctx, downloader.DownloadItem{
+		Result:     result,
+		ParentPath: s.T().TempDir(),
+	})
+	s.Require().NoError(err)
+	s.assertFile(filepath.Join(downloadPath, "stdout"), "hello")
+	s.assertFile(filepath.Join(downloadPath, "outputs", "data.txt"), "data")
+}
+
+func (s *DownloaderTestSuite) TestDownloadSingleFile() {
+	result := s.publish(webhook.EncodingPlain)
+	downloadPath, err := s.downloader.FetchResult(s.ctx, downloader.DownloadItem{
+		Result:     result,
+		SingleFile: "outputs/data.txt",
+		ParentPath: s.T().TempDir(),
+	})
+	s.Require().NoError(err)
+	s.assertFile(filepath.Join(downloadPath, "outputs", "data.txt"), "data")
+	s.NoFileExists(filepath.Join(downloadPath, "stdout"))
+
+	_, err = s.downloader.FetchResult(s.ctx, downloader.DownloadItem{
+		Result:     result,
+		SingleFile: "missing",
+		ParentPath: s.T().TempDir(),
+	})
+	s.Require().Error(err)
+}
+
+func (s *DownloaderTestSuite) TestDownloadRejectsPathTraversal() {
+	result := &models.SpecConfig{
+		Type: models.StorageSourceWebhook,
+		Params: webhook.ResultSpec{
+			URL:      s.server.URL + "/results/",
+			Encoding: webhook.EncodingPlain,
+			Files:    []string{"../escape"},
+		}.ToMap(),
+	}
+	_, err := s.downloader.FetchResult(s.ctx, downloader.DownloadItem{
+		Result:     result,
+		ParentPath: s.T().TempDir(),
+	})
+	s.Require().Error(err)
+}
+
+func (s *DownloaderTestSuite) assertFile(path string, expected string) {
+	content, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Equal(expected, string(content))
+}
@@ -0,0 +1,118 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/config/types"
+	dockermodels "github.com/bacalhau-project/bacalhau/pkg/executor/docker/models"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+const containerPolicyReason = "accept the requested container options"
+
+var _ bidstrategy.SemanticBidStrategy = (*ContainerPolicyBidStrategy)(nil)
+
+// ContainerPolicyBidStrategy rejects docker jobs that request container options,
+// such as capabilities, ulimits or tmpfs mounts, that exceed the policy of the compute node.
+type ContainerPolicyBidStrategy struct {
+	policy types.DockerPolicy
+}
+
+func NewContainerPolicyBidStrategy(policy types.DockerPolicy) *ContainerPolicyBidStrategy {
+	return &ContainerPolicyBidStrategy{policy: policy}
+}
+
+// ShouldBid implements semantic.SemanticBidStrategy
+func (s *ContainerPolicyBidStrategy) ShouldBid(
+	ctx context.Context,
+	request bidstrategy.BidStrategyRequest,
+) (bidstrategy.BidStrategyResponse, error) {
+	if request.Job.Task().Engine.Type != models.EngineDocker {
+		return bidstrategy.NewBidResponse(true, "examine container options for non-Docker jobs"), nil
+	}
+
+	dockerEngine, err := dockermodels.DecodeSpec(request.Job.Task().Engine)
+	if err != nil {
+		return bidstrategy.BidStrategyResponse{}, err
+	}
+
+	if err = ValidateContainerPolicy(dockerEngine, s.policy); err != nil {
+		return bidstrategy.NewBidResponse(false, containerPolicyReason+": %s", err), nil
+	}
+	return bidstrategy.NewBidResponse(true, containerPolicyReason), nil
+}
+
+// ValidateContainerPolicy returns an error if the container options of the engine spec exceed the policy.
+func ValidateContainerPolicy(spec dockermodels.EngineSpec, policy types.DockerPolicy) error {
+	var mErr error
+
+	if policy.MaxShmSize != "" && spec.ShmSize != "" {
+		maxShmSize, err := humanize.ParseBytes(policy.MaxShmSize)
+		if err != nil {
+			return fmt.Errorf("invalid docker policy max shm size %q: %w", policy.MaxShmSize, err)
+		}
+		if spec.ShmSizeBytes() > maxShmSize {
+			mErr = errors.Join(mErr, fmt.Errorf("shm size %s exceeds the maximum of %s", spec.ShmSize, policy.MaxShmSize))
+		}
+	}
+
+	if policy.MaxTmpfsSize != "" && len(spec.Tmpfs) > 0 {
+		maxTmpfsSize, err := humanize.ParseBytes(policy.MaxTmpfsSize)
+		if err != nil {
+			return fmt.Errorf("invalid docker policy max tmpfs size %q: %w", policy.MaxTmpfsSize, err)
+		}
+		var total uint64
+		for _, tmpfs := range spec.Tmpfs {
+			if tmpfs.Size == "" {
+				mErr = errors.Join(mErr, fmt.Errorf("tmpfs mount %s must specify its size", tmpfs.Target))
+			}
+			total += tmpfs.SizeBytes()
+		}
+		if total > maxTmpfsSize {
+			mErr = errors.Join(mErr, fmt.Errorf("total tmpfs size %s exceeds the maximum of %s",
+				humanize.IBytes(total), policy.MaxTmpfsSize))
+		}
+	}
+
+	allowedCapabilities := make([]string, 0, len(policy.AllowedCapabilities))
+	for _, capability := range policy.AllowedCapabilities {
+		allowedCapabilities = append(allowedCapabilities, normalizeCapability(capability))
+	}
+	for _, capability := range spec.CapAdd {
+		if !slices.Contains(allowedCapabilities, normalizeCapability(capability)) {
+			mErr = errors.Join(mErr, fmt.Errorf("adding capability %s is not allowed", capability))
+		}
+	}
+
+	for _, ulimit := range spec.Ulimits {
+		if !slices.ContainsFunc(policy.AllowedUlimits, func(allowed string) bool {
+			return strings.EqualFold(allowed, ulimit.Name)
+		}) {
+			mErr = errors.Join(mErr, fmt.Errorf("setting ulimit %s is not allowed", ulimit.Name))
+		}
+	}
+
+	if policy.RequireNonRootUser && isRootUser(spec.User) {
+		mErr = errors.Join(mErr, errors.New("jobs must run as a non-root user"))
+	}
+	return mErr
+}
+
+// normalizeCapability returns the capability in the upper case form without the CAP_ prefix, e.g. SYS_PTRACE
+func normalizeCapability(capability string) string {
+	return strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(capability)), "CAP_")
+}
+
+// isRootUser returns true if the container user is root, or not set and so defaults to the image's user,
+// which is commonly root.
+func isRootUser(user string) bool {
+	name, _, _ := strings.Cut(user, ":")
+	return name == "" || name == "0" || name == "root"
+}
@@ -0,0 +1,100 @@
+//go:build unit || !integration
+
+package semantic_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/config/types"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/docker/bidstrategy/semantic"
+	dockermodels "github.com/bacalhau-project/bacalhau/pkg/executor/docker/models"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+type ContainerPolicyTestSuite struct {
+	suite.Suite
+	policy types.DockerPolicy
+}
+
+func TestContainerPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(ContainerPolicyTestSuite))
+}
+
+func (s *ContainerPolicyTestSuite) SetupTest() {
+	s.policy = types.DockerPolicy{
+		MaxShmSize:          "1Gi",
+		MaxTmpfsSize:        "1Gi",
+		AllowedCapabilities: []string{"SYS_PTRACE"},
+		AllowedUlimits:      []string{"nofile"},
+	}
+}
+
+func (s *ContainerPolicyTestSuite) shouldBid(jobType string, builder *dockermodels.DockerEngineBuilder) bidstrategy.BidStrategyResponse {
+	job := mock.Job()
+	job.Type = jobType
+	job.Task().Engine = builder.MustBuild()
+	response, err := semantic.NewContainerPolicyBidStrategy(s.policy).ShouldBid(
+		context.Background(), bidstrategy.BidStrategyRequest{Job: *job})
+	s.Require().NoError(err)
+	return response
+}
+
+func (s *ContainerPolicyTestSuite) TestAllowedOptions() {
+	response := s.shouldBid(models.JobTypeBatch, dockermodels.NewDockerEngineBuilder("ubuntu").
+		WithShmSize("512Mi").
+		WithTmpfs(dockermodels.TmpfsMount{Target: "/a", Size: "512Mi"}, dockermodels.TmpfsMount{Target: "/b", Size: "512Mi"}).
+		WithCapabilities([]string{"cap_sys_ptrace"}, []string{"ALL"}).
+		WithUlimits(dockermodels.Ulimit{Name: "NOFILE", Soft: 1024, Hard: 1024}))
+	s.True(response.ShouldBid, response.Reason)
+}
+
+func (s *ContainerPolicyTestSuite) TestRejectedOptions() {
+	tests := []struct {
+		name    string
+		builder *dockermodels.DockerEngineBuilder
+	}{
+		{
+			name:    "shm size above maximum",
+			builder: dockermodels.NewDockerEngineBuilder("ubuntu").WithShmSize("2Gi"),
+		},
+		{
+			name: "total tmpfs size above maximum",
+			builder: dockermodels.NewDockerEngineBuilder("ubuntu").WithTmpfs(
+				dockermodels.TmpfsMount{Target: "/a", Size: "768Mi"}, dockermodels.TmpfsMount{Target: "/b", Size: "768Mi"}),
+		},
+		{
+			name:    "tmpfs without size",
+			builder: dockermodels.NewDockerEngineBuilder("ubuntu").WithTmpfs(dockermodels.TmpfsMount{Target: "/a"}),
+		},
+		{
+			name:    "capability not allowed",
+			builder: dockermodels.NewDockerEngineBuilder("ubuntu").WithCapabilities([]string{"SYS_ADMIN"}, nil),
+		},
+		{
+			name: "ulimit not allowed",
+			builder: dockermodels.NewDockerEngineBuilder("ubuntu").WithUlimits(
+				dockermodels.Ulimit{Name: "memlock", Soft: -1, Hard: -1}),
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			response := s.shouldBid(models.JobTypeBatch, tt.builder)
+			s.False(response.ShouldBid, response.Reason)
+		})
+	}
+}
+
+func (s *ContainerPolicyTestSuite) TestRequireNonRootUser() {
+	s.policy.RequireNonRootUser = true
+	for _, user := range []string{"", "root", "0:0"} {
+		response := s.shouldBid(models.JobTypeBatch, dockermodels.NewDockerEngineBuilder("ubuntu").WithUser(user))
+		s.False(response.ShouldBid, "user %q should be rejected", user)
+	}
+	response := s.shouldBid(models.JobTypeBatch, dockermodels.NewDockerEngineBuilder("ubuntu").WithUser("1000:1000"))
+	s.True(response.ShouldBid, response.Reason)
+}
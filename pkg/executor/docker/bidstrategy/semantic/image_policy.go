@@ -0,0 +1,255 @@
+package semantic
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"slices"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/cache"
+	"github.com/bacalhau-project/bacalhau/pkg/cache/basic"
+	"github.com/bacalhau-project/bacalhau/pkg/config/types"
+	dockermodels "github.com/bacalhau-project/bacalhau/pkg/executor/docker/models"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
+)
+
+const imagePolicyReason = "accept the image %s"
+
+var _ bidstrategy.SemanticBidStrategy = (*ImagePolicyBidStrategy)(nil)
+
+// ImageRegistry resolves image digests and reads image signatures
+type ImageRegistry interface {
+	ResolveDigest(ctx context.Context, ref reference.Named) (digest.Digest, error)
+	Signatures(ctx context.Context, ref reference.Named, dgst digest.Digest) ([]oci.ImageSignature, error)
+}
+
+type ImagePolicyParams struct {
+	Config types.DockerImagePolicy
+	// CacheConfig configures the cache of the digests that images were resolved to
+	CacheConfig types.DockerManifestCache
+	Registry    ImageRegistry
+}
+
+// ImagePolicy checks the images of docker jobs against the image policy of the compute node.
+type ImagePolicy struct {
+	allowed       []string
+	denied        []string
+	requireDigest bool
+	keys          []crypto.PublicKey
+	registry      ImageRegistry
+
+	// pinned caches the references that images were resolved and verified to, so that executions run
+	// the image that was checked when bidding, even if its tag has moved since.
+	pinned cache.Cache[string]
+}
+
+func NewImagePolicy(params ImagePolicyParams) (*ImagePolicy, error) {
+	for _, pattern := range slices.Concat(params.Config.AllowedImages, params.Config.DeniedImages) {
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid image pattern %q", pattern)
+		}
+	}
+
+	keys := make([]crypto.PublicKey, 0, len(params.Config.PublicKeys))
+	for _, path := range params.Config.PublicKeys {
+		key, err := loadPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	pinned, err := basic.NewCache[string](
+		basic.WithCleanupFrequency(params.CacheConfig.Refresh.AsTimeDuration()),
+		basic.WithMaxCost(params.CacheConfig.Size),
+		basic.WithTTL(params.CacheConfig.TTL.AsTimeDuration()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImagePolicy{
+		allowed:       params.Config.AllowedImages,
+		denied:        params.Config.DeniedImages,
+		requireDigest: params.Config.RequireDigest,
+		keys:          keys,
+		registry:      params.Registry,
+		pinned:        pinned,
+	}, nil
+}
+
+// PinsDigests returns true if images are resolved to the digest that they run
+func (p *ImagePolicy) PinsDigests() bool {
+	return p.requireDigest || len(p.keys) > 0
+}
+
+// Check returns the reference of the image to run, or an error explaining why the image does not satisfy
+// the policy. Images are pinned to their digest if the policy requires it or verifies signatures, so that
+// the image that runs is the image that was verified.
+func (p *ImagePolicy) Check(ctx context.Context, image string) (string, error) {
+	ref, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %q: %w", image, err)
+	}
+
+	name := ref.Name()
+	if len(p.allowed) > 0 && !matchesAny(p.allowed, name) {
+		return "", fmt.Errorf("image %s is not allowed", name)
+	}
+	if matchesAny(p.denied, name) {
+		return "", fmt.Errorf("image %s is denied", name)
+	}
+	if !p.PinsDigests() {
+		return image, nil
+	}
+
+	if pinned, found := p.pinned.Get(ref.String()); found {
+		return pinned, nil
+	}
+
+	dgst, err := p.registry.ResolveDigest(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the digest of image %s: %w", reference.FamiliarString(ref), err)
+	}
+	if len(p.keys) > 0 {
+		if err = p.verifySignatures(ctx, ref, dgst); err != nil {
+			return "", err
+		}
+	}
+
+	pinnedRef, err := reference.WithDigest(reference.TrimNamed(ref), dgst)
+	if err != nil {
+		return "", err
+	}
+	pinned := reference.FamiliarString(pinnedRef)
+	// failing to cache means the image is resolved again when running, which is checked again
+	_ = p.pinned.SetWithDefaultTTL(ref.String(), pinned, 1)
+	return pinned, nil
+}
+
+// Close releases the resources of the policy
+func (p *ImagePolicy) Close() {
+	p.pinned.Close()
+}
+
+// verifySignatures checks that the image has a signature of its digest that is verified by one of the keys
+func (p *ImagePolicy) verifySignatures(ctx context.Context, ref reference.Named, dgst digest.Digest) error {
+	signatures, err := p.registry.Signatures(ctx, ref, dgst)
+	if err != nil {
+		return fmt.Errorf("failed to read the signatures of image %s: %w", reference.FamiliarString(ref), err)
+	}
+	for _, signature := range signatures {
+		signed, err := signature.SignedManifestDigest()
+		if err != nil || signed != dgst {
+			continue
+		}
+		for _, key := range p.keys {
+			if verifySignature(key, signature.Payload, signature.Signature) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("image %s@%s has no signature verified by the trusted keys", reference.FamiliarName(ref), dgst)
+}
+
+// verifySignature verifies a cosign signature of the payload with the public key
+func verifySignature(key crypto.PublicKey, payload, signature []byte) bool {
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		var h hash.Hash
+		switch key.Curve {
+		case elliptic.P384():
+			h = sha512.New384()
+		case elliptic.P521():
+			h = sha512.New()
+		default:
+			h = sha256.New()
+		}
+		h.Write(payload)
+		return ecdsa.VerifyASN1(key, h.Sum(nil), signature)
+	case *rsa.PublicKey:
+		sum := sha256.Sum256(payload)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, payload, signature)
+	default:
+		return false
+	}
+}
+
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image signing key: %w", err)
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("image signing key %s is not PEM encoded", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image signing key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// matchesAny returns true if the image name matches any of the patterns, where * matches within a path
+// segment and ** matches across segments
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := doublestar.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ImagePolicyBidStrategy rejects docker jobs with images that don't satisfy the image policy of the
+// compute node, such as images that are not allowed or not signed by a trusted key.
+type ImagePolicyBidStrategy struct {
+	policy *ImagePolicy
+}
+
+func NewImagePolicyBidStrategy(policy *ImagePolicy) *ImagePolicyBidStrategy {
+	return &ImagePolicyBidStrategy{policy: policy}
+}
+
+// ShouldBid implements semantic.SemanticBidStrategy
+func (s *ImagePolicyBidStrategy) ShouldBid(
+	ctx context.Context,
+	request bidstrategy.BidStrategyRequest,
+) (bidstrategy.BidStrategyResponse, error) {
+	if request.Job.Task().Engine.Type != models.EngineDocker {
+		return bidstrategy.NewBidResponse(true, "examine image policies for non-Docker jobs"), nil
+	}
+
+	dockerEngine, err := dockermodels.DecodeSpec(request.Job.Task().Engine)
+	if err != nil {
+		return bidstrategy.BidStrategyResponse{}, err
+	}
+
+	if _, err = s.policy.Check(ctx, dockerEngine.Image); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return bidstrategy.BidStrategyResponse{}, err
+		}
+		return bidstrategy.NewBidResponse(false, imagePolicyReason+": %s", dockerEngine.Image, err), nil
+	}
+	return bidstrategy.NewBidResponse(true, imagePolicyReason, dockerEngine.Image), nil
+}
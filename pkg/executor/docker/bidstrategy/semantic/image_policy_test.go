@@ -0,0 +1,177 @@
+//go:build unit || !integration
+
+package semantic_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/config/types"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/docker/bidstrategy/semantic"
+	dockermodels "github.com/bacalhau-project/bacalhau/pkg/executor/docker/models"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+type ImagePolicyTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	registry *oci.TestRegistry
+	key      *ecdsa.PrivateKey
+	keyPath  string
+}
+
+func TestImagePolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(ImagePolicyTestSuite))
+}
+
+func (s *ImagePolicyTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.registry = oci.NewTestRegistry(s.T())
+	s.key, s.keyPath = s.generateKey()
+}
+
+// generateKey generates a signing key and writes its public key to a PEM file
+func (s *ImagePolicyTestSuite) generateKey() (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	s.Require().NoError(err)
+	path := filepath.Join(s.T().TempDir(), "cosign.pub")
+	s.Require().NoError(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	return key, path
+}
+
+func (s *ImagePolicyTestSuite) newPolicy(config types.DockerImagePolicy) *semantic.ImagePolicy {
+	policy, err := semantic.NewImagePolicy(semantic.ImagePolicyParams{
+		Config: config,
+		CacheConfig: types.DockerManifestCache{
+			Size:    100,
+			TTL:     types.Duration(time.Hour),
+			Refresh: types.Duration(time.Hour),
+		},
+		Registry: oci.NewClient(oci.ClientParams{
+			InsecureRegistries: []string{s.registry.Host()},
+		}),
+	})
+	s.Require().NoError(err)
+	s.T().Cleanup(policy.Close)
+	return policy
+}
+
+func (s *ImagePolicyTestSuite) shouldBid(policy *semantic.ImagePolicy, image string) bidstrategy.BidStrategyResponse {
+	job := mock.Job()
+	job.Task().Engine = dockermodels.NewDockerEngineBuilder(image).MustBuild()
+	response, err := semantic.NewImagePolicyBidStrategy(policy).ShouldBid(s.ctx, bidstrategy.BidStrategyRequest{Job: *job})
+	s.Require().NoError(err)
+	return response
+}
+
+func (s *ImagePolicyTestSuite) TestAllowAndDenyPatterns() {
+	policy := s.newPolicy(types.DockerImagePolicy{
+		AllowedImages: []string{"docker.io/library/*", "ghcr.io/my-org/**"},
+		DeniedImages:  []string{"docker.io/library/busybox"},
+	})
+
+	tests := []struct {
+		image   string
+		allowed bool
+	}{
+		{image: "ubuntu:24.04", allowed: true},
+		{image: "docker.io/library/alpine", allowed: true},
+		{image: "ghcr.io/my-org/team/app:v1", allowed: true},
+		{image: "busybox", allowed: false},
+		{image: "someone/ubuntu", allowed: false},
+		{image: "ghcr.io/other-org/app", allowed: false},
+	}
+	for _, tt := range tests {
+		s.Run(tt.image, func() {
+			response := s.shouldBid(policy, tt.image)
+			s.Equal(tt.allowed, response.ShouldBid, response.Reason)
+		})
+	}
+}
+
+func (s *ImagePolicyTestSuite) TestRequireDigestPinsTags() {
+	dgst := s.registry.PushImage("app", "v1", "first")
+	policy := s.newPolicy(types.DockerImagePolicy{RequireDigest: true})
+	s.True(policy.PinsDigests())
+
+	image := s.registry.Host() + "/app:v1"
+	pinned, err := policy.Check(s.ctx, image)
+	s.Require().NoError(err)
+	s.Equal(s.registry.Host()+"/app@"+dgst.String(), pinned)
+
+	// executions run the digest checked when bidding, even if the tag has moved since
+	s.registry.PushImage("app", "v1", "second")
+	pinned, err = policy.Check(s.ctx, image)
+	s.Require().NoError(err)
+	s.Equal(s.registry.Host()+"/app@"+dgst.String(), pinned)
+
+	response := s.shouldBid(policy, s.registry.Host()+"/missing:v1")
+	s.False(response.ShouldBid, response.Reason)
+}
+
+func (s *ImagePolicyTestSuite) TestImagesAreNotResolvedWithoutPinning() {
+	policy := s.newPolicy(types.DockerImagePolicy{})
+	s.False(policy.PinsDigests())
+
+	image := s.registry.Host() + "/missing:v1"
+	checked, err := policy.Check(s.ctx, image)
+	s.Require().NoError(err)
+	s.Equal(image, checked)
+}
+
+func (s *ImagePolicyTestSuite) TestSignatureVerification() {
+	signed := s.registry.PushImage("signed", "v1", "signed")
+	s.registry.SignImage("signed", signed, s.key)
+	s.registry.PushImage("unsigned", "v1", "unsigned")
+	untrusted := s.registry.PushImage("untrusted", "v1", "untrusted")
+	untrustedKey, _ := s.generateKey()
+	s.registry.SignImage("untrusted", untrusted, untrustedKey)
+	// signatures of other digests in the repository do not verify the image
+	copied := s.registry.PushImage("copied", "v1", "copied")
+	s.registry.SignImage("copied", signed, s.key)
+
+	policy := s.newPolicy(types.DockerImagePolicy{PublicKeys: []string{s.keyPath}})
+
+	tests := []struct {
+		image    string
+		verified bool
+	}{
+		{image: "signed:v1", verified: true},
+		{image: "signed@" + signed.String(), verified: true},
+		{image: "unsigned:v1", verified: false},
+		{image: "untrusted:v1", verified: false},
+		{image: "copied@" + copied.String(), verified: false},
+	}
+	for _, tt := range tests {
+		s.Run(tt.image, func() {
+			response := s.shouldBid(policy, s.registry.Host()+"/"+tt.image)
+			s.Equal(tt.verified, response.ShouldBid, response.Reason)
+		})
+	}
+}
+
+func (s *ImagePolicyTestSuite) TestInvalidConfig() {
+	_, err := semantic.NewImagePolicy(semantic.ImagePolicyParams{
+		Config: types.DockerImagePolicy{AllowedImages: []string{"docker.io/[library"}},
+	})
+	s.Error(err)
+
+	_, err = semantic.NewImagePolicy(semantic.ImagePolicyParams{
+		Config: types.DockerImagePolicy{PublicKeys: []string{filepath.Join(s.T().TempDir(), "missing.pub")}},
+	})
+	s.Error(err)
+}
@@ -0,0 +1,30 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/docker"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+)
+
+// Checkpoint sends the checkpoint signal to the container of a running execution,
+// similar to `docker kill --signal`. The task is expected to write its state into
+// the checkpoint directory mounted into the container.
+func (e *Executor) Checkpoint(ctx context.Context, request *executor.CheckpointRequest) error {
+	handler, found := e.handlers.Get(request.ExecutionID)
+	if !found {
+		return executor.NewExecutorError(executor.ExecutionNotFound,
+			fmt.Sprintf("checkpointing execution (%s)", request.ExecutionID))
+	}
+	if !handler.active() {
+		return executor.NewExecutorError(executor.ExecutionNotRunning,
+			fmt.Sprintf("checkpointing execution (%s)", request.ExecutionID))
+	}
+
+	if err := e.client.ContainerKill(ctx, handler.containerID, request.Signal); err != nil {
+		return docker.NewDockerError(err)
+	}
+	handler.logger.Debug().Str("signal", request.Signal).Msg("signalled container to checkpoint")
+	return nil
+}
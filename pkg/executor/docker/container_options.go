@@ -0,0 +1,48 @@
+package docker
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+
+	dockermodels "github.com/bacalhau-project/bacalhau/pkg/executor/docker/models"
+)
+
+// applyContainerOptions configures the host config with the container options requested by the engine spec,
+// such as the size of /dev/shm, tmpfs mounts, ulimits and capabilities.
+func applyContainerOptions(hostConfig *container.HostConfig, spec dockermodels.EngineSpec) error {
+	if spec.ShmSize != "" {
+		shmSize := spec.ShmSizeBytes()
+		if shmSize > math.MaxInt64 {
+			return fmt.Errorf("shm size %s exceeds maximum allowed integer value", spec.ShmSize)
+		}
+		hostConfig.ShmSize = int64(shmSize) //nolint:gosec // G115: overflow already checked above
+	}
+
+	for _, tmpfs := range spec.Tmpfs {
+		size := tmpfs.SizeBytes()
+		if size > math.MaxInt64 {
+			return fmt.Errorf("tmpfs size %s exceeds maximum allowed integer value", tmpfs.Size)
+		}
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:         mount.TypeTmpfs,
+			Target:       tmpfs.Target,
+			TmpfsOptions: &mount.TmpfsOptions{SizeBytes: int64(size)}, //nolint:gosec // G115: overflow already checked above
+		})
+	}
+
+	for _, ulimit := range spec.Ulimits {
+		hostConfig.Ulimits = append(hostConfig.Ulimits, &container.Ulimit{
+			Name: ulimit.Name,
+			Soft: ulimit.Soft,
+			Hard: ulimit.Hard,
+		})
+	}
+
+	hostConfig.CapAdd = spec.CapAdd
+	hostConfig.CapDrop = spec.CapDrop
+	hostConfig.ReadonlyRootfs = spec.ReadOnlyRootfs
+	return nil
+}
@@ -0,0 +1,227 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/bacalhau-project/bacalhau/pkg/docker"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+)
+
+// Exec runs an interactive command inside the container of a running execution,
+// similar to `docker exec`.
+func (e *Executor) Exec(ctx context.Context, request *executor.ExecRequest) (executor.ExecSession, error) {
+	handler, err := e.runningHandler(request.ExecutionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(request.Command) == 0 {
+		return nil, executor.NewExecutorError(executor.ExecutorSpecValidationErr, "exec requires a command to run")
+	}
+
+	created, err := e.client.ContainerExecCreate(ctx, handler.containerID, container.ExecOptions{
+		Tty:          request.TTY,
+		ConsoleSize:  consoleSize(request),
+		AttachStdin:  request.Stdin,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          request.Command,
+	})
+	if err != nil {
+		return nil, docker.NewDockerError(err)
+	}
+
+	resp, err := e.client.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{
+		Tty:         request.TTY,
+		ConsoleSize: consoleSize(request),
+	})
+	if err != nil {
+		return nil, docker.NewDockerError(err)
+	}
+
+	handler.logger.Info().Strs("command", request.Command).Str("exec", created.ID).Msg("started exec session")
+	return newExecSession(resp, request.Stdin, request.TTY, execSessionHooks{
+		resize: func(ctx context.Context, height, width uint) error {
+			return e.client.ContainerExecResize(ctx, created.ID, container.ResizeOptions{Height: height, Width: width})
+		},
+		exitCode: func(ctx context.Context) (int, error) {
+			inspect, err := e.client.ContainerExecInspect(ctx, created.ID)
+			if err != nil {
+				return 0, docker.NewDockerError(err)
+			}
+			return inspect.ExitCode, nil
+		},
+	}), nil
+}
+
+// Attach connects to the output of the main process of a running execution, similar to `docker attach`.
+// Execution containers are started without standard input, so attached sessions only receive output.
+func (e *Executor) Attach(ctx context.Context, request *executor.ExecRequest) (executor.ExecSession, error) {
+	handler, err := e.runningHandler(request.ExecutionID)
+	if err != nil {
+		return nil, err
+	}
+
+	inspect, err := e.client.ContainerInspect(ctx, handler.containerID)
+	if err != nil {
+		return nil, docker.NewDockerError(err)
+	}
+	tty := inspect.Config != nil && inspect.Config.Tty
+
+	resp, err := e.client.ContainerAttach(ctx, handler.containerID, container.AttachOptions{
+		Stream: true,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return nil, docker.NewDockerError(err)
+	}
+
+	handler.logger.Info().Msg("attached to execution container")
+	return newExecSession(resp, false, tty, execSessionHooks{
+		resize: func(ctx context.Context, height, width uint) error {
+			return e.client.ContainerResize(ctx, handler.containerID, container.ResizeOptions{Height: height, Width: width})
+		},
+		exitCode: func(ctx context.Context) (int, error) {
+			inspect, err := e.client.ContainerInspect(ctx, handler.containerID)
+			if err != nil {
+				return 0, docker.NewDockerError(err)
+			}
+			if inspect.State == nil || inspect.State.Running {
+				// the session was detached while the container keeps running
+				return -1, nil
+			}
+			return inspect.State.ExitCode, nil
+		},
+	}), nil
+}
+
+// runningHandler returns the handler of an execution whose container is running
+func (e *Executor) runningHandler(executionID string) (*executionHandler, error) {
+	handler, found := e.handlers.Get(executionID)
+	if !found {
+		return nil, executor.NewExecutorError(executor.ExecutionNotFound,
+			fmt.Sprintf("opening session into execution (%s)", executionID))
+	}
+	if !handler.active() {
+		return nil, executor.NewExecutorError(executor.ExecutionNotRunning,
+			fmt.Sprintf("opening session into execution (%s)", executionID))
+	}
+	return handler, nil
+}
+
+func consoleSize(request *executor.ExecRequest) *[2]uint {
+	if !request.TTY || request.Height == 0 || request.Width == 0 {
+		return nil
+	}
+	return &[2]uint{request.Height, request.Width}
+}
+
+// execSessionHooks are the container operations an execSession relies on
+type execSessionHooks struct {
+	resize   func(ctx context.Context, height, width uint) error
+	exitCode func(ctx context.Context) (int, error)
+}
+
+// execSession is an interactive session backed by a hijacked connection to the docker daemon.
+// Without a TTY, docker multiplexes stdout and stderr on the connection, and the session
+// demultiplexes them into separate pipes. Both pipes must be drained for the session to progress.
+type execSession struct {
+	resp   types.HijackedResponse
+	tty    bool
+	hooks  execSessionHooks
+	stdin  io.WriteCloser
+	stdout *io.PipeReader
+	stderr *io.PipeReader
+	done   chan struct{}
+}
+
+func newExecSession(resp types.HijackedResponse, stdin bool, tty bool, hooks execSessionHooks) *execSession {
+	stdoutReader, stdoutWriter := io.Pipe()
+	s := &execSession{
+		resp:   resp,
+		tty:    tty,
+		hooks:  hooks,
+		stdout: stdoutReader,
+		done:   make(chan struct{}),
+	}
+	if stdin {
+		s.stdin = &hijackedStdin{resp: &s.resp}
+	}
+
+	var stderrWriter *io.PipeWriter
+	if !tty {
+		s.stderr, stderrWriter = io.Pipe()
+	}
+
+	go func() {
+		defer close(s.done)
+		var err error
+		if tty {
+			_, err = io.Copy(stdoutWriter, resp.Reader)
+		} else {
+			_, err = stdcopy.StdCopy(stdoutWriter, stderrWriter, resp.Reader)
+			stderrWriter.CloseWithError(err)
+		}
+		stdoutWriter.CloseWithError(err)
+	}()
+	return s
+}
+
+func (s *execSession) Stdin() io.WriteCloser {
+	return s.stdin
+}
+
+func (s *execSession) Stdout() io.Reader {
+	return s.stdout
+}
+
+func (s *execSession) Stderr() io.Reader {
+	if s.stderr == nil {
+		return nil
+	}
+	return s.stderr
+}
+
+func (s *execSession) Resize(ctx context.Context, height, width uint) error {
+	if !s.tty {
+		return nil
+	}
+	return s.hooks.resize(ctx, height, width)
+}
+
+func (s *execSession) Wait(ctx context.Context) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-s.done:
+	}
+	return s.hooks.exitCode(ctx)
+}
+
+func (s *execSession) Close() error {
+	s.resp.Close()
+	return nil
+}
+
+// hijackedStdin writes to the standard input of a hijacked connection.
+// Closing it half-closes the connection so the command observes EOF while its output keeps flowing.
+type hijackedStdin struct {
+	resp *types.HijackedResponse
+}
+
+func (w *hijackedStdin) Write(p []byte) (int, error) {
+	return w.resp.Conn.Write(p)
+}
+
+func (w *hijackedStdin) Close() error {
+	return w.resp.CloseWrite()
+}
+
+// compile-time check for interface implementation
+var _ executor.ExecSession = (*execSession)(nil)
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/distribution/reference"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/pkg/errors"
@@ -30,6 +31,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/docker"
 	"github.com/bacalhau-project/bacalhau/pkg/executor"
 	"github.com/bacalhau-project/bacalhau/pkg/executor/docker/bidstrategy/semantic"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
 	"github.com/bacalhau-project/bacalhau/pkg/util/generic"
@@ -50,6 +52,8 @@ type ExecutorParams struct {
 	ID              string
 	Config          types.Docker
 	ShouldKeepStack bool
+	// ImageCacheFile records the images pulled by the node, so that they can be removed after a restart
+	ImageCacheFile string
 }
 
 type Executor struct {
@@ -63,6 +67,9 @@ type Executor struct {
 	complete          map[string]chan struct{}
 	client            *docker.Client
 	dockerCacheConfig types.DockerManifestCache
+	policy            types.DockerPolicy
+	imagePolicy       *semantic.ImagePolicy
+	images            *imageCache
 	shouldKeepStack   bool
 }
 
@@ -72,12 +79,40 @@ func NewExecutor(params ExecutorParams) (*Executor, error) {
 		return nil, err
 	}
 
+	imagePolicy, err := semantic.NewImagePolicy(semantic.ImagePolicyParams{
+		Config:      params.Config.ImagePolicy,
+		CacheConfig: params.Config.ManifestCache,
+		Registry: oci.NewClient(oci.ClientParams{
+			Credentials: oci.Credentials(docker.GetDockerCredentials()),
+			// docker credentials are only used for the default registry, as when pulling through the daemon
+			CredentialsDomains: []string{"docker.io"},
+			InsecureRegistries: params.Config.ImagePolicy.InsecureRegistries,
+		}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker image policy: %w", err)
+	}
+
+	images, err := newImageCache(imageCacheParams{
+		Client:    dockerClient,
+		Policy:    imagePolicy,
+		Config:    params.Config.ImageCache,
+		StatePath: params.ImageCacheFile,
+	})
+	if err != nil {
+		return nil, err
+	}
+	images.Start(context.Background())
+
 	de := &Executor{
 		ID:                params.ID,
 		client:            dockerClient,
 		activeFlags:       make(map[string]chan struct{}),
 		complete:          make(map[string]chan struct{}),
 		dockerCacheConfig: params.Config.ManifestCache,
+		policy:            params.Config.Policy,
+		imagePolicy:       imagePolicy,
+		images:            images,
 		shouldKeepStack:   params.ShouldKeepStack,
 	}
 
@@ -88,6 +123,8 @@ func (e *Executor) Shutdown(ctx context.Context) error {
 	// We have to use a detached context, rather than the one passed in to `NewExecutor`, as it may have already been
 	// canceled and so would prevent us from performing any cleanup work.
 	safeCtx := pkgUtil.NewDetachedContext(ctx)
+	e.images.Stop()
+	e.imagePolicy.Close()
 	if e.shouldKeepStack || !e.client.IsInstalled(safeCtx) {
 		return nil
 	}
@@ -99,6 +136,16 @@ func (e *Executor) Shutdown(ctx context.Context) error {
 	return err
 }
 
+// CachedImages implements executor.ImageCache
+func (e *Executor) CachedImages() []string {
+	return e.images.CachedImages()
+}
+
+// WarmImages implements executor.ImageCache
+func (e *Executor) WarmImages(ctx context.Context, images []string) {
+	e.images.WarmImages(ctx, images)
+}
+
 // IsInstalled checks if docker itself is installed.
 func (e *Executor) IsInstalled(ctx context.Context) (bool, error) {
 	return e.client.IsInstalled(ctx), nil
@@ -108,6 +155,14 @@ func (e *Executor) ShouldBid(
 	ctx context.Context,
 	request bidstrategy.BidStrategyRequest,
 ) (bidstrategy.BidStrategyResponse, error) {
+	response, err := semantic.NewContainerPolicyBidStrategy(e.policy).ShouldBid(ctx, request)
+	if err != nil || !response.ShouldBid {
+		return response, err
+	}
+	response, err = semantic.NewImagePolicyBidStrategy(e.imagePolicy).ShouldBid(ctx, request)
+	if err != nil || !response.ShouldBid {
+		return response, err
+	}
 	return semantic.NewImagePlatformBidStrategy(e.client, e.dockerCacheConfig).ShouldBid(ctx, request)
 }
 
@@ -153,6 +208,11 @@ func (e *Executor) Start(ctx context.Context, request *executor.RunCommandReques
 		containerID = jobContainer.ID
 	}
 
+	var imageDigest string
+	if e.imagePolicy.PinsDigests() {
+		imageDigest = e.containerImageDigest(ctx, containerID)
+	}
+
 	childCtx, cancel := context.WithCancelCause(ctx)
 	handler := &executionHandler{
 		client: e.client,
@@ -171,6 +231,10 @@ func (e *Executor) Start(ctx context.Context, request *executor.RunCommandReques
 		activeCh:     make(chan bool),
 		running:      atomic.NewBool(false),
 		cancelFunc:   cancel,
+		imageDigest:  imageDigest,
+		stopSignal:   request.StopSignal,
+		killTimeout:  request.KillTimeout,
+		overlayDirs:  overlayUpperDirs(request.Inputs),
 	}
 
 	// register the handler for this executionID
@@ -230,12 +294,19 @@ func (e *Executor) doWait(ctx context.Context, out chan *models.RunCommandResult
 }
 
 // Cancel tries to cancel a specific execution by its executionID.
+// If the execution has a kill timeout, its container is first sent the stop signal and given the
+// kill timeout to exit, so that the results it writes while terminating are collected.
 // It returns an error if the execution is not found.
 func (e *Executor) Cancel(ctx context.Context, executionID string) error {
 	handler, found := e.handlers.Get(executionID)
 	if !found {
 		return executor.NewExecutorError(executor.ExecutionNotFound, fmt.Sprintf("canceling execution (%s)", executionID))
 	}
+	if handler.killTimeout > 0 && handler.active() {
+		if err := handler.stop(ctx); err != nil {
+			handler.logger.Warn().Err(err).Msg("failed to stop container gracefully")
+		}
+	}
 	handler.cancelFunc(executor.NewExecutorError(executor.ExecutionAlreadyCancelled, "execution already cancelled"))
 	return nil
 }
@@ -320,6 +391,15 @@ func (e *Executor) newDockerJobContainer(ctx context.Context, params *executor.R
 	if err != nil {
 		return container.CreateResponse{}, fmt.Errorf("decoding engine spec: %w", err)
 	}
+	// the policy is checked when bidding, but might have changed since then
+	if err = semantic.ValidateContainerPolicy(dockerArgs, e.policy); err != nil {
+		return container.CreateResponse{}, fmt.Errorf("container options rejected by node policy: %w", err)
+	}
+	// the image is resolved to the digest that was checked when bidding, if the policy pins digests
+	image, err := e.imagePolicy.Check(ctx, dockerArgs.Image)
+	if err != nil {
+		return container.CreateResponse{}, fmt.Errorf("image rejected by node policy: %w", err)
+	}
 
 	// merge both the job level and engine level environment variables
 	envVars := envvar.MergeSlices(
@@ -328,19 +408,36 @@ func (e *Executor) newDockerJobContainer(ctx context.Context, params *executor.R
 	)
 
 	containerConfig := &container.Config{
-		Image:      dockerArgs.Image,
+		Image:      image,
 		Tty:        false,
 		Env:        envVars,
 		Entrypoint: dockerArgs.Entrypoint,
 		Cmd:        dockerArgs.Parameters,
 		Labels:     e.containerLabels(params.ExecutionID, params.JobID),
 		WorkingDir: dockerArgs.WorkingDirectory,
+		User:       dockerArgs.User,
 	}
 
 	mounts, err := makeContainerMounts(ctx, params.Inputs, params.Outputs, compute.ExecutionResultsDir(params.ExecutionDir))
 	if err != nil {
 		return container.CreateResponse{}, fmt.Errorf("creating container mounts: %w", err)
 	}
+	if params.CheckpointDir != "" {
+		// the task writes its checkpoints into this directory when signalled, to be published by the compute node
+		mounts = append(mounts, mount.Mount{
+			Type:   mount.TypeBind,
+			Source: params.CheckpointDir,
+			Target: models.CheckpointPath,
+		})
+	}
+	for _, volume := range params.Volumes {
+		// persistent volumes are node-local directories that outlive the execution
+		mounts = append(mounts, mount.Mount{
+			Type:   mount.TypeBind,
+			Source: volume.Source,
+			Target: volume.Target,
+		})
+	}
 
 	// Create GPU request if the job requests it
 	// TODO we need to use the resource units requested by for the GPU.
@@ -366,13 +463,21 @@ func (e *Executor) newDockerJobContainer(ctx context.Context, params *executor.R
 			Devices:        deviceMappings,
 		},
 	}
+	if err = applyContainerOptions(hostConfig, dockerArgs); err != nil {
+		return container.CreateResponse{}, fmt.Errorf("applying container options: %w", err)
+	}
 
 	if _, set := os.LookupEnv("SKIP_IMAGE_PULL"); !set {
-		if pullErr := e.client.PullImage(ctx, dockerArgs.Image); pullErr != nil {
-			return container.CreateResponse{}, docker.NewDockerImageError(pullErr, dockerArgs.Image)
+		if pullErr := e.images.Pull(ctx, image); pullErr != nil {
+			return container.CreateResponse{}, docker.NewDockerImageError(pullErr, image)
 		}
 	}
-	log.Ctx(ctx).Trace().Msgf("Container: %+v %+v", containerConfig, mounts)
+	// the environment is left out as it may hold resolved secrets
+	log.Ctx(ctx).Trace().
+		Str("image", containerConfig.Image).
+		Strs("entrypoint", containerConfig.Entrypoint).
+		Strs("cmd", containerConfig.Cmd).
+		Msgf("Container mounts: %+v", mounts)
 	// Create a network if the job requests it, modifying the containerConfig and hostConfig.
 	err = e.setupNetworkForJob(ctx, params, containerConfig, hostConfig)
 	if err != nil {
@@ -450,7 +555,15 @@ func makeContainerMounts(
 	// these are paths for both input and output data
 	var mounts []mount.Mount
 	for _, input := range inputs {
-		if input.Volume.Type == storage.StorageVolumeConnectorBind {
+		if input.Volume.Type == storage.StorageVolumeConnectorBind && input.Volume.Overlay != nil {
+			log.Ctx(ctx).Trace().Msgf("Input Overlay Volume: %+v %+v", input.InputSource, input.Volume)
+
+			overlayMount, err := makeOverlayMount(input.Volume)
+			if err != nil {
+				return nil, err
+			}
+			mounts = append(mounts, overlayMount)
+		} else if input.Volume.Type == storage.StorageVolumeConnectorBind {
 			log.Ctx(ctx).Trace().Msgf("Input Volume: %+v %+v", input.InputSource, input.Volume)
 
 			mounts = append(mounts, mount.Mount{
@@ -526,6 +639,7 @@ func labelExecutionValue(executorID string, executionID string) string {
 
 // Compile-time interface check:
 var _ executor.Executor = (*Executor)(nil)
+var _ executor.ImageCache = (*Executor)(nil)
 
 // FindRunningContainer, not part of the Executor interface, is a utility function that
 // helps locate a container durin a restart check.
@@ -533,3 +647,21 @@ func (e *Executor) FindRunningContainer(ctx context.Context, executionID string)
 	labelValue := labelExecutionValue(e.ID, executionID)
 	return e.client.FindContainer(ctx, labelExecutionID, labelValue)
 }
+
+// containerImageDigest returns the digest of the image the container runs, if it runs an image pinned to
+// a digest. The digest is recorded in the result of the execution.
+func (e *Executor) containerImageDigest(ctx context.Context, containerID string) string {
+	info, err := e.client.ContainerInspect(ctx, containerID)
+	if err != nil || info.Config == nil {
+		log.Ctx(ctx).Warn().Err(err).Str("container", containerID).Msg("failed to inspect the image of the container")
+		return ""
+	}
+	ref, err := reference.ParseNormalizedNamed(info.Config.Image)
+	if err != nil {
+		return ""
+	}
+	if digested, ok := ref.(reference.Digested); ok {
+		return digested.Digest().String()
+	}
+	return ""
+}
@@ -158,6 +158,8 @@ func (s *ExecutorTestSuite) startJob(spec *models.Task, name string) {
 				MaxStderrFileLength:   system.MaxStderrFileLength,
 				MaxStderrReturnLength: system.MaxStderrReturnLength,
 			},
+			StopSignal:  spec.GetStopSignal(),
+			KillTimeout: spec.GetKillTimeout(),
 		},
 	))
 }
@@ -419,6 +421,61 @@ func (s *ExecutorTestSuite) TestDockerExecutionCancellation() {
 	}
 }
 
+// cancelRunningJob starts the task, cancels it once its container is running, and returns its result
+func (s *ExecutorTestSuite) cancelRunningJob(task *models.Task) *models.RunCommandResult {
+	executionID := uuid.New().String()
+	s.startJob(task, executionID)
+	s.Require().Eventually(func() bool {
+		handler, ok := s.executor.handlers.Get(executionID)
+		return ok && handler.active()
+	}, time.Second*10, time.Millisecond*100, "Could not find a running container")
+	handler, _ := s.executor.handlers.Get(executionID)
+	<-handler.activeCh
+	// give the entrypoint time to install its signal handlers
+	time.Sleep(time.Second)
+
+	s.Require().NoError(s.executor.Cancel(s.ctx, executionID))
+	resultC, errC := s.executor.Wait(s.ctx, executionID)
+	select {
+	case err := <-errC:
+		s.Require().NoError(err)
+	case result := <-resultC:
+		s.Require().NotNil(result)
+		return result
+	}
+	return nil
+}
+
+func (s *ExecutorTestSuite) TestDockerExecutionGracefulCancellation() {
+	es, err := dockermodels.NewDockerEngineBuilder("busybox:1.37.0").
+		WithEntrypoint("sh", "-c", `trap 'echo flushed; exit 0' INT; echo started; while true; do sleep 0.1; done`).
+		Build()
+	s.Require().NoError(err)
+
+	task := mock.Task()
+	task.Engine = es
+	task.StopSignal = "SIGINT"
+	task.KillTimeout = 10
+
+	result := s.cancelRunningJob(task)
+	s.Equal(0, result.ExitCode)
+	s.Equal("started\nflushed\n", result.STDOUT)
+}
+
+func (s *ExecutorTestSuite) TestDockerExecutionKilledAfterKillTimeout() {
+	es, err := dockermodels.NewDockerEngineBuilder("busybox:1.37.0").
+		WithEntrypoint("sh", "-c", `trap '' TERM; while true; do sleep 0.1; done`).
+		Build()
+	s.Require().NoError(err)
+
+	task := mock.Task()
+	task.Engine = es
+	task.KillTimeout = 1
+
+	result := s.cancelRunningJob(task)
+	s.Equal(137, result.ExitCode)
+}
+
 func (s *ExecutorTestSuite) TestDockerNetworkingAppendsHTTPHeader() {
 	s.server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_, err := w.Write([]byte(r.Header.Get("X-Bacalhau-Job-ID")))
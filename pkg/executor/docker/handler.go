@@ -38,6 +38,14 @@ type executionHandler struct {
 	executionDir string
 	limits       executor.OutputLimits
 	keepStack    bool
+	// imageDigest is the digest of the image the container runs, if it is pinned to a digest
+	imageDigest string
+	// stopSignal is sent to the container to ask it to terminate gracefully when cancelled
+	stopSignal string
+	// killTimeout is how long the container has to exit after the stop signal before it is killed
+	killTimeout time.Duration
+	// overlayDirs are the writable layers of the inputs mounted with an overlay
+	overlayDirs []string
 
 	//
 	// synchronization
@@ -64,6 +72,14 @@ func (h *executionHandler) run(ctx context.Context) {
 		if err := h.destroy(destroyTimeout); err != nil {
 			log.Warn().Err(err).Msg("failed to cleanup container")
 		}
+		for _, dir := range h.overlayDirs {
+			if err := removeOverlayWhiteouts(dir); err != nil {
+				log.Warn().Err(err).Str("dir", dir).Msg("failed to remove whiteouts from input overlay")
+			}
+		}
+		if h.result != nil {
+			h.result.ImageDigest = h.imageDigest
+		}
 		h.running.Store(false)
 		close(h.waitCh)
 		ActiveExecutions.Dec(ctx, attribute.String("executor_id", h.ID))
@@ -200,6 +216,40 @@ func (h *executionHandler) kill(ctx context.Context) error {
 	return h.client.ContainerStop(ctx, h.containerID, time.Second)
 }
 
+// stop asks the container to terminate gracefully by sending it the stop signal, and kills it if it
+// is still running after the kill timeout. It returns once the run method collected the results of the
+// container, so that the outputs written while terminating are part of the execution result.
+func (h *executionHandler) stop(ctx context.Context) error {
+	h.logger.Info().
+		Str("signal", h.stopSignal).
+		Dur("kill_timeout", h.killTimeout).
+		Msg("stopping the container")
+	if err := h.client.ContainerKill(ctx, h.containerID, h.stopSignal); err != nil {
+		return fmt.Errorf("failed to send %s to container (%s): %w", h.stopSignal, h.containerID, err)
+	}
+
+	timer := time.NewTimer(h.killTimeout)
+	defer timer.Stop()
+	select {
+	case <-h.waitCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	h.logger.Info().Msg("container did not stop within the kill timeout, killing it")
+	if err := h.client.ContainerKill(ctx, h.containerID, "SIGKILL"); err != nil {
+		return fmt.Errorf("failed to kill container (%s): %w", h.containerID, err)
+	}
+	select {
+	case <-h.waitCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (h *executionHandler) destroy(timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -0,0 +1,412 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/image"
+	"github.com/dustin/go-humanize"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/config/types"
+)
+
+const (
+	// maxAdvertisedImages limits the number of cached images advertised to the orchestrator
+	maxAdvertisedImages = 100
+	// imageHintQueueSize limits the hinted images waiting to be pulled. Hints are dropped while the queue is full.
+	imageHintQueueSize = 32
+	// imageHintRetryInterval is how long a hinted image is not warmed again, as the orchestrator hints the
+	// same popular images in every heartbeat
+	imageHintRetryInterval = time.Hour
+)
+
+// imageClient is the subset of the docker client used to manage the cached images
+type imageClient interface {
+	PullImage(ctx context.Context, image string) error
+	ImageInspect(ctx context.Context, imageID string) (image.InspectResponse, error)
+	ImageList(ctx context.Context, options image.ListOptions) ([]image.Summary, error)
+	ImageRemove(ctx context.Context, imageID string, options image.RemoveOptions) ([]image.DeleteResponse, error)
+}
+
+// imageChecker checks hinted images against the image policy of the node before they are pulled
+type imageChecker interface {
+	Check(ctx context.Context, image string) (string, error)
+}
+
+// cachedImage tracks an image pulled by the node
+type cachedImage struct {
+	// References are the references the image was pulled by, which are removed to remove the image
+	References []string  `json:"References"`
+	Size       int64     `json:"Size"`
+	LastUsed   time.Time `json:"LastUsed"`
+}
+
+type imageCacheParams struct {
+	Client imageClient
+	Policy imageChecker
+	Config types.DockerImageCache
+	// StatePath is the file recording the images pulled by the node, so that they can still be removed
+	// after a restart. The images are only tracked in memory if empty.
+	StatePath string
+	Clock     clock.Clock
+}
+
+// imageCache keeps images pulled on the node so that executions don't wait for cold image pulls.
+//
+// It pre-pulls the configured images and warms the images hinted by the orchestrator in the background.
+// The images pulled by the node are tracked with their last use, and the least recently used ones are removed
+// once they exceed the disk budget. Images the node did not pull, such as images pulled by the operator,
+// are never removed. Docker refuses to remove images used by containers, which keeps the images of running
+// executions in the cache.
+type imageCache struct {
+	client    imageClient
+	policy    imageChecker
+	disabled  bool
+	prePull   []string
+	maxSize   int64
+	interval  time.Duration
+	statePath string
+	clock     clock.Clock
+
+	hints chan string
+	stop  context.CancelFunc
+	done  chan struct{}
+
+	// mu guards images, advertised and hinted
+	mu         sync.Mutex
+	images     map[string]*cachedImage
+	advertised []string
+	// hinted tracks when hinted images were last queued to be warmed
+	hinted map[string]time.Time
+}
+
+func newImageCache(params imageCacheParams) (*imageCache, error) {
+	var maxSize uint64
+	if params.Config.MaxSize != "" {
+		var err error
+		if maxSize, err = humanize.ParseBytes(params.Config.MaxSize); err != nil {
+			return nil, fmt.Errorf("invalid docker image cache size %q: %w", params.Config.MaxSize, err)
+		}
+		if maxSize > uint64(1<<63-1) {
+			return nil, fmt.Errorf("docker image cache size %d exceeds maximum allowed integer value", maxSize)
+		}
+	}
+	for _, img := range params.Config.PrePull {
+		if _, err := reference.ParseNormalizedNamed(img); err != nil {
+			return nil, fmt.Errorf("invalid docker image to pre-pull %q: %w", img, err)
+		}
+	}
+	if params.Clock == nil {
+		params.Clock = clock.New()
+	}
+
+	c := &imageCache{
+		client:    params.Client,
+		policy:    params.Policy,
+		disabled:  params.Config.Disabled,
+		prePull:   params.Config.PrePull,
+		maxSize:   int64(maxSize),
+		interval:  params.Config.CleanupInterval.AsTimeDuration(),
+		statePath: params.StatePath,
+		clock:     params.Clock,
+		hints:     make(chan string, imageHintQueueSize),
+		images:    make(map[string]*cachedImage),
+		hinted:    make(map[string]time.Time),
+	}
+	c.load()
+	return c, nil
+}
+
+// Start pre-pulls the configured images, and then warms hinted images and removes unused images in the
+// background until the cache is stopped.
+func (c *imageCache) Start(ctx context.Context) {
+	if c.disabled || c.stop != nil {
+		return
+	}
+	ctx, c.stop = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+	go c.run(ctx)
+}
+
+// Stop stops the background work of the cache and waits for it to return
+func (c *imageCache) Stop() {
+	if c.stop == nil {
+		return
+	}
+	c.stop()
+	<-c.done
+}
+
+func (c *imageCache) run(ctx context.Context) {
+	defer close(c.done)
+
+	c.refresh(ctx)
+	for _, img := range c.prePull {
+		if err := c.Pull(ctx, img); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("image", img).Msg("failed to pre-pull docker image")
+		}
+	}
+
+	var cleanup <-chan time.Time
+	if c.interval > 0 {
+		ticker := c.clock.Ticker(c.interval)
+		defer ticker.Stop()
+		cleanup = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case img := <-c.hints:
+			c.warm(ctx, img)
+		case <-cleanup:
+			c.Cleanup(ctx)
+			c.refresh(ctx)
+		}
+	}
+}
+
+// Pull pulls the image if it is not present on the node, and marks it as used if the node pulled it
+func (c *imageCache) Pull(ctx context.Context, img string) error {
+	_, err := c.client.ImageInspect(ctx, img)
+	present := err == nil
+	if err = c.client.PullImage(ctx, img); err != nil {
+		return err
+	}
+	if c.disabled {
+		return nil
+	}
+
+	info, err := c.client.ImageInspect(ctx, img)
+	if err != nil {
+		// the image is pulled, failing to track it only means it is never removed
+		log.Ctx(ctx).Debug().Err(err).Str("image", img).Msg("failed to inspect pulled docker image")
+		return nil
+	}
+
+	c.mu.Lock()
+	record, tracked := c.images[info.ID]
+	if !tracked && present {
+		// the image was not pulled by the node
+		c.mu.Unlock()
+		return nil
+	}
+	if !tracked {
+		record = &cachedImage{}
+		c.images[info.ID] = record
+	}
+	if !slices.Contains(record.References, img) {
+		record.References = append(record.References, img)
+	}
+	record.Size = info.Size
+	record.LastUsed = c.clock.Now()
+	c.save(ctx)
+	c.mu.Unlock()
+
+	if !present {
+		c.refresh(ctx)
+	}
+	return nil
+}
+
+// WarmImages queues the images to be pulled in the background, skipping images that were recently queued.
+// Images are dropped while the queue is full.
+func (c *imageCache) WarmImages(ctx context.Context, images []string) {
+	if c.disabled {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	for img, hinted := range c.hinted {
+		if now.Sub(hinted) >= imageHintRetryInterval {
+			delete(c.hinted, img)
+		}
+	}
+	for _, img := range images {
+		if _, found := c.hinted[img]; found {
+			continue
+		}
+		select {
+		case c.hints <- img:
+			c.hinted[img] = now
+		default:
+			log.Ctx(ctx).Debug().Str("image", img).Msg("docker image warming queue is full, skipping hinted image")
+		}
+	}
+}
+
+// warm pulls a hinted image, if it satisfies the image policy of the node
+func (c *imageCache) warm(ctx context.Context, img string) {
+	checked, err := c.policy.Check(ctx, img)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("image", img).Msg("skipping hinted docker image")
+		return
+	}
+	if err = c.Pull(ctx, checked); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("image", checked).Msg("failed to warm hinted docker image")
+	}
+}
+
+// CachedImages returns the digested references of the most recently created images on the node
+func (c *imageCache) CachedImages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.advertised)
+}
+
+// refresh lists the images on the node to update the advertised images
+func (c *imageCache) refresh(ctx context.Context) {
+	if c.disabled {
+		return
+	}
+	summaries, err := c.client.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to list docker images")
+		return
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Created > summaries[j].Created })
+
+	var advertised []string
+	for _, summary := range summaries {
+		for _, repoDigest := range summary.RepoDigests {
+			ref, err := reference.ParseNormalizedNamed(repoDigest)
+			if err != nil {
+				continue
+			}
+			advertised = append(advertised, ref.String())
+		}
+		if len(advertised) >= maxAdvertisedImages {
+			advertised = advertised[:maxAdvertisedImages]
+			break
+		}
+	}
+
+	c.mu.Lock()
+	c.advertised = advertised
+	c.mu.Unlock()
+}
+
+// Cleanup removes the least recently used images pulled by the node until they fit in the disk budget.
+// Pre-pulled images and images used by containers are kept.
+func (c *imageCache) Cleanup(ctx context.Context) {
+	if c.maxSize == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var total int64
+	ids := make([]string, 0, len(c.images))
+	for id, record := range c.images {
+		if _, err := c.client.ImageInspect(ctx, id); errdefs.IsNotFound(err) {
+			// the image was removed by someone else
+			delete(c.images, id)
+			continue
+		}
+		total += record.Size
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return c.images[ids[i]].LastUsed.Before(c.images[ids[j]].LastUsed) })
+
+	for _, id := range ids {
+		if total <= c.maxSize {
+			break
+		}
+		record := c.images[id]
+		if c.isPrePulled(record) {
+			continue
+		}
+		if err := c.remove(ctx, record); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("image", id).Msg("keeping docker image that cannot be removed")
+			continue
+		}
+		log.Ctx(ctx).Debug().Str("image", id).Strs("references", record.References).Msg("removed unused docker image")
+		delete(c.images, id)
+		total -= record.Size
+	}
+	c.save(ctx)
+}
+
+// remove removes the references the image was pulled by, which removes the image once it has no other
+// references. Docker refuses to remove the references of images used by containers.
+func (c *imageCache) remove(ctx context.Context, record *cachedImage) error {
+	for _, ref := range record.References {
+		_, err := c.client.ImageRemove(ctx, ref, image.RemoveOptions{PruneChildren: true})
+		if err != nil && !errdefs.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// isPrePulled returns true if the image was pulled by one of the configured images to pre-pull
+func (c *imageCache) isPrePulled(record *cachedImage) bool {
+	for _, ref := range record.References {
+		for _, img := range c.prePull {
+			if sameImage(ref, img) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameImage returns true if both references normalize to the same reference, e.g. "ubuntu" and
+// "docker.io/library/ubuntu:latest"
+func sameImage(a, b string) bool {
+	refA, errA := reference.ParseNormalizedNamed(a)
+	refB, errB := reference.ParseNormalizedNamed(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return reference.TagNameOnly(refA).String() == reference.TagNameOnly(refB).String()
+}
+
+func (c *imageCache) load() {
+	if c.statePath == "" {
+		return
+	}
+	data, err := os.ReadFile(c.statePath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warn().Err(err).Msg("failed to read docker image cache state")
+		}
+		return
+	}
+	images := make(map[string]*cachedImage)
+	if err = json.Unmarshal(data, &images); err != nil {
+		log.Warn().Err(err).Msg("ignoring corrupted docker image cache state")
+		return
+	}
+	c.images = images
+}
+
+// save persists the images pulled by the node so that they can be removed after a restart.
+// It must be called with mu held.
+func (c *imageCache) save(ctx context.Context) {
+	if c.statePath == "" {
+		return
+	}
+	data, err := json.Marshal(c.images)
+	if err == nil {
+		if err = os.WriteFile(c.statePath+".tmp", data, 0o600); err == nil {
+			err = os.Rename(c.statePath+".tmp", c.statePath)
+		}
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to save docker image cache state")
+	}
+}
@@ -0,0 +1,270 @@
+//go:build unit || !integration
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/image"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/config/types"
+)
+
+// fakeImage is an image of the fake image client
+type fakeImage struct {
+	id     string
+	size   int64
+	digest string
+}
+
+// fakeImageClient is an in-memory docker daemon that pulls images from a fake registry
+type fakeImageClient struct {
+	mu       sync.Mutex
+	registry map[string]fakeImage
+	// local maps the references of the images on the node to their image
+	local map[string]fakeImage
+	// inUse are the IDs of the images used by containers
+	inUse map[string]bool
+	pulls []string
+}
+
+func newFakeImageClient() *fakeImageClient {
+	return &fakeImageClient{
+		registry: make(map[string]fakeImage),
+		local:    make(map[string]fakeImage),
+		inUse:    make(map[string]bool),
+	}
+}
+
+func (c *fakeImageClient) PullImage(_ context.Context, img string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, present := c.local[img]; present {
+		return nil
+	}
+	fake, found := c.registry[img]
+	if !found {
+		return fmt.Errorf("image %s: %w", img, errdefs.ErrNotFound)
+	}
+	c.local[img] = fake
+	c.pulls = append(c.pulls, img)
+	return nil
+}
+
+func (c *fakeImageClient) ImageInspect(_ context.Context, imageID string) (image.InspectResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for ref, fake := range c.local {
+		if ref == imageID || fake.id == imageID {
+			return image.InspectResponse{ID: fake.id, Size: fake.size}, nil
+		}
+	}
+	return image.InspectResponse{}, fmt.Errorf("image %s: %w", imageID, errdefs.ErrNotFound)
+}
+
+func (c *fakeImageClient) ImageList(context.Context, image.ListOptions) ([]image.Summary, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	summaries := make([]image.Summary, 0, len(c.local))
+	for _, fake := range c.local {
+		summaries = append(summaries, image.Summary{ID: fake.id, RepoDigests: []string{fake.digest}})
+	}
+	return summaries, nil
+}
+
+func (c *fakeImageClient) ImageRemove(_ context.Context, imageID string, _ image.RemoveOptions) ([]image.DeleteResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fake, found := c.local[imageID]
+	if !found {
+		return nil, fmt.Errorf("image %s: %w", imageID, errdefs.ErrNotFound)
+	}
+	if c.inUse[fake.id] {
+		return nil, fmt.Errorf("image %s is used by a container: %w", imageID, errdefs.ErrConflict)
+	}
+	delete(c.local, imageID)
+	return []image.DeleteResponse{{Untagged: imageID}}, nil
+}
+
+func (c *fakeImageClient) present(img string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, found := c.local[img]
+	return found
+}
+
+func (c *fakeImageClient) pulled() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.pulls)
+}
+
+// fakeImagePolicy denies the images in denied, and allows any other image
+type fakeImagePolicy struct {
+	denied []string
+}
+
+func (p fakeImagePolicy) Check(_ context.Context, img string) (string, error) {
+	if slices.Contains(p.denied, img) {
+		return "", fmt.Errorf("image %s is denied", img)
+	}
+	return img, nil
+}
+
+type ImageCacheTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	client *fakeImageClient
+	clock  *clock.Mock
+}
+
+func TestImageCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(ImageCacheTestSuite))
+}
+
+func (s *ImageCacheTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.client = newFakeImageClient()
+	s.clock = clock.NewMock()
+	for i, img := range []string{"first:v1", "second:v1", "third:v1", "pinned:v1", "denied:v1"} {
+		s.client.registry[img] = fakeImage{
+			id:     fmt.Sprintf("sha256:%d", i),
+			size:   100,
+			digest: fmt.Sprintf("%s@sha256:%064d", img[:len(img)-3], i),
+		}
+	}
+}
+
+func (s *ImageCacheTestSuite) newCache(config types.DockerImageCache, statePath string) *imageCache {
+	cache, err := newImageCache(imageCacheParams{
+		Client:    s.client,
+		Policy:    fakeImagePolicy{denied: []string{"denied:v1"}},
+		Config:    config,
+		StatePath: statePath,
+		Clock:     s.clock,
+	})
+	s.Require().NoError(err)
+	s.T().Cleanup(cache.Stop)
+	return cache
+}
+
+func (s *ImageCacheTestSuite) pull(cache *imageCache, images ...string) {
+	for _, img := range images {
+		s.Require().NoError(cache.Pull(s.ctx, img))
+		s.clock.Add(time.Minute)
+	}
+}
+
+func (s *ImageCacheTestSuite) TestCleanupRemovesLeastRecentlyUsedImages() {
+	cache := s.newCache(types.DockerImageCache{MaxSize: "250B"}, "")
+	s.pull(cache, "first:v1", "second:v1", "third:v1")
+	// using the first image again makes the second image the least recently used
+	s.pull(cache, "first:v1")
+
+	cache.Cleanup(s.ctx)
+	s.True(s.client.present("first:v1"))
+	s.False(s.client.present("second:v1"))
+	s.True(s.client.present("third:v1"))
+}
+
+func (s *ImageCacheTestSuite) TestCleanupOnlyRemovesImagesPulledByTheNode() {
+	// the first image was pulled by someone else before the node used it
+	s.Require().NoError(s.client.PullImage(s.ctx, "first:v1"))
+	cache := s.newCache(types.DockerImageCache{MaxSize: "1B"}, "")
+	s.pull(cache, "first:v1", "second:v1")
+
+	cache.Cleanup(s.ctx)
+	s.True(s.client.present("first:v1"))
+	s.False(s.client.present("second:v1"))
+}
+
+func (s *ImageCacheTestSuite) TestCleanupKeepsPrePulledAndUsedImages() {
+	cache := s.newCache(types.DockerImageCache{MaxSize: "1B", PrePull: []string{"docker.io/library/pinned:v1"}}, "")
+	s.pull(cache, "pinned:v1", "first:v1", "second:v1")
+	s.client.inUse[s.client.registry["first:v1"].id] = true
+
+	cache.Cleanup(s.ctx)
+	s.True(s.client.present("pinned:v1"))
+	s.True(s.client.present("first:v1"))
+	s.False(s.client.present("second:v1"))
+
+	// the image is removed once it is no longer used
+	delete(s.client.inUse, s.client.registry["first:v1"].id)
+	cache.Cleanup(s.ctx)
+	s.False(s.client.present("first:v1"))
+}
+
+func (s *ImageCacheTestSuite) TestCleanupWithoutBudgetKeepsImages() {
+	cache := s.newCache(types.DockerImageCache{}, "")
+	s.pull(cache, "first:v1", "second:v1")
+
+	cache.Cleanup(s.ctx)
+	s.True(s.client.present("first:v1"))
+	s.True(s.client.present("second:v1"))
+}
+
+func (s *ImageCacheTestSuite) TestPulledImagesAreTrackedAcrossRestarts() {
+	statePath := filepath.Join(s.T().TempDir(), "docker_images.json")
+	s.pull(s.newCache(types.DockerImageCache{}, statePath), "first:v1")
+
+	cache := s.newCache(types.DockerImageCache{MaxSize: "1B"}, statePath)
+	cache.Cleanup(s.ctx)
+	s.False(s.client.present("first:v1"))
+}
+
+func (s *ImageCacheTestSuite) TestPrePullAndCachedImages() {
+	cache := s.newCache(types.DockerImageCache{PrePull: []string{"first:v1", "second:v1"}}, "")
+	cache.Start(s.ctx)
+
+	s.Eventually(func() bool { return len(cache.CachedImages()) == 2 }, time.Second, 10*time.Millisecond)
+	s.ElementsMatch([]string{
+		"docker.io/library/first@sha256:" + fmt.Sprintf("%064d", 0),
+		"docker.io/library/second@sha256:" + fmt.Sprintf("%064d", 1),
+	}, cache.CachedImages())
+}
+
+func (s *ImageCacheTestSuite) TestWarmImages() {
+	cache := s.newCache(types.DockerImageCache{}, "")
+	cache.Start(s.ctx)
+
+	cache.WarmImages(s.ctx, []string{"first:v1", "denied:v1", "missing:v1"})
+	s.Eventually(func() bool { return s.client.present("first:v1") }, time.Second, 10*time.Millisecond)
+	s.False(s.client.present("denied:v1"), "hinted images are checked against the image policy")
+
+	// recently hinted images are not warmed again
+	s.client.mu.Lock()
+	delete(s.client.local, "first:v1")
+	s.client.mu.Unlock()
+	cache.WarmImages(s.ctx, []string{"first:v1", "second:v1"})
+	s.Eventually(func() bool { return s.client.present("second:v1") }, time.Second, 10*time.Millisecond)
+	s.False(s.client.present("first:v1"))
+	s.Equal([]string{"first:v1", "second:v1"}, s.client.pulled())
+}
+
+func (s *ImageCacheTestSuite) TestDisabledCache() {
+	cache := s.newCache(types.DockerImageCache{Disabled: true, MaxSize: "1B", PrePull: []string{"first:v1"}}, "")
+	cache.Start(s.ctx)
+	s.pull(cache, "second:v1")
+
+	cache.WarmImages(s.ctx, []string{"third:v1"})
+	cache.Cleanup(s.ctx)
+	s.True(s.client.present("second:v1"))
+	s.Empty(cache.CachedImages())
+	s.Equal([]string{"second:v1"}, s.client.pulled())
+}
+
+func (s *ImageCacheTestSuite) TestInvalidConfig() {
+	_, err := newImageCache(imageCacheParams{Config: types.DockerImageCache{MaxSize: "lots"}})
+	s.Error(err)
+	_, err = newImageCache(imageCacheParams{Config: types.DockerImageCache{PrePull: []string{"UPPER:case"}}})
+	s.Error(err)
+}
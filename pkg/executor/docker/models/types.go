@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/fatih/structs"
 
 	"github.com/bacalhau-project/bacalhau/pkg/models"
@@ -23,6 +24,38 @@ type EngineSpec struct {
 	EnvironmentVariables []string `json:"EnvironmentVariables,omitempty"`
 	// WorkingDirectory inside the container
 	WorkingDirectory string `json:"WorkingDirectory,omitempty"`
+	// User the container process runs as, in the form "user[:group]" where user and group are names or ids
+	User string `json:"User,omitempty" structs:",omitempty"`
+	// ReadOnlyRootfs mounts the root filesystem of the container as read only
+	ReadOnlyRootfs bool `json:"ReadOnlyRootfs,omitempty" structs:",omitempty"`
+	// ShmSize is the size of /dev/shm, e.g. "1Gi". Uses the docker default if empty.
+	ShmSize string `json:"ShmSize,omitempty" structs:",omitempty"`
+	// Tmpfs are in-memory filesystems mounted in the container
+	Tmpfs []TmpfsMount `json:"Tmpfs,omitempty" structs:",omitempty"`
+	// Ulimits are the resource limits of the container process, e.g. the maximum number of open files
+	Ulimits []Ulimit `json:"Ulimits,omitempty" structs:",omitempty"`
+	// CapAdd are the Linux capabilities added to the container, e.g. "SYS_PTRACE"
+	CapAdd []string `json:"CapAdd,omitempty" structs:",omitempty"`
+	// CapDrop are the Linux capabilities dropped from the container, e.g. "ALL"
+	CapDrop []string `json:"CapDrop,omitempty" structs:",omitempty"`
+}
+
+// TmpfsMount is an in-memory filesystem mounted in the container
+type TmpfsMount struct {
+	// Target is the absolute path of the mount inside the container
+	Target string `json:"Target"`
+	// Size is the maximum size of the mount, e.g. "512Mi". Uses the docker default if empty.
+	Size string `json:"Size,omitempty" structs:",omitempty"`
+}
+
+// Ulimit is a resource limit of the container process
+type Ulimit struct {
+	// Name of the limit, e.g. "nofile" or "memlock"
+	Name string `json:"Name"`
+	// Soft is the soft limit. -1 means unlimited.
+	Soft int64 `json:"Soft"`
+	// Hard is the hard limit. -1 means unlimited.
+	Hard int64 `json:"Hard"`
 }
 
 func (c EngineSpec) Validate() error {
@@ -49,7 +82,53 @@ func (c EngineSpec) Validate() error {
 			}
 		}
 	}
-	return nil
+	return c.validateContainerOptions()
+}
+
+// validateContainerOptions validates the options that configure the container beyond the command it runs
+func (c EngineSpec) validateContainerOptions() error {
+	var mErr error
+	if c.ShmSize != "" {
+		if _, err := humanize.ParseBytes(c.ShmSize); err != nil {
+			mErr = errors.Join(mErr, fmt.Errorf("invalid docker engine param: 'ShmSize' (%q) is not a valid size", c.ShmSize))
+		}
+	}
+	for _, tmpfs := range c.Tmpfs {
+		if !strings.HasPrefix(tmpfs.Target, "/") {
+			mErr = errors.Join(mErr, fmt.Errorf("invalid docker engine param: tmpfs target (%q) "+
+				"must contain absolute path", tmpfs.Target))
+		}
+		if tmpfs.Size != "" {
+			if _, err := humanize.ParseBytes(tmpfs.Size); err != nil {
+				mErr = errors.Join(mErr, fmt.Errorf("invalid docker engine param: tmpfs size (%q) is not a valid size", tmpfs.Size))
+			}
+		}
+	}
+	for _, ulimit := range c.Ulimits {
+		if ulimit.Name == "" {
+			mErr = errors.Join(mErr, errors.New("invalid docker engine param: ulimit name cannot be empty"))
+		}
+		if ulimit.Soft < -1 || ulimit.Hard < -1 {
+			mErr = errors.Join(mErr, fmt.Errorf("invalid docker engine param: ulimit %s cannot be negative", ulimit.Name))
+		}
+		if ulimit.Hard != -1 && (ulimit.Soft == -1 || ulimit.Soft > ulimit.Hard) {
+			mErr = errors.Join(mErr, fmt.Errorf(
+				"invalid docker engine param: ulimit %s soft limit cannot exceed its hard limit", ulimit.Name))
+		}
+	}
+	return mErr
+}
+
+// ShmSizeBytes returns the size of /dev/shm in bytes, or 0 if not set
+func (c EngineSpec) ShmSizeBytes() uint64 {
+	size, _ := humanize.ParseBytes(c.ShmSize)
+	return size
+}
+
+// SizeBytes returns the maximum size of the tmpfs mount in bytes, or 0 if not set
+func (t TmpfsMount) SizeBytes() uint64 {
+	size, _ := humanize.ParseBytes(t.Size)
+	return size
 }
 
 func (c EngineSpec) ToMap() map[string]interface{} {
@@ -122,6 +201,49 @@ func (b *DockerEngineBuilder) WithParameters(e ...string) *DockerEngineBuilder {
 	return b
 }
 
+// WithUser is a builder method that sets the user the container process runs as.
+// It returns the DockerEngineBuilder for further chaining of builder methods.
+func (b *DockerEngineBuilder) WithUser(e string) *DockerEngineBuilder {
+	b.spec.User = e
+	return b
+}
+
+// WithReadOnlyRootfs is a builder method that sets whether the root filesystem of the container is read only.
+// It returns the DockerEngineBuilder for further chaining of builder methods.
+func (b *DockerEngineBuilder) WithReadOnlyRootfs(e bool) *DockerEngineBuilder {
+	b.spec.ReadOnlyRootfs = e
+	return b
+}
+
+// WithShmSize is a builder method that sets the size of /dev/shm.
+// It returns the DockerEngineBuilder for further chaining of builder methods.
+func (b *DockerEngineBuilder) WithShmSize(e string) *DockerEngineBuilder {
+	b.spec.ShmSize = e
+	return b
+}
+
+// WithTmpfs is a builder method that sets the Docker engine's tmpfs mounts.
+// It returns the DockerEngineBuilder for further chaining of builder methods.
+func (b *DockerEngineBuilder) WithTmpfs(e ...TmpfsMount) *DockerEngineBuilder {
+	b.spec.Tmpfs = e
+	return b
+}
+
+// WithUlimits is a builder method that sets the Docker engine's ulimits.
+// It returns the DockerEngineBuilder for further chaining of builder methods.
+func (b *DockerEngineBuilder) WithUlimits(e ...Ulimit) *DockerEngineBuilder {
+	b.spec.Ulimits = e
+	return b
+}
+
+// WithCapabilities is a builder method that sets the Linux capabilities added to and dropped from the container.
+// It returns the DockerEngineBuilder for further chaining of builder methods.
+func (b *DockerEngineBuilder) WithCapabilities(add []string, drop []string) *DockerEngineBuilder {
+	b.spec.CapAdd = add
+	b.spec.CapDrop = drop
+	return b
+}
+
 // Build method constructs the final SpecConfig object by calling the embedded EngineBuilder's Build method.
 func (b *DockerEngineBuilder) Build() (*models.SpecConfig, error) {
 	if err := b.spec.Validate(); err != nil {
@@ -95,6 +95,28 @@ func TestDockerEngineBuilder_RoundTrip(t *testing.T) {
 				Parameters:           []string{"arg1", "arg2"},
 			},
 		},
+		{
+			name: "valid spec with container options",
+			builder: func() *DockerEngineBuilder {
+				return NewDockerEngineBuilder("myImage").
+					WithUser("1000:1000").
+					WithReadOnlyRootfs(true).
+					WithShmSize("1Gi").
+					WithTmpfs(TmpfsMount{Target: "/scratch", Size: "512Mi"}).
+					WithUlimits(Ulimit{Name: "nofile", Soft: 1024, Hard: 4096}).
+					WithCapabilities([]string{"SYS_PTRACE"}, []string{"ALL"})
+			},
+			expectedSpec: EngineSpec{
+				Image:          "myImage",
+				User:           "1000:1000",
+				ReadOnlyRootfs: true,
+				ShmSize:        "1Gi",
+				Tmpfs:          []TmpfsMount{{Target: "/scratch", Size: "512Mi"}},
+				Ulimits:        []Ulimit{{Name: "nofile", Soft: 1024, Hard: 4096}},
+				CapAdd:         []string{"SYS_PTRACE"},
+				CapDrop:        []string{"ALL"},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -179,6 +201,38 @@ func TestEngineSpec_Validate(t *testing.T) {
 			},
 			expectedErrorMsg: "",
 		},
+		{
+			name: "Invalid Shm Size",
+			engineSpec: EngineSpec{
+				Image:   "valid-image",
+				ShmSize: "lots",
+			},
+			expectedErrorMsg: "invalid docker engine param: 'ShmSize' (\"lots\") is not a valid size",
+		},
+		{
+			name: "Relative Tmpfs Target",
+			engineSpec: EngineSpec{
+				Image: "valid-image",
+				Tmpfs: []TmpfsMount{{Target: "scratch"}},
+			},
+			expectedErrorMsg: "invalid docker engine param: tmpfs target (\"scratch\") must contain absolute path",
+		},
+		{
+			name: "Ulimit Soft Above Hard",
+			engineSpec: EngineSpec{
+				Image:   "valid-image",
+				Ulimits: []Ulimit{{Name: "nofile", Soft: 2048, Hard: 1024}},
+			},
+			expectedErrorMsg: "invalid docker engine param: ulimit nofile soft limit cannot exceed its hard limit",
+		},
+		{
+			name: "Unlimited Ulimit",
+			engineSpec: EngineSpec{
+				Image:   "valid-image",
+				Ulimits: []Ulimit{{Name: "memlock", Soft: -1, Hard: -1}},
+			},
+			expectedErrorMsg: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types/mount"
+
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+)
+
+// makeOverlayMount mounts an input writable with a kernel overlay of its private writable layer over the
+// read-only input. The overlay is an anonymous volume of docker's local driver, which is removed with
+// the container while the writable layer stays in place to be published.
+func makeOverlayMount(volume storage.StorageVolume) (mount.Mount, error) {
+	layer := volume.Overlay
+	for _, dir := range []string{volume.Source, layer.UpperDir, layer.WorkDir} {
+		// the directories are passed as comma separated mount options, where colons separate lower dirs
+		if strings.ContainsAny(dir, ",:") {
+			return mount.Mount{}, fmt.Errorf("cannot mount input %s with an overlay as path %q contains ',' or ':'",
+				volume.Target, dir)
+		}
+	}
+	return mount.Mount{
+		Type:   mount.TypeVolume,
+		Target: volume.Target,
+		VolumeOptions: &mount.VolumeOptions{
+			DriverConfig: &mount.Driver{
+				Name: "local",
+				Options: map[string]string{
+					"type":   "overlay",
+					"device": "overlay",
+					"o": fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s",
+						volume.Source, layer.UpperDir, layer.WorkDir),
+				},
+			},
+		},
+	}, nil
+}
+
+// overlayUpperDirs returns the writable layers of the inputs mounted with an overlay
+func overlayUpperDirs(inputs []storage.PreparedStorage) []string {
+	var dirs []string
+	for _, input := range inputs {
+		if input.Volume.Overlay != nil {
+			dirs = append(dirs, input.Volume.Overlay.UpperDir)
+		}
+	}
+	return dirs
+}
+
+// removeOverlayWhiteouts removes the whiteouts that the kernel overlay creates in the writable layer for
+// files deleted from the input. Whiteouts are character devices that only make sense as part of the
+// overlay, and would otherwise be published along with the files written by the execution.
+func removeOverlayWhiteouts(upperDir string) error {
+	return filepath.WalkDir(upperDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.Type()&fs.ModeCharDevice != 0 {
+			return os.Remove(path)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,48 @@
+//go:build unit || !integration
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+)
+
+func TestMakeOverlayMount(t *testing.T) {
+	volume := storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: "/data/input",
+		Target: "/inputs",
+		Overlay: &storage.OverlayLayer{
+			UpperDir: "/exec/results/changes",
+			WorkDir:  "/exec/overlays/0/work",
+		},
+	}
+	overlayMount, err := makeOverlayMount(volume)
+	require.NoError(t, err)
+	assert.Equal(t, mount.TypeVolume, overlayMount.Type)
+	assert.Empty(t, overlayMount.Source, "overlay should be an anonymous volume removed with the container")
+	assert.Equal(t, "/inputs", overlayMount.Target)
+	assert.False(t, overlayMount.ReadOnly)
+	assert.Equal(t, map[string]string{
+		"type":   "overlay",
+		"device": "overlay",
+		"o":      "lowerdir=/data/input,upperdir=/exec/results/changes,workdir=/exec/overlays/0/work",
+	}, overlayMount.VolumeOptions.DriverConfig.Options)
+
+	volume.Source = "/data/a,b"
+	_, err = makeOverlayMount(volume)
+	require.ErrorContains(t, err, "contains ',' or ':'")
+}
+
+func TestOverlayUpperDirs(t *testing.T) {
+	inputs := []storage.PreparedStorage{
+		{Volume: storage.StorageVolume{Source: "/data/a"}},
+		{Volume: storage.StorageVolume{Source: "/data/b", Overlay: &storage.OverlayLayer{UpperDir: "/upper/b"}}},
+	}
+	assert.Equal(t, []string{"/upper/b"}, overlayUpperDirs(inputs))
+}
@@ -239,5 +239,17 @@ func (e *NoopExecutor) GetLogStream(ctx context.Context, request messages.Execut
 	return nil, fmt.Errorf("not implemented for NoopExecutor")
 }
 
+func (e *NoopExecutor) Exec(ctx context.Context, request *executor.ExecRequest) (executor.ExecSession, error) {
+	return nil, NewNoopExecutorError(executor.ExecNotSupported, "exec is not supported by the noop executor")
+}
+
+func (e *NoopExecutor) Attach(ctx context.Context, request *executor.ExecRequest) (executor.ExecSession, error) {
+	return nil, NewNoopExecutorError(executor.ExecNotSupported, "attach is not supported by the noop executor")
+}
+
+func (e *NoopExecutor) Checkpoint(ctx context.Context, request *executor.CheckpointRequest) error {
+	return NewNoopExecutorError(executor.CheckpointNotSupported, "checkpoints are not supported by the noop executor")
+}
+
 // Compile-time check that Executor implements the Executor interface.
 var _ executor.Executor = (*NoopExecutor)(nil)
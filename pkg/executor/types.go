@@ -3,6 +3,7 @@ package executor
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
 	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
@@ -53,6 +54,35 @@ type Executor interface {
 	// Returns an io.ReadCloser to read the output stream and an error if the operation fails.
 	// Specifically, it will return an error if the execution does not exist.
 	GetLogStream(ctx context.Context, request messages.ExecutionLogsRequest) (io.ReadCloser, error)
+
+	// Exec starts an interactive command inside a running execution, such as a shell in the
+	// execution's container, and returns a session connected to the command's standard streams.
+	// Returns an error if the execution does not exist or is no longer running.
+	// Executors that cannot run commands inside their executions return an ExecNotSupported error.
+	Exec(ctx context.Context, request *ExecRequest) (ExecSession, error)
+
+	// Attach connects to the standard streams of the main process of a running execution.
+	// The command of the request is ignored. Closing the session detaches from the process
+	// without stopping it.
+	Attach(ctx context.Context, request *ExecRequest) (ExecSession, error)
+
+	// Checkpoint signals a running execution to write a checkpoint of its state into its checkpoint directory.
+	// It returns once the signal is delivered, without waiting for the checkpoint to be written.
+	// Executors that cannot signal their executions return a CheckpointNotSupported error.
+	Checkpoint(ctx context.Context, request *CheckpointRequest) error
+}
+
+// ImageCache is implemented by executors that keep the images of their executions cached on the node,
+// such as the docker executor. The compute node advertises the cached images so that jobs are preferably
+// scheduled on nodes that already have their image, and warms the cache with the images hinted by the orchestrator.
+type ImageCache interface {
+	// CachedImages returns the digested references, e.g. "docker.io/library/ubuntu@sha256:...",
+	// of the images cached on the node.
+	CachedImages() []string
+
+	// WarmImages pulls the images into the cache in the background. It does not block,
+	// and images that cannot be pulled are skipped.
+	WarmImages(ctx context.Context, images []string)
 }
 
 // RunCommandRequest encapsulates the parameters required to initiate a job execution.
@@ -60,6 +90,7 @@ type Executor interface {
 type RunCommandRequest struct {
 	JobID        string                    // Unique identifier for the job.
 	ExecutionID  string                    // Unique identifier for a specific execution of the job.
+	Namespace    string                    // Namespace of the job.
 	Resources    *models.Resources         // Resource requirements like CPU, Memory, GPU, Disk.
 	Network      *models.NetworkConfig     // Network configuration for the execution.
 	Outputs      []*models.ResultPath      // Paths where the execution should store its outputs.
@@ -68,6 +99,49 @@ type RunCommandRequest struct {
 	EngineParams *models.SpecConfig        // Engine-specific configuration parameters.
 	Env          map[string]string         // System defined and task level environment variables.
 	OutputLimits OutputLimits              // Output size limits for the execution.
+	// Directory where the execution writes its checkpoints. Empty if checkpoints are disabled.
+	CheckpointDir string
+	// Persistent volumes of the task, mounted read-write into the execution.
+	Volumes []storage.StorageVolume
+	// Signal sent to the execution to ask it to terminate gracefully when it is cancelled, such as SIGTERM.
+	StopSignal string
+	// Grace period the execution has to terminate after the stop signal before it is killed.
+	// Zero means the execution is killed immediately.
+	KillTimeout time.Duration
+}
+
+// CheckpointRequest encapsulates the parameters to ask a running execution to checkpoint its state.
+type CheckpointRequest struct {
+	ExecutionID string // Identifier of the running execution.
+	Signal      string // Name of the signal to send to the execution, such as SIGUSR1.
+}
+
+// ExecRequest encapsulates the parameters of an interactive session into a running execution.
+type ExecRequest struct {
+	ExecutionID string   // Identifier of the running execution.
+	Command     []string // Command and arguments to run. Ignored when attaching.
+	TTY         bool     // Allocate a pseudo terminal. Stdout and stderr are merged when set.
+	Stdin       bool     // Attach the standard input of the command.
+	Height      uint     // Initial height of the terminal in rows, if TTY is set.
+	Width       uint     // Initial width of the terminal in columns, if TTY is set.
+}
+
+// ExecSession is an interactive session into a running execution, returned by Exec and Attach.
+type ExecSession interface {
+	// Stdin returns the writer of the session's standard input, or nil if stdin was not requested.
+	Stdin() io.WriteCloser
+	// Stdout returns the reader of the session's standard output.
+	// When a TTY was requested, it also carries the standard error.
+	Stdout() io.Reader
+	// Stderr returns the reader of the session's standard error, or nil if a TTY was requested.
+	Stderr() io.Reader
+	// Resize changes the size of the session's terminal. It is a no-op without a TTY.
+	Resize(ctx context.Context, height, width uint) error
+	// Wait blocks until the output of the session is drained and returns the exit code of the command.
+	// The exit code is -1 if the session ended while the process keeps running, such as after detaching.
+	Wait(ctx context.Context) (int, error)
+	// Close ends the session and releases its resources.
+	Close() error
 }
 
 // Common Error Codes for Executor
@@ -77,6 +151,9 @@ const (
 	ExecutionAlreadyComplete  bacerrors.ErrorCode = "ExecutionAlreadyComplete"
 	ExecutionNotFound         bacerrors.ErrorCode = "ExecutionNotFound"
 	ExecutorSpecValidationErr bacerrors.ErrorCode = "ExecutorSpecValidationErr"
+	ExecutionNotRunning       bacerrors.ErrorCode = "ExecutionNotRunning"
+	ExecNotSupported          bacerrors.ErrorCode = "ExecNotSupported"
+	CheckpointNotSupported    bacerrors.ErrorCode = "CheckpointNotSupported"
 )
 
 func NewExecutorError(code bacerrors.ErrorCode, message string) bacerrors.Error {
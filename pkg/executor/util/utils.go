@@ -9,15 +9,22 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/executor/docker"
 	noop_executor "github.com/bacalhau-project/bacalhau/pkg/executor/noop"
 	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/funcs/kv"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/funcs/objectstore"
 	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/blobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/provider"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
 	s3helper "github.com/bacalhau-project/bacalhau/pkg/s3"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/blob"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/git"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/inline"
 	ipfs_storage "github.com/bacalhau-project/bacalhau/pkg/storage/ipfs"
 	local_storage "github.com/bacalhau-project/bacalhau/pkg/storage/local"
 	noop_storage "github.com/bacalhau-project/bacalhau/pkg/storage/noop"
+	oci_storage "github.com/bacalhau-project/bacalhau/pkg/storage/oci"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/s3"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/tracing"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/url/urldownload"
@@ -32,15 +39,25 @@ type StandardStorageProviderOptions struct {
 
 type StandardExecutorOptions struct {
 	DockerID string
+	// DockerImageCacheFile records the docker images pulled by the node. The images are only tracked in memory if empty.
+	DockerImageCacheFile string
+	// WASMCacheDir is the directory of the compiled WASM module cache. The cache is disabled if empty.
+	WASMCacheDir string
+	// WASMKeyValueStore backs the key-value store of WASM jobs. The store is not available to jobs if nil.
+	WASMKeyValueStore kv.Store
 }
 
-func NewStandardStorageProvider(cfg types.Bacalhau) (storage.StorageProvider, error) {
+// NewStandardStorageProvider creates the storage providers enabled by the config.
+// Inputs uploaded to the orchestrator are not supported if blobStore is nil.
+func NewStandardStorageProvider(
+	cfg types.Bacalhau, secretResolver git.SecretResolver, blobStore blobstore.Store) (storage.StorageProvider, error) {
 	providers := make(map[string]storage.Storage)
 
 	if cfg.InputSources.IsNotDisabled(models.StorageSourceURL) {
 		providers[models.StorageSourceURL] = tracing.Wrap(urldownload.NewStorage(
 			time.Duration(cfg.InputSources.ReadTimeout),
 			cfg.InputSources.MaxRetryCount,
+			secretResolver,
 		))
 	}
 
@@ -48,6 +65,12 @@ func NewStandardStorageProvider(cfg types.Bacalhau) (storage.StorageProvider, er
 		providers[models.StorageSourceInline] = tracing.Wrap(inline.NewStorage())
 	}
 
+	if cfg.InputSources.IsNotDisabled(models.StorageSourceBlob) && blobStore != nil {
+		providers[models.StorageSourceBlob] = tracing.Wrap(blob.NewStorage(blob.StorageProviderParams{
+			Store: blobStore,
+		}))
+	}
+
 	if cfg.InputSources.IsNotDisabled(models.StorageSourceS3) {
 		s3Cfg, err := s3helper.DefaultAWSConfig()
 		if err != nil {
@@ -62,6 +85,31 @@ func NewStandardStorageProvider(cfg types.Bacalhau) (storage.StorageProvider, er
 		))
 	}
 
+	if cfg.InputSources.IsNotDisabled(models.StorageSourceOCI) {
+		providers[models.StorageSourceOCI] = tracing.Wrap(oci_storage.NewStorage(oci_storage.StorageProviderParams{
+			Client: oci.NewClient(oci.ClientParams{
+				Credentials:        oci.GetCredentials(),
+				InsecureRegistries: cfg.InputSources.Types.OCI.InsecureRegistries,
+			}),
+			Timeout: time.Duration(cfg.InputSources.ReadTimeout),
+		}))
+	}
+
+	if cfg.InputSources.IsNotDisabled(models.StorageSourceGit) {
+		mirrorDir, err := cfg.GitMirrorDir()
+		if err != nil {
+			return nil, err
+		}
+		gitStorage, err := git.NewStorage(git.StorageProviderParams{
+			MirrorDir:      mirrorDir,
+			SecretResolver: secretResolver,
+		})
+		if err != nil {
+			return nil, err
+		}
+		providers[models.StorageSourceGit] = tracing.Wrap(gitStorage)
+	}
+
 	if cfg.InputSources.IsNotDisabled(models.StorageSourceLocal) {
 		localStorage, err := local_storage.NewStorageProvider(
 			local_storage.StorageProviderParams{
@@ -111,8 +159,9 @@ func NewStandardExecutorProvider(
 	if cfg.IsNotDisabled(models.EngineDocker) {
 		var err error
 		providers[models.EngineDocker], err = docker.NewExecutor(docker.ExecutorParams{
-			ID:     executorOptions.DockerID,
-			Config: cfg.Types.Docker,
+			ID:             executorOptions.DockerID,
+			Config:         cfg.Types.Docker,
+			ImageCacheFile: executorOptions.DockerImageCacheFile,
 		})
 		if err != nil {
 			return nil, err
@@ -120,7 +169,22 @@ func NewStandardExecutorProvider(
 	}
 
 	if cfg.IsNotDisabled(models.EngineWasm) {
-		wasmExecutor, err := wasm.NewExecutor()
+		var objectStoreClient objectstore.Client
+		if len(cfg.Types.WASM.ObjectStore.Buckets) > 0 {
+			s3Cfg, err := s3helper.DefaultAWSConfig()
+			if err != nil {
+				return nil, err
+			}
+			objectStoreClient = objectstore.NewS3Client(s3helper.NewClientProvider(s3helper.ClientProviderParams{
+				AWSConfig: s3Cfg,
+			}))
+		}
+		wasmExecutor, err := wasm.NewExecutor(wasm.ExecutorParams{
+			Config:            cfg.Types.WASM,
+			CacheDir:          executorOptions.WASMCacheDir,
+			KeyValueStore:     executorOptions.WASMKeyValueStore,
+			ObjectStoreClient: objectStoreClient,
+		})
 		if err != nil {
 			return nil, err
 		}
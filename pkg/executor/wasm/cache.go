@@ -0,0 +1,299 @@
+package wasm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tetratelabs/wazero"
+)
+
+const (
+	// moduleCacheIndexFile tracks the cache files and last use of each compiled module
+	moduleCacheIndexFile = "index.json"
+	// wazeroCacheDirPrefix is the prefix of the version specific directory wazero writes compiled modules into
+	wazeroCacheDirPrefix = "wazero-"
+	// staleTempFileAge is the age after which an incomplete cache file is considered abandoned
+	staleTempFileAge = time.Hour
+)
+
+// moduleCompiler compiles WASM modules. It is implemented by wazero.Runtime.
+type moduleCompiler interface {
+	CompileModule(ctx context.Context, binary []byte) (wazero.CompiledModule, error)
+}
+
+// moduleCacheEntry tracks the files wazero wrote to the cache when compiling a module
+type moduleCacheEntry struct {
+	Files    []string  `json:"Files"`
+	Size     int64     `json:"Size"`
+	LastUsed time.Time `json:"LastUsed"`
+}
+
+// ModuleCache is a node-wide on-disk cache of compiled WASM modules, shared by all WASM executions.
+//
+// Compilation is delegated to wazero's file based CompilationCache, which keys entries by the module content
+// and the wazero version. ModuleCache wraps it to keep the cache within a maximum size: it attributes the files
+// wazero writes to the module that was compiled, and evicts the least recently used modules once the cache grows
+// past its limit. Cache misses are compiled one at a time so that new files can be attributed to their module,
+// while cache hits are compiled concurrently.
+type ModuleCache struct {
+	dir      string
+	cacheDir string
+	maxSize  int64
+	cache    wazero.CompilationCache
+
+	// compileMu serializes cache misses
+	compileMu sync.Mutex
+	// mu guards the index
+	mu    sync.Mutex
+	index map[string]*moduleCacheEntry
+}
+
+// NewModuleCache creates a compiled module cache in dir that holds at most maxSize bytes.
+// A maxSize of zero means the cache is not limited in size.
+func NewModuleCache(dir string, maxSize uint64) (*ModuleCache, error) {
+	if maxSize > uint64(1<<63-1) {
+		return nil, fmt.Errorf("wasm module cache size %d exceeds maximum allowed integer value", maxSize)
+	}
+	cache, err := wazero.NewCompilationCacheWithDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("creating wasm module cache in %s: %w", dir, err)
+	}
+
+	c := &ModuleCache{
+		dir:      dir,
+		cacheDir: filepath.Join(dir, wazeroCacheDirName()),
+		maxSize:  int64(maxSize),
+		cache:    cache,
+		index:    make(map[string]*moduleCacheEntry),
+	}
+	if _, err = os.Stat(c.cacheDir); err == nil {
+		c.removeStaleVersions()
+	} else {
+		log.Warn().Err(err).Msg("unable to locate wasm module cache directory. cache size will not be limited")
+	}
+	c.loadIndex()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict(context.Background())
+	return c, nil
+}
+
+// CompilationCache returns the wazero compilation cache to configure runtimes with
+func (c *ModuleCache) CompilationCache() wazero.CompilationCache {
+	return c.cache
+}
+
+// Compile compiles the module with the given compiler, which must be a runtime configured with the
+// cache's CompilationCache, and records the use of the module in the cache.
+func (c *ModuleCache) Compile(ctx context.Context, compiler moduleCompiler, binary []byte) (wazero.CompiledModule, error) {
+	sum := sha256.Sum256(binary)
+	key := hex.EncodeToString(sum[:])
+
+	if c.touch(key) {
+		ModuleCacheHits.Inc(ctx)
+		return compiler.CompileModule(ctx, binary)
+	}
+
+	c.compileMu.Lock()
+	defer c.compileMu.Unlock()
+
+	// the module might have been compiled while we were waiting
+	if c.touch(key) {
+		ModuleCacheHits.Inc(ctx)
+		return compiler.CompileModule(ctx, binary)
+	}
+
+	ModuleCacheMisses.Inc(ctx)
+	before := c.listFiles()
+	module, err := compiler.CompileModule(ctx, binary)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &moduleCacheEntry{LastUsed: time.Now().UTC()}
+	for name, info := range c.listFiles() {
+		if _, ok := before[name]; !ok && !isTempFile(name) {
+			entry.Files = append(entry.Files, name)
+			entry.Size += info.Size()
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.index[key] = entry
+	c.evict(ctx)
+	c.saveIndex(ctx)
+	return module, nil
+}
+
+// Close closes the underlying compilation cache
+func (c *ModuleCache) Close(ctx context.Context) error {
+	return c.cache.Close(ctx)
+}
+
+// touch marks the module as used and returns true if it is cached
+func (c *ModuleCache) touch(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.index[key]
+	if !ok {
+		return false
+	}
+	// the files might have been removed from outside the cache, in which case the module has to be recompiled
+	if len(entry.Files) == 0 || !c.filesExist(entry.Files) {
+		delete(c.index, key)
+		return false
+	}
+	entry.LastUsed = time.Now().UTC()
+	return true
+}
+
+func (c *ModuleCache) filesExist(files []string) bool {
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(c.cacheDir, file)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// evict removes the least recently used modules until the cache is within its maximum size,
+// as well as files that do not belong to any cached module. It must be called with mu held.
+func (c *ModuleCache) evict(ctx context.Context) {
+	owned := make(map[string]bool)
+	var size int64
+	for _, entry := range c.index {
+		size += entry.Size
+		for _, file := range entry.Files {
+			owned[file] = true
+		}
+	}
+	for name, info := range c.listFiles() {
+		if owned[name] || (isTempFile(name) && time.Since(info.ModTime()) < staleTempFileAge) {
+			continue
+		}
+		c.removeFile(ctx, name)
+	}
+
+	if c.maxSize <= 0 || size <= c.maxSize {
+		return
+	}
+	keys := make([]string, 0, len(c.index))
+	for key := range c.index {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.index[keys[i]].LastUsed.Before(c.index[keys[j]].LastUsed)
+	})
+	for _, key := range keys {
+		if size <= c.maxSize {
+			break
+		}
+		entry := c.index[key]
+		for _, file := range entry.Files {
+			c.removeFile(ctx, file)
+		}
+		size -= entry.Size
+		delete(c.index, key)
+		ModuleCacheEvictions.Inc(ctx)
+	}
+}
+
+func (c *ModuleCache) removeFile(ctx context.Context, name string) {
+	if err := os.Remove(filepath.Join(c.cacheDir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Ctx(ctx).Warn().Err(err).Str("file", name).Msg("failed to remove wasm module cache file")
+	}
+}
+
+// listFiles returns the files in the version specific cache directory
+func (c *ModuleCache) listFiles() map[string]os.FileInfo {
+	files := make(map[string]os.FileInfo)
+	entries, err := os.ReadDir(c.cacheDir)
+	if err != nil {
+		return files
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			files[entry.Name()] = info
+		}
+	}
+	return files
+}
+
+// removeStaleVersions removes the modules compiled by other versions of wazero, as they can never be used again
+func (c *ModuleCache) removeStaleVersions() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	current := filepath.Base(c.cacheDir)
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), wazeroCacheDirPrefix) && entry.Name() != current {
+			if err = os.RemoveAll(filepath.Join(c.dir, entry.Name())); err != nil {
+				log.Warn().Err(err).Str("dir", entry.Name()).Msg("failed to remove stale wasm module cache")
+			}
+		}
+	}
+}
+
+func (c *ModuleCache) loadIndex() {
+	data, err := os.ReadFile(filepath.Join(c.dir, moduleCacheIndexFile))
+	if err != nil {
+		return
+	}
+	index := make(map[string]*moduleCacheEntry)
+	if err = json.Unmarshal(data, &index); err != nil {
+		log.Warn().Err(err).Msg("ignoring corrupted wasm module cache index")
+		return
+	}
+	c.index = index
+}
+
+// saveIndex persists the index so that the cache survives restarts. It must be called with mu held.
+func (c *ModuleCache) saveIndex(ctx context.Context) {
+	data, err := json.Marshal(c.index)
+	if err == nil {
+		path := filepath.Join(c.dir, moduleCacheIndexFile)
+		if err = os.WriteFile(path+".tmp", data, 0o600); err == nil {
+			err = os.Rename(path+".tmp", path)
+		}
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to save wasm module cache index")
+	}
+}
+
+func isTempFile(name string) bool {
+	return strings.HasSuffix(name, ".tmp")
+}
+
+// wazeroCacheDirName returns the name of the directory wazero writes compiled modules into, which is
+// specific to the wazero version and platform.
+func wazeroCacheDirName() string {
+	version := "dev"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if strings.Contains(dep.Path, "github.com/tetratelabs/wazero") && dep.Version != "" && dep.Version != "(devel)" {
+				version = dep.Version
+			}
+		}
+	}
+	return wazeroCacheDirPrefix + version + "-" + runtime.GOARCH + "-" + runtime.GOOS
+}
@@ -0,0 +1,128 @@
+//go:build unit || !integration
+
+package wasm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tetratelabs/wazero"
+
+	"github.com/bacalhau-project/bacalhau/testdata/wasm/easter"
+	"github.com/bacalhau-project/bacalhau/testdata/wasm/noop"
+)
+
+type ModuleCacheTestSuite struct {
+	suite.Suite
+	ctx context.Context
+	dir string
+}
+
+func TestModuleCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(ModuleCacheTestSuite))
+}
+
+func (s *ModuleCacheTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.dir = s.T().TempDir()
+}
+
+func (s *ModuleCacheTestSuite) newCache(maxSize uint64) *ModuleCache {
+	cache, err := NewModuleCache(s.dir, maxSize)
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = cache.Close(s.ctx) })
+	return cache
+}
+
+// compile compiles the module with a new runtime, as every execution does
+func (s *ModuleCacheTestSuite) compile(cache *ModuleCache, binary []byte) {
+	runtime := wazero.NewRuntimeWithConfig(s.ctx, wazero.NewRuntimeConfig().WithCompilationCache(cache.CompilationCache()))
+	defer func() { _ = runtime.Close(s.ctx) }()
+	module, err := cache.Compile(s.ctx, runtime, binary)
+	s.Require().NoError(err)
+	s.Require().NoError(module.Close(s.ctx))
+}
+
+func moduleKey(binary []byte) string {
+	sum := sha256.Sum256(binary)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *ModuleCacheTestSuite) TestCompileCachesModule() {
+	cache := s.newCache(0)
+
+	s.compile(cache, noop.Program())
+	entry, ok := cache.index[moduleKey(noop.Program())]
+	s.Require().True(ok)
+	s.NotEmpty(entry.Files)
+	s.Positive(entry.Size)
+	files := cache.listFiles()
+
+	// compiling the same module again reuses the cached files
+	s.compile(cache, noop.Program())
+	s.Len(cache.listFiles(), len(files))
+	s.Len(cache.index, 1)
+}
+
+func (s *ModuleCacheTestSuite) TestCachePersistsAcrossRestarts() {
+	cache := s.newCache(0)
+	s.compile(cache, noop.Program())
+	s.Require().NoError(cache.Close(s.ctx))
+
+	restarted := s.newCache(0)
+	s.True(restarted.touch(moduleKey(noop.Program())))
+}
+
+func (s *ModuleCacheTestSuite) TestRemovedFilesAreRecompiled() {
+	cache := s.newCache(0)
+	s.compile(cache, noop.Program())
+	key := moduleKey(noop.Program())
+	for _, file := range cache.index[key].Files {
+		s.Require().NoError(os.Remove(filepath.Join(cache.cacheDir, file)))
+	}
+
+	s.False(cache.touch(key))
+	s.compile(cache, noop.Program())
+	s.True(cache.touch(key))
+}
+
+func (s *ModuleCacheTestSuite) TestEvictsLeastRecentlyUsedModules() {
+	cache := s.newCache(0)
+	noopKey, easterKey := moduleKey(noop.Program()), moduleKey(easter.Program())
+	s.compile(cache, noop.Program())
+	s.compile(cache, easter.Program())
+	noopFiles := cache.index[noopKey].Files
+
+	// limit the cache to hold either module, but not both
+	cache.mu.Lock()
+	cache.maxSize = max(cache.index[noopKey].Size, cache.index[easterKey].Size)
+	cache.evict(s.ctx)
+	cache.mu.Unlock()
+
+	s.NotContains(cache.index, noopKey)
+	s.Contains(cache.index, easterKey)
+	for _, file := range noopFiles {
+		s.NoFileExists(filepath.Join(cache.cacheDir, file))
+	}
+
+	// using the evicted module again evicts the other one
+	s.compile(cache, noop.Program())
+	s.Contains(cache.index, noopKey)
+	s.NotContains(cache.index, easterKey)
+}
+
+func (s *ModuleCacheTestSuite) TestRemovesStaleVersions() {
+	stale := filepath.Join(s.dir, wazeroCacheDirPrefix+"v0.0.1-"+"amd64-linux")
+	cache := s.newCache(0)
+	s.compile(cache, noop.Program())
+	s.Require().NoError(os.MkdirAll(stale, 0o700))
+
+	s.newCache(0)
+	s.NoDirExists(stale)
+	s.DirExists(cache.cacheDir)
+}
@@ -0,0 +1,621 @@
+package component
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf8"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// callContext implements the canonical ABI, which passes component values to and from core functions
+// through their flat parameters and results, and their linear memory.
+type callContext struct {
+	ctx     context.Context
+	memory  api.Memory
+	realloc api.Function
+	table   *handleTable
+}
+
+var errOutOfBounds = errors.New("memory access out of bounds")
+
+// flatValues iterates over the flat core values of component values
+type flatValues struct {
+	values []uint64
+	pos    int
+}
+
+func (f *flatValues) next() (uint64, error) {
+	if f.pos >= len(f.values) {
+		return 0, errors.New("missing core value")
+	}
+	v := f.values[f.pos]
+	f.pos++
+	return v, nil
+}
+
+// liftFlat lifts a value of the type from flat core values
+//
+//nolint:gocyclo,funlen // one case per kind of type
+func (c *callContext) liftFlat(t *Type, f *flatValues) (any, error) {
+	switch t.Kind {
+	case KindString, KindList:
+		ptr, err := f.next()
+		if err != nil {
+			return nil, err
+		}
+		length, err := f.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.Kind == KindString {
+			return c.loadString(uint32(ptr), uint32(length))
+		}
+		return c.loadList(t.Elem, uint32(ptr), uint32(length))
+	case KindRecord, KindTuple:
+		fields := make([]any, len(t.Fields))
+		for i, field := range t.Fields {
+			value, err := c.liftFlat(field.Type, f)
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = value
+		}
+		return fields, nil
+	case KindVariant, KindEnum, KindOption, KindResult:
+		disc, err := f.next()
+		if err != nil {
+			return nil, err
+		}
+		if disc >= uint64(len(t.Cases)) {
+			return nil, fmt.Errorf("invalid case %d of %s", disc, t)
+		}
+		payload := make([]uint64, len(flatten(t))-1)
+		for i := range payload {
+			if payload[i], err = f.next(); err != nil {
+				return nil, err
+			}
+		}
+		return c.liftCase(t, uint32(disc), &flatValues{values: payload})
+	case KindFlags:
+		var flags uint64
+		for i := range flatten(t) {
+			word, err := f.next()
+			if err != nil {
+				return nil, err
+			}
+			flags |= uint64(uint32(word)) << (32 * i)
+		}
+		return flags, nil
+	default:
+		v, err := f.next()
+		if err != nil {
+			return nil, err
+		}
+		return c.liftScalar(t, v)
+	}
+}
+
+// liftCase lifts the value of the case of a variant from its flat payload
+func (c *callContext) liftCase(t *Type, disc uint32, payload *flatValues) (any, error) {
+	if t.Kind == KindEnum {
+		return disc, nil
+	}
+	caseType := t.Cases[disc].Type
+	if caseType == nil {
+		return Variant{Case: disc}, nil
+	}
+	// payloads are joined into wider core types, which only keep the low bits of 32-bit values
+	for i, flat := range flatten(caseType) {
+		if flat == api.ValueTypeI32 || flat == api.ValueTypeF32 {
+			payload.values[i] = uint64(uint32(payload.values[i]))
+		}
+	}
+	value, err := c.liftFlat(caseType, payload)
+	if err != nil {
+		return nil, err
+	}
+	return Variant{Case: disc, Value: value}, nil
+}
+
+// liftScalar lifts a value that is passed as a single core value
+func (c *callContext) liftScalar(t *Type, v uint64) (any, error) {
+	switch t.Kind {
+	case KindBool:
+		return uint32(v) != 0, nil
+	case KindS8:
+		return int8(v), nil //nolint:gosec // G115: wrapping to the type's width is intended
+	case KindU8:
+		return uint8(v), nil //nolint:gosec // G115: wrapping to the type's width is intended
+	case KindS16:
+		return int16(v), nil //nolint:gosec // G115: wrapping to the type's width is intended
+	case KindU16:
+		return uint16(v), nil //nolint:gosec // G115: wrapping to the type's width is intended
+	case KindS32:
+		return int32(v), nil //nolint:gosec // G115: wrapping to the type's width is intended
+	case KindU32:
+		return uint32(v), nil //nolint:gosec // G115: wrapping to the type's width is intended
+	case KindS64:
+		return int64(v), nil //nolint:gosec // G115: two's complement conversion is intended
+	case KindU64:
+		return v, nil
+	case KindF32:
+		return math.Float32frombits(uint32(v)), nil //nolint:gosec // G115: f32 values are held in the low bits
+	case KindF64:
+		return math.Float64frombits(v), nil
+	case KindChar:
+		r := rune(uint32(v)) //nolint:gosec // G115: chars are held in the low bits
+		if !utf8.ValidRune(r) {
+			return nil, fmt.Errorf("invalid char 0x%x", uint32(v))
+		}
+		return r, nil
+	case KindOwn:
+		return c.table.remove(t.Resource, uint32(v)) //nolint:gosec // G115: handles are held in the low bits
+	case KindBorrow:
+		return c.table.get(t.Resource, uint32(v)) //nolint:gosec // G115: handles are held in the low bits
+	default:
+		return nil, fmt.Errorf("unexpected scalar type %s", t)
+	}
+}
+
+// lowerFlat lowers a value of the type into flat core values
+//
+//nolint:gocyclo // one case per kind of type
+func (c *callContext) lowerFlat(t *Type, value any, flat []uint64) ([]uint64, error) {
+	switch t.Kind {
+	case KindString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", value)
+		}
+		ptr, err := c.storeString(s)
+		return append(flat, uint64(ptr), uint64(len(s))), err
+	case KindList:
+		ptr, length, err := c.storeList(t.Elem, value)
+		return append(flat, uint64(ptr), uint64(length)), err
+	case KindRecord, KindTuple:
+		fields, err := recordFields(t, value)
+		if err != nil {
+			return nil, err
+		}
+		for i, field := range t.Fields {
+			if flat, err = c.lowerFlat(field.Type, fields[i], flat); err != nil {
+				return nil, err
+			}
+		}
+		return flat, nil
+	case KindVariant, KindEnum, KindOption, KindResult:
+		disc, payload, err := variantCase(t, value)
+		if err != nil {
+			return nil, err
+		}
+		flat = append(flat, uint64(disc))
+		start := len(flat)
+		if caseType := t.Cases[disc].Type; caseType != nil {
+			if flat, err = c.lowerFlat(caseType, payload, flat); err != nil {
+				return nil, err
+			}
+		}
+		// pad the payload to the joined payload of all the cases
+		for len(flat)-start < len(flatten(t))-1 {
+			flat = append(flat, 0)
+		}
+		return flat, nil
+	case KindFlags:
+		flags, err := Unsigned(value)
+		if err != nil {
+			return nil, err
+		}
+		for i := range flatten(t) {
+			flat = append(flat, uint64(uint32(flags>>(32*i))))
+		}
+		return flat, nil
+	default:
+		v, err := c.lowerScalar(t, value)
+		return append(flat, v), err
+	}
+}
+
+// lowerScalar lowers a value that is passed as a single core value
+func (c *callContext) lowerScalar(t *Type, value any) (uint64, error) {
+	switch t.Kind {
+	case KindBool:
+		b, ok := value.(bool)
+		if !ok {
+			return 0, fmt.Errorf("expected bool, got %T", value)
+		}
+		if b {
+			return 1, nil
+		}
+		return 0, nil
+	case KindS8, KindU8, KindS16, KindU16, KindS32, KindU32, KindChar:
+		v, err := Unsigned(value)
+		return uint64(uint32(v)), err
+	case KindS64, KindU64:
+		return Unsigned(value)
+	case KindF32:
+		f, ok := value.(float32)
+		if !ok {
+			return 0, fmt.Errorf("expected float32, got %T", value)
+		}
+		return uint64(math.Float32bits(f)), nil
+	case KindF64:
+		f, ok := value.(float64)
+		if !ok {
+			return 0, fmt.Errorf("expected float64, got %T", value)
+		}
+		return math.Float64bits(f), nil
+	case KindOwn, KindBorrow:
+		if t.Resource.host {
+			return uint64(c.table.add(t.Resource, value)), nil
+		}
+		rep, err := Unsigned(value)
+		return uint64(c.table.add(t.Resource, uint32(rep))), err //nolint:gosec // G115: guest representations are 32-bit
+	default:
+		return 0, fmt.Errorf("unexpected scalar type %s", t)
+	}
+}
+
+func recordFields(t *Type, value any) ([]any, error) {
+	fields, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected []any for %s, got %T", t, value)
+	}
+	if len(fields) != len(t.Fields) {
+		return nil, fmt.Errorf("expected %d fields for %s, got %d", len(t.Fields), t, len(fields))
+	}
+	return fields, nil
+}
+
+// variantCase returns the case and payload of a variant value
+func variantCase(t *Type, value any) (uint32, any, error) {
+	var disc uint32
+	var payload any
+	if t.Kind == KindEnum {
+		v, err := Unsigned(value)
+		if err != nil {
+			return 0, nil, err
+		}
+		disc = uint32(v) //nolint:gosec // G115: checked against the number of cases below
+	} else {
+		v, ok := value.(Variant)
+		if !ok {
+			return 0, nil, fmt.Errorf("expected Variant for %s, got %T", t, value)
+		}
+		disc, payload = v.Case, v.Value
+	}
+	if disc >= uint32(len(t.Cases)) { //nolint:gosec // G115: types have less than 2^32 cases
+		return 0, nil, fmt.Errorf("invalid case %d of %s", disc, t)
+	}
+	return disc, payload, nil
+}
+
+func (c *callContext) read(ptr, n uint32) ([]byte, error) {
+	if c.memory == nil {
+		return nil, errors.New("canonical option memory is required")
+	}
+	b, ok := c.memory.Read(ptr, n)
+	if !ok {
+		return nil, errOutOfBounds
+	}
+	return b, nil
+}
+
+func (c *callContext) loadString(ptr, length uint32) (string, error) {
+	b, err := c.read(ptr, length)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (c *callContext) loadList(elem *Type, ptr, length uint32) (any, error) {
+	elemSize := size(elem)
+	if uint64(length)*uint64(elemSize) > math.MaxUint32 {
+		return nil, errOutOfBounds
+	}
+	b, err := c.read(ptr, length*elemSize)
+	if err != nil {
+		return nil, err
+	}
+	if elem.Kind == KindU8 {
+		return append([]byte(nil), b...), nil
+	}
+	values := make([]any, length)
+	for i := range values {
+		if values[i], err = c.load(elem, ptr+uint32(i)*elemSize); err != nil { //nolint:gosec // G115: bounds checked above
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// load loads a value of the type from linear memory
+//
+//nolint:gocyclo // one case per kind of type
+func (c *callContext) load(t *Type, ptr uint32) (any, error) {
+	b, err := c.read(ptr, size(t))
+	if err != nil {
+		return nil, err
+	}
+	switch t.Kind {
+	case KindBool, KindS8, KindU8:
+		return c.liftScalar(t, uint64(b[0]))
+	case KindS16, KindU16:
+		return c.liftScalar(t, uint64(binary.LittleEndian.Uint16(b)))
+	case KindS32, KindU32, KindF32, KindChar, KindOwn, KindBorrow:
+		return c.liftScalar(t, uint64(binary.LittleEndian.Uint32(b)))
+	case KindS64, KindU64, KindF64:
+		return c.liftScalar(t, binary.LittleEndian.Uint64(b))
+	case KindString, KindList:
+		p, length := binary.LittleEndian.Uint32(b), binary.LittleEndian.Uint32(b[4:])
+		if t.Kind == KindString {
+			return c.loadString(p, length)
+		}
+		return c.loadList(t.Elem, p, length)
+	case KindRecord, KindTuple:
+		fields := make([]any, len(t.Fields))
+		var offset uint32
+		for i, field := range t.Fields {
+			offset = alignTo(offset, alignment(field.Type))
+			if fields[i], err = c.load(field.Type, ptr+offset); err != nil {
+				return nil, err
+			}
+			offset += size(field.Type)
+		}
+		return fields, nil
+	case KindFlags:
+		var flags uint64
+		if len(t.Labels) <= 16 {
+			for i := range flagsSize(len(t.Labels)) {
+				flags |= uint64(b[i]) << (8 * i)
+			}
+			return flags, nil
+		}
+		for i := range uint32(len(b) / 4) { //nolint:gosec // G115: flags are at most 8 bytes
+			flags |= uint64(binary.LittleEndian.Uint32(b[4*i:])) << (32 * i)
+		}
+		return flags, nil
+	default:
+		discSize := discriminantSize(len(t.Cases))
+		var disc uint32
+		for i := range discSize {
+			disc |= uint32(b[i]) << (8 * i)
+		}
+		if disc >= uint32(len(t.Cases)) { //nolint:gosec // G115: types have less than 2^32 cases
+			return nil, fmt.Errorf("invalid case %d of %s", disc, t)
+		}
+		if t.Kind == KindEnum {
+			return disc, nil
+		}
+		caseType := t.Cases[disc].Type
+		if caseType == nil {
+			return Variant{Case: disc}, nil
+		}
+		value, err := c.load(caseType, ptr+alignTo(discSize, maxCaseAlignment(t.Cases)))
+		if err != nil {
+			return nil, err
+		}
+		return Variant{Case: disc, Value: value}, nil
+	}
+}
+
+// store stores a value of the type into linear memory
+//
+//nolint:gocyclo // one case per kind of type
+func (c *callContext) store(t *Type, ptr uint32, value any) error {
+	b, err := c.read(ptr, size(t))
+	if err != nil {
+		return err
+	}
+	switch t.Kind {
+	case KindBool, KindS8, KindU8, KindS16, KindU16, KindS32, KindU32, KindF32, KindChar, KindOwn, KindBorrow,
+		KindS64, KindU64, KindF64:
+		v, err := c.lowerScalar(t, value)
+		if err != nil {
+			return err
+		}
+		putUint(b, v)
+		return nil
+	case KindString, KindList:
+		flat, err := c.lowerFlat(t, value, nil)
+		if err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint32(b, uint32(flat[0]))
+		binary.LittleEndian.PutUint32(b[4:], uint32(flat[1]))
+		return nil
+	case KindRecord, KindTuple:
+		fields, err := recordFields(t, value)
+		if err != nil {
+			return err
+		}
+		var offset uint32
+		for i, field := range t.Fields {
+			offset = alignTo(offset, alignment(field.Type))
+			if err = c.store(field.Type, ptr+offset, fields[i]); err != nil {
+				return err
+			}
+			offset += size(field.Type)
+		}
+		return nil
+	case KindFlags:
+		flags, err := Unsigned(value)
+		if err != nil {
+			return err
+		}
+		putUint(b, flags)
+		return nil
+	default:
+		disc, payload, err := variantCase(t, value)
+		if err != nil {
+			return err
+		}
+		discSize := discriminantSize(len(t.Cases))
+		putUint(b[:discSize], uint64(disc))
+		if caseType := t.Cases[disc].Type; caseType != nil {
+			return c.store(caseType, ptr+alignTo(discSize, maxCaseAlignment(t.Cases)), payload)
+		}
+		return nil
+	}
+}
+
+// putUint writes the low bytes of the value in little endian order, filling the buffer
+func putUint(b []byte, v uint64) {
+	for i := range b {
+		b[i] = byte(v >> (8 * i))
+	}
+}
+
+// allocate allocates memory in the component through its realloc function
+func (c *callContext) allocate(align, n uint32) (uint32, error) {
+	if c.realloc == nil {
+		return 0, errors.New("canonical option realloc is required")
+	}
+	results, err := c.realloc.Call(c.ctx, 0, 0, uint64(align), uint64(n))
+	if err != nil {
+		return 0, err
+	}
+	ptr := uint32(results[0])
+	if ptr%align != 0 {
+		return 0, fmt.Errorf("realloc returned unaligned pointer %d", ptr)
+	}
+	if _, err = c.read(ptr, n); err != nil {
+		return 0, err
+	}
+	return ptr, nil
+}
+
+func (c *callContext) storeString(s string) (uint32, error) {
+	if uint64(len(s)) > math.MaxUint32 {
+		return 0, errOutOfBounds
+	}
+	ptr, err := c.allocate(1, uint32(len(s)))
+	if err != nil {
+		return 0, err
+	}
+	if !c.memory.WriteString(ptr, s) {
+		return 0, errOutOfBounds
+	}
+	return ptr, nil
+}
+
+func (c *callContext) storeList(elem *Type, value any) (uint32, uint32, error) {
+	if elem.Kind == KindU8 {
+		if b, ok := value.([]byte); ok {
+			if uint64(len(b)) > math.MaxUint32 {
+				return 0, 0, errOutOfBounds
+			}
+			ptr, err := c.allocate(1, uint32(len(b)))
+			if err != nil {
+				return 0, 0, err
+			}
+			if !c.memory.Write(ptr, b) {
+				return 0, 0, errOutOfBounds
+			}
+			return ptr, uint32(len(b)), nil
+		}
+	}
+	values, ok := value.([]any)
+	if !ok {
+		return 0, 0, fmt.Errorf("expected []any for list<%s>, got %T", elem, value)
+	}
+	elemSize := size(elem)
+	if uint64(len(values))*uint64(elemSize) > math.MaxUint32 {
+		return 0, 0, errOutOfBounds
+	}
+	length := uint32(len(values))
+	ptr, err := c.allocate(alignment(elem), length*elemSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	for i, v := range values {
+		if err = c.store(elem, ptr+uint32(i)*elemSize, v); err != nil { //nolint:gosec // G115: bounds checked above
+			return 0, 0, err
+		}
+	}
+	return ptr, length, nil
+}
+
+// liftParams lifts the parameters a component passes to a lowered function
+func (c *callContext) liftParams(ft *FuncType, stack []uint64) ([]any, error) {
+	paramsType := ft.paramsType()
+	if len(flatten(paramsType)) > maxFlatParams {
+		params, err := c.load(paramsType, uint32(stack[0]))
+		if err != nil {
+			return nil, err
+		}
+		return params.([]any), nil
+	}
+	params, err := c.liftFlat(paramsType, &flatValues{values: stack})
+	if err != nil {
+		return nil, err
+	}
+	return params.([]any), nil
+}
+
+// lowerResult lowers the result of a lowered function, either into the stack or the memory of the return pointer
+func (c *callContext) lowerResult(ft *FuncType, result any, stack []uint64) error {
+	if ft.Result == nil {
+		return nil
+	}
+	flat := ft.flatResults()
+	if len(flat) > maxFlatResults {
+		retPtrIndex := len(ft.flatParams())
+		if retPtrIndex > maxFlatParams {
+			retPtrIndex = 1
+		}
+		return c.store(ft.Result, uint32(stack[retPtrIndex]), result)
+	}
+	values, err := c.lowerFlat(ft.Result, result, nil)
+	if err != nil {
+		return err
+	}
+	copy(stack, values)
+	return nil
+}
+
+// lowerParams lowers the parameters of a call to a lifted function
+func (c *callContext) lowerParams(ft *FuncType, args []any) ([]uint64, error) {
+	paramsType := ft.paramsType()
+	if len(flatten(paramsType)) > maxFlatParams {
+		ptr, err := c.allocate(alignment(paramsType), size(paramsType))
+		if err != nil {
+			return nil, err
+		}
+		return []uint64{uint64(ptr)}, c.store(paramsType, ptr, args)
+	}
+	return c.lowerFlat(paramsType, args, nil)
+}
+
+// liftResult lifts the result of a call to a lifted function
+func (c *callContext) liftResult(ft *FuncType, results []uint64) (any, error) {
+	if ft.Result == nil {
+		return nil, nil
+	}
+	if len(ft.flatResults()) > maxFlatResults {
+		return c.load(ft.Result, uint32(results[0]))
+	}
+	return c.liftFlat(ft.Result, &flatValues{values: results})
+}
+
+// coreSignature returns the core parameters and results of a function of the type. Lowered functions
+// return results that don't fit the flat results through a pointer passed as last parameter, while
+// lifted functions return a pointer to them.
+func coreSignature(ft *FuncType, lower bool) ([]api.ValueType, []api.ValueType) {
+	params := ft.flatParams()
+	if len(params) > maxFlatParams {
+		params = []api.ValueType{api.ValueTypeI32}
+	}
+	results := ft.flatResults()
+	if len(results) > maxFlatResults {
+		if lower {
+			return append(params, api.ValueTypeI32), nil
+		}
+		results = []api.ValueType{api.ValueTypeI32}
+	}
+	return params, results
+}
@@ -0,0 +1,698 @@
+package component
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Magic is the magic number every WASM binary starts with
+const Magic = "\x00asm"
+
+// Layer is the layer of WebAssembly components, which is 0 for core modules
+const Layer = 1
+
+// componentVersion is the version of the component binary format this package decodes
+const componentVersion = 0x0d
+
+// headerSize is the size of the magic number, version and layer of a WASM binary
+const headerSize = 8
+
+// Section IDs of the component binary format
+const (
+	sectionCustom       = 0
+	sectionCoreModule   = 1
+	sectionCoreInstance = 2
+	sectionCoreType     = 3
+	sectionComponent    = 4
+	sectionInstance     = 5
+	sectionAlias        = 6
+	sectionType         = 7
+	sectionCanon        = 8
+	sectionStart        = 9
+	sectionImport       = 10
+	sectionExport       = 11
+	sectionValue        = 12
+)
+
+// Sorts of the core index spaces
+const (
+	coreSortFunc     = 0x00
+	coreSortTable    = 0x01
+	coreSortMemory   = 0x02
+	coreSortGlobal   = 0x03
+	coreSortType     = 0x10
+	coreSortModule   = 0x11
+	coreSortInstance = 0x12
+)
+
+// Sorts of the component index spaces
+const (
+	sortCore      = 0x00
+	sortFunc      = 0x01
+	sortValue     = 0x02
+	sortType      = 0x03
+	sortComponent = 0x04
+	sortInstance  = 0x05
+)
+
+// Targets of aliases
+const (
+	aliasExport     = 0x00
+	aliasCoreExport = 0x01
+	aliasOuter      = 0x02
+)
+
+// Canonical definitions
+const (
+	canonLift         = 0x00
+	canonLower        = 0x01
+	canonResourceNew  = 0x02
+	canonResourceDrop = 0x03
+	canonResourceRep  = 0x04
+)
+
+// Canonical options
+const (
+	optUTF8       = 0x00
+	optUTF16      = 0x01
+	optLatin1     = 0x02
+	optMemory     = 0x03
+	optRealloc    = 0x04
+	optPostReturn = 0x05
+)
+
+// IsComponent returns true if the binary is a WebAssembly component rather than a core module.
+// The layer is encoded in the two bytes following the magic number and version.
+func IsComponent(binary []byte) bool {
+	if len(binary) < headerSize || string(binary[:len(Magic)]) != Magic {
+		return false
+	}
+	return binary[6] == Layer && binary[7] == 0
+}
+
+// Component is a decoded WebAssembly component, ready to be instantiated
+type Component struct {
+	// definitions of the component in binary order, which is the order their indices are assigned in
+	definitions []any
+}
+
+type coreModuleDef struct {
+	binary []byte
+}
+
+type coreInstantiateArg struct {
+	name     string
+	instance uint32
+}
+
+type coreInstanceDef struct {
+	module uint32
+	args   []coreInstantiateArg
+}
+
+type coreInlineExport struct {
+	name  string
+	sort  byte
+	index uint32
+}
+
+type coreInlineInstanceDef struct {
+	exports []coreInlineExport
+}
+
+type coreTypeDef struct{}
+
+type inlineExport struct {
+	name  string
+	sort  sortIndex
+	index uint32
+}
+
+type inlineInstanceDef struct {
+	exports []inlineExport
+}
+
+// sortIndex identifies an index space, with core set for the core sorts
+type sortIndex struct {
+	sort     byte
+	coreSort byte
+}
+
+func (s sortIndex) String() string {
+	if s.sort == sortCore {
+		return fmt.Sprintf("core sort 0x%x", s.coreSort)
+	}
+	return fmt.Sprintf("sort 0x%x", s.sort)
+}
+
+type aliasDef struct {
+	sort   sortIndex
+	target byte
+	// instance and name of export aliases
+	instance uint32
+	name     string
+	// count and index of outer aliases
+	outerCount uint32
+	outerIndex uint32
+}
+
+type typeDef struct {
+	expr typeExpr
+}
+
+type canonOptions struct {
+	encoding   byte
+	memory     *uint32
+	realloc    *uint32
+	postReturn *uint32
+}
+
+type canonDef struct {
+	kind byte
+	// function of lifts and lowers
+	function uint32
+	options  canonOptions
+	// type of lifts, and resource type of resource built-ins
+	typeIndex uint32
+}
+
+type importDef struct {
+	name string
+	desc externDesc
+}
+
+type exportDef struct {
+	name  string
+	sort  sortIndex
+	index uint32
+}
+
+// externDesc describes an import or export
+type externDesc struct {
+	sort sortIndex
+	// index is the type of the import or export, or the bound type of type imports and exports
+	index uint32
+	// subResource is set for type imports and exports bounded as fresh resource types
+	subResource bool
+}
+
+// Decode decodes a WebAssembly component. Nested components, component values and start functions are
+// not supported, which the components built by current toolchains don't use.
+func Decode(binary []byte) (*Component, error) {
+	if !IsComponent(binary) {
+		return nil, errors.New("not a WebAssembly component")
+	}
+	if binary[4] != componentVersion || binary[5] != 0 {
+		return nil, fmt.Errorf("unsupported component binary version 0x%x", binary[4])
+	}
+
+	r := &reader{buf: binary, pos: headerSize}
+	c := new(Component)
+	for !r.eof() {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		contents, err := r.bytes(size)
+		if err != nil {
+			return nil, err
+		}
+		if err = c.decodeSection(id, &reader{buf: contents}); err != nil {
+			return nil, fmt.Errorf("section %d at offset %d: %w", id, r.pos-int(size), err)
+		}
+	}
+	return c, nil
+}
+
+func (c *Component) decodeSection(id byte, r *reader) error {
+	var decode func(*reader) (any, error)
+	switch id {
+	case sectionCustom:
+		return nil
+	case sectionCoreModule:
+		c.definitions = append(c.definitions, &coreModuleDef{binary: r.buf})
+		return nil
+	case sectionCoreInstance:
+		decode = decodeCoreInstance
+	case sectionCoreType:
+		decode = func(r *reader) (any, error) {
+			return &coreTypeDef{}, skipCoreType(r)
+		}
+	case sectionInstance:
+		decode = decodeInstance
+	case sectionAlias:
+		decode = func(r *reader) (any, error) { return decodeAlias(r) }
+	case sectionType:
+		decode = func(r *reader) (any, error) {
+			expr, err := decodeTypeExpr(r)
+			return &typeDef{expr: expr}, err
+		}
+	case sectionCanon:
+		decode = decodeCanon
+	case sectionImport:
+		decode = func(r *reader) (any, error) {
+			name, err := r.externName()
+			if err != nil {
+				return nil, err
+			}
+			desc, err := decodeExternDesc(r)
+			return &importDef{name: name, desc: desc}, err
+		}
+	case sectionExport:
+		decode = decodeExport
+	case sectionComponent:
+		return errors.New("nested components are not supported")
+	case sectionStart:
+		return errors.New("component start functions are not supported")
+	case sectionValue:
+		return errors.New("component values are not supported")
+	default:
+		return fmt.Errorf("unknown section id %d", id)
+	}
+
+	count, err := r.u32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		definition, err := decode(r)
+		if err != nil {
+			return err
+		}
+		c.definitions = append(c.definitions, definition)
+	}
+	if !r.eof() {
+		return errors.New("unexpected trailing bytes")
+	}
+	return nil
+}
+
+func decodeCoreInstance(r *reader) (any, error) {
+	kind, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case 0x00:
+		def := new(coreInstanceDef)
+		if def.module, err = r.u32(); err != nil {
+			return nil, err
+		}
+		count, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint32(0); i < count; i++ {
+			var arg coreInstantiateArg
+			if arg.name, err = r.name(); err != nil {
+				return nil, err
+			}
+			if err = r.expect(coreSortInstance); err != nil {
+				return nil, err
+			}
+			if arg.instance, err = r.u32(); err != nil {
+				return nil, err
+			}
+			def.args = append(def.args, arg)
+		}
+		return def, nil
+	case 0x01:
+		def := new(coreInlineInstanceDef)
+		count, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint32(0); i < count; i++ {
+			var export coreInlineExport
+			if export.name, err = r.name(); err != nil {
+				return nil, err
+			}
+			if export.sort, err = r.byte(); err != nil {
+				return nil, err
+			}
+			if export.index, err = r.u32(); err != nil {
+				return nil, err
+			}
+			def.exports = append(def.exports, export)
+		}
+		return def, nil
+	default:
+		return nil, fmt.Errorf("unknown core instance kind 0x%x", kind)
+	}
+}
+
+func decodeInstance(r *reader) (any, error) {
+	kind, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	if kind == 0x00 {
+		return nil, errors.New("instantiating nested components is not supported")
+	}
+	if kind != 0x01 {
+		return nil, fmt.Errorf("unknown instance kind 0x%x", kind)
+	}
+	def := new(inlineInstanceDef)
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < count; i++ {
+		var export inlineExport
+		if export.name, err = r.externName(); err != nil {
+			return nil, err
+		}
+		if export.sort, err = decodeSort(r); err != nil {
+			return nil, err
+		}
+		if export.index, err = r.u32(); err != nil {
+			return nil, err
+		}
+		def.exports = append(def.exports, export)
+	}
+	return def, nil
+}
+
+func decodeSort(r *reader) (sortIndex, error) {
+	sort, err := r.byte()
+	if err != nil {
+		return sortIndex{}, err
+	}
+	s := sortIndex{sort: sort}
+	switch sort {
+	case sortCore:
+		s.coreSort, err = r.byte()
+		return s, err
+	case sortFunc, sortValue, sortType, sortComponent, sortInstance:
+		return s, nil
+	default:
+		return s, fmt.Errorf("unknown sort 0x%x", sort)
+	}
+}
+
+func decodeAlias(r *reader) (*aliasDef, error) {
+	sort, err := decodeSort(r)
+	if err != nil {
+		return nil, err
+	}
+	def := &aliasDef{sort: sort}
+	if def.target, err = r.byte(); err != nil {
+		return nil, err
+	}
+	switch def.target {
+	case aliasExport, aliasCoreExport:
+		if def.instance, err = r.u32(); err != nil {
+			return nil, err
+		}
+		def.name, err = r.name()
+	case aliasOuter:
+		if def.outerCount, err = r.u32(); err != nil {
+			return nil, err
+		}
+		def.outerIndex, err = r.u32()
+	default:
+		err = fmt.Errorf("unknown alias target 0x%x", def.target)
+	}
+	return def, err
+}
+
+func decodeCanon(r *reader) (any, error) {
+	kind, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	def := &canonDef{kind: kind}
+	switch kind {
+	case canonLift, canonLower:
+		if err = r.expect(0x00); err != nil {
+			return nil, err
+		}
+		if def.function, err = r.u32(); err != nil {
+			return nil, err
+		}
+		if def.options, err = decodeCanonOptions(r); err != nil {
+			return nil, err
+		}
+		if kind == canonLift {
+			def.typeIndex, err = r.u32()
+		}
+		return def, err
+	case canonResourceNew, canonResourceDrop, canonResourceRep:
+		def.typeIndex, err = r.u32()
+		return def, err
+	default:
+		return nil, fmt.Errorf("canonical definition 0x%x is not supported", kind)
+	}
+}
+
+func decodeCanonOptions(r *reader) (canonOptions, error) {
+	options := canonOptions{encoding: optUTF8}
+	count, err := r.u32()
+	if err != nil {
+		return options, err
+	}
+	for i := uint32(0); i < count; i++ {
+		option, err := r.byte()
+		if err != nil {
+			return options, err
+		}
+		switch option {
+		case optUTF8, optUTF16, optLatin1:
+			options.encoding = option
+		case optMemory, optRealloc, optPostReturn:
+			index, err := r.u32()
+			if err != nil {
+				return options, err
+			}
+			switch option {
+			case optMemory:
+				options.memory = &index
+			case optRealloc:
+				options.realloc = &index
+			default:
+				options.postReturn = &index
+			}
+		default:
+			return options, fmt.Errorf("canonical option 0x%x is not supported", option)
+		}
+	}
+	return options, nil
+}
+
+func decodeExport(r *reader) (any, error) {
+	name, err := r.externName()
+	if err != nil {
+		return nil, err
+	}
+	def := &exportDef{name: name}
+	if def.sort, err = decodeSort(r); err != nil {
+		return nil, err
+	}
+	if def.index, err = r.u32(); err != nil {
+		return nil, err
+	}
+	// the optional type ascription of the export doesn't change how it is instantiated
+	hasType, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	if hasType == 0x01 {
+		_, err = decodeExternDesc(r)
+	}
+	return def, err
+}
+
+func decodeExternDesc(r *reader) (externDesc, error) {
+	var desc externDesc
+	sort, err := r.byte()
+	if err != nil {
+		return desc, err
+	}
+	desc.sort = sortIndex{sort: sort}
+	switch sort {
+	case sortCore:
+		if err = r.expect(coreSortModule); err != nil {
+			return desc, err
+		}
+		desc.sort.coreSort = coreSortModule
+		desc.index, err = r.u32()
+	case sortFunc, sortComponent, sortInstance:
+		desc.index, err = r.u32()
+	case sortValue:
+		return desc, errors.New("component values are not supported")
+	case sortType:
+		bound, err := r.byte()
+		if err != nil {
+			return desc, err
+		}
+		switch bound {
+		case 0x00:
+			desc.index, err = r.u32()
+			return desc, err
+		case 0x01:
+			desc.subResource = true
+		default:
+			return desc, fmt.Errorf("unknown type bound 0x%x", bound)
+		}
+	default:
+		err = fmt.Errorf("unknown extern sort 0x%x", sort)
+	}
+	return desc, err
+}
+
+// skipCoreType decodes a core function type, which only takes a place in the core type index space.
+// Core module types are only used to import modules, which is not supported.
+func skipCoreType(r *reader) error {
+	form, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if form != 0x60 {
+		return fmt.Errorf("core type 0x%x is not supported", form)
+	}
+	for range 2 {
+		count, err := r.u32()
+		if err != nil {
+			return err
+		}
+		for i := uint32(0); i < count; i++ {
+			if err = skipCoreValType(r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func skipCoreValType(r *reader) error {
+	valType, err := r.byte()
+	if err != nil {
+		return err
+	}
+	switch valType {
+	case 0x7f, 0x7e, 0x7d, 0x7c, 0x7b, 0x70, 0x6f:
+		return nil
+	case 0x63, 0x64:
+		_, err = r.s33()
+		return err
+	default:
+		return fmt.Errorf("unknown core value type 0x%x", valType)
+	}
+}
+
+// reader decodes the primitives of the WASM binary format
+type reader struct {
+	buf []byte
+	pos int
+}
+
+var errUnexpectedEnd = errors.New("unexpected end of binary")
+
+func (r *reader) eof() bool {
+	return r.pos >= len(r.buf)
+}
+
+func (r *reader) byte() (byte, error) {
+	if r.eof() {
+		return 0, errUnexpectedEnd
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *reader) expect(expected byte) error {
+	b, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if b != expected {
+		return fmt.Errorf("expected 0x%x, got 0x%x at offset %d", expected, b, r.pos-1)
+	}
+	return nil
+}
+
+func (r *reader) bytes(n uint32) ([]byte, error) {
+	if uint64(len(r.buf)-r.pos) < uint64(n) {
+		return nil, errUnexpectedEnd
+	}
+	b := r.buf[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+// u32 decodes an unsigned LEB128 integer of at most 32 bits
+func (r *reader) u32() (uint32, error) {
+	var result uint32
+	for shift := 0; shift < 35; shift += 7 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		result |= uint32(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return result, nil
+		}
+	}
+	return 0, errors.New("integer too large")
+}
+
+// s33 decodes a signed LEB128 integer of at most 33 bits
+func (r *reader) s33() (int64, error) {
+	var result int64
+	for shift := 0; shift < 35; shift += 7 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		result |= int64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			if shift+7 < 64 && b&0x40 != 0 {
+				result |= -1 << (shift + 7)
+			}
+			return result, nil
+		}
+	}
+	return 0, errors.New("integer too large")
+}
+
+func (r *reader) name() (string, error) {
+	size, err := r.u32()
+	if err != nil {
+		return "", err
+	}
+	b, err := r.bytes(size)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(b) {
+		return "", errors.New("invalid UTF-8 name")
+	}
+	return string(b), nil
+}
+
+// externName decodes the name of an import or export, which may be followed by a version suffix
+func (r *reader) externName() (string, error) {
+	kind, err := r.byte()
+	if err != nil {
+		return "", err
+	}
+	name, err := r.name()
+	if err != nil {
+		return "", err
+	}
+	switch kind {
+	case 0x00:
+		return name, nil
+	case 0x01:
+		suffix, err := r.name()
+		return name + suffix, err
+	default:
+		return "", fmt.Errorf("unknown name kind 0x%x", kind)
+	}
+}
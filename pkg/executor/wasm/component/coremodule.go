@@ -0,0 +1,215 @@
+package component
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of core imports
+const (
+	coreImportFunc   = 0x00
+	coreImportTable  = 0x01
+	coreImportMemory = 0x02
+	coreImportGlobal = 0x03
+	coreImportTag    = 0x04
+)
+
+// coreImportSection is the ID of the import section of core modules
+const coreImportSection = 2
+
+// coreImportResolver resolves an import of a core module to the module instance and name it imports
+type coreImportResolver func(module, name string, kind byte) (string, string, error)
+
+// rewriteImports returns a copy of the core module binary importing the items it imports from the module
+// instances the resolver resolves them to. The runtime links core modules by the name of the module
+// instances, while components link them through the arguments of their instantiation.
+func rewriteImports(binary []byte, resolve coreImportResolver) ([]byte, error) {
+	if len(binary) < headerSize || string(binary[:len(Magic)]) != Magic {
+		return nil, errors.New("not a core WebAssembly module")
+	}
+	out := append([]byte(nil), binary[:headerSize]...)
+	r := &reader{buf: binary, pos: headerSize}
+	for !r.eof() {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		sectionSize, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		contents, err := r.bytes(sectionSize)
+		if err != nil {
+			return nil, err
+		}
+		if id == coreImportSection {
+			if contents, err = rewriteImportSection(contents, resolve); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, id)
+		out = appendU32(out, uint32(len(contents))) //nolint:gosec // G115: sections are smaller than 2^32
+		out = append(out, contents...)
+	}
+	return out, nil
+}
+
+func rewriteImportSection(section []byte, resolve coreImportResolver) ([]byte, error) {
+	r := &reader{buf: section}
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	out := appendU32(nil, count)
+	for i := uint32(0); i < count; i++ {
+		module, err := r.name()
+		if err != nil {
+			return nil, err
+		}
+		name, err := r.name()
+		if err != nil {
+			return nil, err
+		}
+		start := r.pos
+		kind, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if err = skipImportDesc(r, kind); err != nil {
+			return nil, fmt.Errorf("import %s.%s: %w", module, name, err)
+		}
+		if module, name, err = resolve(module, name, kind); err != nil {
+			return nil, err
+		}
+		out = appendName(out, module)
+		out = appendName(out, name)
+		out = append(out, section[start:r.pos]...)
+	}
+	if !r.eof() {
+		return nil, errors.New("unexpected trailing bytes in import section")
+	}
+	return out, nil
+}
+
+func skipImportDesc(r *reader, kind byte) error {
+	switch kind {
+	case coreImportFunc:
+		_, err := r.u32()
+		return err
+	case coreImportTable:
+		if err := skipCoreValType(r); err != nil {
+			return err
+		}
+		return skipLimits(r)
+	case coreImportMemory:
+		return skipLimits(r)
+	case coreImportGlobal:
+		if err := skipCoreValType(r); err != nil {
+			return err
+		}
+		_, err := r.byte()
+		return err
+	case coreImportTag:
+		if err := r.expect(0x00); err != nil {
+			return err
+		}
+		_, err := r.u32()
+		return err
+	default:
+		return fmt.Errorf("unknown import kind 0x%x", kind)
+	}
+}
+
+// skipLimits skips the limits of a table or memory, whose flags tell if they have a maximum
+func skipLimits(r *reader) error {
+	flags, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if _, err = r.u64(); err != nil {
+		return err
+	}
+	if flags&0x01 != 0 {
+		_, err = r.u64()
+	}
+	return err
+}
+
+// u64 decodes an unsigned LEB128 integer of at most 64 bits
+func (r *reader) u64() (uint64, error) {
+	var result uint64
+	for shift := 0; shift < 70; shift += 7 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		result |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return result, nil
+		}
+	}
+	return 0, errors.New("integer too large")
+}
+
+func appendU32(out []byte, v uint32) []byte {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func appendName(out []byte, name string) []byte {
+	out = appendU32(out, uint32(len(name))) //nolint:gosec // G115: names are smaller than 2^32
+	return append(out, name...)
+}
+
+// coreExportSection is the ID of the export section of core modules
+const coreExportSection = 7
+
+// coreExports returns the names of the exports of a core module binary
+func coreExports(binary []byte) ([]string, error) {
+	r := &reader{buf: binary, pos: headerSize}
+	for !r.eof() {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		sectionSize, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		contents, err := r.bytes(sectionSize)
+		if err != nil {
+			return nil, err
+		}
+		if id != coreExportSection {
+			continue
+		}
+		section := &reader{buf: contents}
+		count, err := section.u32()
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, count)
+		for i := uint32(0); i < count; i++ {
+			name, err := section.name()
+			if err != nil {
+				return nil, err
+			}
+			// the kind and index of the export
+			if _, err = section.byte(); err != nil {
+				return nil, err
+			}
+			if _, err = section.u32(); err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+		}
+		return names, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,697 @@
+package component
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// InstantiateParams are the parameters of the instantiation of a component
+type InstantiateParams struct {
+	// Runtime instantiates the core modules of the component
+	Runtime wazero.Runtime
+	// Linker provides the instances the component imports
+	Linker *Linker
+	// Name prefixes the names of the core module instances of the component, which must be unique
+	// in the runtime
+	Name string
+	// Compile compiles the core modules of the component. Modules are compiled by the runtime if nil.
+	Compile func(ctx context.Context, binary []byte) (wazero.CompiledModule, error)
+}
+
+// coreItem is a core function, table, memory or global, which is either the export of a core module
+// instance, or a function implemented by the host such as a lowered function
+type coreItem struct {
+	module string
+	name   string
+	host   *hostCoreFunc
+}
+
+type hostCoreFunc struct {
+	params  []api.ValueType
+	results []api.ValueType
+	fn      api.GoModuleFunc
+}
+
+// coreInstance is a core module instance, or a core instance of inline exports
+type coreInstance struct {
+	exports map[string]coreItem
+}
+
+type componentFunc struct {
+	typ  *FuncType
+	call func(ctx context.Context, args []any) (any, error)
+}
+
+type componentInstance struct {
+	funcs map[string]*componentFunc
+	types map[string]*typeEntry
+}
+
+// Instance is an instantiated component
+type Instance struct {
+	runtime wazero.Runtime
+	// modules are the core module instances of the component, in instantiation order
+	modules   []api.Module
+	table     *handleTable
+	funcs     map[string]*componentFunc
+	instances map[string]*componentInstance
+}
+
+// instantiation holds the index spaces of a component being instantiated
+type instantiation struct {
+	params   InstantiateParams
+	instance *Instance
+
+	coreModules   []*coreModuleDef
+	coreInstances []*coreInstance
+	coreFuncs     []coreItem
+	coreTables    []coreItem
+	coreMemories  []coreItem
+	coreGlobals   []coreItem
+	funcs         []*componentFunc
+	instances     []*componentInstance
+	types         *typeScope
+}
+
+// Instantiate instantiates the component, linking its imports to the instances of the linker.
+// The instance must be closed once it is no longer used.
+func Instantiate(ctx context.Context, c *Component, params InstantiateParams) (*Instance, error) {
+	if params.Compile == nil {
+		params.Compile = params.Runtime.CompileModule
+	}
+	in := &instantiation{
+		params: params,
+		instance: &Instance{
+			runtime:   params.Runtime,
+			table:     newHandleTable(),
+			funcs:     make(map[string]*componentFunc),
+			instances: make(map[string]*componentInstance),
+		},
+	}
+	in.types = &typeScope{defineResource: in.defineResource}
+	for _, definition := range c.definitions {
+		if err := in.define(ctx, definition); err != nil {
+			_ = in.instance.Close(ctx)
+			return nil, err
+		}
+	}
+	return in.instance, nil
+}
+
+func (in *instantiation) define(ctx context.Context, definition any) error {
+	switch def := definition.(type) {
+	case *coreModuleDef:
+		in.coreModules = append(in.coreModules, def)
+	case *coreInstanceDef:
+		return in.instantiateCoreModule(ctx, def)
+	case *coreInlineInstanceDef:
+		return in.defineCoreInlineInstance(def)
+	case *coreTypeDef:
+		// core types are only referenced by the imports of core modules, which are not supported
+	case *inlineInstanceDef:
+		return in.defineInlineInstance(def)
+	case *aliasDef:
+		return in.defineAlias(def)
+	case *typeDef:
+		return in.types.define(def.expr)
+	case *canonDef:
+		return in.defineCanon(def)
+	case *importDef:
+		return in.defineImport(def)
+	case *exportDef:
+		return in.defineExport(def)
+	default:
+		return fmt.Errorf("unknown definition %T", definition)
+	}
+	return nil
+}
+
+// instantiateCoreModule instantiates a core module, importing the exports of the core instances passed
+// as arguments. Host functions are imported from a host module instantiated for the core module.
+func (in *instantiation) instantiateCoreModule(ctx context.Context, def *coreInstanceDef) error {
+	if def.module >= uint32(len(in.coreModules)) { //nolint:gosec // G115: index spaces are smaller than 2^32
+		return fmt.Errorf("core module index %d out of bounds", def.module)
+	}
+	args := make(map[string]*coreInstance, len(def.args))
+	for _, arg := range def.args {
+		instance, err := in.coreInstance(arg.instance)
+		if err != nil {
+			return err
+		}
+		args[arg.name] = instance
+	}
+
+	index := len(in.coreInstances)
+	moduleName := fmt.Sprintf("%s#core%d", in.params.Name, index)
+	hostName := fmt.Sprintf("%s#host%d", in.params.Name, index)
+	var hostFuncs []*hostCoreFunc
+	binary, err := rewriteImports(in.coreModules[def.module].binary, func(module, name string, kind byte) (string, string, error) {
+		arg, ok := args[module]
+		if !ok {
+			return "", "", fmt.Errorf("core module %s imports %s.%s, which is not passed to its instantiation", moduleName, module, name)
+		}
+		item, ok := arg.exports[name]
+		if !ok {
+			return "", "", fmt.Errorf("core module %s imports %s.%s, which is not exported by its argument", moduleName, module, name)
+		}
+		if item.host == nil {
+			return item.module, item.name, nil
+		}
+		if kind != coreImportFunc {
+			return "", "", fmt.Errorf("core module %s imports function %s.%s as another kind", moduleName, module, name)
+		}
+		hostFuncs = append(hostFuncs, item.host)
+		return hostName, strconv.Itoa(len(hostFuncs) - 1), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(hostFuncs) > 0 {
+		builder := in.params.Runtime.NewHostModuleBuilder(hostName)
+		for i, fn := range hostFuncs {
+			builder.NewFunctionBuilder().WithGoModuleFunction(fn.fn, fn.params, fn.results).Export(strconv.Itoa(i))
+		}
+		host, err := builder.Instantiate(ctx)
+		if err != nil {
+			return fmt.Errorf("instantiating the host functions of core module %s: %w", moduleName, err)
+		}
+		in.instance.modules = append(in.instance.modules, host)
+	}
+
+	compiled, err := in.params.Compile(ctx, binary)
+	if err != nil {
+		return fmt.Errorf("compiling core module %s: %w", moduleName, err)
+	}
+	defer func() { _ = compiled.Close(ctx) }()
+	module, err := in.params.Runtime.InstantiateModule(ctx, compiled,
+		wazero.NewModuleConfig().WithName(moduleName).WithStartFunctions())
+	if err != nil {
+		return fmt.Errorf("instantiating core module %s: %w", moduleName, err)
+	}
+	in.instance.modules = append(in.instance.modules, module)
+
+	exports, err := coreExports(binary)
+	if err != nil {
+		return fmt.Errorf("decoding the exports of core module %s: %w", moduleName, err)
+	}
+	instance := &coreInstance{exports: make(map[string]coreItem, len(exports))}
+	for _, name := range exports {
+		instance.exports[name] = coreItem{module: moduleName, name: name}
+	}
+	in.coreInstances = append(in.coreInstances, instance)
+	return nil
+}
+
+func (in *instantiation) defineCoreInlineInstance(def *coreInlineInstanceDef) error {
+	instance := &coreInstance{exports: make(map[string]coreItem, len(def.exports))}
+	for _, export := range def.exports {
+		space, err := in.coreSpace(export.sort)
+		if err != nil {
+			return err
+		}
+		if export.index >= uint32(len(*space)) { //nolint:gosec // G115: index spaces are smaller than 2^32
+			return fmt.Errorf("core index %d of export %q out of bounds", export.index, export.name)
+		}
+		instance.exports[export.name] = (*space)[export.index]
+	}
+	in.coreInstances = append(in.coreInstances, instance)
+	return nil
+}
+
+func (in *instantiation) defineInlineInstance(def *inlineInstanceDef) error {
+	instance := &componentInstance{funcs: make(map[string]*componentFunc), types: make(map[string]*typeEntry)}
+	for _, export := range def.exports {
+		switch export.sort.sort {
+		case sortFunc:
+			fn, err := in.function(export.index)
+			if err != nil {
+				return err
+			}
+			instance.funcs[export.name] = fn
+		case sortType:
+			entry, err := in.types.get(export.index)
+			if err != nil {
+				return err
+			}
+			instance.types[export.name] = entry
+		default:
+			return fmt.Errorf("export %q of instance: %s exports are not supported", export.name, export.sort)
+		}
+	}
+	in.instances = append(in.instances, instance)
+	return nil
+}
+
+func (in *instantiation) defineAlias(def *aliasDef) error {
+	switch def.target {
+	case aliasCoreExport:
+		instance, err := in.coreInstance(def.instance)
+		if err != nil {
+			return err
+		}
+		item, ok := instance.exports[def.name]
+		if !ok {
+			return fmt.Errorf("core instance %d has no export %q", def.instance, def.name)
+		}
+		if def.sort.sort != sortCore {
+			return fmt.Errorf("core export alias of %s", def.sort)
+		}
+		space, err := in.coreSpace(def.sort.coreSort)
+		if err != nil {
+			return err
+		}
+		*space = append(*space, item)
+	case aliasExport:
+		instance, err := in.componentInstance(def.instance)
+		if err != nil {
+			return err
+		}
+		switch def.sort.sort {
+		case sortFunc:
+			fn, ok := instance.funcs[def.name]
+			if !ok {
+				return fmt.Errorf("instance %d has no function %q", def.instance, def.name)
+			}
+			in.funcs = append(in.funcs, fn)
+		case sortType:
+			entry, ok := instance.types[def.name]
+			if !ok {
+				return fmt.Errorf("instance %d has no type %q", def.instance, def.name)
+			}
+			in.types.types = append(in.types.types, entry)
+		default:
+			return fmt.Errorf("alias of %s exports is not supported", def.sort)
+		}
+	case aliasOuter:
+		// components are not nested, so outer aliases can only refer to the component itself
+		if def.outerCount != 0 || def.sort.sort != sortType {
+			return errors.New("only outer aliases of the types of the component are supported")
+		}
+		entry, err := in.types.get(def.outerIndex)
+		if err != nil {
+			return err
+		}
+		in.types.types = append(in.types.types, entry)
+	}
+	return nil
+}
+
+func (in *instantiation) defineCanon(def *canonDef) error {
+	switch def.kind {
+	case canonLift:
+		return in.lift(def)
+	case canonLower:
+		return in.lower(def)
+	}
+
+	entry, err := in.types.get(def.typeIndex)
+	if err != nil {
+		return err
+	}
+	resource := entry.resource
+	if resource == nil {
+		return fmt.Errorf("type %d of resource built-in is not a resource", def.typeIndex)
+	}
+	table := in.instance.table
+	i32 := []api.ValueType{api.ValueTypeI32}
+	var fn *hostCoreFunc
+	switch def.kind {
+	case canonResourceNew:
+		fn = &hostCoreFunc{params: i32, results: i32, fn: func(_ context.Context, _ api.Module, stack []uint64) {
+			stack[0] = uint64(table.add(resource, uint32(stack[0]))) //nolint:gosec // G115: reps are i32 values
+		}}
+	case canonResourceRep:
+		fn = &hostCoreFunc{params: i32, results: i32, fn: func(_ context.Context, _ api.Module, stack []uint64) {
+			rep, err := table.get(resource, uint32(stack[0])) //nolint:gosec // G115: handles are i32 values
+			if err != nil {
+				panic(err)
+			}
+			guestRep, ok := rep.(uint32)
+			if !ok {
+				panic(fmt.Errorf("resource %s is not defined by the component", resource))
+			}
+			stack[0] = uint64(guestRep)
+		}}
+	default:
+		fn = &hostCoreFunc{params: i32, fn: func(ctx context.Context, _ api.Module, stack []uint64) {
+			rep, err := table.remove(resource, uint32(stack[0])) //nolint:gosec // G115: handles are i32 values
+			if err != nil {
+				panic(err)
+			}
+			if err = dropResource(ctx, resource, rep); err != nil {
+				panic(err)
+			}
+		}}
+	}
+	in.coreFuncs = append(in.coreFuncs, coreItem{host: fn})
+	return nil
+}
+
+// dropResource releases the representation of a resource whose handle is dropped
+func dropResource(ctx context.Context, resource *ResourceType, rep any) error {
+	if resource.host {
+		if resource.drop != nil {
+			resource.drop(ctx, rep)
+		}
+		return nil
+	}
+	if resource.dtor == nil {
+		return nil
+	}
+	guestRep, ok := rep.(uint32)
+	if !ok {
+		return fmt.Errorf("invalid representation %T of resource defined by the component", rep)
+	}
+	return resource.dtor(ctx, guestRep)
+}
+
+// lift defines a component function calling a core function of the component
+func (in *instantiation) lift(def *canonDef) error {
+	entry, err := in.types.get(def.typeIndex)
+	if err != nil {
+		return err
+	}
+	if entry.fn == nil {
+		return fmt.Errorf("type %d of lifted function is not a function type", def.typeIndex)
+	}
+	ft := entry.fn
+	core, err := in.coreFunction(def.function)
+	if err != nil {
+		return err
+	}
+	options, err := in.resolveOptions(def.options)
+	if err != nil {
+		return err
+	}
+	var postReturn api.Function
+	if def.options.postReturn != nil {
+		if postReturn, err = in.coreFunction(*def.options.postReturn); err != nil {
+			return err
+		}
+	}
+
+	table := in.instance.table
+	in.funcs = append(in.funcs, &componentFunc{typ: ft, call: func(ctx context.Context, args []any) (any, error) {
+		if len(args) != len(ft.Params) {
+			return nil, fmt.Errorf("function %s called with %d arguments", ft, len(args))
+		}
+		c := &callContext{ctx: ctx, memory: options.memory, realloc: options.realloc, table: table}
+		params, err := c.lowerParams(ft, args)
+		if err != nil {
+			return nil, err
+		}
+		results, err := core.Call(ctx, params...)
+		if err != nil {
+			return nil, err
+		}
+		result, err := c.liftResult(ft, results)
+		if err != nil {
+			return nil, err
+		}
+		if postReturn != nil {
+			if _, err = postReturn.Call(ctx, results...); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}})
+	return nil
+}
+
+// lower defines a core function calling a component function, such as a function of the host
+func (in *instantiation) lower(def *canonDef) error {
+	fn, err := in.function(def.function)
+	if err != nil {
+		return err
+	}
+	options, err := in.resolveOptions(def.options)
+	if err != nil {
+		return err
+	}
+	ft := fn.typ
+	table := in.instance.table
+	params, results := coreSignature(ft, true)
+	in.coreFuncs = append(in.coreFuncs, coreItem{host: &hostCoreFunc{
+		params:  params,
+		results: results,
+		fn: func(ctx context.Context, _ api.Module, stack []uint64) {
+			c := &callContext{ctx: ctx, memory: options.memory, realloc: options.realloc, table: table}
+			args, err := c.liftParams(ft, stack)
+			if err != nil {
+				panic(err)
+			}
+			result, err := fn.call(ctx, args)
+			if err != nil {
+				// errors such as the exit of the component are propagated by the runtime as is
+				panic(err)
+			}
+			if err = c.lowerResult(ft, result, stack); err != nil {
+				panic(err)
+			}
+		},
+	}})
+	return nil
+}
+
+type resolvedOptions struct {
+	memory  api.Memory
+	realloc api.Function
+}
+
+func (in *instantiation) resolveOptions(options canonOptions) (resolvedOptions, error) {
+	var resolved resolvedOptions
+	if options.encoding != optUTF8 {
+		return resolved, errors.New("only UTF-8 string encoding is supported")
+	}
+	if options.memory != nil {
+		if *options.memory >= uint32(len(in.coreMemories)) { //nolint:gosec // G115: index spaces are smaller than 2^32
+			return resolved, fmt.Errorf("core memory index %d out of bounds", *options.memory)
+		}
+		item := in.coreMemories[*options.memory]
+		module := in.params.Runtime.Module(item.module)
+		if module == nil || module.ExportedMemory(item.name) == nil {
+			return resolved, fmt.Errorf("core memory %d is not exported by a core module", *options.memory)
+		}
+		resolved.memory = module.ExportedMemory(item.name)
+	}
+	if options.realloc != nil {
+		realloc, err := in.coreFunction(*options.realloc)
+		if err != nil {
+			return resolved, err
+		}
+		resolved.realloc = realloc
+	}
+	return resolved, nil
+}
+
+// coreFunction returns a core function exported by a core module instance
+func (in *instantiation) coreFunction(index uint32) (api.Function, error) {
+	if index >= uint32(len(in.coreFuncs)) { //nolint:gosec // G115: index spaces are smaller than 2^32
+		return nil, fmt.Errorf("core function index %d out of bounds", index)
+	}
+	item := in.coreFuncs[index]
+	if item.host != nil {
+		return nil, fmt.Errorf("core function %d is implemented by the host, and cannot be lifted", index)
+	}
+	module := in.params.Runtime.Module(item.module)
+	if module == nil {
+		return nil, fmt.Errorf("core function %d is not exported by a core module", index)
+	}
+	if _, ok := module.ExportedFunctionDefinitions()[item.name]; !ok {
+		return nil, fmt.Errorf("core module %s does not export function %s", item.module, item.name)
+	}
+	return module.ExportedFunction(item.name), nil
+}
+
+// defineResource creates a resource type defined by the component, destroyed by its destructor if it has one
+func (in *instantiation) defineResource(expr *resourceTypeExpr) (*ResourceType, error) {
+	if expr.dtor == nil {
+		return newGuestResource(nil), nil
+	}
+	dtor, err := in.coreFunction(*expr.dtor)
+	if err != nil {
+		return nil, err
+	}
+	return newGuestResource(func(ctx context.Context, rep uint32) error {
+		_, err := dtor.Call(ctx, uint64(rep))
+		return err
+	}), nil
+}
+
+func (in *instantiation) defineImport(def *importDef) error {
+	switch def.desc.sort.sort {
+	case sortInstance:
+		entry, err := in.types.get(def.desc.index)
+		if err != nil {
+			return err
+		}
+		if entry.instance == nil {
+			return fmt.Errorf("type of imported instance %s is not an instance type", def.name)
+		}
+		host, err := in.params.Linker.resolve(def.name)
+		if err != nil {
+			return err
+		}
+		typ, err := evalInstanceType(entry.instance, func(name string) (*ResourceType, error) {
+			resource, ok := host.resources[name]
+			if !ok {
+				// the functions using the resource are not provided either
+				resource = NewHostResource(name, nil)
+			}
+			return resource, nil
+		})
+		if err != nil {
+			return fmt.Errorf("import %s: %w", def.name, err)
+		}
+		instance := &componentInstance{funcs: make(map[string]*componentFunc), types: typ.types}
+		for name, ft := range typ.funcs {
+			fn, ok := host.funcs[name]
+			if !ok {
+				fn = unsupportedFunc(def.name, name)
+			}
+			instance.funcs[name] = &componentFunc{typ: ft, call: fn}
+		}
+		in.instances = append(in.instances, instance)
+	case sortType:
+		if def.desc.subResource {
+			return fmt.Errorf("component imports resource %s, which is not provided by the host", def.name)
+		}
+		entry, err := in.types.get(def.desc.index)
+		if err != nil {
+			return err
+		}
+		in.types.types = append(in.types.types, entry)
+	default:
+		return fmt.Errorf("component imports %s of %s, which is not supported", def.name, def.desc.sort)
+	}
+	return nil
+}
+
+// unsupportedFunc implements a function the host doesn't provide, which traps when called
+func unsupportedFunc(instance, name string) HostFunc {
+	return func(context.Context, []any) (any, error) {
+		return nil, fmt.Errorf("function %s of %s is not supported by the host", name, instance)
+	}
+}
+
+func (in *instantiation) defineExport(def *exportDef) error {
+	switch def.sort.sort {
+	case sortFunc:
+		fn, err := in.function(def.index)
+		if err != nil {
+			return err
+		}
+		in.funcs = append(in.funcs, fn)
+		in.instance.funcs[def.name] = fn
+	case sortInstance:
+		instance, err := in.componentInstance(def.index)
+		if err != nil {
+			return err
+		}
+		in.instances = append(in.instances, instance)
+		in.instance.instances[def.name] = instance
+	case sortType:
+		entry, err := in.types.get(def.index)
+		if err != nil {
+			return err
+		}
+		in.types.types = append(in.types.types, entry)
+	default:
+		return fmt.Errorf("component exports %s of %s, which is not supported", def.name, def.sort)
+	}
+	return nil
+}
+
+func (in *instantiation) coreSpace(sort byte) (*[]coreItem, error) {
+	switch sort {
+	case coreSortFunc:
+		return &in.coreFuncs, nil
+	case coreSortTable:
+		return &in.coreTables, nil
+	case coreSortMemory:
+		return &in.coreMemories, nil
+	case coreSortGlobal:
+		return &in.coreGlobals, nil
+	default:
+		return nil, fmt.Errorf("core sort 0x%x is not supported", sort)
+	}
+}
+
+func (in *instantiation) coreInstance(index uint32) (*coreInstance, error) {
+	if index >= uint32(len(in.coreInstances)) { //nolint:gosec // G115: index spaces are smaller than 2^32
+		return nil, fmt.Errorf("core instance index %d out of bounds", index)
+	}
+	return in.coreInstances[index], nil
+}
+
+func (in *instantiation) componentInstance(index uint32) (*componentInstance, error) {
+	if index >= uint32(len(in.instances)) { //nolint:gosec // G115: index spaces are smaller than 2^32
+		return nil, fmt.Errorf("instance index %d out of bounds", index)
+	}
+	return in.instances[index], nil
+}
+
+func (in *instantiation) function(index uint32) (*componentFunc, error) {
+	if index >= uint32(len(in.funcs)) { //nolint:gosec // G115: index spaces are smaller than 2^32
+		return nil, fmt.Errorf("function index %d out of bounds", index)
+	}
+	return in.funcs[index], nil
+}
+
+// Func is a function exported by a component instance
+type Func struct {
+	fn *componentFunc
+}
+
+// Func returns the exported function with the name, or nil if the component doesn't export it. Functions of
+// exported instances are named after the instance and the function, such as "wasi:cli/run@0.2.0#run", and
+// are found in any compatible version of the instance.
+func (i *Instance) Func(name string) *Func {
+	instanceName, funcName, found := strings.Cut(name, "#")
+	if !found {
+		if fn, ok := i.funcs[name]; ok {
+			return &Func{fn: fn}
+		}
+		return nil
+	}
+	unversioned, version := splitVersion(instanceName)
+	for exportName, instance := range i.instances {
+		exportUnversioned, exportVersion := splitVersion(exportName)
+		if exportUnversioned != unversioned || !compatibleVersions(version, exportVersion) {
+			continue
+		}
+		if fn, ok := instance.funcs[funcName]; ok {
+			return &Func{fn: fn}
+		}
+	}
+	return nil
+}
+
+// Type returns the type of the function
+func (f *Func) Type() *FuncType {
+	return f.fn.typ
+}
+
+// Call calls the function with the arguments, and returns its result, or nil if the function has no result
+func (f *Func) Call(ctx context.Context, args ...any) (any, error) {
+	return f.fn.call(ctx, args)
+}
+
+// Close closes the core module instances of the component, and drops the host resources it still holds
+func (i *Instance) Close(ctx context.Context) error {
+	var errs error
+	for j := len(i.modules) - 1; j >= 0; j-- {
+		errs = errors.Join(errs, i.modules[j].Close(ctx))
+	}
+	for _, h := range i.table.drain() {
+		errs = errors.Join(errs, dropResource(ctx, h.resource, h.rep))
+	}
+	return errs
+}
@@ -0,0 +1,132 @@
+//go:build unit || !integration
+
+package component
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component/test"
+)
+
+func instantiate(t *testing.T, binary []byte, linker *Linker) *Instance {
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	t.Cleanup(func() { _ = runtime.Close(ctx) })
+
+	c, err := Decode(binary)
+	require.NoError(t, err)
+	instance, err := Instantiate(ctx, c, InstantiateParams{Runtime: runtime, Linker: linker, Name: t.Name()})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = instance.Close(ctx) })
+	return instance
+}
+
+func TestIsComponent(t *testing.T) {
+	require.True(t, IsComponent(test.Component()))
+	require.False(t, IsComponent(test.Module()))
+	require.False(t, IsComponent([]byte("\x00asm")))
+}
+
+func TestDecodeRejectsCoreModules(t *testing.T) {
+	_, err := Decode(test.Module())
+	require.Error(t, err)
+}
+
+func TestInstantiateStrings(t *testing.T) {
+	linker := NewLinker()
+	linker.Instance("test:host/api@0.1.0").Func("greet", func(_ context.Context, args []any) (any, error) {
+		a := NewArgs(args)
+		name := a.String(0)
+		if a.Err() != nil {
+			return nil, a.Err()
+		}
+		return "hello, " + name, nil
+	})
+	instance := instantiate(t, test.Greet("world"), linker)
+
+	run := instance.Func("run")
+	require.NotNil(t, run)
+	require.Equal(t, "func() -> string", run.Type().String())
+	result, err := run.Call(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "hello, world", result)
+}
+
+type counter struct {
+	value uint32
+}
+
+func TestInstantiateResources(t *testing.T) {
+	var dropped []any
+	linker := NewLinker()
+	linker.Instance("test:host/counter@0.1.2").
+		Resource("counter", NewHostResource("counter", func(_ context.Context, rep any) {
+			dropped = append(dropped, rep)
+		})).
+		Func("make", func(context.Context, []any) (any, error) {
+			return &counter{value: 42}, nil
+		}).
+		Func("[method]counter.get", func(_ context.Context, args []any) (any, error) {
+			a := NewArgs(args)
+			c := Resource[*counter](a, 0)
+			if a.Err() != nil {
+				return nil, a.Err()
+			}
+			return c.value, nil
+		})
+	instance := instantiate(t, test.Counter(), linker)
+
+	result, err := instance.Func("run").Call(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint32(42), result)
+	require.Equal(t, []any{&counter{value: 42}}, dropped)
+}
+
+func TestInstantiateUnsupportedImports(t *testing.T) {
+	// functions of instances the host doesn't provide trap when called
+	instance := instantiate(t, test.Greet("world"), NewLinker())
+	_, err := instance.Func("run").Call(context.Background())
+	require.ErrorContains(t, err, "function greet of test:host/api@0.1.0 is not supported by the host")
+}
+
+func TestInstantiateIncompatibleVersions(t *testing.T) {
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer func() { _ = runtime.Close(ctx) }()
+
+	linker := NewLinker()
+	linker.Instance("test:host/api@0.2.0")
+	c, err := Decode(test.Greet("world"))
+	require.NoError(t, err)
+	_, err = Instantiate(ctx, c, InstantiateParams{Runtime: runtime, Linker: linker, Name: "greet"})
+	require.ErrorContains(t, err, "the host provides version 0.2.0")
+}
+
+func TestCompatibleVersions(t *testing.T) {
+	testCases := []struct {
+		a, b       string
+		compatible bool
+	}{
+		{"0.2.0", "0.2.3", true},
+		{"0.2.0", "0.3.0", false},
+		{"1.0.0", "1.4.0", true},
+		{"1.0.0", "2.0.0", false},
+		{"", "0.2.0", true},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.compatible, compatibleVersions(tc.a, tc.b), "%s and %s", tc.a, tc.b)
+	}
+}
+
+func TestInstanceFunc(t *testing.T) {
+	linker := NewLinker()
+	instance := instantiate(t, test.StdoutCommand("hello"), linker)
+	require.NotNil(t, instance.Func("wasi:cli/run@0.2.0#run"))
+	require.NotNil(t, instance.Func("wasi:cli/run@0.2.1#run"))
+	require.Nil(t, instance.Func("wasi:cli/run@0.3.0#run"))
+	require.Nil(t, instance.Func("run"))
+}
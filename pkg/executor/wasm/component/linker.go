@@ -0,0 +1,100 @@
+package component
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// HostFunc implements a function that components import. It is called with the arguments of the call as
+// component values, and returns its result, or nil if the function has no result. Returning an error traps
+// the component.
+type HostFunc func(ctx context.Context, args []any) (any, error)
+
+// HostInstance is an instance implemented by the host, such as a WASI interface, that components import
+// by name. The types of its functions are those the component imports them with.
+type HostInstance struct {
+	name      string
+	version   string
+	funcs     map[string]HostFunc
+	resources map[string]*ResourceType
+}
+
+// Func defines a function of the instance, such as "get-stdout" or "[method]output-stream.write"
+func (i *HostInstance) Func(name string, fn HostFunc) *HostInstance {
+	i.funcs[name] = fn
+	return i
+}
+
+// Resource defines a resource type of the instance, such as "output-stream"
+func (i *HostInstance) Resource(name string, resource *ResourceType) *HostInstance {
+	i.resources[name] = resource
+	return i
+}
+
+// Linker holds the host instances that components can import
+type Linker struct {
+	instances map[string]*HostInstance
+}
+
+// NewLinker creates a linker without host instances
+func NewLinker() *Linker {
+	return &Linker{instances: make(map[string]*HostInstance)}
+}
+
+// Instance returns the host instance with the name, such as "wasi:cli/stdout@0.2.0", defining it if needed.
+// Components can import the instance with any version compatible with the version of the name.
+func (l *Linker) Instance(name string) *HostInstance {
+	unversioned, version := splitVersion(name)
+	instance, ok := l.instances[unversioned]
+	if !ok {
+		instance = &HostInstance{
+			name:      unversioned,
+			version:   version,
+			funcs:     make(map[string]HostFunc),
+			resources: make(map[string]*ResourceType),
+		}
+		l.instances[unversioned] = instance
+	}
+	return instance
+}
+
+// resolve returns the host instance satisfying an import. Components often import interfaces they never
+// use, such as the sockets of WASI, so instances the host doesn't provide resolve to an empty instance
+// whose functions trap when called.
+func (l *Linker) resolve(name string) (*HostInstance, error) {
+	unversioned, version := splitVersion(name)
+	instance, ok := l.instances[unversioned]
+	if !ok {
+		return &HostInstance{name: unversioned, funcs: map[string]HostFunc{}, resources: map[string]*ResourceType{}}, nil
+	}
+	if !compatibleVersions(instance.version, version) {
+		return nil, fmt.Errorf("component imports %s, but the host provides version %s", name, instance.version)
+	}
+	return instance, nil
+}
+
+// splitVersion splits an import or export name, such as "wasi:cli/stdout@0.2.0", from its version
+func splitVersion(name string) (string, string) {
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// compatibleVersions returns true if the semantic versions are compatible, in which case the host can
+// provide one when the other is imported. Names without a version are compatible with any version.
+func compatibleVersions(a, b string) bool {
+	if a == "" || b == "" {
+		return true
+	}
+	aParts, bParts := strings.SplitN(a, ".", 3), strings.SplitN(b, ".", 3)
+	if aParts[0] != bParts[0] {
+		return false
+	}
+	// before 1.0.0, minor versions are not compatible with each other
+	if aParts[0] == "0" {
+		return len(aParts) > 1 && len(bParts) > 1 && aParts[1] == bParts[1]
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package component
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ResourceType is a resource type of the component model. Resources are passed between components and
+// the host as handles, which index the handle table of the component instance.
+//
+// Host resources are implemented by the host, and represented by a Go value. Guest resources are
+// defined by the component, and represented by a 32-bit integer.
+type ResourceType struct {
+	name string
+	// drop releases the representation of a host resource when its last handle is dropped
+	drop func(ctx context.Context, rep any)
+	// dtor destroys the representation of a guest resource when its last handle is dropped, if set
+	dtor func(ctx context.Context, rep uint32) error
+	host bool
+}
+
+// NewHostResource creates a resource type implemented by the host. Drop is called with the representation
+// of the resource when the component drops it, and may be nil.
+func NewHostResource(name string, drop func(ctx context.Context, rep any)) *ResourceType {
+	return &ResourceType{name: name, drop: drop, host: true}
+}
+
+func newGuestResource(dtor func(ctx context.Context, rep uint32) error) *ResourceType {
+	return &ResourceType{name: "guest resource", dtor: dtor}
+}
+
+func (r *ResourceType) String() string {
+	return r.name
+}
+
+type handle struct {
+	resource *ResourceType
+	rep      any
+}
+
+// handleTable holds the resource handles of a component instance. Handle 0 is never used, so that
+// components can use it as a sentinel.
+type handleTable struct {
+	mu      sync.Mutex
+	handles []*handle
+	free    []uint32
+}
+
+func newHandleTable() *handleTable {
+	return &handleTable{handles: []*handle{nil}}
+}
+
+// add adds a handle to the resource and returns its index
+func (t *handleTable) add(resource *ResourceType, rep any) uint32 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := &handle{resource: resource, rep: rep}
+	if n := len(t.free); n > 0 {
+		index := t.free[n-1]
+		t.free = t.free[:n-1]
+		t.handles[index] = h
+		return index
+	}
+	t.handles = append(t.handles, h)
+	return uint32(len(t.handles) - 1) //nolint:gosec // G115: the table is smaller than 2^32
+}
+
+// get returns the representation of the resource of the handle
+func (t *handleTable) get(resource *ResourceType, index uint32) (any, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, err := t.lookup(resource, index)
+	if err != nil {
+		return nil, err
+	}
+	return h.rep, nil
+}
+
+// remove removes the handle and returns the representation of its resource
+func (t *handleTable) remove(resource *ResourceType, index uint32) (any, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, err := t.lookup(resource, index)
+	if err != nil {
+		return nil, err
+	}
+	t.handles[index] = nil
+	t.free = append(t.free, index)
+	return h.rep, nil
+}
+
+// lookup returns the handle at the index. It must be called with mu held.
+func (t *handleTable) lookup(resource *ResourceType, index uint32) (*handle, error) {
+	if index >= uint32(len(t.handles)) || t.handles[index] == nil { //nolint:gosec // G115: the table is smaller than 2^32
+		return nil, fmt.Errorf("unknown resource handle %d", index)
+	}
+	h := t.handles[index]
+	if h.resource != resource {
+		return nil, fmt.Errorf("resource handle %d is a %s, not a %s", index, h.resource, resource)
+	}
+	return h, nil
+}
+
+// drain removes all the handles, returning those of host resources so that they can be dropped
+func (t *handleTable) drain() []*handle {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var handles []*handle
+	for _, h := range t.handles {
+		if h != nil && h.resource.host {
+			handles = append(handles, h)
+		}
+	}
+	t.handles = []*handle{nil}
+	t.free = nil
+	return handles
+}
@@ -0,0 +1,166 @@
+package test
+
+// Sorts of component definitions
+const (
+	SortCoreFunc   = 0x00
+	SortCoreTable  = 0x01
+	SortCoreMemory = 0x02
+	SortFunc       = 0x01
+	SortType       = 0x03
+	SortInstance   = 0x05
+)
+
+// Primitive value types of components
+const (
+	U8     = 0x7d
+	U32Val = 0x79
+	String = 0x73
+)
+
+// InstantiateCore encodes the instantiation of a core module with the arguments
+func InstantiateCore(module uint32, args ...[]byte) []byte {
+	return Bytes([]byte{0x00}, U32(module), Vec(args...))
+}
+
+// Arg encodes a core instance passed to the instantiation of a core module
+func Arg(name string, instance uint32) []byte {
+	return Bytes(Name(name), []byte{0x12}, U32(instance))
+}
+
+// CoreInlineInstance encodes a core instance of the exports
+func CoreInlineInstance(exports ...[]byte) []byte {
+	return Bytes([]byte{0x01}, Vec(exports...))
+}
+
+// CoreExport encodes an export of a core inline instance
+func CoreExport(name string, sort byte, index uint32) []byte {
+	return Bytes(Name(name), []byte{sort}, U32(index))
+}
+
+// InlineInstance encodes a component instance of the exports
+func InlineInstance(exports ...[]byte) []byte {
+	return Bytes([]byte{0x01}, Vec(exports...))
+}
+
+// InlineExport encodes an export of an inline instance
+func InlineExport(name string, sort byte, index uint32) []byte {
+	return Bytes(ExternName(name), []byte{sort}, U32(index))
+}
+
+// AliasCoreExport encodes an alias of an export of a core instance
+func AliasCoreExport(sort byte, instance uint32, name string) []byte {
+	return Bytes([]byte{0x00, sort, 0x01}, U32(instance), Name(name))
+}
+
+// AliasExport encodes an alias of an export of a component instance
+func AliasExport(sort byte, instance uint32, name string) []byte {
+	return Bytes([]byte{sort, 0x00}, U32(instance), Name(name))
+}
+
+// AliasOuter encodes an alias of a type of an enclosing component or type
+func AliasOuter(count, index uint32) []byte {
+	return Bytes([]byte{SortType, 0x02}, U32(count), U32(index))
+}
+
+// Lift encodes the lifting of a core function to a component function of the type
+func Lift(coreFunc, typ uint32, options ...[]byte) []byte {
+	return Bytes([]byte{0x00, 0x00}, U32(coreFunc), Vec(options...), U32(typ))
+}
+
+// Lower encodes the lowering of a component function to a core function
+func Lower(fn uint32, options ...[]byte) []byte {
+	return Bytes([]byte{0x01, 0x00}, U32(fn), Vec(options...))
+}
+
+// ResourceDrop encodes the resource.drop built-in of the resource type
+func ResourceDrop(typ uint32) []byte {
+	return Bytes([]byte{0x03}, U32(typ))
+}
+
+// Memory encodes the memory option of lifts and lowers
+func Memory(index uint32) []byte {
+	return Bytes([]byte{0x03}, U32(index))
+}
+
+// ReallocOption encodes the realloc option of lifts and lowers
+func ReallocOption(index uint32) []byte {
+	return Bytes([]byte{0x04}, U32(index))
+}
+
+// InstanceType encodes an instance type with the declarations
+func InstanceType(decls ...[]byte) []byte {
+	return Bytes([]byte{0x42}, Vec(decls...))
+}
+
+// TypeDecl encodes a type declaration of an instance type
+func TypeDecl(typ []byte) []byte {
+	return Bytes([]byte{0x01}, typ)
+}
+
+// AliasDecl encodes an alias declaration of an instance type
+func AliasDecl(alias []byte) []byte {
+	return Bytes([]byte{0x02}, alias)
+}
+
+// ExportDecl encodes an export declaration of an instance type
+func ExportDecl(name string, desc []byte) []byte {
+	return Bytes([]byte{0x04}, ExternName(name), desc)
+}
+
+// SubResource describes a fresh resource type
+func SubResource() []byte {
+	return []byte{SortType, 0x01}
+}
+
+// TypeEq describes a type equal to the type
+func TypeEq(index uint32) []byte {
+	return Bytes([]byte{SortType, 0x00}, U32(index))
+}
+
+// FuncDesc describes a function of the type
+func FuncDesc(typ uint32) []byte {
+	return Bytes([]byte{SortFunc}, U32(typ))
+}
+
+// InstanceDesc describes an instance of the type
+func InstanceDesc(typ uint32) []byte {
+	return Bytes([]byte{SortInstance}, U32(typ))
+}
+
+// Import encodes an import of the component
+func Import(name string, desc []byte) []byte {
+	return Bytes(ExternName(name), desc)
+}
+
+// Export encodes an export of the component without type ascription
+func Export(name string, sort byte, index uint32) []byte {
+	return Bytes(ExternName(name), []byte{sort}, U32(index), []byte{0x00})
+}
+
+// Own encodes the type of owned handles of the resource type
+func Own(resource uint32) []byte {
+	return Bytes([]byte{0x69}, U32(resource))
+}
+
+// Borrow encodes the type of borrowed handles of the resource type
+func Borrow(resource uint32) []byte {
+	return Bytes([]byte{0x68}, U32(resource))
+}
+
+// List encodes the type of lists of the value type
+func List(elem byte) []byte {
+	return []byte{0x70, elem}
+}
+
+// Param encodes a parameter of a function type
+func Param(name string, valType byte) []byte {
+	return Bytes(Name(name), []byte{valType})
+}
+
+// Func encodes a function type with the parameters and result, or without result if result is nil
+func Func(result []byte, params ...[]byte) []byte {
+	if result == nil {
+		return Bytes([]byte{0x40}, Vec(params...), []byte{0x01, 0x00})
+	}
+	return Bytes([]byte{0x40}, Vec(params...), []byte{0x00}, result)
+}
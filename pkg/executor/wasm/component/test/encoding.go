@@ -0,0 +1,179 @@
+// Package test encodes the WebAssembly components and core modules used to test the component runtime,
+// which can't be built by the toolchains available to tests.
+package test
+
+// Section IDs of core modules
+const (
+	CoreSectionType     = 1
+	CoreSectionImport   = 2
+	CoreSectionFunction = 3
+	CoreSectionTable    = 4
+	CoreSectionMemory   = 5
+	CoreSectionGlobal   = 6
+	CoreSectionExport   = 7
+	CoreSectionElement  = 9
+	CoreSectionCode     = 10
+	CoreSectionData     = 11
+)
+
+// Section IDs of components
+const (
+	SectionCoreModule   = 1
+	SectionCoreInstance = 2
+	SectionInstance     = 5
+	SectionAlias        = 6
+	SectionType         = 7
+	SectionCanon        = 8
+	SectionImport       = 10
+	SectionExport       = 11
+)
+
+// Core value types
+const (
+	I32 = 0x7f
+	I64 = 0x7e
+)
+
+// U32 encodes an unsigned LEB128 integer
+func U32(v uint32) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+// Name encodes a name
+func Name(name string) []byte {
+	return append(U32(uint32(len(name))), name...) //nolint:gosec // G115: test names are short
+}
+
+// ExternName encodes the name of an import or export of a component
+func ExternName(name string) []byte {
+	return append([]byte{0x00}, Name(name)...)
+}
+
+// Bytes concatenates byte slices
+func Bytes(parts ...[]byte) []byte {
+	var out []byte
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
+
+// Vec encodes a vector of items
+func Vec(items ...[]byte) []byte {
+	return append(U32(uint32(len(items))), Bytes(items...)...) //nolint:gosec // G115: test vectors are short
+}
+
+// Section encodes a section with the contents
+func Section(id byte, contents ...[]byte) []byte {
+	body := Bytes(contents...)
+	return Bytes([]byte{id}, U32(uint32(len(body))), body) //nolint:gosec // G115: test sections are small
+}
+
+// Module encodes a core module with the sections
+func Module(sections ...[]byte) []byte {
+	return Bytes([]byte("\x00asm\x01\x00\x00\x00"), Bytes(sections...))
+}
+
+// Component encodes a component with the sections
+func Component(sections ...[]byte) []byte {
+	return Bytes([]byte("\x00asm\x0d\x00\x01\x00"), Bytes(sections...))
+}
+
+// FuncType encodes a core function type
+func FuncType(params, results []byte) []byte {
+	return Bytes([]byte{0x60}, U32(uint32(len(params))), params, U32(uint32(len(results))), results) //nolint:gosec
+}
+
+// Code encodes the body of a core function without locals from its instructions
+func Code(instructions ...[]byte) []byte {
+	body := Bytes([]byte{0x00}, Bytes(instructions...), []byte{0x0b})
+	return append(U32(uint32(len(body))), body...) //nolint:gosec // G115: test functions are small
+}
+
+// CodeWithLocals encodes the body of a core function with count locals of the type
+func CodeWithLocals(count uint32, valType byte, instructions ...[]byte) []byte {
+	body := Bytes([]byte{0x01}, U32(count), []byte{valType}, Bytes(instructions...), []byte{0x0b})
+	return append(U32(uint32(len(body))), body...) //nolint:gosec // G115: test functions are small
+}
+
+// I32Const encodes an i32.const instruction
+func I32Const(v int32) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append([]byte{0x41}, append(out, b)...)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+// Call encodes a call instruction
+func Call(index uint32) []byte {
+	return append([]byte{0x10}, U32(index)...)
+}
+
+// LocalGet encodes a local.get instruction
+func LocalGet(index uint32) []byte {
+	return append([]byte{0x20}, U32(index)...)
+}
+
+// LocalSet encodes a local.set instruction
+func LocalSet(index uint32) []byte {
+	return append([]byte{0x21}, U32(index)...)
+}
+
+// Shim encodes the core module that components use to import functions into the module defining their
+// memory: it exports a function "0" of the type that calls the function of the table "$imports", which
+// the Fixup module fills once the memory is defined.
+func Shim(params []byte) []byte {
+	call := make([][]byte, 0, len(params)+2)
+	for i := range params {
+		call = append(call, LocalGet(uint32(i))) //nolint:gosec // G115: test functions have few parameters
+	}
+	call = append(call, I32Const(0), []byte{0x11, 0x00, 0x00})
+	return Module(
+		Section(CoreSectionType, Vec(FuncType(params, nil))),
+		Section(CoreSectionFunction, Vec(U32(0))),
+		Section(CoreSectionTable, Vec([]byte{0x70, 0x00, 0x01})),
+		Section(CoreSectionExport, Vec(
+			Bytes(Name("0"), []byte{0x00, 0x00}),
+			Bytes(Name("$imports"), []byte{0x01, 0x00}),
+		)),
+		Section(CoreSectionCode, Vec(Code(call...))),
+	)
+}
+
+// Fixup encodes the core module that fills the table of the Shim module with the function "0"
+func Fixup(params []byte) []byte {
+	return Module(
+		Section(CoreSectionType, Vec(FuncType(params, nil))),
+		Section(CoreSectionImport, Vec(
+			Bytes(Name(""), Name("0"), []byte{0x00, 0x00}),
+			Bytes(Name(""), Name("$imports"), []byte{0x01, 0x70, 0x00, 0x01}),
+		)),
+		Section(CoreSectionElement, Vec(Bytes([]byte{0x00}, I32Const(0), []byte{0x0b}, Vec(U32(0))))),
+	)
+}
+
+// Realloc encodes the body of a bump allocator, which allocates from the i32 global 0
+func Realloc() []byte {
+	return Code(
+		// align the global to the alignment parameter
+		[]byte{0x23, 0x00}, LocalGet(2), []byte{0x6a}, I32Const(1), []byte{0x6b},
+		I32Const(0), LocalGet(2), []byte{0x6b}, []byte{0x71},
+		[]byte{0x22, 0x00},
+		// bump the global by the size parameter
+		LocalGet(3), []byte{0x6a}, []byte{0x24, 0x00},
+		LocalGet(0),
+	)
+}
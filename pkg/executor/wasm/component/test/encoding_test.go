@@ -0,0 +1,64 @@
+//go:build unit || !integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+)
+
+func TestU32(t *testing.T) {
+	require.Equal(t, []byte{0x00}, U32(0))
+	require.Equal(t, []byte{0x7f}, U32(127))
+	require.Equal(t, []byte{0x80, 0x01}, U32(128))
+	require.Equal(t, []byte{0xe5, 0x8e, 0x26}, U32(624485))
+}
+
+func TestI32Const(t *testing.T) {
+	require.Equal(t, []byte{0x41, 0x00}, I32Const(0))
+	require.Equal(t, []byte{0x41, 0x3f}, I32Const(63))
+	require.Equal(t, []byte{0x41, 0xc0, 0x00}, I32Const(64))
+	require.Equal(t, []byte{0x41, 0x7f}, I32Const(-1))
+	require.Equal(t, []byte{0x41, 0x80, 0x02}, I32Const(256))
+}
+
+func TestCoreModulesCompile(t *testing.T) {
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer func() { _ = runtime.Close(ctx) }()
+
+	// the fixup module imports from the module named "", which wazero only compiles once the component
+	// runtime renames its imports
+	params := []byte{I32, I32, I32}
+	modules := map[string][]byte{
+		"shim": Shim(params),
+		"main": mainModule([][2]string{{"host", "greet"}}, [][]byte{FuncType(params, nil)},
+			FuncType(nil, []byte{I32}), Code(I32Const(0)), "data"),
+	}
+	for name, binary := range modules {
+		_, err := runtime.CompileModule(ctx, binary)
+		require.NoError(t, err, name)
+	}
+}
+
+func TestRealloc(t *testing.T) {
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer func() { _ = runtime.Close(ctx) }()
+
+	binary := mainModule(nil, nil, FuncType(nil, []byte{I32}), Code(I32Const(0)), "")
+	module, err := runtime.Instantiate(ctx, binary)
+	require.NoError(t, err)
+	realloc := module.ExportedFunction("realloc")
+
+	// allocations start at 1024 and are aligned
+	results, err := realloc.Call(ctx, 0, 0, 1, 3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1024), results[0])
+	results, err = realloc.Call(ctx, 0, 0, 8, 4)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1032), results[0])
+}
@@ -0,0 +1,206 @@
+package test
+
+// dataOffset is the address of the data of the main modules of the fixtures
+const dataOffset = 16
+
+// resultOffset is the address at which the main modules of the fixtures have results stored
+const resultOffset = 256
+
+// mainModule encodes the core module of a component that defines its memory. It imports the functions
+// of the types from the "host" module, and defines a realloc function and a run function with the body.
+// The data is stored at dataOffset.
+func mainModule(imports [][2]string, importTypes [][]byte, runType []byte, run []byte, data string) []byte {
+	types := append([][]byte{
+		FuncType([]byte{I32, I32, I32, I32}, []byte{I32}),
+		runType,
+	}, importTypes...)
+	importEntries := make([][]byte, len(imports))
+	for i, imp := range imports {
+		typ := uint32(i + 2) //nolint:gosec // G115: fixtures have few imports
+		importEntries[i] = Bytes(Name(imp[0]), Name(imp[1]), []byte{0x00}, U32(typ))
+	}
+	funcs := uint32(len(imports)) //nolint:gosec // G115: fixtures have few imports
+	sections := [][]byte{
+		Section(CoreSectionType, Vec(types...)),
+		Section(CoreSectionImport, Vec(importEntries...)),
+		Section(CoreSectionFunction, Vec(U32(0), U32(1))),
+		Section(CoreSectionMemory, Vec([]byte{0x00, 0x01})),
+		Section(CoreSectionGlobal, Vec(Bytes([]byte{I32, 0x01}, I32Const(1024), []byte{0x0b}))),
+		Section(CoreSectionExport, Vec(
+			Bytes(Name("memory"), []byte{SortCoreMemory, 0x00}),
+			Bytes(Name("realloc"), []byte{SortCoreFunc}, U32(funcs)),
+			Bytes(Name("run"), []byte{SortCoreFunc}, U32(funcs+1)),
+		)),
+		Section(CoreSectionCode, Vec(Realloc(), run)),
+	}
+	if data != "" {
+		sections = append(sections, Section(CoreSectionData, Vec(Bytes([]byte{0x00}, I32Const(dataOffset), []byte{0x0b}, Name(data)))))
+	}
+	return Module(sections...)
+}
+
+// Greet encodes a component that imports the function greet(name: string) -> string of the instance
+// "test:host/api@0.1.0", and exports a function run() -> string that returns the greeting of the name.
+func Greet(name string) []byte {
+	run := Code(I32Const(dataOffset), I32Const(int32(len(name))), I32Const(resultOffset), Call(0), //nolint:gosec
+		I32Const(resultOffset))
+	params := []byte{I32, I32, I32}
+	return Component(
+		Section(SectionType, Vec(
+			InstanceType(
+				TypeDecl(Func([]byte{String}, Param("name", String))),
+				ExportDecl("greet", FuncDesc(0)),
+			),
+		)),
+		Section(SectionImport, Vec(Import("test:host/api@0.1.0", InstanceDesc(0)))),
+		Section(SectionAlias, Vec(AliasExport(SortFunc, 0, "greet"))),
+		Section(SectionCoreModule, Shim(params)),
+		Section(SectionCoreModule, mainModule(
+			[][2]string{{"host", "greet"}}, [][]byte{FuncType(params, nil)},
+			FuncType(nil, []byte{I32}), run, name)),
+		Section(SectionCoreModule, Fixup(params)),
+		// core instance 0 is the shim, 1 the imports of the main module, 2 the main module
+		Section(SectionCoreInstance, Vec(InstantiateCore(0))),
+		Section(SectionAlias, Vec(AliasCoreExport(SortCoreFunc, 0, "0"))),
+		Section(SectionCoreInstance, Vec(
+			CoreInlineInstance(CoreExport("greet", SortCoreFunc, 0)),
+			InstantiateCore(1, Arg("host", 1)),
+		)),
+		Section(SectionAlias, Vec(
+			AliasCoreExport(SortCoreMemory, 2, "memory"),
+			AliasCoreExport(SortCoreFunc, 2, "realloc"),
+		)),
+		Section(SectionCanon, Vec(Lower(0, Memory(0), ReallocOption(1)))),
+		Section(SectionAlias, Vec(AliasCoreExport(SortCoreTable, 0, "$imports"))),
+		// core instance 3 fills the table of the shim, which instance 4 fixes up
+		Section(SectionCoreInstance, Vec(
+			CoreInlineInstance(CoreExport("0", SortCoreFunc, 2), CoreExport("$imports", SortCoreTable, 0)),
+			InstantiateCore(2, Arg("", 3)),
+		)),
+		Section(SectionType, Vec(Func([]byte{String}))),
+		Section(SectionAlias, Vec(AliasCoreExport(SortCoreFunc, 2, "run"))),
+		Section(SectionCanon, Vec(Lift(3, 1, Memory(0), ReallocOption(1)))),
+		Section(SectionExport, Vec(Export("run", SortFunc, 1))),
+	)
+}
+
+// Counter encodes a component that imports the resource "counter" of the instance "test:host/counter@0.1.0",
+// with the functions make() -> counter and [method]counter.get() -> u32. It exports a function run() -> u32
+// that makes a counter, gets its value and drops it.
+func Counter() []byte {
+	run := CodeWithLocals(1, I32, Call(0), LocalSet(0), LocalGet(0), Call(1), LocalGet(0), Call(2))
+	return Component(
+		Section(SectionType, Vec(
+			InstanceType(
+				ExportDecl("counter", SubResource()),
+				TypeDecl(Own(0)),
+				TypeDecl(Borrow(0)),
+				TypeDecl(Func(U32(1))),
+				TypeDecl(Func([]byte{U32Val}, Param("self", 2))),
+				ExportDecl("make", FuncDesc(3)),
+				ExportDecl("[method]counter.get", FuncDesc(4)),
+			),
+		)),
+		Section(SectionImport, Vec(Import("test:host/counter@0.1.0", InstanceDesc(0)))),
+		Section(SectionAlias, Vec(
+			AliasExport(SortType, 0, "counter"),
+			AliasExport(SortFunc, 0, "make"),
+			AliasExport(SortFunc, 0, "[method]counter.get"),
+		)),
+		Section(SectionCanon, Vec(Lower(0), Lower(1), ResourceDrop(1))),
+		Section(SectionCoreModule, mainModule(
+			[][2]string{{"host", "make"}, {"host", "get"}, {"host", "drop"}},
+			[][]byte{FuncType(nil, []byte{I32}), FuncType([]byte{I32}, []byte{I32}), FuncType([]byte{I32}, nil)},
+			FuncType(nil, []byte{I32}), run, "")),
+		Section(SectionCoreInstance, Vec(
+			CoreInlineInstance(
+				CoreExport("make", SortCoreFunc, 0),
+				CoreExport("get", SortCoreFunc, 1),
+				CoreExport("drop", SortCoreFunc, 2),
+			),
+			InstantiateCore(0, Arg("host", 0)),
+		)),
+		Section(SectionType, Vec(Func([]byte{U32Val}))),
+		Section(SectionAlias, Vec(AliasCoreExport(SortCoreFunc, 1, "run"))),
+		Section(SectionCanon, Vec(Lift(3, 2))),
+		Section(SectionExport, Vec(Export("run", SortFunc, 2))),
+	)
+}
+
+// StdoutCommand encodes a WASI 0.2 command component that writes the message to its stdout
+func StdoutCommand(message string) []byte {
+	run := CodeWithLocals(1, I32,
+		Call(0), LocalSet(0),
+		LocalGet(0), I32Const(dataOffset), I32Const(int32(len(message))), I32Const(resultOffset), Call(1), //nolint:gosec
+		LocalGet(0), Call(2),
+		I32Const(0))
+	params := []byte{I32, I32, I32, I32}
+	streamError := Bytes([]byte{0x71}, Vec(
+		Bytes(Name("last-operation-failed"), []byte{0x01, 0x02, 0x00}),
+		Bytes(Name("closed"), []byte{0x00, 0x00}),
+	))
+	return Component(
+		Section(SectionType, Vec(InstanceType(ExportDecl("error", SubResource())))),
+		Section(SectionImport, Vec(Import("wasi:io/error@0.2.0", InstanceDesc(0)))),
+		Section(SectionAlias, Vec(AliasExport(SortType, 0, "error"))),
+		Section(SectionType, Vec(InstanceType(
+			AliasDecl(AliasOuter(1, 1)),
+			ExportDecl("error", TypeEq(0)),
+			TypeDecl(Own(1)),
+			TypeDecl(streamError),
+			ExportDecl("stream-error", TypeEq(3)),
+			ExportDecl("output-stream", SubResource()),
+			TypeDecl(Borrow(5)),
+			TypeDecl(List(U8)),
+			TypeDecl([]byte{0x6a, 0x00, 0x01, 0x04}),
+			TypeDecl(Func([]byte{8}, Param("self", 6), Param("contents", 7))),
+			ExportDecl("[method]output-stream.blocking-write-and-flush", FuncDesc(9)),
+		))),
+		Section(SectionImport, Vec(Import("wasi:io/streams@0.2.0", InstanceDesc(2)))),
+		Section(SectionAlias, Vec(AliasExport(SortType, 1, "output-stream"))),
+		Section(SectionType, Vec(InstanceType(
+			AliasDecl(AliasOuter(1, 3)),
+			ExportDecl("output-stream", TypeEq(0)),
+			TypeDecl(Own(1)),
+			TypeDecl(Func(U32(2))),
+			ExportDecl("get-stdout", FuncDesc(3)),
+		))),
+		Section(SectionImport, Vec(Import("wasi:cli/stdout@0.2.0", InstanceDesc(4)))),
+		Section(SectionAlias, Vec(
+			AliasExport(SortFunc, 2, "get-stdout"),
+			AliasExport(SortFunc, 1, "[method]output-stream.blocking-write-and-flush"),
+		)),
+		Section(SectionCoreModule, Shim(params)),
+		Section(SectionCoreModule, mainModule(
+			[][2]string{{"host", "get-stdout"}, {"host", "write"}, {"host", "drop"}},
+			[][]byte{FuncType(nil, []byte{I32}), FuncType(params, nil), FuncType([]byte{I32}, nil)},
+			FuncType(nil, []byte{I32}), run, message)),
+		Section(SectionCoreModule, Fixup(params)),
+		Section(SectionCoreInstance, Vec(InstantiateCore(0))),
+		Section(SectionAlias, Vec(AliasCoreExport(SortCoreFunc, 0, "0"))),
+		Section(SectionCanon, Vec(Lower(0), ResourceDrop(3))),
+		Section(SectionCoreInstance, Vec(
+			CoreInlineInstance(
+				CoreExport("get-stdout", SortCoreFunc, 1),
+				CoreExport("write", SortCoreFunc, 0),
+				CoreExport("drop", SortCoreFunc, 2),
+			),
+			InstantiateCore(1, Arg("host", 1)),
+		)),
+		Section(SectionAlias, Vec(
+			AliasCoreExport(SortCoreMemory, 2, "memory"),
+			AliasCoreExport(SortCoreFunc, 2, "realloc"),
+		)),
+		Section(SectionCanon, Vec(Lower(1, Memory(0), ReallocOption(3)))),
+		Section(SectionAlias, Vec(AliasCoreExport(SortCoreTable, 0, "$imports"))),
+		Section(SectionCoreInstance, Vec(
+			CoreInlineInstance(CoreExport("0", SortCoreFunc, 4), CoreExport("$imports", SortCoreTable, 0)),
+			InstantiateCore(2, Arg("", 3)),
+		)),
+		Section(SectionType, Vec([]byte{0x6a, 0x00, 0x00}, Func(U32(5)))),
+		Section(SectionAlias, Vec(AliasCoreExport(SortCoreFunc, 2, "run"))),
+		Section(SectionCanon, Vec(Lift(5, 6))),
+		Section(SectionInstance, Vec(InlineInstance(InlineExport("run", SortFunc, 2)))),
+		Section(SectionExport, Vec(Export("wasi:cli/run@0.2.0", SortInstance, 3))),
+	)
+}
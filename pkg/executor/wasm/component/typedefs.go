@@ -0,0 +1,550 @@
+package component
+
+import (
+	"errors"
+	"fmt"
+)
+
+// typeExpr is a decoded type definition, which is evaluated into a type when the component is instantiated
+type typeExpr any
+
+// valTypeRef refers to a primitive type, or to a type by its index
+type valTypeRef struct {
+	prim  Kind
+	index uint32
+}
+
+type namedValType struct {
+	name string
+	typ  valTypeRef
+}
+
+type caseExpr struct {
+	name string
+	typ  *valTypeRef
+}
+
+type defValTypeExpr struct {
+	kind Kind
+	// elem is the element type of lists and options
+	elem valTypeRef
+	// fields of records and tuples
+	fields []namedValType
+	// cases of variants
+	cases []caseExpr
+	// labels of enums and flags
+	labels []string
+	// ok and err types of results
+	ok, err *valTypeRef
+	// resource of own and borrow handles
+	resource uint32
+}
+
+type funcTypeExpr struct {
+	params []namedValType
+	result *valTypeRef
+}
+
+type exportDecl struct {
+	name string
+	desc externDesc
+}
+
+type instanceTypeExpr struct {
+	// decls are the coreTypeDef, typeDef, aliasDef and exportDecl declarations of the instance type
+	decls []any
+}
+
+type componentTypeExpr struct{}
+
+type resourceTypeExpr struct {
+	dtor *uint32
+}
+
+var primitiveCodes = map[byte]Kind{
+	0x7f: KindBool, 0x7e: KindS8, 0x7d: KindU8, 0x7c: KindS16, 0x7b: KindU16, 0x7a: KindS32, 0x79: KindU32,
+	0x78: KindS64, 0x77: KindU64, 0x76: KindF32, 0x75: KindF64, 0x74: KindChar, 0x73: KindString,
+}
+
+// decodeTypeExpr decodes a type definition of the type section or of an instance or component type
+func decodeTypeExpr(r *reader) (typeExpr, error) {
+	if r.eof() {
+		return nil, errUnexpectedEnd
+	}
+	switch code := r.buf[r.pos]; code {
+	case 0x40:
+		r.pos++
+		return decodeFuncType(r)
+	case 0x41:
+		r.pos++
+		if _, err := decodeDecls(r, true); err != nil {
+			return nil, err
+		}
+		return &componentTypeExpr{}, nil
+	case 0x42:
+		r.pos++
+		decls, err := decodeDecls(r, false)
+		return &instanceTypeExpr{decls: decls}, err
+	case 0x3f:
+		r.pos++
+		if err := r.expect(0x7f); err != nil {
+			return nil, err
+		}
+		expr := new(resourceTypeExpr)
+		hasDtor, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if hasDtor == 0x01 {
+			dtor, err := r.u32()
+			if err != nil {
+				return nil, err
+			}
+			expr.dtor = &dtor
+		}
+		return expr, nil
+	default:
+		return decodeDefValType(r)
+	}
+}
+
+func decodeDefValType(r *reader) (typeExpr, error) {
+	code, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	if kind, ok := primitiveCodes[code]; ok {
+		return &defValTypeExpr{kind: kind}, nil
+	}
+
+	expr := new(defValTypeExpr)
+	switch code {
+	case 0x72, 0x6f:
+		expr.kind = KindRecord
+		if code == 0x6f {
+			expr.kind = KindTuple
+		}
+		count, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint32(0); i < count; i++ {
+			var field namedValType
+			if code == 0x72 {
+				if field.name, err = r.name(); err != nil {
+					return nil, err
+				}
+			}
+			if field.typ, err = decodeValType(r); err != nil {
+				return nil, err
+			}
+			expr.fields = append(expr.fields, field)
+		}
+	case 0x71:
+		expr.kind = KindVariant
+		count, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint32(0); i < count; i++ {
+			var c caseExpr
+			if c.name, err = r.name(); err != nil {
+				return nil, err
+			}
+			if c.typ, err = decodeOptionalValType(r); err != nil {
+				return nil, err
+			}
+			// cases used to refine other cases, which is no longer part of the format
+			if err = r.expect(0x00); err != nil {
+				return nil, err
+			}
+			expr.cases = append(expr.cases, c)
+		}
+	case 0x70, 0x6b:
+		expr.kind = KindList
+		if code == 0x6b {
+			expr.kind = KindOption
+		}
+		if expr.elem, err = decodeValType(r); err != nil {
+			return nil, err
+		}
+	case 0x6e, 0x6d:
+		expr.kind = KindFlags
+		if code == 0x6d {
+			expr.kind = KindEnum
+		}
+		count, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint32(0); i < count; i++ {
+			label, err := r.name()
+			if err != nil {
+				return nil, err
+			}
+			expr.labels = append(expr.labels, label)
+		}
+		if expr.kind == KindFlags && len(expr.labels) > maxFlags {
+			return nil, fmt.Errorf("flags with more than %d labels are not supported", maxFlags)
+		}
+	case 0x6a:
+		expr.kind = KindResult
+		if expr.ok, err = decodeOptionalValType(r); err != nil {
+			return nil, err
+		}
+		if expr.err, err = decodeOptionalValType(r); err != nil {
+			return nil, err
+		}
+	case 0x69, 0x68:
+		expr.kind = KindOwn
+		if code == 0x68 {
+			expr.kind = KindBorrow
+		}
+		if expr.resource, err = r.u32(); err != nil {
+			return nil, err
+		}
+	case 0x67, 0x66, 0x65, 0x64:
+		return nil, fmt.Errorf("value type 0x%x is not supported", code)
+	default:
+		return nil, fmt.Errorf("unknown type 0x%x", code)
+	}
+	return expr, nil
+}
+
+func decodeFuncType(r *reader) (*funcTypeExpr, error) {
+	expr := new(funcTypeExpr)
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < count; i++ {
+		var param namedValType
+		if param.name, err = r.name(); err != nil {
+			return nil, err
+		}
+		if param.typ, err = decodeValType(r); err != nil {
+			return nil, err
+		}
+		expr.params = append(expr.params, param)
+	}
+
+	kind, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case 0x00:
+		result, err := decodeValType(r)
+		if err != nil {
+			return nil, err
+		}
+		expr.result = &result
+	case 0x01:
+		// named results were removed from the format, except for an empty list
+		if err = r.expect(0x00); err != nil {
+			return nil, errors.New("functions with named results are not supported")
+		}
+	default:
+		return nil, fmt.Errorf("unknown result list 0x%x", kind)
+	}
+	return expr, nil
+}
+
+func decodeValType(r *reader) (valTypeRef, error) {
+	start := r.pos
+	value, err := r.s33()
+	if err != nil {
+		return valTypeRef{}, err
+	}
+	if value >= 0 {
+		return valTypeRef{index: uint32(value)}, nil //nolint:gosec // G115: s33 indices fit in 32 bits
+	}
+	kind, ok := primitiveCodes[r.buf[start]]
+	if !ok {
+		return valTypeRef{}, fmt.Errorf("unknown value type 0x%x", r.buf[start])
+	}
+	return valTypeRef{prim: kind}, nil
+}
+
+func decodeOptionalValType(r *reader) (*valTypeRef, error) {
+	present, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch present {
+	case 0x00:
+		return nil, nil
+	case 0x01:
+		t, err := decodeValType(r)
+		return &t, err
+	default:
+		return nil, fmt.Errorf("unknown optional value type 0x%x", present)
+	}
+}
+
+// decodeDecls decodes the declarations of an instance type, or of a component type if imports are allowed
+func decodeDecls(r *reader, allowImports bool) ([]any, error) {
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	decls := make([]any, 0, count)
+	for i := uint32(0); i < count; i++ {
+		kind, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		var decl any
+		switch kind {
+		case 0x00:
+			decl, err = &coreTypeDef{}, skipCoreType(r)
+		case 0x01:
+			var expr typeExpr
+			expr, err = decodeTypeExpr(r)
+			decl = &typeDef{expr: expr}
+		case 0x02:
+			decl, err = decodeAlias(r)
+		case 0x03, 0x04:
+			if kind == 0x03 && !allowImports {
+				return nil, errors.New("imports are not allowed in instance types")
+			}
+			export := new(exportDecl)
+			if export.name, err = r.externName(); err != nil {
+				return nil, err
+			}
+			export.desc, err = decodeExternDesc(r)
+			decl = export
+		default:
+			return nil, fmt.Errorf("unknown declaration 0x%x", kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+		decls = append(decls, decl)
+	}
+	return decls, nil
+}
+
+// typeEntry is an entry of a type index space
+type typeEntry struct {
+	val      *Type
+	fn       *FuncType
+	resource *ResourceType
+	// instance is an instance type, which is evaluated when an instance of the type is imported
+	instance *deferredInstanceType
+	// component is set for component types, which are only used to import components
+	component bool
+}
+
+// deferredInstanceType is an instance type with the scope it was defined in
+type deferredInstanceType struct {
+	expr  *instanceTypeExpr
+	scope *typeScope
+}
+
+// typeScope is a type index space, nested in the type index space of an enclosing component or type
+type typeScope struct {
+	parent *typeScope
+	types  []*typeEntry
+	// defineResource creates the resource types the component defines. Resource types cannot be
+	// defined in instance types, where it is nil.
+	defineResource func(expr *resourceTypeExpr) (*ResourceType, error)
+}
+
+func (s *typeScope) get(index uint32) (*typeEntry, error) {
+	if index >= uint32(len(s.types)) { //nolint:gosec // G115: index spaces are smaller than 2^32
+		return nil, fmt.Errorf("type index %d out of bounds", index)
+	}
+	return s.types[index], nil
+}
+
+// outer returns the type of an enclosing scope
+func (s *typeScope) outer(count, index uint32) (*typeEntry, error) {
+	scope := s
+	for i := uint32(0); i < count; i++ {
+		if scope.parent == nil {
+			return nil, fmt.Errorf("outer alias count %d exceeds the enclosing scopes", count)
+		}
+		scope = scope.parent
+	}
+	return scope.get(index)
+}
+
+// valType evaluates a value type reference
+func (s *typeScope) valType(ref valTypeRef) (*Type, error) {
+	if ref.prim != 0 {
+		return Primitive(ref.prim), nil
+	}
+	entry, err := s.get(ref.index)
+	if err != nil {
+		return nil, err
+	}
+	if entry.val == nil {
+		return nil, fmt.Errorf("type %d is not a value type", ref.index)
+	}
+	return entry.val, nil
+}
+
+func (s *typeScope) optionalValType(ref *valTypeRef) (*Type, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	return s.valType(*ref)
+}
+
+// define evaluates a type definition and adds it to the scope
+func (s *typeScope) define(expr typeExpr) error {
+	entry, err := s.eval(expr)
+	if err != nil {
+		return err
+	}
+	s.types = append(s.types, entry)
+	return nil
+}
+
+func (s *typeScope) eval(expr typeExpr) (*typeEntry, error) {
+	switch expr := expr.(type) {
+	case *defValTypeExpr:
+		val, err := s.evalDefValType(expr)
+		return &typeEntry{val: val}, err
+	case *funcTypeExpr:
+		fn := &FuncType{Params: make([]Field, len(expr.params))}
+		for i, param := range expr.params {
+			t, err := s.valType(param.typ)
+			if err != nil {
+				return nil, err
+			}
+			fn.Params[i] = Field{Name: param.name, Type: t}
+		}
+		var err error
+		fn.Result, err = s.optionalValType(expr.result)
+		return &typeEntry{fn: fn}, err
+	case *instanceTypeExpr:
+		return &typeEntry{instance: &deferredInstanceType{expr: expr, scope: s}}, nil
+	case *componentTypeExpr:
+		return &typeEntry{component: true}, nil
+	case *resourceTypeExpr:
+		if s.defineResource == nil {
+			return nil, errors.New("resource types can only be defined by components")
+		}
+		resource, err := s.defineResource(expr)
+		return &typeEntry{resource: resource}, err
+	default:
+		return nil, fmt.Errorf("unknown type definition %T", expr)
+	}
+}
+
+func (s *typeScope) evalDefValType(expr *defValTypeExpr) (*Type, error) {
+	var err error
+	switch expr.kind {
+	case KindRecord, KindTuple:
+		t := &Type{Kind: expr.kind, Fields: make([]Field, len(expr.fields))}
+		for i, field := range expr.fields {
+			if t.Fields[i].Type, err = s.valType(field.typ); err != nil {
+				return nil, err
+			}
+			t.Fields[i].Name = field.name
+		}
+		return t, nil
+	case KindVariant:
+		t := &Type{Kind: KindVariant, Cases: make([]Case, len(expr.cases))}
+		for i, c := range expr.cases {
+			if t.Cases[i].Type, err = s.optionalValType(c.typ); err != nil {
+				return nil, err
+			}
+			t.Cases[i].Name = c.name
+		}
+		return t, nil
+	case KindList:
+		elem, err := s.valType(expr.elem)
+		return List(elem), err
+	case KindOption:
+		elem, err := s.valType(expr.elem)
+		return Option(elem), err
+	case KindEnum:
+		return Enum(expr.labels...), nil
+	case KindFlags:
+		return Flags(expr.labels...), nil
+	case KindResult:
+		ok, err := s.optionalValType(expr.ok)
+		if err != nil {
+			return nil, err
+		}
+		errType, err := s.optionalValType(expr.err)
+		return ResultOf(ok, errType), err
+	case KindOwn, KindBorrow:
+		entry, err := s.get(expr.resource)
+		if err != nil {
+			return nil, err
+		}
+		if entry.resource == nil {
+			return nil, fmt.Errorf("type %d of %s handle is not a resource", expr.resource, expr.kind)
+		}
+		return &Type{Kind: expr.kind, Resource: entry.resource}, nil
+	default:
+		return Primitive(expr.kind), nil
+	}
+}
+
+// instanceType is an evaluated instance type
+type instanceType struct {
+	funcs map[string]*FuncType
+	types map[string]*typeEntry
+}
+
+// evalInstanceType evaluates an instance type when an instance of the type is imported. The resource types
+// the instance exports are bound to the resources of the instance that satisfies the import.
+func evalInstanceType(
+	deferred *deferredInstanceType, bindResource func(name string) (*ResourceType, error)) (*instanceType, error) {
+	scope := &typeScope{parent: deferred.scope}
+	result := &instanceType{funcs: make(map[string]*FuncType), types: make(map[string]*typeEntry)}
+	for _, decl := range deferred.expr.decls {
+		switch decl := decl.(type) {
+		case *coreTypeDef:
+		case *typeDef:
+			if err := scope.define(decl.expr); err != nil {
+				return nil, err
+			}
+		case *aliasDef:
+			if decl.target != aliasOuter || decl.sort.sort != sortType {
+				return nil, errors.New("only outer type aliases are supported in instance types")
+			}
+			entry, err := scope.outer(decl.outerCount, decl.outerIndex)
+			if err != nil {
+				return nil, err
+			}
+			scope.types = append(scope.types, entry)
+		case *exportDecl:
+			switch decl.desc.sort.sort {
+			case sortFunc:
+				entry, err := scope.get(decl.desc.index)
+				if err != nil {
+					return nil, err
+				}
+				if entry.fn == nil {
+					return nil, fmt.Errorf("export %q of instance type is not a function", decl.name)
+				}
+				result.funcs[decl.name] = entry.fn
+			case sortType:
+				var entry *typeEntry
+				if decl.desc.subResource {
+					resource, err := bindResource(decl.name)
+					if err != nil {
+						return nil, err
+					}
+					entry = &typeEntry{resource: resource}
+				} else {
+					var err error
+					if entry, err = scope.get(decl.desc.index); err != nil {
+						return nil, err
+					}
+				}
+				scope.types = append(scope.types, entry)
+				result.types[decl.name] = entry
+			default:
+				return nil, fmt.Errorf("export %q of instance type: %s exports are not supported", decl.name, decl.desc.sort)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,402 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Kind is the kind of a component value type
+type Kind uint8
+
+const (
+	KindBool Kind = iota + 1
+	KindS8
+	KindU8
+	KindS16
+	KindU16
+	KindS32
+	KindU32
+	KindS64
+	KindU64
+	KindF32
+	KindF64
+	KindChar
+	KindString
+	KindList
+	KindRecord
+	KindTuple
+	KindVariant
+	KindEnum
+	KindOption
+	KindResult
+	KindFlags
+	KindOwn
+	KindBorrow
+)
+
+var kindNames = map[Kind]string{
+	KindBool: "bool", KindS8: "s8", KindU8: "u8", KindS16: "s16", KindU16: "u16", KindS32: "s32", KindU32: "u32",
+	KindS64: "s64", KindU64: "u64", KindF32: "f32", KindF64: "f64", KindChar: "char", KindString: "string",
+	KindList: "list", KindRecord: "record", KindTuple: "tuple", KindVariant: "variant", KindEnum: "enum",
+	KindOption: "option", KindResult: "result", KindFlags: "flags", KindOwn: "own", KindBorrow: "borrow",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind(%d)", k)
+}
+
+// maxFlags is the maximum number of flags of a flags type, which are represented as the bits of a uint64
+const maxFlags = 64
+
+// Type is a component value type.
+//
+// Values of component types are represented in Go as follows:
+//   - bool, integers, floats and char as bool, int8 to uint64, float32, float64 and rune
+//   - string as string, list<u8> as []byte and other lists as []any
+//   - records and tuples as []any holding their fields in order
+//   - variants, options and results as Variant, and enums as the uint32 index of their case
+//   - flags as a uint64 with a bit set per flag
+//   - own and borrow handles as the representation of their resource, such as the Go value of a host resource
+type Type struct {
+	Kind Kind
+	// Elem is the element type of lists
+	Elem *Type
+	// Fields are the fields of records and tuples, which are unnamed in tuples
+	Fields []Field
+	// Cases are the cases of variants, enums, options and results. Options have the cases none and some,
+	// and results the cases ok and error.
+	Cases []Case
+	// Labels are the labels of flags
+	Labels []string
+	// Resource is the resource type of own and borrow handles
+	Resource *ResourceType
+}
+
+// Field is a field of a record or tuple, or a parameter of a function
+type Field struct {
+	Name string
+	Type *Type
+}
+
+// Case is a case of a variant, whose type is nil if the case has no payload
+type Case struct {
+	Name string
+	Type *Type
+}
+
+// FuncType is the type of a component function
+type FuncType struct {
+	Params []Field
+	// Result is the result type of the function, or nil if it has none
+	Result *Type
+}
+
+var primitives = map[Kind]*Type{}
+
+func init() {
+	for kind := KindBool; kind <= KindString; kind++ {
+		primitives[kind] = &Type{Kind: kind}
+	}
+}
+
+// Primitive returns the type of the primitive kind, such as KindU32 or KindString
+func Primitive(kind Kind) *Type {
+	return primitives[kind]
+}
+
+// List returns the type of lists of the element type
+func List(elem *Type) *Type {
+	return &Type{Kind: KindList, Elem: elem}
+}
+
+// Record returns the type of records with the fields
+func Record(fields ...Field) *Type {
+	return &Type{Kind: KindRecord, Fields: fields}
+}
+
+// Tuple returns the type of tuples of the types
+func Tuple(types ...*Type) *Type {
+	fields := make([]Field, len(types))
+	for i, t := range types {
+		fields[i] = Field{Type: t}
+	}
+	return &Type{Kind: KindTuple, Fields: fields}
+}
+
+// VariantOf returns the type of variants with the cases
+func VariantOf(cases ...Case) *Type {
+	return &Type{Kind: KindVariant, Cases: cases}
+}
+
+// Enum returns the type of enums with the cases
+func Enum(names ...string) *Type {
+	cases := make([]Case, len(names))
+	for i, name := range names {
+		cases[i] = Case{Name: name}
+	}
+	return &Type{Kind: KindEnum, Cases: cases}
+}
+
+// Option returns the type of options of the type
+func Option(t *Type) *Type {
+	return &Type{Kind: KindOption, Cases: []Case{{Name: "none"}, {Name: "some", Type: t}}}
+}
+
+// ResultOf returns the type of results with the ok and error types, which may be nil
+func ResultOf(ok, err *Type) *Type {
+	return &Type{Kind: KindResult, Cases: []Case{{Name: "ok", Type: ok}, {Name: "error", Type: err}}}
+}
+
+// Flags returns the type of flags with the labels
+func Flags(labels ...string) *Type {
+	return &Type{Kind: KindFlags, Labels: labels}
+}
+
+// Own returns the type of owned handles of the resource
+func Own(resource *ResourceType) *Type {
+	return &Type{Kind: KindOwn, Resource: resource}
+}
+
+// Borrow returns the type of borrowed handles of the resource
+func Borrow(resource *ResourceType) *Type {
+	return &Type{Kind: KindBorrow, Resource: resource}
+}
+
+func (t *Type) String() string {
+	if t == nil {
+		return "_"
+	}
+	switch t.Kind {
+	case KindList, KindOption:
+		elem := t.Elem
+		if t.Kind == KindOption {
+			elem = t.Cases[1].Type
+		}
+		return fmt.Sprintf("%s<%s>", t.Kind, elem)
+	case KindRecord, KindTuple:
+		fields := make([]string, len(t.Fields))
+		for i, field := range t.Fields {
+			fields[i] = field.Type.String()
+			if field.Name != "" {
+				fields[i] = field.Name + ": " + fields[i]
+			}
+		}
+		return fmt.Sprintf("%s<%s>", t.Kind, strings.Join(fields, ", "))
+	case KindVariant, KindEnum:
+		cases := make([]string, len(t.Cases))
+		for i, c := range t.Cases {
+			cases[i] = c.Name
+			if c.Type != nil {
+				cases[i] += "(" + c.Type.String() + ")"
+			}
+		}
+		return fmt.Sprintf("%s { %s }", t.Kind, strings.Join(cases, ", "))
+	case KindResult:
+		return fmt.Sprintf("result<%s, %s>", t.Cases[0].Type, t.Cases[1].Type)
+	case KindFlags:
+		return fmt.Sprintf("flags { %s }", strings.Join(t.Labels, ", "))
+	case KindOwn, KindBorrow:
+		return fmt.Sprintf("%s<%s>", t.Kind, t.Resource)
+	default:
+		return t.Kind.String()
+	}
+}
+
+// isVariant returns true if values of the type are encoded as variants
+func (t *Type) isVariant() bool {
+	switch t.Kind {
+	case KindVariant, KindEnum, KindOption, KindResult:
+		return true
+	default:
+		return false
+	}
+}
+
+// alignment returns the alignment of the type in linear memory
+func alignment(t *Type) uint32 {
+	switch t.Kind {
+	case KindBool, KindS8, KindU8:
+		return 1
+	case KindS16, KindU16:
+		return 2
+	case KindS32, KindU32, KindF32, KindChar, KindOwn, KindBorrow, KindString, KindList:
+		return 4
+	case KindS64, KindU64, KindF64:
+		return 8
+	case KindRecord, KindTuple:
+		align := uint32(1)
+		for _, field := range t.Fields {
+			align = max(align, alignment(field.Type))
+		}
+		return align
+	case KindFlags:
+		return flagsSize(len(t.Labels))
+	default:
+		return max(discriminantSize(len(t.Cases)), maxCaseAlignment(t.Cases))
+	}
+}
+
+// size returns the size of the type in linear memory
+func size(t *Type) uint32 {
+	switch t.Kind {
+	case KindBool, KindS8, KindU8:
+		return 1
+	case KindS16, KindU16:
+		return 2
+	case KindS32, KindU32, KindF32, KindChar, KindOwn, KindBorrow:
+		return 4
+	case KindS64, KindU64, KindF64, KindString, KindList:
+		return 8
+	case KindRecord, KindTuple:
+		var s uint32
+		for _, field := range t.Fields {
+			s = alignTo(s, alignment(field.Type)) + size(field.Type)
+		}
+		return alignTo(s, alignment(t))
+	case KindFlags:
+		if len(t.Labels) > 16 {
+			return 4 * ((uint32(len(t.Labels)) + 31) / 32)
+		}
+		return flagsSize(len(t.Labels))
+	default:
+		s := alignTo(discriminantSize(len(t.Cases)), maxCaseAlignment(t.Cases))
+		var payload uint32
+		for _, c := range t.Cases {
+			if c.Type != nil {
+				payload = max(payload, size(c.Type))
+			}
+		}
+		return alignTo(s+payload, alignment(t))
+	}
+}
+
+// flagsSize returns the size of the integer flags are packed in, up to 32 flags
+func flagsSize(n int) uint32 {
+	switch {
+	case n <= 8:
+		return 1
+	case n <= 16:
+		return 2
+	default:
+		return 4
+	}
+}
+
+func discriminantSize(n int) uint32 {
+	switch {
+	case n <= 1<<8:
+		return 1
+	case n <= 1<<16:
+		return 2
+	default:
+		return 4
+	}
+}
+
+func maxCaseAlignment(cases []Case) uint32 {
+	align := uint32(1)
+	for _, c := range cases {
+		if c.Type != nil {
+			align = max(align, alignment(c.Type))
+		}
+	}
+	return align
+}
+
+func alignTo(offset, align uint32) uint32 {
+	return (offset + align - 1) / align * align
+}
+
+// flatten returns the core value types the type is passed as in function parameters and results
+func flatten(t *Type) []api.ValueType {
+	switch t.Kind {
+	case KindBool, KindS8, KindU8, KindS16, KindU16, KindS32, KindU32, KindChar, KindOwn, KindBorrow:
+		return []api.ValueType{api.ValueTypeI32}
+	case KindS64, KindU64:
+		return []api.ValueType{api.ValueTypeI64}
+	case KindF32:
+		return []api.ValueType{api.ValueTypeF32}
+	case KindF64:
+		return []api.ValueType{api.ValueTypeF64}
+	case KindString, KindList:
+		return []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
+	case KindRecord, KindTuple:
+		var flat []api.ValueType
+		for _, field := range t.Fields {
+			flat = append(flat, flatten(field.Type)...)
+		}
+		return flat
+	case KindFlags:
+		flat := make([]api.ValueType, (len(t.Labels)+31)/32)
+		for i := range flat {
+			flat[i] = api.ValueTypeI32
+		}
+		return flat
+	default:
+		var payload []api.ValueType
+		for _, c := range t.Cases {
+			if c.Type == nil {
+				continue
+			}
+			for i, flat := range flatten(c.Type) {
+				if i < len(payload) {
+					payload[i] = join(payload[i], flat)
+				} else {
+					payload = append(payload, flat)
+				}
+			}
+		}
+		return append([]api.ValueType{api.ValueTypeI32}, payload...)
+	}
+}
+
+// join returns the core value type that can hold the values of both types
+func join(a, b api.ValueType) api.ValueType {
+	if a == b {
+		return a
+	}
+	if (a == api.ValueTypeI32 && b == api.ValueTypeF32) || (a == api.ValueTypeF32 && b == api.ValueTypeI32) {
+		return api.ValueTypeI32
+	}
+	return api.ValueTypeI64
+}
+
+const (
+	// maxFlatParams is the maximum number of core parameters passed directly, past which they are passed in memory
+	maxFlatParams = 16
+	// maxFlatResults is the maximum number of core results returned directly, past which they are returned in memory
+	maxFlatResults = 1
+)
+
+// paramsType returns the tuple of the function's parameters, which is how they are passed in memory
+func (f *FuncType) paramsType() *Type {
+	return &Type{Kind: KindTuple, Fields: f.Params}
+}
+
+func (f *FuncType) flatParams() []api.ValueType {
+	return flatten(f.paramsType())
+}
+
+func (f *FuncType) flatResults() []api.ValueType {
+	if f.Result == nil {
+		return nil
+	}
+	return flatten(f.Result)
+}
+
+func (f *FuncType) String() string {
+	params := make([]string, len(f.Params))
+	for i, param := range f.Params {
+		params[i] = param.Name + ": " + param.Type.String()
+	}
+	s := "func(" + strings.Join(params, ", ") + ")"
+	if f.Result != nil {
+		s += " -> " + f.Result.String()
+	}
+	return s
+}
@@ -0,0 +1,165 @@
+package component
+
+import (
+	"fmt"
+)
+
+// Variant is the value of a variant, option or result: the index of its case, and its payload if the
+// case has one
+type Variant struct {
+	Case  uint32
+	Value any
+}
+
+// None returns the none value of an option
+func None() Variant {
+	return Variant{Case: 0}
+}
+
+// Some returns the value of an option holding the value
+func Some(value any) Variant {
+	return Variant{Case: 1, Value: value}
+}
+
+// Ok returns the ok value of a result, with the value as payload
+func Ok(value any) Variant {
+	return Variant{Case: 0, Value: value}
+}
+
+// Err returns the error value of a result, with the value as payload
+func Err(value any) Variant {
+	return Variant{Case: 1, Value: value}
+}
+
+// IsErr returns true if the variant is the error case of a result
+func (v Variant) IsErr() bool {
+	return v.Case == 1
+}
+
+// Unsigned returns the value of an integer of any Go integer type as an unsigned 64-bit integer,
+// with negative values in two's complement
+func Unsigned(value any) (uint64, error) {
+	switch v := value.(type) {
+	case uint64:
+		return v, nil
+	case uint32:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint8:
+		return uint64(v), nil
+	case uint:
+		return uint64(v), nil
+	case int64:
+		return uint64(v), nil //nolint:gosec // G115: two's complement conversion is intended
+	case int32:
+		return uint64(v), nil //nolint:gosec // G115: two's complement conversion is intended
+	case int16:
+		return uint64(v), nil //nolint:gosec // G115: two's complement conversion is intended
+	case int8:
+		return uint64(v), nil //nolint:gosec // G115: two's complement conversion is intended
+	case int:
+		return uint64(v), nil //nolint:gosec // G115: two's complement conversion is intended
+	default:
+		return 0, fmt.Errorf("expected an integer, got %T", value)
+	}
+}
+
+// Args gives typed access to the arguments of a host function call. The first conversion error is
+// recorded and returned by Err, and the accessors return zero values after it.
+type Args struct {
+	values []any
+	err    error
+}
+
+// NewArgs wraps the arguments of a host function call
+func NewArgs(values []any) *Args {
+	return &Args{values: values}
+}
+
+// Err returns the first error converting an argument
+func (a *Args) Err() error {
+	return a.err
+}
+
+func (a *Args) get(i int) any {
+	if a.err != nil {
+		return nil
+	}
+	if i >= len(a.values) {
+		a.err = fmt.Errorf("missing argument %d", i)
+		return nil
+	}
+	return a.values[i]
+}
+
+// Any returns the argument as is, such as the representation of a resource
+func (a *Args) Any(i int) any {
+	return a.get(i)
+}
+
+// Uint64 returns an integer argument
+func (a *Args) Uint64(i int) uint64 {
+	value := a.get(i)
+	if a.err != nil {
+		return 0
+	}
+	v, err := Unsigned(value)
+	if err != nil {
+		a.err = fmt.Errorf("argument %d: %w", i, err)
+	}
+	return v
+}
+
+// Uint32 returns an integer argument of at most 32 bits, such as an enum
+func (a *Args) Uint32(i int) uint32 {
+	return uint32(a.Uint64(i)) //nolint:gosec // G115: truncation to the argument's width is intended
+}
+
+// Bool returns a bool argument
+func (a *Args) Bool(i int) bool {
+	return getAs[bool](a, i)
+}
+
+// String returns a string argument
+func (a *Args) String(i int) string {
+	return getAs[string](a, i)
+}
+
+// Bytes returns a list<u8> argument
+func (a *Args) Bytes(i int) []byte {
+	return getAs[[]byte](a, i)
+}
+
+// List returns a list argument of another element type than u8
+func (a *Args) List(i int) []any {
+	return getAs[[]any](a, i)
+}
+
+// Record returns a record or tuple argument
+func (a *Args) Record(i int) []any {
+	return getAs[[]any](a, i)
+}
+
+// Variant returns a variant, option or result argument
+func (a *Args) Variant(i int) Variant {
+	return getAs[Variant](a, i)
+}
+
+func getAs[T any](a *Args, i int) T {
+	var zero T
+	value := a.get(i)
+	if a.err != nil {
+		return zero
+	}
+	v, ok := value.(T)
+	if !ok {
+		a.err = fmt.Errorf("argument %d: expected %T, got %T", i, zero, value)
+	}
+	return v
+}
+
+// Resource returns the representation of a host resource argument
+func Resource[T any](a *Args, i int) T {
+	return getAs[T](a, i)
+}
@@ -16,3 +16,12 @@ const (
 	// BytesInGB represents the number of bytes in a gigabyte
 	BytesInGB = 1 << 30
 )
+
+// Entry points of WebAssembly components
+const (
+	// startFunction is the entry point of WASI commands
+	startFunction = "_start"
+
+	// componentRunFunction is the function of components that the start function runs
+	componentRunFunction = "wasi:cli/run@0.2.0#run"
+)
@@ -6,23 +6,26 @@ import (
 	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
 )
 
 const Component = "WASM"
 
 // WASM-specific error codes
 const (
-	ModuleNotFound     = "ModuleNotFound"
-	ModuleCompileError = "ModuleCompileError"
-	ModuleLoadError    = "ModuleLoadError"
-	WASIError          = "WASIError"
-	UnknownModuleError = "UnknownModuleError"
+	ModuleNotFound        = "ModuleNotFound"
+	ModuleCompileError    = "ModuleCompileError"
+	ComponentNotSupported = "ComponentNotSupported"
+	ModuleLoadError       = "ModuleLoadError"
+	WASIError             = "WASIError"
+	UnknownModuleError    = "UnknownModuleError"
 	// Handler and Executor specific error codes
 	SpecError        = "SpecError"
 	LogError         = "LogError"
 	EntrypointError  = "EntrypointError"
 	MemoryLimitError = "MemoryLimitError"
 	FilesystemError  = "FilesystemError"
+	FuelExhausted    = "FuelExhausted"
 	// Configuration error codes
 	InputConfigError  = "InputConfigError"
 	OutputConfigError = "OutputConfigError"
@@ -50,6 +53,27 @@ func NewModuleCompileError(path string, err error) bacerrors.Error {
 		WithHint("Check that the module is compatible with the WASM runtime and follows the correct format")
 }
 
+// NewComponentNotSupportedError creates an error when a WebAssembly component is imported as a core module.
+func NewComponentNotSupportedError(path string) bacerrors.Error {
+	return bacerrors.Newf("module at %q is a WebAssembly component, which can only be run as the entry module", path).
+		WithCode(ComponentNotSupported).
+		WithHTTPStatusCode(http.StatusBadRequest).
+		WithComponent(Component).
+		WithHint(`Components link to the host through the WASI 0.2 interfaces they import, and can't be imported by
+other modules. To resolve this:
+1. Build import modules as core modules, e.g. for the wasm32-wasip1 target
+2. Or compose the components into a single component before running it`)
+}
+
+// NewComponentImportModulesError creates an error when import modules are given for a WebAssembly component.
+func NewComponentImportModulesError(path string) bacerrors.Error {
+	return bacerrors.Newf("entry module at %q is a WebAssembly component, which can't use import modules", path).
+		WithCode(ComponentNotSupported).
+		WithHTTPStatusCode(http.StatusBadRequest).
+		WithComponent(Component).
+		WithHint("Compose the components the entry component depends on into it, and remove the import modules")
+}
+
 // NewModuleLoadError creates an error when a WASM module fails to load.
 func NewModuleLoadError(path string, err error) bacerrors.Error {
 	return bacerrors.Wrapf(err, "failed to load module at %q", path).
@@ -119,6 +143,15 @@ func NewMemoryLimitError(requested, max uint64) bacerrors.Error {
 		WithHint("Reduce the requested memory to be within the WASM limit")
 }
 
+// NewFuelExhaustedError creates an error when an execution consumes its whole fuel budget
+func NewFuelExhaustedError(budget uint64) bacerrors.Error {
+	return bacerrors.Newf("execution exhausted its fuel budget of %d", budget).
+		WithCode(FuelExhausted).
+		WithHTTPStatusCode(http.StatusBadRequest).
+		WithComponent(Component).
+		WithHint("Increase the fuel budget of the job, or reduce the work done by the module")
+}
+
 // NewFilesystemError creates an error when there's an issue with the filesystem
 func NewFilesystemError(path string, err error) bacerrors.Error {
 	return bacerrors.Wrapf(err, "filesystem error at %q", path).
@@ -153,3 +186,21 @@ func NewInputConfigError(msg string) bacerrors.Error {
 3. Verify that input sources exist and are accessible
 4. Make sure input paths are properly formatted`)
 }
+
+// NewExecNotSupportedError creates an error when an interactive session is requested into a WASM execution
+func NewExecNotSupportedError() bacerrors.Error {
+	return bacerrors.New("interactive sessions are not supported for WASM executions").
+		WithCode(executor.ExecNotSupported).
+		WithHTTPStatusCode(http.StatusNotImplemented).
+		WithComponent(Component).
+		WithHint("Use 'bacalhau job logs --follow' to observe the output of WASM executions")
+}
+
+// NewCheckpointNotSupportedError creates an error when a WASM execution is asked to checkpoint its state
+func NewCheckpointNotSupportedError() bacerrors.Error {
+	return bacerrors.New("checkpoints are not supported for WASM executions").
+		WithCode(executor.CheckpointNotSupported).
+		WithHTTPStatusCode(http.StatusNotImplemented).
+		WithComponent(Component).
+		WithHint("Remove the checkpoint configuration from the task, or run it with the docker engine")
+}
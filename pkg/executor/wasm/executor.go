@@ -8,10 +8,12 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/dustin/go-humanize"
 	"github.com/rs/zerolog/log"
 	"github.com/tetratelabs/wazero"
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/config/types"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/math"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
 	"github.com/bacalhau-project/bacalhau/pkg/models/messages"
@@ -20,8 +22,11 @@ import (
 
 	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
 	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/funcs/kv"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/funcs/objectstore"
 	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/util/filefs"
 	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/util/mountfs"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/util/overlayfs"
 	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/util/touchfs"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
 )
@@ -32,11 +37,46 @@ import (
 type Executor struct {
 	// handlers is a map of executionID to its handler.
 	handlers generic.SyncMap[string, *executionHandler]
+	// moduleCache caches compiled modules across executions. It is nil if caching is disabled.
+	moduleCache *ModuleCache
+	// storeModules are the key-value and object store host modules available to executions.
+	storeModules storeModules
+}
+
+type ExecutorParams struct {
+	Config types.WASM
+	// CacheDir is the directory of the compiled module cache. The cache is disabled if empty.
+	CacheDir string
+	// KeyValueStore backs the key-value store of WASM jobs. The store is not available to jobs if nil.
+	KeyValueStore kv.Store
+	// ObjectStoreClient accesses the object store buckets configured for WASM jobs.
+	// The buckets are not available to jobs if nil.
+	ObjectStoreClient objectstore.Client
 }
 
 // NewExecutor creates a new WASM executor instance.
-func NewExecutor() (*Executor, error) {
-	return &Executor{}, nil
+func NewExecutor(params ExecutorParams) (*Executor, error) {
+	modules, err := newStoreModules(params)
+	if err != nil {
+		return nil, err
+	}
+	e := &Executor{storeModules: modules}
+	if params.CacheDir != "" && !params.Config.CompilationCache.Disabled {
+		var maxSize uint64
+		if params.Config.CompilationCache.MaxSize != "" {
+			var err error
+			maxSize, err = humanize.ParseBytes(params.Config.CompilationCache.MaxSize)
+			if err != nil {
+				return nil, fmt.Errorf("invalid wasm compilation cache max size %q: %w", params.Config.CompilationCache.MaxSize, err)
+			}
+		}
+		moduleCache, err := NewModuleCache(params.CacheDir, maxSize)
+		if err != nil {
+			return nil, err
+		}
+		e.moduleCache = moduleCache
+	}
+	return e, nil
 }
 
 // IsInstalled checks if the WASM executor is available.
@@ -84,6 +124,7 @@ func (e *Executor) Start(ctx context.Context, request *executor.RunCommandReques
 		compute.ExecutionResultsDir(request.ExecutionDir),
 		request.Inputs,
 		request.Outputs,
+		request.Volumes,
 	)
 	if err != nil {
 		return err
@@ -92,6 +133,8 @@ func (e *Executor) Start(ctx context.Context, request *executor.RunCommandReques
 	handler, err := newExecutionHandler(ctx,
 		request,
 		wazero.NewRuntimeWithConfig(ctx, engineConfig),
+		e.moduleCache,
+		e.storeModules,
 		rootFs)
 
 	if err != nil {
@@ -107,6 +150,9 @@ func (e *Executor) Start(ctx context.Context, request *executor.RunCommandReques
 // configureRuntime sets up the WASM runtime with appropriate memory limits
 func (e *Executor) configureRuntime(memoryLimit uint64) (wazero.RuntimeConfig, error) {
 	engineConfig := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if e.moduleCache != nil {
+		engineConfig = engineConfig.WithCompilationCache(e.moduleCache.CompilationCache())
+	}
 
 	// Apply memory limits to the runtime. We have to do this in multiples of
 	// the WASM page size of 64kb, so round up to the nearest page size if the
@@ -193,6 +239,21 @@ func (e *Executor) GetLogStream(ctx context.Context, request messages.ExecutionL
 	return handler.outputStream(ctx, request)
 }
 
+// Exec is not supported for WASM executions, which have no environment to run other commands in.
+func (e *Executor) Exec(ctx context.Context, request *executor.ExecRequest) (executor.ExecSession, error) {
+	return nil, NewExecNotSupportedError()
+}
+
+// Attach is not supported for WASM executions.
+func (e *Executor) Attach(ctx context.Context, request *executor.ExecRequest) (executor.ExecSession, error) {
+	return nil, NewExecNotSupportedError()
+}
+
+// Checkpoint is not supported for WASM executions, which cannot receive signals.
+func (e *Executor) Checkpoint(ctx context.Context, request *executor.CheckpointRequest) error {
+	return NewCheckpointNotSupportedError()
+}
+
 // Run initiates and waits for the completion of an execution in one call.
 // This method serves as a higher-level convenience function that
 // internally calls Start and Wait methods.
@@ -219,14 +280,17 @@ func (e *Executor) Run(
 // makeFsFromStorage sets up a virtual filesystem (represented by an fs.FS) that
 // will be the filesystem exposed to our WASM. The strategy for this is to:
 //
-//   - mount each input at the name specified by Path
+//   - mount each input at the name specified by Path, with its writable layer over
+//     it if the input is mounted with an overlay
 //   - make a directory in the job results directory for each output and mount that
 //     at the name specified by Name
+//   - mount each persistent volume writable at its target
 func (e *Executor) makeFsFromStorage(
 	ctx context.Context,
 	jobResultsDir string,
 	volumes []storage.PreparedStorage,
-	outputs []*models.ResultPath) (fs.FS, error) {
+	outputs []*models.ResultPath,
+	persistentVolumes []storage.StorageVolume) (fs.FS, error) {
 	var err error
 	rootFs := mountfs.New()
 
@@ -251,7 +315,9 @@ func (e *Executor) makeFsFromStorage(
 		}
 
 		var inputFs fs.FS
-		if stat.IsDir() {
+		if stat.IsDir() && v.Volume.Overlay != nil {
+			inputFs = overlayfs.New(v.Volume.Source, v.Volume.Overlay.UpperDir)
+		} else if stat.IsDir() {
 			inputFs = os.DirFS(v.Volume.Source)
 		} else {
 			inputFs = filefs.New(v.Volume.Source)
@@ -297,6 +363,18 @@ func (e *Executor) makeFsFromStorage(
 		}
 	}
 
+	for _, volume := range persistentVolumes {
+		log.Ctx(ctx).Debug().
+			Str("target", volume.Target).
+			Str("dir", volume.Source).
+			Msg("Using persistent volume")
+
+		err = rootFs.Mount(volume.Target, touchfs.New(volume.Source))
+		if err != nil {
+			return nil, NewFilesystemError(volume.Target, err)
+		}
+	}
+
 	return rootFs, nil
 }
 
@@ -22,7 +22,7 @@ func TestExecutorTestSuite(t *testing.T) {
 }
 
 func (s *ExecutorTestSuite) TestFailingRequestedMemGreaterThan4GB() {
-	e, err := NewExecutor()
+	e, err := NewExecutor(ExecutorParams{})
 	s.Require().NoError(err)
 
 	r := &executor.RunCommandRequest{
@@ -0,0 +1,130 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// errFuelExhausted aborts the execution once its fuel budget is exhausted
+var errFuelExhausted = errors.New("fuel exhausted")
+
+// errNoFuelTank is returned if a module that was not instrumented calls the refuel function
+var errNoFuelTank = errors.New("module calling refuel has no fuel tank")
+
+// fuelBatch is the most fuel a module is granted at once. Modules consume the fuel they are granted
+// without calling the host, and only call it again once they have consumed it all.
+const fuelBatch = 10_000
+
+// fuelMeter tracks the fuel consumed by an execution against its budget.
+//
+// Fuel is consumed deterministically, one unit on entry to every function and one unit on every iteration
+// of a loop, so that the same module with the same inputs consumes the same fuel on every node, regardless
+// of the speed of the hardware it runs on. Modules are instrumented to consume fuel when they are compiled
+// (see instrumentFuel), and hold the fuel they are granted in a global of their own, their tank.
+type fuelMeter struct {
+	budget uint64
+
+	mu sync.Mutex
+	// consumed is the fuel consumed by modules before their tanks were last emptied
+	consumed  uint64
+	tanks     map[api.Module]*fuelTank
+	exhausted bool
+}
+
+// fuelTank is the global holding the fuel granted to a module that it has not consumed yet
+type fuelTank struct {
+	global  api.MutableGlobal
+	granted uint64
+}
+
+func newFuelMeter(budget uint64) *fuelMeter {
+	return &fuelMeter{budget: budget, tanks: make(map[api.Module]*fuelTank)}
+}
+
+// instantiate instantiates the module that instrumented modules call to refuel in the runtime
+func (m *fuelMeter) instantiate(ctx context.Context, runtime wazero.Runtime) error {
+	_, err := runtime.NewHostModuleBuilder(fuelModuleName).
+		NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(m.refuel), nil, nil).
+		Export(fuelRefuelName).
+		Instantiate(ctx)
+	return err
+}
+
+// refuel refills the empty tank of the calling module, or aborts the execution if the budget is exhausted.
+// The tanks of all other modules are emptied first, so that no module holds fuel beyond the budget.
+func (m *fuelMeter) refuel(_ context.Context, mod api.Module, _ []uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tank, ok := m.tanks[mod]
+	if !ok {
+		global, ok := mod.ExportedGlobal(fuelTankExport).(api.MutableGlobal)
+		if !ok {
+			panic(errNoFuelTank)
+		}
+		tank = &fuelTank{global: global}
+		m.tanks[mod] = tank
+	}
+
+	for _, t := range m.tanks {
+		m.consumed += t.granted - t.global.Get()
+		t.granted = 0
+		t.global.Set(0)
+	}
+	if m.consumed >= m.budget {
+		m.exhausted = true
+		// wazero recovers the panic and returns it as the error of the call
+		panic(errFuelExhausted)
+	}
+	tank.granted = min(fuelBatch, m.budget-m.consumed)
+	tank.global.Set(tank.granted)
+}
+
+// Consumed returns the fuel consumed by the execution, which never exceeds its budget
+func (m *fuelMeter) Consumed() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	consumed := m.consumed
+	for _, t := range m.tanks {
+		consumed += t.granted - t.global.Get()
+	}
+	return min(consumed, m.budget)
+}
+
+// Exhausted returns true if the execution was aborted because it ran out of fuel
+func (m *fuelMeter) Exhausted() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.exhausted
+}
+
+type fuelMeterKey struct{}
+
+// withFuelMeter returns a context whose modules are instrumented to consume fuel when they are loaded
+func withFuelMeter(ctx context.Context, meter *fuelMeter) context.Context {
+	return context.WithValue(ctx, fuelMeterKey{}, meter)
+}
+
+func fuelMeterFromContext(ctx context.Context) *fuelMeter {
+	meter, _ := ctx.Value(fuelMeterKey{}).(*fuelMeter)
+	return meter
+}
+
+// meterModule returns the module binary instrumented to consume fuel if the context meters fuel, and the
+// binary unchanged otherwise
+func meterModule(ctx context.Context, binary []byte) ([]byte, error) {
+	if fuelMeterFromContext(ctx) == nil {
+		return binary, nil
+	}
+	instrumented, err := instrumentFuel(binary)
+	if err != nil {
+		return nil, fmt.Errorf("instrumenting module to consume fuel: %w", err)
+	}
+	return instrumented, nil
+}
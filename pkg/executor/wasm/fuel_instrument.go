@@ -0,0 +1,773 @@
+package wasm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The fuel of instrumented modules is held in a global of the module, its tank, which the module refills
+// by calling the refuel function of the fuel meter whenever it is empty.
+const (
+	fuelModuleName = "bacalhau:fuel/meter"
+	fuelRefuelName = "refuel"
+	fuelTankExport = "bacalhau:fuel/tank"
+)
+
+// Sections of core modules
+const (
+	typeSection     = 1
+	importSection   = 2
+	globalSection   = 6
+	exportSection   = 7
+	startSection    = 8
+	elementSection  = 9
+	codeSection     = 10
+	debugSectionPre = ".debug_"
+)
+
+// sectionOrder is the position of each known section in a core module, which sections must follow
+var sectionOrder = map[byte]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 13: 6, 6: 7, 7: 8, 8: 9, 9: 10, 12: 11, 10: 12, 11: 13}
+
+// Kinds of imports and exports
+const (
+	externFunc   = 0x00
+	externTable  = 0x01
+	externMemory = 0x02
+	externGlobal = 0x03
+	externTag    = 0x04
+)
+
+// Opcodes of the instructions the instrumentation rewrites or inserts
+const (
+	opBlock           = 0x02
+	opLoop            = 0x03
+	opIf              = 0x04
+	opEnd             = 0x0b
+	opCall            = 0x10
+	opReturnCall      = 0x12
+	opGlobalGet       = 0x23
+	opGlobalSet       = 0x24
+	opI64Const        = 0x42
+	opI64Eqz          = 0x50
+	opI64Sub          = 0x7d
+	opRefFunc         = 0xd2
+	opPrefixMisc      = 0xfc
+	opPrefixSIMD      = 0xfd
+	opPrefixAtomic    = 0xfe
+	blockTypeEmpty    = 0x40
+	valTypeI64        = 0x7e
+	globalMutable     = 0x01
+	funcTypeForm      = 0x60
+	nameSubsectionFn  = 1
+	nameSubsectionLoc = 2
+	nameSubsectionLbl = 3
+)
+
+// wasmMagic starts the binary of every WebAssembly module
+var wasmMagic = []byte("\x00asm")
+
+type moduleSection struct {
+	id       byte
+	contents []byte
+}
+
+// fuelInstrumenter rewrites a core module to consume a unit of fuel on entry to each of its functions and
+// on each iteration of its loops. A module that runs out of fuel calls the refuel function, which is
+// imported after the functions the module imports and so shifts the index of every function it defines.
+type fuelInstrumenter struct {
+	types       uint32
+	funcImports uint32
+	globals     uint32
+}
+
+// instrumentFuel returns a copy of the core module binary that consumes fuel as it runs. The module
+// imports the refuel function of the fuel meter and exports its tank, so that the meter can refill it.
+// DWARF sections are dropped, as the instrumentation moves the code they describe.
+func instrumentFuel(binary []byte) ([]byte, error) {
+	const headerLength = 8
+	if len(binary) < headerLength || !bytes.Equal(binary[:len(wasmMagic)], wasmMagic) {
+		return nil, errors.New("not a core WebAssembly module")
+	}
+	sections, err := readSections(binary[headerLength:])
+	if err != nil {
+		return nil, err
+	}
+
+	m := &fuelInstrumenter{}
+	var globalImports uint32
+	for _, s := range sections {
+		switch s.id {
+		case typeSection:
+			m.types, err = newWasmReader(s.contents).u32()
+		case importSection:
+			m.funcImports, globalImports, err = countImports(s.contents)
+		case globalSection:
+			m.globals, err = newWasmReader(s.contents).u32()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %w", s.id, err)
+		}
+	}
+	m.globals += globalImports
+
+	// the refuel function, its type and the tank are added to sections the module might not have
+	for _, id := range []byte{typeSection, importSection, globalSection, exportSection} {
+		sections = ensureSection(sections, id)
+	}
+
+	out := append([]byte(nil), binary[:headerLength]...)
+	for _, s := range sections {
+		if s.id == customSectionID && isDebugSection(s.contents) {
+			continue
+		}
+		contents, err := m.rewriteSection(s)
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %w", s.id, err)
+		}
+		out = append(out, s.id)
+		out = appendU32(out, uint32(len(contents))) //nolint:gosec // G115: sections are smaller than 2^32
+		out = append(out, contents...)
+	}
+	return out, nil
+}
+
+func readSections(binary []byte) ([]moduleSection, error) {
+	var sections []moduleSection
+	r := newWasmReader(binary)
+	for !r.eof() {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		contents, err := r.bytes(size)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, moduleSection{id: id, contents: contents})
+	}
+	return sections, nil
+}
+
+// ensureSection adds an empty section with the ID if the module has none, before the first section that
+// must follow it
+func ensureSection(sections []moduleSection, id byte) []moduleSection {
+	at := len(sections)
+	for i, s := range sections {
+		if s.id == id {
+			return sections
+		}
+		if s.id != customSectionID && sectionOrder[s.id] > sectionOrder[id] && at == len(sections) {
+			at = i
+		}
+	}
+	empty := moduleSection{id: id, contents: []byte{0x00}}
+	return append(sections[:at], append([]moduleSection{empty}, sections[at:]...)...)
+}
+
+func isDebugSection(contents []byte) bool {
+	name, err := newWasmReader(contents).name()
+	return err == nil && strings.HasPrefix(name, debugSectionPre)
+}
+
+// countImports returns the number of functions and globals the module imports
+func countImports(section []byte) (funcs, globals uint32, err error) {
+	r := newWasmReader(section)
+	count, err := r.u32()
+	if err != nil {
+		return 0, 0, err
+	}
+	for i := uint32(0); i < count; i++ {
+		if _, err = r.name(); err != nil {
+			return 0, 0, err
+		}
+		if _, err = r.name(); err != nil {
+			return 0, 0, err
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return 0, 0, err
+		}
+		switch kind {
+		case externFunc:
+			funcs++
+			_, err = r.u32()
+		case externTable:
+			if _, err = r.byte(); err == nil {
+				err = r.skipLimits()
+			}
+		case externMemory:
+			err = r.skipLimits()
+		case externGlobal:
+			globals++
+			_, err = r.bytes(2) // value type and mutability
+		case externTag:
+			_, err = r.bytes(1)
+			if err == nil {
+				_, err = r.u32()
+			}
+		default:
+			err = fmt.Errorf("unknown import kind 0x%x", kind)
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return funcs, globals, nil
+}
+
+func (m *fuelInstrumenter) rewriteSection(s moduleSection) ([]byte, error) {
+	r := newWasmReader(s.contents)
+	switch s.id {
+	case typeSection:
+		return m.appendEntry(r, []byte{funcTypeForm, 0x00, 0x00})
+	case importSection:
+		entry := appendName(nil, fuelModuleName)
+		entry = appendName(entry, fuelRefuelName)
+		entry = appendU32(append(entry, externFunc), m.types)
+		return m.appendEntry(r, entry)
+	case globalSection:
+		return m.rewriteGlobals(r)
+	case exportSection:
+		return m.rewriteExports(r)
+	case startSection:
+		index, err := r.u32()
+		return appendU32(nil, m.funcIndex(index)), err
+	case elementSection:
+		return m.rewriteElements(r)
+	case codeSection:
+		return m.rewriteCode(r)
+	case customSectionID:
+		name, err := r.name()
+		if err != nil || name != nameSectionName {
+			return s.contents, nil //nolint:nilerr // custom sections the runtime doesn't understand are kept as they are
+		}
+		return m.rewriteNames(r, appendName(nil, name))
+	default:
+		return s.contents, nil
+	}
+}
+
+// funcIndex returns the index of a function once the refuel function is imported
+func (m *fuelInstrumenter) funcIndex(index uint32) uint32 {
+	if index >= m.funcImports {
+		return index + 1
+	}
+	return index
+}
+
+// refuelIndex returns the index of the refuel function, and tankIndex the index of the tank global
+func (m *fuelInstrumenter) refuelIndex() uint32 { return m.funcImports }
+func (m *fuelInstrumenter) tankIndex() uint32   { return m.globals }
+
+// appendEntry appends an entry to a section that is a vector of entries
+func (m *fuelInstrumenter) appendEntry(r *wasmReader, entry []byte) ([]byte, error) {
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	out := appendU32(nil, count+1)
+	out = append(out, r.rest()...)
+	return append(out, entry...), nil
+}
+
+func (m *fuelInstrumenter) rewriteGlobals(r *wasmReader) ([]byte, error) {
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	out := appendU32(nil, count+1)
+	for i := uint32(0); i < count; i++ {
+		globalType, err := r.bytes(2) // value type and mutability
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, globalType...)
+		if out, err = m.rewriteExpr(r, out); err != nil {
+			return nil, err
+		}
+	}
+	// the tank starts empty, so that the module refuels on entry to its first function
+	return append(out, valTypeI64, globalMutable, opI64Const, 0x00, opEnd), nil
+}
+
+func (m *fuelInstrumenter) rewriteExports(r *wasmReader) ([]byte, error) {
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	out := appendU32(nil, count+1)
+	for i := uint32(0); i < count; i++ {
+		name, err := r.name()
+		if err != nil {
+			return nil, err
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		index, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		if kind == externFunc {
+			index = m.funcIndex(index)
+		}
+		out = appendU32(append(appendName(out, name), kind), index)
+	}
+	return appendU32(append(appendName(out, fuelTankExport), externGlobal), m.tankIndex()), nil
+}
+
+// rewriteElements rewrites the function indices of element segments, whose flags tell if they are active,
+// with an explicit table, and if they list function indices or expressions
+func (m *fuelInstrumenter) rewriteElements(r *wasmReader) ([]byte, error) {
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	out := appendU32(nil, count)
+	for i := uint32(0); i < count; i++ {
+		flags, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		if flags > 7 {
+			return nil, fmt.Errorf("unknown element segment flags 0x%x", flags)
+		}
+		out = appendU32(out, flags)
+		if flags&0x01 == 0 {
+			if flags&0x02 != 0 {
+				table, err := r.u32()
+				if err != nil {
+					return nil, err
+				}
+				out = appendU32(out, table)
+			}
+			if out, err = m.rewriteExpr(r, out); err != nil {
+				return nil, err
+			}
+		}
+		if flags&0x03 != 0 {
+			kind, err := r.byte()
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, kind)
+		}
+		items, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		out = appendU32(out, items)
+		for j := uint32(0); j < items; j++ {
+			if flags&0x04 != 0 {
+				out, err = m.rewriteExpr(r, out)
+			} else {
+				var index uint32
+				index, err = r.u32()
+				out = appendU32(out, m.funcIndex(index))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *fuelInstrumenter) rewriteCode(r *wasmReader) ([]byte, error) {
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	out := appendU32(nil, count)
+	for i := uint32(0); i < count; i++ {
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		body, err := r.bytes(size)
+		if err != nil {
+			return nil, err
+		}
+		rewritten, err := m.rewriteBody(newWasmReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("function %d: %w", m.funcImports+i, err)
+		}
+		out = appendU32(out, uint32(len(rewritten))) //nolint:gosec // G115: functions are smaller than 2^32
+		out = append(out, rewritten...)
+	}
+	return out, nil
+}
+
+// rewriteBody charges fuel on entry to the function and on each iteration of its loops
+func (m *fuelInstrumenter) rewriteBody(r *wasmReader) ([]byte, error) {
+	locals, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < locals; i++ {
+		if _, err = r.u32(); err != nil {
+			return nil, err
+		}
+		if _, err = r.byte(); err != nil {
+			return nil, err
+		}
+	}
+	out := append([]byte(nil), r.buf[:r.pos]...)
+	out = m.appendCharge(out)
+	for !r.eof() {
+		if out, err = m.rewriteInstruction(r, out); err != nil {
+			return nil, err
+		}
+		if r.buf[r.last] == opLoop {
+			out = m.appendCharge(out)
+		}
+	}
+	return out, nil
+}
+
+// rewriteExpr rewrites a constant expression, which ends at its first end instruction
+func (m *fuelInstrumenter) rewriteExpr(r *wasmReader, out []byte) ([]byte, error) {
+	for {
+		var err error
+		if out, err = m.rewriteInstruction(r, out); err != nil {
+			return nil, err
+		}
+		if r.buf[r.last] == opEnd {
+			return out, nil
+		}
+	}
+}
+
+// appendCharge appends the instructions consuming a unit of fuel, which refuel the tank if it is empty
+func (m *fuelInstrumenter) appendCharge(out []byte) []byte {
+	out = appendU32(append(out, opGlobalGet), m.tankIndex())
+	out = appendU32(append(out, opI64Eqz, opIf, blockTypeEmpty, opCall), m.refuelIndex())
+	out = appendU32(append(out, opEnd, opGlobalGet), m.tankIndex())
+	return appendU32(append(out, opI64Const, 0x01, opI64Sub, opGlobalSet), m.tankIndex())
+}
+
+// rewriteInstruction copies an instruction, rewriting the index of the functions it references
+//
+//nolint:gocyclo // the immediates of each opcode are decoded in a single switch
+func (m *fuelInstrumenter) rewriteInstruction(r *wasmReader, out []byte) ([]byte, error) {
+	r.last = r.pos
+	op, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case op == opCall || op == opReturnCall || op == opRefFunc:
+		index, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		return appendU32(append(out, op), m.funcIndex(index)), nil
+	case op == 0x00 || op == 0x01 || op == 0x05 || op == opEnd || op == 0x0f || op == 0x1a || op == 0x1b ||
+		op == 0xd1 || (op >= 0x45 && op <= 0xc4):
+		// no immediates
+	case op == opBlock || op == opLoop || op == opIf || op == 0xd0:
+		err = r.skipLEB() // block type or heap type
+	case op == 0x0c || op == 0x0d || (op >= 0x20 && op <= 0x26) || op == 0x3f || op == 0x40:
+		_, err = r.u32()
+	case op == 0x0e:
+		err = r.skipVec(func() error { _, err := r.u32(); return err })
+		if err == nil {
+			_, err = r.u32()
+		}
+	case op == 0x11 || op == 0x13:
+		if _, err = r.u32(); err == nil {
+			_, err = r.u32()
+		}
+	case op == 0x1c:
+		err = r.skipVec(func() error { _, err := r.byte(); return err })
+	case op >= 0x28 && op <= 0x3e:
+		err = r.skipMemarg()
+	case op == 0x41 || op == opI64Const:
+		err = r.skipLEB()
+	case op == 0x43:
+		_, err = r.bytes(4)
+	case op == 0x44:
+		_, err = r.bytes(8)
+	case op == opPrefixMisc:
+		err = r.skipMiscImmediates()
+	case op == opPrefixSIMD:
+		err = r.skipSIMDImmediates()
+	case op == opPrefixAtomic:
+		err = r.skipAtomicImmediates()
+	default:
+		return nil, fmt.Errorf("unsupported opcode 0x%x at offset %d", op, r.last)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(out, r.buf[r.last:r.pos]...), nil
+}
+
+// rewriteNames rewrites the function indices of the function, local and label names of the name section
+func (m *fuelInstrumenter) rewriteNames(r *wasmReader, out []byte) ([]byte, error) {
+	for !r.eof() {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		contents, err := r.bytes(size)
+		if err != nil {
+			return nil, err
+		}
+		if id == nameSubsectionFn || id == nameSubsectionLoc || id == nameSubsectionLbl {
+			if contents, err = m.rewriteNameMap(newWasmReader(contents), id != nameSubsectionFn); err != nil {
+				return nil, fmt.Errorf("name subsection %d: %w", id, err)
+			}
+		}
+		out = appendU32(append(out, id), uint32(len(contents))) //nolint:gosec // G115: subsections are smaller than 2^32
+		out = append(out, contents...)
+	}
+	return out, nil
+}
+
+// rewriteNameMap rewrites the function indices of a map of names, or of a map of names per function if indirect
+func (m *fuelInstrumenter) rewriteNameMap(r *wasmReader, indirect bool) ([]byte, error) {
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	out := appendU32(nil, count)
+	for i := uint32(0); i < count; i++ {
+		index, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		start := r.pos
+		if indirect {
+			err = r.skipVec(func() error {
+				if _, err := r.u32(); err != nil {
+					return err
+				}
+				_, err := r.name()
+				return err
+			})
+		} else {
+			_, err = r.name()
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = appendU32(out, m.funcIndex(index))
+		out = append(out, r.buf[start:r.pos]...)
+	}
+	return out, nil
+}
+
+// wasmReader decodes a core module binary
+type wasmReader struct {
+	buf []byte
+	pos int
+	// last is the position of the last instruction read
+	last int
+}
+
+func newWasmReader(buf []byte) *wasmReader {
+	return &wasmReader{buf: buf}
+}
+
+var errUnexpectedEnd = errors.New("unexpected end of module")
+
+func (r *wasmReader) eof() bool {
+	return r.pos >= len(r.buf)
+}
+
+func (r *wasmReader) rest() []byte {
+	return r.buf[r.pos:]
+}
+
+func (r *wasmReader) byte() (byte, error) {
+	if r.eof() {
+		return 0, errUnexpectedEnd
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *wasmReader) bytes(n uint32) ([]byte, error) {
+	if uint64(len(r.buf)-r.pos) < uint64(n) {
+		return nil, errUnexpectedEnd
+	}
+	b := r.buf[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+// u32 decodes an unsigned LEB128 integer of at most 32 bits
+func (r *wasmReader) u32() (uint32, error) {
+	var result uint32
+	for shift := 0; shift < 35; shift += 7 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		result |= uint32(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return result, nil
+		}
+	}
+	return 0, errors.New("integer too large")
+}
+
+// skipLEB skips a signed or unsigned LEB128 integer of at most 64 bits
+func (r *wasmReader) skipLEB() error {
+	for i := 0; i < 10; i++ {
+		b, err := r.byte()
+		if err != nil {
+			return err
+		}
+		if b&0x80 == 0 {
+			return nil
+		}
+	}
+	return errors.New("integer too large")
+}
+
+func (r *wasmReader) name() (string, error) {
+	size, err := r.u32()
+	if err != nil {
+		return "", err
+	}
+	b, err := r.bytes(size)
+	return string(b), err
+}
+
+func (r *wasmReader) skipVec(skip func() error) error {
+	count, err := r.u32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		if err = skip(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipLimits skips the limits of a table or memory, whose flags tell if they have a maximum
+func (r *wasmReader) skipLimits() error {
+	flags, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if err = r.skipLEB(); err == nil && flags&0x01 != 0 {
+		err = r.skipLEB()
+	}
+	return err
+}
+
+// skipMemarg skips the alignment and offset of a memory access, whose alignment tells if it has a memory index
+func (r *wasmReader) skipMemarg() error {
+	align, err := r.u32()
+	if err != nil {
+		return err
+	}
+	if align&0x40 != 0 {
+		if _, err = r.u32(); err != nil {
+			return err
+		}
+	}
+	return r.skipLEB()
+}
+
+func (r *wasmReader) skipU32s(n int) error {
+	for i := 0; i < n; i++ {
+		if _, err := r.u32(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipMiscImmediates skips the immediates of the saturating truncation, bulk memory and table instructions
+func (r *wasmReader) skipMiscImmediates() error {
+	op, err := r.u32()
+	if err != nil {
+		return err
+	}
+	switch {
+	case op <= 7:
+		return nil
+	case op == 8 || op == 10 || op == 12 || op == 14:
+		return r.skipU32s(2)
+	case op == 9 || op == 11 || op == 13 || (op >= 15 && op <= 17):
+		return r.skipU32s(1)
+	default:
+		return fmt.Errorf("unsupported opcode 0xfc %d", op)
+	}
+}
+
+// skipSIMDImmediates skips the immediates of the vector instructions
+func (r *wasmReader) skipSIMDImmediates() error {
+	op, err := r.u32()
+	if err != nil {
+		return err
+	}
+	switch {
+	case op <= 11 || op == 92 || op == 93:
+		return r.skipMemarg()
+	case op == 12 || op == 13:
+		_, err = r.bytes(16) // constant or shuffle lanes
+	case op >= 21 && op <= 34:
+		_, err = r.bytes(1) // lane
+	case op >= 84 && op <= 91:
+		if err = r.skipMemarg(); err == nil {
+			_, err = r.bytes(1)
+		}
+	case op <= 0xff:
+		return nil
+	default:
+		return fmt.Errorf("unsupported opcode 0xfd %d", op)
+	}
+	return err
+}
+
+// skipAtomicImmediates skips the immediates of the atomic memory instructions
+func (r *wasmReader) skipAtomicImmediates() error {
+	op, err := r.u32()
+	if err != nil {
+		return err
+	}
+	switch {
+	case op == 3:
+		_, err = r.bytes(1) // atomic.fence
+		return err
+	case op <= 2 || (op >= 0x10 && op <= 0x4e):
+		return r.skipMemarg()
+	default:
+		return fmt.Errorf("unsupported opcode 0xfe %d", op)
+	}
+}
+
+func appendU32(out []byte, v uint32) []byte {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func appendName(out []byte, name string) []byte {
+	out = appendU32(out, uint32(len(name))) //nolint:gosec // G115: names are smaller than 2^32
+	return append(out, name...)
+}
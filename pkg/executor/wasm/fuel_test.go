@@ -0,0 +1,216 @@
+//go:build unit || !integration
+
+package wasm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component/test"
+	"github.com/bacalhau-project/bacalhau/testdata/wasm/cat"
+	"github.com/bacalhau-project/bacalhau/testdata/wasm/csv"
+	"github.com/bacalhau-project/bacalhau/testdata/wasm/dynamic"
+	"github.com/bacalhau-project/bacalhau/testdata/wasm/easter"
+	"github.com/bacalhau-project/bacalhau/testdata/wasm/env"
+	"github.com/bacalhau-project/bacalhau/testdata/wasm/exit_code"
+	"github.com/bacalhau-project/bacalhau/testdata/wasm/logtest"
+	"github.com/bacalhau-project/bacalhau/testdata/wasm/noop"
+)
+
+type FuelTestSuite struct {
+	suite.Suite
+	ctx context.Context
+}
+
+func TestFuelTestSuite(t *testing.T) {
+	suite.Run(t, new(FuelTestSuite))
+}
+
+func (s *FuelTestSuite) SetupTest() {
+	s.ctx = context.Background()
+}
+
+// loopModule is a module importing env.nop, whose _start function loops forever without calling any
+// function, and whose count function counts to 10 in a loop and returns the count doubled by a function
+// it calls through a table. The functions are named in its name section.
+func loopModule() []byte {
+	const i32 = 0x7f
+	loop := []byte{0x03, 0x40}
+	end := []byte{0x0b}
+	return test.Module(
+		test.Section(1, test.Vec(
+			test.FuncType(nil, nil),
+			test.FuncType(nil, []byte{i32}),
+			test.FuncType([]byte{i32}, []byte{i32}),
+		)),
+		test.Section(2, test.Vec(test.Bytes(test.Name("env"), test.Name("nop"), []byte{0x00}, test.U32(0)))),
+		test.Section(3, test.Vec(test.U32(0), test.U32(1), test.U32(2))),
+		test.Section(4, test.Vec([]byte{0x70, 0x00, 0x01})),
+		test.Section(7, test.Vec(
+			test.Bytes(test.Name("_start"), []byte{0x00}, test.U32(1)),
+			test.Bytes(test.Name("count"), []byte{0x00}, test.U32(2)),
+		)),
+		test.Section(9, test.Vec(test.Bytes(test.U32(0), test.I32Const(0), end, test.Vec(test.U32(3))))),
+		test.Section(10, test.Vec(
+			test.Code(loop, []byte{0x0c, 0x00}, end),
+			test.CodeWithLocals(1, i32,
+				test.Call(0),
+				loop,
+				test.LocalGet(0), test.I32Const(1), []byte{0x6a}, test.LocalSet(0),
+				test.LocalGet(0), test.I32Const(10), []byte{0x48, 0x0d, 0x00},
+				end,
+				test.LocalGet(0), test.I32Const(0), []byte{0x11, 0x02, 0x00},
+			),
+			test.Code(test.LocalGet(0), test.LocalGet(0), []byte{0x6a}),
+		)),
+		test.Section(0, test.Name("name"), test.Section(0x01, test.Vec(
+			test.Bytes(test.U32(1), test.Name("spin")),
+			test.Bytes(test.U32(2), test.Name("count_to_ten")),
+			test.Bytes(test.U32(3), test.Name("double")),
+		))),
+	)
+}
+
+// run calls the function of the module with the given fuel budget, and returns the meter, the results
+// and the error of the call
+func (s *FuelTestSuite) run(binary []byte, function string, budget uint64) (*fuelMeter, []uint64, error) {
+	meter := newFuelMeter(budget)
+	ctx := withFuelMeter(s.ctx, meter)
+
+	runtime := wazero.NewRuntime(ctx)
+	defer func() { _ = runtime.Close(ctx) }()
+	wasi_snapshot_preview1.MustInstantiate(ctx, runtime)
+	_, err := runtime.NewHostModuleBuilder("env").NewFunctionBuilder().WithFunc(func() {}).Export("nop").Instantiate(ctx)
+	s.Require().NoError(err)
+	s.Require().NoError(meter.instantiate(ctx, runtime))
+
+	instrumented, err := meterModule(ctx, binary)
+	s.Require().NoError(err)
+	module, err := runtime.CompileModule(ctx, instrumented)
+	s.Require().NoError(err)
+	instance, err := runtime.InstantiateModule(ctx, module, wazero.NewModuleConfig().WithStartFunctions())
+	s.Require().NoError(err)
+
+	results, err := instance.ExportedFunction(function).Call(ctx)
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 0 {
+		err = nil
+	}
+	return meter, results, err
+}
+
+func (s *FuelTestSuite) TestFuelConsumptionIsDeterministic() {
+	first, _, err := s.run(noop.Program(), "_start", 1_000_000)
+	s.Require().NoError(err)
+	s.False(first.Exhausted())
+	s.Positive(first.Consumed())
+
+	second, _, err := s.run(noop.Program(), "_start", 1_000_000)
+	s.Require().NoError(err)
+	s.Equal(first.Consumed(), second.Consumed())
+}
+
+func (s *FuelTestSuite) TestExhaustedBudgetAbortsExecution() {
+	unmetered, _, err := s.run(noop.Program(), "_start", 1_000_000)
+	s.Require().NoError(err)
+
+	budget := unmetered.Consumed() - 1
+	meter, _, err := s.run(noop.Program(), "_start", budget)
+	s.Require().ErrorIs(err, errFuelExhausted)
+	s.True(meter.Exhausted())
+	s.Equal(budget, meter.Consumed())
+}
+
+func (s *FuelTestSuite) TestFunctionEntriesAndLoopIterationsConsumeFuel() {
+	meter, results, err := s.run(loopModule(), "count", 100)
+	s.Require().NoError(err)
+	s.Equal([]uint64{20}, results)
+	// one unit on entry to count and to double, and one unit on each of the 10 iterations of the loop
+	s.Equal(uint64(12), meter.Consumed())
+	s.False(meter.Exhausted())
+}
+
+func (s *FuelTestSuite) TestCallFreeLoopExhaustsBudget() {
+	budget := uint64(3 * fuelBatch / 2)
+	meter, _, err := s.run(loopModule(), "_start", budget)
+	s.Require().ErrorIs(err, errFuelExhausted)
+	s.True(meter.Exhausted())
+	s.Equal(budget, meter.Consumed())
+}
+
+func (s *FuelTestSuite) TestModulesShareBudget() {
+	meter := newFuelMeter(15)
+	ctx := withFuelMeter(s.ctx, meter)
+	runtime := wazero.NewRuntime(ctx)
+	defer func() { _ = runtime.Close(ctx) }()
+	_, err := runtime.NewHostModuleBuilder("env").NewFunctionBuilder().WithFunc(func() {}).Export("nop").Instantiate(ctx)
+	s.Require().NoError(err)
+	s.Require().NoError(meter.instantiate(ctx, runtime))
+
+	instrumented, err := meterModule(ctx, loopModule())
+	s.Require().NoError(err)
+	module, err := runtime.CompileModule(ctx, instrumented)
+	s.Require().NoError(err)
+	var instances []api.Module
+	for _, name := range []string{"first", "second"} {
+		instance, err := runtime.InstantiateModule(ctx, module, wazero.NewModuleConfig().WithName(name).WithStartFunctions())
+		s.Require().NoError(err)
+		instances = append(instances, instance)
+	}
+
+	_, err = instances[0].ExportedFunction("count").Call(ctx)
+	s.Require().NoError(err)
+	_, err = instances[1].ExportedFunction("count").Call(ctx)
+	s.Require().ErrorIs(err, errFuelExhausted)
+	s.Equal(uint64(15), meter.Consumed())
+}
+
+func (s *FuelTestSuite) TestInstrumentationKeepsFunctionNames() {
+	instrumented, err := instrumentFuel(loopModule())
+	s.Require().NoError(err)
+	s.True(hasNameSection(instrumented))
+
+	runtime := wazero.NewRuntime(s.ctx)
+	defer func() { _ = runtime.Close(s.ctx) }()
+	module, err := runtime.CompileModule(s.ctx, instrumented)
+	s.Require().NoError(err)
+	s.Equal("count_to_ten", module.ExportedFunctions()["count"].Name())
+	s.Equal("spin", module.ExportedFunctions()["_start"].Name())
+}
+
+func (s *FuelTestSuite) TestInstrumentedProgramsCompile() {
+	runtime := wazero.NewRuntime(s.ctx)
+	defer func() { _ = runtime.Close(s.ctx) }()
+	programs := map[string][]byte{
+		"cat":       cat.Program(),
+		"csv":       csv.Program(),
+		"dynamic":   dynamic.Program(),
+		"easter":    easter.Program(),
+		"env":       env.Program(),
+		"exit_code": exit_code.Program(),
+		"logtest":   logtest.Program(),
+		"noop":      noop.Program(),
+	}
+	for name, program := range programs {
+		instrumented, err := instrumentFuel(program)
+		s.Require().NoError(err, name)
+		_, err = runtime.CompileModule(s.ctx, instrumented)
+		s.Require().NoError(err, name)
+	}
+}
+
+func (s *FuelTestSuite) TestInstrumentRejectsInvalidModules() {
+	_, err := instrumentFuel([]byte("not a module"))
+	s.Error(err)
+
+	truncated := loopModule()
+	_, err = instrumentFuel(truncated[:len(truncated)-10])
+	s.Error(err)
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
+)
+
+// ComponentInterface is the interface of the HTTP functions imported by WebAssembly components.
+// See wit/http.wit for its definition.
+const ComponentInterface = "bacalhau:http/requests@0.1.0"
+
+// LinkComponent defines the HTTP interface of components in the linker
+func LinkComponent(linker *component.Linker, params Params) {
+	if params.Network == nil || params.Network.Disabled() {
+		return // Don't register any network functions
+	}
+
+	httpModule := newHTTPModule(params)
+	linker.Instance(ComponentInterface).Func("send", httpModule.send)
+}
+
+// send implements the send function of the component interface. The request and response records hold
+// the method, url, headers and body, and the status, headers and body respectively. Errors are returned
+// as the cases of the error-code enum, which follow the status codes of the module.
+func (m *module) send(ctx context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	request := a.Record(0)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	fields := component.NewArgs(request)
+	method := fields.Uint32(0)
+	url := fields.String(1)
+	headerList := fields.List(2)
+	body := fields.Bytes(3)
+	if fields.Err() != nil {
+		return nil, fields.Err()
+	}
+	headers := make(http.Header)
+	for _, header := range headerList {
+		pair := component.NewArgs([]any{header})
+		fields := component.NewArgs(pair.Record(0))
+		name, value := fields.String(0), fields.String(1)
+		if err := errors.Join(pair.Err(), fields.Err()); err != nil {
+			return nil, err
+		}
+		headers.Add(name, value)
+	}
+
+	req, err := m.newRequest(ctx, method, url, headers, body)
+	if err != nil {
+		return errorCode(requestErrorStatus(err)), nil
+	}
+	resp, respBody, status := m.do(req, m.params.MaxResponseSize)
+	if status != StatusSuccess {
+		return errorCode(status), nil
+	}
+
+	var respHeaders []any
+	for name, values := range resp.Header {
+		for _, value := range values {
+			respHeaders = append(respHeaders, []any{name, value})
+		}
+	}
+	return component.Ok([]any{safeUint16(resp.StatusCode), respHeaders, respBody}), nil
+}
+
+// errorCode returns the error-code of a status, whose cases start at StatusInvalidURL
+func errorCode(status uint32) component.Variant {
+	return component.Err(status - StatusInvalidURL)
+}
+
+// safeUint16 converts an HTTP status code to uint16
+func safeUint16(n int) uint16 {
+	if n < 0 || n > 0xffff {
+		return 0
+	}
+	return uint16(n)
+}
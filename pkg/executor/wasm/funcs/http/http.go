@@ -1,6 +1,7 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
@@ -157,56 +158,74 @@ func (m *module) httpRequest(
 	// Prepare the request
 	req, err := m.prepareHTTPRequest(ctx, mod, method, urlPtr, urlLen, headersPtr, headersLen, bodyPtr, bodyLen)
 	if err != nil {
-		switch err.Error() {
-		case errInvalidURL:
-			return StatusInvalidURL
-		case errHostNotAllowed:
-			return StatusNotAllowed
-		case errInvalidBody:
-			return StatusBadInput
-		default:
-			return StatusInvalidURL
-		}
+		return requestErrorStatus(err)
+	}
+
+	// Execute request, reading the response up to the maximum allowed size
+	resp, respBody, status := m.do(req, m.calculateMaxResponseSize(mod))
+	if status != StatusSuccess {
+		return status
+	}
+
+	// Write response to memory
+	return m.writeResponseToMemory(mod, resp, respBody,
+		responseHeadersPtr, responseHeadersLenPtr,
+		responseBodyPtr, responseBodyLenPtr,
+		statusPtr)
+}
+
+// requestErrorStatus returns the status of an error preparing a request
+func requestErrorStatus(err error) uint32 {
+	switch err.Error() {
+	case errInvalidURL:
+		return StatusInvalidURL
+	case errHostNotAllowed:
+		return StatusNotAllowed
+	case errInvalidBody:
+		return StatusBadInput
+	default:
+		return StatusInvalidURL
 	}
+}
 
-	// Execute request
+// do executes the request and reads its response body, up to maxSize bytes
+func (m *module) do(req *http.Request, maxSize uint64) (*http.Response, []byte, uint32) {
 	resp, err := m.client.Do(req)
 	if err != nil {
 		// Check for timeout
 		var urlErr *url.Error
 		if errors.As(err, &urlErr) && urlErr.Timeout() {
-			return StatusTimeout
+			return nil, nil, StatusTimeout
 		}
-		return StatusNetworkError
+		return nil, nil, StatusNetworkError
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	// Calculate maximum allowed response size and read response with limit
-	maxSize := m.calculateMaxResponseSize(mod)
 	respBody, err := io.ReadAll(io.LimitReader(resp.Body, safeInt64(maxSize)))
 	if err != nil {
-		return StatusNetworkError
+		return nil, nil, StatusNetworkError
 	}
-
-	// Write response to memory
-	return m.writeResponseToMemory(mod, resp, respBody,
-		responseHeadersPtr, responseHeadersLenPtr,
-		responseBodyPtr, responseBodyLenPtr,
-		statusPtr)
+	return resp, respBody, StatusSuccess
 }
 
 // isHostAllowed checks if the given host is allowed according to the configuration
 func (m *module) isHostAllowed(host string) bool {
-	if m.params.Network == nil {
+	return IsHostAllowed(m.params.Network, host)
+}
+
+// IsHostAllowed checks if the given host is allowed by the network configuration of a job.
+// Other host modules that reach remote services use it to apply the same network gating.
+func IsHostAllowed(network *models.NetworkConfig, host string) bool {
+	if network == nil {
 		return false
 	}
 
-	if m.params.Network.Type == models.NetworkFull || m.params.Network.Type == models.NetworkHost {
+	if network.Type == models.NetworkFull || network.Type == models.NetworkHost {
 		return true
 	}
 
-	if m.params.Network.Type == models.NetworkHTTP {
-		allowedDomains := m.params.Network.DomainSet()
+	if network.Type == models.NetworkHTTP {
+		allowedDomains := network.DomainSet()
 		for _, allowed := range allowedDomains {
 			if matched, _ := matchWildcard(allowed, host); matched {
 				return true
@@ -290,17 +309,6 @@ func (m *module) prepareHTTPRequest(
 		return nil, errors.New(errInvalidURL)
 	}
 
-	urlStr := string(urlBytes)
-	parsedURL, err := url.Parse(urlStr)
-	if err != nil {
-		return nil, errors.New(errInvalidURL)
-	}
-
-	// Check if the host is allowed
-	if !m.isHostAllowed(parsedURL.Host) {
-		return nil, errors.New(errHostNotAllowed)
-	}
-
 	// Read headers if provided
 	headers := make(http.Header)
 	if headersPtr != 0 && headersLen > 0 {
@@ -319,11 +327,32 @@ func (m *module) prepareHTTPRequest(
 		}
 	}
 
+	return m.newRequest(ctx, method, string(urlBytes), headers, bodyBytes)
+}
+
+// newRequest creates a request to an allowed host
+func (m *module) newRequest(
+	ctx context.Context,
+	method uint32,
+	urlStr string,
+	headers http.Header,
+	body []byte,
+) (*http.Request, error) {
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, errors.New(errInvalidURL)
+	}
+
+	// Check if the host is allowed
+	if !m.isHostAllowed(parsedURL.Host) {
+		return nil, errors.New(errHostNotAllowed)
+	}
+
 	methodStr := methodToString(method)
 
 	var reqBody io.Reader
-	if len(bodyBytes) > 0 {
-		reqBody = strings.NewReader(string(bodyBytes))
+	if len(body) > 0 {
+		reqBody = bytes.NewReader(body)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, methodStr, urlStr, reqBody)
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
 )
 
@@ -255,6 +256,45 @@ func (s *HTTPUnitSuite) TestHeaderParsing() {
 	}
 }
 
+// TestComponentSend tests the send function of the component interface
+func (s *HTTPUnitSuite) TestComponentSend() {
+	request := func(method uint32, url string) []any {
+		headers := []any{[]any{"Content-Type", "application/json"}}
+		return []any{method, url, headers, []byte(`{"name": "test"}`)}
+	}
+
+	s.Run("Success", func() {
+		result, err := s.module.send(s.ctx, []any{request(MethodPost, s.serverURL+"/post")})
+		s.Require().NoError(err)
+		response := result.(component.Variant)
+		s.Require().False(response.IsErr())
+		fields := response.Value.([]any)
+		s.Equal(uint16(http.StatusCreated), fields[0])
+		s.Contains(fields[1], []any{"Content-Type", "application/json"})
+		s.Equal([]byte(`{"message": "Created"}`), fields[2])
+	})
+
+	s.Run("Invalid URL", func() {
+		result, err := s.module.send(s.ctx, []any{request(MethodGet, "://invalid")})
+		s.Require().NoError(err)
+		s.Equal(component.Err(uint32(0)), result)
+	})
+
+	s.Run("Host not allowed", func() {
+		m := newHTTPModule(Params{
+			Network: &models.NetworkConfig{Type: models.NetworkHTTP, Domains: []string{"example.com"}},
+		})
+		result, err := m.send(s.ctx, []any{request(MethodGet, s.serverURL+"/get")})
+		s.Require().NoError(err)
+		s.Equal(errorCode(StatusNotAllowed), result)
+	})
+
+	s.Run("Invalid request", func() {
+		_, err := s.module.send(s.ctx, []any{"not a record"})
+		s.Error(err)
+	})
+}
+
 // Run the test suite
 func TestHTTPUnit(t *testing.T) {
 	suite.Run(t, new(HTTPUnitSuite))
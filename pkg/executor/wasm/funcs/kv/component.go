@@ -0,0 +1,100 @@
+package kv
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
+)
+
+// ComponentInterface is the interface of the key-value functions imported by WebAssembly components.
+// See wit/kv.wit for its definition.
+const ComponentInterface = "bacalhau:kv/store@0.1.0"
+
+// errorCodes maps the status codes of the module to the cases of the error-code enum
+var errorCodes = map[uint32]uint32{
+	StatusBadInput:   0,
+	StatusTooLarge:   1,
+	StatusStoreError: 2,
+}
+
+// LinkComponent defines the key-value interface of components in the linker
+func LinkComponent(linker *component.Linker, params Params) error {
+	if params.Network == nil || params.Network.Disabled() || params.Store == nil {
+		return nil // Don't register any key-value functions
+	}
+	if params.Scope == "" {
+		return errors.New("key-value scope is required")
+	}
+
+	kvModule := newKVModule(params)
+	linker.Instance(ComponentInterface).
+		Func("get", kvModule.componentGet).
+		Func("put", kvModule.componentPut).
+		Func("delete", kvModule.componentDelete)
+	return nil
+}
+
+// componentGet implements get, which returns none if the key does not exist
+func (m *module) componentGet(ctx context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	key := a.String(0)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	key, status := m.scopedKey(key)
+	if status != StatusSuccess {
+		return errorCode(status), nil
+	}
+	value, status := m.getValue(ctx, key)
+	if status == StatusNotFound {
+		return component.Ok(component.None()), nil
+	}
+	if status != StatusSuccess {
+		return errorCode(status), nil
+	}
+	return component.Ok(component.Some(value)), nil
+}
+
+// componentPut implements put
+func (m *module) componentPut(ctx context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	key, value := a.String(0), a.Bytes(1)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	key, status := m.scopedKey(key)
+	if status == StatusSuccess {
+		status = m.putValue(ctx, key, value)
+	}
+	if status != StatusSuccess {
+		return errorCode(status), nil
+	}
+	return component.Ok(nil), nil
+}
+
+// componentDelete implements delete. Deleting a key that does not exist succeeds.
+func (m *module) componentDelete(ctx context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	key := a.String(0)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	key, status := m.scopedKey(key)
+	if status == StatusSuccess {
+		status = m.deleteValue(ctx, key)
+	}
+	if status != StatusSuccess {
+		return errorCode(status), nil
+	}
+	return component.Ok(nil), nil
+}
+
+// errorCode returns the error-code of a status
+func errorCode(status uint32) component.Variant {
+	code, ok := errorCodes[status]
+	if !ok {
+		code = errorCodes[StatusStoreError]
+	}
+	return component.Err(code)
+}
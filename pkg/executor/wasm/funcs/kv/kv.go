@@ -0,0 +1,264 @@
+package kv
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+// ModuleName Defines the key-value store function namespace
+const ModuleName = "bacalhau:kv/store"
+
+// Result codes
+const (
+	StatusSuccess        uint32 = 0
+	StatusNotFound       uint32 = 1
+	StatusBadInput       uint32 = 2
+	StatusTooLarge       uint32 = 3
+	StatusBufferTooSmall uint32 = 4
+	StatusMemoryError    uint32 = 5
+	StatusStoreError     uint32 = 6
+)
+
+const (
+	// DefaultTimeout is the default timeout for key-value operations
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultMaxValueSize is the default maximum size of a value
+	DefaultMaxValueSize = 1024 * 1024 // 1MB
+
+	// MaxKeyLength is the maximum length of a key, excluding its scope
+	MaxKeyLength = 256
+)
+
+// ErrKeyNotFound is returned by stores when the key does not exist
+var ErrKeyNotFound = errors.New("key not found")
+
+// validKey matches dot separated tokens of the characters allowed in keys
+var validKey = regexp.MustCompile(`^[-/_=a-zA-Z0-9]+(\.[-/_=a-zA-Z0-9]+)*$`)
+
+// Store is the key-value store backing the host functions
+type Store interface {
+	// Get returns the value of the key, or ErrKeyNotFound if it does not exist
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put sets the value of the key
+	Put(ctx context.Context, key string, value []byte) error
+	// Delete removes the key
+	Delete(ctx context.Context, key string) error
+}
+
+type Params struct {
+	// Network defines the networking configuration of the job.
+	// The functions are not registered if networking is disabled.
+	Network *models.NetworkConfig
+
+	// Store is the store backing the functions. The functions are not registered if nil.
+	Store Store
+
+	// Scope prefixes all keys, so that jobs can only access the keys of their scope
+	Scope string
+
+	// Timeout specifies the maximum duration of an operation
+	Timeout time.Duration
+
+	// MaxValueSize is the maximum allowed size of a value
+	// Default is 1MB
+	MaxValueSize uint64
+}
+
+// JobScope returns the scope of the keys private to a job
+func JobScope(jobID string) string {
+	return "job." + base64.RawURLEncoding.EncodeToString([]byte(jobID))
+}
+
+// NamespaceScope returns the scope of the keys shared by all jobs of a namespace
+func NamespaceScope(namespace string) string {
+	return "namespace." + base64.RawURLEncoding.EncodeToString([]byte(namespace))
+}
+
+// InstantiateModule instantiates the key-value host functions
+func InstantiateModule(ctx context.Context, r wazero.Runtime, params Params) error {
+	if params.Network == nil || params.Network.Disabled() || params.Store == nil {
+		return nil // Don't register any key-value functions
+	}
+	if params.Scope == "" {
+		return errors.New("key-value scope is required")
+	}
+
+	kvModule := newKVModule(params)
+
+	moduleBuilder := r.NewHostModuleBuilder(ModuleName)
+
+	moduleBuilder.NewFunctionBuilder().
+		WithFunc(kvModule.get).
+		WithName("kv_get").
+		WithParameterNames("key_ptr", "key_len", "value_ptr", "value_len_ptr").
+		WithResultNames("status_code").
+		Export("kv_get")
+
+	moduleBuilder.NewFunctionBuilder().
+		WithFunc(kvModule.put).
+		WithName("kv_put").
+		WithParameterNames("key_ptr", "key_len", "value_ptr", "value_len").
+		WithResultNames("status_code").
+		Export("kv_put")
+
+	moduleBuilder.NewFunctionBuilder().
+		WithFunc(kvModule.delete).
+		WithName("kv_delete").
+		WithParameterNames("key_ptr", "key_len").
+		WithResultNames("status_code").
+		Export("kv_delete")
+
+	_, err := moduleBuilder.Instantiate(ctx)
+	return err
+}
+
+// module manages key-value functionality for WASM
+type module struct {
+	params Params
+}
+
+func newKVModule(params Params) *module {
+	if params.Timeout == 0 {
+		params.Timeout = DefaultTimeout
+	}
+	if params.MaxValueSize == 0 {
+		params.MaxValueSize = DefaultMaxValueSize
+	}
+	return &module{params: params}
+}
+
+// get reads the value of a key into the buffer at value_ptr. The buffer size is read from value_len_ptr,
+// which is set to the length of the value. If the buffer is too small, nothing is written to it and
+// StatusBufferTooSmall is returned, so that the caller can retry with a larger buffer.
+func (m *module) get(ctx context.Context, mod api.Module, keyPtr, keyLen, valuePtr, valueLenPtr uint32) uint32 {
+	key, status := m.readKey(mod, keyPtr, keyLen)
+	if status != StatusSuccess {
+		return status
+	}
+
+	memory := mod.Memory()
+	bufSize, ok := memory.ReadUint32Le(valueLenPtr)
+	if !ok {
+		return StatusMemoryError
+	}
+
+	value, status := m.getValue(ctx, key)
+	if status != StatusSuccess {
+		return status
+	}
+
+	if !memory.WriteUint32Le(valueLenPtr, safeUint32(len(value))) {
+		return StatusMemoryError
+	}
+	if safeUint32(len(value)) > bufSize {
+		return StatusBufferTooSmall
+	}
+	if len(value) > 0 && !memory.Write(valuePtr, value) {
+		return StatusMemoryError
+	}
+	return StatusSuccess
+}
+
+// put sets the value of a key
+func (m *module) put(ctx context.Context, mod api.Module, keyPtr, keyLen, valuePtr, valueLen uint32) uint32 {
+	key, status := m.readKey(mod, keyPtr, keyLen)
+	if status != StatusSuccess {
+		return status
+	}
+	if uint64(valueLen) > m.params.MaxValueSize {
+		return StatusTooLarge
+	}
+
+	value, ok := mod.Memory().Read(valuePtr, valueLen)
+	if !ok {
+		return StatusMemoryError
+	}
+	// copy the value, as the memory view changes with the guest
+	return m.putValue(ctx, key, append([]byte(nil), value...))
+}
+
+// delete removes a key. Deleting a key that does not exist succeeds.
+func (m *module) delete(ctx context.Context, mod api.Module, keyPtr, keyLen uint32) uint32 {
+	key, status := m.readKey(mod, keyPtr, keyLen)
+	if status != StatusSuccess {
+		return status
+	}
+	return m.deleteValue(ctx, key)
+}
+
+// getValue returns the value of a scoped key
+func (m *module) getValue(ctx context.Context, key string) ([]byte, uint32) {
+	ctx, cancel := context.WithTimeout(ctx, m.params.Timeout)
+	defer cancel()
+	value, err := m.params.Store.Get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, StatusNotFound
+	}
+	if err != nil {
+		return nil, StatusStoreError
+	}
+	return value, StatusSuccess
+}
+
+// putValue sets the value of a scoped key
+func (m *module) putValue(ctx context.Context, key string, value []byte) uint32 {
+	if uint64(len(value)) > m.params.MaxValueSize {
+		return StatusTooLarge
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.params.Timeout)
+	defer cancel()
+	if err := m.params.Store.Put(ctx, key, value); err != nil {
+		return StatusStoreError
+	}
+	return StatusSuccess
+}
+
+// deleteValue removes a scoped key
+func (m *module) deleteValue(ctx context.Context, key string) uint32 {
+	ctx, cancel := context.WithTimeout(ctx, m.params.Timeout)
+	defer cancel()
+	if err := m.params.Store.Delete(ctx, key); err != nil {
+		return StatusStoreError
+	}
+	return StatusSuccess
+}
+
+// readKey reads the key from WASM memory, validates it and prefixes it with the scope
+func (m *module) readKey(mod api.Module, keyPtr, keyLen uint32) (string, uint32) {
+	if keyLen == 0 || keyLen > MaxKeyLength {
+		return "", StatusBadInput
+	}
+	keyBytes, ok := mod.Memory().Read(keyPtr, keyLen)
+	if !ok {
+		return "", StatusMemoryError
+	}
+	return m.scopedKey(string(keyBytes))
+}
+
+// scopedKey validates the key and prefixes it with the scope
+func (m *module) scopedKey(key string) (string, uint32) {
+	if key == "" || len(key) > MaxKeyLength || !validKey.MatchString(key) {
+		return "", StatusBadInput
+	}
+	return m.params.Scope + "." + key, StatusSuccess
+}
+
+// safeUint32 converts an int to uint32, capping at the maximum value
+func safeUint32(n int) uint32 {
+	if n < 0 {
+		return 0
+	}
+	if uint64(n) > uint64(^uint32(0)) {
+		return ^uint32(0)
+	}
+	return uint32(n)
+}
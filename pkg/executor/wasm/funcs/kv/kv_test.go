@@ -0,0 +1,267 @@
+//go:build unit || !integration
+
+package kv
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental/wazerotest"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+// Offsets of the guest memory used by the tests
+const (
+	keyPtr      uint32 = 0
+	valueLenPtr uint32 = 1024
+	valuePtr    uint32 = 2048
+)
+
+// memoryStore is an in-memory Store
+type memoryStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	err    error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{values: make(map[string][]byte)}
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	value, ok := s.values[key]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return value, nil
+}
+
+func (s *memoryStore) Put(_ context.Context, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.values[key] = value
+	return nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.values, key)
+	return nil
+}
+
+type KVUnitSuite struct {
+	suite.Suite
+	ctx    context.Context
+	store  *memoryStore
+	module *module
+	guest  *wazerotest.Module
+}
+
+func TestKVUnit(t *testing.T) {
+	suite.Run(t, new(KVUnitSuite))
+}
+
+func (s *KVUnitSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.store = newMemoryStore()
+	s.module = newKVModule(Params{
+		Network:      &models.NetworkConfig{Type: models.NetworkHost},
+		Store:        s.store,
+		Scope:        JobScope("job-1"),
+		MaxValueSize: 16,
+	})
+	s.guest = wazerotest.NewModule(wazerotest.NewMemory(wazerotest.PageSize))
+}
+
+func (s *KVUnitSuite) writeKey(key string) uint32 {
+	s.Require().True(s.guest.Memory().WriteString(keyPtr, key))
+	return safeUint32(len(key))
+}
+
+func (s *KVUnitSuite) put(key, value string) uint32 {
+	keyLen := s.writeKey(key)
+	s.Require().True(s.guest.Memory().WriteString(valuePtr, value))
+	return s.module.put(s.ctx, s.guest, keyPtr, keyLen, valuePtr, safeUint32(len(value)))
+}
+
+// get reads the value of the key with a buffer of the given size
+func (s *KVUnitSuite) get(key string, bufSize uint32) (string, uint32) {
+	keyLen := s.writeKey(key)
+	s.Require().True(s.guest.Memory().WriteUint32Le(valueLenPtr, bufSize))
+	status := s.module.get(s.ctx, s.guest, keyPtr, keyLen, valuePtr, valueLenPtr)
+	if status != StatusSuccess {
+		return "", status
+	}
+	valueLen, ok := s.guest.Memory().ReadUint32Le(valueLenPtr)
+	s.Require().True(ok)
+	value, ok := s.guest.Memory().Read(valuePtr, valueLen)
+	s.Require().True(ok)
+	return string(value), status
+}
+
+func (s *KVUnitSuite) TestPutGetDelete() {
+	s.Equal(StatusSuccess, s.put("counter", "42"))
+
+	value, status := s.get("counter", 16)
+	s.Equal(StatusSuccess, status)
+	s.Equal("42", value)
+
+	keyLen := s.writeKey("counter")
+	s.Equal(StatusSuccess, s.module.delete(s.ctx, s.guest, keyPtr, keyLen))
+	_, status = s.get("counter", 16)
+	s.Equal(StatusNotFound, status)
+
+	// deleting a missing key succeeds
+	s.Equal(StatusSuccess, s.module.delete(s.ctx, s.guest, keyPtr, keyLen))
+}
+
+func (s *KVUnitSuite) TestKeysAreScoped() {
+	s.Equal(StatusSuccess, s.put("counter", "42"))
+	s.Contains(s.store.values, JobScope("job-1")+".counter")
+
+	other := newKVModule(Params{
+		Network: &models.NetworkConfig{Type: models.NetworkHost},
+		Store:   s.store,
+		Scope:   JobScope("job-2"),
+	})
+	keyLen := s.writeKey("counter")
+	s.Require().True(s.guest.Memory().WriteUint32Le(valueLenPtr, 16))
+	s.Equal(StatusNotFound, other.get(s.ctx, s.guest, keyPtr, keyLen, valuePtr, valueLenPtr))
+}
+
+func (s *KVUnitSuite) TestBufferTooSmall() {
+	s.Equal(StatusSuccess, s.put("greeting", "hello"))
+
+	_, status := s.get("greeting", 2)
+	s.Equal(StatusBufferTooSmall, status)
+
+	// the length of the value is reported so that the caller can retry with a large enough buffer
+	valueLen, ok := s.guest.Memory().ReadUint32Le(valueLenPtr)
+	s.Require().True(ok)
+	s.EqualValues(5, valueLen)
+}
+
+func (s *KVUnitSuite) TestInvalidInput() {
+	for _, key := range []string{"", "with space", "trailing.", ".leading", "a..b", string(make([]byte, MaxKeyLength+1))} {
+		s.Equal(StatusBadInput, s.put(key, "value"), "key %q", key)
+	}
+	s.Equal(StatusTooLarge, s.put("large", "this value is too large"))
+	s.Equal(StatusMemoryError, s.module.put(s.ctx, s.guest, keyPtr, s.writeKey("key"), wazerotest.PageSize, 8))
+}
+
+func (s *KVUnitSuite) TestStoreError() {
+	s.store.err = errors.New("unavailable")
+	s.Equal(StatusStoreError, s.put("counter", "42"))
+	_, status := s.get("counter", 16)
+	s.Equal(StatusStoreError, status)
+}
+
+func (s *KVUnitSuite) TestScopes() {
+	s.NotEqual(JobScope("default"), NamespaceScope("default"))
+	// scopes can't be forged from keys, as their identifiers are encoded
+	s.True(validKey.MatchString(JobScope("job.with/odd chars")[len("job."):]))
+}
+
+func (s *KVUnitSuite) TestInstantiateModule() {
+	cases := []struct {
+		name       string
+		params     Params
+		registered bool
+		expectErr  bool
+	}{
+		{
+			name:       "registered if networking is enabled",
+			params:     Params{Network: &models.NetworkConfig{Type: models.NetworkHost}, Store: s.store, Scope: JobScope("job")},
+			registered: true,
+		},
+		{
+			name:   "not registered if networking is disabled",
+			params: Params{Network: &models.NetworkConfig{Type: models.NetworkNone}, Store: s.store, Scope: JobScope("job")},
+		},
+		{
+			name:   "not registered without a store",
+			params: Params{Network: &models.NetworkConfig{Type: models.NetworkHost}, Scope: JobScope("job")},
+		},
+		{
+			name:      "scope is required",
+			params:    Params{Network: &models.NetworkConfig{Type: models.NetworkHost}, Store: s.store},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		s.Run(tc.name, func() {
+			runtime := wazero.NewRuntime(s.ctx)
+			defer func() { _ = runtime.Close(s.ctx) }()
+
+			err := InstantiateModule(s.ctx, runtime, tc.params)
+			if tc.expectErr {
+				s.Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(tc.registered, runtime.Module(ModuleName) != nil)
+		})
+	}
+}
+
+// TestComponentFunctions tests the functions of the component interface
+func (s *KVUnitSuite) TestComponentFunctions() {
+	result, err := s.module.componentPut(s.ctx, []any{"counter", []byte("42")})
+	s.Require().NoError(err)
+	s.Equal(component.Ok(nil), result)
+	s.Contains(s.store.values, JobScope("job-1")+".counter")
+
+	result, err = s.module.componentGet(s.ctx, []any{"counter"})
+	s.Require().NoError(err)
+	s.Equal(component.Ok(component.Some([]byte("42"))), result)
+
+	result, err = s.module.componentDelete(s.ctx, []any{"counter"})
+	s.Require().NoError(err)
+	s.Equal(component.Ok(nil), result)
+
+	result, err = s.module.componentGet(s.ctx, []any{"counter"})
+	s.Require().NoError(err)
+	s.Equal(component.Ok(component.None()), result, "missing keys are none")
+
+	result, err = s.module.componentPut(s.ctx, []any{"a..b", []byte("42")})
+	s.Require().NoError(err)
+	s.Equal(component.Err(uint32(0)), result, "bad-input")
+	result, err = s.module.componentPut(s.ctx, []any{"large", []byte("this value is too large")})
+	s.Require().NoError(err)
+	s.Equal(component.Err(uint32(1)), result, "too-large")
+
+	s.store.err = errors.New("unavailable")
+	result, err = s.module.componentGet(s.ctx, []any{"counter"})
+	s.Require().NoError(err)
+	s.Equal(component.Err(uint32(2)), result, "store-error")
+
+	_, err = s.module.componentGet(s.ctx, []any{42})
+	s.Error(err, "invalid arguments trap")
+}
+
+func (s *KVUnitSuite) TestLinkComponent() {
+	linker := component.NewLinker()
+	s.Require().NoError(LinkComponent(linker, Params{Network: &models.NetworkConfig{Type: models.NetworkNone}, Store: s.store}))
+	s.Error(LinkComponent(linker, Params{Network: &models.NetworkConfig{Type: models.NetworkHost}, Store: s.store}),
+		"scope is required")
+	s.NoError(LinkComponent(linker, Params{Network: &models.NetworkConfig{Type: models.NetworkHost}, Store: s.store, Scope: "job"}))
+}
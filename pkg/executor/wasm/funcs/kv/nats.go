@@ -0,0 +1,127 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	natsutil "github.com/bacalhau-project/bacalhau/pkg/nats"
+)
+
+type NATSStoreParams struct {
+	// ClientFactory creates the client connected to the orchestrator
+	ClientFactory natsutil.ClientFactory
+	// Bucket is the name of the JetStream key-value bucket
+	Bucket string
+}
+
+// NATSStore is a Store backed by a JetStream key-value bucket on the orchestrator.
+// It connects lazily on first use, so that nodes that never run jobs using the store don't hold a connection.
+type NATSStore struct {
+	clientFactory natsutil.ClientFactory
+	bucket        string
+
+	mu   sync.Mutex
+	conn *nats.Conn
+	kv   jetstream.KeyValue
+}
+
+func NewNATSStore(params NATSStoreParams) (*NATSStore, error) {
+	bucket := strings.ToLower(params.Bucket)
+	if bucket == "" {
+		return nil, errors.New("key-value bucket name is required")
+	}
+	if params.ClientFactory == nil {
+		return nil, errors.New("nats client factory is required")
+	}
+	return &NATSStore{
+		clientFactory: params.ClientFactory,
+		bucket:        bucket,
+	}, nil
+}
+
+// keyValue returns the bucket, connecting and creating it if it does not exist
+func (s *NATSStore) keyValue(ctx context.Context) (jetstream.KeyValue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.kv != nil {
+		return s.kv, nil
+	}
+
+	if s.conn == nil {
+		conn, err := s.clientFactory.CreateClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to key-value store: %w", err)
+		}
+		s.conn = conn
+	}
+	js, err := jetstream.New(s.conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to jetstream: %w", err)
+	}
+
+	kv, err := js.KeyValue(ctx, s.bucket)
+	if errors.Is(err, jetstream.ErrBucketNotFound) {
+		kv, err = js.CreateKeyValue(ctx, jetstream.KeyValueConfig{Bucket: s.bucket})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open key-value bucket %s: %w", s.bucket, err)
+	}
+	s.kv = kv
+	return kv, nil
+}
+
+// Get implements Store
+func (s *NATSStore) Get(ctx context.Context, key string) ([]byte, error) {
+	kv, err := s.keyValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := kv.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return entry.Value(), nil
+}
+
+// Put implements Store
+func (s *NATSStore) Put(ctx context.Context, key string, value []byte) error {
+	kv, err := s.keyValue(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = kv.Put(ctx, key, value)
+	return err
+}
+
+// Delete implements Store
+func (s *NATSStore) Delete(ctx context.Context, key string) error {
+	kv, err := s.keyValue(ctx)
+	if err != nil {
+		return err
+	}
+	return kv.Delete(ctx, key)
+}
+
+// Close closes the connection to the store, if any
+func (s *NATSStore) Close(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+		s.kv = nil
+	}
+	return nil
+}
+
+// compile-time check that we implement the interface
+var _ Store = (*NATSStore)(nil)
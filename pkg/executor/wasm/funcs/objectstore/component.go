@@ -0,0 +1,119 @@
+package objectstore
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
+)
+
+// ComponentInterface is the interface of the object store functions imported by WebAssembly components.
+// See wit/s3.wit for its definition.
+const ComponentInterface = "bacalhau:object-store/s3@0.1.0"
+
+// maxComponentRead is the maximum number of bytes a component reads from an object at once
+const maxComponentRead = 1024 * 1024
+
+// errorCodes maps the status codes of the module to the cases of the error-code enum
+var errorCodes = map[uint32]uint32{
+	StatusNotFound:       0,
+	StatusNotAllowed:     1,
+	StatusBadInput:       2,
+	StatusStoreError:     3,
+	StatusBadHandle:      4,
+	StatusTooManyObjects: 5,
+}
+
+// LinkComponent defines the object store interface of components in the linker. Objects are resources
+// whose representation is their handle, and objects the component drops without closing are aborted.
+func LinkComponent(linker *component.Linker, params Params) {
+	if params.Network == nil || params.Network.Disabled() || params.Client == nil || len(params.Buckets) == 0 {
+		return // Don't register any object store functions
+	}
+
+	objectModule := newObjectStoreModule(params)
+	linker.Instance(ComponentInterface).
+		Resource("object", component.NewHostResource("object", objectModule.dropObject)).
+		Func("open-read", objectModule.componentOpen(false)).
+		Func("open-write", objectModule.componentOpen(true)).
+		Func("[method]object.read", objectModule.componentRead).
+		Func("[method]object.write", objectModule.componentWrite).
+		Func("close", objectModule.componentClose)
+}
+
+// componentOpen implements open-read and open-write, which return the opened object
+func (m *module) componentOpen(write bool) component.HostFunc {
+	return func(ctx context.Context, args []any) (any, error) {
+		a := component.NewArgs(args)
+		bucketName, key := a.String(0), a.String(1)
+		if a.Err() != nil {
+			return nil, a.Err()
+		}
+		handle, status := m.open(ctx, bucketName, key, write)
+		if status != StatusSuccess {
+			return errorCode(status), nil
+		}
+		return component.Ok(handle), nil
+	}
+}
+
+// componentRead implements the read method of objects, which returns an empty list at the end of the object
+func (m *module) componentRead(_ context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	handle := component.Resource[uint32](a, 0)
+	n := a.Uint64(1)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	data, status := m.readObject(handle, uint32(min(n, maxComponentRead))) //nolint:gosec // G115: n is capped at 1MiB
+	if status != StatusSuccess {
+		return errorCode(status), nil
+	}
+	return component.Ok(data), nil
+}
+
+// componentWrite implements the write method of objects
+func (m *module) componentWrite(_ context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	handle := component.Resource[uint32](a, 0)
+	data := a.Bytes(1)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	if status := m.writeObject(handle, data); status != StatusSuccess {
+		return errorCode(status), nil
+	}
+	return component.Ok(nil), nil
+}
+
+// componentClose implements close, which takes ownership of the object and waits for its upload to complete
+func (m *module) componentClose(ctx context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	handle := component.Resource[uint32](a, 0)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	if status := m.closeObject(ctx, handle); status != StatusSuccess {
+		return errorCode(status), nil
+	}
+	return component.Ok(nil), nil
+}
+
+// dropObject aborts an object the component dropped without closing it
+func (m *module) dropObject(_ context.Context, rep any) {
+	handle, ok := rep.(uint32)
+	if !ok {
+		return
+	}
+	if obj := m.remove(handle); obj != nil {
+		obj.abort()
+	}
+}
+
+// errorCode returns the error-code of a status
+func errorCode(status uint32) component.Variant {
+	code, ok := errorCodes[status]
+	if !ok {
+		code = errorCodes[StatusStoreError]
+	}
+	return component.Err(code)
+}
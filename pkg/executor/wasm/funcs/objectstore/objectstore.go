@@ -0,0 +1,411 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/funcs/http"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+// ModuleName Defines the object store function namespace
+const ModuleName = "bacalhau:object-store/s3"
+
+// Result codes
+const (
+	StatusSuccess        uint32 = 0
+	StatusNotFound       uint32 = 1
+	StatusNotAllowed     uint32 = 2
+	StatusBadInput       uint32 = 3
+	StatusMemoryError    uint32 = 4
+	StatusStoreError     uint32 = 5
+	StatusBadHandle      uint32 = 6
+	StatusTooManyObjects uint32 = 7
+)
+
+const (
+	// DefaultMaxOpenObjects is the default maximum number of objects a job can have open at once
+	DefaultMaxOpenObjects = 16
+
+	// MaxKeyLength is the maximum length of an object key
+	MaxKeyLength = 1024
+)
+
+var (
+	// ErrObjectNotFound is returned by clients when the object does not exist
+	ErrObjectNotFound = errors.New("object not found")
+
+	// errAborted aborts the uploads of objects that were not closed by the job
+	errAborted = errors.New("object was not closed before the execution ended")
+)
+
+// Bucket is a bucket that WASM jobs are allowed to access
+type Bucket struct {
+	Name     string
+	Region   string
+	Endpoint string
+	// ReadOnly prevents jobs from writing objects to the bucket
+	ReadOnly bool
+}
+
+// Host returns the host that serves the bucket, which is subject to the network configuration of the job
+func (b Bucket) Host() string {
+	if b.Endpoint != "" {
+		if u, err := url.Parse(b.Endpoint); err == nil && u.Host != "" {
+			return u.Host
+		}
+		return b.Endpoint
+	}
+	if b.Region != "" {
+		return fmt.Sprintf("%s.s3.%s.amazonaws.com", b.Name, b.Region)
+	}
+	return b.Name + ".s3.amazonaws.com"
+}
+
+// Client reads and writes objects
+type Client interface {
+	// GetObject returns a stream of the object's content, or ErrObjectNotFound if it does not exist
+	GetObject(ctx context.Context, bucket Bucket, key string) (io.ReadCloser, error)
+	// PutObject writes the object with the content of the stream, until it is closed
+	PutObject(ctx context.Context, bucket Bucket, key string, body io.Reader) error
+}
+
+type Params struct {
+	// Network defines the networking configuration of the job.
+	// The functions are not registered if networking is disabled, and buckets are only accessible
+	// if the job is allowed to reach the host serving them.
+	Network *models.NetworkConfig
+
+	// Buckets are the buckets jobs are allowed to access.
+	// The functions are not registered if there are none.
+	Buckets []Bucket
+
+	// Client reads and writes objects. The functions are not registered if nil.
+	Client Client
+
+	// MaxOpenObjects is the maximum number of objects a job can have open at once
+	// Default is 16
+	MaxOpenObjects int
+}
+
+// InstantiateModule instantiates the object store host functions
+func InstantiateModule(ctx context.Context, r wazero.Runtime, params Params) error {
+	if params.Network == nil || params.Network.Disabled() || params.Client == nil || len(params.Buckets) == 0 {
+		return nil // Don't register any object store functions
+	}
+
+	objectModule := newObjectStoreModule(params)
+
+	moduleBuilder := r.NewHostModuleBuilder(ModuleName)
+
+	moduleBuilder.NewFunctionBuilder().
+		WithFunc(objectModule.openRead).
+		WithName("object_open_read").
+		WithParameterNames("bucket_ptr", "bucket_len", "key_ptr", "key_len", "handle_ptr").
+		WithResultNames("status_code").
+		Export("object_open_read")
+
+	moduleBuilder.NewFunctionBuilder().
+		WithFunc(objectModule.openWrite).
+		WithName("object_open_write").
+		WithParameterNames("bucket_ptr", "bucket_len", "key_ptr", "key_len", "handle_ptr").
+		WithResultNames("status_code").
+		Export("object_open_write")
+
+	moduleBuilder.NewFunctionBuilder().
+		WithFunc(objectModule.read).
+		WithName("object_read").
+		WithParameterNames("handle", "buf_ptr", "buf_len", "read_len_ptr").
+		WithResultNames("status_code").
+		Export("object_read")
+
+	moduleBuilder.NewFunctionBuilder().
+		WithFunc(objectModule.write).
+		WithName("object_write").
+		WithParameterNames("handle", "buf_ptr", "buf_len").
+		WithResultNames("status_code").
+		Export("object_write")
+
+	moduleBuilder.NewFunctionBuilder().
+		WithFunc(objectModule.close).
+		WithName("object_close").
+		WithParameterNames("handle").
+		WithResultNames("status_code").
+		Export("object_close")
+
+	// release the objects the job left open when the module is closed at the end of the execution
+	ctx = experimental.WithCloseNotifier(ctx, experimental.CloseNotifyFunc(objectModule.closeAll))
+	_, err := moduleBuilder.Instantiate(ctx)
+	return err
+}
+
+// object is an object open for reading or writing
+type object struct {
+	reader io.ReadCloser
+	writer *io.PipeWriter
+	// done receives the result of the upload of an object open for writing
+	done chan error
+}
+
+// abort releases the object without completing its upload
+func (o *object) abort() {
+	if o.reader != nil {
+		_ = o.reader.Close()
+	} else {
+		_ = o.writer.CloseWithError(errAborted)
+	}
+}
+
+// module manages object store functionality for WASM
+type module struct {
+	params Params
+
+	mu         sync.Mutex
+	objects    map[uint32]*object
+	nextHandle uint32
+}
+
+func newObjectStoreModule(params Params) *module {
+	if params.MaxOpenObjects == 0 {
+		params.MaxOpenObjects = DefaultMaxOpenObjects
+	}
+	return &module{
+		params:     params,
+		objects:    make(map[uint32]*object),
+		nextHandle: 1,
+	}
+}
+
+// openRead opens an object for reading and writes its handle to handle_ptr
+func (m *module) openRead(ctx context.Context, mod api.Module, bucketPtr, bucketLen, keyPtr, keyLen, handlePtr uint32) uint32 {
+	return m.openAndRegister(ctx, mod, bucketPtr, bucketLen, keyPtr, keyLen, handlePtr, false)
+}
+
+// openWrite opens an object for writing and writes its handle to handle_ptr.
+// The object is uploaded as it is written, and is complete once closed.
+func (m *module) openWrite(ctx context.Context, mod api.Module, bucketPtr, bucketLen, keyPtr, keyLen, handlePtr uint32) uint32 {
+	return m.openAndRegister(ctx, mod, bucketPtr, bucketLen, keyPtr, keyLen, handlePtr, true)
+}
+
+func (m *module) openAndRegister(
+	ctx context.Context, mod api.Module, bucketPtr, bucketLen, keyPtr, keyLen, handlePtr uint32, write bool,
+) uint32 {
+	bucketName, key, status := m.readLocation(mod, bucketPtr, bucketLen, keyPtr, keyLen)
+	if status != StatusSuccess {
+		return status
+	}
+	handle, status := m.open(ctx, bucketName, key, write)
+	if status != StatusSuccess {
+		return status
+	}
+	if !mod.Memory().WriteUint32Le(handlePtr, handle) {
+		if obj := m.remove(handle); obj != nil {
+			obj.abort()
+		}
+		return StatusMemoryError
+	}
+	return StatusSuccess
+}
+
+// read reads up to buf_len bytes of an object into the buffer at buf_ptr, and writes the number of bytes
+// read to read_len_ptr. Zero bytes are read once the end of the object is reached.
+func (m *module) read(ctx context.Context, mod api.Module, handle, bufPtr, bufLen, readLenPtr uint32) uint32 {
+	data, status := m.readObject(handle, bufLen)
+	if status != StatusSuccess {
+		return status
+	}
+
+	memory := mod.Memory()
+	if len(data) > 0 && !memory.Write(bufPtr, data) {
+		return StatusMemoryError
+	}
+	if !memory.WriteUint32Le(readLenPtr, uint32(len(data))) { //nolint:gosec // G115: data is at most bufLen long
+		return StatusMemoryError
+	}
+	return StatusSuccess
+}
+
+// write writes the buf_len bytes at buf_ptr to an object
+func (m *module) write(ctx context.Context, mod api.Module, handle, bufPtr, bufLen uint32) uint32 {
+	data, ok := mod.Memory().Read(bufPtr, bufLen)
+	if !ok {
+		return StatusMemoryError
+	}
+	return m.writeObject(handle, data)
+}
+
+// close closes an object. For objects open for writing, it waits for the upload to complete.
+func (m *module) close(ctx context.Context, mod api.Module, handle uint32) uint32 {
+	return m.closeObject(ctx, handle)
+}
+
+// open opens an object for reading or writing and returns its handle.
+// Objects open for writing are uploaded as they are written, and are complete once closed.
+func (m *module) open(ctx context.Context, bucketName, key string, write bool) (uint32, uint32) {
+	bucket, status := m.bucket(bucketName, key)
+	if status != StatusSuccess {
+		return 0, status
+	}
+	if write && bucket.ReadOnly {
+		return 0, StatusNotAllowed
+	}
+	if !m.hasCapacity() {
+		return 0, StatusTooManyObjects
+	}
+
+	if !write {
+		reader, err := m.params.Client.GetObject(ctx, bucket, key)
+		if errors.Is(err, ErrObjectNotFound) {
+			return 0, StatusNotFound
+		}
+		if err != nil {
+			return 0, StatusStoreError
+		}
+		return m.add(&object{reader: reader}), StatusSuccess
+	}
+
+	reader, writer := io.Pipe()
+	obj := &object{writer: writer, done: make(chan error, 1)}
+	go func() {
+		err := m.params.Client.PutObject(ctx, bucket, key, reader)
+		// unblock writes if the upload stopped reading
+		_ = reader.CloseWithError(err)
+		obj.done <- err
+	}()
+	return m.add(obj), StatusSuccess
+}
+
+// readObject reads up to n bytes of an object, and no bytes once the end of the object is reached
+func (m *module) readObject(handle, n uint32) ([]byte, uint32) {
+	obj, ok := m.get(handle)
+	if !ok || obj.reader == nil {
+		return nil, StatusBadHandle
+	}
+
+	buf := make([]byte, n)
+	read, err := io.ReadFull(obj.reader, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, StatusStoreError
+	}
+	return buf[:read], StatusSuccess
+}
+
+// writeObject writes the data to an object
+func (m *module) writeObject(handle uint32, data []byte) uint32 {
+	obj, ok := m.get(handle)
+	if !ok || obj.writer == nil {
+		return StatusBadHandle
+	}
+	if _, err := obj.writer.Write(data); err != nil {
+		return StatusStoreError
+	}
+	return StatusSuccess
+}
+
+// closeObject closes an object. For objects open for writing, it waits for the upload to complete.
+func (m *module) closeObject(ctx context.Context, handle uint32) uint32 {
+	obj := m.remove(handle)
+	if obj == nil {
+		return StatusBadHandle
+	}
+
+	if obj.reader != nil {
+		_ = obj.reader.Close()
+		return StatusSuccess
+	}
+
+	_ = obj.writer.Close()
+	select {
+	case err := <-obj.done:
+		if err != nil {
+			return StatusStoreError
+		}
+		return StatusSuccess
+	case <-ctx.Done():
+		return StatusStoreError
+	}
+}
+
+// closeAll releases the objects left open, aborting their uploads
+func (m *module) closeAll(context.Context, uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for handle, obj := range m.objects {
+		obj.abort()
+		delete(m.objects, handle)
+	}
+}
+
+func (m *module) hasCapacity() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.objects) < m.params.MaxOpenObjects
+}
+
+func (m *module) get(handle uint32) (*object, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	obj, ok := m.objects[handle]
+	return obj, ok
+}
+
+// add tracks the object and returns its handle
+func (m *module) add(obj *object) uint32 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	handle := m.nextHandle
+	m.nextHandle++
+	m.objects[handle] = obj
+	return handle
+}
+
+// remove stops tracking the object of the handle and returns it, or nil if the handle is unknown
+func (m *module) remove(handle uint32) *object {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	obj := m.objects[handle]
+	delete(m.objects, handle)
+	return obj
+}
+
+// readLocation reads the bucket name and key from WASM memory
+func (m *module) readLocation(mod api.Module, bucketPtr, bucketLen, keyPtr, keyLen uint32) (string, string, uint32) {
+	if keyLen > MaxKeyLength {
+		return "", "", StatusBadInput
+	}
+	memory := mod.Memory()
+	bucketName, ok := memory.Read(bucketPtr, bucketLen)
+	if !ok {
+		return "", "", StatusMemoryError
+	}
+	key, ok := memory.Read(keyPtr, keyLen)
+	if !ok {
+		return "", "", StatusMemoryError
+	}
+	return string(bucketName), string(key), StatusSuccess
+}
+
+// bucket returns the bucket with the name, checking that the job can access it and that the key is valid
+func (m *module) bucket(name, key string) (Bucket, uint32) {
+	if name == "" || key == "" || len(key) > MaxKeyLength {
+		return Bucket{}, StatusBadInput
+	}
+	idx := slices.IndexFunc(m.params.Buckets, func(b Bucket) bool { return b.Name == name })
+	if idx < 0 {
+		return Bucket{}, StatusNotAllowed
+	}
+	bucket := m.params.Buckets[idx]
+	if !http.IsHostAllowed(m.params.Network, bucket.Host()) {
+		return Bucket{}, StatusNotAllowed
+	}
+	return bucket, StatusSuccess
+}
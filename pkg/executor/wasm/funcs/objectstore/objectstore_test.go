@@ -0,0 +1,280 @@
+//go:build unit || !integration
+
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental/wazerotest"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+// Offsets of the guest memory used by the tests
+const (
+	bucketPtr uint32 = 0
+	keyPtr    uint32 = 1024
+	handlePtr uint32 = 4096
+	lenPtr    uint32 = 4100
+	bufPtr    uint32 = 8192
+)
+
+// memoryClient is an in-memory Client
+type memoryClient struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryClient() *memoryClient {
+	return &memoryClient{objects: make(map[string][]byte)}
+}
+
+func (c *memoryClient) GetObject(_ context.Context, bucket Bucket, key string) (io.ReadCloser, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	content, ok := c.objects[bucket.Name+"/"+key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (c *memoryClient) PutObject(_ context.Context, bucket Bucket, key string, body io.Reader) error {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.objects[bucket.Name+"/"+key] = content
+	return nil
+}
+
+type ObjectStoreUnitSuite struct {
+	suite.Suite
+	ctx    context.Context
+	client *memoryClient
+	module *module
+	guest  *wazerotest.Module
+}
+
+func TestObjectStoreUnit(t *testing.T) {
+	suite.Run(t, new(ObjectStoreUnitSuite))
+}
+
+func (s *ObjectStoreUnitSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.client = newMemoryClient()
+	s.module = newObjectStoreModule(Params{
+		Network: &models.NetworkConfig{Type: models.NetworkHost},
+		Buckets: []Bucket{
+			{Name: "results", Region: "us-east-1"},
+			{Name: "datasets", Region: "us-east-1", ReadOnly: true},
+		},
+		Client:         s.client,
+		MaxOpenObjects: 2,
+	})
+	s.guest = wazerotest.NewModule(wazerotest.NewMemory(wazerotest.PageSize))
+}
+
+// writeLocation writes the bucket and key to guest memory and returns their lengths
+func (s *ObjectStoreUnitSuite) writeLocation(bucket, key string) (uint32, uint32) {
+	s.Require().True(s.guest.Memory().WriteString(bucketPtr, bucket))
+	s.Require().True(s.guest.Memory().WriteString(keyPtr, key))
+	return uint32(len(bucket)), uint32(len(key)) //nolint:gosec // G115: test inputs are small
+}
+
+func (s *ObjectStoreUnitSuite) open(write bool, bucket, key string) (uint32, uint32) {
+	bucketLen, keyLen := s.writeLocation(bucket, key)
+	open := s.module.openRead
+	if write {
+		open = s.module.openWrite
+	}
+	status := open(s.ctx, s.guest, bucketPtr, bucketLen, keyPtr, keyLen, handlePtr)
+	if status != StatusSuccess {
+		return 0, status
+	}
+	handle, ok := s.guest.Memory().ReadUint32Le(handlePtr)
+	s.Require().True(ok)
+	return handle, status
+}
+
+func (s *ObjectStoreUnitSuite) write(handle uint32, content string) uint32 {
+	s.Require().True(s.guest.Memory().WriteString(bufPtr, content))
+	return s.module.write(s.ctx, s.guest, handle, bufPtr, uint32(len(content))) //nolint:gosec // G115: test inputs are small
+}
+
+// readAll reads the object in chunks of the given size
+func (s *ObjectStoreUnitSuite) readAll(handle, chunkSize uint32) string {
+	var content []byte
+	for {
+		s.Require().Equal(StatusSuccess, s.module.read(s.ctx, s.guest, handle, bufPtr, chunkSize, lenPtr))
+		n, ok := s.guest.Memory().ReadUint32Le(lenPtr)
+		s.Require().True(ok)
+		if n == 0 {
+			return string(content)
+		}
+		chunk, ok := s.guest.Memory().Read(bufPtr, n)
+		s.Require().True(ok)
+		content = append(content, chunk...)
+	}
+}
+
+func (s *ObjectStoreUnitSuite) TestWriteThenRead() {
+	handle, status := s.open(true, "results", "out/result.txt")
+	s.Require().Equal(StatusSuccess, status)
+	s.Equal(StatusSuccess, s.write(handle, "hello "))
+	s.Equal(StatusSuccess, s.write(handle, "world"))
+	s.Equal(StatusSuccess, s.module.close(s.ctx, s.guest, handle))
+	s.Equal("hello world", string(s.client.objects["results/out/result.txt"]))
+
+	handle, status = s.open(false, "results", "out/result.txt")
+	s.Require().Equal(StatusSuccess, status)
+	s.Equal("hello world", s.readAll(handle, 4))
+	s.Equal(StatusSuccess, s.module.close(s.ctx, s.guest, handle))
+}
+
+func (s *ObjectStoreUnitSuite) TestAccessControl() {
+	_, status := s.open(false, "unknown", "key")
+	s.Equal(StatusNotAllowed, status, "buckets must be allowed")
+
+	_, status = s.open(true, "datasets", "key")
+	s.Equal(StatusNotAllowed, status, "read-only buckets can't be written")
+
+	_, status = s.open(false, "datasets", "missing")
+	s.Equal(StatusNotFound, status)
+
+	s.module.params.Network = &models.NetworkConfig{Type: models.NetworkHTTP, Domains: []string{"example.com"}}
+	_, status = s.open(false, "results", "key")
+	s.Equal(StatusNotAllowed, status, "the bucket host must be reachable by the job")
+
+	s.module.params.Network = &models.NetworkConfig{Type: models.NetworkHTTP, Domains: []string{"*.amazonaws.com"}}
+	_, status = s.open(true, "results", "key")
+	s.Equal(StatusSuccess, status)
+}
+
+func (s *ObjectStoreUnitSuite) TestHandles() {
+	s.Equal(StatusBadHandle, s.module.close(s.ctx, s.guest, 42))
+
+	s.client.objects["datasets/input"] = []byte("data")
+	readHandle, status := s.open(false, "datasets", "input")
+	s.Require().Equal(StatusSuccess, status)
+	s.Equal(StatusBadHandle, s.write(readHandle, "data"), "objects open for reading can't be written")
+
+	writeHandle, status := s.open(true, "results", "output")
+	s.Require().Equal(StatusSuccess, status)
+	s.Equal(StatusBadHandle, s.module.read(s.ctx, s.guest, writeHandle, bufPtr, 4, lenPtr),
+		"objects open for writing can't be read")
+
+	_, status = s.open(false, "datasets", "input")
+	s.Equal(StatusTooManyObjects, status)
+
+	s.Equal(StatusSuccess, s.module.close(s.ctx, s.guest, readHandle))
+	s.Equal(StatusBadHandle, s.module.close(s.ctx, s.guest, readHandle), "handles can only be closed once")
+}
+
+func (s *ObjectStoreUnitSuite) TestCloseAllAbortsUploads() {
+	handle, status := s.open(true, "results", "partial")
+	s.Require().Equal(StatusSuccess, status)
+	s.Equal(StatusSuccess, s.write(handle, "partial content"))
+	obj, ok := s.module.get(handle)
+	s.Require().True(ok)
+
+	s.module.closeAll(s.ctx, 0)
+	s.ErrorIs(<-obj.done, errAborted)
+	s.NotContains(s.client.objects, "results/partial")
+	s.Empty(s.module.objects)
+}
+
+func (s *ObjectStoreUnitSuite) TestBucketHost() {
+	s.Equal("results.s3.us-east-1.amazonaws.com", Bucket{Name: "results", Region: "us-east-1"}.Host())
+	s.Equal("results.s3.amazonaws.com", Bucket{Name: "results"}.Host())
+	s.Equal("minio.local:9000", Bucket{Name: "results", Endpoint: "http://minio.local:9000"}.Host())
+}
+
+func (s *ObjectStoreUnitSuite) TestInstantiateModule() {
+	buckets := []Bucket{{Name: "results"}}
+	cases := []struct {
+		name       string
+		params     Params
+		registered bool
+	}{
+		{
+			name:       "registered if networking is enabled",
+			params:     Params{Network: &models.NetworkConfig{Type: models.NetworkHost}, Buckets: buckets, Client: s.client},
+			registered: true,
+		},
+		{
+			name:   "not registered if networking is disabled",
+			params: Params{Network: &models.NetworkConfig{Type: models.NetworkNone}, Buckets: buckets, Client: s.client},
+		},
+		{
+			name:   "not registered without buckets",
+			params: Params{Network: &models.NetworkConfig{Type: models.NetworkHost}, Client: s.client},
+		},
+	}
+
+	for _, tc := range cases {
+		s.Run(tc.name, func() {
+			runtime := wazero.NewRuntime(s.ctx)
+			defer func() { _ = runtime.Close(s.ctx) }()
+
+			s.Require().NoError(InstantiateModule(s.ctx, runtime, tc.params))
+			s.Equal(tc.registered, runtime.Module(ModuleName) != nil)
+		})
+	}
+}
+
+// TestComponentFunctions tests the functions of the component interface
+func (s *ObjectStoreUnitSuite) TestComponentFunctions() {
+	call := func(fn component.HostFunc, args ...any) any {
+		result, err := fn(s.ctx, args)
+		s.Require().NoError(err)
+		return result
+	}
+
+	opened := call(s.module.componentOpen(true), "results", "out/result.txt").(component.Variant)
+	s.Require().False(opened.IsErr())
+	s.Equal(component.Ok(nil), call(s.module.componentWrite, opened.Value, []byte("hello")))
+	s.Equal(component.Ok(nil), call(s.module.componentClose, opened.Value))
+	s.Equal("hello", string(s.client.objects["results/out/result.txt"]))
+
+	opened = call(s.module.componentOpen(false), "results", "out/result.txt").(component.Variant)
+	s.Require().False(opened.IsErr())
+	s.Equal(component.Ok([]byte("hel")), call(s.module.componentRead, opened.Value, uint64(3)))
+	s.Equal(component.Ok([]byte("lo")), call(s.module.componentRead, opened.Value, uint64(3)))
+	s.Equal(component.Ok([]byte{}), call(s.module.componentRead, opened.Value, uint64(3)), "empty at the end")
+	s.Equal(component.Err(uint32(4)), call(s.module.componentWrite, opened.Value, []byte("data")), "bad-handle")
+	s.Equal(component.Ok(nil), call(s.module.componentClose, opened.Value))
+
+	s.Equal(component.Err(uint32(0)), call(s.module.componentOpen(false), "results", "missing"), "not-found")
+	s.Equal(component.Err(uint32(1)), call(s.module.componentOpen(true), "datasets", "key"), "not-allowed")
+	s.Equal(component.Err(uint32(2)), call(s.module.componentOpen(false), "results", ""), "bad-input")
+
+	_, err := s.module.componentOpen(false)(s.ctx, []any{"results"})
+	s.Error(err, "invalid arguments trap")
+}
+
+func (s *ObjectStoreUnitSuite) TestComponentDropAbortsUploads() {
+	result, err := s.module.componentOpen(true)(s.ctx, []any{"results", "partial"})
+	s.Require().NoError(err)
+	opened := result.(component.Variant)
+	s.Require().False(opened.IsErr())
+	obj, ok := s.module.get(opened.Value.(uint32))
+	s.Require().True(ok)
+
+	s.module.dropObject(s.ctx, opened.Value)
+	s.ErrorIs(<-obj.done, errAborted)
+	s.NotContains(s.client.objects, "results/partial")
+	s.Empty(s.module.objects)
+}
+
+// compile-time check that the fake implements the interface
+var _ Client = (*memoryClient)(nil)
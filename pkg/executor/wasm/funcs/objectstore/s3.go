@@ -0,0 +1,54 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	s3helper "github.com/bacalhau-project/bacalhau/pkg/s3"
+)
+
+// S3Client is a Client for S3 compatible object stores, using the credentials of the compute node
+type S3Client struct {
+	provider *s3helper.ClientProvider
+}
+
+func NewS3Client(provider *s3helper.ClientProvider) *S3Client {
+	return &S3Client{provider: provider}
+}
+
+// GetObject implements Client
+func (c *S3Client) GetObject(ctx context.Context, bucket Bucket, key string) (io.ReadCloser, error) {
+	client := c.provider.GetClient(bucket.Endpoint, bucket.Region)
+	output, err := client.S3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket.Name),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+// PutObject implements Client. The object is uploaded in parts as the stream is read,
+// so that it does not need to fit in memory.
+func (c *S3Client) PutObject(ctx context.Context, bucket Bucket, key string, body io.Reader) error {
+	client := c.provider.GetClient(bucket.Endpoint, bucket.Region)
+	_, err := client.Uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket.Name),
+		Key:    aws.String(key),
+		Body:   body,
+	})
+	return err
+}
+
+// compile-time check that we implement the interface
+var _ Client = (*S3Client)(nil)
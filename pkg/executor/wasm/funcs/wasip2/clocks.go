@@ -0,0 +1,43 @@
+package wasip2
+
+import (
+	"context"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
+)
+
+func (h *host) linkClocks(linker *component.Linker) {
+	linker.Instance(name("wasi:clocks/monotonic-clock")).
+		Func("now", func(context.Context, []any) (any, error) {
+			return uint64(time.Since(h.start)), nil //nolint:gosec // G115: the monotonic clock is not negative
+		}).
+		Func("resolution", h.constant(uint64(1))).
+		Func("subscribe-instant", func(_ context.Context, args []any) (any, error) {
+			a := component.NewArgs(args)
+			instant := a.Uint64(0)
+			if a.Err() != nil {
+				return nil, a.Err()
+			}
+			return &pollable{deadline: h.start.Add(time.Duration(instant))}, nil //nolint:gosec // G115: instants fit durations
+		}).
+		Func("subscribe-duration", func(_ context.Context, args []any) (any, error) {
+			a := component.NewArgs(args)
+			duration := a.Uint64(0)
+			if a.Err() != nil {
+				return nil, a.Err()
+			}
+			return &pollable{deadline: time.Now().Add(time.Duration(duration))}, nil //nolint:gosec // G115: durations fit
+		})
+
+	linker.Instance(name("wasi:clocks/wall-clock")).
+		Func("now", func(context.Context, []any) (any, error) {
+			return datetime(time.Now()), nil
+		}).
+		Func("resolution", h.constant([]any{uint64(0), uint32(1)}))
+}
+
+// datetime returns the datetime record of the time, in seconds and nanoseconds since the Unix epoch
+func datetime(t time.Time) []any {
+	return []any{uint64(t.Unix()), uint32(t.Nanosecond())} //nolint:gosec // G115: times are after the epoch
+}
@@ -0,0 +1,590 @@
+package wasip2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"path"
+	"strings"
+	"syscall"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
+)
+
+// Cases of the error-code enum of wasi:filesystem/types
+const (
+	errorCodeAccess        uint32 = 0
+	errorCodeBadDescriptor uint32 = 3
+	errorCodeExist         uint32 = 7
+	errorCodeFileTooLarge  uint32 = 8
+	errorCodeInvalid       uint32 = 12
+	errorCodeIO            uint32 = 13
+	errorCodeIsDirectory   uint32 = 14
+	errorCodeNameTooLong   uint32 = 18
+	errorCodeNoEntry       uint32 = 20
+	errorCodeNotDirectory  uint32 = 24
+	errorCodeNotEmpty      uint32 = 25
+	errorCodeUnsupported   uint32 = 27
+	errorCodeReadOnly      uint32 = 33
+	errorCodeInvalidSeek   uint32 = 34
+)
+
+// Cases of the descriptor-type enum
+const (
+	descriptorTypeUnknown         uint32 = 0
+	descriptorTypeCharacterDevice uint32 = 2
+	descriptorTypeDirectory       uint32 = 3
+	descriptorTypeFifo            uint32 = 4
+	descriptorTypeSymbolicLink    uint32 = 5
+	descriptorTypeRegularFile     uint32 = 6
+	descriptorTypeSocket          uint32 = 7
+)
+
+// Flags of the descriptor-flags, open-flags and path-flags types
+const (
+	descriptorFlagRead            uint64 = 1 << 0
+	descriptorFlagWrite           uint64 = 1 << 1
+	descriptorFlagMutateDirectory uint64 = 1 << 5
+
+	openFlagCreate    uint64 = 1 << 0
+	openFlagDirectory uint64 = 1 << 1
+	openFlagExclusive uint64 = 1 << 2
+	openFlagTruncate  uint64 = 1 << 3
+)
+
+// descriptor is the representation of descriptor resources. Directories are only represented by their
+// path, while files are opened.
+type descriptor struct {
+	fs    fs.FS
+	path  string
+	file  fs.File
+	flags uint64
+}
+
+// dirEntryStream is the representation of directory-entry-stream resources
+type dirEntryStream struct {
+	entries []fs.DirEntry
+}
+
+func dropDescriptor(_ context.Context, rep any) {
+	if d, ok := rep.(*descriptor); ok && d.file != nil {
+		_ = d.file.Close()
+	}
+}
+
+// descriptorFunc implements a method of descriptors returning a result<T, error-code>
+type descriptorFunc func(d *descriptor, a *component.Args) (any, error)
+
+func (h *host) descriptorMethod(fn descriptorFunc) component.HostFunc {
+	return func(_ context.Context, args []any) (any, error) {
+		a := component.NewArgs(args)
+		d := component.Resource[*descriptor](a, 0)
+		if a.Err() != nil {
+			return nil, a.Err()
+		}
+		value, err := fn(d, component.NewArgs(args[1:]))
+		if err != nil {
+			var argErr argumentError
+			if errors.As(err, &argErr) {
+				return nil, argErr.err
+			}
+			return component.Err(errorCode(err)), nil
+		}
+		return component.Ok(value), nil
+	}
+}
+
+// argumentError is an invalid argument of a call, which traps rather than returning an error code
+type argumentError struct {
+	err error
+}
+
+func (e argumentError) Error() string {
+	return e.err.Error()
+}
+
+func checkArgs(a *component.Args) error {
+	if a.Err() != nil {
+		return argumentError{err: a.Err()}
+	}
+	return nil
+}
+
+var errUnsupported = errors.New("unsupported")
+
+func (h *host) linkFilesystem(linker *component.Linker) {
+	types := linker.Instance(name("wasi:filesystem/types")).
+		Resource("descriptor", h.descriptor).
+		Resource("directory-entry-stream", h.dirEntryStream).
+		Func(method("descriptor", "read-via-stream"), h.descriptorMethod(readViaStream)).
+		Func(method("descriptor", "write-via-stream"), h.descriptorMethod(writeViaStream)).
+		Func(method("descriptor", "append-via-stream"), h.descriptorMethod(appendViaStream)).
+		Func(method("descriptor", "advise"), h.descriptorMethod(noop)).
+		Func(method("descriptor", "sync-data"), h.descriptorMethod(syncFile)).
+		Func(method("descriptor", "sync"), h.descriptorMethod(syncFile)).
+		Func(method("descriptor", "get-flags"), h.descriptorMethod(func(d *descriptor, _ *component.Args) (any, error) {
+			return d.flags, nil
+		})).
+		Func(method("descriptor", "get-type"), h.descriptorMethod(func(d *descriptor, _ *component.Args) (any, error) {
+			info, err := d.stat()
+			if err != nil {
+				return nil, err
+			}
+			return descriptorType(info.Mode()), nil
+		})).
+		Func(method("descriptor", "set-size"), h.descriptorMethod(setSize)).
+		Func(method("descriptor", "read"), h.descriptorMethod(readAt)).
+		Func(method("descriptor", "write"), h.descriptorMethod(writeAt)).
+		Func(method("descriptor", "read-directory"), h.descriptorMethod(readDirectory)).
+		Func(method("descriptor", "stat"), h.descriptorMethod(func(d *descriptor, _ *component.Args) (any, error) {
+			info, err := d.stat()
+			if err != nil {
+				return nil, err
+			}
+			return descriptorStat(info), nil
+		})).
+		Func(method("descriptor", "stat-at"), h.descriptorMethod(statAt)).
+		Func(method("descriptor", "open-at"), h.descriptorMethod(openAt)).
+		Func(method("descriptor", "metadata-hash"), h.descriptorMethod(func(d *descriptor, _ *component.Args) (any, error) {
+			info, err := d.stat()
+			if err != nil {
+				return nil, err
+			}
+			return metadataHash(d.path, info), nil
+		})).
+		Func(method("descriptor", "metadata-hash-at"), h.descriptorMethod(metadataHashAt)).
+		Func(method("descriptor", "is-same-object"), func(_ context.Context, args []any) (any, error) {
+			a := component.NewArgs(args)
+			d := component.Resource[*descriptor](a, 0)
+			other := component.Resource[*descriptor](a, 1)
+			if a.Err() != nil {
+				return nil, a.Err()
+			}
+			return d.path == other.path, nil
+		}).
+		Func(method("directory-entry-stream", "read-directory-entry"), readDirectoryEntry).
+		Func("filesystem-error-code", func(_ context.Context, args []any) (any, error) {
+			a := component.NewArgs(args)
+			err := component.Resource[error](a, 0)
+			if a.Err() != nil {
+				return nil, a.Err()
+			}
+			return component.Some(errorCode(err)), nil
+		})
+
+	// the filesystem of jobs can't be modified other than by writing files
+	for _, unsupported := range []string{
+		"set-times", "set-times-at", "link-at", "readlink-at", "create-directory-at", "remove-directory-at",
+		"rename-at", "symlink-at", "unlink-file-at",
+	} {
+		types.Func(method("descriptor", unsupported), h.descriptorMethod(func(*descriptor, *component.Args) (any, error) {
+			return nil, errUnsupported
+		}))
+	}
+
+	linker.Instance(name("wasi:filesystem/preopens")).
+		Func("get-directories", func(context.Context, []any) (any, error) {
+			if h.params.FS == nil {
+				return []any{}, nil
+			}
+			root := &descriptor{fs: h.params.FS, path: ".", flags: descriptorFlagRead | descriptorFlagMutateDirectory}
+			return []any{[]any{root, "/"}}, nil
+		})
+}
+
+func noop(*descriptor, *component.Args) (any, error) {
+	return nil, nil
+}
+
+func (d *descriptor) stat() (fs.FileInfo, error) {
+	if d.file != nil {
+		return d.file.Stat()
+	}
+	return fs.Stat(d.fs, d.path)
+}
+
+// resolve returns the path of a path relative to the directory of the descriptor
+func (d *descriptor) resolve(relative string) (string, error) {
+	if d.file != nil {
+		return "", syscall.ENOTDIR
+	}
+	if strings.HasPrefix(relative, "/") {
+		return "", fs.ErrPermission
+	}
+	resolved := path.Join(d.path, relative)
+	if !fs.ValidPath(resolved) {
+		// the path escapes the preopened directory
+		return "", fs.ErrPermission
+	}
+	return resolved, nil
+}
+
+func readViaStream(d *descriptor, a *component.Args) (any, error) {
+	offset := a.Uint64(0)
+	if err := checkArgs(a); err != nil {
+		return nil, err
+	}
+	if d.file == nil {
+		return nil, syscall.EISDIR
+	}
+	readerAt, ok := d.file.(io.ReaderAt)
+	if !ok {
+		return nil, errUnsupported
+	}
+	if offset > math.MaxInt64 {
+		return nil, syscall.EINVAL
+	}
+	return &inputStream{r: io.NewSectionReader(readerAt, int64(offset), math.MaxInt64-int64(offset))}, nil
+}
+
+func writeViaStream(d *descriptor, a *component.Args) (any, error) {
+	offset := a.Uint64(0)
+	if err := checkArgs(a); err != nil {
+		return nil, err
+	}
+	writerAt, err := d.writerAt()
+	if err != nil {
+		return nil, err
+	}
+	if offset > math.MaxInt64 {
+		return nil, syscall.EINVAL
+	}
+	return &outputStream{w: io.NewOffsetWriter(writerAt, int64(offset))}, nil
+}
+
+func appendViaStream(d *descriptor, _ *component.Args) (any, error) {
+	if d.file == nil {
+		return nil, syscall.EISDIR
+	}
+	seeker, ok := d.file.(io.WriteSeeker)
+	if !ok {
+		return nil, syscall.EROFS
+	}
+	if _, err := seeker.Seek(0, io.SeekEnd); err != nil {
+		return nil, err
+	}
+	return &outputStream{w: seeker}, nil
+}
+
+func (d *descriptor) writerAt() (io.WriterAt, error) {
+	if d.file == nil {
+		return nil, syscall.EISDIR
+	}
+	writerAt, ok := d.file.(io.WriterAt)
+	if !ok || d.flags&descriptorFlagWrite == 0 {
+		return nil, syscall.EROFS
+	}
+	return writerAt, nil
+}
+
+func syncFile(d *descriptor, _ *component.Args) (any, error) {
+	if syncer, ok := d.file.(interface{ Sync() error }); ok {
+		return nil, syncer.Sync()
+	}
+	return nil, nil
+}
+
+func setSize(d *descriptor, a *component.Args) (any, error) {
+	size := a.Uint64(0)
+	if err := checkArgs(a); err != nil {
+		return nil, err
+	}
+	truncater, ok := d.file.(interface{ Truncate(int64) error })
+	if !ok || d.flags&descriptorFlagWrite == 0 {
+		return nil, syscall.EROFS
+	}
+	if size > math.MaxInt64 {
+		return nil, syscall.EFBIG
+	}
+	return nil, truncater.Truncate(int64(size))
+}
+
+func readAt(d *descriptor, a *component.Args) (any, error) {
+	length := a.Uint64(0)
+	offset := a.Uint64(1)
+	if err := checkArgs(a); err != nil {
+		return nil, err
+	}
+	if d.file == nil {
+		return nil, syscall.EISDIR
+	}
+	readerAt, ok := d.file.(io.ReaderAt)
+	if !ok {
+		return nil, errUnsupported
+	}
+	if offset > math.MaxInt64 {
+		return nil, syscall.EINVAL
+	}
+	buf := make([]byte, min(length, maxReadSize))
+	n, err := readerAt.ReadAt(buf, int64(offset))
+	eof := errors.Is(err, io.EOF)
+	if err != nil && !eof {
+		return nil, err
+	}
+	return []any{buf[:n], eof}, nil
+}
+
+func writeAt(d *descriptor, a *component.Args) (any, error) {
+	buffer := a.Bytes(0)
+	offset := a.Uint64(1)
+	if err := checkArgs(a); err != nil {
+		return nil, err
+	}
+	writerAt, err := d.writerAt()
+	if err != nil {
+		return nil, err
+	}
+	if offset > math.MaxInt64 {
+		return nil, syscall.EINVAL
+	}
+	n, err := writerAt.WriteAt(buffer, int64(offset))
+	if err != nil {
+		return nil, err
+	}
+	return uint64(n), nil //nolint:gosec // G115: n is not negative
+}
+
+func readDirectory(d *descriptor, _ *component.Args) (any, error) {
+	if d.file != nil {
+		return nil, syscall.ENOTDIR
+	}
+	entries, err := fs.ReadDir(d.fs, d.path)
+	if err != nil {
+		return nil, err
+	}
+	return &dirEntryStream{entries: entries}, nil
+}
+
+func readDirectoryEntry(_ context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	stream := component.Resource[*dirEntryStream](a, 0)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	if len(stream.entries) == 0 {
+		return component.Ok(component.None()), nil
+	}
+	entry := stream.entries[0]
+	stream.entries = stream.entries[1:]
+	return component.Ok(component.Some([]any{descriptorType(entry.Type()), entry.Name()})), nil
+}
+
+func statAt(d *descriptor, a *component.Args) (any, error) {
+	// symbolic links are always followed by fs.FS
+	_ = a.Uint64(0)
+	relative := a.String(1)
+	if err := checkArgs(a); err != nil {
+		return nil, err
+	}
+	resolved, err := d.resolve(relative)
+	if err != nil {
+		return nil, err
+	}
+	info, err := statPath(d.fs, resolved)
+	if err != nil {
+		return nil, err
+	}
+	return descriptorStat(info), nil
+}
+
+func metadataHashAt(d *descriptor, a *component.Args) (any, error) {
+	_ = a.Uint64(0)
+	relative := a.String(1)
+	if err := checkArgs(a); err != nil {
+		return nil, err
+	}
+	resolved, err := d.resolve(relative)
+	if err != nil {
+		return nil, err
+	}
+	info, err := statPath(d.fs, resolved)
+	if err != nil {
+		return nil, err
+	}
+	return metadataHash(resolved, info), nil
+}
+
+func openAt(d *descriptor, a *component.Args) (any, error) {
+	_ = a.Uint64(0)
+	relative := a.String(1)
+	openFlags := a.Uint64(2)
+	flags := a.Uint64(3)
+	if err := checkArgs(a); err != nil {
+		return nil, err
+	}
+	resolved, err := d.resolve(relative)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := fileExists(d.fs, resolved)
+	switch {
+	case err != nil:
+		return nil, err
+	case exists && openFlags&openFlagExclusive != 0:
+		return nil, fs.ErrExist
+	case !exists && openFlags&(openFlagCreate|openFlagDirectory) != openFlagCreate:
+		// directories can't be created by opening them
+		return nil, fs.ErrNotExist
+	}
+
+	// the filesystem creates files that don't exist when they are opened in writable mounts
+	file, err := d.fs.Open(resolved)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		_ = file.Close()
+		if flags&descriptorFlagWrite != 0 {
+			return nil, syscall.EISDIR
+		}
+		return &descriptor{fs: d.fs, path: resolved, flags: flags}, nil
+	}
+	if openFlags&openFlagDirectory != 0 {
+		_ = file.Close()
+		return nil, syscall.ENOTDIR
+	}
+
+	opened := &descriptor{fs: d.fs, path: resolved, file: file, flags: flags}
+	if flags&descriptorFlagWrite != 0 {
+		if _, ok := file.(io.WriterAt); !ok {
+			_ = file.Close()
+			return nil, syscall.EROFS
+		}
+	}
+	if openFlags&openFlagTruncate != 0 {
+		if _, err = setSize(opened, component.NewArgs([]any{uint64(0)})); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+	return opened, nil
+}
+
+// fileExists returns true if the file exists. It lists the directory of the file rather than opening it,
+// as the writable mounts of jobs create the files that are opened.
+func fileExists(fsys fs.FS, name string) (bool, error) {
+	if name == "." {
+		return true, nil
+	}
+	entries, err := fs.ReadDir(fsys, path.Dir(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	base := path.Base(name)
+	for _, entry := range entries {
+		if entry.Name() == base {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// statPath returns the file info of an existing file, without creating it
+func statPath(fsys fs.FS, name string) (fs.FileInfo, error) {
+	exists, err := fileExists(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fs.ErrNotExist
+	}
+	return fs.Stat(fsys, name)
+}
+
+// descriptorType returns the descriptor-type of a file mode
+func descriptorType(mode fs.FileMode) uint32 {
+	switch {
+	case mode.IsDir():
+		return descriptorTypeDirectory
+	case mode.IsRegular():
+		return descriptorTypeRegularFile
+	case mode&fs.ModeSymlink != 0:
+		return descriptorTypeSymbolicLink
+	case mode&fs.ModeNamedPipe != 0:
+		return descriptorTypeFifo
+	case mode&fs.ModeSocket != 0:
+		return descriptorTypeSocket
+	case mode&fs.ModeCharDevice != 0:
+		return descriptorTypeCharacterDevice
+	default:
+		return descriptorTypeUnknown
+	}
+}
+
+// descriptorStat returns the descriptor-stat record of a file
+func descriptorStat(info fs.FileInfo) []any {
+	modified := component.Some(datetime(info.ModTime()))
+	return []any{
+		descriptorType(info.Mode()),
+		uint64(1),
+		uint64(max(info.Size(), 0)),
+		component.None(),
+		modified,
+		component.None(),
+	}
+}
+
+// metadataHash returns the metadata-hash-value record of a file, which changes when the file changes
+func metadataHash(path string, info fs.FileInfo) []any {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%d", path, info.Size(), info.ModTime().UnixNano())))
+	return []any{binary.LittleEndian.Uint64(sum[:8]), binary.LittleEndian.Uint64(sum[8:16])}
+}
+
+// errorCode returns the error-code of a filesystem error
+func errorCode(err error) uint32 {
+	var errno syscall.Errno
+	switch {
+	case errors.Is(err, errUnsupported):
+		return errorCodeUnsupported
+	case errors.Is(err, fs.ErrNotExist):
+		return errorCodeNoEntry
+	case errors.Is(err, fs.ErrExist):
+		return errorCodeExist
+	case errors.Is(err, fs.ErrPermission):
+		return errorCodeAccess
+	case errors.Is(err, fs.ErrInvalid):
+		return errorCodeInvalid
+	case errors.Is(err, fs.ErrClosed):
+		return errorCodeBadDescriptor
+	case errors.As(err, &errno):
+		return errnoCode(errno)
+	default:
+		return errorCodeIO
+	}
+}
+
+func errnoCode(errno syscall.Errno) uint32 {
+	switch errno {
+	case syscall.EISDIR:
+		return errorCodeIsDirectory
+	case syscall.ENOTDIR:
+		return errorCodeNotDirectory
+	case syscall.ENOTEMPTY:
+		return errorCodeNotEmpty
+	case syscall.EROFS, syscall.EBADF:
+		return errorCodeReadOnly
+	case syscall.EINVAL:
+		return errorCodeInvalid
+	case syscall.ESPIPE:
+		return errorCodeInvalidSeek
+	case syscall.EFBIG:
+		return errorCodeFileTooLarge
+	case syscall.ENAMETOOLONG:
+		return errorCodeNameTooLong
+	default:
+		return errorCodeIO
+	}
+}
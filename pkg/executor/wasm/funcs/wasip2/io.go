@@ -0,0 +1,241 @@
+package wasip2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/tetratelabs/wazero/sys"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
+)
+
+// Cases of the stream-error variant
+const (
+	streamErrorLastOperationFailed = 0
+	streamErrorClosed              = 1
+)
+
+// writeBudget is the number of bytes output streams accept at once
+const writeBudget = 1024 * 1024 // 1MB
+
+// inputStream is the representation of input-stream resources
+type inputStream struct {
+	r io.Reader
+}
+
+// outputStream is the representation of output-stream resources
+type outputStream struct {
+	w io.Writer
+}
+
+// pollable is the representation of pollable resources, which are ready once their deadline is reached.
+// Streams are always ready, and their pollables have no deadline.
+type pollable struct {
+	deadline time.Time
+}
+
+func (p *pollable) ready() bool {
+	return p.deadline.IsZero() || !time.Now().Before(p.deadline)
+}
+
+// exit implements wasi:cli/exit, which exits the component with a status of 0 if ok, and 1 otherwise
+func exit(_ context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	status := a.Variant(0)
+	if err := a.Err(); err != nil {
+		return nil, err
+	}
+	if status.IsErr() {
+		return nil, sys.NewExitError(1)
+	}
+	return nil, sys.NewExitError(0)
+}
+
+func (h *host) linkIO(linker *component.Linker) {
+	linker.Instance(name("wasi:io/error")).
+		Resource("error", h.errorResource).
+		Func(method("error", "to-debug-string"), func(_ context.Context, args []any) (any, error) {
+			a := component.NewArgs(args)
+			err := component.Resource[error](a, 0)
+			if a.Err() != nil {
+				return nil, a.Err()
+			}
+			return err.Error(), nil
+		})
+
+	linker.Instance(name("wasi:io/poll")).
+		Resource("pollable", h.pollable).
+		Func(method("pollable", "ready"), func(_ context.Context, args []any) (any, error) {
+			a := component.NewArgs(args)
+			p := component.Resource[*pollable](a, 0)
+			if a.Err() != nil {
+				return nil, a.Err()
+			}
+			return p.ready(), nil
+		}).
+		Func(method("pollable", "block"), func(ctx context.Context, args []any) (any, error) {
+			a := component.NewArgs(args)
+			p := component.Resource[*pollable](a, 0)
+			if a.Err() != nil {
+				return nil, a.Err()
+			}
+			return nil, sleepUntil(ctx, p.deadline)
+		}).
+		Func("poll", poll)
+
+	linker.Instance(name("wasi:io/streams")).
+		Resource("input-stream", h.inputStream).
+		Resource("output-stream", h.outputStream).
+		Func(method("input-stream", "read"), h.read).
+		Func(method("input-stream", "blocking-read"), h.read).
+		Func(method("input-stream", "skip"), h.skip).
+		Func(method("input-stream", "blocking-skip"), h.skip).
+		Func(method("input-stream", "subscribe"), h.subscribeStream).
+		Func(method("output-stream", "check-write"), h.constant(component.Ok(uint64(writeBudget)))).
+		Func(method("output-stream", "write"), h.write).
+		Func(method("output-stream", "blocking-write-and-flush"), h.write).
+		Func(method("output-stream", "flush"), h.constant(component.Ok(nil))).
+		Func(method("output-stream", "blocking-flush"), h.constant(component.Ok(nil))).
+		Func(method("output-stream", "write-zeroes"), h.writeZeroes).
+		Func(method("output-stream", "blocking-write-zeroes-and-flush"), h.writeZeroes).
+		Func(method("output-stream", "splice"), h.splice).
+		Func(method("output-stream", "blocking-splice"), h.splice).
+		Func(method("output-stream", "subscribe"), h.subscribeStream)
+}
+
+// poll blocks until at least one of the pollables is ready, and returns the indices of the ready pollables
+func poll(ctx context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	list := a.List(0)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	if len(list) == 0 {
+		return nil, errors.New("poll called without pollables")
+	}
+	pollables := make([]*pollable, len(list))
+	var earliest time.Time
+	for i, item := range list {
+		p, ok := item.(*pollable)
+		if !ok {
+			return nil, errors.New("poll called with an invalid pollable")
+		}
+		pollables[i] = p
+		if i == 0 || p.deadline.Before(earliest) {
+			earliest = p.deadline
+		}
+	}
+	if err := sleepUntil(ctx, earliest); err != nil {
+		return nil, err
+	}
+	var ready []any
+	for i, p := range pollables {
+		if p.ready() {
+			ready = append(ready, uint32(i)) //nolint:gosec // G115: lists are smaller than 2^32
+		}
+	}
+	return ready, nil
+}
+
+// sleepUntil blocks until the deadline, or until the context is done
+func sleepUntil(ctx context.Context, deadline time.Time) error {
+	wait := time.Until(deadline)
+	if deadline.IsZero() || wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (h *host) subscribeStream(context.Context, []any) (any, error) {
+	return &pollable{}, nil
+}
+
+// streamError returns the stream-error of a failed stream operation
+func streamError(err error) component.Variant {
+	if errors.Is(err, io.EOF) {
+		return component.Err(component.Variant{Case: streamErrorClosed})
+	}
+	return component.Err(component.Variant{Case: streamErrorLastOperationFailed, Value: err})
+}
+
+func (h *host) read(_ context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	stream := component.Resource[*inputStream](a, 0)
+	length := a.Uint64(1)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	buf := make([]byte, min(length, maxReadSize))
+	n, err := stream.r.Read(buf)
+	if n == 0 && err != nil {
+		return streamError(err), nil
+	}
+	return component.Ok(buf[:n]), nil
+}
+
+func (h *host) skip(_ context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	stream := component.Resource[*inputStream](a, 0)
+	length := a.Uint64(1)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	n, err := io.CopyN(io.Discard, stream.r, int64(min(length, maxReadSize))) //nolint:gosec // G115: bounded above
+	if n == 0 && err != nil {
+		return streamError(err), nil
+	}
+	return component.Ok(uint64(n)), nil //nolint:gosec // G115: n is not negative
+}
+
+func (h *host) write(_ context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	stream := component.Resource[*outputStream](a, 0)
+	contents := a.Bytes(1)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	if _, err := stream.w.Write(contents); err != nil {
+		return streamError(err), nil
+	}
+	return component.Ok(nil), nil
+}
+
+func (h *host) writeZeroes(_ context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	stream := component.Resource[*outputStream](a, 0)
+	length := a.Uint64(1)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	if length > writeBudget {
+		return nil, errors.New("write-zeroes called with more bytes than permitted by check-write")
+	}
+	if _, err := stream.w.Write(make([]byte, length)); err != nil {
+		return streamError(err), nil
+	}
+	return component.Ok(nil), nil
+}
+
+func (h *host) splice(_ context.Context, args []any) (any, error) {
+	a := component.NewArgs(args)
+	stream := component.Resource[*outputStream](a, 0)
+	src := component.Resource[*inputStream](a, 1)
+	length := a.Uint64(2)
+	if a.Err() != nil {
+		return nil, a.Err()
+	}
+	n, err := io.CopyN(stream.w, src.r, int64(min(length, maxReadSize))) //nolint:gosec // G115: bounded above
+	if n == 0 && err != nil {
+		return streamError(err), nil
+	}
+	return component.Ok(uint64(n)), nil //nolint:gosec // G115: n is not negative
+}
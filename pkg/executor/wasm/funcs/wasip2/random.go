@@ -0,0 +1,66 @@
+package wasip2
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
+)
+
+func (h *host) linkRandom(linker *component.Linker) {
+	linker.Instance(name("wasi:random/random")).
+		Func("get-random-bytes", func(_ context.Context, args []any) (any, error) {
+			a := component.NewArgs(args)
+			length := a.Uint64(0)
+			if a.Err() != nil {
+				return nil, a.Err()
+			}
+			return randomBytes(h.params.Random, length)
+		}).
+		Func("get-random-u64", func(context.Context, []any) (any, error) {
+			b, err := randomBytes(h.params.Random, 8)
+			if err != nil {
+				return nil, err
+			}
+			return binary.LittleEndian.Uint64(b), nil
+		})
+
+	linker.Instance(name("wasi:random/insecure")).
+		Func("get-insecure-random-bytes", func(_ context.Context, args []any) (any, error) {
+			a := component.NewArgs(args)
+			length := a.Uint64(0)
+			if a.Err() != nil {
+				return nil, a.Err()
+			}
+			if length > maxRandomSize {
+				return nil, fmt.Errorf("requested %d random bytes, more than the maximum of %d", length, maxRandomSize)
+			}
+			b := make([]byte, length)
+			for i := range b {
+				b[i] = byte(mathrand.Uint32())
+			}
+			return b, nil
+		}).
+		Func("get-insecure-random-u64", func(context.Context, []any) (any, error) {
+			return mathrand.Uint64(), nil
+		})
+
+	linker.Instance(name("wasi:random/insecure-seed")).
+		Func("insecure-seed", func(context.Context, []any) (any, error) {
+			return []any{mathrand.Uint64(), mathrand.Uint64()}, nil
+		})
+}
+
+func randomBytes(source io.Reader, length uint64) ([]byte, error) {
+	if length > maxRandomSize {
+		return nil, fmt.Errorf("requested %d random bytes, more than the maximum of %d", length, maxRandomSize)
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(source, b); err != nil {
+		return nil, fmt.Errorf("reading random bytes: %w", err)
+	}
+	return b, nil
+}
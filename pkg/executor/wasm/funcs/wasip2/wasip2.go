@@ -0,0 +1,163 @@
+// Package wasip2 implements the host interfaces of WASI 0.2 that WebAssembly components import: the
+// arguments, environment and stdio of wasi:cli, wasi:io streams and polling, wasi:clocks, wasi:random and
+// wasi:filesystem over the filesystem of the job.
+//
+// The sockets interfaces are not provided, so components calling them trap.
+package wasip2
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
+)
+
+// Version is the version of WASI the interfaces implement. Components built against any 0.2 version can
+// import them.
+const Version = "0.2.0"
+
+// maxReadSize is the maximum number of bytes returned by a single read, to bound the memory used by a call
+const maxReadSize = 1024 * 1024 // 1MB
+
+// maxRandomSize is the maximum number of random bytes a component can request at once
+const maxRandomSize = 64 * 1024 * 1024 // 64MB
+
+// Params configure the WASI interfaces provided to a component
+type Params struct {
+	// Args are the arguments of the component, starting with the program name
+	Args []string
+	// Env holds the environment variables of the component
+	Env map[string]string
+	// Stdin is the standard input of the component. It is empty if nil.
+	Stdin io.Reader
+	// Stdout and Stderr receive the standard output and error of the component. They are discarded if nil.
+	Stdout io.Writer
+	Stderr io.Writer
+	// FS is the filesystem preopened at "/". The component has no filesystem if nil.
+	FS fs.FS
+	// Random is the source of random bytes. It defaults to crypto/rand.
+	Random io.Reader
+}
+
+// host implements the interfaces for a single component instance
+type host struct {
+	params Params
+	// start is the origin of the monotonic clock
+	start time.Time
+
+	errorResource  *component.ResourceType
+	pollable       *component.ResourceType
+	inputStream    *component.ResourceType
+	outputStream   *component.ResourceType
+	terminalInput  *component.ResourceType
+	terminalOutput *component.ResourceType
+	descriptor     *component.ResourceType
+	dirEntryStream *component.ResourceType
+}
+
+// Link defines the WASI 0.2 interfaces in the linker, for a single instantiation of a component
+func Link(linker *component.Linker, params Params) {
+	if params.Stdin == nil {
+		params.Stdin = eofReader{}
+	}
+	if params.Stdout == nil {
+		params.Stdout = io.Discard
+	}
+	if params.Stderr == nil {
+		params.Stderr = io.Discard
+	}
+	if params.Random == nil {
+		params.Random = rand.Reader
+	}
+	h := newHost(params)
+	h.linkCLI(linker)
+	h.linkIO(linker)
+	h.linkClocks(linker)
+	h.linkRandom(linker)
+	h.linkFilesystem(linker)
+}
+
+func newHost(params Params) *host {
+	return &host{
+		params:         params,
+		start:          time.Now(),
+		errorResource:  component.NewHostResource("error", nil),
+		pollable:       component.NewHostResource("pollable", nil),
+		inputStream:    component.NewHostResource("input-stream", nil),
+		outputStream:   component.NewHostResource("output-stream", nil),
+		terminalInput:  component.NewHostResource("terminal-input", nil),
+		terminalOutput: component.NewHostResource("terminal-output", nil),
+		descriptor:     component.NewHostResource("descriptor", dropDescriptor),
+		dirEntryStream: component.NewHostResource("directory-entry-stream", nil),
+	}
+}
+
+// name returns the versioned name of a WASI interface, such as "wasi:cli/stdout@0.2.0"
+func name(iface string) string {
+	return iface + "@" + Version
+}
+
+// method returns the name of a method of a resource, such as "[method]output-stream.write"
+func method(resource, name string) string {
+	return "[method]" + resource + "." + name
+}
+
+func (h *host) linkCLI(linker *component.Linker) {
+	linker.Instance(name("wasi:cli/environment")).
+		Func("get-environment", h.getEnvironment).
+		Func("get-arguments", h.getArguments).
+		Func("initial-cwd", func(context.Context, []any) (any, error) {
+			return component.Some("/"), nil
+		})
+	linker.Instance(name("wasi:cli/exit")).Func("exit", exit)
+	linker.Instance(name("wasi:cli/stdin")).Func("get-stdin", h.constant(&inputStream{r: h.params.Stdin}))
+	linker.Instance(name("wasi:cli/stdout")).Func("get-stdout", h.constant(&outputStream{w: h.params.Stdout}))
+	linker.Instance(name("wasi:cli/stderr")).Func("get-stderr", h.constant(&outputStream{w: h.params.Stderr}))
+
+	// stdio is never a terminal
+	linker.Instance(name("wasi:cli/terminal-input")).Resource("terminal-input", h.terminalInput)
+	linker.Instance(name("wasi:cli/terminal-output")).Resource("terminal-output", h.terminalOutput)
+	none := h.constant(component.None())
+	linker.Instance(name("wasi:cli/terminal-stdin")).Func("get-terminal-stdin", none)
+	linker.Instance(name("wasi:cli/terminal-stdout")).Func("get-terminal-stdout", none)
+	linker.Instance(name("wasi:cli/terminal-stderr")).Func("get-terminal-stderr", none)
+}
+
+// constant returns a function returning the value
+func (h *host) constant(value any) component.HostFunc {
+	return func(context.Context, []any) (any, error) {
+		return value, nil
+	}
+}
+
+func (h *host) getEnvironment(context.Context, []any) (any, error) {
+	keys := make([]string, 0, len(h.params.Env))
+	for key := range h.params.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	env := make([]any, len(keys))
+	for i, key := range keys {
+		env[i] = []any{key, h.params.Env[key]}
+	}
+	return env, nil
+}
+
+func (h *host) getArguments(context.Context, []any) (any, error) {
+	args := make([]any, len(h.params.Args))
+	for i, arg := range h.params.Args {
+		args[i] = arg
+	}
+	return args, nil
+}
+
+// eofReader is an empty reader
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
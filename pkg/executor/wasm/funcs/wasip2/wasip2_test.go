@@ -0,0 +1,129 @@
+//go:build unit || !integration
+
+package wasip2
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero/sys"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/util/touchfs"
+)
+
+func TestStreams(t *testing.T) {
+	ctx := context.Background()
+	h := newHost(Params{})
+
+	var out bytes.Buffer
+	result, err := h.write(ctx, []any{&outputStream{w: &out}, []byte("hello")})
+	require.NoError(t, err)
+	require.Equal(t, component.Ok(nil), result)
+	require.Equal(t, "hello", out.String())
+
+	in := &inputStream{r: strings.NewReader("abc")}
+	result, err = h.read(ctx, []any{in, uint64(2)})
+	require.NoError(t, err)
+	require.Equal(t, component.Ok([]byte("ab")), result)
+	result, err = h.read(ctx, []any{in, uint64(2)})
+	require.NoError(t, err)
+	require.Equal(t, component.Ok([]byte("c")), result)
+	result, err = h.read(ctx, []any{in, uint64(2)})
+	require.NoError(t, err)
+	require.Equal(t, component.Err(component.Variant{Case: streamErrorClosed}), result)
+}
+
+func TestExit(t *testing.T) {
+	_, err := exit(context.Background(), []any{component.Ok(nil)})
+	require.Equal(t, sys.NewExitError(0), err)
+	_, err = exit(context.Background(), []any{component.Err(nil)})
+	require.Equal(t, sys.NewExitError(1), err)
+}
+
+func TestEnvironment(t *testing.T) {
+	h := newHost(Params{Args: []string{"", "-v"}, Env: map[string]string{"B": "2", "A": "1"}})
+	env, err := h.getEnvironment(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []any{[]any{"A", "1"}, []any{"B", "2"}}, env)
+	args, err := h.getArguments(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []any{"", "-v"}, args)
+}
+
+func TestFilesystem(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "input.txt"), []byte("input"), 0o600))
+
+	h := newHost(Params{})
+	root := &descriptor{fs: touchfs.New(dir), path: ".", flags: descriptorFlagRead | descriptorFlagMutateDirectory}
+	open := func(path string, openFlags, flags uint64) component.Variant {
+		result, err := h.descriptorMethod(openAt)(ctx, []any{root, uint64(0), path, openFlags, flags})
+		require.NoError(t, err)
+		return result.(component.Variant)
+	}
+
+	t.Run("read", func(t *testing.T) {
+		opened := open("input.txt", 0, descriptorFlagRead)
+		require.False(t, opened.IsErr())
+		defer dropDescriptor(ctx, opened.Value)
+
+		result, err := h.descriptorMethod(readAt)(ctx, []any{opened.Value, uint64(16), uint64(1)})
+		require.NoError(t, err)
+		require.Equal(t, component.Ok([]any{[]byte("nput"), true}), result)
+	})
+
+	t.Run("write", func(t *testing.T) {
+		opened := open("output.txt", openFlagCreate, descriptorFlagWrite)
+		require.False(t, opened.IsErr())
+
+		result, err := h.descriptorMethod(writeAt)(ctx, []any{opened.Value, []byte("output"), uint64(0)})
+		require.NoError(t, err)
+		require.Equal(t, component.Ok(uint64(6)), result)
+		dropDescriptor(ctx, opened.Value)
+
+		contents, err := os.ReadFile(filepath.Join(dir, "output.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "output", string(contents))
+	})
+
+	t.Run("read only", func(t *testing.T) {
+		root := &descriptor{fs: os.DirFS(dir), path: "."}
+		result, err := h.descriptorMethod(openAt)(ctx, []any{root, uint64(0), "input.txt", uint64(0), descriptorFlagWrite})
+		require.NoError(t, err)
+		opened := result.(component.Variant)
+		require.False(t, opened.IsErr())
+		defer dropDescriptor(ctx, opened.Value)
+		result, err = h.descriptorMethod(writeAt)(ctx, []any{opened.Value, []byte("output"), uint64(0)})
+		require.NoError(t, err)
+		require.Equal(t, component.Err(errorCodeReadOnly), result)
+
+		result, err = h.descriptorMethod(openAt)(ctx, []any{root, uint64(0), "missing.txt", uint64(0), descriptorFlagRead})
+		require.NoError(t, err)
+		require.Equal(t, component.Err(errorCodeNoEntry), result)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		require.Equal(t, component.Err(errorCodeAccess), open("../input.txt", 0, descriptorFlagRead))
+		require.Equal(t, component.Err(errorCodeAccess), open("/input.txt", 0, descriptorFlagRead))
+		require.Equal(t, component.Err(errorCodeExist), open("input.txt", openFlagCreate|openFlagExclusive, 0))
+		require.Equal(t, component.Err(errorCodeNotDirectory), open("input.txt", openFlagDirectory, 0))
+		require.Equal(t, component.Err(errorCodeIsDirectory), open(".", 0, descriptorFlagWrite))
+		require.Equal(t, component.Err(errorCodeNoEntry), open("missing", openFlagCreate|openFlagDirectory, 0))
+
+		// opening a missing file without creating it doesn't create it in writable mounts
+		require.Equal(t, component.Err(errorCodeNoEntry), open("missing", 0, descriptorFlagRead))
+		require.NoFileExists(t, filepath.Join(dir, "missing"))
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := h.descriptorMethod(openAt)(ctx, []any{root, uint64(0), 42, uint64(0), uint64(0)})
+		require.Error(t, err)
+	})
+}
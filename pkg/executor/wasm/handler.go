@@ -20,7 +20,9 @@ import (
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute"
 	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
 	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/funcs/http"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/funcs/wasip2"
 	wasmmodels "github.com/bacalhau-project/bacalhau/pkg/executor/wasm/models"
 	wasmlogs "github.com/bacalhau-project/bacalhau/pkg/executor/wasm/util/logger"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
@@ -35,6 +37,12 @@ import (
 type executionHandler struct {
 	// runtime configured with resource-limits
 	runtime wazero.Runtime
+	// fuel meters the fuel consumed by the execution. It is nil if the job has no fuel budget.
+	fuel *fuelMeter
+	// moduleCache caches compiled modules across executions. It is nil if caching is disabled.
+	moduleCache *ModuleCache
+	// storeModules are the key-value and object store host modules available to the execution
+	storeModules storeModules
 	// spec contains the WASM engine specification
 	spec wasmmodels.EngineSpec
 	// virtual filesystem exposed to wasm module
@@ -64,6 +72,8 @@ func newExecutionHandler(
 	ctx context.Context,
 	request *executor.RunCommandRequest,
 	runtime wazero.Runtime,
+	moduleCache *ModuleCache,
+	storeModules storeModules,
 	fs fs.FS,
 ) (*executionHandler, error) {
 	// Decode WASM engine spec
@@ -78,10 +88,18 @@ func newExecutionHandler(
 		return nil, NewLogError(err)
 	}
 
+	var fuel *fuelMeter
+	if wasmSpec.Fuel > 0 {
+		fuel = newFuelMeter(wasmSpec.Fuel)
+	}
+
 	return &executionHandler{
-		runtime: runtime,
-		spec:    wasmSpec,
-		fs:      fs,
+		runtime:      runtime,
+		fuel:         fuel,
+		moduleCache:  moduleCache,
+		storeModules: storeModules,
+		spec:         wasmSpec,
+		fs:           fs,
 
 		request: request,
 
@@ -120,6 +138,11 @@ func (h *executionHandler) run(ctx context.Context) {
 		ActiveExecutions.Dec(ctx)
 	}()
 
+	// Meter fuel from compilation onwards, so that modules are instrumented to consume fuel as they are loaded
+	if h.fuel != nil {
+		ctx = withFuelMeter(ctx, h.fuel)
+	}
+
 	// Set up execution context with cancellation
 	var wasmCtx context.Context
 	wasmCtx, h.cancel = context.WithCancel(ctx)
@@ -133,18 +156,36 @@ func (h *executionHandler) run(ctx context.Context) {
 	tracingEngine := h.setupTracing(ctx)
 	defer closer.ContextCloserWithLogOnError(ctx, "engine", tracingEngine)
 
+	if h.fuel != nil {
+		if err := h.fuel.instantiate(ctx, tracingEngine.Runtime); err != nil {
+			h.result = executor.NewFailedResult(fmt.Sprintf("failed to load fuel meter: %s", err))
+			return
+		}
+	}
+
 	// Set up logging and module configuration
 	stdout, stderr := h.logManager.GetWriters()
 	config := h.createModuleConfig(stdout, stderr)
 
 	// Load and instantiate modules
-	instance, err := h.loadModules(ctx, tracingEngine, config)
+	loader := NewModuleLoader(tracingEngine, config, h.fs).WithModuleCache(h.moduleCache)
+	entry, err := loader.LoadComponent(h.spec.EntryModule)
+	if err != nil {
+		h.result = executor.NewFailedResult(fmt.Sprintf("failed to load entry module %s: %s", h.spec.EntryModule, err))
+		return
+	}
+	var entryFunc func(ctx context.Context) error
+	if entry != nil {
+		entryFunc, err = h.loadComponent(ctx, tracingEngine, entry, stdout, stderr)
+	} else {
+		entryFunc, err = h.loadModules(ctx, tracingEngine, loader)
+	}
 	if err != nil {
 		return
 	}
 
 	// Execute the main function
-	h.executeMainFunction(wasmCtx, instance)
+	h.executeMainFunction(wasmCtx, entryFunc)
 }
 
 // setupTracing initializes OpenTelemetry tracing for the WASM execution
@@ -193,15 +234,20 @@ func (h *executionHandler) createModuleConfig(stdout, stderr io.Writer) wazero.M
 	return config
 }
 
-// loadModules loads and instantiates all required WASM modules
-func (h *executionHandler) loadModules(ctx context.Context, engine tracedRuntime, config wazero.ModuleConfig) (api.Module, error) {
-	h.logger.Info().Msg("instantiating wasm modules")
-	loader := NewModuleLoader(engine, config, h.fs)
-
-	// in wasm, if network type is undefined, we default to host
+// defaultNetwork defaults the network of the execution to host, if it is undefined
+func (h *executionHandler) defaultNetwork() {
 	if h.request.Network == nil || h.request.Network.Type == models.NetworkDefault {
 		h.request.Network = &models.NetworkConfig{Type: models.NetworkHost}
 	}
+}
+
+// loadModules loads and instantiates all required WASM modules, and returns the entry point of the entry module
+func (h *executionHandler) loadModules(
+	ctx context.Context, engine tracedRuntime, loader *ModuleLoader) (func(ctx context.Context) error, error) {
+	h.logger.Info().Msg("instantiating wasm modules")
+
+	// in wasm, if network type is undefined, we default to host
+	h.defaultNetwork()
 
 	// Load HTTP module if networking is enabled
 	if h.request.Network != nil && h.request.Network.Type != models.NetworkNone {
@@ -217,6 +263,12 @@ func (h *executionHandler) loadModules(ctx context.Context, engine tracedRuntime
 		}
 	}
 
+	// Load the store modules, which follow the same network gating as the HTTP module
+	if err := h.storeModules.instantiate(ctx, engine.Runtime, h.request, h.spec); err != nil {
+		h.result = executor.NewFailedResult(err.Error())
+		return nil, err
+	}
+
 	// Load import modules first
 	for _, importModule := range h.spec.ImportModules {
 		if _, err := loader.InstantiateModule(ctx, importModule); err != nil {
@@ -237,32 +289,96 @@ func (h *executionHandler) loadModules(ctx context.Context, engine tracedRuntime
 		return nil, err
 	}
 
-	return instance, nil
+	entryFunc := instance.ExportedFunction(h.spec.Entrypoint)
+	return func(ctx context.Context) error {
+		_, err := entryFunc.Call(ctx)
+		return err
+	}, nil
 }
 
-// verifyEntryPoint checks if the specified entry point exists in the module
-func (h *executionHandler) verifyEntryPoint(instance api.Module) error {
-	definitions := instance.ExportedFunctionDefinitions()
-	_, found := definitions[h.spec.Entrypoint]
+// loadComponent instantiates a WebAssembly component with the WASI 0.2, HTTP and store interfaces, and returns
+// its entry point. The _start entry point runs the wasi:cli/run export of the component, while other
+// entry points name a function the component exports, such as "wasi:cli/run@0.2.0#run".
+func (h *executionHandler) loadComponent(
+	ctx context.Context,
+	engine tracedRuntime,
+	entry *component.Component,
+	stdout, stderr io.Writer,
+) (func(ctx context.Context) error, error) {
+	h.logger.Info().Msg("instantiating wasm component")
+	if len(h.spec.ImportModules) > 0 {
+		h.result = executor.NewFailedResult("import modules are not supported with WebAssembly components")
+		return nil, NewComponentImportModulesError(h.spec.EntryModule)
+	}
 
-	if !found {
+	// in wasm, if network type is undefined, we default to host
+	h.defaultNetwork()
+
+	linker := component.NewLinker()
+	wasip2.Link(linker, wasip2.Params{
+		Args:   append([]string{""}, h.spec.Parameters...),
+		Env:    h.request.Env,
+		Stdout: stdout,
+		Stderr: stderr,
+		FS:     h.fs,
+	})
+	http.LinkComponent(linker, http.Params{Network: h.request.Network})
+	if err := h.storeModules.link(linker, h.request, h.spec); err != nil {
+		h.result = executor.NewFailedResult(err.Error())
+		return nil, err
+	}
+
+	instance, err := component.Instantiate(ctx, entry, component.InstantiateParams{
+		Runtime: engine,
+		Linker:  linker,
+		Name:    h.spec.EntryModule,
+		Compile: func(ctx context.Context, binary []byte) (wazero.CompiledModule, error) {
+			binary, err := meterModule(ctx, binary)
+			if err != nil {
+				return nil, err
+			}
+			if h.moduleCache != nil {
+				return h.moduleCache.Compile(ctx, engine, binary)
+			}
+			return engine.CompileModule(ctx, binary)
+		},
+	})
+	if err != nil {
+		h.result = executor.NewFailedResult(fmt.Sprintf("failed to load entry module %s: %s", h.spec.EntryModule, err))
+		return nil, NewModuleLoadError(h.spec.EntryModule, err)
+	}
+
+	entrypoint := h.spec.Entrypoint
+	if entrypoint == "" || entrypoint == startFunction {
+		entrypoint = componentRunFunction
+	}
+	entryFunc := instance.Func(entrypoint)
+	if entryFunc == nil || len(entryFunc.Type().Params) > 0 {
+		_ = instance.Close(ctx)
 		h.result = executor.NewFailedResult(
-			fmt.Sprintf("unable to find the entrypoint '%s' in the WASM module", h.spec.Entrypoint),
+			fmt.Sprintf("unable to find the entrypoint '%s' in the WASM component", entrypoint),
 		)
-		return NewEntrypointError(h.spec.Entrypoint)
+		return nil, NewEntrypointError(entrypoint)
 	}
-	return nil
+
+	return func(ctx context.Context) error {
+		defer func() { _ = instance.Close(ctx) }()
+		result, err := entryFunc.Call(ctx)
+		if err != nil {
+			return err
+		}
+		// commands exit with a status of 1 if they return an error, and 0 otherwise
+		if resultType := entryFunc.Type().Result; resultType != nil && resultType.Kind == component.KindResult {
+			if status, ok := result.(component.Variant); ok && status.IsErr() {
+				return sys.NewExitError(1)
+			}
+		}
+		return sys.NewExitError(0)
+	}, nil
 }
 
-// executeMainFunction runs the main WASM function and handles its completion
-func (h *executionHandler) executeMainFunction(ctx context.Context, instance api.Module) {
-	// Calling instance.ExportedFunction with an invalid name returns an item that
-	// is not null. Or rather, the returned item is not null, but something internal
-	// when calling Call() _is_ null, causing a panic.
-	//
-	// To avoid this, we need to check the keys of the definitions map and
-	// see if the entry point is there and if not we will not attempt to look
-	// for it.
+// verifyEntryPoint checks if the specified entry point exists in the module
+func (h *executionHandler) verifyEntryPoint(instance api.Module) error {
 	definitions := instance.ExportedFunctionDefinitions()
 	_, found := definitions[h.spec.Entrypoint]
 
@@ -270,10 +386,13 @@ func (h *executionHandler) executeMainFunction(ctx context.Context, instance api
 		h.result = executor.NewFailedResult(
 			fmt.Sprintf("unable to find the entrypoint '%s' in the WASM module", h.spec.Entrypoint),
 		)
-		return
+		return NewEntrypointError(h.spec.Entrypoint)
 	}
+	return nil
+}
 
-	entryFunc := instance.ExportedFunction(h.spec.Entrypoint)
+// executeMainFunction runs the main WASM function and handles its completion
+func (h *executionHandler) executeMainFunction(ctx context.Context, entryFunc func(ctx context.Context) error) {
 	h.logger.Info().Msg("running execution")
 
 	// TODO(forrest): this is a bit of a race condition as the operation has not started when these lines are called.
@@ -284,7 +403,7 @@ func (h *executionHandler) executeMainFunction(ctx context.Context, instance api
 	// the exit code for inclusion in the job output, and ignore the return code
 	// from the function (most WASI compilers will not give one). Some compilers
 	// though do not set an exit code, so we use a default of -1.
-	_, wasmErr := entryFunc.Call(ctx)
+	wasmErr := entryFunc(ctx)
 	exitCode := int64(-1)
 	var errExit *sys.ExitError
 	if errors.As(wasmErr, &errExit) {
@@ -292,6 +411,9 @@ func (h *executionHandler) executeMainFunction(ctx context.Context, instance api
 		wasmErr = nil
 		h.logger.Info().Int64("exit_code", exitCode).Msg("execution ended")
 	}
+	if h.fuel != nil && h.fuel.Exhausted() {
+		wasmErr = NewFuelExhaustedError(h.fuel.budget)
+	}
 	if wasmErr != nil {
 		// in the event that an error is returned without an exist code we'll assume the operation
 		// failed and set the exit code to 1
@@ -306,6 +428,9 @@ func (h *executionHandler) executeMainFunction(ctx context.Context, instance api
 	stdoutReader, stderrReader := h.logManager.GetDefaultReaders(false)
 	executionResultsDir := compute.ExecutionResultsDir(h.request.ExecutionDir)
 	h.result = executor.WriteJobResults(executionResultsDir, stdoutReader, stderrReader, int(exitCode), wasmErr, h.request.OutputLimits)
+	if h.fuel != nil {
+		h.result.FuelConsumed = h.fuel.Consumed()
+	}
 }
 
 // active returns whether the execution is currently running
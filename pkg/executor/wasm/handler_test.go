@@ -0,0 +1,163 @@
+//go:build unit || !integration
+
+package wasm
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component/test"
+	wasmmodels "github.com/bacalhau-project/bacalhau/pkg/executor/wasm/models"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/util/touchfs"
+	"github.com/bacalhau-project/bacalhau/pkg/logger"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/testdata/wasm/noop"
+)
+
+// runEntryModule runs the entry module with the entrypoint, and returns the result of the execution
+func runEntryModule(t *testing.T, entryModule []byte, entrypoint string) *models.RunCommandResult {
+	result, _ := runModule(t, entryModule, wasmmodels.NewWasmEngineBuilder("main.wasm").WithEntrypoint(entrypoint), nil, nil)
+	return result
+}
+
+// runModule runs the entry module with the engine spec and environment, in a writable directory holding the
+// module and the files. It returns the result of the execution and the directory.
+func runModule(
+	t *testing.T,
+	entryModule []byte,
+	engine *wasmmodels.WasmEngineBuilder,
+	env map[string]string,
+	files map[string]string,
+) (*models.RunCommandResult, string) {
+	ctx := context.Background()
+	moduleDir, executionDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.wasm"), entryModule, 0o600))
+	for name, contents := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(moduleDir, name), []byte(contents), 0o600))
+	}
+	require.NoError(t, os.MkdirAll(compute.ExecutionResultsDir(executionDir), 0o755))
+
+	runtime := wazero.NewRuntime(ctx)
+	defer func() { _ = runtime.Close(ctx) }()
+
+	request := &executor.RunCommandRequest{
+		JobID:        "job",
+		ExecutionID:  "execution",
+		EngineParams: engine.MustBuild(),
+		Env:          env,
+		Network:      &models.NetworkConfig{Type: models.NetworkNone},
+		ExecutionDir: executionDir,
+		OutputLimits: executor.OutputLimits{
+			MaxStdoutFileLength:   datasize.KB,
+			MaxStdoutReturnLength: datasize.KB,
+			MaxStderrFileLength:   datasize.KB,
+			MaxStderrReturnLength: datasize.KB,
+		},
+	}
+	handler, err := newExecutionHandler(ctx, request, runtime, nil, storeModules{}, touchfs.New(moduleDir))
+	require.NoError(t, err)
+	handler.run(ctx)
+	require.NotNil(t, handler.result)
+	return handler.result, moduleDir
+}
+
+func TestRunComponent(t *testing.T) {
+	logger.ConfigureTestLogging(t)
+
+	result := runEntryModule(t, test.StdoutCommand("hello from a component\n"), startFunction)
+	require.Empty(t, result.ErrorMsg)
+	require.Equal(t, 0, result.ExitCode)
+	require.Equal(t, "hello from a component\n", result.STDOUT)
+
+	result = runEntryModule(t, test.StdoutCommand("hello"), componentRunFunction)
+	require.Empty(t, result.ErrorMsg)
+	require.Equal(t, "hello", result.STDOUT)
+
+	result = runEntryModule(t, test.StdoutCommand("hello"), "missing")
+	require.Contains(t, result.ErrorMsg, "unable to find the entrypoint 'missing' in the WASM component")
+}
+
+// TestRunWithFuel runs a core module and a component with a fuel budget, which they consume as they run
+// until it is exhausted
+func TestRunWithFuel(t *testing.T) {
+	logger.ConfigureTestLogging(t)
+
+	for name, entryModule := range map[string][]byte{
+		"module":    noop.Program(),
+		"component": test.StdoutCommand("hello"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			engine := wasmmodels.NewWasmEngineBuilder("main.wasm").WithEntrypoint(startFunction).WithFuel(1_000_000)
+			result, _ := runModule(t, entryModule, engine, nil, nil)
+			require.Empty(t, result.ErrorMsg)
+			require.Equal(t, 0, result.ExitCode)
+			require.Positive(t, result.FuelConsumed)
+
+			budget := result.FuelConsumed - 1
+			engine = wasmmodels.NewWasmEngineBuilder("main.wasm").WithEntrypoint(startFunction).WithFuel(budget)
+			result, _ = runModule(t, entryModule, engine, nil, nil)
+			require.Contains(t, result.ErrorMsg, "exhausted its fuel budget")
+			require.Equal(t, budget, result.FuelConsumed)
+		})
+	}
+}
+
+// TestRunToolchainComponent runs the component built by the Rust toolchain for wasm32-wasip2 from the sources
+// in testdata/wasm/component, which checks the runtime against the components real toolchains generate.
+// The component is built with `make component/main.wasm` in testdata/wasm, and the test skips until it is.
+func TestRunToolchainComponent(t *testing.T) {
+	logger.ConfigureTestLogging(t)
+
+	binary, err := os.ReadFile(filepath.Join("..", "..", "..", "testdata", "wasm", "component", "main.wasm"))
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip("testdata/wasm/component/main.wasm is not built")
+	}
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		stdout   string
+		stderr   string
+		exitCode int
+		files    map[string]string
+	}{
+		{name: "arguments", args: []string{"echo", "hello", "world"}, stdout: "hello world\n"},
+		{name: "environment", args: []string{"env", "GREETING"}, env: map[string]string{"GREETING": "hi"}, stdout: "hi\n"},
+		{name: "read file", args: []string{"cat", "/input.txt"}, stdout: "input"},
+		{name: "write file", args: []string{"write", "/output.txt", "output"}, files: map[string]string{"output.txt": "output"}},
+		{name: "list directory", args: []string{"ls", "/"}, stdout: "input.txt main.wasm\n"},
+		{name: "wall clock", args: []string{"time"}, stdout: "ok\n"},
+		{name: "random", args: []string{"random"}, stdout: "ok\n"},
+		{name: "exit code", args: []string{"exit", "3"}, stderr: "exiting\n", exitCode: 3},
+		{name: "missing file", args: []string{"cat", "/missing.txt"}, exitCode: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := wasmmodels.NewWasmEngineBuilder("main.wasm").WithParameters(tc.args...)
+			result, dir := runModule(t, binary, engine, tc.env, map[string]string{"input.txt": "input"})
+			require.Empty(t, result.ErrorMsg)
+			require.Equal(t, tc.exitCode, result.ExitCode)
+			require.Equal(t, tc.stdout, result.STDOUT)
+			if tc.stderr != "" {
+				require.Equal(t, tc.stderr, result.STDERR)
+			}
+			for name, contents := range tc.files {
+				written, err := os.ReadFile(filepath.Join(dir, name))
+				require.NoError(t, err)
+				require.Equal(t, contents, string(written))
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package wasm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/tetratelabs/wazero"
+
+	"github.com/bacalhau-project/bacalhau/pkg/config/types"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/funcs/kv"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/funcs/objectstore"
+	wasmmodels "github.com/bacalhau-project/bacalhau/pkg/executor/wasm/models"
+)
+
+// storeModules holds the node wide parameters of the key-value and object store host modules
+type storeModules struct {
+	kvStore        kv.Store
+	kvMaxValueSize uint64
+	objectClient   objectstore.Client
+	buckets        []objectstore.Bucket
+}
+
+func newStoreModules(params ExecutorParams) (storeModules, error) {
+	modules := storeModules{objectClient: params.ObjectStoreClient}
+
+	if !params.Config.KeyValue.Disabled {
+		modules.kvStore = params.KeyValueStore
+	}
+	if params.Config.KeyValue.MaxValueSize != "" {
+		maxValueSize, err := humanize.ParseBytes(params.Config.KeyValue.MaxValueSize)
+		if err != nil {
+			return storeModules{}, fmt.Errorf("invalid wasm key-value max value size %q: %w", params.Config.KeyValue.MaxValueSize, err)
+		}
+		modules.kvMaxValueSize = maxValueSize
+	}
+
+	for _, bucket := range params.Config.ObjectStore.Buckets {
+		modules.buckets = append(modules.buckets, newBucket(bucket))
+	}
+	return modules, nil
+}
+
+func newBucket(bucket types.WASMBucket) objectstore.Bucket {
+	return objectstore.Bucket{
+		Name:     bucket.Name,
+		Region:   bucket.Region,
+		Endpoint: bucket.Endpoint,
+		ReadOnly: bucket.ReadOnly,
+	}
+}
+
+// instantiate instantiates the store host modules for an execution. Like the HTTP module, they are
+// only available if the network of the execution is not disabled.
+func (m storeModules) instantiate(
+	ctx context.Context,
+	runtime wazero.Runtime,
+	request *executor.RunCommandRequest,
+	spec wasmmodels.EngineSpec,
+) error {
+	if err := kv.InstantiateModule(ctx, runtime, m.kvParams(request, spec)); err != nil {
+		return fmt.Errorf("failed to load key-value module: %w", err)
+	}
+	if err := objectstore.InstantiateModule(ctx, runtime, m.objectStoreParams(request)); err != nil {
+		return fmt.Errorf("failed to load object store module: %w", err)
+	}
+	return nil
+}
+
+// link defines the store interfaces of WebAssembly components in the linker, with the same
+// network gating as the host modules of core modules
+func (m storeModules) link(
+	linker *component.Linker,
+	request *executor.RunCommandRequest,
+	spec wasmmodels.EngineSpec,
+) error {
+	if err := kv.LinkComponent(linker, m.kvParams(request, spec)); err != nil {
+		return fmt.Errorf("failed to load key-value interface: %w", err)
+	}
+	objectstore.LinkComponent(linker, m.objectStoreParams(request))
+	return nil
+}
+
+func (m storeModules) kvParams(request *executor.RunCommandRequest, spec wasmmodels.EngineSpec) kv.Params {
+	scope := kv.JobScope(request.JobID)
+	if spec.KeyValueScope == wasmmodels.KeyValueScopeNamespace {
+		scope = kv.NamespaceScope(request.Namespace)
+	}
+	return kv.Params{
+		Network:      request.Network,
+		Store:        m.kvStore,
+		Scope:        scope,
+		MaxValueSize: m.kvMaxValueSize,
+	}
+}
+
+func (m storeModules) objectStoreParams(request *executor.RunCommandRequest) objectstore.Params {
+	return objectstore.Params{
+		Network: request.Network,
+		Buckets: m.buckets,
+		Client:  m.objectClient,
+	}
+}
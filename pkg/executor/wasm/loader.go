@@ -11,6 +11,8 @@ import (
 	"github.com/tetratelabs/wazero/api"
 	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 	"go.ptx.dk/multierrgroup"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component"
 )
 
 // ModuleLoader handles the loading and instantiation of WebAssembly modules.
@@ -41,6 +43,8 @@ type ModuleLoader struct {
 	config wazero.ModuleConfig
 	// fs is the filesystem where modules are mounted
 	fs fs.FS
+	// cache caches compiled modules across executions. Modules are compiled directly if nil.
+	cache *ModuleCache
 
 	// mtx ensures thread-safe module instantiation
 	// The runtime will throw an error if the same module is instantiated more than once
@@ -57,6 +61,13 @@ func NewModuleLoader(runtime wazero.Runtime, config wazero.ModuleConfig, fs fs.F
 	}
 }
 
+// WithModuleCache compiles modules through the given cache, which must be the
+// compilation cache the runtime is configured with.
+func (loader *ModuleLoader) WithModuleCache(cache *ModuleCache) *ModuleLoader {
+	loader.cache = cache
+	return loader
+}
+
 // InstantiateModule loads and instantiates the module at the given path and all of
 // its dependencies. It looks in the provided filesystem for modules.
 //
@@ -74,6 +85,9 @@ func (loader *ModuleLoader) InstantiateModule(ctx context.Context, modulePath st
 	if err != nil {
 		return nil, err
 	}
+	// Release the compiled module once instantiated. The runtime's engine may be shared with
+	// other executions through the compilation cache, and would otherwise keep it in memory.
+	defer func() { _ = compiledModule.Close(ctx) }()
 
 	// Load all dependencies in parallel
 	var wg multierrgroup.Group
@@ -104,6 +118,28 @@ func (loader *ModuleLoader) InstantiateModule(ctx context.Context, modulePath st
 	return module, nil
 }
 
+// LoadComponent loads the component at the given path, or returns nil if the module at the path is a
+// core module. Components are instantiated by the component package rather than the loader, as they link
+// to the host through the interfaces they import rather than to other modules.
+func (loader *ModuleLoader) LoadComponent(path string) (*component.Component, error) {
+	resolvedPath, err := loader.resolveModulePath(path)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := fs.ReadFile(loader.fs, resolvedPath)
+	if err != nil {
+		return nil, NewModuleLoadError(resolvedPath, err)
+	}
+	if !component.IsComponent(bytes) {
+		return nil, nil
+	}
+	decoded, err := component.Decode(bytes)
+	if err != nil {
+		return nil, NewModuleLoadError(resolvedPath, err)
+	}
+	return decoded, nil
+}
+
 // loadModuleByPath loads and compiles a module from the filesystem.
 // If the path is a directory containing a single file, that file is used.
 func (loader *ModuleLoader) loadModuleByPath(ctx context.Context, path string) (wazero.CompiledModule, error) {
@@ -194,7 +230,21 @@ func (loader *ModuleLoader) loadFile(ctx context.Context, path string) (wazero.C
 		return nil, NewModuleLoadError(path, err)
 	}
 
-	module, err := loader.runtime.CompileModule(ctx, bytes)
+	// Components can only be entry modules, and are otherwise rejected with a confusing error
+	if component.IsComponent(bytes) {
+		return nil, NewComponentNotSupportedError(path)
+	}
+
+	if bytes, err = meterModule(ctx, bytes); err != nil {
+		return nil, NewModuleCompileError(path, err)
+	}
+
+	var module wazero.CompiledModule
+	if loader.cache != nil {
+		module, err = loader.cache.Compile(ctx, loader.runtime, bytes)
+	} else {
+		module, err = loader.runtime.CompileModule(ctx, bytes)
+	}
 	if err != nil {
 		return nil, NewModuleCompileError(path, err)
 	}
@@ -69,6 +69,16 @@ func TestModuleLoading(t *testing.T) {
 			errorChecker:  require.NoError,
 			moduleChecker: require.NotNil,
 		},
+		{
+			name: "rejects WebAssembly components as core modules",
+			// an empty component: the magic number followed by the component version and layer
+			entryModule:   []byte{0x00, 0x61, 0x73, 0x6d, 0x0d, 0x00, 0x01, 0x00},
+			importModules: map[string][]byte{},
+			errorChecker: func(t require.TestingT, err error, _ ...any) {
+				require.ErrorContains(t, err, "is a WebAssembly component")
+			},
+			moduleChecker: require.Nil,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -17,4 +17,22 @@ var (
 		"wasm_active_executions",
 		"Number of active WASM executions",
 	))
+
+	ModuleCacheHits = lo.Must(telemetry.NewCounter(
+		wasmExecutorMeter,
+		"wasm_module_cache_hits",
+		"Number of WASM module compilations served from the compiled module cache",
+	))
+
+	ModuleCacheMisses = lo.Must(telemetry.NewCounter(
+		wasmExecutorMeter,
+		"wasm_module_cache_misses",
+		"Number of WASM module compilations that missed the compiled module cache",
+	))
+
+	ModuleCacheEvictions = lo.Must(telemetry.NewCounter(
+		wasmExecutorMeter,
+		"wasm_module_cache_evictions",
+		"Number of compiled WASM modules evicted from the compiled module cache",
+	))
 )
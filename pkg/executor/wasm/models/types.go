@@ -10,6 +10,13 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/models"
 )
 
+const (
+	// KeyValueScopeJob limits the keys a job can access in the key-value store to its own keys. This is the default.
+	KeyValueScopeJob = "job"
+	// KeyValueScopeNamespace shares the keys of the key-value store with all jobs of the namespace.
+	KeyValueScopeNamespace = "namespace"
+)
+
 // EngineSpec contains necessary parameters to execute a wasm job.
 type EngineSpec struct {
 	// EntryModule is the target path of the input source containing the WASM code to start running.
@@ -19,6 +26,8 @@ type EngineSpec struct {
 	// Entrypoint is the name of the function in the EntryModule to call to run the job.
 	// For WASI jobs, this will should be `_start`, but jobs can choose to call other WASM functions instead.
 	// Entrypoint must be a zero-parameter zero-result function.
+	// For WebAssembly components, `_start` runs the `run` function of their `wasi:cli/run` export, and other
+	// entry points name a zero-parameter function the component exports, such as `wasi:cli/run@0.2.0#run`.
 	Entrypoint string `json:"Entrypoint,omitempty"`
 
 	// Parameters contains arguments supplied to the program (i.e. as ARGV).
@@ -27,12 +36,26 @@ type EngineSpec struct {
 	// ImportModules is a slice of target paths for WASM modules whose exports will be available as imports
 	// to the EntryModule. These targets must match InputSource targets in the job spec.
 	ImportModules []string `json:"ImportModules,omitempty"`
+
+	// Fuel is the budget of fuel the execution can consume, where entering a function and every iteration
+	// of a loop consume one unit each.
+	// Unlike the execution timeout, fuel is consumed the same way on every node, and an execution that
+	// exhausts its budget fails deterministically. Fuel is not metered if zero.
+	Fuel uint64 `json:"Fuel,omitempty" structs:",omitempty"`
+
+	// KeyValueScope is the scope of the keys the job can access in the key-value store, which is either
+	// KeyValueScopeJob or KeyValueScopeNamespace. Defaults to KeyValueScopeJob.
+	KeyValueScope string `json:"KeyValueScope,omitempty" structs:",omitempty"`
 }
 
 func (c EngineSpec) Validate() error {
 	if c.EntryModule == "" {
 		return errors.New("invalid wasm engine entry module. target path cannot be empty")
 	}
+	if c.KeyValueScope != "" && c.KeyValueScope != KeyValueScopeJob && c.KeyValueScope != KeyValueScopeNamespace {
+		return fmt.Errorf("invalid wasm engine key-value scope %q. must be %s or %s",
+			c.KeyValueScope, KeyValueScopeJob, KeyValueScopeNamespace)
+	}
 	return nil
 }
 
@@ -91,6 +114,16 @@ func (b *WasmEngineBuilder) WithImportModules(e []string) *WasmEngineBuilder {
 	return b
 }
 
+func (b *WasmEngineBuilder) WithFuel(fuel uint64) *WasmEngineBuilder {
+	b.spec.Fuel = fuel
+	return b
+}
+
+func (b *WasmEngineBuilder) WithKeyValueScope(scope string) *WasmEngineBuilder {
+	b.spec.KeyValueScope = scope
+	return b
+}
+
 func (b *WasmEngineBuilder) Build() (*models.SpecConfig, error) {
 	if err := b.spec.Validate(); err != nil {
 		return nil, err
@@ -2,6 +2,7 @@ package wasm
 
 import (
 	"context"
+	"encoding/binary"
 
 	"github.com/bacalhau-project/bacalhau/pkg/telemetry"
 
@@ -13,6 +14,11 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	customSectionID = 0
+	nameSectionName = "name"
+)
+
 var _ wazero.Runtime = tracedRuntime{}
 var _ api.Function = tracedFunction{}
 var _ api.Module = tracedModule{}
@@ -48,15 +54,45 @@ type tracedCompiledModule struct {
 	observeTrace
 }
 
-func (t tracedRuntime) Instantiate(ctx context.Context, source []byte) (api.Module, error) {
-	var traceCtx *observe.TraceCtx
-	var err error
+// newTraceCtx returns the trace context of a module, or nil if tracing is disabled. Modules without a name
+// section, such as the core modules generated for WebAssembly components, can't be traced by the adapter
+// and are run without a trace context.
+func (t tracedRuntime) newTraceCtx(ctx context.Context, module []byte) (*observe.TraceCtx, error) {
+	if t.adapter == nil || !hasNameSection(module) {
+		return nil, nil
+	}
+	return t.adapter.NewTraceCtx(ctx, t.Runtime, module, nil)
+}
 
-	if t.adapter != nil {
-		traceCtx, err = t.adapter.NewTraceCtx(ctx, t.Runtime, source, nil)
-		if err != nil {
-			return nil, err
+// hasNameSection reports whether the core module binary has a "name" custom section
+func hasNameSection(module []byte) bool {
+	const headerLength = 8
+	if len(module) < headerLength {
+		return false
+	}
+	for rest := module[headerLength:]; len(rest) > 0; {
+		id := rest[0]
+		size, n := binary.Uvarint(rest[1:])
+		if n <= 0 || size > uint64(len(rest)-1-n) {
+			return false
+		}
+		section := rest[1+n : 1+n+int(size)]
+		rest = rest[1+n+int(size):]
+		if id != customSectionID {
+			continue
 		}
+		nameLength, m := binary.Uvarint(section)
+		if m > 0 && nameLength <= uint64(len(section)-m) && string(section[m:m+int(nameLength)]) == nameSectionName {
+			return true
+		}
+	}
+	return false
+}
+
+func (t tracedRuntime) Instantiate(ctx context.Context, source []byte) (api.Module, error) {
+	traceCtx, err := t.newTraceCtx(ctx, source)
+	if err != nil {
+		return nil, err
 	}
 	ctx, span := telemetry.NewSpan(ctx, telemetry.GetTracer(), "pkg/executor/wasm.tracedRuntime.Instantiate")
 	defer span.End()
@@ -68,14 +104,9 @@ func (t tracedRuntime) Instantiate(ctx context.Context, source []byte) (api.Modu
 }
 
 func (t tracedRuntime) InstantiateWithConfig(ctx context.Context, source []byte, config wazero.ModuleConfig) (api.Module, error) {
-	var traceCtx *observe.TraceCtx
-	var err error
-
-	if t.adapter != nil {
-		traceCtx, err = t.adapter.NewTraceCtx(ctx, t.Runtime, source, nil)
-		if err != nil {
-			return nil, err
-		}
+	traceCtx, err := t.newTraceCtx(ctx, source)
+	if err != nil {
+		return nil, err
 	}
 	ctx, span := telemetry.NewSpan(ctx, telemetry.GetTracer(), "pkg/executor/wasm.tracedRuntime.InstantiateWithConfig")
 	defer span.End()
@@ -87,14 +118,9 @@ func (t tracedRuntime) InstantiateWithConfig(ctx context.Context, source []byte,
 }
 
 func (t tracedRuntime) CompileModule(ctx context.Context, binary []byte) (wazero.CompiledModule, error) {
-	var traceCtx *observe.TraceCtx
-	var err error
-
-	if t.adapter != nil {
-		traceCtx, err = t.adapter.NewTraceCtx(ctx, t.Runtime, binary, nil)
-		if err != nil {
-			return nil, err
-		}
+	traceCtx, err := t.newTraceCtx(ctx, binary)
+	if err != nil {
+		return nil, err
 	}
 	ctx, span := telemetry.NewSpan(ctx, telemetry.GetTracer(), "pkg/executor/wasm.tracedRuntime.CompileModule")
 	defer span.End()
@@ -0,0 +1,31 @@
+//go:build unit || !integration
+
+package wasm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/component/test"
+)
+
+func TestHasNameSection(t *testing.T) {
+	// a name section naming the function "run"
+	names := test.Section(0, test.Name("name"), test.Section(1, test.Vec(test.Bytes(test.U32(0), test.Name("run")))))
+	require.True(t, hasNameSection(test.Module(
+		test.Section(test.CoreSectionType, test.Vec(test.FuncType(nil, nil))),
+		test.Section(0, test.Name("producers"), test.Vec()),
+		names,
+	)))
+
+	require.False(t, hasNameSection(test.Module()))
+	require.False(t, hasNameSection(test.Module(test.Section(0, test.Name("producers"), test.Vec()))))
+	require.False(t, hasNameSection(test.Shim([]byte{test.I32})))
+	require.False(t, hasNameSection(nil))
+
+	// truncated sections
+	truncated := test.Module(names)
+	require.False(t, hasNameSection(truncated[:len(truncated)-1]))
+	require.False(t, hasNameSection(truncated[:10]))
+}
@@ -0,0 +1,264 @@
+// overlayfs implements an fs.FS that overlays a writable upper directory over a
+// read-only lower directory, similar to the kernel overlay filesystem.
+//
+// Files are read from the upper directory if they exist there, and from the
+// lower directory otherwise. A file of the lower directory is copied into the
+// upper directory the first time it is written to, so that the lower directory
+// is never modified and only the files written by the WASM module end up in the
+// upper directory. Directories list the files of both directories.
+//
+// Like touchfs, files that don't exist in either directory are created in the
+// upper directory when they are opened, as long as their parent directory
+// exists. Files cannot be deleted or renamed through an fs.FS.
+
+package overlayfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+)
+
+type overlayFS struct {
+	lower string
+	upper string
+}
+
+// New returns an fs.FS serving the files of the lower directory, with any changes
+// written to the upper directory instead
+func New(lower, upper string) fs.FS {
+	return &overlayFS{lower: lower, upper: upper}
+}
+
+func (o *overlayFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	upperPath := filepath.Join(o.upper, name)
+	lowerPath := filepath.Join(o.lower, name)
+	upperStat, upperErr := os.Stat(upperPath)
+	lowerStat, lowerErr := os.Stat(lowerPath)
+
+	switch {
+	case upperErr == nil && upperStat.IsDir(), upperErr != nil && lowerErr == nil && lowerStat.IsDir():
+		return &overlayDir{fs: o, name: name}, nil
+	case upperErr == nil:
+		// the file was already copied up or created, so it is writable
+		return os.OpenFile(upperPath, os.O_RDWR, 0) //nolint:gosec // G304: path within the upper directory
+	case lowerErr == nil:
+		file, err := os.Open(lowerPath) //nolint:gosec // G304: path within the lower directory
+		if err != nil {
+			return nil, err
+		}
+		return &copyOnWriteFile{fs: o, name: name, file: file}, nil
+	case errors.Is(upperErr, fs.ErrNotExist) && errors.Is(lowerErr, fs.ErrNotExist):
+		if err := o.copyUpDir(filepath.Dir(name)); err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return os.Create(upperPath) //nolint:gosec // G304: path within the upper directory
+	default:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.Join(upperErr, lowerErr)}
+	}
+}
+
+// copyUpDir creates the directory in the upper directory if it exists in the lower directory
+func (o *overlayFS) copyUpDir(dir string) error {
+	if _, err := os.Stat(filepath.Join(o.upper, dir)); err == nil {
+		return nil
+	}
+	stat, err := os.Stat(filepath.Join(o.lower, dir))
+	if err != nil {
+		return err
+	}
+	if !stat.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	return os.MkdirAll(filepath.Join(o.upper, dir), util.OS_USER_RWX)
+}
+
+// copyUp copies the file of the lower directory into the upper directory, and opens the copy for writing
+func (o *overlayFS) copyUp(name string) (*os.File, error) {
+	if err := o.copyUpDir(filepath.Dir(name)); err != nil {
+		return nil, err
+	}
+	lowerPath := filepath.Join(o.lower, name)
+	stat, err := os.Stat(lowerPath)
+	if err != nil {
+		return nil, err
+	}
+	src, err := os.Open(lowerPath) //nolint:gosec // G304: path within the lower directory
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = src.Close() }()
+
+	//nolint:gosec // G304: path within the upper directory
+	dst, err := os.OpenFile(filepath.Join(o.upper, name), os.O_CREATE|os.O_RDWR|os.O_TRUNC, stat.Mode().Perm()|util.OS_USER_W)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return nil, err
+	}
+	return dst, nil
+}
+
+// copyOnWriteFile is a file of the lower directory, which is copied into the upper
+// directory the first time it is written to
+type copyOnWriteFile struct {
+	fs   *overlayFS
+	name string
+
+	mu     sync.Mutex
+	file   *os.File
+	copied bool
+}
+
+func (f *copyOnWriteFile) current() *os.File {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file
+}
+
+// writable returns the copy of the file in the upper directory, at the offset of the lower file
+func (f *copyOnWriteFile) writable() (*os.File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.copied {
+		return f.file, nil
+	}
+	offset, err := f.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	upper, err := f.fs.copyUp(f.name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = upper.Seek(offset, io.SeekStart); err != nil {
+		_ = upper.Close()
+		return nil, err
+	}
+	_ = f.file.Close()
+	f.file = upper
+	f.copied = true
+	return f.file, nil
+}
+
+func (f *copyOnWriteFile) Stat() (fs.FileInfo, error) {
+	return f.current().Stat()
+}
+
+func (f *copyOnWriteFile) Read(p []byte) (int, error) {
+	return f.current().Read(p)
+}
+
+func (f *copyOnWriteFile) ReadAt(p []byte, off int64) (int, error) {
+	return f.current().ReadAt(p, off)
+}
+
+func (f *copyOnWriteFile) Seek(offset int64, whence int) (int64, error) {
+	return f.current().Seek(offset, whence)
+}
+
+func (f *copyOnWriteFile) Write(p []byte) (int, error) {
+	file, err := f.writable()
+	if err != nil {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: err}
+	}
+	return file.Write(p)
+}
+
+func (f *copyOnWriteFile) WriteAt(p []byte, off int64) (int, error) {
+	file, err := f.writable()
+	if err != nil {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: err}
+	}
+	return file.WriteAt(p, off)
+}
+
+func (f *copyOnWriteFile) Close() error {
+	return f.current().Close()
+}
+
+// overlayDir is a directory listing the files of both the upper and lower directories
+type overlayDir struct {
+	fs   *overlayFS
+	name string
+
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *overlayDir) Stat() (fs.FileInfo, error) {
+	stat, err := os.Stat(filepath.Join(d.fs.upper, d.name))
+	if err != nil {
+		return os.Stat(filepath.Join(d.fs.lower, d.name))
+	}
+	return stat, nil
+}
+
+func (d *overlayDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fmt.Errorf("is a directory")}
+}
+
+func (d *overlayDir) Close() error {
+	return nil
+}
+
+func (d *overlayDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.list()
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+		d.read = true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+// list returns the entries of the directory, where entries of the upper directory shadow the lower ones
+func (d *overlayDir) list() ([]fs.DirEntry, error) {
+	byName := make(map[string]fs.DirEntry)
+	for _, root := range []string{d.fs.lower, d.fs.upper} {
+		entries, err := os.ReadDir(filepath.Join(root, d.name))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			byName[entry.Name()] = entry
+		}
+	}
+	entries := make([]fs.DirEntry, 0, len(byName))
+	for _, entry := range byName {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+var _ fs.ReadDirFile = (*overlayDir)(nil)
+var _ io.WriterAt = (*copyOnWriteFile)(nil)
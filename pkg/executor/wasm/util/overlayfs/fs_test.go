@@ -0,0 +1,96 @@
+//go:build unit || !integration
+
+package overlayfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type overlayFsSuite struct {
+	suite.Suite
+
+	lowerDir string
+	upperDir string
+	fs       fs.FS
+}
+
+func TestOverlayFSSuite(t *testing.T) {
+	suite.Run(t, new(overlayFsSuite))
+}
+
+func (suite *overlayFsSuite) SetupTest() {
+	suite.lowerDir = suite.T().TempDir()
+	suite.upperDir = suite.T().TempDir()
+	require.NoError(suite.T(), os.MkdirAll(filepath.Join(suite.lowerDir, "sub"), 0o755))
+	require.NoError(suite.T(), os.WriteFile(filepath.Join(suite.lowerDir, "test.txt"), []byte("hello"), 0o444))
+	require.NoError(suite.T(), os.WriteFile(filepath.Join(suite.lowerDir, "sub", "nested.txt"), []byte("nested"), 0o644))
+	suite.fs = New(suite.lowerDir, suite.upperDir)
+}
+
+func (suite *overlayFsSuite) write(name string, data string) {
+	file, err := suite.fs.Open(name)
+	require.NoError(suite.T(), err)
+	defer func() { _ = file.Close() }()
+
+	writer, ok := file.(io.Writer)
+	require.True(suite.T(), ok)
+	_, err = io.WriteString(writer, data)
+	require.NoError(suite.T(), err)
+}
+
+func (suite *overlayFsSuite) TestReadingDoesNotCopy() {
+	contents, err := fs.ReadFile(suite.fs, "sub/nested.txt")
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), "nested", string(contents))
+	require.NoFileExists(suite.T(), filepath.Join(suite.upperDir, "sub", "nested.txt"))
+}
+
+func (suite *overlayFsSuite) TestWritingCopiesUp() {
+	suite.write("test.txt", "HE")
+	suite.write("sub/nested.txt", "NE")
+
+	contents, err := fs.ReadFile(suite.fs, "test.txt")
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), "HEllo", string(contents))
+	contents, err = fs.ReadFile(suite.fs, "sub/nested.txt")
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), "NEsted", string(contents))
+
+	// the lower directory is not modified, and the upper directory only holds the written files
+	contents, err = os.ReadFile(filepath.Join(suite.lowerDir, "test.txt"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), "hello", string(contents))
+	require.FileExists(suite.T(), filepath.Join(suite.upperDir, "sub", "nested.txt"))
+}
+
+func (suite *overlayFsSuite) TestNewFile() {
+	suite.write("sub/new.txt", "cool")
+
+	contents, err := os.ReadFile(filepath.Join(suite.upperDir, "sub", "new.txt"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), "cool", string(contents))
+	require.NoFileExists(suite.T(), filepath.Join(suite.lowerDir, "sub", "new.txt"))
+
+	_, err = suite.fs.Open("missing/new.txt")
+	require.Error(suite.T(), err)
+}
+
+func (suite *overlayFsSuite) TestReadDirMergesLayers() {
+	suite.write("new.txt", "cool")
+	suite.write("test.txt", "HE")
+
+	entries, err := fs.ReadDir(suite.fs, ".")
+	require.NoError(suite.T(), err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	require.Equal(suite.T(), []string{"new.txt", "sub", "test.txt"}, names)
+}
@@ -1679,7 +1679,8 @@ func (b *BoltJobStore) addHistory(
 	recorder.Latency(ctx, jobstore.OperationPartDuration, jobstore.AttrOperationPartSequence)
 
 	historyEntry.SeqNum = seq
-	data, err := b.marshaller.Marshal(historyEntry)
+	// events can echo task specs, so make sure sealed secrets never reach the history
+	data, err := b.marshaller.Marshal(historyEntry.Redacted())
 	if err != nil {
 		return err
 	}
@@ -1819,7 +1820,9 @@ func (b *BoltJobStore) updateExecution(
 	if err := request.Condition.Validate(existingExecution); err != nil {
 		return err
 	}
-	if existingExecution.IsTerminalComputeState() {
+	// terminal executions can only be updated to record the results a cancelled execution
+	// published while it was terminating
+	if existingExecution.IsTerminalComputeState() && !existingExecution.AcceptsTerminalUpdate(request.NewValues) {
 		return jobstore.NewErrExecutionAlreadyTerminal(
 			request.ExecutionID, existingExecution.ComputeState.StateType, request.NewValues.ComputeState.StateType)
 	}
@@ -0,0 +1,303 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog/log"
+
+	natsutil "github.com/bacalhau-project/bacalhau/pkg/nats"
+)
+
+const (
+	// BlobsBucket is the object store bucket of committed blobs
+	BlobsBucket = "bacalhau-blobs"
+	// ChunksBucket is the object store bucket of the chunks of blobs being uploaded
+	ChunksBucket = "bacalhau-blob-chunks"
+	// ChunksTTL is how long the chunks of uploads that are never committed are kept
+	ChunksTTL = time.Hour
+)
+
+type NATSStoreParams struct {
+	// ClientFactory creates the client connected to the orchestrator
+	ClientFactory natsutil.ClientFactory
+}
+
+// NATSStore is a Store backed by JetStream object store buckets on the orchestrator.
+// It connects lazily on first use, so that nodes that never fetch blobs don't hold a connection.
+type NATSStore struct {
+	clientFactory natsutil.ClientFactory
+
+	mu     sync.Mutex
+	conn   *nats.Conn
+	blobs  jetstream.ObjectStore
+	chunks jetstream.ObjectStore
+}
+
+func NewNATSStore(params NATSStoreParams) (*NATSStore, error) {
+	if params.ClientFactory == nil {
+		return nil, errors.New("nats client factory is required")
+	}
+	return &NATSStore{clientFactory: params.ClientFactory}, nil
+}
+
+// buckets returns the blobs and chunks buckets, connecting and creating them if they do not exist
+func (s *NATSStore) buckets(ctx context.Context) (jetstream.ObjectStore, jetstream.ObjectStore, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.blobs != nil {
+		return s.blobs, s.chunks, nil
+	}
+
+	if s.conn == nil {
+		conn, err := s.clientFactory.CreateClient(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to blob store: %w", err)
+		}
+		s.conn = conn
+	}
+	js, err := jetstream.New(s.conn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to jetstream: %w", err)
+	}
+
+	blobs, err := objectStore(ctx, js, jetstream.ObjectStoreConfig{
+		Bucket:      BlobsBucket,
+		Description: "Content-addressed blobs uploaded as job inputs",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	chunks, err := objectStore(ctx, js, jetstream.ObjectStoreConfig{
+		Bucket:      ChunksBucket,
+		Description: "Chunks of blobs being uploaded",
+		TTL:         ChunksTTL,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	s.blobs, s.chunks = blobs, chunks
+	return blobs, chunks, nil
+}
+
+// objectStore opens an object store bucket, creating it if it does not exist
+func objectStore(ctx context.Context, js jetstream.JetStream, config jetstream.ObjectStoreConfig) (
+	jetstream.ObjectStore, error) {
+	store, err := js.ObjectStore(ctx, config.Bucket)
+	if errors.Is(err, jetstream.ErrBucketNotFound) {
+		store, err = js.CreateObjectStore(ctx, config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open object store bucket %s: %w", config.Bucket, err)
+	}
+	return store, nil
+}
+
+// Stat implements Store
+func (s *NATSStore) Stat(ctx context.Context, digest string) (Info, error) {
+	if err := ValidateDigest(digest); err != nil {
+		return Info{}, err
+	}
+	blobs, _, err := s.buckets(ctx)
+	if err != nil {
+		return Info{}, err
+	}
+	info, err := blobs.GetInfo(ctx, digest)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrObjectNotFound) {
+			return Info{}, NewErrBlobNotFound(digest)
+		}
+		return Info{}, err
+	}
+	return objectInfo(info), nil
+}
+
+// PutChunk implements Store
+func (s *NATSStore) PutChunk(ctx context.Context, digest string, index int, data []byte) error {
+	if err := ValidateDigest(digest); err != nil {
+		return err
+	}
+	if err := ValidateChunkIndex(index); err != nil {
+		return err
+	}
+	if len(data) > ChunkSize {
+		return newValidationError(fmt.Sprintf("chunk of %d bytes exceeds the maximum of %d bytes", len(data), ChunkSize))
+	}
+	_, chunks, err := s.buckets(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = chunks.PutBytes(ctx, chunkName(digest, index), data)
+	return err
+}
+
+// Commit implements Store. The chunks are read twice: once to verify the digest, and once to store the blob,
+// so that a blob is never visible with content that does not match its digest. Committing an existing
+// blob updates its commit time, so that it is not garbage collected before the job using it is submitted.
+func (s *NATSStore) Commit(ctx context.Context, digest string, chunks int) (Info, error) {
+	if err := ValidateDigest(digest); err != nil {
+		return Info{}, err
+	}
+	if chunks < 1 || chunks > MaxChunks {
+		return Info{}, newValidationError(fmt.Sprintf("invalid number of chunks %d. Must be between 1 and %d", chunks, MaxChunks))
+	}
+	blobs, chunkStore, err := s.buckets(ctx)
+	if err != nil {
+		return Info{}, err
+	}
+	if _, err = s.Stat(ctx, digest); err == nil {
+		s.deleteChunks(ctx, digest, chunks)
+		if err = blobs.UpdateMeta(ctx, digest, jetstream.ObjectMeta{Name: digest}); err != nil {
+			return Info{}, fmt.Errorf("failed to update blob %s: %w", digest, err)
+		}
+		return s.Stat(ctx, digest)
+	}
+
+	hash := sha256.New()
+	if err = copyChunks(ctx, chunkStore, digest, chunks, hash); err != nil {
+		return Info{}, err
+	}
+	if actual := FormatDigest(hash.Sum(nil)); actual != digest {
+		s.deleteChunks(ctx, digest, chunks)
+		return Info{}, newValidationError(fmt.Sprintf("uploaded content has digest %s instead of %s", actual, digest))
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(copyChunks(ctx, chunkStore, digest, chunks, writer))
+	}()
+	info, err := blobs.Put(ctx, jetstream.ObjectMeta{Name: digest}, reader)
+	_ = reader.Close()
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to store blob %s: %w", digest, err)
+	}
+	s.deleteChunks(ctx, digest, chunks)
+	return objectInfo(info), nil
+}
+
+// Get implements Store
+func (s *NATSStore) Get(ctx context.Context, digest string, w io.Writer) (Info, error) {
+	if err := ValidateDigest(digest); err != nil {
+		return Info{}, err
+	}
+	blobs, _, err := s.buckets(ctx)
+	if err != nil {
+		return Info{}, err
+	}
+	result, err := blobs.Get(ctx, digest)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrObjectNotFound) {
+			return Info{}, NewErrBlobNotFound(digest)
+		}
+		return Info{}, err
+	}
+	defer result.Close()
+	size, err := io.Copy(w, result)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to read blob %s: %w", digest, err)
+	}
+	return Info{Digest: digest, Size: size}, nil
+}
+
+// List implements Store
+func (s *NATSStore) List(ctx context.Context) ([]Info, error) {
+	blobs, _, err := s.buckets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := blobs.List(ctx)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrNoObjectsFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	infos := make([]Info, 0, len(objects))
+	for _, object := range objects {
+		infos = append(infos, objectInfo(object))
+	}
+	return infos, nil
+}
+
+// Delete implements Store
+func (s *NATSStore) Delete(ctx context.Context, digest string) error {
+	if err := ValidateDigest(digest); err != nil {
+		return err
+	}
+	blobs, _, err := s.buckets(ctx)
+	if err != nil {
+		return err
+	}
+	if err = blobs.Delete(ctx, digest); err != nil && !errors.Is(err, jetstream.ErrObjectNotFound) {
+		return fmt.Errorf("failed to delete blob %s: %w", digest, err)
+	}
+	return nil
+}
+
+// Close closes the connection to the store, if any
+func (s *NATSStore) Close(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	s.blobs, s.chunks = nil, nil
+	return nil
+}
+
+func objectInfo(info *jetstream.ObjectInfo) Info {
+	return Info{
+		Digest:    info.Name,
+		Size:      int64(info.Size), //nolint:gosec // G115: blob sizes fit in int64
+		Committed: info.ModTime,
+	}
+}
+
+// copyChunks writes the chunks of a blob in order to w
+func copyChunks(ctx context.Context, store jetstream.ObjectStore, digest string, chunks int, w io.Writer) error {
+	for index := range chunks {
+		result, err := store.Get(ctx, chunkName(digest, index))
+		if err != nil {
+			if errors.Is(err, jetstream.ErrObjectNotFound) {
+				return newValidationError(fmt.Sprintf("chunk %d of blob %s was not uploaded", index, digest))
+			}
+			return err
+		}
+		_, err = io.Copy(w, result)
+		_ = result.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read chunk %d of blob %s: %w", index, digest, err)
+		}
+	}
+	return nil
+}
+
+// deleteChunks deletes the uploaded chunks of a blob. Failures are only logged,
+// as chunks that are left behind expire.
+func (s *NATSStore) deleteChunks(ctx context.Context, digest string, chunks int) {
+	_, store, err := s.buckets(ctx)
+	if err != nil {
+		return
+	}
+	for index := range chunks {
+		err = store.Delete(ctx, chunkName(digest, index))
+		if err != nil && !errors.Is(err, jetstream.ErrObjectNotFound) {
+			log.Ctx(ctx).Debug().Err(err).Msgf("failed to delete chunk %d of blob %s", index, digest)
+		}
+	}
+}
+
+func chunkName(digest string, index int) string {
+	return fmt.Sprintf("%s/%06d", digest, index)
+}
+
+// compile-time check that we implement the interface
+var _ Store = (*NATSStore)(nil)
@@ -0,0 +1,165 @@
+//go:build unit || !integration
+
+package blobstore_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/blobstore"
+	natsutil "github.com/bacalhau-project/bacalhau/pkg/nats"
+)
+
+type NATSStoreSuite struct {
+	suite.Suite
+	ctx   context.Context
+	nats  *server.Server
+	store *blobstore.NATSStore
+}
+
+func TestNATSStoreSuite(t *testing.T) {
+	suite.Run(t, new(NATSStoreSuite))
+}
+
+func (s *NATSStoreSuite) SetupTest() {
+	s.ctx = context.Background()
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	opts.JetStream = true
+	opts.StoreDir = s.T().TempDir()
+	s.nats = natsserver.RunServer(&opts)
+
+	var err error
+	s.store, err = blobstore.NewNATSStore(blobstore.NATSStoreParams{
+		ClientFactory: natsutil.ClientFactoryFunc(func(context.Context) (*nats.Conn, error) {
+			return nats.Connect(s.nats.ClientURL())
+		}),
+	})
+	s.Require().NoError(err)
+}
+
+func (s *NATSStoreSuite) TearDownTest() {
+	s.Require().NoError(s.store.Close(s.ctx))
+	s.nats.Shutdown()
+}
+
+// upload uploads data in chunks of chunkSize, and returns its digest
+func (s *NATSStoreSuite) upload(data []byte, chunkSize int) (string, int) {
+	digest, _, err := blobstore.Digest(bytes.NewReader(data))
+	s.Require().NoError(err)
+	chunks := 0
+	for offset := 0; offset < len(data); offset += chunkSize {
+		s.Require().NoError(s.store.PutChunk(s.ctx, digest, chunks, data[offset:min(offset+chunkSize, len(data))]))
+		chunks++
+	}
+	return digest, chunks
+}
+
+func (s *NATSStoreSuite) requireBlob(digest string, expected []byte) {
+	var content bytes.Buffer
+	info, err := s.store.Get(s.ctx, digest, &content)
+	s.Require().NoError(err)
+	s.Equal(int64(len(expected)), info.Size)
+	s.Equal(expected, content.Bytes())
+}
+
+func (s *NATSStoreSuite) TestUploadInChunks() {
+	data := make([]byte, 300*1024)
+	_, err := rand.Read(data)
+	s.Require().NoError(err)
+
+	digest, chunks := s.upload(data, 128*1024)
+	s.Require().Equal(3, chunks)
+	_, err = s.store.Stat(s.ctx, digest)
+	s.True(bacerrors.IsErrorWithCode(err, bacerrors.NotFoundError), "blob must not exist before it is committed")
+
+	info, err := s.store.Commit(s.ctx, digest, chunks)
+	s.Require().NoError(err)
+	s.Equal(digest, info.Digest)
+	s.Equal(int64(len(data)), info.Size)
+
+	info, err = s.store.Stat(s.ctx, digest)
+	s.Require().NoError(err)
+	s.Equal(int64(len(data)), info.Size)
+	s.requireBlob(digest, data)
+}
+
+func (s *NATSStoreSuite) TestCommitExistingBlob() {
+	data := []byte("hello blob")
+	digest, chunks := s.upload(data, len(data))
+	first, err := s.store.Commit(s.ctx, digest, chunks)
+	s.Require().NoError(err)
+
+	// committing again without uploading the chunks deduplicates uploads, and only updates the commit time
+	info, err := s.store.Commit(s.ctx, digest, chunks)
+	s.Require().NoError(err)
+	s.Equal(int64(len(data)), info.Size)
+	s.True(info.Committed.After(first.Committed), "the commit time should be updated")
+	s.requireBlob(digest, data)
+}
+
+func (s *NATSStoreSuite) TestListAndDelete() {
+	blobs, err := s.store.List(s.ctx)
+	s.Require().NoError(err)
+	s.Empty(blobs)
+
+	digest, chunks := s.upload([]byte("hello blob"), 4)
+	_, err = s.store.Commit(s.ctx, digest, chunks)
+	s.Require().NoError(err)
+	blobs, err = s.store.List(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Len(blobs, 1)
+	s.Equal(digest, blobs[0].Digest)
+	s.Equal(int64(10), blobs[0].Size)
+	s.False(blobs[0].Committed.IsZero())
+
+	s.Require().NoError(s.store.Delete(s.ctx, digest))
+	_, err = s.store.Stat(s.ctx, digest)
+	s.True(bacerrors.IsErrorWithCode(err, bacerrors.NotFoundError))
+	s.Require().NoError(s.store.Delete(s.ctx, digest), "deleting a missing blob is a no-op")
+}
+
+func (s *NATSStoreSuite) TestCommitDigestMismatch() {
+	digest, _, err := blobstore.Digest(bytes.NewReader([]byte("expected")))
+	s.Require().NoError(err)
+	s.Require().NoError(s.store.PutChunk(s.ctx, digest, 0, []byte("tampered")))
+
+	_, err = s.store.Commit(s.ctx, digest, 1)
+	s.Require().Error(err)
+	s.True(bacerrors.IsErrorWithCode(err, bacerrors.ValidationError))
+	_, err = s.store.Stat(s.ctx, digest)
+	s.True(bacerrors.IsErrorWithCode(err, bacerrors.NotFoundError))
+}
+
+func (s *NATSStoreSuite) TestCommitMissingChunk() {
+	data := []byte("0123456789")
+	digest, _, err := blobstore.Digest(bytes.NewReader(data))
+	s.Require().NoError(err)
+	s.Require().NoError(s.store.PutChunk(s.ctx, digest, 0, data[:5]))
+
+	_, err = s.store.Commit(s.ctx, digest, 2)
+	s.Require().Error(err)
+	s.True(bacerrors.IsErrorWithCode(err, bacerrors.ValidationError))
+}
+
+func (s *NATSStoreSuite) TestInvalidDigest() {
+	for _, digest := range []string{"", "abc", "md5:abc", "sha256:xyz", "sha256:ABCDEF"} {
+		_, err := s.store.Stat(s.ctx, digest)
+		s.True(bacerrors.IsErrorWithCode(err, bacerrors.ValidationError), digest)
+	}
+}
+
+func (s *NATSStoreSuite) TestGetMissingBlob() {
+	digest, _, err := blobstore.Digest(bytes.NewReader([]byte("missing")))
+	s.Require().NoError(err)
+	_, err = s.store.Get(s.ctx, digest, &bytes.Buffer{})
+	s.True(bacerrors.IsErrorWithCode(err, bacerrors.NotFoundError))
+}
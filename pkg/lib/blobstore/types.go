@@ -0,0 +1,108 @@
+// Package blobstore stores content-addressed blobs, such as archives of local directories that
+// clients upload as job inputs, in a JetStream object store of the orchestrator.
+//
+// Blobs are identified by the digest of their content, so that identical uploads are deduplicated
+// across jobs. Clients upload blobs in chunks that fit within the body limit of API requests, and
+// commit them once all chunks are uploaded. Committing assembles the chunks into the blob and
+// verifies its digest, so that a blob is only visible once it is complete and intact.
+package blobstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+)
+
+const (
+	// DigestAlgorithm is the algorithm of the digests identifying blobs
+	DigestAlgorithm = "sha256"
+	// ChunkSize is the size of the chunks blobs are uploaded in, except for the last chunk.
+	// It keeps upload requests well within the body limit of the API.
+	ChunkSize = 4 * 1024 * 1024
+	// MaxChunks is the maximum number of chunks of a blob
+	MaxChunks = 10000
+
+	errComponent = "BlobStore"
+)
+
+// Info describes a stored blob
+type Info struct {
+	// Digest is the digest of the content of the blob, such as sha256:<hex>
+	Digest string `json:"Digest"`
+	// Size is the size of the blob in bytes
+	Size int64 `json:"Size"`
+	// Committed is when the blob was last committed. It is not returned by the API.
+	Committed time.Time `json:"-"`
+}
+
+// Store stores content-addressed blobs
+type Store interface {
+	// Stat returns the info of a blob, or a not found error if it was not committed
+	Stat(ctx context.Context, digest string) (Info, error)
+	// PutChunk stores a chunk of a blob being uploaded. Chunks are indexed from 0.
+	PutChunk(ctx context.Context, digest string, index int, data []byte) error
+	// Commit assembles the uploaded chunks of a blob into the blob, and verifies its digest.
+	// Committing a blob that already exists is a no-op, so that uploads are deduplicated.
+	Commit(ctx context.Context, digest string, chunks int) (Info, error)
+	// Get writes the content of a blob to w
+	Get(ctx context.Context, digest string, w io.Writer) (Info, error)
+	// List returns the info of all committed blobs
+	List(ctx context.Context) ([]Info, error)
+	// Delete deletes a committed blob. Deleting a blob that does not exist is a no-op.
+	Delete(ctx context.Context, digest string) error
+}
+
+// Digest returns the digest of the content read from r, and its size
+func Digest(r io.Reader) (string, int64, error) {
+	hash := sha256.New()
+	size, err := io.Copy(hash, r)
+	if err != nil {
+		return "", 0, err
+	}
+	return FormatDigest(hash.Sum(nil)), size, nil
+}
+
+// FormatDigest formats a sha256 sum as a blob digest
+func FormatDigest(sum []byte) string {
+	return DigestAlgorithm + ":" + hex.EncodeToString(sum)
+}
+
+// ValidateDigest returns an error if digest is not a sha256 digest
+func ValidateDigest(digest string) error {
+	algorithm, sum, ok := strings.Cut(digest, ":")
+	if !ok || algorithm != DigestAlgorithm {
+		return newValidationError(fmt.Sprintf("invalid blob digest %q. Must be of the form %s:<hex>", digest, DigestAlgorithm))
+	}
+	decoded, err := hex.DecodeString(sum)
+	if err != nil || len(decoded) != sha256.Size || strings.ToLower(sum) != sum {
+		return newValidationError(fmt.Sprintf("invalid blob digest %q. Must be a lowercase hex %s sum", digest, DigestAlgorithm))
+	}
+	return nil
+}
+
+// ValidateChunkIndex returns an error if index is not the index of a chunk of a blob
+func ValidateChunkIndex(index int) error {
+	if index < 0 || index >= MaxChunks {
+		return newValidationError(fmt.Sprintf("invalid chunk index %d. Must be between 0 and %d", index, MaxChunks-1))
+	}
+	return nil
+}
+
+// NewErrBlobNotFound returns an error for a blob that does not exist
+func NewErrBlobNotFound(digest string) bacerrors.Error {
+	return bacerrors.Newf("blob %s not found", digest).
+		WithComponent(errComponent).
+		WithCode(bacerrors.NotFoundError)
+}
+
+func newValidationError(message string) bacerrors.Error {
+	return bacerrors.New(message).
+		WithComponent(errComponent).
+		WithCode(bacerrors.ValidationError)
+}
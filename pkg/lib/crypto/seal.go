@@ -0,0 +1,128 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// sealedKeySize is the size of the random AES key used to encrypt sealed payloads.
+	sealedKeySize = 32
+	// sealedHeaderSize is the size of the length prefix of the wrapped key in a sealed payload.
+	sealedHeaderSize = 2
+)
+
+// Seal encrypts plaintext so that only the holder of the private key matching pub can open it.
+// A random AES-256-GCM key encrypts the plaintext and is itself wrapped with RSA-OAEP.
+// The result is encoded as URL-safe base64 so it can be embedded in job specs.
+func Seal(pub *rsa.PublicKey, plaintext []byte) (string, error) {
+	key := make([]byte, sealedKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", errors.Wrap(err, "failed to generate sealing key")
+	}
+
+	wrappedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, key, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to wrap sealing key")
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", errors.Wrap(err, "failed to generate nonce")
+	}
+
+	out := make([]byte, sealedHeaderSize, sealedHeaderSize+len(wrappedKey)+len(nonce)+len(plaintext)+gcm.Overhead())
+	binary.BigEndian.PutUint16(out, uint16(len(wrappedKey))) //nolint:gosec // G115: RSA ciphertexts fit in uint16
+	out = append(out, wrappedKey...)
+	out = append(out, nonce...)
+	out = gcm.Seal(out, nonce, plaintext, nil)
+	return base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// Unseal decrypts a payload produced by Seal using the matching private key.
+func Unseal(sk *rsa.PrivateKey, sealed string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode sealed payload")
+	}
+	if len(data) < sealedHeaderSize {
+		return nil, fmt.Errorf("sealed payload is too short")
+	}
+
+	keyLen := int(binary.BigEndian.Uint16(data))
+	data = data[sealedHeaderSize:]
+	if len(data) < keyLen {
+		return nil, fmt.Errorf("sealed payload is too short")
+	}
+
+	key, err := rsa.DecryptOAEP(sha256.New(), nil, sk, data[:keyLen], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unwrap sealing key")
+	}
+	data = data[keyLen:]
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("sealed payload is too short")
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt sealed payload")
+	}
+	return plaintext, nil
+}
+
+// EncodePublicKey encodes an RSA public key as base64 PKIX DER so it can be advertised by a node.
+func EncodePublicKey(pub *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal public key")
+	}
+	return base64.StdEncoding.EncodeToString(der), nil
+}
+
+// DecodePublicKey decodes an RSA public key encoded with EncodePublicKey.
+func DecodePublicKey(encoded string) (*rsa.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse public key")
+	}
+	pub, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+	return pub, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create GCM")
+	}
+	return gcm, nil
+}
@@ -0,0 +1,55 @@
+//go:build unit || !integration
+
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sealTestKeySize keeps key generation fast in tests.
+const sealTestKeySize = 2048
+
+func TestSealRoundTrip(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, sealTestKeySize)
+	require.NoError(t, err)
+
+	sealed, err := Seal(&key.PublicKey, []byte("s3cr3t"))
+	require.NoError(t, err)
+	require.NotContains(t, sealed, "s3cr3t")
+
+	opened, err := Unseal(key, sealed)
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", string(opened))
+}
+
+func TestUnsealWithWrongKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, sealTestKeySize)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, sealTestKeySize)
+	require.NoError(t, err)
+
+	sealed, err := Seal(&key.PublicKey, []byte("s3cr3t"))
+	require.NoError(t, err)
+
+	_, err = Unseal(other, sealed)
+	require.Error(t, err)
+
+	_, err = Unseal(key, "not-base64!")
+	require.Error(t, err)
+}
+
+func TestPublicKeyEncoding(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, sealTestKeySize)
+	require.NoError(t, err)
+
+	encoded, err := EncodePublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	decoded, err := DecodePublicKey(encoded)
+	require.NoError(t, err)
+	require.True(t, key.PublicKey.Equal(decoded))
+}
@@ -0,0 +1,38 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+)
+
+// signatureHash is the hash function used to sign and verify messages.
+const signatureHash = crypto.SHA256
+
+// Sign signs msg with RSASSA-PKCS1-v1_5 over its SHA-256 digest, and returns the signature as base64.
+func Sign(sk *rsa.PrivateKey, msg []byte) (string, error) {
+	digest := signatureHash.New()
+	digest.Write(msg)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, sk, signatureHash, digest.Sum(nil))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign message")
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// Verify verifies a signature produced by Sign with the private key matching pub.
+func Verify(pub *rsa.PublicKey, msg []byte, sig string) error {
+	sigBytes, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode signature")
+	}
+	digest := signatureHash.New()
+	digest.Write(msg)
+	if err = rsa.VerifyPKCS1v15(pub, signatureHash, digest.Sum(nil), sigBytes); err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+//go:build unit || !integration
+
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, sealTestKeySize)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, sealTestKeySize)
+	require.NoError(t, err)
+
+	sig, err := Sign(key, []byte("message"))
+	require.NoError(t, err)
+	require.NoError(t, Verify(&key.PublicKey, []byte("message"), sig))
+
+	require.Error(t, Verify(&key.PublicKey, []byte("tampered"), sig))
+	require.Error(t, Verify(&other.PublicKey, []byte("message"), sig))
+	require.Error(t, Verify(&key.PublicKey, []byte("message"), "not-base64!"))
+}
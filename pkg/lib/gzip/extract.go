@@ -0,0 +1,121 @@
+package gzip
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	extractedDirPerm  = 0755
+	extractedFilePerm = 0600
+)
+
+// ErrMaxSizeExceeded is returned when the files extracted from an archive exceed the maximum size
+var ErrMaxSizeExceeded = errors.New("extracted files exceed the maximum size")
+
+// ExtractTar extracts the tar archive read from r into destDir. The total size of the extracted files
+// is limited to maxBytes to prevent decompression bombs. Entries outside of destDir are rejected,
+// symlinks are only extracted if they point within destDir, and other special files are skipped.
+func ExtractTar(r io.Reader, destDir string, maxBytes int64) error {
+	reader := tar.NewReader(r)
+	remaining := maxBytes
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+		target, err := ExtractPath(destDir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, extractedDirPerm); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			written, err := WriteFileWithMaxBytes(target, reader, header.FileInfo().Mode().Perm()|extractedFilePerm, remaining)
+			if err != nil {
+				return err
+			}
+			remaining -= written
+		case tar.TypeSymlink:
+			if err = extractSymlink(target, header.Linkname); err != nil {
+				return err
+			}
+		default:
+			// hard links, devices and other special files are not extracted
+		}
+	}
+}
+
+// DecompressWithMaxTotalBytes decompresses the .tar.gz file at tarGzPath into destDir,
+// limiting the total size of the extracted files to maxBytes.
+func DecompressWithMaxTotalBytes(tarGzPath, destDir string, maxBytes int64) error {
+	file, err := os.Open(tarGzPath) //nolint:gosec // G304: tarGzPath is provided by the caller
+	if err != nil {
+		return fmt.Errorf("failed to open tar.gz file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	gzr, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer func() { _ = gzr.Close() }()
+	return ExtractTar(gzr, destDir, maxBytes)
+}
+
+// ExtractPath returns the path of an archive entry in dir, rejecting entries outside of dir
+func ExtractPath(dir, name string) (string, error) {
+	target := filepath.Join(dir, name)
+	if target != filepath.Clean(dir) && !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry %q is outside of the extracted directory", name)
+	}
+	return target, nil
+}
+
+// WriteFileWithMaxBytes writes the content to the file at path, creating its parent directories,
+// and returns the number of bytes written. It fails with ErrMaxSizeExceeded if the content is
+// larger than maxBytes.
+func WriteFileWithMaxBytes(path string, content io.Reader, perm os.FileMode, maxBytes int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), extractedDirPerm); err != nil {
+		return 0, err
+	}
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm) //nolint:gosec // G304: path is checked by the caller
+	if err != nil {
+		return 0, err
+	}
+	// one more byte than allowed is read to detect content exceeding the limit
+	written, err := io.Copy(out, io.LimitReader(content, max(maxBytes, 0)+1))
+	if err == nil && written > maxBytes {
+		err = fmt.Errorf("%w of %d bytes", ErrMaxSizeExceeded, maxBytes)
+	}
+	if err != nil {
+		_ = out.Close()
+		return written, err
+	}
+	return written, out.Close()
+}
+
+// extractSymlink creates a symlink if its target is within the extracted directory. Symlinks
+// cannot point up the tree, as the directories they traverse may themselves be symlinks.
+func extractSymlink(target, linkname string) error {
+	if filepath.IsAbs(linkname) || slices.Contains(strings.Split(filepath.ToSlash(linkname), "/"), "..") {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), extractedDirPerm); err != nil {
+		return err
+	}
+	return os.Symlink(linkname, target)
+}
@@ -0,0 +1,62 @@
+//go:build unit || !integration
+
+package gzip_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+)
+
+func tarArchive(t *testing.T, files map[string]string, symlinks map[string]string) *bytes.Buffer {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for name, content := range files {
+		require.NoError(t, writer.WriteHeader(&tar.Header{
+			Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg,
+		}))
+		_, err := writer.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	for name, link := range symlinks {
+		require.NoError(t, writer.WriteHeader(&tar.Header{Name: name, Linkname: link, Typeflag: tar.TypeSymlink}))
+	}
+	require.NoError(t, writer.Close())
+	return &buf
+}
+
+func TestExtractTar(t *testing.T) {
+	files := map[string]string{"a.txt": "a", "dir/b.txt": "b"}
+	dir := t.TempDir()
+	require.NoError(t, gzip.ExtractTar(tarArchive(t, files, map[string]string{"link": "dir/b.txt", "escape": "../a.txt"}), dir, 2))
+
+	for name, content := range files {
+		actual, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.Equal(t, content, string(actual))
+	}
+	link, err := os.Readlink(filepath.Join(dir, "link"))
+	require.NoError(t, err)
+	require.Equal(t, "dir/b.txt", link)
+	require.NoFileExists(t, filepath.Join(dir, "escape"))
+}
+
+func TestExtractTarLimitsTotalSize(t *testing.T) {
+	files := map[string]string{"a.txt": strings.Repeat("a", 10), "b.txt": strings.Repeat("b", 10)}
+	err := gzip.ExtractTar(tarArchive(t, files, nil), t.TempDir(), 15)
+	require.ErrorIs(t, err, gzip.ErrMaxSizeExceeded)
+}
+
+func TestExtractTarRejectsEntriesOutsideDir(t *testing.T) {
+	dir := t.TempDir()
+	err := gzip.ExtractTar(tarArchive(t, map[string]string{"../escaped.txt": "x"}, nil), filepath.Join(dir, "out"), 10)
+	require.ErrorContains(t, err, "outside of the extracted directory")
+	require.NoFileExists(t, filepath.Join(dir, "escaped.txt"))
+}
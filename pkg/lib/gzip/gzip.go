@@ -16,12 +16,26 @@ const DefaultMaxDecompressSize = 100 * 1024 * 1024 * 1024 // 100 GB
 // into a gzip archive written to targetFile. It uses relative paths for
 // the file headers within the archive to preserve the directory structure.
 func Compress(sourcePath string, targetFile *os.File) error {
-	gw := gzip.NewWriter(targetFile)
-	defer func() { _ = gw.Close() }()
+	return CompressToWriter(sourcePath, targetFile)
+}
 
+// CompressToWriter compresses the sourcePath (which can be a file or a directory)
+// into a gzip archive streamed to w, such as a pipe to an upload, without
+// writing the archive to disk.
+func CompressToWriter(sourcePath string, w io.Writer) error {
+	gw := gzip.NewWriter(w)
 	tarWriter := tar.NewWriter(gw)
-	defer func() { _ = tarWriter.Close() }()
+	if err := writeTar(sourcePath, tarWriter); err != nil {
+		return err
+	}
+	// closing writes the end of the archive, which must not be lost
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
 
+func writeTar(sourcePath string, tarWriter *tar.Writer) error {
 	info, err := os.Stat(sourcePath)
 	if err != nil {
 		return fmt.Errorf("failed to stat source path: %w", err)
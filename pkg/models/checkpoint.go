@@ -0,0 +1,141 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// CheckpointPath is the well-known directory inside the task where it writes its checkpoint state
+	CheckpointPath = "/bacalhau_checkpoint"
+	// CheckpointRestorePath is where the last checkpoint of a previous execution is mounted when restoring
+	CheckpointRestorePath = "/bacalhau_restore"
+	// CheckpointCompleteMarker is the file the task creates in the checkpoint directory
+	// to signal that its checkpoint has been fully written
+	CheckpointCompleteMarker = ".checkpoint_complete"
+	// CheckpointRestoreAlias is the alias of the input source used to restore a checkpoint
+	CheckpointRestoreAlias = "checkpoint"
+
+	// DefaultCheckpointSignal is the signal sent to the task to ask it to write a checkpoint
+	DefaultCheckpointSignal = "SIGUSR1"
+	// DefaultCheckpointTimeout is how long in seconds the task has to write its checkpoint after being signalled
+	DefaultCheckpointTimeout = 60
+)
+
+// CheckpointConfig is the configuration for periodically checkpointing the state of a
+// long-running task, so that it can be restored if its execution is rescheduled.
+// Checkpoints are published through the task's publisher as the execution <executionID>-checkpoint-<n>,
+// apart from the results of the execution.
+type CheckpointConfig struct {
+	// Interval is the time between checkpoints in seconds
+	Interval int64 `json:"Interval"`
+	// Timeout is the maximum amount of time in seconds the task has to write its checkpoint
+	// after being signalled. Defaults to DefaultCheckpointTimeout.
+	Timeout int64 `json:"Timeout,omitempty"`
+	// Signal is the signal sent to the task to ask it to write a checkpoint.
+	// Defaults to DefaultCheckpointSignal.
+	Signal string `json:"Signal,omitempty"`
+}
+
+// IsEnabled returns true if checkpoints are configured
+func (c *CheckpointConfig) IsEnabled() bool {
+	return c != nil && c.Interval > 0
+}
+
+// GetInterval returns the interval between checkpoints
+func (c *CheckpointConfig) GetInterval() time.Duration {
+	return time.Duration(c.Interval) * time.Second
+}
+
+// GetTimeout returns the maximum time the task has to write a checkpoint
+func (c *CheckpointConfig) GetTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return time.Duration(c.Timeout) * time.Second
+	}
+	return DefaultCheckpointTimeout * time.Second
+}
+
+// GetSignal returns the signal sent to the task to ask it to write a checkpoint
+func (c *CheckpointConfig) GetSignal() string {
+	if c.Signal != "" {
+		return c.Signal
+	}
+	return DefaultCheckpointSignal
+}
+
+// Normalize normalizes the checkpoint configuration
+func (c *CheckpointConfig) Normalize() {
+	if c == nil {
+		return
+	}
+	c.Signal = strings.ToUpper(strings.TrimSpace(c.Signal))
+}
+
+// Copy returns a deep copy of the checkpoint config.
+func (c *CheckpointConfig) Copy() *CheckpointConfig {
+	if c == nil {
+		return nil
+	}
+	return &CheckpointConfig{
+		Interval: c.Interval,
+		Timeout:  c.Timeout,
+		Signal:   c.Signal,
+	}
+}
+
+// Validate is used to check a checkpoint config for reasonable configuration.
+func (c *CheckpointConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	var mErr error
+	if c.Interval < 0 {
+		mErr = errors.Join(mErr, fmt.Errorf("invalid checkpoint interval value: %s", c.GetInterval()))
+	}
+	if c.Timeout < 0 {
+		mErr = errors.Join(mErr, fmt.Errorf("invalid checkpoint timeout value: %d", c.Timeout))
+	}
+	if c.Timeout > 0 && c.Interval > 0 && c.Timeout > c.Interval {
+		mErr = errors.Join(mErr, fmt.Errorf(
+			"checkpoint timeout %s should not be greater than checkpoint interval %s", c.GetTimeout(), c.GetInterval()))
+	}
+	return mErr
+}
+
+// Checkpoint is the state of a task that was checkpointed and published while its execution was running.
+type Checkpoint struct {
+	// Sequence is the number of the checkpoint within its execution, starting at 1
+	Sequence uint64 `json:"Sequence"`
+	// Result is where the checkpoint was published
+	Result *SpecConfig `json:"Result"`
+	// CreateTime is the time the checkpoint was published
+	CreateTime int64 `json:"CreateTime"`
+}
+
+// GetCreateTime returns the time the checkpoint was published
+func (c *Checkpoint) GetCreateTime() time.Time {
+	return time.Unix(0, c.CreateTime).UTC()
+}
+
+// RestoreInputSource returns the input source that mounts the checkpoint into a new execution
+func (c *Checkpoint) RestoreInputSource() *InputSource {
+	return &InputSource{
+		Source: c.Result.Copy(),
+		Alias:  CheckpointRestoreAlias,
+		Target: CheckpointRestorePath,
+	}
+}
+
+// Copy returns a deep copy of the checkpoint.
+func (c *Checkpoint) Copy() *Checkpoint {
+	if c == nil {
+		return nil
+	}
+	return &Checkpoint{
+		Sequence:   c.Sequence,
+		Result:     c.Result.Copy(),
+		CreateTime: c.CreateTime,
+	}
+}
@@ -0,0 +1,84 @@
+//go:build unit || !integration
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CheckpointConfigTestSuite struct {
+	suite.Suite
+}
+
+func TestCheckpointConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(CheckpointConfigTestSuite))
+}
+
+func (suite *CheckpointConfigTestSuite) TestDefaults() {
+	config := &CheckpointConfig{Interval: 600}
+	suite.True(config.IsEnabled())
+	suite.Equal(10*time.Minute, config.GetInterval())
+	suite.Equal(DefaultCheckpointTimeout*time.Second, config.GetTimeout())
+	suite.Equal(DefaultCheckpointSignal, config.GetSignal())
+
+	var disabled *CheckpointConfig
+	suite.False(disabled.IsEnabled())
+	suite.False((&CheckpointConfig{}).IsEnabled())
+}
+
+func (suite *CheckpointConfigTestSuite) TestNormalize() {
+	config := &CheckpointConfig{Interval: 60, Signal: " sigterm "}
+	config.Normalize()
+	suite.Equal("SIGTERM", config.GetSignal())
+}
+
+func (suite *CheckpointConfigTestSuite) TestCopy() {
+	original := &CheckpointConfig{Interval: 60, Timeout: 10, Signal: "SIGUSR2"}
+	copyConfig := original.Copy()
+	suite.Equal(original, copyConfig)
+	suite.NotSame(original, copyConfig)
+}
+
+func (suite *CheckpointConfigTestSuite) TestValidate() {
+	tests := []struct {
+		name      string
+		config    *CheckpointConfig
+		expectErr bool
+	}{
+		{name: "nil", config: nil},
+		{name: "valid", config: &CheckpointConfig{Interval: 60, Timeout: 30}},
+		{name: "negative interval", config: &CheckpointConfig{Interval: -1}, expectErr: true},
+		{name: "negative timeout", config: &CheckpointConfig{Interval: 60, Timeout: -1}, expectErr: true},
+		{name: "timeout above interval", config: &CheckpointConfig{Interval: 60, Timeout: 120}, expectErr: true},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			err := tt.config.Validate()
+			if tt.expectErr {
+				suite.Error(err)
+			} else {
+				suite.NoError(err)
+			}
+		})
+	}
+}
+
+func (suite *CheckpointConfigTestSuite) TestRestoreInputSource() {
+	checkpoint := &Checkpoint{Sequence: 1, Result: &SpecConfig{Type: StorageSourceS3}}
+	input := checkpoint.RestoreInputSource()
+	suite.Equal(CheckpointRestorePath, input.Target)
+	suite.Equal(CheckpointRestoreAlias, input.Alias)
+	suite.Equal(StorageSourceS3, input.Source.Type)
+	suite.NotSame(checkpoint.Result, input.Source)
+}
+
+func (suite *CheckpointConfigTestSuite) TestUpsertHasNewCheckpoint() {
+	previous := &Execution{ID: "e1"}
+	current := &Execution{ID: "e1", Checkpoint: &Checkpoint{Sequence: 1}}
+	suite.True(ExecutionUpsert{Current: current, Previous: previous}.HasNewCheckpoint())
+	suite.False(ExecutionUpsert{Current: current, Previous: current}.HasNewCheckpoint())
+	suite.False(ExecutionUpsert{Current: previous, Previous: previous}.HasNewCheckpoint())
+}
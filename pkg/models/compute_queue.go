@@ -0,0 +1,74 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// ComputeQueueState describes the executions waiting for capacity on a compute node
+type ComputeQueueState struct {
+	// Policy is the queueing policy used to order the enqueued executions
+	Policy string `json:"Policy"`
+	// Executions are the enqueued executions, ordered by their position in the queue
+	Executions []QueuedExecution `json:"Executions,omitempty"`
+	// ReservedExecutionID is the execution that capacity is being reserved for, if any.
+	// No other execution is started if doing so would delay it further.
+	ReservedExecutionID string `json:"ReservedExecutionID,omitempty"`
+	// ReservedCapacity is the capacity reserved for ReservedExecutionID
+	ReservedCapacity *Resources `json:"ReservedCapacity,omitempty"`
+}
+
+// ComputeQueueSummary summarizes the executions waiting for capacity on a compute node. It is shared in the
+// node info of the node, while the full queue state is available from the debug endpoint of the node.
+type ComputeQueueSummary struct {
+	// Policy is the queueing policy used to order the enqueued executions
+	Policy string `json:"Policy"`
+	// Length is the number of enqueued executions
+	Length int `json:"Length"`
+	// ReservedExecutionID is the execution that capacity is being reserved for, if any
+	ReservedExecutionID string `json:"ReservedExecutionID,omitempty"`
+	// ReservedWaitTime is how long the reserved execution has been waiting for capacity
+	ReservedWaitTime time.Duration `json:"ReservedWaitTime,omitempty"`
+}
+
+// QueuedExecution describes an execution waiting for capacity on a compute node
+type QueuedExecution struct {
+	ExecutionID string `json:"ExecutionID"`
+	JobID       string `json:"JobID"`
+	// Position is the 1-based position of the execution in the queue
+	Position int `json:"Position"`
+	// Priority is the priority of the job
+	Priority int `json:"Priority"`
+	// EffectivePriority is the priority of the job after aging it with its waiting time
+	EffectivePriority int64 `json:"EffectivePriority"`
+	// EnqueuedAt is when the execution started waiting for capacity
+	EnqueuedAt time.Time `json:"EnqueuedAt"`
+	// Resources are the resources the execution is waiting for
+	Resources Resources `json:"Resources"`
+	// Reserved is true if capacity is being reserved for the execution
+	Reserved bool `json:"Reserved,omitempty"`
+}
+
+// Copy returns a deep copy of the queue state
+func (s *ComputeQueueState) Copy() *ComputeQueueState {
+	if s == nil {
+		return nil
+	}
+	cpy := new(ComputeQueueState)
+	*cpy = *s
+	cpy.Executions = slices.Clone(s.Executions)
+	for i := range cpy.Executions {
+		cpy.Executions[i].Resources = copyOrZero(s.Executions[i].Resources.Copy())
+	}
+	cpy.ReservedCapacity = s.ReservedCapacity.Copy()
+	return cpy
+}
+
+// Copy returns a copy of the queue summary
+func (s *ComputeQueueSummary) Copy() *ComputeQueueSummary {
+	if s == nil {
+		return nil
+	}
+	cpy := *s
+	return &cpy
+}
@@ -60,13 +60,21 @@ const (
 	StorageSourceInline         = "inline"
 	StorageSourceLocalDirectory = "localDirectory" // Deprecated: use StorageSourceLocal instead
 	StorageSourceLocal          = "local"
+	StorageSourceWebhook        = "webhook"
+	StorageSourceOCI            = "oci"
+	StorageSourceGit            = "git"
+	StorageSourceJob            = "job"
+	StorageSourceBlob           = "blob"
 )
 
 var StoragesNames = []string{
+	StorageSourceBlob,
+	StorageSourceGit,
 	StorageSourceIPFS,
 	StorageSourceInline,
 	StorageSourceLocalDirectory,
 	StorageSourceLocal,
+	StorageSourceOCI,
 	StorageSourceS3,
 	StorageSourceS3PreSigned,
 	StorageSourceURL,
@@ -78,6 +86,8 @@ const (
 	PublisherS3        = "s3"
 	PublisherS3Managed = "s3managed"
 	PublisherLocal     = "local"
+	PublisherWebhook   = "webhook"
+	PublisherOCI       = "oci"
 )
 
 var PublisherNames = []string{
@@ -86,6 +96,8 @@ var PublisherNames = []string{
 	PublisherS3,
 	PublisherS3Managed,
 	PublisherLocal,
+	PublisherWebhook,
+	PublisherOCI,
 }
 
 const (
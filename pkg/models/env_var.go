@@ -2,14 +2,75 @@ package models
 
 import (
 	"fmt"
+	"maps"
+	"regexp"
+	"slices"
 	"strings"
 	"unicode"
 )
 
+const (
+	// EnvVarSecretScheme is the prefix of environment variable values that reference
+	// a secret resolved by the compute node, e.g. "secret:vault/kv/data/app#password"
+	EnvVarSecretScheme = "secret"
+
+	// SecretBackendOrchestrator references a secret held by the orchestrator.
+	// The orchestrator replaces such references with a sealed secret before
+	// handing the execution to a compute node.
+	SecretBackendOrchestrator = "orchestrator"
+
+	// SecretBackendSealed references a secret delivered by the orchestrator
+	// encrypted to the compute node's public key.
+	SecretBackendSealed = "sealed"
+
+	// SecretBackendDelimiter separates the backend from the key in a secret reference
+	SecretBackendDelimiter = "/"
+
+	// RedactedValue replaces secret material in specs, events and logs
+	RedactedValue = "[REDACTED]"
+)
+
+// sealedSecretPattern matches sealed secret payloads embedded in free text
+var sealedSecretPattern = regexp.MustCompile(
+	regexp.QuoteMeta(EnvVarSecretScheme+":"+SecretBackendSealed+SecretBackendDelimiter) + `[A-Za-z0-9_-]+`)
+
 // EnvVarValue represents an environment variable value that can be
 // either a literal value or a reference using prefix syntax (e.g., "env:VAR_NAME")
 type EnvVarValue string
 
+// NewSecretRef returns a value referencing the secret key in the given backend
+func NewSecretRef(backend, key string) EnvVarValue {
+	return EnvVarValue(EnvVarSecretScheme + ":" + backend + SecretBackendDelimiter + key)
+}
+
+// SecretRef returns the backend and key of a secret reference.
+// ok is false if the value is not a secret reference.
+func (v EnvVarValue) SecretRef() (backend string, key string, ok bool) {
+	ref, found := strings.CutPrefix(string(v), EnvVarSecretScheme+":")
+	if !found {
+		return "", "", false
+	}
+	backend, key, _ = strings.Cut(ref, SecretBackendDelimiter)
+	return backend, key, true
+}
+
+// IsSecret returns true if the value references a secret
+func (v EnvVarValue) IsSecret() bool {
+	_, _, ok := v.SecretRef()
+	return ok
+}
+
+// Redacted returns the value with any secret material removed.
+// References to secrets held elsewhere are kept as they are,
+// while the payload of sealed secrets is replaced with RedactedValue.
+func (v EnvVarValue) Redacted() EnvVarValue {
+	backend, _, ok := v.SecretRef()
+	if ok && backend == SecretBackendSealed {
+		return NewSecretRef(backend, RedactedValue)
+	}
+	return v
+}
+
 // Validate checks if the environment variable value has basic syntax
 func (v EnvVarValue) Validate(name string) error {
 	// Only validate that it's not empty
@@ -60,6 +121,94 @@ func ValidateEnvVars(env map[string]EnvVarValue) error {
 	return nil
 }
 
+// RedactEnvVars returns a copy of the environment variables with secret material redacted
+func RedactEnvVars(env map[string]EnvVarValue) map[string]EnvVarValue {
+	if env == nil {
+		return nil
+	}
+	result := make(map[string]EnvVarValue, len(env))
+	for k, v := range env {
+		result[k] = v.Redacted()
+	}
+	return result
+}
+
+// RedactSecrets removes sealed secret payloads from free text, such as event messages
+func RedactSecrets(s string) string {
+	return sealedSecretPattern.ReplaceAllString(
+		s, EnvVarSecretScheme+":"+SecretBackendSealed+SecretBackendDelimiter+RedactedValue)
+}
+
+// SecretRefReplacer returns the value replacing a secret reference found at location
+type SecretRefReplacer func(location string, value EnvVarValue) (EnvVarValue, error)
+
+// replaceSecretRefs replaces the secret references in the string values of value, which may be nested
+// in maps and slices as decoded from spec params. Maps and slices are copied when a reference they hold
+// is replaced, so that they can be shared with copies of the spec. changed is true if value was replaced.
+func replaceSecretRefs(value any, location string, replace SecretRefReplacer) (result any, changed bool, err error) {
+	switch v := value.(type) {
+	case string:
+		if !EnvVarValue(v).IsSecret() {
+			return v, false, nil
+		}
+		replaced, err := replace(location, EnvVarValue(v))
+		if err != nil {
+			return nil, false, err
+		}
+		return string(replaced), string(replaced) != v, nil
+	case map[string]any:
+		return replaceSecretRefsInMap(v, location, replace)
+	case map[string]string:
+		return replaceSecretRefsInMap(v, location, replace)
+	case []any:
+		return replaceSecretRefsInSlice(v, location, replace)
+	case []string:
+		return replaceSecretRefsInSlice(v, location, replace)
+	default:
+		return value, false, nil
+	}
+}
+
+func replaceSecretRefsInMap[V any](m map[string]V, location string, replace SecretRefReplacer) (any, bool, error) {
+	var replaced map[string]V
+	for key, item := range m {
+		result, changed, err := replaceSecretRefs(item, location+"."+key, replace)
+		if err != nil {
+			return nil, false, err
+		}
+		if changed {
+			if replaced == nil {
+				replaced = maps.Clone(m)
+			}
+			replaced[key] = result.(V)
+		}
+	}
+	if replaced == nil {
+		return m, false, nil
+	}
+	return replaced, true, nil
+}
+
+func replaceSecretRefsInSlice[V any](s []V, location string, replace SecretRefReplacer) (any, bool, error) {
+	var replaced []V
+	for i, item := range s {
+		result, changed, err := replaceSecretRefs(item, fmt.Sprintf("%s[%d]", location, i), replace)
+		if err != nil {
+			return nil, false, err
+		}
+		if changed {
+			if replaced == nil {
+				replaced = slices.Clone(s)
+			}
+			replaced[i] = result.(V)
+		}
+	}
+	if replaced == nil {
+		return s, false, nil
+	}
+	return replaced, true, nil
+}
+
 // EnvVarsToStringMap converts a map of environment variables to a map of strings.
 // This is useful when interfacing with APIs that expect traditional string-based environment variables.
 func EnvVarsToStringMap(env map[string]EnvVarValue) map[string]string {
@@ -182,3 +182,64 @@ func (s *EnvVarValueSuite) TestEnvVarsToStringMap() {
 		})
 	}
 }
+
+func (s *EnvVarValueSuite) TestSecretRef() {
+	tests := []struct {
+		name        string
+		value       EnvVarValue
+		wantBackend string
+		wantKey     string
+		wantOK      bool
+	}{
+		{
+			name:        "vault reference",
+			value:       "secret:vault/kv/data/app#password",
+			wantBackend: "vault",
+			wantKey:     "kv/data/app#password",
+			wantOK:      true,
+		},
+		{
+			name:        "missing key",
+			value:       "secret:file",
+			wantBackend: "file",
+			wantOK:      true,
+		},
+		{
+			name:  "env reference",
+			value: "env:TEST_VAR",
+		},
+		{
+			name:  "literal value",
+			value: "literal",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			backend, key, ok := tt.value.SecretRef()
+			s.Equal(tt.wantOK, ok)
+			s.Equal(tt.wantOK, tt.value.IsSecret())
+			s.Equal(tt.wantBackend, backend)
+			s.Equal(tt.wantKey, key)
+		})
+	}
+}
+
+func (s *EnvVarValueSuite) TestRedaction() {
+	env := map[string]EnvVarValue{
+		"SEALED":  NewSecretRef(SecretBackendSealed, "c2VhbGVk-payload_1"),
+		"VAULT":   "secret:vault/kv/data/app#password",
+		"LITERAL": "literal-value",
+	}
+
+	redacted := RedactEnvVars(env)
+	s.Equal(EnvVarValue("secret:sealed/[REDACTED]"), redacted["SEALED"])
+	s.Equal(env["VAULT"], redacted["VAULT"])
+	s.Equal(env["LITERAL"], redacted["LITERAL"])
+
+	// the original map is left untouched
+	s.Equal(EnvVarValue("secret:sealed/c2VhbGVk-payload_1"), env["SEALED"])
+
+	s.Equal("failed to resolve secret:sealed/[REDACTED]: bad key",
+		RedactSecrets("failed to resolve secret:sealed/c2VhbGVk-payload_1: bad key"))
+}
@@ -0,0 +1,31 @@
+package models
+
+type ExecOutputType int
+
+const (
+	execOutputTypeUnknown ExecOutputType = iota
+	// ExecOutputTypeStarted is sent once the session is established and ready to receive input
+	ExecOutputTypeStarted
+	ExecOutputTypeSTDOUT
+	ExecOutputTypeSTDERR
+	// ExecOutputTypeExit is the last frame of a session and carries the exit code of the command
+	ExecOutputTypeExit
+)
+
+// ExecOutput is a frame of output of an interactive session into a running execution
+type ExecOutput struct {
+	Type     ExecOutputType
+	Data     []byte `json:",omitempty"`
+	ExitCode int    `json:",omitempty"`
+}
+
+// ExecInput is a frame of input sent to an interactive session into a running execution
+type ExecInput struct {
+	// Data is written to the standard input of the command
+	Data []byte `json:",omitempty"`
+	// CloseStdin closes the standard input of the command after Data is written
+	CloseStdin bool `json:",omitempty"`
+	// Height and Width resize the terminal of the session when both are set
+	Height uint `json:",omitempty"`
+	Width  uint `json:",omitempty"`
+}
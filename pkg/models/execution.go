@@ -5,6 +5,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/lib/validate"
@@ -122,6 +123,13 @@ type Execution struct {
 	// PreviousExecution is the execution that this execution is replacing
 	PreviousExecution string `json:"PreviousExecution"`
 
+	// RestoreFrom is the last checkpoint of the previous execution, mounted into this
+	// execution so that it can resume where the previous execution left off
+	RestoreFrom *InputSource `json:"RestoreFrom,omitempty"`
+
+	// Checkpoint is the last checkpoint published by this execution while it was running
+	Checkpoint *Checkpoint `json:"Checkpoint,omitempty"`
+
 	// NextExecution is the execution that this execution is being replaced by
 	NextExecution string `json:"NextExecution"`
 
@@ -202,9 +210,21 @@ func (e *Execution) Copy() *Execution {
 	na.AllocatedResources = na.AllocatedResources.Copy()
 	na.PublishedResult = na.PublishedResult.Copy()
 	na.RunOutput = na.RunOutput.Copy()
+	na.RestoreFrom = na.RestoreFrom.Copy()
+	na.Checkpoint = na.Checkpoint.Copy()
 	return na
 }
 
+// Redacted returns a copy of the execution with secret material removed from its job
+func (e *Execution) Redacted() *Execution {
+	if e == nil {
+		return nil
+	}
+	ne := e.Copy()
+	ne.Job = e.Job.Redacted()
+	return ne
+}
+
 // Validate is used to check a job for reasonable configuration
 func (e *Execution) Validate() error {
 	err := errors.Join(
@@ -235,6 +255,18 @@ func (e *Execution) IsTerminalDesiredState() bool {
 	return e.DesiredState.StateType == ExecutionDesiredStateStopped
 }
 
+// ResultOrigin identifies the execution that published a result
+type ResultOrigin struct {
+	JobID       string `json:"JobID"`
+	ExecutionID string `json:"ExecutionID"`
+	NodeID      string `json:"NodeID"`
+}
+
+// ResultOrigin returns the origin of the results published by the execution
+func (e *Execution) ResultOrigin() ResultOrigin {
+	return ResultOrigin{JobID: e.JobID, ExecutionID: e.ID, NodeID: e.NodeID}
+}
+
 // IsTerminalComputeState returns true if the execution observed state is terminal
 func (e *Execution) IsTerminalComputeState() bool {
 	switch e.ComputeState.StateType {
@@ -245,6 +277,20 @@ func (e *Execution) IsTerminalComputeState() bool {
 	}
 }
 
+// AcceptsTerminalUpdate returns true if the terminal execution can be updated with values.
+// The only update allowed is recording the published result and run output of a cancelled
+// execution, which it publishes while it terminates gracefully.
+func (e *Execution) AcceptsTerminalUpdate(values Execution) bool {
+	if e.ComputeState.StateType != ExecutionStateCancelled || values.ComputeState.StateType != ExecutionStateCancelled {
+		return false
+	}
+	return reflect.DeepEqual(values, Execution{
+		ComputeState:    values.ComputeState,
+		PublishedResult: values.PublishedResult,
+		RunOutput:       values.RunOutput,
+	})
+}
+
 // IsDiscarded returns true if the execution has failed, been cancelled or rejected.
 func (e *Execution) IsDiscarded() bool {
 	switch e.ComputeState.StateType {
@@ -306,6 +352,21 @@ type RunCommandResult struct {
 
 	// Runner error
 	ErrorMsg string `json:"ErrorMsg"`
+
+	// fuel consumed by the run, for engines that meter it.
+	FuelConsumed uint64 `json:"FuelConsumed,omitempty"`
+
+	// digest of the image the run used, for engines that pin images to a digest.
+	ImageDigest string `json:"ImageDigest,omitempty"`
+
+	// size in bytes of the published results, after the output policy of the task was applied.
+	ResultsSize int64 `json:"ResultsSize,omitempty"`
+
+	// number of result files that were not published as they did not match the output policy of the task.
+	ResultsExcluded int `json:"ResultsExcluded,omitempty"`
+
+	// bool describing if results were truncated or dropped as they exceeded the max size of the output policy.
+	ResultsTruncated bool `json:"ResultsTruncated,omitempty"`
 }
 
 func NewRunCommandResult() *RunCommandResult {
@@ -326,7 +387,7 @@ func (r *RunCommandResult) Copy() *RunCommandResult {
 	newRCR := new(RunCommandResult)
 	*newRCR = *r
 
-	// Since all fields are simple types (string, bool, int),
+	// Since all fields are simple types (string, bool, int, uint64),
 	// a shallow copy is sufficient.
 	return newRCR
 }
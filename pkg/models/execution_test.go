@@ -213,3 +213,24 @@ func (s *ExecutionTestSuite) TestAllocatePorts() {
 		})
 	}
 }
+
+func (s *ExecutionTestSuite) TestAcceptsTerminalUpdate() {
+	cancelled := &Execution{ComputeState: NewExecutionState(ExecutionStateCancelled)}
+	completed := &Execution{ComputeState: NewExecutionState(ExecutionStateCompleted)}
+	results := Execution{
+		ComputeState:    NewExecutionState(ExecutionStateCancelled),
+		PublishedResult: &SpecConfig{Type: "myResult"},
+		RunOutput:       &RunCommandResult{ExitCode: 143},
+	}
+
+	s.True(cancelled.AcceptsTerminalUpdate(results))
+	s.False(completed.AcceptsTerminalUpdate(results), "only cancelled executions record results after terminating")
+
+	failed := results
+	failed.ComputeState = NewExecutionState(ExecutionStateFailed)
+	s.False(cancelled.AcceptsTerminalUpdate(failed), "the state of terminal executions cannot change")
+
+	rescheduled := results
+	rescheduled.DesiredState = NewExecutionDesiredState(ExecutionDesiredStateRunning)
+	s.False(cancelled.AcceptsTerminalUpdate(rescheduled), "only the results can be recorded")
+}
@@ -20,3 +20,20 @@ func (u ExecutionUpsert) HasStateChange() bool {
 	return u.Previous.DesiredState.StateType != u.Current.DesiredState.StateType ||
 		u.Previous.ComputeState.StateType != u.Current.ComputeState.StateType
 }
+
+// HasNewCheckpoint returns true if the execution published a checkpoint in this change
+func (u ExecutionUpsert) HasNewCheckpoint() bool {
+	if u.Current == nil || u.Current.Checkpoint == nil {
+		return false
+	}
+	return u.Previous == nil || u.Previous.Checkpoint == nil ||
+		u.Previous.Checkpoint.Sequence != u.Current.Checkpoint.Sequence
+}
+
+// HasNewPublishedResult returns true if the execution recorded a published result in this change
+func (u ExecutionUpsert) HasNewPublishedResult() bool {
+	if u.Current == nil || u.Current.PublishedResult == nil {
+		return false
+	}
+	return u.Previous == nil || u.Previous.PublishedResult == nil
+}
@@ -20,6 +20,35 @@ type InputSource struct {
 
 	// Target is the path where the artifact should be mounted on
 	Target string `json:"Target"`
+
+	// Overlay mounts the input writable with a private copy-on-write layer per execution,
+	// so that the execution can modify the input without changing its source. Optional.
+	Overlay *InputOverlay `json:"Overlay,omitempty"`
+}
+
+// InputOverlay configures an input that is mounted with a private writable layer over its read-only data
+type InputOverlay struct {
+	// Publish is the name of the result directory where the files written by the execution to the
+	// input are published. Optional. The writable layer is discarded when the execution ends if not set.
+	Publish string `json:"Publish,omitempty"`
+}
+
+// Copy returns a deep copy of the overlay
+func (o *InputOverlay) Copy() *InputOverlay {
+	if o == nil {
+		return nil
+	}
+	cpy := *o
+	return &cpy
+}
+
+// Validate validates the overlay
+func (o *InputOverlay) Validate() error {
+	if o == nil || o.Publish == "" {
+		return nil
+	}
+	return validate.True(o.Publish != "." && o.Publish != ".." && !strings.ContainsAny(o.Publish, `/\`),
+		"overlay publish name `%s` must be a single path component", o.Publish)
 }
 
 func (a *InputSource) MarshalZerologObject(e *zerolog.Event) {
@@ -37,6 +66,9 @@ func (a *InputSource) Normalize() {
 	a.Source.Normalize()
 	a.Alias = strings.TrimSpace(a.Alias)
 	a.Target = strings.TrimSpace(a.Target)
+	if a.Overlay != nil {
+		a.Overlay.Publish = strings.TrimSpace(a.Overlay.Publish)
+	}
 }
 
 // Copy returns a deep copy of the artifact
@@ -45,9 +77,10 @@ func (a *InputSource) Copy() *InputSource {
 		return nil
 	}
 	return &InputSource{
-		Source: a.Source.Copy(),
-		Alias:  a.Alias,
-		Target: a.Target,
+		Source:  a.Source.Copy(),
+		Alias:   a.Alias,
+		Target:  a.Target,
+		Overlay: a.Overlay.Copy(),
 	}
 }
 
@@ -62,5 +95,8 @@ func (a *InputSource) Validate() error {
 	if err := a.Source.Validate(); err != nil {
 		mErr = errors.Join(mErr, fmt.Errorf("invalid input source: %w", err))
 	}
+	if err := a.Overlay.Validate(); err != nil {
+		mErr = errors.Join(mErr, fmt.Errorf("invalid overlay: %w", err))
+	}
 	return mErr
 }
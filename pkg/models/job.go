@@ -234,6 +234,22 @@ func (j *Job) Copy() *Job {
 	return nj
 }
 
+// Redacted returns a copy of the job with secret material removed from its tasks.
+// It should be used whenever a job is returned to users or written to logs.
+func (j *Job) Redacted() *Job {
+	if j == nil {
+		return nil
+	}
+	nj := j.Copy()
+	for _, task := range nj.Tasks {
+		// redacting values never fails
+		_ = task.ReplaceSecretRefs(func(_ string, value EnvVarValue) (EnvVarValue, error) {
+			return value.Redacted(), nil
+		})
+	}
+	return nj
+}
+
 // Validate is used to check a job for reasonable configuration
 func (j *Job) Validate() error {
 	errs := errors.Join(
@@ -315,6 +331,10 @@ func (j *Job) ValidateSubmission() error {
 			outer := fmt.Errorf("task %s validation failed: %v", task.Name, err)
 			mErr = errors.Join(mErr, outer)
 		}
+		if len(task.Volumes) > 0 && !j.IsLongRunning() {
+			mErr = errors.Join(mErr, fmt.Errorf(
+				"task %s cannot have persistent volumes as they are only supported by service and daemon jobs", task.Name))
+		}
 	}
 
 	return mErr
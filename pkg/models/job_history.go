@@ -56,6 +56,19 @@ type JobHistory struct {
 	ExecutionState *StateChange[ExecutionStateType] `json:"ExecutionState,omitempty"`
 }
 
+// Redacted returns a copy of the history entry with sealed secrets removed from its event
+func (jh JobHistory) Redacted() JobHistory {
+	jh.Event.Message = RedactSecrets(jh.Event.Message)
+	if jh.Event.Details != nil {
+		details := make(map[string]string, len(jh.Event.Details))
+		for k, v := range jh.Event.Details {
+			details[k] = RedactSecrets(v)
+		}
+		jh.Event.Details = details
+	}
+	return jh
+}
+
 // Occurred returns when the action that triggered an update to job history
 // actually occurred.
 //
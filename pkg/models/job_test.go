@@ -231,6 +231,65 @@ func (suite *JobTestSuite) TestJobTask() {
 	suite.Equal(job.Tasks[0], job.Task())
 }
 
+func (suite *JobTestSuite) TestPersistentVolumesValidation() {
+	testCases := []struct {
+		name     string
+		jobType  string
+		volumes  []*models.PersistentVolume
+		errorMsg string
+	}{
+		{
+			name:    "service job with volumes",
+			jobType: models.JobTypeService,
+			volumes: []*models.PersistentVolume{{Name: "data", Target: "/data", MaxSize: "1GB"}},
+		},
+		{
+			name:     "batch job with volumes",
+			jobType:  models.JobTypeBatch,
+			volumes:  []*models.PersistentVolume{{Name: "data", Target: "/data"}},
+			errorMsg: "only supported by service and daemon jobs",
+		},
+		{
+			name:    "duplicate volume names",
+			jobType: models.JobTypeDaemon,
+			volumes: []*models.PersistentVolume{
+				{Name: "data", Target: "/data"},
+				{Name: "data", Target: "/other"},
+			},
+			errorMsg: "volume with name 'data' already exists",
+		},
+		{
+			name:    "duplicate volume targets",
+			jobType: models.JobTypeService,
+			volumes: []*models.PersistentVolume{
+				{Name: "data", Target: "/data"},
+				{Name: "cache", Target: "/data"},
+			},
+			errorMsg: "volume target '/data' is already used",
+		},
+		{
+			name:     "relative target",
+			jobType:  models.JobTypeService,
+			volumes:  []*models.PersistentVolume{{Name: "data", Target: "data"}},
+			errorMsg: "absolute",
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			job := mock.Job()
+			job.Type = tc.jobType
+			job.Task().Volumes = tc.volumes
+			err := job.ValidateSubmission()
+			if tc.errorMsg == "" {
+				suite.NoError(err)
+			} else {
+				suite.ErrorContains(err, tc.errorMsg)
+			}
+		})
+	}
+}
+
 func (suite *JobTestSuite) TestIsTerminal() {
 	job := &models.Job{
 		State: models.State[models.JobStateType]{StateType: models.JobStateTypeCompleted},
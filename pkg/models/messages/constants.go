@@ -8,6 +8,7 @@ const (
 
 	BidResultMessageType    = "BidResult"
 	RunResultMessageType    = "RunResult"
+	CheckpointMessageType   = "Checkpoint"
 	ComputeErrorMessageType = "ComputeError"
 
 	HandshakeRequestMessageType      = "transport.HandshakeRequest"
@@ -22,4 +23,6 @@ const (
 
 	ManagedPublisherPreSignURLRequestType  = "managedPublisher.PreSignURLRequest"
 	ManagedPublisherPreSignURLResponseType = "managedPublisher.PreSignURLResponse"
+	ManagedPublisherMultipartRequestType   = "managedPublisher.MultipartRequest"
+	ManagedPublisherMultipartResponseType  = "managedPublisher.MultipartResponse"
 )
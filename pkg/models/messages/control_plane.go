@@ -30,6 +30,10 @@ type HeartbeatRequest struct {
 
 type HeartbeatResponse struct {
 	LastComputeSeqNum uint64 `json:"LastComputeSeqNum"` // Last seq received from compute node
+	// ImageHints are the images of recently popular jobs, which compute nodes can pull ahead of time
+	ImageHints []string `json:"ImageHints,omitempty"`
+	// DeleteVolumes are the persistent volumes the compute node should delete, such as the volumes of stopped jobs
+	DeleteVolumes []models.VolumeRef `json:"DeleteVolumes,omitempty"`
 }
 
 // UpdateNodeInfoRequest is used to update the node info
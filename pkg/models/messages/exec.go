@@ -0,0 +1,17 @@
+package messages
+
+// ExecRequest asks a compute node to open an interactive session into a running execution
+type ExecRequest struct {
+	// SessionID identifies the session, and the subject its input is delivered on
+	SessionID   string
+	ExecutionID string
+	NodeID      string
+	// Command to run inside the execution. Ignored when Attach is set.
+	Command []string
+	// Attach connects to the main process of the execution instead of running a command
+	Attach bool
+	TTY    bool
+	Stdin  bool
+	Height uint
+	Width  uint
+}
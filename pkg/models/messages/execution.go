@@ -13,6 +13,15 @@ type RunResult struct {
 	BaseResponse
 	PublishResult    *models.SpecConfig
 	RunCommandResult *models.RunCommandResult
+	// Cancelled is true if the execution was cancelled, and the results are the ones
+	// it wrote while terminating gracefully
+	Cancelled bool
+}
+
+// CheckpointResult is sent by the compute node when a running execution has published a checkpoint
+type CheckpointResult struct {
+	BaseResponse
+	Checkpoint *models.Checkpoint
 }
 
 type ComputeError struct {
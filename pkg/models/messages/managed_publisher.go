@@ -12,3 +12,46 @@ type ManagedPublisherPreSignURLResponse struct {
 	JobID        string
 	PreSignedURL string
 }
+
+// ManagedPublisherMultipartOperation is an operation of a multipart upload to the managed S3 bucket
+type ManagedPublisherMultipartOperation string
+
+const (
+	// ManagedPublisherMultipartCreate starts a multipart upload
+	ManagedPublisherMultipartCreate ManagedPublisherMultipartOperation = "create"
+	// ManagedPublisherMultipartPresignPart pre-signs the URL to upload a part
+	ManagedPublisherMultipartPresignPart ManagedPublisherMultipartOperation = "presignPart"
+	// ManagedPublisherMultipartComplete completes a multipart upload from its uploaded parts
+	ManagedPublisherMultipartComplete ManagedPublisherMultipartOperation = "complete"
+	// ManagedPublisherMultipartAbort aborts a multipart upload and deletes its uploaded parts
+	ManagedPublisherMultipartAbort ManagedPublisherMultipartOperation = "abort"
+)
+
+// ManagedPublisherUploadedPart is an uploaded part of a multipart upload
+type ManagedPublisherUploadedPart struct {
+	PartNumber int32
+	ETag       string
+}
+
+// ManagedPublisherMultipartRequest requests an operation of a multipart upload of the results
+// of an execution to the managed S3 bucket. Compute nodes upload the parts to pre-signed URLs.
+type ManagedPublisherMultipartRequest struct {
+	BaseRequest
+	ExecutionID string
+	JobID       string
+	Operation   ManagedPublisherMultipartOperation
+	// UploadID identifies the multipart upload of all operations but create
+	UploadID string
+	// PartNumber is the number of the part to pre-sign, starting at 1
+	PartNumber int32
+	// Parts are the uploaded parts of the upload to complete
+	Parts []ManagedPublisherUploadedPart
+}
+
+type ManagedPublisherMultipartResponse struct {
+	BaseResponse
+	ExecutionID  string
+	JobID        string
+	UploadID     string
+	PreSignedURL string
+}
@@ -136,6 +136,7 @@ func (n NodeInfo) HasStaticConfigChanged(other NodeInfo) bool {
 			"AvailableCapacity",
 			"RunningExecutions",
 			"EnqueuedExecutions",
+			"Queue",
 		),
 		// Ignore ordering in slices
 		cmpopts.SortSlices(func(a, b string) bool { return a < b }),
@@ -161,6 +162,18 @@ type ComputeNodeInfo struct {
 	// Address is the network location where this compute node can be reached
 	// Format: IPv4 or hostname (e.g., "192.168.1.100" or "node1.example.com")
 	Address string `json:"address"`
+	// PublicKey is the base64 encoded PKIX public key of the node.
+	// The orchestrator uses it to seal secrets delivered to executions on this node.
+	PublicKey string `json:"PublicKey,omitempty"`
+	// Queue summarizes the executions waiting for capacity on the node, including the length of the
+	// queue and the long-waiting execution capacity is reserved for, if any.
+	Queue *ComputeQueueSummary `json:"Queue,omitempty"`
+	// CachedImages are the digested references of the container images cached on the node,
+	// which are used to prefer nodes that already have the image of a job.
+	CachedImages []string `json:"CachedImages,omitempty"`
+	// Volumes are the persistent volumes of jobs held by the node,
+	// which are used to prefer nodes that already hold the volumes of a job.
+	Volumes []VolumeInfo `json:"Volumes,omitempty"`
 }
 
 // Copy provides a copy of the allocation and deep copies the job
@@ -180,5 +193,8 @@ func (c *ComputeNodeInfo) Copy() *ComputeNodeInfo {
 	cpy.AvailableCapacity = copyOrZero(c.AvailableCapacity.Copy())
 	cpy.MaxJobRequirements = copyOrZero(c.MaxJobRequirements.Copy())
 	cpy.Address = c.Address
+	cpy.Queue = c.Queue.Copy()
+	cpy.CachedImages = slices.Clone(c.CachedImages)
+	cpy.Volumes = slices.Clone(c.Volumes)
 	return cpy
 }
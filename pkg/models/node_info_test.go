@@ -221,6 +221,15 @@ func (s *NodeInfoTestSuite) TestHasNodeInfoChanged() {
 			},
 			expectChanged: false,
 		},
+		{
+			name: "changed queue summary only",
+			changeFunction: func(info *NodeInfo) *NodeInfo {
+				info = info.Copy()
+				info.ComputeNodeInfo.Queue = &ComputeQueueSummary{Policy: "aging", Length: 3, ReservedExecutionID: "e1"}
+				return info
+			},
+			expectChanged: false,
+		},
 		{
 			name: "multiple dynamic field changes only",
 			changeFunction: func(info *NodeInfo) *NodeInfo {
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/dustin/go-humanize"
+)
+
+// OutputLimitAction is what the compute node does when the results of a task exceed its maximum size
+type OutputLimitAction string
+
+const (
+	// OutputLimitFail fails the execution without publishing its results
+	OutputLimitFail OutputLimitAction = "fail"
+	// OutputLimitTruncate publishes results up to the maximum size, truncating the file that
+	// crosses the limit and dropping the files after it
+	OutputLimitTruncate OutputLimitAction = "truncate"
+	// OutputLimitPublishPartial publishes the whole files that fit within the maximum size,
+	// and drops the others
+	OutputLimitPublishPartial OutputLimitAction = "publish-partial"
+)
+
+// OutputLimitActions are the supported actions when results exceed their maximum size
+var OutputLimitActions = []OutputLimitAction{OutputLimitFail, OutputLimitTruncate, OutputLimitPublishPartial}
+
+// OutputPolicy filters and limits the results of a task before they are published.
+// Globs match paths relative to the root of the results, such as "outputs/**/*.csv". They never remove
+// the stdout, stderr and exitCode files of the results.
+// Files are considered in lexical order of their paths when applying the maximum size.
+type OutputPolicy struct {
+	// MaxSize is the maximum total size of the published results, such as "10GB". Unlimited if empty.
+	MaxSize string `json:"MaxSize,omitempty"`
+	// Include are the globs of the result files to publish. All files are published if empty.
+	Include []string `json:"Include,omitempty"`
+	// Exclude are the globs of the result files not to publish, even if they are included.
+	Exclude []string `json:"Exclude,omitempty"`
+	// OnExceeded is what to do when the results exceed MaxSize. Defaults to OutputLimitFail.
+	OnExceeded OutputLimitAction `json:"OnExceeded,omitempty"`
+}
+
+// IsEnabled returns true if the policy filters or limits the results
+func (p *OutputPolicy) IsEnabled() bool {
+	return p != nil && (p.MaxSize != "" || len(p.Include) > 0 || len(p.Exclude) > 0)
+}
+
+// GetMaxSize returns the maximum size of the results in bytes, or 0 if unlimited
+func (p *OutputPolicy) GetMaxSize() (uint64, error) {
+	if p == nil || p.MaxSize == "" {
+		return 0, nil
+	}
+	return humanize.ParseBytes(p.MaxSize)
+}
+
+// GetOnExceeded returns what to do when the results exceed their maximum size
+func (p *OutputPolicy) GetOnExceeded() OutputLimitAction {
+	if p.OnExceeded != "" {
+		return p.OnExceeded
+	}
+	return OutputLimitFail
+}
+
+// Matches returns true if the result file at path is published by the policy's globs
+func (p *OutputPolicy) Matches(path string) bool {
+	if p == nil {
+		return true
+	}
+	if len(p.Include) > 0 && !slices.ContainsFunc(p.Include, func(pattern string) bool {
+		return doublestar.MatchUnvalidated(pattern, path)
+	}) {
+		return false
+	}
+	return !slices.ContainsFunc(p.Exclude, func(pattern string) bool {
+		return doublestar.MatchUnvalidated(pattern, path)
+	})
+}
+
+// Normalize normalizes the output policy
+func (p *OutputPolicy) Normalize() {
+	if p == nil {
+		return
+	}
+	p.MaxSize = strings.TrimSpace(p.MaxSize)
+	p.OnExceeded = OutputLimitAction(strings.ToLower(strings.TrimSpace(string(p.OnExceeded))))
+}
+
+// Copy returns a deep copy of the output policy.
+func (p *OutputPolicy) Copy() *OutputPolicy {
+	if p == nil {
+		return nil
+	}
+	return &OutputPolicy{
+		MaxSize:    p.MaxSize,
+		Include:    slices.Clone(p.Include),
+		Exclude:    slices.Clone(p.Exclude),
+		OnExceeded: p.OnExceeded,
+	}
+}
+
+// Validate is used to check an output policy for reasonable configuration.
+func (p *OutputPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	var mErr error
+	if _, err := p.GetMaxSize(); err != nil {
+		mErr = errors.Join(mErr, fmt.Errorf("invalid max size %q: %w", p.MaxSize, err))
+	}
+	for _, pattern := range slices.Concat(p.Include, p.Exclude) {
+		if !doublestar.ValidatePattern(pattern) {
+			mErr = errors.Join(mErr, fmt.Errorf("invalid glob %q", pattern))
+		}
+	}
+	if p.OnExceeded != "" && !slices.Contains(OutputLimitActions, p.OnExceeded) {
+		mErr = errors.Join(mErr, fmt.Errorf("invalid action %q when the max size is exceeded. Must be one of %v",
+			p.OnExceeded, OutputLimitActions))
+	}
+	return mErr
+}
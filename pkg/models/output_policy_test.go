@@ -0,0 +1,78 @@
+//go:build unit || !integration
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OutputPolicyTestSuite struct {
+	suite.Suite
+}
+
+func TestOutputPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(OutputPolicyTestSuite))
+}
+
+func (suite *OutputPolicyTestSuite) TestDefaults() {
+	var disabled *OutputPolicy
+	suite.False(disabled.IsEnabled())
+	suite.False((&OutputPolicy{OnExceeded: OutputLimitTruncate}).IsEnabled())
+	suite.True((&OutputPolicy{MaxSize: "1GB"}).IsEnabled())
+	suite.True((&OutputPolicy{Exclude: []string{"*.tmp"}}).IsEnabled())
+
+	policy := &OutputPolicy{MaxSize: "10MiB"}
+	suite.Equal(OutputLimitFail, policy.GetOnExceeded())
+	maxSize, err := policy.GetMaxSize()
+	suite.Require().NoError(err)
+	suite.Equal(uint64(10*1024*1024), maxSize)
+}
+
+func (suite *OutputPolicyTestSuite) TestMatches() {
+	policy := &OutputPolicy{
+		Include: []string{"stdout", "outputs/**/*.csv"},
+		Exclude: []string{"outputs/tmp/**"},
+	}
+	suite.True(policy.Matches("stdout"))
+	suite.True(policy.Matches("outputs/data.csv"))
+	suite.True(policy.Matches("outputs/a/b/data.csv"))
+	suite.False(policy.Matches("stderr"))
+	suite.False(policy.Matches("outputs/model.bin"))
+	suite.False(policy.Matches("outputs/tmp/data.csv"))
+
+	excludeOnly := &OutputPolicy{Exclude: []string{"**/*.log"}}
+	suite.True(excludeOnly.Matches("outputs/data.csv"))
+	suite.False(excludeOnly.Matches("outputs/run.log"))
+
+	var unset *OutputPolicy
+	suite.True(unset.Matches("anything"))
+}
+
+func (suite *OutputPolicyTestSuite) TestNormalizeAndCopy() {
+	policy := &OutputPolicy{MaxSize: " 1GB ", Include: []string{"outputs/**"}, OnExceeded: " Truncate "}
+	policy.Normalize()
+	suite.Equal("1GB", policy.MaxSize)
+	suite.Equal(OutputLimitTruncate, policy.OnExceeded)
+
+	copyPolicy := policy.Copy()
+	suite.Equal(policy, copyPolicy)
+	copyPolicy.Include[0] = "changed"
+	suite.Equal("outputs/**", policy.Include[0])
+}
+
+func (suite *OutputPolicyTestSuite) TestValidate() {
+	suite.NoError((*OutputPolicy)(nil).Validate())
+	suite.NoError((&OutputPolicy{
+		MaxSize:    "500MB",
+		Include:    []string{"outputs/**"},
+		Exclude:    []string{"**/*.tmp"},
+		OnExceeded: OutputLimitPublishPartial,
+	}).Validate())
+
+	suite.Error((&OutputPolicy{MaxSize: "lots"}).Validate())
+	suite.Error((&OutputPolicy{Include: []string{"outputs/[a"}}).Validate())
+	suite.Error((&OutputPolicy{Exclude: []string{"outputs/{a"}}).Validate())
+	suite.Error((&OutputPolicy{MaxSize: "1GB", OnExceeded: "ignore"}).Validate())
+}
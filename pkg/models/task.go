@@ -3,6 +3,9 @@ package models
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/exp/maps"
@@ -10,6 +13,14 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/lib/validate"
 )
 
+const (
+	// DefaultStopSignal is the signal sent to the task to ask it to terminate gracefully
+	DefaultStopSignal = "SIGTERM"
+)
+
+// StopSignals are the signals that can be sent to a task to ask it to terminate gracefully
+var StopSignals = []string{"SIGTERM", "SIGINT", "SIGQUIT", "SIGHUP", "SIGUSR1", "SIGUSR2", "SIGKILL"}
+
 type Task struct {
 	// Name of the task
 	Name string `json:"Name"`
@@ -34,12 +45,46 @@ type Task struct {
 	// ResultPaths is a list of task volumes to be included in the task's published result
 	ResultPaths []*ResultPath `json:"ResultPaths,omitempty"`
 
+	// Volumes is a list of node-local persistent volumes mounted into the task, whose data
+	// persists across the executions of long running jobs on the same node
+	Volumes []*PersistentVolume `json:"Volumes,omitempty"`
+
 	// ResourcesConfig is the resources needed by this task
 	ResourcesConfig *ResourcesConfig `json:"Resources,omitempty"`
 
 	Network *NetworkConfig `json:"Network,omitempty"`
 
 	Timeouts *TimeoutConfig `json:"Timeouts,omitempty"`
+
+	// Checkpoint configures periodic checkpoints of the task's state, which are published
+	// and restored into the next execution if the task is rescheduled
+	Checkpoint *CheckpointConfig `json:"Checkpoint,omitempty"`
+
+	// OutputPolicy filters and limits the results of the task before they are published
+	OutputPolicy *OutputPolicy `json:"OutputPolicy,omitempty"`
+
+	// StopSignal is the signal sent to the task to ask it to terminate gracefully when its
+	// execution is cancelled, such as when the job is stopped or the node is drained.
+	// Defaults to DefaultStopSignal. Only supported by the docker engine.
+	StopSignal string `json:"StopSignal,omitempty"`
+
+	// KillTimeout is the grace period in seconds the task has to terminate after being sent
+	// the stop signal, before it is forcefully killed. Zero means the task is killed immediately.
+	// Only supported by the docker engine.
+	KillTimeout int64 `json:"KillTimeout,omitempty"`
+}
+
+// GetStopSignal returns the signal sent to the task to ask it to terminate gracefully
+func (t *Task) GetStopSignal() string {
+	if t.StopSignal != "" {
+		return t.StopSignal
+	}
+	return DefaultStopSignal
+}
+
+// GetKillTimeout returns the grace period the task has to terminate before being killed
+func (t *Task) GetKillTimeout() time.Duration {
+	return time.Duration(t.KillTimeout) * time.Second
 }
 
 func (t *Task) MetricAttributes() []attribute.KeyValue {
@@ -82,8 +127,12 @@ func (t *Task) Normalize() {
 	t.ResourcesConfig.Normalize()
 	NormalizeSlice(t.InputSources)
 	NormalizeSlice(t.ResultPaths)
+	NormalizeSlice(t.Volumes)
 	t.Network.Normalize()
 	t.ResourcesConfig.Normalize()
+	t.Checkpoint.Normalize()
+	t.OutputPolicy.Normalize()
+	t.StopSignal = strings.ToUpper(strings.TrimSpace(t.StopSignal))
 }
 
 func (t *Task) Copy() *Task {
@@ -97,13 +146,80 @@ func (t *Task) Copy() *Task {
 	nt.ResourcesConfig = t.ResourcesConfig.Copy()
 	nt.InputSources = CopySlice(t.InputSources)
 	nt.ResultPaths = CopySlice(t.ResultPaths)
+	if t.Volumes != nil {
+		nt.Volumes = CopySlice(t.Volumes)
+	}
 	nt.Meta = maps.Clone(t.Meta)
 	nt.Env = maps.Clone(t.Env)
 	nt.Network = t.Network.Copy()
 	nt.Timeouts = t.Timeouts.Copy()
+	nt.Checkpoint = t.Checkpoint.Copy()
+	nt.OutputPolicy = t.OutputPolicy.Copy()
 	return nt
 }
 
+// ReplaceSecretRefs replaces the secret references of the task with the values returned by replace,
+// which is only called with values that reference secrets.
+// References are found in environment variables, and in the params of the publisher and input sources,
+// such as the headers of webhook publishers or the token of git inputs. Params are copied before
+// references are replaced, so the task can share them with the task it was copied from, while
+// environment variables are replaced in place. replace may return the value unchanged to only
+// inspect the references.
+func (t *Task) ReplaceSecretRefs(replace SecretRefReplacer) error {
+	for name, value := range t.Env {
+		if !value.IsSecret() {
+			continue
+		}
+		replaced, err := replace("environment variable "+name, value)
+		if err != nil {
+			return err
+		}
+		if replaced != value {
+			t.Env[name] = replaced
+		}
+	}
+	if err := replaceSpecSecretRefs(t.Publisher, "publisher", replace); err != nil {
+		return err
+	}
+	for i, input := range t.InputSources {
+		if input == nil {
+			continue
+		}
+		location := fmt.Sprintf("input source %d", i)
+		if input.Alias != "" {
+			location = "input source " + input.Alias
+		}
+		if err := replaceSpecSecretRefs(input.Source, location, replace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceSpecSecretRefs replaces the secret references in the params of spec
+func replaceSpecSecretRefs(spec *SpecConfig, location string, replace SecretRefReplacer) error {
+	if spec == nil {
+		return nil
+	}
+	var params map[string]any
+	for key, value := range spec.Params {
+		replaced, changed, err := replaceSecretRefs(value, location+" param "+key, replace)
+		if err != nil {
+			return err
+		}
+		if changed {
+			if params == nil {
+				params = maps.Clone(spec.Params)
+			}
+			params[key] = replaced
+		}
+	}
+	if params != nil {
+		spec.Params = params
+	}
+	return nil
+}
+
 // Validate is used to check a job for reasonable configuration
 func (t *Task) Validate() error {
 	var mErr error
@@ -112,6 +228,12 @@ func (t *Task) Validate() error {
 	if len(t.ResultPaths) > 0 && t.Publisher.IsEmpty() {
 		mErr = errors.Join(mErr, errors.New("publisher must be set if result paths are set"))
 	}
+	if t.Checkpoint.IsEnabled() && t.Publisher.IsEmpty() {
+		mErr = errors.Join(mErr, errors.New("publisher must be set if checkpoints are enabled"))
+	}
+	if t.publishesOverlays() && t.Publisher.IsEmpty() {
+		mErr = errors.Join(mErr, errors.New("publisher must be set if input overlays are published"))
+	}
 
 	if err := t.Timeouts.Validate(); err != nil {
 		mErr = errors.Join(mErr, fmt.Errorf("task timeouts validation failed: %v", err))
@@ -137,6 +259,23 @@ func (t *Task) ValidateSubmission() error {
 	if err := t.Timeouts.ValidateSubmission(); err != nil {
 		mErr = errors.Join(mErr, fmt.Errorf("invalid timeouts: %v", err))
 	}
+	if err := t.Checkpoint.Validate(); err != nil {
+		mErr = errors.Join(mErr, fmt.Errorf("invalid checkpoint: %v", err))
+	}
+	if err := t.OutputPolicy.Validate(); err != nil {
+		mErr = errors.Join(mErr, fmt.Errorf("invalid output policy: %v", err))
+	}
+	if t.StopSignal != "" && !slices.Contains(StopSignals, t.StopSignal) {
+		mErr = errors.Join(mErr, fmt.Errorf("invalid stop signal %q. Must be one of %s",
+			t.StopSignal, strings.Join(StopSignals, ", ")))
+	}
+	if t.KillTimeout < 0 {
+		mErr = errors.Join(mErr, fmt.Errorf("invalid kill timeout value: %d", t.KillTimeout))
+	}
+	// wasm modules cannot handle signals, so they are always stopped immediately
+	if (t.StopSignal != "" || t.KillTimeout != 0) && t.Engine != nil && t.Engine.IsType(EngineWasm) {
+		mErr = errors.Join(mErr, errors.New("stop signal and kill timeout are not supported by the wasm engine"))
+	}
 	if err := t.ResourcesConfig.Validate(); err != nil {
 		mErr = errors.Join(mErr, fmt.Errorf("invalid resources: %v", err))
 	}
@@ -155,6 +294,14 @@ func (t *Task) ValidateSubmission() error {
 		mErr = errors.Join(mErr, err)
 	}
 
+	if err := ValidateSlice(t.Volumes); err != nil {
+		mErr = errors.Join(mErr, fmt.Errorf("invalid volumes: %v", err))
+	}
+
+	if err := t.validateVolumes(); err != nil {
+		mErr = errors.Join(mErr, err)
+	}
+
 	if err := t.Network.Validate(); err != nil {
 		mErr = errors.Join(mErr, fmt.Errorf("invalid network: %v", err))
 	}
@@ -199,6 +346,51 @@ func (t *Task) validateResultPaths() error {
 			seenResultPaths[result.Path] = true
 		}
 	}
+	// published overlays are written next to the result paths in the results of the execution
+	for _, input := range t.InputSources {
+		if input == nil || input.Overlay == nil || input.Overlay.Publish == "" {
+			continue
+		}
+		if seenResultNames[input.Overlay.Publish] {
+			return fmt.Errorf("overlay of input '%s' is published as '%s', which is already used by another result",
+				input.Target, input.Overlay.Publish)
+		}
+		seenResultNames[input.Overlay.Publish] = true
+	}
+	return nil
+}
+
+// publishesOverlays returns true if the writable layer of any input is published as a result
+func (t *Task) publishesOverlays() bool {
+	for _, input := range t.InputSources {
+		if input != nil && input.Overlay != nil && input.Overlay.Publish != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Task) validateVolumes() error {
+	seenVolumeNames := make(map[string]bool)
+	seenTargets := make(map[string]bool)
+	for _, input := range t.InputSources {
+		if input.Target != "" {
+			seenTargets[input.Target] = true
+		}
+	}
+	for _, volume := range t.Volumes {
+		if volume == nil {
+			continue
+		}
+		if seenVolumeNames[volume.Name] {
+			return fmt.Errorf("volume with name '%s' already exists", volume.Name)
+		}
+		seenVolumeNames[volume.Name] = true
+		if seenTargets[volume.Target] {
+			return fmt.Errorf("volume target '%s' is already used by another input or volume", volume.Target)
+		}
+		seenTargets[volume.Target] = true
+	}
 	return nil
 }
 
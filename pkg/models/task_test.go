@@ -4,6 +4,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -290,6 +291,83 @@ func (suite *TaskTestSuite) TestTaskValidation() {
 			},
 			validationMode: noError,
 		},
+		{
+			name: "Valid graceful termination",
+			task: &Task{
+				Name:        "graceful-termination",
+				Engine:      &SpecConfig{Type: "docker"},
+				StopSignal:  " sigint ",
+				KillTimeout: 30,
+			},
+			validationMode: noError,
+		},
+		{
+			name: "Invalid stop signal",
+			task: &Task{
+				Name:       "invalid-stop-signal",
+				Engine:     &SpecConfig{Type: "docker"},
+				StopSignal: "SIGSTOP",
+			},
+			validationMode: submissionError,
+			errMsg:         `invalid stop signal "SIGSTOP"`,
+		},
+		{
+			name: "Negative kill timeout",
+			task: &Task{
+				Name:        "negative-kill-timeout",
+				Engine:      &SpecConfig{Type: "docker"},
+				KillTimeout: -1,
+			},
+			validationMode: submissionError,
+			errMsg:         "invalid kill timeout value: -1",
+		},
+		{
+			name: "Graceful termination of wasm task",
+			task: &Task{
+				Name:        "wasm-graceful-termination",
+				Engine:      &SpecConfig{Type: EngineWasm},
+				KillTimeout: 30,
+			},
+			validationMode: submissionError,
+			errMsg:         "stop signal and kill timeout are not supported by the wasm engine",
+		},
+		{
+			name: "Published overlay without publisher",
+			task: &Task{
+				Name:   "overlay-without-publisher",
+				Engine: &SpecConfig{Type: "docker"},
+				InputSources: []*InputSource{
+					{Target: "/input", Source: &SpecConfig{Type: "local"}, Overlay: &InputOverlay{Publish: "changes"}},
+				},
+			},
+			validationMode: postSubmissionError,
+			errMsg:         "publisher must be set if input overlays are published",
+		},
+		{
+			name: "Overlay published as an existing result",
+			task: &Task{
+				Name:   "overlay-result-conflict",
+				Engine: &SpecConfig{Type: "docker"},
+				InputSources: []*InputSource{
+					{Target: "/input", Source: &SpecConfig{Type: "local"}, Overlay: &InputOverlay{Publish: "outputs"}},
+				},
+				ResultPaths: []*ResultPath{{Name: "outputs", Path: "/outputs"}},
+			},
+			validationMode: submissionError,
+			errMsg:         "already used by another result",
+		},
+		{
+			name: "Overlay published as a nested path",
+			task: &Task{
+				Name:   "overlay-nested-publish",
+				Engine: &SpecConfig{Type: "docker"},
+				InputSources: []*InputSource{
+					{Target: "/input", Source: &SpecConfig{Type: "local"}, Overlay: &InputOverlay{Publish: "../changes"}},
+				},
+			},
+			validationMode: submissionError,
+			errMsg:         "must be a single path component",
+		},
 	}
 
 	for _, tt := range tests {
@@ -318,6 +396,16 @@ func (suite *TaskTestSuite) TestTaskValidation() {
 	}
 }
 
+func (suite *TaskTestSuite) TestTaskStopDefaults() {
+	task := &Task{}
+	suite.Equal(DefaultStopSignal, task.GetStopSignal())
+	suite.Zero(task.GetKillTimeout())
+
+	task = &Task{StopSignal: "SIGINT", KillTimeout: 30}
+	suite.Equal("SIGINT", task.GetStopSignal())
+	suite.Equal(30*time.Second, task.GetKillTimeout())
+}
+
 func (suite *TaskTestSuite) TestTaskCopy() {
 	original := &Task{
 		Name:      "original-task",
@@ -372,6 +460,59 @@ func (suite *TaskTestSuite) TestTaskCopy() {
 	suite.NotEqual(original.Env, cpy.Env)
 }
 
+func (suite *TaskTestSuite) TestReplaceSecretRefs() {
+	headers := map[string]any{"Authorization": "secret:orchestrator/webhook-token", "Accept": "text/plain"}
+	original := &Task{
+		Env:       map[string]EnvVarValue{"PASSWORD": "secret:orchestrator/password", "LITERAL": "value"},
+		Publisher: &SpecConfig{Type: "webhook", Params: map[string]any{"URL": "https://example.com", "Headers": headers}},
+		InputSources: []*InputSource{
+			{Alias: "repo", Source: &SpecConfig{Type: "git", Params: map[string]any{"Token": "secret:vault/git#token"}}},
+			{Source: &SpecConfig{Type: "url", Params: map[string]any{"Headers": map[string]string{"X-Key": "secret:file/key"}}}},
+		},
+	}
+	task := original.Copy()
+
+	locations := make(map[string]EnvVarValue)
+	err := task.ReplaceSecretRefs(func(location string, value EnvVarValue) (EnvVarValue, error) {
+		locations[location] = value
+		backend, key, _ := value.SecretRef()
+		if backend != SecretBackendOrchestrator {
+			return value, nil
+		}
+		return NewSecretRef(SecretBackendSealed, key), nil
+	})
+	suite.Require().NoError(err)
+	suite.Equal(map[string]EnvVarValue{
+		"environment variable PASSWORD":         "secret:orchestrator/password",
+		"publisher param Headers.Authorization": "secret:orchestrator/webhook-token",
+		"input source repo param Token":         "secret:vault/git#token",
+		"input source 1 param Headers.X-Key":    "secret:file/key",
+	}, locations)
+
+	suite.Equal(EnvVarValue("secret:sealed/password"), task.Env["PASSWORD"])
+	suite.Equal(map[string]any{"Authorization": "secret:sealed/webhook-token", "Accept": "text/plain"},
+		task.Publisher.Params["Headers"])
+	suite.Equal("secret:vault/git#token", task.InputSources[0].Source.Params["Token"])
+
+	// params shared with the original task are copied before they are modified
+	suite.Equal("secret:orchestrator/webhook-token", headers["Authorization"])
+	suite.Equal(EnvVarValue("secret:orchestrator/password"), original.Env["PASSWORD"])
+}
+
+func (suite *TaskTestSuite) TestRedactedJobParams() {
+	job := &Job{Tasks: []*Task{{
+		Env: map[string]EnvVarValue{"PASSWORD": NewSecretRef(SecretBackendSealed, "payload")},
+		Publisher: &SpecConfig{Type: "webhook", Params: map[string]any{
+			"Headers": map[string]any{"Authorization": string(NewSecretRef(SecretBackendSealed, "payload"))},
+		}},
+	}}}
+
+	redacted := job.Redacted()
+	suite.Equal(EnvVarValue("secret:sealed/[REDACTED]"), redacted.Task().Env["PASSWORD"])
+	suite.Equal(map[string]any{"Authorization": "secret:sealed/[REDACTED]"}, redacted.Task().Publisher.Params["Headers"])
+	suite.Equal(map[string]any{"Authorization": "secret:sealed/payload"}, job.Task().Publisher.Params["Headers"])
+}
+
 func (suite *TaskTestSuite) TestAllStorageTypes() {
 	task := &Task{
 		InputSources: []*InputSource{
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/validate"
+)
+
+// volumeNamePattern matches the names of persistent volumes, which are used as directory names on compute nodes
+var volumeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// PersistentVolume is a node-local named volume of a task, whose data persists across the executions
+// of its job on the same node, such as when a service job is updated or restarted. Persistent volumes
+// are only supported by long running jobs, and are deleted when their job is stopped.
+type PersistentVolume struct {
+	// Name identifies the volume within its job, such as "data"
+	Name string `json:"Name"`
+	// Target is the absolute path where the volume is mounted in the task
+	Target string `json:"Target"`
+	// MaxSize is the maximum size of the volume, such as "10GB".
+	// Defaults to the maximum volume size of the compute node.
+	MaxSize string `json:"MaxSize,omitempty"`
+}
+
+// GetMaxSize returns the maximum size of the volume in bytes, or 0 if it defaults to the node's maximum
+func (v *PersistentVolume) GetMaxSize() (uint64, error) {
+	if v == nil || v.MaxSize == "" {
+		return 0, nil
+	}
+	return humanize.ParseBytes(v.MaxSize)
+}
+
+// Normalize normalizes the persistent volume
+func (v *PersistentVolume) Normalize() {
+	if v == nil {
+		return
+	}
+	v.Name = strings.TrimSpace(v.Name)
+	v.Target = strings.TrimSpace(v.Target)
+	v.MaxSize = strings.TrimSpace(v.MaxSize)
+}
+
+// Copy returns a deep copy of the persistent volume
+func (v *PersistentVolume) Copy() *PersistentVolume {
+	if v == nil {
+		return nil
+	}
+	cpy := *v
+	return &cpy
+}
+
+// Validate is used to check a persistent volume for reasonable configuration
+func (v *PersistentVolume) Validate() error {
+	if v == nil {
+		return errors.New("persistent volume is nil")
+	}
+	mErr := validate.NotBlank(v.Name, "persistent volume name cannot be blank")
+	if v.Name != "" && !volumeNamePattern.MatchString(v.Name) {
+		mErr = errors.Join(mErr, fmt.Errorf("invalid persistent volume name %q. Must start with a letter or digit "+
+			"and only contain letters, digits, '_', '.' and '-'", v.Name))
+	}
+	if !filepath.IsAbs(v.Target) {
+		mErr = errors.Join(mErr, fmt.Errorf("target of persistent volume %q must be an absolute path", v.Name))
+	}
+	if _, err := v.GetMaxSize(); err != nil {
+		mErr = errors.Join(mErr, fmt.Errorf("invalid max size %q of persistent volume %q: %w", v.MaxSize, v.Name, err))
+	}
+	return mErr
+}
+
+// VolumeRef identifies the persistent volume of a job on a compute node.
+// An empty name refers to all the volumes of the job.
+type VolumeRef struct {
+	JobID string `json:"JobID"`
+	Name  string `json:"Name,omitempty"`
+}
+
+// Matches returns true if the reference refers to the volume of the job with the name
+func (r VolumeRef) Matches(jobID, name string) bool {
+	return r.JobID == jobID && (r.Name == "" || r.Name == name)
+}
+
+// VolumeInfo describes a persistent volume held by a compute node
+type VolumeInfo struct {
+	Namespace string `json:"Namespace"`
+	JobID     string `json:"JobID"`
+	Name      string `json:"Name"`
+	// Size is the size in bytes of the volume's data when it was last measured, which is when
+	// an execution using the volume started or ended, and periodically while it runs
+	Size uint64 `json:"Size"`
+	// MaxSize is the maximum size in bytes of the volume
+	MaxSize    uint64    `json:"MaxSize"`
+	CreateTime time.Time `json:"CreateTime"`
+	// LastUsedTime is when an execution using the volume was last measured
+	LastUsedTime time.Time `json:"LastUsedTime"`
+}
+
+// Ref returns the reference to the volume
+func (v VolumeInfo) Ref() VolumeRef {
+	return VolumeRef{JobID: v.JobID, Name: v.Name}
+}
@@ -8,12 +8,14 @@ const (
 	ComputeEndpointSubjectPrefix = "node.compute"
 	CallbackSubjectPrefix        = "node.orchestrator"
 	ManagementSubjectPrefix      = "node.management"
+	ExecInputSubjectPrefix       = "node.exec"
 
 	AskForBid       = "AskForBid/v1"
 	BidAccepted     = "BidAccepted/v1"
 	BidRejected     = "BidRejected/v1"
 	CancelExecution = "CancelExecution/v1"
 	ExecutionLogs   = "ExecutionLogs/v1"
+	Exec            = "Exec/v1"
 
 	OnBidComplete    = "OnBidComplete/v1"
 	OnRunComplete    = "OnRunComplete/v1"
@@ -47,3 +49,8 @@ func managementPublishSubject(nodeID string, method string) string {
 func managementSubscribeSubject() string {
 	return fmt.Sprintf("%s.>", ManagementSubjectPrefix)
 }
+
+// execInputSubject is the subject the input of an interactive session is delivered on
+func execInputSubject(nodeID string, sessionID string) string {
+	return fmt.Sprintf("%s.%s.%s", ExecInputSubjectPrefix, nodeID, sessionID)
+}
@@ -0,0 +1,116 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/execstream"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/concurrency"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/models/messages"
+	"github.com/bacalhau-project/bacalhau/pkg/nats/stream"
+)
+
+// execInputChanLen is the channel length for buffering the input of a session.
+const execInputChanLen = 32
+
+// ExecHandlerParams defines parameters for creating a new ExecHandler.
+type ExecHandlerParams struct {
+	Name       string
+	Conn       *nats.Conn
+	ExecServer execstream.Server
+}
+
+// ExecHandler serves interactive sessions into the executions of a compute node over NATS.
+// The output of a session is streamed back to the requester, while its input is received
+// on a dedicated subject for the session.
+type ExecHandler struct {
+	name            string
+	conn            *nats.Conn
+	execServer      execstream.Server
+	subscription    *nats.Subscription
+	streamingClient *stream.ProducerClient
+}
+
+// NewExecHandler creates a new ExecHandler.
+func NewExecHandler(ctx context.Context, params ExecHandlerParams) (*ExecHandler, error) {
+	streamingClient, err := stream.NewProducerClient(ctx, stream.ProducerClientParams{
+		Conn: params.Conn,
+		Config: stream.StreamProducerClientConfig{
+			HeartBeatIntervalDuration:        stream.DefaultHeartBeatIntervalDuration,
+			HeartBeatRequestTimeout:          stream.DefaultHeartBeatRequestTimeout,
+			StreamCancellationBufferDuration: stream.DefaultStreamCancellationBufferDuration,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	handler := &ExecHandler{
+		name:            params.Name,
+		conn:            params.Conn,
+		execServer:      params.ExecServer,
+		streamingClient: streamingClient,
+	}
+
+	subject := computeEndpointPublishSubject(handler.name, Exec)
+	subscription, err := handler.conn.Subscribe(subject, func(m *nats.Msg) {
+		processAndStream(context.Background(), handler.streamingClient, m, handler.exec)
+	})
+	if err != nil {
+		return nil, err
+	}
+	handler.subscription = subscription
+	log.Debug().Msgf("NATS exec handler subscribed to %s", subject)
+	return handler, nil
+}
+
+// exec subscribes to the input of the session before opening it, and releases
+// the subscription once the output of the session ends.
+func (handler *ExecHandler) exec(ctx context.Context, request messages.ExecRequest) (
+	<-chan *concurrency.AsyncResult[models.ExecOutput], error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	// the input channel is never closed, as NATS may still deliver messages after unsubscribing.
+	// The session stops reading it once its context is done.
+	input := make(chan models.ExecInput, execInputChanLen)
+	subscription, err := handler.conn.Subscribe(execInputSubject(handler.name, request.SessionID), func(m *nats.Msg) {
+		frame := models.ExecInput{}
+		if err := json.Unmarshal(m.Data, &frame); err != nil {
+			log.Warn().Err(err).Str("session", request.SessionID).Msg("failed to decode session input")
+			return
+		}
+		select {
+		case input <- frame:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	output, err := handler.execServer.Exec(ctx, request, input)
+	if err != nil {
+		_ = subscription.Unsubscribe()
+		cancel()
+		return nil, err
+	}
+
+	forwarded := make(chan *concurrency.AsyncResult[models.ExecOutput], execInputChanLen)
+	go func() {
+		defer close(forwarded)
+		defer cancel()
+		defer func() { _ = subscription.Unsubscribe() }()
+		for result := range output {
+			select {
+			case forwarded <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return forwarded, nil
+}
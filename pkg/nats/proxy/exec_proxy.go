@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/execstream"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/concurrency"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/models/messages"
+	"github.com/bacalhau-project/bacalhau/pkg/nats/stream"
+)
+
+type ExecProxyParams struct {
+	Conn *nats.Conn
+}
+
+// ExecProxy forwards interactive sessions to the compute node running the execution.
+// The output of the session is streamed from the compute node, and the input is published
+// to the session's input subject once the compute node reports the session as started.
+type ExecProxy struct {
+	conn            *nats.Conn
+	streamingClient *stream.ConsumerClient
+}
+
+func NewExecProxy(params ExecProxyParams) (*ExecProxy, error) {
+	sc, err := stream.NewConsumerClient(stream.ConsumerClientParams{
+		Conn: params.Conn,
+		Config: stream.StreamConsumerClientConfig{
+			StreamCancellationBufferDuration: streamCancellationBufferDuration,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ExecProxy{
+		conn:            params.Conn,
+		streamingClient: sc,
+	}, nil
+}
+
+func (p *ExecProxy) Exec(ctx context.Context, request messages.ExecRequest, input <-chan models.ExecInput) (
+	<-chan *concurrency.AsyncResult[models.ExecOutput], error) {
+	output, err := proxyStreamingRequest[messages.ExecRequest, models.ExecOutput](
+		ctx, p.streamingClient, &BaseRequest[messages.ExecRequest]{
+			TargetNodeID: request.NodeID,
+			Method:       Exec,
+			Body:         request,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	started := make(chan struct{})
+	go p.forwardInput(ctx, request, input, started)
+
+	forwarded := make(chan *concurrency.AsyncResult[models.ExecOutput], asyncRequestChanLen)
+	go func() {
+		defer close(forwarded)
+		isStarted := false
+		for result := range output {
+			if !isStarted && result.Err == nil && result.Value.Type == models.ExecOutputTypeStarted {
+				isStarted = true
+				close(started)
+			}
+			select {
+			case forwarded <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return forwarded, nil
+}
+
+// forwardInput publishes the input of the session once the compute node is subscribed to it
+func (p *ExecProxy) forwardInput(
+	ctx context.Context, request messages.ExecRequest, input <-chan models.ExecInput, started <-chan struct{}) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-started:
+	}
+
+	subject := execInputSubject(request.NodeID, request.SessionID)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case frame, ok := <-input:
+			if !ok {
+				// let the compute node know there is no more input
+				frame = models.ExecInput{CloseStdin: true}
+			}
+			data, err := json.Marshal(frame)
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to encode session input")
+				return
+			}
+			if err = p.conn.Publish(subject, data); err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("session", request.SessionID).Msg("failed to publish session input")
+				return
+			}
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// Compile-time interface check:
+var _ execstream.Server = (*ExecProxy)(nil)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/rs/zerolog/log"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
@@ -15,6 +16,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity/disk"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/env"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/execstream"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/logstream"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/sensors"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
@@ -22,6 +24,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/compute/watchers"
 	"github.com/bacalhau-project/bacalhau/pkg/executor"
 	executor_util "github.com/bacalhau-project/bacalhau/pkg/executor/util"
+	baccrypto "github.com/bacalhau-project/bacalhau/pkg/lib/crypto"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/watcher"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
 	"github.com/bacalhau-project/bacalhau/pkg/nats"
@@ -99,10 +102,30 @@ func NewComputeNode(
 		return nil, err
 	}
 
+	userKeyPath, err := cfg.BacalhauConfig.UserKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	userKey, err := baccrypto.LoadUserKey(userKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := baccrypto.EncodePublicKey(userKey.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	secretBackends, err := createSecretBackends(cfg, userKey)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create environment variable resolver to be used by both bidder and executor
 	envResolver := env.NewResolver(env.ResolverParams{
-		AllowList: cfg.BacalhauConfig.Compute.Env.AllowList,
+		AllowList:      cfg.BacalhauConfig.Compute.Env.AllowList,
+		SecretBackends: secretBackends,
 	})
+	secretRedactor := compute.NewSecretRedactor()
 
 	portAllocator, err := compute.NewPortAllocator(
 		cfg.BacalhauConfig.Compute.Network.PortRangeStart,
@@ -112,6 +135,11 @@ func NewComputeNode(
 		return nil, err
 	}
 
+	volumeManager, err := createVolumeManager(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// We set the default network type if the node rejects network jobs.
 	// Otherwise, we let each executor set the proper network type if not explicitly defined.
 	// - docker: sets the default as bridge, since it is supported across multiple platforms
@@ -130,15 +158,25 @@ func NewComputeNode(
 		FailureInjectionConfig: cfg.FailureInjectionConfig,
 		ResultsPath:            *resultsPath,
 		EnvResolver:            envResolver,
+		SecretRedactor:         secretRedactor,
 		PortAllocator:          portAllocator,
+		ManifestKey:            userKey.PrivateKey(),
+		Volumes:                volumeManager,
 		DefaultNetworkType:     defaultNetworkType,
 	})
 
+	queuePolicy, err := compute.ParseQueuePolicy(cfg.BacalhauConfig.Compute.Queue.Policy)
+	if err != nil {
+		return nil, err
+	}
 	bufferRunner := compute.NewExecutorBuffer(compute.ExecutorBufferParams{
 		ID:                     cfg.NodeID,
 		DelegateExecutor:       baseExecutor,
 		RunningCapacityTracker: runningCapacityTracker,
 		EnqueuedUsageTracker:   enqueuedUsageTracker,
+		QueuePolicy:            queuePolicy,
+		AgingInterval:          cfg.BacalhauConfig.Compute.Queue.AgingInterval.AsTimeDuration(),
+		ReservationThreshold:   cfg.BacalhauConfig.Compute.Queue.ReservationThreshold.AsTimeDuration(),
 	})
 	runningInfoProvider := sensors.NewRunningExecutionsInfoProvider(sensors.RunningExecutionsInfoProviderParams{
 		Name:          "ActiveJobs",
@@ -172,6 +210,7 @@ func NewComputeNode(
 		executionStore,
 		capacityCalculator,
 		envResolver,
+		volumeManager,
 	)
 	baseEndpoint := compute.NewBaseEndpoint(compute.BaseEndpointParams{
 		ExecutionStore: executionStore,
@@ -180,6 +219,10 @@ func NewComputeNode(
 	// register debug info providers for the /debug endpoint
 	debugInfoProviders := []models.DebugInfoProvider{
 		runningInfoProvider,
+		sensors.NewQueueInfoProvider(sensors.QueueInfoProviderParams{
+			Name:          "ExecutionQueue",
+			BackendBuffer: bufferRunner,
+		}),
 		sensors.NewCompletedJobs(executionStore),
 	}
 
@@ -193,6 +236,9 @@ func NewComputeNode(
 	// TODO: attempt to auto-detect the address if not provided
 	address := cfg.BacalhauConfig.Compute.Network.AdvertisedAddress
 
+	// images cached by the executors are advertised to the orchestrator, which hints popular images to warm
+	imageCache := executorImageCache(ctx, executors)
+
 	// node info provider
 	nodeInfoProvider.RegisterNodeInfoDecorator(compute.NewNodeInfoDecorator(compute.NodeInfoDecoratorParams{
 		Executors:              executors,
@@ -203,6 +249,9 @@ func NewComputeNode(
 		ExecutorBuffer:         bufferRunner,
 		MaxJobRequirements:     allocatedResources,
 		AdvertisedAddress:      address,
+		PublicKey:              publicKey,
+		ImageCache:             imageCache,
+		Volumes:                volumeManager,
 	}))
 	nodeInfoProvider.RegisterLabelProvider(capacity.NewGPULabelsProvider(allocatedResources))
 
@@ -219,6 +268,12 @@ func NewComputeNode(
 		return nil, err
 	}
 
+	// volumes of stopped jobs are deleted when requested by the orchestrator
+	var volumeDeleter nclprotocolcompute.VolumeDeleter
+	if volumeManager != nil {
+		volumeDeleter = volumeManager
+	}
+
 	// connection manager
 	connectionManager, err := nclprotocolcompute.NewConnectionManager(nclprotocolcompute.Config{
 		NodeID:                  cfg.NodeID,
@@ -235,7 +290,14 @@ func NewComputeNode(
 			ExecutionStore: executionStore,
 			Executors:      executors,
 			ResultsPath:    *resultsPath,
+			SecretRedactor: secretRedactor,
 		}),
+		ExecServer: execstream.NewServer(execstream.ServerParams{
+			ExecutionStore: executionStore,
+			Executors:      executors,
+		}),
+		ImageWarmer:   imageCache,
+		VolumeDeleter: volumeDeleter,
 	})
 	if err != nil {
 		return nil, err
@@ -308,6 +370,79 @@ func createExecutionStore(ctx context.Context, cfg NodeConfig) (store.ExecutionS
 	return executionStore, nil
 }
 
+// createSecretBackends creates the backends used to resolve secret references in task environment variables.
+// Secrets sealed by the orchestrator are always accepted, while the file and vault backends are opt-in.
+func createSecretBackends(cfg NodeConfig, userKey *baccrypto.UserKey) ([]env.SecretBackend, error) {
+	secretsConfig := cfg.BacalhauConfig.Compute.Env.Secrets
+	backends := []env.SecretBackend{
+		env.NewSealedSecretBackend(userKey.PrivateKey()),
+	}
+
+	if secretsConfig.File.Enabled {
+		path, keyFile, err := cfg.BacalhauConfig.ComputeSecretsPaths()
+		if err != nil {
+			return nil, err
+		}
+		fileStore, err := env.NewFileSecretStore(env.FileSecretStoreParams{
+			Path:    path,
+			KeyFile: keyFile,
+		})
+		if err != nil {
+			return nil, bacerrors.Wrap(err, "failed to create file secret store")
+		}
+		backends = append(backends, fileStore)
+	}
+
+	if secretsConfig.Vault.Address != "" {
+		vault, err := env.NewVaultSecretBackend(env.VaultSecretBackendParams{
+			Address:   secretsConfig.Vault.Address,
+			Token:     secretsConfig.Vault.Token,
+			Namespace: secretsConfig.Vault.Namespace,
+			Timeout:   secretsConfig.Vault.Timeout.AsTimeDuration(),
+		})
+		if err != nil {
+			return nil, bacerrors.Wrap(err, "failed to create vault secret backend")
+		}
+		backends = append(backends, vault)
+	}
+
+	return backends, nil
+}
+
+// createVolumeManager creates the manager of the persistent volumes of long running jobs,
+// or returns nil if persistent volumes are disabled
+func createVolumeManager(cfg NodeConfig) (*compute.VolumeManager, error) {
+	volumesConfig := cfg.BacalhauConfig.Compute.Volumes
+	if volumesConfig.Disabled {
+		return nil, nil
+	}
+	var maxSize uint64
+	if volumesConfig.MaxSize != "" {
+		var err error
+		if maxSize, err = humanize.ParseBytes(volumesConfig.MaxSize); err != nil {
+			return nil, fmt.Errorf("invalid max size of persistent volumes %q: %w", volumesConfig.MaxSize, err)
+		}
+	}
+	volumesDir, err := cfg.BacalhauConfig.VolumesDir()
+	if err != nil {
+		return nil, err
+	}
+	return compute.NewVolumeManager(compute.VolumeManagerParams{
+		Dir:           volumesDir,
+		MaxSize:       maxSize,
+		CheckInterval: volumesConfig.CheckInterval.AsTimeDuration(),
+	})
+}
+
+// persistentVolumesStrategyParams returns the parameters of the bid strategy of jobs with persistent volumes,
+// which are rejected if the node doesn't manage persistent volumes
+func persistentVolumesStrategyParams(volumeManager *compute.VolumeManager) semantic.PersistentVolumesStrategyParams {
+	if volumeManager == nil {
+		return semantic.PersistentVolumesStrategyParams{Disabled: true}
+	}
+	return semantic.PersistentVolumesStrategyParams{MaxSize: volumeManager.MaxSize()}
+}
+
 func (c *Compute) Cleanup(ctx context.Context) {
 	c.cleanupFunc(ctx)
 }
@@ -321,6 +456,7 @@ func NewBidder(
 	executionStore store.ExecutionStore,
 	calculator capacity.UsageCalculator,
 	envResolver compute.EnvVarResolver,
+	volumeManager *compute.VolumeManager,
 ) compute.Bidder {
 	var semanticBidStrats []bidstrategy.SemanticBidStrategy
 	if cfg.SystemConfig.BidSemanticStrategy == nil {
@@ -348,6 +484,7 @@ func NewBidder(
 			semantic.NewEnvResolverStrategy(semantic.EnvResolverStrategyParams{
 				Resolver: envResolver,
 			}),
+			semantic.NewPersistentVolumesStrategy(persistentVolumesStrategyParams(volumeManager)),
 		}
 	} else {
 		semanticBidStrats = []bidstrategy.SemanticBidStrategy{cfg.SystemConfig.BidSemanticStrategy}
@@ -373,6 +510,16 @@ func NewBidder(
 	})
 }
 
+// executorImageCache returns the image cache of the docker executor, or nil if it is not available
+func executorImageCache(ctx context.Context, executors executor.ExecProvider) executor.ImageCache {
+	dockerExecutor, err := executors.Get(ctx, models.EngineDocker)
+	if err != nil {
+		return nil
+	}
+	imageCache, _ := dockerExecutor.(executor.ImageCache)
+	return imageCache
+}
+
 func setupComputeWatchers(
 	ctx context.Context,
 	executionStore store.ExecutionStore,
@@ -16,4 +16,8 @@ const (
 	// orchestratorExecutionLoggerWatcherID is the ID of the watcher that listens for execution events
 	// and logs them.
 	orchestratorExecutionLoggerWatcherID = "orchestrator-logger"
+
+	// orchestratorImagePopularityWatcherID is the ID of the watcher that listens for execution events
+	// and counts the images of new executions to hint popular images to compute nodes.
+	orchestratorImagePopularityWatcherID = "image-popularity"
 )
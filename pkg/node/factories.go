@@ -8,13 +8,17 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/authn"
 	"github.com/bacalhau-project/bacalhau/pkg/authn/ask"
 	"github.com/bacalhau-project/bacalhau/pkg/authn/challenge"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/env"
 	"github.com/bacalhau-project/bacalhau/pkg/config/types"
 	"github.com/bacalhau-project/bacalhau/pkg/executor"
 	executor_util "github.com/bacalhau-project/bacalhau/pkg/executor/util"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm/funcs/kv"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/blobstore"
 	baccrypto "github.com/bacalhau-project/bacalhau/pkg/lib/crypto"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/ncl"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/policy"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/provider"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher"
 	publisher_util "github.com/bacalhau-project/bacalhau/pkg/publisher/util"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
@@ -52,7 +56,24 @@ func NewStandardStorageProvidersFactory(cfg types.Bacalhau) StorageProvidersFact
 		ctx context.Context,
 		nodeConfig NodeConfig,
 	) (storage.StorageProvider, error) {
-		pr, err := executor_util.NewStandardStorageProvider(cfg)
+		secretResolver, err := newSpecSecretResolver(nodeConfig)
+		if err != nil {
+			return nil, err
+		}
+		var blobStore blobstore.Store
+		if cfg.InputSources.IsNotDisabled(models.StorageSourceBlob) && nodeConfig.NATSClientFactory != nil {
+			store, err := blobstore.NewNATSStore(blobstore.NATSStoreParams{
+				ClientFactory: nodeConfig.NATSClientFactory,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if nodeConfig.CleanupManager != nil {
+				nodeConfig.CleanupManager.RegisterCallbackWithContext(store.Close)
+			}
+			blobStore = store
+		}
+		pr, err := executor_util.NewStandardStorageProvider(cfg, secretResolver, blobStore)
 		if err != nil {
 			return nil, err
 		}
@@ -63,10 +84,43 @@ func NewStandardStorageProvidersFactory(cfg types.Bacalhau) StorageProvidersFact
 func NewStandardExecutorsFactory(cfg types.EngineConfig) ExecutorsFactory {
 	return ExecutorsFactoryFunc(
 		func(ctx context.Context, nodeConfig NodeConfig) (executor.ExecProvider, error) {
+			var dockerImageCacheFile string
+			if cfg.IsNotDisabled(models.EngineDocker) && !cfg.Types.Docker.ImageCache.Disabled {
+				var err error
+				dockerImageCacheFile, err = nodeConfig.BacalhauConfig.DockerImageCacheFilePath()
+				if err != nil {
+					return nil, err
+				}
+			}
+			var wasmCacheDir string
+			if cfg.IsNotDisabled(models.EngineWasm) && !cfg.Types.WASM.CompilationCache.Disabled {
+				var err error
+				wasmCacheDir, err = nodeConfig.BacalhauConfig.WASMCompilationCacheDir()
+				if err != nil {
+					return nil, err
+				}
+			}
+			var wasmKeyValueStore kv.Store
+			if cfg.IsNotDisabled(models.EngineWasm) && !cfg.Types.WASM.KeyValue.Disabled && nodeConfig.NATSClientFactory != nil {
+				store, err := kv.NewNATSStore(kv.NATSStoreParams{
+					ClientFactory: nodeConfig.NATSClientFactory,
+					Bucket:        cfg.Types.WASM.KeyValue.Bucket,
+				})
+				if err != nil {
+					return nil, err
+				}
+				if nodeConfig.CleanupManager != nil {
+					nodeConfig.CleanupManager.RegisterCallbackWithContext(store.Close)
+				}
+				wasmKeyValueStore = store
+			}
 			pr, err := executor_util.NewStandardExecutorProvider(
 				cfg,
 				executor_util.StandardExecutorOptions{
-					DockerID: fmt.Sprintf("bacalhau-%s", nodeConfig.NodeID),
+					DockerID:             fmt.Sprintf("bacalhau-%s", nodeConfig.NodeID),
+					DockerImageCacheFile: dockerImageCacheFile,
+					WASMCacheDir:         wasmCacheDir,
+					WASMKeyValueStore:    wasmKeyValueStore,
 				},
 			)
 			if err != nil {
@@ -84,7 +138,11 @@ func NewStandardPublishersFactory(
 		func(
 			ctx context.Context,
 			nodeConfig NodeConfig) (publisher.PublisherProvider, error) {
-			pr, err := publisher_util.NewPublisherProvider(ctx, cfg, nclPublisherProvider)
+			secretResolver, err := newSpecSecretResolver(nodeConfig)
+			if err != nil {
+				return nil, err
+			}
+			pr, err := publisher_util.NewPublisherProvider(ctx, cfg, nclPublisherProvider, secretResolver)
 			if err != nil {
 				return nil, err
 			}
@@ -92,6 +150,25 @@ func NewStandardPublishersFactory(
 		})
 }
 
+// newSpecSecretResolver creates the resolver of secret references in publisher and input source specs,
+// such as the headers of the webhook publisher or the token of git inputs, backed by the same secret
+// backends as task environment variables.
+func newSpecSecretResolver(nodeConfig NodeConfig) (*env.SecretResolver, error) {
+	userKeyPath, err := nodeConfig.BacalhauConfig.UserKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	userKey, err := baccrypto.LoadUserKey(userKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	backends, err := createSecretBackends(nodeConfig, userKey)
+	if err != nil {
+		return nil, err
+	}
+	return env.NewSecretResolver(backends...), nil
+}
+
 func NewStandardAuthenticatorsFactory(userKey *baccrypto.UserKey) AuthenticatorsFactory {
 	return AuthenticatorsFactoryFunc(
 		func(ctx context.Context, nodeConfig NodeConfig) (authn.Provider, error) {
@@ -19,6 +19,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/lib/policy"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/validate"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
+	nats_helper "github.com/bacalhau-project/bacalhau/pkg/nats"
 	nats_transport "github.com/bacalhau-project/bacalhau/pkg/nats/transport"
 	"github.com/bacalhau-project/bacalhau/pkg/node/metrics"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
@@ -52,6 +53,8 @@ type NodeConfig struct {
 	SystemConfig           SystemConfig
 	DependencyInjector     NodeDependencyInjector
 	FailureInjectionConfig models.FailureInjectionConfig
+	// NATSClientFactory creates clients connected to the orchestrator. It is set once the transport is created.
+	NATSClientFactory nats_helper.ClientFactory
 }
 
 // Validate Config
@@ -191,6 +194,7 @@ func NewNode(
 	if err != nil {
 		return nil, err
 	}
+	cfg.NATSClientFactory = transportLayer
 
 	var debugInfoProviders []models.DebugInfoProvider
 	debugInfoProviders = append(debugInfoProviders, transportLayer.DebugInfoProviders()...)
@@ -10,8 +10,10 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/env"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
 	boltjobstore "github.com/bacalhau-project/bacalhau/pkg/jobstore/boltdb"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/blobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/watcher"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
 	"github.com/bacalhau-project/bacalhau/pkg/models/messages"
@@ -92,9 +94,20 @@ func NewRequesterNode(
 	}
 	evalBroker.SetEnabled(true)
 
+	secretStore, err := createOrchestratorSecretStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// planners that execute the proposed plan by the scheduler
 	// order of the planners is important as they are executed in order
 	planners := planner.NewChain(
+		// seals orchestrator secrets referenced by new executions to the key of their nodes
+		planner.NewSecretSealer(planner.SecretSealerParams{
+			SecretStore: secretStore,
+			NodeLookup:  nodesManager,
+		}),
+
 		// logs job completion or failure
 		planner.NewLoggingPlanner(),
 
@@ -226,6 +239,8 @@ func NewRequesterNode(
 		transformer.OrchestratorInstanceID(metadataStore.InstanceID()),
 		transformer.DefaultsApplier(cfg.BacalhauConfig.JobDefaults),
 		transformer.NewLegacyWasmModuleTransformer(),
+		transformer.OrchestratorSecretsValidator(secretStore),
+		transformer.JobInputsResolver(jobStore),
 	}
 
 	logStreamProxy, err := proxy.NewLogStreamProxy(proxy.LogStreamProxyParams{
@@ -235,10 +250,18 @@ func NewRequesterNode(
 		return nil, err
 	}
 
+	execProxy, err := proxy.NewExecProxy(proxy.ExecProxyParams{
+		Conn: natsConn,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	endpointV2 := orchestrator.NewBaseEndpoint(&orchestrator.BaseEndpointParams{
 		ID:                nodeID,
 		Store:             jobStore,
 		LogstreamServer:   logStreamProxy,
+		ExecServer:        execProxy,
 		JobTransformer:    jobTransformers,
 		ResultTransformer: resultTransformers,
 	})
@@ -258,11 +281,34 @@ func NewRequesterNode(
 		discovery.NewDebugInfoProvider(nodesManager),
 	}
 
+	// blobs uploaded by clients as job inputs are stored in the orchestrator's JetStream
+	blobStore, err := blobstore.NewNATSStore(blobstore.NATSStoreParams{ClientFactory: transportLayer})
+	if err != nil {
+		return nil, err
+	}
+
+	// deletes the blobs that are no longer referenced by jobs
+	blobJanitor, err := orchestrator.NewBlobJanitor(orchestrator.BlobJanitorParams{
+		JobStore:  jobStore,
+		BlobStore: blobStore,
+	})
+	if err != nil {
+		return nil, err
+	}
+	blobJanitor.Start(ctx)
+
+	// tells compute nodes to delete the persistent volumes of stopped jobs, and the volumes requested through the API
+	volumeJanitor := orchestrator.NewVolumeJanitor(orchestrator.VolumeJanitorParams{
+		JobStore: jobStore,
+	})
+
 	orchestrator_endpoint.NewEndpoint(orchestrator_endpoint.EndpointParams{
-		Router:       apiServer.Router,
-		Orchestrator: endpointV2,
-		JobStore:     jobStore,
-		NodeManager:  nodesManager,
+		Router:        apiServer.Router,
+		Orchestrator:  endpointV2,
+		JobStore:      jobStore,
+		NodeManager:   nodesManager,
+		BlobStore:     blobStore,
+		VolumeJanitor: volumeJanitor,
 	})
 
 	authenticators, err := cfg.DependencyInjector.AuthenticatorsFactory.Get(ctx, cfg)
@@ -290,6 +336,9 @@ func NewRequesterNode(
 		return nil, pkgerrors.Wrap(err, "failed to start connection manager")
 	}
 
+	// counts the images of new executions, which are hinted to compute nodes to pull ahead of time
+	imagePopularity := watchers.NewImagePopularity(watchers.ImagePopularityParams{})
+
 	// connection manager
 	connectionManager, err := transportorchestrator.NewComputeManager(transportorchestrator.Config{
 		NodeID:                  cfg.NodeID,
@@ -301,7 +350,9 @@ func NewRequesterNode(
 			ProtocolRouter: protocolRouter,
 			SubjectFn:      nclprotocol.NatsSubjectComputeInMsgs,
 		}),
-		EventStore: jobStore.GetEventStore(),
+		EventStore:             jobStore.GetEventStore(),
+		ImageHintProvider:      imagePopularity,
+		VolumeDeletionProvider: volumeJanitor,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection manager: %w", err)
@@ -325,7 +376,17 @@ func NewRequesterNode(
 		return nil, fmt.Errorf("failed to register a handler for S3 managed publisher pre-sign url messages: %w", err)
 	}
 
-	watcherRegistry, err := setupOrchestratorWatchers(ctx, jobStore, evalBroker)
+	// Message handler for multipart uploads of large results for S3 managed publisher
+	err = connectionManager.RegisterDataPlaneHandler(
+		ctx,
+		messages.ManagedPublisherMultipartRequestType,
+		s3managed.NewMultipartUploadRequestHandler(s3ManagedPublisherURLGenerator),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register a handler for S3 managed publisher multipart upload messages: %w", err)
+	}
+
+	watcherRegistry, err := setupOrchestratorWatchers(ctx, jobStore, evalBroker, imagePopularity)
 	if err != nil {
 		return nil, err
 	}
@@ -376,11 +437,19 @@ func NewRequesterNode(
 
 		// stop the housekeeping background task
 		housekeeping.Stop(ctx)
+
+		// stop collecting blobs
+		blobJanitor.Stop()
+
 		for _, worker := range workers {
 			worker.Stop()
 		}
 		evalBroker.SetEnabled(false)
 
+		if cleanupErr = blobStore.Close(ctx); cleanupErr != nil {
+			logDebugIfContextCancelled(ctx, cleanupErr, "failed to close blob store")
+		}
+
 		// Close the jobstore after the evaluation broker is disabled
 		cleanupErr = jobStore.Close(ctx)
 		if cleanupErr != nil {
@@ -421,6 +490,8 @@ func createNodeRanker(cfg NodeConfig, jobStore jobstore.Store) (orchestrator.Nod
 		ranking.NewMinVersionNodeRanker(ranking.MinVersionNodeRankerParams{MinVersion: minBacalhauVersion}),
 		ranking.NewPreviousExecutionsNodeRanker(ranking.PreviousExecutionsNodeRankerParams{JobStore: jobStore}),
 		ranking.NewAvailableCapacityNodeRanker(),
+		ranking.NewImageLocalityNodeRanker(),
+		ranking.NewVolumeLocalityNodeRanker(),
 		// arbitrary rankers
 		ranking.NewRandomNodeRanker(ranking.RandomNodeRankerParams{
 			RandomnessRange: cfg.SystemConfig.NodeRankRandomnessRange,
@@ -429,6 +500,27 @@ func createNodeRanker(cfg NodeConfig, jobStore jobstore.Store) (orchestrator.Nod
 	return nodeRankerChain, nil
 }
 
+// createOrchestratorSecretStore creates the store of secrets that jobs reference as "secret:orchestrator/<name>".
+// It returns nil if orchestrator secrets are not enabled.
+func createOrchestratorSecretStore(cfg NodeConfig) (orchestrator.SecretStore, error) {
+	if !cfg.BacalhauConfig.Orchestrator.Secrets.Enabled {
+		return nil, nil
+	}
+	path, keyFile, err := cfg.BacalhauConfig.OrchestratorSecretsPaths()
+	if err != nil {
+		return nil, err
+	}
+	secretStore, err := env.NewFileSecretStore(env.FileSecretStoreParams{
+		Name:    models.SecretBackendOrchestrator,
+		Path:    path,
+		KeyFile: keyFile,
+	})
+	if err != nil {
+		return nil, bacerrors.Wrap(err, "failed to create orchestrator secret store")
+	}
+	return secretStore, nil
+}
+
 func createJobStore(ct context.Context, cfg NodeConfig) (jobstore.Store, error) {
 	jobStoreDBPath, err := cfg.BacalhauConfig.JobStoreFilePath()
 	if err != nil {
@@ -473,6 +565,7 @@ func setupOrchestratorWatchers(
 	ctx context.Context,
 	jobStore jobstore.Store,
 	evalBroker orchestrator.EvaluationBroker,
+	imagePopularity *watchers.ImagePopularity,
 ) (watcher.Manager, error) {
 	watcherRegistry := watcher.NewManager(jobStore.GetEventStore())
 
@@ -505,6 +598,22 @@ func setupOrchestratorWatchers(
 		return nil, fmt.Errorf("failed to setup orchestrator logger watcher: %w", err)
 	}
 
+	// Set up image popularity watcher
+	_, err = watcherRegistry.Create(ctx, orchestratorImagePopularityWatcherID,
+		watcher.WithHandler(imagePopularity),
+		watcher.WithEphemeral(),
+		watcher.WithAutoStart(),
+		watcher.WithInitialEventIterator(watcher.LatestIterator()),
+		watcher.WithRetryStrategy(watcher.RetryStrategySkip),
+		watcher.WithFilter(watcher.EventFilter{
+			ObjectTypes: []string{jobstore.EventObjectExecutionUpsert},
+			Operations:  []watcher.Operation{watcher.OperationCreate},
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup image popularity watcher: %w", err)
+	}
+
 	return watcherRegistry, nil
 }
 
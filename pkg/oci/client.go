@@ -0,0 +1,484 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+)
+
+const (
+	// defaultRegistryHost serves the repositories of the default docker.io domain
+	defaultRegistryHost = "registry-1.docker.io"
+
+	// maxManifestSize limits the size of the manifests read from registries
+	maxManifestSize = 4 * 1024 * 1024
+
+	// mediaTypeDockerLayerGzip is the media type of gzipped layers of docker images
+	mediaTypeDockerLayerGzip = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+)
+
+// manifestMediaTypes are the manifest media types accepted when pulling artifacts
+var manifestMediaTypes = []string{
+	v1.MediaTypeImageManifest,
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// digestMediaTypes are the manifest media types accepted when resolving digests,
+// including the indexes of multi-platform images
+var digestMediaTypes = []string{
+	v1.MediaTypeImageIndex,
+	v1.MediaTypeImageManifest,
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+var errManifestNotFound = errors.New("manifest not found")
+
+// Artifact is the content pushed as an OCI artifact with a single gzipped tar layer
+type Artifact struct {
+	// Layer is the tar.gz archive of the content
+	Layer io.ReaderAt
+	// LayerSize is the size of the archive in bytes
+	LayerSize int64
+	// Annotations are set on the artifact manifest
+	Annotations map[string]string
+	// LayerAnnotations are set on the descriptor of the layer
+	LayerAnnotations map[string]string
+}
+
+type ClientParams struct {
+	// HTTPClient sends the requests to registries. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Credentials authenticate requests to registries that require them
+	Credentials Credentials
+	// CredentialsDomains restricts the credentials to the registries of these domains, e.g. docker.io.
+	// Credentials are sent to all registries if empty.
+	CredentialsDomains []string
+	// InsecureRegistries are the registries, e.g. "localhost:5000", accessed over plain HTTP
+	InsecureRegistries []string
+}
+
+// Client pushes and pulls OCI artifacts, resolves image digests and reads image signatures
+// using the registry HTTP API
+type Client struct {
+	httpClient         *http.Client
+	credentials        Credentials
+	credentialsDomains []string
+	insecureRegistries []string
+
+	mu sync.Mutex
+	// authorizations caches the Authorization header of each repository and scope
+	authorizations map[string]string
+}
+
+func NewClient(params ClientParams) *Client {
+	httpClient := params.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		httpClient:         httpClient,
+		credentials:        params.Credentials,
+		credentialsDomains: params.CredentialsDomains,
+		insecureRegistries: params.InsecureRegistries,
+		authorizations:     make(map[string]string),
+	}
+}
+
+// Push pushes the artifact under the tag, and returns the digest of its manifest
+func (c *Client) Push(ctx context.Context, ref reference.NamedTagged, artifact Artifact) (digest.Digest, error) {
+	layerDigest, err := digest.FromReader(io.NewSectionReader(artifact.Layer, 0, artifact.LayerSize))
+	if err != nil {
+		return "", err
+	}
+	layer := v1.Descriptor{
+		MediaType:   v1.MediaTypeImageLayerGzip,
+		Digest:      layerDigest,
+		Size:        artifact.LayerSize,
+		Annotations: artifact.LayerAnnotations,
+	}
+	config := v1.Descriptor{
+		MediaType: v1.DescriptorEmptyJSON.MediaType,
+		Digest:    v1.DescriptorEmptyJSON.Digest,
+		Size:      v1.DescriptorEmptyJSON.Size,
+	}
+
+	if err = c.pushBlob(ctx, ref, config, bytes.NewReader(v1.DescriptorEmptyJSON.Data)); err != nil {
+		return "", err
+	}
+	if err = c.pushBlob(ctx, ref, layer, artifact.Layer); err != nil {
+		return "", err
+	}
+
+	manifest, err := json.Marshal(v1.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    v1.MediaTypeImageManifest,
+		ArtifactType: ArtifactTypeResult,
+		Config:       config,
+		Layers:       []v1.Descriptor{layer},
+		Annotations:  artifact.Annotations,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.do(ctx, ref, true, request{
+		method:      http.MethodPut,
+		url:         c.repositoryURL(ref) + "/manifests/" + ref.Tag(),
+		contentType: v1.MediaTypeImageManifest,
+		body:        bytes.NewReader(manifest),
+		size:        int64(len(manifest)),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "registry response", resp.Body)
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", newRegistryError(ref, "pushing manifest", resp)
+	}
+	return digest.FromBytes(manifest), nil
+}
+
+// Manifest returns the image manifest the reference points to and its digest.
+// Manifests referenced by digest are verified against it.
+func (c *Client) Manifest(ctx context.Context, ref reference.Named) (v1.Manifest, digest.Digest, error) {
+	resp, err := c.do(ctx, ref, false, request{
+		method: http.MethodGet,
+		url:    c.repositoryURL(ref) + "/manifests/" + manifestReference(ref),
+		accept: manifestMediaTypes,
+	})
+	if err != nil {
+		return v1.Manifest{}, "", err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "registry response", resp.Body)
+	if resp.StatusCode == http.StatusNotFound {
+		return v1.Manifest{}, "", fmt.Errorf("%w: %s", errManifestNotFound, reference.FamiliarString(ref))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return v1.Manifest{}, "", newRegistryError(ref, "fetching manifest", resp)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return v1.Manifest{}, "", err
+	}
+	dgst := digest.FromBytes(content)
+	if digested, ok := ref.(reference.Digested); ok && digested.Digest() != dgst {
+		return v1.Manifest{}, "", fmt.Errorf("manifest of %s does not match its digest", reference.FamiliarString(ref))
+	}
+
+	var manifest v1.Manifest
+	if err = json.Unmarshal(content, &manifest); err != nil {
+		return v1.Manifest{}, "", fmt.Errorf("invalid manifest of %s: %w", reference.FamiliarString(ref), err)
+	}
+	return manifest, dgst, nil
+}
+
+// ResolveDigest returns the digest of the manifest the reference points to, which is the index of
+// multi-platform images. References that are already pinned to a digest are not resolved.
+func (c *Client) ResolveDigest(ctx context.Context, ref reference.Named) (digest.Digest, error) {
+	if digested, ok := ref.(reference.Digested); ok {
+		return digested.Digest(), nil
+	}
+	resp, err := c.do(ctx, ref, false, request{
+		method: http.MethodGet,
+		url:    c.repositoryURL(ref) + "/manifests/" + manifestReference(ref),
+		accept: digestMediaTypes,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "registry response", resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", newRegistryError(ref, "resolving digest", resp)
+	}
+
+	if header := resp.Header.Get("Docker-Content-Digest"); header != "" {
+		return digest.Parse(header)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return "", err
+	}
+	return digest.FromBytes(content), nil
+}
+
+// FetchBlob writes the blob described by the descriptor to w, verifying its size and digest
+func (c *Client) FetchBlob(ctx context.Context, ref reference.Named, descriptor v1.Descriptor, w io.Writer) error {
+	if err := descriptor.Digest.Validate(); err != nil {
+		return fmt.Errorf("invalid blob digest of %s: %w", reference.FamiliarString(ref), err)
+	}
+	resp, err := c.do(ctx, ref, false, request{
+		method: http.MethodGet,
+		url:    c.repositoryURL(ref) + "/blobs/" + descriptor.Digest.String(),
+	})
+	if err != nil {
+		return err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "registry response", resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return newRegistryError(ref, "fetching blob "+descriptor.Digest.String(), resp)
+	}
+
+	verifier := descriptor.Digest.Verifier()
+	// read one more byte than expected to detect blobs larger than their descriptor
+	n, err := io.Copy(io.MultiWriter(w, verifier), io.LimitReader(resp.Body, descriptor.Size+1))
+	if err != nil {
+		return err
+	}
+	if n != descriptor.Size || !verifier.Verified() {
+		return fmt.Errorf("blob %s of %s does not match its descriptor", descriptor.Digest, reference.FamiliarString(ref))
+	}
+	return nil
+}
+
+// ArchiveLayers returns the gzipped tar layers of the manifest, in order
+func ArchiveLayers(manifest v1.Manifest) []v1.Descriptor {
+	var layers []v1.Descriptor
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == v1.MediaTypeImageLayerGzip || layer.MediaType == mediaTypeDockerLayerGzip {
+			layers = append(layers, layer)
+		}
+	}
+	return layers
+}
+
+// pushBlob uploads a blob to the repository unless it already exists, using a monolithic upload
+func (c *Client) pushBlob(ctx context.Context, ref reference.Named, descriptor v1.Descriptor, content io.ReaderAt) error {
+	blobURL := c.repositoryURL(ref) + "/blobs/" + descriptor.Digest.String()
+	resp, err := c.do(ctx, ref, true, request{method: http.MethodHead, url: blobURL})
+	if err != nil {
+		return err
+	}
+	closer.DrainAndCloseWithLogOnError(ctx, "registry response", resp.Body)
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = c.do(ctx, ref, true, request{method: http.MethodPost, url: c.repositoryURL(ref) + "/blobs/uploads/"})
+	if err != nil {
+		return err
+	}
+	closer.DrainAndCloseWithLogOnError(ctx, "registry response", resp.Body)
+	if resp.StatusCode != http.StatusAccepted {
+		return newRegistryError(ref, "starting blob upload", resp)
+	}
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.Header.Get("Location") == "" {
+		return fmt.Errorf("registry returned an invalid upload location for %s", reference.FamiliarString(ref))
+	}
+	query := location.Query()
+	query.Set("digest", descriptor.Digest.String())
+	location.RawQuery = query.Encode()
+
+	resp, err = c.do(ctx, ref, true, request{
+		method:      http.MethodPut,
+		url:         location.String(),
+		contentType: "application/octet-stream",
+		body:        io.NewSectionReader(content, 0, descriptor.Size),
+		size:        descriptor.Size,
+	})
+	if err != nil {
+		return err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "registry response", resp.Body)
+	if resp.StatusCode != http.StatusCreated {
+		return newRegistryError(ref, "uploading blob "+descriptor.Digest.String(), resp)
+	}
+	return nil
+}
+
+type request struct {
+	method      string
+	url         string
+	accept      []string
+	contentType string
+	body        io.ReadSeeker
+	size        int64
+}
+
+// do sends the request, authenticating and retrying once if the registry requires it
+func (c *Client) do(ctx context.Context, ref reference.Named, push bool, r request) (*http.Response, error) {
+	actions := "pull"
+	if push {
+		actions = "pull,push"
+	}
+	cacheKey := reference.Domain(ref) + "/" + reference.Path(ref) + ":" + actions
+
+	c.mu.Lock()
+	authorization := c.authorizations[cacheKey]
+	c.mu.Unlock()
+
+	resp, err := c.send(ctx, r, authorization)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	closer.DrainAndCloseWithLogOnError(ctx, "registry response", resp.Body)
+	authorization, err = c.authorize(ctx, ref, actions, challenge)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.authorizations[cacheKey] = authorization
+	c.mu.Unlock()
+
+	if r.body != nil {
+		if _, err = r.body.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return c.send(ctx, r, authorization)
+}
+
+func (c *Client) send(ctx context.Context, r request, authorization string) (*http.Response, error) {
+	var body io.Reader
+	if r.body != nil {
+		body = r.body
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url, body)
+	if err != nil {
+		return nil, err
+	}
+	if r.body != nil {
+		req.ContentLength = r.size
+	}
+	if len(r.accept) > 0 {
+		req.Header.Set("Accept", strings.Join(r.accept, ", "))
+	}
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return c.httpClient.Do(req)
+}
+
+// authorize returns the Authorization header answering the challenge of the registry,
+// requesting a bearer token from its authorization server if needed
+func (c *Client) authorize(ctx context.Context, ref reference.Named, actions string, challenge string) (string, error) {
+	credentials, hasCredentials := c.credentialsFor(ref)
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if strings.EqualFold(scheme, "Basic") {
+		if !hasCredentials {
+			return "", fmt.Errorf("registry of %s requires credentials", reference.FamiliarString(ref))
+		}
+		return "Basic " + credentials.basicAuth(), nil
+	}
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported registry authentication scheme %q for %s", scheme, reference.FamiliarString(ref))
+	}
+
+	attributes := parseChallenge(params)
+	realm, err := url.Parse(attributes["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid registry authentication realm %q", attributes["realm"])
+	}
+	query := realm.Query()
+	if service := attributes["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:%s", reference.Path(ref), actions))
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if hasCredentials {
+		req.Header.Set("Authorization", "Basic "+credentials.basicAuth())
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "registry response", resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry authentication returned %s for %s", resp.Status, reference.FamiliarString(ref))
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid registry authentication response: %w", err)
+	}
+	if body.Token == "" {
+		body.Token = body.AccessToken
+	}
+	return "Bearer " + body.Token, nil
+}
+
+// credentialsFor returns the credentials to authenticate to the registry of the reference, if any
+func (c *Client) credentialsFor(ref reference.Named) (Credentials, bool) {
+	if !c.credentials.IsValid() {
+		return Credentials{}, false
+	}
+	if len(c.credentialsDomains) > 0 && !slices.Contains(c.credentialsDomains, reference.Domain(ref)) {
+		return Credentials{}, false
+	}
+	return c.credentials, true
+}
+
+// manifestReference returns the tag or digest of the manifest the reference points to
+func manifestReference(ref reference.Named) string {
+	if digested, ok := ref.(reference.Digested); ok {
+		return digested.Digest().String()
+	}
+	if tagged, ok := ref.(reference.Tagged); ok {
+		return tagged.Tag()
+	}
+	return "latest"
+}
+
+func (c *Client) repositoryURL(ref reference.Named) string {
+	host := reference.Domain(ref)
+	scheme := "https"
+	if slices.Contains(c.insecureRegistries, host) {
+		scheme = "http"
+	}
+	if host == "docker.io" {
+		host = defaultRegistryHost
+	}
+	return fmt.Sprintf("%s://%s/v2/%s", scheme, host, reference.Path(ref))
+}
+
+func newRegistryError(ref reference.Named, action string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10)) //nolint:mnd // 1KB of context is enough
+	return fmt.Errorf("registry returned %s %s of %s: %s",
+		resp.Status, action, reference.FamiliarString(ref), strings.TrimSpace(string(body)))
+}
+
+// parseChallenge parses the comma separated key="value" attributes of an authentication challenge
+func parseChallenge(params string) map[string]string {
+	attributes := make(map[string]string)
+	for params != "" {
+		var key, value string
+		key, params, _ = strings.Cut(strings.TrimLeft(params, ", "), "=")
+		if strings.HasPrefix(params, `"`) {
+			value, params, _ = strings.Cut(params[1:], `"`)
+		} else {
+			value, params, _ = strings.Cut(params, ",")
+		}
+		attributes[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return attributes
+}
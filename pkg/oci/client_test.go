@@ -0,0 +1,268 @@
+//go:build unit || !integration
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+type ClientTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	registry *TestRegistry
+	client   *Client
+}
+
+func TestClientTestSuite(t *testing.T) {
+	suite.Run(t, new(ClientTestSuite))
+}
+
+func (s *ClientTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.registry = NewTestRegistry(s.T())
+	s.client = NewClient(ClientParams{InsecureRegistries: []string{s.registry.Host()}})
+}
+
+func (s *ClientTestSuite) tagged(repository, tag string) reference.NamedTagged {
+	named, err := ParseRepository(s.registry.Host() + "/" + repository)
+	s.Require().NoError(err)
+	tagged, err := reference.WithTag(named, tag)
+	s.Require().NoError(err)
+	return tagged
+}
+
+func (s *ClientTestSuite) push(ref reference.NamedTagged, content []byte) digest.Digest {
+	dgst, err := s.client.Push(s.ctx, ref, Artifact{
+		Layer:            bytes.NewReader(content),
+		LayerSize:        int64(len(content)),
+		Annotations:      map[string]string{AnnotationJobID: "job-1"},
+		LayerAnnotations: map[string]string{v1.AnnotationTitle: "results.tar.gz"},
+	})
+	s.Require().NoError(err)
+	return dgst
+}
+
+func (s *ClientTestSuite) TestPushAndPull() {
+	ref := s.tagged("results", "v1")
+	content := []byte("layer content")
+	dgst := s.push(ref, content)
+
+	manifest := s.registry.Manifest("results", "v1")
+	s.Equal(ArtifactTypeResult, manifest.ArtifactType)
+	s.Equal("job-1", manifest.Annotations[AnnotationJobID])
+	s.Equal(v1.DescriptorEmptyJSON.Digest, manifest.Config.Digest)
+	s.Require().Len(manifest.Layers, 1)
+	s.Equal(v1.MediaTypeImageLayerGzip, manifest.Layers[0].MediaType)
+	s.Equal("results.tar.gz", manifest.Layers[0].Annotations[v1.AnnotationTitle])
+
+	// pull by tag and by digest
+	for _, ref := range []string{ref.String(), ref.Name() + "@" + dgst.String()} {
+		named, err := ParseReference(ref)
+		s.Require().NoError(err)
+		pulled, pulledDigest, err := s.client.Manifest(s.ctx, named)
+		s.Require().NoError(err)
+		s.Equal(dgst, pulledDigest)
+
+		layers := ArchiveLayers(pulled)
+		s.Require().Len(layers, 1)
+		var buf bytes.Buffer
+		s.Require().NoError(s.client.FetchBlob(s.ctx, named, layers[0], &buf))
+		s.Equal(content, buf.Bytes())
+	}
+}
+
+func (s *ClientTestSuite) TestPushExistingBlob() {
+	content := []byte("shared layer")
+	first := s.push(s.tagged("results", "v1"), content)
+	second := s.push(s.tagged("results", "v2"), content)
+	s.Equal(first, second)
+}
+
+func (s *ClientTestSuite) TestPullMissing() {
+	named, err := ParseReference(s.registry.Host() + "/results:missing")
+	s.Require().NoError(err)
+	_, _, err = s.client.Manifest(s.ctx, named)
+	s.Require().Error(err)
+}
+
+func (s *ClientTestSuite) TestFetchBlobVerifiesDigest() {
+	ref := s.tagged("results", "v1")
+	s.push(ref, []byte("layer content"))
+
+	descriptor := s.registry.PushBlob("results", v1.MediaTypeImageLayerGzip, []byte("tampered"))
+	descriptor.Digest = digest.FromString("something else")
+	s.Require().Error(s.client.FetchBlob(s.ctx, ref, descriptor, &bytes.Buffer{}))
+
+	descriptor = s.registry.PushBlob("results", v1.MediaTypeImageLayerGzip, []byte("tampered"))
+	descriptor.Size--
+	s.Require().Error(s.client.FetchBlob(s.ctx, ref, descriptor, &bytes.Buffer{}))
+}
+
+func (s *ClientTestSuite) TestManifestVerifiesDigest() {
+	s.registry.PushManifest("results", "v1", v1.Manifest{Versioned: specs.Versioned{SchemaVersion: 2}})
+	named, err := ParseReference(s.registry.Host() + "/results@" + digest.FromString("other").String())
+	s.Require().NoError(err)
+	_, _, err = s.client.Manifest(s.ctx, named)
+	s.Require().Error(err)
+}
+
+func (s *ClientTestSuite) TestTokenAuthentication() {
+	credentials := Credentials{Username: "user", Password: "pass"}
+	s.registry.RequireToken("secret-token", credentials)
+
+	// pushing without credentials fails
+	_, err := s.client.Push(s.ctx, s.tagged("results", "v1"), Artifact{Layer: bytes.NewReader(nil)})
+	s.Require().Error(err)
+
+	s.client = NewClient(ClientParams{
+		Credentials:        credentials,
+		InsecureRegistries: []string{s.registry.Host()},
+	})
+	s.push(s.tagged("results", "v1"), []byte("layer content"))
+	named, err := ParseReference(s.registry.Host() + "/results:v1")
+	s.Require().NoError(err)
+	_, _, err = s.client.Manifest(s.ctx, named)
+	s.Require().NoError(err)
+}
+
+func (s *ClientTestSuite) TestCredentialsDomains() {
+	credentials := Credentials{Username: "user", Password: "pass"}
+	s.registry.RequireToken("secret-token", credentials)
+
+	// credentials are not sent to the registries of other domains
+	s.client = NewClient(ClientParams{
+		Credentials:        credentials,
+		CredentialsDomains: []string{"docker.io"},
+		InsecureRegistries: []string{s.registry.Host()},
+	})
+	_, err := s.client.Push(s.ctx, s.tagged("results", "v1"), Artifact{Layer: bytes.NewReader(nil)})
+	s.Require().Error(err)
+
+	s.client = NewClient(ClientParams{
+		Credentials:        credentials,
+		CredentialsDomains: []string{s.registry.Host()},
+		InsecureRegistries: []string{s.registry.Host()},
+	})
+	s.push(s.tagged("results", "v1"), []byte("layer content"))
+}
+
+func (s *ClientTestSuite) image(image string) reference.Named {
+	ref, err := reference.ParseNormalizedNamed(s.registry.Host() + "/" + image)
+	s.Require().NoError(err)
+	return ref
+}
+
+func (s *ClientTestSuite) TestResolveDigest() {
+	expected := s.registry.PushImage("app", "v1", "v1")
+	s.registry.PushImage("app", "latest", "latest")
+
+	dgst, err := s.client.ResolveDigest(s.ctx, s.image("app:v1"))
+	s.Require().NoError(err)
+	s.Equal(expected, dgst)
+
+	// images without a tag resolve the latest tag
+	dgst, err = s.client.ResolveDigest(s.ctx, s.image("app"))
+	s.Require().NoError(err)
+	s.NotEqual(expected, dgst)
+
+	// images pinned to a digest are not resolved
+	pinned := digest.FromString("pinned")
+	dgst, err = s.client.ResolveDigest(s.ctx, s.image("missing@"+pinned.String()))
+	s.Require().NoError(err)
+	s.Equal(pinned, dgst)
+
+	_, err = s.client.ResolveDigest(s.ctx, s.image("missing:v1"))
+	s.Error(err)
+
+	// anonymous clients are issued tokens by registries that require them
+	s.registry.RequireToken("secret-token", Credentials{})
+	dgst, err = s.client.ResolveDigest(s.ctx, s.image("app:v1"))
+	s.Require().NoError(err)
+	s.Equal(expected, dgst)
+}
+
+func (s *ClientTestSuite) TestSignatures() {
+	dgst := s.registry.PushImage("app", "v1", "v1")
+
+	signatures, err := s.client.Signatures(s.ctx, s.image("app:v1"), dgst)
+	s.Require().NoError(err)
+	s.Empty(signatures, "unsigned images have no signatures")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+	s.registry.SignImage("app", dgst, key)
+
+	signatures, err = s.client.Signatures(s.ctx, s.image("app:v1"), dgst)
+	s.Require().NoError(err)
+	s.Require().Len(signatures, 1)
+	signed, err := signatures[0].SignedManifestDigest()
+	s.Require().NoError(err)
+	s.Equal(dgst, signed)
+	s.NotEmpty(signatures[0].Signature)
+}
+
+func (s *ClientTestSuite) TestPublisherSpecReference() {
+	execution := &models.Execution{ID: "e-123", JobID: "j-456", NodeID: "n-789"}
+	for _, tc := range []struct {
+		name     string
+		spec     PublisherSpec
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "default tag",
+			spec:     PublisherSpec{Repository: "ghcr.io/org/results"},
+			expected: "ghcr.io/org/results:e-123",
+		},
+		{
+			name:     "templated tag",
+			spec:     PublisherSpec{Repository: "localhost:5000/results", Tag: "{jobID}-{nodeID}"},
+			expected: "localhost:5000/results:j-456-n-789",
+		},
+		{
+			name:     "docker hub repository",
+			spec:     PublisherSpec{Repository: "org/results", Tag: "latest"},
+			expected: "docker.io/org/results:latest",
+		},
+		{
+			name:    "invalid tag",
+			spec:    PublisherSpec{Repository: "ghcr.io/org/results", Tag: "in valid"},
+			wantErr: true,
+		},
+	} {
+		s.Run(tc.name, func() {
+			ref, err := tc.spec.Reference(execution)
+			if tc.wantErr {
+				s.Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(tc.expected, ref.String())
+		})
+	}
+}
+
+func (s *ClientTestSuite) TestValidateSpecs() {
+	s.NoError(PublisherSpec{Repository: "ghcr.io/org/results"}.Validate())
+	s.Error(PublisherSpec{}.Validate())
+	s.Error(PublisherSpec{Repository: "ghcr.io/org/results:v1"}.Validate())
+	s.Error(PublisherSpec{Repository: "Invalid Repository"}.Validate())
+
+	s.NoError(SourceSpec{Reference: "ghcr.io/org/dataset:v1"}.Validate())
+	s.NoError(SourceSpec{Reference: "ghcr.io/org/dataset@" + digest.FromString("x").String()}.Validate())
+	s.Error(SourceSpec{}.Validate())
+	s.Error(SourceSpec{Reference: "ghcr.io/org/dataset@sha256:invalid"}.Validate())
+}
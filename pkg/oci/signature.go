@@ -0,0 +1,77 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+)
+
+// cosignSignatureAnnotation holds the base64 encoded signature of a cosign signature layer
+const cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// ImageSignature is a cosign signature of an image
+type ImageSignature struct {
+	// Payload is the signed payload, which references the digest of the signed image
+	Payload []byte
+	// Signature is the signature of the payload
+	Signature []byte
+}
+
+// SignedManifestDigest returns the digest of the image manifest the signature payload refers to
+func (s ImageSignature) SignedManifestDigest() (digest.Digest, error) {
+	var payload struct {
+		Critical struct {
+			Image struct {
+				DockerManifestDigest digest.Digest `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(s.Payload, &payload); err != nil {
+		return "", fmt.Errorf("invalid signature payload: %w", err)
+	}
+	return payload.Critical.Image.DockerManifestDigest, payload.Critical.Image.DockerManifestDigest.Validate()
+}
+
+// Signatures returns the cosign signatures of the image manifest with the given digest,
+// which cosign stores in the image repository under the sha256-<hash>.sig tag.
+// No signatures are returned for unsigned images.
+func (c *Client) Signatures(ctx context.Context, ref reference.Named, dgst digest.Digest) ([]ImageSignature, error) {
+	signaturesRef, err := reference.WithTag(reference.TrimNamed(ref), fmt.Sprintf("%s-%s.sig", dgst.Algorithm(), dgst.Encoded()))
+	if err != nil {
+		return nil, err
+	}
+	manifest, _, err := c.Manifest(ctx, signaturesRef)
+	if errors.Is(err, errManifestNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := make([]ImageSignature, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		encoded, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature of %s: %w", reference.FamiliarString(ref), err)
+		}
+		if layer.Size > maxManifestSize {
+			return nil, fmt.Errorf("signature payload %s of %s is too large", layer.Digest, reference.FamiliarString(ref))
+		}
+		var payload bytes.Buffer
+		if err = c.FetchBlob(ctx, ref, layer, &payload); err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, ImageSignature{Payload: payload.Bytes(), Signature: signature})
+	}
+	return signatures, nil
+}
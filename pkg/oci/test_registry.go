@@ -0,0 +1,260 @@
+package oci
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// cosignPayloadMediaType is the media type of cosign signature payloads
+const cosignPayloadMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+// TestRegistry is an in-memory registry serving the registry HTTP API over plain HTTP,
+// to test pushing and pulling artifacts, resolving image digests and verifying image
+// signatures against a local registry.
+type TestRegistry struct {
+	t      testing.TB
+	server *httptest.Server
+
+	mu        sync.Mutex
+	manifests map[string][]byte
+	blobs     map[string][]byte
+	uploads   map[string]string
+	token     string
+	username  string
+	password  string
+}
+
+// NewTestRegistry starts a registry that is closed when the test ends
+func NewTestRegistry(t testing.TB) *TestRegistry {
+	r := &TestRegistry{
+		t:         t,
+		manifests: make(map[string][]byte),
+		blobs:     make(map[string][]byte),
+		uploads:   make(map[string]string),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+// Host returns the host of the registry, which prefixes the names of its repositories
+func (r *TestRegistry) Host() string {
+	return strings.TrimPrefix(r.server.URL, "http://")
+}
+
+// RequireToken makes the registry require a bearer token, issued by its token endpoint
+// to clients authenticating with the given credentials
+func (r *TestRegistry) RequireToken(token string, credentials Credentials) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.token = token
+	r.username = credentials.Username
+	r.password = credentials.Password
+}
+
+// Manifest returns the manifest pushed to the repository under the tag or digest
+func (r *TestRegistry) Manifest(repository, ref string) v1.Manifest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	content, ok := r.manifests[manifestKey(repository, ref)]
+	require.True(r.t, ok, "manifest %s not found in %s", ref, repository)
+	var manifest v1.Manifest
+	require.NoError(r.t, json.Unmarshal(content, &manifest))
+	return manifest
+}
+
+// PushManifest pushes a manifest to the repository under the tag, and returns its digest
+func (r *TestRegistry) PushManifest(repository, tag string, manifest v1.Manifest) digest.Digest {
+	content, err := json.Marshal(manifest)
+	require.NoError(r.t, err)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dgst := digest.FromBytes(content)
+	r.manifests[manifestKey(repository, tag)] = content
+	r.manifests[manifestKey(repository, dgst.String())] = content
+	return dgst
+}
+
+// PushBlob pushes a blob to the repository, and returns its descriptor
+func (r *TestRegistry) PushBlob(repository, mediaType string, content []byte) v1.Descriptor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dgst := digest.FromBytes(content)
+	r.blobs[repository+"@"+dgst.String()] = content
+	return v1.Descriptor{MediaType: mediaType, Digest: dgst, Size: int64(len(content))}
+}
+
+// PushImage pushes an image manifest with the given content under the tag, and returns its digest
+func (r *TestRegistry) PushImage(repository, tag, content string) digest.Digest {
+	return r.PushManifest(repository, tag, v1.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: v1.MediaTypeImageManifest,
+		Config:    r.PushBlob(repository, v1.MediaTypeImageConfig, []byte(content)),
+		Layers:    []v1.Descriptor{},
+	})
+}
+
+// SignImage pushes a cosign signature of the image manifest with the digest, signed by the key
+func (r *TestRegistry) SignImage(repository string, dgst digest.Digest, key crypto.Signer) {
+	payload, err := json.Marshal(map[string]any{
+		"critical": map[string]any{
+			"identity": map[string]string{"docker-reference": r.Host() + "/" + repository},
+			"image":    map[string]string{"docker-manifest-digest": dgst.String()},
+			"type":     "cosign container image signature",
+		},
+		"optional": nil,
+	})
+	require.NoError(r.t, err)
+
+	sum := sha256.Sum256(payload)
+	signature, err := key.Sign(rand.Reader, sum[:], crypto.SHA256)
+	require.NoError(r.t, err)
+
+	layer := r.PushBlob(repository, cosignPayloadMediaType, payload)
+	layer.Annotations = map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)}
+	r.PushManifest(repository, fmt.Sprintf("%s-%s.sig", dgst.Algorithm(), dgst.Encoded()), v1.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: v1.MediaTypeImageManifest,
+		Config:    r.PushBlob(repository, v1.MediaTypeImageConfig, []byte("{}")),
+		Layers:    []v1.Descriptor{layer},
+	})
+}
+
+func (r *TestRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Path == "/token" {
+		username, password, _ := req.BasicAuth()
+		if username != r.username || password != r.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": r.token})
+		return
+	}
+	if r.token != "" && req.Header.Get("Authorization") != "Bearer "+r.token {
+		w.Header().Set("WWW-Authenticate",
+			fmt.Sprintf(`Bearer realm="%s/token",service="test-registry"`, r.server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	if repository, ref, ok := strings.Cut(path, "/manifests/"); ok {
+		r.serveManifest(w, req, repository, ref)
+		return
+	}
+	if repository, id, ok := strings.Cut(path, "/blobs/uploads/"); ok {
+		r.serveUpload(w, req, repository, id)
+		return
+	}
+	if repository, dgst, ok := strings.Cut(path, "/blobs/"); ok {
+		content, found := r.blobs[repository+"@"+dgst]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(content)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (r *TestRegistry) serveManifest(w http.ResponseWriter, req *http.Request, repository, ref string) {
+	key := manifestKey(repository, ref)
+	switch req.Method {
+	case http.MethodPut:
+		content, err := io.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var manifest v1.Manifest
+		if err = json.Unmarshal(content, &manifest); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// registries reject manifests referencing blobs they don't have
+		for _, descriptor := range append([]v1.Descriptor{manifest.Config}, manifest.Layers...) {
+			if _, found := r.blobs[repository+"@"+descriptor.Digest.String()]; !found {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		dgst := digest.FromBytes(content)
+		r.manifests[key] = content
+		r.manifests[manifestKey(repository, dgst.String())] = content
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet, http.MethodHead:
+		content, found := r.manifests[key]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", v1.MediaTypeImageManifest)
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(content).String())
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(content)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (r *TestRegistry) serveUpload(w http.ResponseWriter, req *http.Request, repository, id string) {
+	switch req.Method {
+	case http.MethodPost:
+		id = uuid.NewString()
+		r.uploads[id] = repository
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%s", repository, id))
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodPut:
+		if r.uploads[id] != repository {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		content, err := io.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		dgst := digest.FromBytes(content)
+		if dgst.String() != req.URL.Query().Get("digest") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		delete(r.uploads, id)
+		r.blobs[repository+"@"+dgst.String()] = content
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func manifestKey(repository, ref string) string {
+	if strings.Contains(ref, ":") {
+		return repository + "@" + ref
+	}
+	return repository + ":" + ref
+}
@@ -0,0 +1,202 @@
+package oci
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/fatih/structs"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+const (
+	// ArtifactTypeResult is the artifact type of job results published as OCI artifacts
+	ArtifactTypeResult = "application/vnd.bacalhau.result.v1"
+
+	// AnnotationJobID holds the ID of the job that produced a published result
+	AnnotationJobID = "io.bacalhau.job.id"
+	// AnnotationExecutionID holds the ID of the execution that produced a published result
+	AnnotationExecutionID = "io.bacalhau.execution.id"
+	// AnnotationNodeID holds the ID of the compute node that published a result
+	AnnotationNodeID = "io.bacalhau.node.id"
+	// AnnotationUncompressedSize holds the size in bytes of the content of a layer once unpacked
+	AnnotationUncompressedSize = "io.bacalhau.content.size"
+
+	// DefaultTag is the tag results are published under if the publisher spec doesn't set one
+	DefaultTag = "{executionID}"
+
+	// UsernameEnvVar and PasswordEnvVar hold the credentials used to authenticate to registries
+	UsernameEnvVar = "OCI_REGISTRY_USERNAME"
+	PasswordEnvVar = "OCI_REGISTRY_PASSWORD"
+
+	errComponent = "OCI"
+)
+
+type Credentials struct {
+	Username string
+	Password string
+}
+
+func (c Credentials) IsValid() bool {
+	return c.Username != "" && c.Password != ""
+}
+
+func (c Credentials) basicAuth() string {
+	return base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Password))
+}
+
+// GetCredentials returns the registry credentials set in the environment
+func GetCredentials() Credentials {
+	return Credentials{
+		Username: os.Getenv(UsernameEnvVar),
+		Password: os.Getenv(PasswordEnvVar),
+	}
+}
+
+// PublisherSpec is the configuration of the OCI publisher in a job spec
+type PublisherSpec struct {
+	// Repository is where results are pushed, e.g. ghcr.io/my-org/results
+	Repository string
+	// Tag is the tag results are pushed under. It supports the {jobID}, {executionID}, {nodeID},
+	// {date} and {time} placeholders, and defaults to DefaultTag.
+	Tag string
+	// Annotations are added to the manifest of the artifact, in addition to the job, execution and node IDs
+	Annotations map[string]string
+}
+
+func (c PublisherSpec) Validate() error {
+	if c.Repository == "" {
+		return newValidationError("invalid oci publisher params: repository cannot be empty")
+	}
+	if _, err := ParseRepository(c.Repository); err != nil {
+		return newValidationError(fmt.Sprintf("invalid oci publisher params: %s", err))
+	}
+	return nil
+}
+
+func (c PublisherSpec) ToMap() map[string]interface{} {
+	return structs.Map(c)
+}
+
+// Reference returns the reference results of the execution are pushed to
+func (c PublisherSpec) Reference(execution *models.Execution) (reference.NamedTagged, error) {
+	repository, err := ParseRepository(c.Repository)
+	if err != nil {
+		return nil, err
+	}
+	tag := c.Tag
+	if tag == "" {
+		tag = DefaultTag
+	}
+	tag = strings.NewReplacer(
+		"{nodeID}", execution.NodeID,
+		"{executionID}", execution.ID,
+		"{jobID}", execution.JobID,
+		"{date}", time.Now().Format("20060102"),
+		"{time}", time.Now().Format("150405"),
+	).Replace(tag)
+	tagged, err := reference.WithTag(repository, tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oci tag %q: %w", tag, err)
+	}
+	return tagged, nil
+}
+
+func DecodePublisherSpec(spec *models.SpecConfig) (PublisherSpec, error) {
+	if !spec.IsType(models.PublisherOCI) {
+		return PublisherSpec{}, newValidationError(
+			fmt.Sprintf("invalid publisher type. expected %s, but received: %s", models.PublisherOCI, spec.Type))
+	}
+	if spec.Params == nil {
+		return PublisherSpec{}, newValidationError("invalid publisher params. cannot be nil")
+	}
+
+	var c PublisherSpec
+	if err := mapstructure.Decode(spec.Params, &c); err != nil {
+		return c, err
+	}
+	return c, c.Validate()
+}
+
+// SourceSpec references an OCI artifact, either as an input source or as a published result
+type SourceSpec struct {
+	// Reference is the artifact reference by tag or digest, e.g. ghcr.io/my-org/results:v1
+	// or ghcr.io/my-org/results@sha256:...
+	Reference string
+}
+
+func (c SourceSpec) Validate() error {
+	if c.Reference == "" {
+		return newValidationError("invalid oci storage params: reference cannot be empty")
+	}
+	if _, err := ParseReference(c.Reference); err != nil {
+		return newValidationError(fmt.Sprintf("invalid oci storage params: %s", err))
+	}
+	return nil
+}
+
+func (c SourceSpec) ToMap() map[string]interface{} {
+	return structs.Map(c)
+}
+
+func DecodeSourceSpec(spec *models.SpecConfig) (SourceSpec, error) {
+	if !spec.IsType(models.StorageSourceOCI) {
+		return SourceSpec{}, newValidationError(
+			fmt.Sprintf("invalid storage source type. expected %s, but received: %s", models.StorageSourceOCI, spec.Type))
+	}
+	if spec.Params == nil {
+		return SourceSpec{}, newValidationError("invalid storage source params. cannot be nil")
+	}
+
+	var c SourceSpec
+	if err := mapstructure.Decode(spec.Params, &c); err != nil {
+		return c, err
+	}
+	return c, c.Validate()
+}
+
+// NewSourceSpecConfig returns the spec of an OCI input source
+func NewSourceSpecConfig(ref string) (*models.SpecConfig, error) {
+	s := SourceSpec{Reference: ref}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &models.SpecConfig{
+		Type:   models.StorageSourceOCI,
+		Params: s.ToMap(),
+	}, nil
+}
+
+// ParseReference parses an artifact reference. References without a tag or digest resolve the latest tag.
+func ParseReference(ref string) (reference.Named, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oci reference %q: %w", ref, err)
+	}
+	return reference.TagNameOnly(named), nil
+}
+
+// ParseRepository parses a repository name, which cannot have a tag or digest
+func ParseRepository(repository string) (reference.Named, error) {
+	named, err := reference.ParseNormalizedNamed(repository)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oci repository %q: %w", repository, err)
+	}
+	if !reference.IsNameOnly(named) {
+		return nil, errors.New("oci repository cannot have a tag or digest: " + repository)
+	}
+	return named, nil
+}
+
+func newValidationError(message string) bacerrors.Error {
+	return bacerrors.New(message).
+		WithComponent(errComponent).
+		WithCode(bacerrors.ValidationError)
+}
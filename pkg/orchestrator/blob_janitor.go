@@ -0,0 +1,177 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/blobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/validate"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/blob"
+)
+
+const (
+	// DefaultBlobCollectionInterval is how often blobs that are no longer referenced are deleted
+	DefaultBlobCollectionInterval = time.Hour
+	// DefaultBlobRetention is how long blobs are kept after they were committed,
+	// and after the last job referencing them ended
+	DefaultBlobRetention = 24 * time.Hour
+)
+
+type BlobJanitorParams struct {
+	JobStore  jobstore.Store
+	BlobStore blobstore.Store
+	// Interval is how often blobs are collected. Defaults to DefaultBlobCollectionInterval.
+	Interval time.Duration
+	// Retention is how long blobs are kept once they are no longer referenced. Defaults to DefaultBlobRetention.
+	Retention time.Duration
+	Clock     clock.Clock
+}
+
+// BlobJanitor deletes the blobs uploaded as job inputs that are no longer referenced by jobs.
+// Blobs are kept while they are referenced by jobs that did not end, and for a retention period after
+// they were committed, so that clients can submit the jobs using them after uploading them, and after
+// the jobs referencing them ended, so that finished jobs can be rerun. Blobs of stopped and deleted
+// jobs are deleted once the retention period is over.
+type BlobJanitor struct {
+	jobStore  jobstore.Store
+	blobStore blobstore.Store
+	interval  time.Duration
+	retention time.Duration
+	clock     clock.Clock
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopChan  chan struct{}
+}
+
+// NewBlobJanitor creates a new BlobJanitor instance
+func NewBlobJanitor(params BlobJanitorParams) (*BlobJanitor, error) {
+	if params.Interval <= 0 {
+		params.Interval = DefaultBlobCollectionInterval
+	}
+	if params.Retention <= 0 {
+		params.Retention = DefaultBlobRetention
+	}
+	if params.Clock == nil {
+		params.Clock = clock.New()
+	}
+	err := errors.Join(
+		validate.NotNil(params.JobStore, "job store cannot be nil"),
+		validate.NotNil(params.BlobStore, "blob store cannot be nil"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error validating blob janitor params: %w", err)
+	}
+	return &BlobJanitor{
+		jobStore:  params.JobStore,
+		blobStore: params.BlobStore,
+		interval:  params.Interval,
+		retention: params.Retention,
+		clock:     params.Clock,
+		stopChan:  make(chan struct{}),
+	}, nil
+}
+
+// Start starts collecting blobs periodically
+func (j *BlobJanitor) Start(ctx context.Context) {
+	j.startOnce.Do(func() {
+		go j.run(ctx)
+	})
+}
+
+// Stop stops collecting blobs
+func (j *BlobJanitor) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stopChan)
+	})
+}
+
+func (j *BlobJanitor) run(ctx context.Context) {
+	ticker := j.clock.Ticker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := j.Collect(ctx); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("Failed to collect unreferenced blobs")
+			}
+		case <-ctx.Done():
+			return
+		case <-j.stopChan:
+			return
+		}
+	}
+}
+
+// Collect deletes the blobs that are past their retention period and not referenced by jobs
+func (j *BlobJanitor) Collect(ctx context.Context) error {
+	blobs, err := j.blobStore.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list blobs: %w", err)
+	}
+	cutoff := j.clock.Now().Add(-j.retention)
+	var expired []blobstore.Info
+	for _, info := range blobs {
+		if info.Committed.Before(cutoff) {
+			expired = append(expired, info)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	// blobs are listed before jobs, so that the jobs submitted with blobs that expired meanwhile are seen
+	referenced, err := j.referencedBlobs(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+	var errs error
+	for _, info := range expired {
+		if _, ok := referenced[info.Digest]; ok {
+			continue
+		}
+		if err = j.blobStore.Delete(ctx, info.Digest); err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		log.Ctx(ctx).Debug().Str("digest", info.Digest).Msg("Deleted unreferenced blob")
+	}
+	return errs
+}
+
+// referencedBlobs returns the digests of the blobs referenced by jobs that did not end,
+// or that ended after cutoff
+func (j *BlobJanitor) referencedBlobs(ctx context.Context, cutoff time.Time) (map[string]struct{}, error) {
+	response, err := j.jobStore.GetJobs(ctx, jobstore.JobQuery{ReturnAll: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	referenced := make(map[string]struct{})
+	for i := range response.Jobs {
+		job := &response.Jobs[i]
+		if job.IsTerminal() && job.GetModifyTime().Before(cutoff) {
+			continue
+		}
+		for _, task := range job.Tasks {
+			for _, input := range task.InputSources {
+				if input == nil || input.Source == nil || !input.Source.IsType(models.StorageSourceBlob) {
+					continue
+				}
+				source, err := blob.DecodeSpec(input.Source)
+				if err != nil {
+					continue
+				}
+				referenced[source.Digest] = struct{}{}
+			}
+		}
+	}
+	return referenced, nil
+}
@@ -0,0 +1,139 @@
+//go:build unit || !integration
+
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/blobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/blob"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+// committedBlobs is a blob store that only records when blobs were committed
+type committedBlobs map[string]time.Time
+
+func (b committedBlobs) Stat(_ context.Context, digest string) (blobstore.Info, error) {
+	committed, ok := b[digest]
+	if !ok {
+		return blobstore.Info{}, blobstore.NewErrBlobNotFound(digest)
+	}
+	return blobstore.Info{Digest: digest, Committed: committed}, nil
+}
+
+func (b committedBlobs) PutChunk(context.Context, string, int, []byte) error { return nil }
+
+func (b committedBlobs) Commit(ctx context.Context, digest string, _ int) (blobstore.Info, error) {
+	return b.Stat(ctx, digest)
+}
+
+func (b committedBlobs) Get(ctx context.Context, digest string, _ io.Writer) (blobstore.Info, error) {
+	return b.Stat(ctx, digest)
+}
+
+func (b committedBlobs) List(ctx context.Context) ([]blobstore.Info, error) {
+	var infos []blobstore.Info
+	for digest := range b {
+		info, _ := b.Stat(ctx, digest)
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (b committedBlobs) Delete(_ context.Context, digest string) error {
+	delete(b, digest)
+	return nil
+}
+
+type BlobJanitorTestSuite struct {
+	suite.Suite
+	ctx          context.Context
+	clock        *clock.Mock
+	mockJobStore *jobstore.MockStore
+	blobs        committedBlobs
+	janitor      *BlobJanitor
+}
+
+func TestBlobJanitorTestSuite(t *testing.T) {
+	suite.Run(t, new(BlobJanitorTestSuite))
+}
+
+func (s *BlobJanitorTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.clock = clock.NewMock()
+	s.clock.Set(time.Now())
+	s.mockJobStore = jobstore.NewMockStore(gomock.NewController(s.T()))
+	s.blobs = make(committedBlobs)
+
+	var err error
+	s.janitor, err = NewBlobJanitor(BlobJanitorParams{
+		JobStore:  s.mockJobStore,
+		BlobStore: s.blobs,
+		Retention: time.Hour,
+		Clock:     s.clock,
+	})
+	s.Require().NoError(err)
+}
+
+// digest returns the digest of content
+func (s *BlobJanitorTestSuite) digest(content string) string {
+	digest, _, err := blobstore.Digest(bytes.NewReader([]byte(content)))
+	s.Require().NoError(err)
+	return digest
+}
+
+// job returns a job in the state, last modified at modified, with an input of the blob
+func (s *BlobJanitorTestSuite) job(state models.JobStateType, modified time.Time, digest string) models.Job {
+	job := mock.Job()
+	job.State = models.NewJobState(state)
+	job.ModifyTime = modified.UnixNano()
+	spec, err := blob.NewSpecConfig(blob.SourceSpec{Digest: digest})
+	s.Require().NoError(err)
+	job.Task().InputSources = []*models.InputSource{{Source: spec, Target: "/inputs"}}
+	return *job
+}
+
+func (s *BlobJanitorTestSuite) TestCollect() {
+	now := s.clock.Now()
+	old := now.Add(-2 * time.Hour)
+	running, recent, finished, stopped, unreferenced, uploaded :=
+		s.digest("running"), s.digest("recent"), s.digest("finished"),
+		s.digest("stopped"), s.digest("unreferenced"), s.digest("uploaded")
+	for _, digest := range []string{running, recent, finished, stopped, unreferenced} {
+		s.blobs[digest] = old
+	}
+	// blobs are kept after they are committed, until the jobs using them are submitted
+	s.blobs[uploaded] = now.Add(-time.Minute)
+
+	s.mockJobStore.EXPECT().GetJobs(gomock.Any(), jobstore.JobQuery{ReturnAll: true}).Return(&jobstore.JobQueryResponse{
+		Jobs: []models.Job{
+			s.job(models.JobStateTypeRunning, old, running),
+			s.job(models.JobStateTypeCompleted, now.Add(-time.Minute), recent),
+			s.job(models.JobStateTypeCompleted, old, finished),
+			s.job(models.JobStateTypeStopped, old, stopped),
+		},
+	}, nil)
+
+	s.Require().NoError(s.janitor.Collect(s.ctx))
+	s.ElementsMatch([]string{running, recent, uploaded}, slices.Collect(maps.Keys(s.blobs)))
+}
+
+func (s *BlobJanitorTestSuite) TestCollectWithoutExpiredBlobs() {
+	s.blobs[s.digest("uploaded")] = s.clock.Now()
+
+	// jobs are not listed if no blob is past its retention
+	s.Require().NoError(s.janitor.Collect(s.ctx))
+	s.Len(s.blobs, 1)
+}
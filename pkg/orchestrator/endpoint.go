@@ -9,6 +9,7 @@ import (
 
 	"github.com/bacalhau-project/bacalhau/pkg/analytics"
 	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/execstream"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/logstream"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/concurrency"
@@ -24,6 +25,7 @@ type BaseEndpointParams struct {
 	ID                string
 	Store             jobstore.Store
 	LogstreamServer   logstream.Server
+	ExecServer        execstream.Server
 	JobTransformer    transformer.JobTransformer
 	ResultTransformer transformer.ResultTransformer
 }
@@ -32,6 +34,7 @@ type BaseEndpoint struct {
 	id                string
 	store             jobstore.Store
 	logstreamServer   logstream.Server
+	execServer        execstream.Server
 	jobTransformer    transformer.JobTransformer
 	resultTransformer transformer.ResultTransformer
 }
@@ -41,6 +44,7 @@ func NewBaseEndpoint(params *BaseEndpointParams) *BaseEndpoint {
 		id:                params.ID,
 		store:             params.Store,
 		logstreamServer:   params.LogstreamServer,
+		execServer:        params.ExecServer,
 		jobTransformer:    params.JobTransformer,
 		resultTransformer: params.ResultTransformer,
 	}
@@ -408,6 +412,75 @@ func (e *BaseEndpoint) ReadLogs(ctx context.Context, request ReadLogsRequest) (
 	return e.logstreamServer.GetLogStream(ctx, req)
 }
 
+// Exec opens an interactive session into a running execution of a job.
+// If no execution is requested, the most recently updated running execution is used.
+func (e *BaseEndpoint) Exec(ctx context.Context, request ExecRequest, input <-chan models.ExecInput) (
+	<-chan *concurrency.AsyncResult[models.ExecOutput], error) {
+	if e.execServer == nil {
+		return nil, bacerrors.New("interactive sessions are not enabled on this orchestrator").
+			WithCode(bacerrors.NotImplemented)
+	}
+	if !request.Attach && len(request.Command) == 0 {
+		return nil, bacerrors.New("a command is required to exec into an execution").
+			WithCode(bacerrors.ValidationError)
+	}
+
+	job, err := e.store.GetJobByIDOrName(ctx, request.JobID, request.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	executions, err := e.store.GetExecutions(ctx, jobstore.GetExecutionsOptions{
+		JobID:          job.ID,
+		AllJobVersions: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var execution *models.Execution
+	for i, exec := range executions {
+		if request.ExecutionID != "" {
+			if exec.ID == request.ExecutionID {
+				execution = &executions[i]
+				break
+			}
+			continue
+		}
+		if exec.ComputeState.StateType == models.ExecutionStateRunning &&
+			(execution == nil || exec.ModifyTime > execution.ModifyTime) {
+			execution = &executions[i]
+		}
+	}
+
+	if execution == nil {
+		if request.ExecutionID != "" {
+			return nil, bacerrors.Newf("execution %s not found in job %s", request.ExecutionID, job.ID).
+				WithCode(bacerrors.NotFoundError)
+		}
+		return nil, bacerrors.Newf("job %s has no running executions", job.ID).
+			WithCode(bacerrors.NotFoundError)
+	}
+	if execution.ComputeState.StateType != models.ExecutionStateRunning {
+		return nil, bacerrors.Newf("cannot open a session into execution %s in state %s",
+			execution.ID, execution.ComputeState.StateType).
+			WithCode(bacerrors.BadRequestError).
+			WithHint("interactive sessions can only be opened into running executions")
+	}
+
+	return e.execServer.Exec(ctx, messages.ExecRequest{
+		SessionID:   uuid.NewString(),
+		ExecutionID: execution.ID,
+		NodeID:      execution.NodeID,
+		Command:     request.Command,
+		Attach:      request.Attach,
+		TTY:         request.TTY,
+		Stdin:       request.Stdin,
+		Height:      request.Height,
+		Width:       request.Width,
+	}, input)
+}
+
 // GetResults returns the results of a job
 func (e *BaseEndpoint) GetResults(ctx context.Context, request *GetResultsRequest) (GetResultsResponse, error) {
 	job, err := e.store.GetJobByIDOrName(ctx, request.JobID, request.Namespace)
@@ -428,8 +501,9 @@ func (e *BaseEndpoint) GetResults(ctx context.Context, request *GetResultsReques
 	}
 
 	results := make([]*models.SpecConfig, 0)
+	var origins []models.ResultOrigin
 	for _, execution := range executions {
-		if execution.ComputeState.StateType == models.ExecutionStateCompleted {
+		if execution.ComputeState.StateType == models.ExecutionStateCompleted && request.Selects(execution) {
 			result := execution.PublishedResult.Copy()
 			err = e.resultTransformer.Transform(ctx, result)
 			if err != nil {
@@ -439,11 +513,13 @@ func (e *BaseEndpoint) GetResults(ctx context.Context, request *GetResultsReques
 			// Only add valid results
 			if result.Type != "" {
 				results = append(results, result)
+				origins = append(origins, execution.ResultOrigin())
 			}
 		}
 	}
 
 	return GetResultsResponse{
 		Results: results,
+		Origins: origins,
 	}, nil
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/orchestrator/transformer"
 	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
 	"github.com/google/uuid"
 )
@@ -949,6 +950,59 @@ func (s *EndpointTestSuite) createTestJobForSubmission(name, namespace string) *
 	return job
 }
 
+// GetResults Tests
+
+func (s *EndpointTestSuite) TestGetResults_SelectsExecutions() {
+	job := s.createTestJob("test-job", models.JobStateTypeCompleted)
+	job.Type = models.JobTypeBatch
+	executions := make([]models.Execution, 0, 3)
+	for i, id := range []string{"e-aaa", "e-abb", "e-bbb"} {
+		execution := mock.ExecutionForJob(&job)
+		execution.ID = id
+		execution.NodeID = "node-" + id
+		execution.PartitionIndex = i
+		execution.ComputeState = models.NewExecutionState(models.ExecutionStateCompleted)
+		execution.PublishedResult = &models.SpecConfig{Type: models.StorageSourceURL, Params: map[string]any{"URL": id}}
+		executions = append(executions, *execution)
+	}
+	s.endpoint.resultTransformer = transformer.ResultFn(func(context.Context, *models.SpecConfig) error { return nil })
+	s.mockJobStore.EXPECT().GetJobByIDOrName(gomock.Any(), job.ID, job.Namespace).Return(job, nil).AnyTimes()
+	s.mockJobStore.EXPECT().GetExecutions(gomock.Any(), gomock.Any()).Return(executions, nil).AnyTimes()
+
+	for _, tc := range []struct {
+		name     string
+		request  GetResultsRequest
+		expected []string
+	}{
+		{name: "all", request: GetResultsRequest{}, expected: []string{"e-aaa", "e-abb", "e-bbb"}},
+		{name: "execution prefix", request: GetResultsRequest{ExecutionIDs: []string{"e-a"}}, expected: []string{"e-aaa", "e-abb"}},
+		{name: "partitions", request: GetResultsRequest{Partitions: []int{0, 2}}, expected: []string{"e-aaa", "e-bbb"}},
+		{
+			name:     "execution and partition",
+			request:  GetResultsRequest{ExecutionIDs: []string{"e-a"}, Partitions: []int{1, 2}},
+			expected: []string{"e-abb"},
+		},
+		{name: "no match", request: GetResultsRequest{ExecutionIDs: []string{"e-c"}}, expected: []string{}},
+	} {
+		s.Run(tc.name, func() {
+			tc.request.JobID = job.ID
+			tc.request.Namespace = job.Namespace
+			response, err := s.endpoint.GetResults(context.Background(), &tc.request)
+			s.Require().NoError(err)
+			urls := make([]string, 0, len(response.Results))
+			for _, result := range response.Results {
+				urls = append(urls, result.Params["URL"].(string))
+			}
+			s.Equal(tc.expected, urls)
+			// results are returned with the executions that published them
+			s.Require().Len(response.Origins, len(response.Results))
+			for i, origin := range response.Origins {
+				s.Equal(models.ResultOrigin{JobID: job.ID, ExecutionID: tc.expected[i], NodeID: "node-" + tc.expected[i]}, origin)
+			}
+		})
+	}
+}
+
 func TestEndpointTestSuite(t *testing.T) {
 	suite.Run(t, new(EndpointTestSuite))
 }
@@ -29,6 +29,7 @@ const (
 	jobExhaustedRetriesMessage = "Job failed because it has been retried too many times"
 	JobTimeoutMessage          = "Job timed out"
 	jobExecutionsFailedMessage = "Job failed because one or more executions failed"
+	jobNonRetryableFailure     = "Job failed because an execution failed with an error that retrying would not fix"
 
 	execCompletedMessage                 = "Completed successfully"
 	execRunningMessage                   = "Running"
@@ -38,6 +39,7 @@ const (
 	execStoppedByOversubscriptionMessage = "Execution stop requested because there are more executions than needed"
 	execStoppedDueToJobFailureMessage    = "Execution stopped due to job failure"
 	execStoppedForJobUpdateMessage       = "Execution stopped for job update"
+	execRestoringCheckpointMessage       = "Restoring checkpoint %d of previous execution %s"
 
 	executionTimeoutMessage = "Execution timed out"
 
@@ -101,6 +103,13 @@ func JobExecutionsFailedEvent() models.Event {
 	return event(EventTopicJobScheduling, jobExecutionsFailedMessage, map[string]string{})
 }
 
+// JobNonRetryableFailureEvent is recorded when an execution failed with an error that would happen again on other nodes
+func JobNonRetryableFailureEvent(execution *models.Execution) models.Event {
+	return *models.NewEvent(EventTopicJobScheduling).
+		WithError(fmt.Errorf("%s: %s", jobNonRetryableFailure, execution.ComputeState.Message)).
+		WithDetail("ExecutionID", execution.ID)
+}
+
 func JobQueueingEvent(reason string) models.Event {
 	message := jobQueuedMessage
 	if reason != "" {
@@ -121,6 +130,14 @@ func ExecCreatedEvent(execution *models.Execution) models.Event {
 		WithDetail("NodeID", execution.NodeID)
 }
 
+// ExecRestoringCheckpointEvent is recorded when a new execution restores the last checkpoint of a previous one
+func ExecRestoringCheckpointEvent(previous *models.Execution) models.Event {
+	return *models.NewEvent(EventTopicJobScheduling).
+		WithMessage(fmt.Sprintf(execRestoringCheckpointMessage,
+			previous.Checkpoint.Sequence, idgen.ShortUUID(previous.ID))).
+		WithDetail("PreviousExecution", previous.ID)
+}
+
 func ExecCompletedEvent() models.Event {
 	return *models.NewEvent(EventTopicExecution).WithMessage(execCompletedMessage)
 }
@@ -110,3 +110,9 @@ type RetryStrategy interface {
 	// ShouldRetry returns true if the job can be retried.
 	ShouldRetry(ctx context.Context, request RetryRequest) bool
 }
+
+// SecretStore holds the secrets that jobs reference as "secret:orchestrator/<name>".
+type SecretStore interface {
+	// Get returns the secret stored under name
+	Get(name string) (string, error)
+}
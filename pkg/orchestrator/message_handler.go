@@ -32,6 +32,7 @@ func NewMessageHandler(store jobstore.Store) *MessageHandler {
 func (m *MessageHandler) ShouldProcess(ctx context.Context, message *envelope.Message) bool {
 	return message.Metadata.Get(envelope.KeyMessageType) == messages.BidResultMessageType ||
 		message.Metadata.Get(envelope.KeyMessageType) == messages.RunResultMessageType ||
+		message.Metadata.Get(envelope.KeyMessageType) == messages.CheckpointMessageType ||
 		message.Metadata.Get(envelope.KeyMessageType) == messages.ComputeErrorMessageType
 }
 
@@ -52,6 +53,8 @@ func (m *MessageHandler) HandleMessage(ctx context.Context, message *envelope.Me
 		err = m.OnBidComplete(ctx, metrics, message)
 	case messages.RunResultMessageType:
 		err = m.OnRunComplete(ctx, metrics, message)
+	case messages.CheckpointMessageType:
+		err = m.OnCheckpoint(ctx, metrics, message)
 	case messages.ComputeErrorMessageType:
 		err = m.OnComputeFailure(ctx, metrics, message)
 	}
@@ -143,6 +146,30 @@ func (m *MessageHandler) OnRunComplete(ctx context.Context, metrics *telemetry.M
 
 	defer txContext.Rollback() //nolint:errcheck
 
+	if result.Cancelled {
+		// the execution was cancelled and published the results it wrote while terminating gracefully.
+		// They are recorded without changing the state of the execution, so no evaluation is needed.
+		if err = m.store.UpdateExecution(txContext, jobstore.UpdateExecutionRequest{
+			ExecutionID: result.ExecutionID,
+			Condition: jobstore.UpdateExecutionCondition{
+				ExpectedStates: []models.ExecutionStateType{models.ExecutionStateCancelled},
+			},
+			NewValues: models.Execution{
+				PublishedResult: result.PublishResult,
+				RunOutput:       result.RunCommandResult,
+				ComputeState:    models.NewExecutionState(models.ExecutionStateCancelled),
+			},
+			Events: result.Events,
+		}); err != nil {
+			return err
+		}
+		metrics.Latency(ctx, messageHandlerProcessPartDuration, AttrPartUpdateExec)
+
+		err = txContext.Commit()
+		metrics.Latency(ctx, messageHandlerProcessPartDuration, AttrPartCommitTx)
+		return err
+	}
+
 	job, err := m.store.GetJob(txContext, result.JobID)
 	metrics.Latency(ctx, messageHandlerProcessPartDuration, AttrPartGetJob)
 	if err != nil {
@@ -191,6 +218,44 @@ func (m *MessageHandler) OnRunComplete(ctx context.Context, metrics *telemetry.M
 	return err
 }
 
+// OnCheckpoint records the last checkpoint published by a running execution,
+// so that it can be restored if the execution has to be rescheduled
+func (m *MessageHandler) OnCheckpoint(ctx context.Context, metrics *telemetry.MetricRecorder, message *envelope.Message) error {
+	result, ok := message.Payload.(*messages.CheckpointResult)
+	if !ok {
+		return envelope.NewErrUnexpectedPayloadType("CheckpointResult", reflect.TypeOf(message.Payload).String())
+	}
+
+	txContext, err := m.store.BeginTx(ctx)
+	metrics.Latency(ctx, messageHandlerProcessPartDuration, AttrPartBeginTx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer txContext.Rollback() //nolint:errcheck
+
+	// no evaluation is needed as the execution is still running
+	if err = m.store.UpdateExecution(txContext, jobstore.UpdateExecutionRequest{
+		ExecutionID: result.ExecutionID,
+		Condition: jobstore.UpdateExecutionCondition{
+			ExpectedDesiredStates: []models.ExecutionDesiredStateType{
+				models.ExecutionDesiredStateRunning,
+			},
+		},
+		NewValues: models.Execution{
+			Checkpoint: result.Checkpoint,
+		},
+		Events: result.Events,
+	}); err != nil {
+		return err
+	}
+	metrics.Latency(ctx, messageHandlerProcessPartDuration, AttrPartUpdateExec)
+
+	err = txContext.Commit()
+	metrics.Latency(ctx, messageHandlerProcessPartDuration, AttrPartCommitTx)
+	return err
+}
+
 func (m *MessageHandler) OnComputeFailure(ctx context.Context, metrics *telemetry.MetricRecorder, message *envelope.Message) error {
 	result, ok := message.Payload.(*messages.ComputeError)
 	if !ok {
@@ -205,6 +270,12 @@ func (m *MessageHandler) OnComputeFailure(ctx context.Context, metrics *telemetr
 
 	defer txContext.Rollback() //nolint:errcheck
 
+	// the details of the failure, such as its error code, let the scheduler decide whether to retry
+	computeState := models.NewExecutionState(models.ExecutionStateFailed).WithMessage(result.Error())
+	if len(result.Events) > 0 && result.Events[0] != nil {
+		computeState = computeState.WithDetails(result.Events[0].Details)
+	}
+
 	// update execution state
 	if err = m.store.UpdateExecution(txContext, jobstore.UpdateExecutionRequest{
 		ExecutionID: result.ExecutionID,
@@ -215,7 +286,7 @@ func (m *MessageHandler) OnComputeFailure(ctx context.Context, metrics *telemetr
 			},
 		},
 		NewValues: models.Execution{
-			ComputeState: models.NewExecutionState(models.ExecutionStateFailed).WithMessage(result.Error()),
+			ComputeState: computeState,
 			DesiredState: models.NewExecutionDesiredState(models.ExecutionDesiredStateStopped).WithMessage("execution failed"),
 		},
 		Events: result.Events,
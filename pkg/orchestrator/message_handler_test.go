@@ -38,6 +38,7 @@ func (suite *MessageHandlerTestSuite) TearDownTest() {
 func (suite *MessageHandlerTestSuite) TestShouldProcess() {
 	suite.True(suite.handler.ShouldProcess(context.Background(), envelope.NewMessage(nil).WithMetadataValue(envelope.KeyMessageType, messages.BidResultMessageType)))
 	suite.True(suite.handler.ShouldProcess(context.Background(), envelope.NewMessage(nil).WithMetadataValue(envelope.KeyMessageType, messages.RunResultMessageType)))
+	suite.True(suite.handler.ShouldProcess(context.Background(), envelope.NewMessage(nil).WithMetadataValue(envelope.KeyMessageType, messages.CheckpointMessageType)))
 	suite.True(suite.handler.ShouldProcess(context.Background(), envelope.NewMessage(nil).WithMetadataValue(envelope.KeyMessageType, messages.ComputeErrorMessageType)))
 	suite.False(suite.handler.ShouldProcess(context.Background(), envelope.NewMessage(nil).WithMetadataValue(envelope.KeyMessageType, "UnknownType")))
 }
@@ -134,6 +135,66 @@ func (suite *MessageHandlerTestSuite) TestHandleRunCompleteForLongRunningJob() {
 	suite.NoError(err)
 }
 
+func (suite *MessageHandlerTestSuite) TestHandleRunCompleteForCancelledExecution() {
+	ctx := context.Background()
+	runResult := &messages.RunResult{
+		BaseResponse: messages.BaseResponse{
+			ExecutionID: "exec-1",
+			JobID:       "job-1",
+			JobType:     "batch",
+		},
+		PublishResult:    &models.SpecConfig{Type: "s3"},
+		RunCommandResult: &models.RunCommandResult{ExitCode: 143},
+		Cancelled:        true,
+	}
+	message := envelope.NewMessage(runResult).WithMetadataValue(envelope.KeyMessageType, messages.RunResultMessageType)
+
+	// the results are recorded without changing the state of the execution or evaluating the job
+	suite.mockStore.EXPECT().BeginTx(gomock.Any()).Return(suite.mockTx, nil)
+	suite.mockStore.EXPECT().UpdateExecution(suite.mockTx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request jobstore.UpdateExecutionRequest) error {
+			suite.Equal("exec-1", request.ExecutionID)
+			suite.Equal([]models.ExecutionStateType{models.ExecutionStateCancelled}, request.Condition.ExpectedStates)
+			suite.Equal(models.ExecutionStateCancelled, request.NewValues.ComputeState.StateType)
+			suite.Equal(runResult.PublishResult, request.NewValues.PublishedResult)
+			suite.Equal(runResult.RunCommandResult, request.NewValues.RunOutput)
+			return nil
+		})
+	suite.mockTx.EXPECT().Commit().Return(nil)
+	suite.mockTx.EXPECT().Rollback().Return(nil)
+
+	err := suite.handler.HandleMessage(ctx, message)
+	suite.NoError(err)
+}
+
+func (suite *MessageHandlerTestSuite) TestHandleCheckpoint() {
+	ctx := context.Background()
+	checkpoint := &models.Checkpoint{Sequence: 2, Result: &models.SpecConfig{Type: "s3"}}
+	checkpointResult := &messages.CheckpointResult{
+		BaseResponse: messages.BaseResponse{
+			ExecutionID: "exec-1",
+			JobID:       "job-1",
+			JobType:     "batch",
+		},
+		Checkpoint: checkpoint,
+	}
+	message := envelope.NewMessage(checkpointResult).WithMetadataValue(envelope.KeyMessageType, messages.CheckpointMessageType)
+
+	suite.mockStore.EXPECT().BeginTx(gomock.Any()).Return(suite.mockTx, nil)
+	suite.mockStore.EXPECT().UpdateExecution(suite.mockTx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request jobstore.UpdateExecutionRequest) error {
+			suite.Equal("exec-1", request.ExecutionID)
+			suite.Equal(checkpoint, request.NewValues.Checkpoint)
+			suite.True(request.NewValues.ComputeState.StateType.IsUndefined())
+			return nil
+		})
+	suite.mockTx.EXPECT().Commit().Return(nil)
+	suite.mockTx.EXPECT().Rollback().Return(nil)
+
+	err := suite.handler.HandleMessage(ctx, message)
+	suite.NoError(err)
+}
+
 func (suite *MessageHandlerTestSuite) TestHandleComputeFailure() {
 	ctx := context.Background()
 	computeError := &messages.ComputeError{
@@ -0,0 +1,108 @@
+package planner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/crypto"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/orchestrator"
+	"github.com/bacalhau-project/bacalhau/pkg/orchestrator/nodes"
+)
+
+// SecretSealer replaces references to orchestrator held secrets in new executions
+// with the secret sealed to the public key of the node the execution is assigned to.
+// References are sealed wherever the node resolves them: in environment variables, and in the
+// params of publishers and input sources, such as webhook headers and git tokens.
+// Only that node can open the sealed secret, and the plain text secret never leaves the orchestrator.
+// It must run before the StateUpdater so that only sealed secrets are persisted.
+// If no store is configured, references are left as they are.
+type SecretSealer struct {
+	store orchestrator.SecretStore
+	nodes nodes.Lookup
+}
+
+// SecretSealerParams holds the dependencies of a SecretSealer
+type SecretSealerParams struct {
+	SecretStore orchestrator.SecretStore
+	NodeLookup  nodes.Lookup
+}
+
+// NewSecretSealer creates a new SecretSealer
+func NewSecretSealer(params SecretSealerParams) *SecretSealer {
+	return &SecretSealer{
+		store: params.SecretStore,
+		nodes: params.NodeLookup,
+	}
+}
+
+// Process seals the orchestrator secrets referenced by the new executions in the plan
+func (s *SecretSealer) Process(ctx context.Context, plan *models.Plan) error {
+	for _, execution := range plan.NewExecutions {
+		// without a store the references are left untouched and the node will reject the execution
+		if s.store == nil || !referencesOrchestratorSecrets(execution) {
+			continue
+		}
+
+		node, err := s.nodes.Get(ctx, execution.NodeID)
+		if err != nil {
+			return fmt.Errorf("failed to look up node %s to seal secrets: %w", execution.NodeID, err)
+		}
+		if node.Info.ComputeNodeInfo.PublicKey == "" {
+			// older nodes do not advertise a key. The references are left untouched and the node
+			// will reject the execution as it cannot resolve them.
+			log.Ctx(ctx).Warn().
+				Str("node", execution.NodeID).
+				Str("execution", execution.ID).
+				Msg("node does not advertise a public key. orchestrator secrets cannot be delivered")
+			continue
+		}
+		publicKey, err := crypto.DecodePublicKey(node.Info.ComputeNodeInfo.PublicKey)
+		if err != nil {
+			return fmt.Errorf("failed to decode public key of node %s: %w", execution.NodeID, err)
+		}
+
+		// executions may share the job with the plan, so seal secrets in a private copy
+		execution.Job = execution.Job.Copy()
+		err = execution.Job.Task().ReplaceSecretRefs(func(location string, value models.EnvVarValue) (models.EnvVarValue, error) {
+			backend, key, _ := value.SecretRef()
+			if backend != models.SecretBackendOrchestrator {
+				return value, nil
+			}
+			secret, err := s.store.Get(key)
+			if err != nil {
+				return "", fmt.Errorf("failed to read orchestrator secret for %s: %w", location, err)
+			}
+			sealed, err := crypto.Seal(publicKey, []byte(secret))
+			if err != nil {
+				return "", fmt.Errorf("failed to seal secret for %s: %w", location, err)
+			}
+			return models.NewSecretRef(models.SecretBackendSealed, sealed), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// referencesOrchestratorSecrets returns true if the execution's task references orchestrator secrets
+func referencesOrchestratorSecrets(execution *models.Execution) bool {
+	if execution.Job == nil || execution.Job.Task() == nil {
+		return false
+	}
+	found := false
+	// the references are left as they are, so the job shared with the plan is not modified
+	_ = execution.Job.Task().ReplaceSecretRefs(func(_ string, value models.EnvVarValue) (models.EnvVarValue, error) {
+		if backend, _, _ := value.SecretRef(); backend == models.SecretBackendOrchestrator {
+			found = true
+		}
+		return value, nil
+	})
+	return found
+}
+
+// compile-time check whether the SecretSealer implements the Planner interface.
+var _ orchestrator.Planner = (*SecretSealer)(nil)
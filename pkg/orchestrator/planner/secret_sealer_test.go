@@ -0,0 +1,158 @@
+//go:build unit || !integration
+
+package planner
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/crypto"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/orchestrator/nodes"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+// mapSecretStore is an in-memory orchestrator.SecretStore
+type mapSecretStore map[string]string
+
+func (m mapSecretStore) Get(name string) (string, error) {
+	value, ok := m[name]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return value, nil
+}
+
+type SecretSealerSuite struct {
+	suite.Suite
+	ctx        context.Context
+	ctrl       *gomock.Controller
+	nodeLookup *nodes.MockLookup
+	key        *rsa.PrivateKey
+	publicKey  string
+	sealer     *SecretSealer
+}
+
+func TestSecretSealerSuite(t *testing.T) {
+	suite.Run(t, new(SecretSealerSuite))
+}
+
+func (s *SecretSealerSuite) SetupSuite() {
+	var err error
+	s.key, err = rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	s.publicKey, err = crypto.EncodePublicKey(&s.key.PublicKey)
+	s.Require().NoError(err)
+}
+
+func (s *SecretSealerSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.ctrl = gomock.NewController(s.T())
+	s.nodeLookup = nodes.NewMockLookup(s.ctrl)
+	s.sealer = NewSecretSealer(SecretSealerParams{
+		SecretStore: mapSecretStore{"db-password": "hunter2"},
+		NodeLookup:  s.nodeLookup,
+	})
+}
+
+func (s *SecretSealerSuite) nodeState(publicKey string) models.NodeState {
+	return models.NodeState{
+		Info: models.NodeInfo{
+			ComputeNodeInfo: models.ComputeNodeInfo{PublicKey: publicKey},
+		},
+	}
+}
+
+func (s *SecretSealerSuite) TestSealsOrchestratorSecrets() {
+	plan := mock.Plan()
+	plan.Job.Task().Env = map[string]models.EnvVarValue{
+		"DB_PASSWORD": "secret:orchestrator/db-password",
+		"LITERAL":     "value",
+	}
+	execution, _ := mockCreateExecutions(plan)
+	plan.NewExecutions = []*models.Execution{execution}
+
+	s.nodeLookup.EXPECT().Get(s.ctx, execution.NodeID).Return(s.nodeState(s.publicKey), nil)
+	s.Require().NoError(s.sealer.Process(s.ctx, plan))
+
+	env := execution.Job.Task().Env
+	backend, sealed, ok := env["DB_PASSWORD"].SecretRef()
+	s.Require().True(ok)
+	s.Equal(models.SecretBackendSealed, backend)
+	s.NotContains(string(env["DB_PASSWORD"]), "hunter2")
+	s.Equal(models.EnvVarValue("value"), env["LITERAL"])
+
+	opened, err := crypto.Unseal(s.key, sealed)
+	s.Require().NoError(err)
+	s.Equal("hunter2", string(opened))
+
+	// the job shared by the plan is left untouched
+	s.Equal(models.EnvVarValue("secret:orchestrator/db-password"), plan.Job.Task().Env["DB_PASSWORD"])
+}
+
+func (s *SecretSealerSuite) TestSealsSpecSecrets() {
+	plan := mock.Plan()
+	plan.Job.Task().Publisher = &models.SpecConfig{Type: models.PublisherWebhook, Params: map[string]any{
+		"URL":     "https://example.com",
+		"Headers": map[string]any{"Authorization": "secret:orchestrator/db-password"},
+	}}
+	plan.Job.Task().InputSources = []*models.InputSource{{
+		Source: &models.SpecConfig{Type: models.StorageSourceGit, Params: map[string]any{"Token": "secret:orchestrator/db-password"}},
+		Target: "/repo",
+	}}
+	execution, _ := mockCreateExecutions(plan)
+	plan.NewExecutions = []*models.Execution{execution}
+
+	s.nodeLookup.EXPECT().Get(s.ctx, execution.NodeID).Return(s.nodeState(s.publicKey), nil)
+	s.Require().NoError(s.sealer.Process(s.ctx, plan))
+
+	task := execution.Job.Task()
+	for _, value := range []any{
+		task.Publisher.Params["Headers"].(map[string]any)["Authorization"],
+		task.InputSources[0].Source.Params["Token"],
+	} {
+		backend, sealed, ok := models.EnvVarValue(value.(string)).SecretRef()
+		s.Require().True(ok)
+		s.Equal(models.SecretBackendSealed, backend)
+		opened, err := crypto.Unseal(s.key, sealed)
+		s.Require().NoError(err)
+		s.Equal("hunter2", string(opened))
+	}
+
+	// the job shared by the plan is left untouched
+	s.Equal("secret:orchestrator/db-password", plan.Job.Task().Publisher.Params["Headers"].(map[string]any)["Authorization"])
+	s.Equal("secret:orchestrator/db-password", plan.Job.Task().InputSources[0].Source.Params["Token"])
+}
+
+func (s *SecretSealerSuite) TestSkipsExecutionsWithoutSecrets() {
+	plan := mock.Plan()
+	mockCreateExecutions(plan)
+	s.Require().NoError(s.sealer.Process(s.ctx, plan))
+}
+
+func (s *SecretSealerSuite) TestNodeWithoutPublicKey() {
+	plan := mock.Plan()
+	plan.Job.Task().Env = map[string]models.EnvVarValue{"DB_PASSWORD": "secret:orchestrator/db-password"}
+	execution, _ := mockCreateExecutions(plan)
+	plan.NewExecutions = []*models.Execution{execution}
+
+	s.nodeLookup.EXPECT().Get(s.ctx, execution.NodeID).Return(s.nodeState(""), nil)
+	s.Require().NoError(s.sealer.Process(s.ctx, plan))
+	s.Equal(models.EnvVarValue("secret:orchestrator/db-password"), execution.Job.Task().Env["DB_PASSWORD"])
+}
+
+func (s *SecretSealerSuite) TestUnknownSecret() {
+	plan := mock.Plan()
+	plan.Job.Task().Env = map[string]models.EnvVarValue{"TOKEN": "secret:orchestrator/missing"}
+	execution, _ := mockCreateExecutions(plan)
+	plan.NewExecutions = []*models.Execution{execution}
+
+	s.nodeLookup.EXPECT().Get(s.ctx, execution.NodeID).Return(s.nodeState(s.publicKey), nil)
+	s.Error(s.sealer.Process(s.ctx, plan))
+}
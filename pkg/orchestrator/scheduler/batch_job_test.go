@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
 
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
 	"github.com/bacalhau-project/bacalhau/pkg/orchestrator/retry"
 )
@@ -189,6 +190,28 @@ func (s *BatchJobSchedulerTestSuite) TestProcess_ShouldMarkJobAsFailed_NoRetry()
 	s.Require().NoError(s.scheduler.Process(context.Background(), scenario.evaluation))
 }
 
+func (s *BatchJobSchedulerTestSuite) TestProcess_ShouldMarkJobAsFailed_NonRetryableError() {
+	scenario := NewScenario(
+		WithCount(2),
+		WithPartitionedExecution("node0", models.ExecutionStateCompleted, 0),
+		WithPartitionedExecution("node1", models.ExecutionStateFailed, 1),
+	)
+	failed := &scenario.executions[1]
+	failed.ComputeState = failed.ComputeState.
+		WithMessage("checksum mismatch").
+		WithDetail(models.DetailsKeyErrorCode, "URLDownload:"+string(bacerrors.ChecksumMismatch))
+	s.mockJobStore(scenario)
+
+	// the job fails without looking for other nodes to retry on
+	s.planner.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, plan *models.Plan) error {
+		s.Equal(models.JobStateTypeFailed, plan.DesiredJobState)
+		s.Empty(plan.NewExecutions)
+		s.Contains(plan.UpdateMessage, "checksum mismatch")
+		return nil
+	})
+	s.Require().NoError(s.scheduler.Process(context.Background(), scenario.evaluation))
+}
+
 func (s *BatchJobSchedulerTestSuite) TestProcess_ShouldMarkJobAsFailed_TotalTimeout() {
 	scenario := NewScenario(
 		WithCount(3),
@@ -266,3 +289,33 @@ func (s *BatchJobSchedulerTestSuite) TestProcess_ShouldStopExpiredExecutions() {
 	s.planner.EXPECT().Process(gomock.Any(), matcher).Times(1)
 	s.Require().NoError(s.scheduler.Process(context.Background(), scenario.evaluation))
 }
+
+func (s *BatchJobSchedulerTestSuite) TestProcess_ShouldRestoreLatestCheckpointOfFailedPartition() {
+	scenario := NewScenario(
+		WithCount(2),
+		WithPartitionedExecution("node0", models.ExecutionStateCompleted, 0),
+		WithPartitionedExecution("node1", models.ExecutionStateFailed, 1),
+		WithPartitionedExecution("node1", models.ExecutionStateFailed, 1),
+	)
+	checkpointed := &scenario.executions[1]
+	checkpointed.Checkpoint = &models.Checkpoint{
+		Sequence:   3,
+		Result:     &models.SpecConfig{Type: models.StorageSourceS3},
+		CreateTime: s.clock.Now().UnixNano(),
+	}
+	s.mockJobStore(scenario)
+	s.mockMatchingNodes(scenario, "node2")
+
+	s.planner.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, plan *models.Plan) error {
+		s.Require().Len(plan.NewExecutions, 1)
+		execution := plan.NewExecutions[0]
+		s.Equal(1, execution.PartitionIndex)
+		s.Equal(checkpointed.ID, execution.PreviousExecution)
+		s.Require().NotNil(execution.RestoreFrom)
+		s.Equal(models.CheckpointRestorePath, execution.RestoreFrom.Target)
+		s.Equal(models.StorageSourceS3, execution.RestoreFrom.Source.Type)
+		s.Len(plan.ExecutionEvents[execution.ID], 2)
+		return nil
+	})
+	s.Require().NoError(s.scheduler.Process(context.Background(), scenario.evaluation))
+}
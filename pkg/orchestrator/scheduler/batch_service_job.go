@@ -272,6 +272,12 @@ func (b *BatchServiceJobScheduler) scheduleRemainingPartitions(ctx context.Conte
 
 	// first, check if there were any failed executions and if we should retry
 	if len(allFailedExecs) > 0 {
+		// failures that would happen again on other nodes are not retried
+		if failed := allFailedExecs.filterByJobVersion(plan.Job.Version).firstNonRetryable(); failed != nil {
+			plan.MarkJobFailed(orchestrator.JobNonRetryableFailureEvent(failed))
+			metrics.AddAttributes(AttrOutcomeKey.String(AttrOutcomeNonRetryable))
+			return nil
+		}
 		if !b.retryStrategy.ShouldRetry(ctx, orchestrator.RetryRequest{JobID: plan.Job.ID}) {
 			plan.MarkJobFailed(orchestrator.JobExhaustedRetriesEvent())
 			metrics.Count(ctx, retriesExhausted)
@@ -282,8 +288,12 @@ func (b *BatchServiceJobScheduler) scheduleRemainingPartitions(ctx context.Conte
 		}
 	}
 
+	// failed executions of the current job version that checkpointed their progress are
+	// restored into the executions replacing them
+	checkpoints := allFailedExecs.filterByJobVersion(plan.Job.Version).latestCheckpoints()
+
 	// find matching nodes for the remaining executions
-	return b.createMissingExecs(ctx, metrics, plan, remainingPartitions)
+	return b.createMissingExecs(ctx, metrics, plan, remainingPartitions, checkpoints)
 }
 
 // createMissingExecs creates new executions for partitions that need them.
@@ -296,8 +306,12 @@ func (b *BatchServiceJobScheduler) scheduleRemainingPartitions(ctx context.Conte
 // - Initial: remainingPartitions = [0,1,2]
 // - If partition 1 fails: remainingPartitions = [1]
 // - If all complete (batch): remainingPartitions = []
+//
+// The checkpoints parameter holds the execution with the latest checkpoint of each partition, if any.
+// A new execution of such a partition replaces that execution and restores its checkpoint.
 func (b *BatchServiceJobScheduler) createMissingExecs(
-	ctx context.Context, metrics *telemetry.MetricRecorder, plan *models.Plan, remainingPartitions []int) error {
+	ctx context.Context, metrics *telemetry.MetricRecorder, plan *models.Plan, remainingPartitions []int,
+	checkpoints map[int]*models.Execution) error {
 	// find matching nodes for the job
 	matching, rejected, err := b.selector.MatchingNodes(ctx, plan.Job)
 	if err != nil {
@@ -359,8 +373,15 @@ func (b *BatchServiceJobScheduler) createMissingExecs(
 			DesiredState:   models.NewExecutionDesiredState(models.ExecutionDesiredStatePending),
 			PartitionIndex: remainingPartitions[i],
 		}
+		if previous, ok := checkpoints[execution.PartitionIndex]; ok {
+			execution.PreviousExecution = previous.ID
+			execution.RestoreFrom = previous.Checkpoint.RestoreInputSource()
+		}
 		execution.Normalize()
 		plan.AppendExecution(execution, orchestrator.ExecCreatedEvent(execution))
+		if execution.RestoreFrom != nil {
+			plan.AppendExecutionEvent(execution.ID, orchestrator.ExecRestoringCheckpointEvent(checkpoints[execution.PartitionIndex]))
+		}
 		count++
 	}
 	metrics.CountAndHistogram(ctx, executionsCreatedTotal, executionsCreated, count)
@@ -114,6 +114,7 @@ const (
 	AttrOutcomeFailure          = "failure"
 	AttrOutcomeAlreadyTerminal  = "already_terminal"
 	AttrOutcomeExhaustedRetries = "exhausted_retries"
+	AttrOutcomeNonRetryable     = "non_retryable"
 	AttrOutcomeQueueing         = "queueing"
 	AttrOutcomeTimeout          = "timeout"
 	AttrOutcomeQueueTimeout     = "queue_timeout"
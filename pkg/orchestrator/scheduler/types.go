@@ -2,15 +2,23 @@ package scheduler
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
 )
 
+// nonRetryableErrorCodes are the error codes of execution failures that would happen again on
+// any other node, such as downloaded inputs not matching their expected checksum.
+var nonRetryableErrorCodes = []bacerrors.ErrorCode{
+	bacerrors.ChecksumMismatch,
+}
+
 // execSet is a set of executions with a series of helper functions defined
 // that help reconcile state.
 type execSet map[string]*models.Execution
@@ -103,6 +111,19 @@ func (set execSet) filterByOutdatedJobVersion(latestJobVersion uint64) execSet {
 	})
 }
 
+// firstNonRetryable returns the earliest execution that failed with a non-retryable error code, or nil if there is none
+func (set execSet) firstNonRetryable() *models.Execution {
+	for _, exec := range set.ordered() {
+		// error codes have the form "<component>:<code>"
+		errorCode := exec.ComputeState.Details[models.DetailsKeyErrorCode]
+		code := bacerrors.Code(errorCode[strings.LastIndex(errorCode, ":")+1:])
+		if errorCode != "" && slices.Contains(nonRetryableErrorCodes, code) {
+			return exec
+		}
+	}
+	return nil
+}
+
 // filterBy compute state filters out execs that don't match the given predicate
 func (set execSet) filterBy(predicate func(execution *models.Execution) bool) execSet {
 	filtered := execSet{}
@@ -211,6 +232,23 @@ func (set execSet) remainingPartitions(totalPartitions int) []int {
 	return available
 }
 
+// latestCheckpoints returns the execution holding the most recent checkpoint of each partition.
+// Partitions without any checkpointed execution are not included. It is used to restore
+// the progress of failed executions into the executions that replace them.
+func (set execSet) latestCheckpoints() map[int]*models.Execution {
+	latest := make(map[int]*models.Execution)
+	for _, exec := range set {
+		if exec.Checkpoint == nil {
+			continue
+		}
+		current, ok := latest[exec.PartitionIndex]
+		if !ok || exec.Checkpoint.CreateTime > current.Checkpoint.CreateTime {
+			latest[exec.PartitionIndex] = exec
+		}
+	}
+	return latest
+}
+
 // executionsByApprovalStatus represents the different sets of executions based on their approval status.
 type executionsByApprovalStatus struct {
 	toApprove execSet
@@ -0,0 +1,79 @@
+package ranking
+
+import (
+	"context"
+
+	"github.com/distribution/reference"
+	"github.com/rs/zerolog/log"
+
+	dockermodels "github.com/bacalhau-project/bacalhau/pkg/executor/docker/models"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/orchestrator"
+)
+
+type ImageLocalityNodeRanker struct {
+}
+
+func NewImageLocalityNodeRanker() *ImageLocalityNodeRanker {
+	return &ImageLocalityNodeRanker{}
+}
+
+// RankNodes ranks nodes based on whether they already have the image of docker jobs cached, so that
+// executions start without waiting for the image to be pulled:
+// - Rank 10: Node has the image digest cached.
+// - Rank 5: Node has an image of the same repository cached, which likely shares layers with the image.
+// - Rank 0: Node doesn't have the image cached, or the job is not a docker job.
+func (s *ImageLocalityNodeRanker) RankNodes(
+	ctx context.Context, job models.Job, nodes []models.NodeInfo) ([]orchestrator.NodeRank, error) {
+	ranks := make([]orchestrator.NodeRank, len(nodes))
+	image := jobImage(job)
+	for i, node := range nodes {
+		rank, reason := orchestrator.RankPossible, "image not cached"
+		if image == nil {
+			reason = "not a docker job"
+		} else {
+			rank, reason = rankCachedImages(image, node.ComputeNodeInfo.CachedImages)
+		}
+		ranks[i] = orchestrator.NodeRank{
+			NodeInfo:  node,
+			Rank:      rank,
+			Reason:    reason,
+			Retryable: true,
+		}
+		log.Ctx(ctx).Trace().Object("Rank", ranks[i]).Msg("Ranked node")
+	}
+	return ranks, nil
+}
+
+func rankCachedImages(image reference.Named, cachedImages []string) (int, string) {
+	rank, reason := orchestrator.RankPossible, "image not cached"
+	digested, isDigested := image.(reference.Digested)
+	for _, cachedImage := range cachedImages {
+		cached, err := reference.ParseNormalizedNamed(cachedImage)
+		if err != nil || cached.Name() != image.Name() {
+			continue
+		}
+		if cachedDigest, ok := cached.(reference.Digested); ok && isDigested && cachedDigest.Digest() == digested.Digest() {
+			return orchestrator.RankPreferred, "image cached"
+		}
+		rank, reason = orchestrator.RankPreferred/2, "image repository cached"
+	}
+	return rank, reason
+}
+
+// jobImage returns the image of docker jobs, or nil for other jobs
+func jobImage(job models.Job) reference.Named {
+	task := job.Task()
+	if task == nil || task.Engine == nil || task.Engine.Type != models.EngineDocker {
+		return nil
+	}
+	engine, err := dockermodels.DecodeSpec(task.Engine)
+	if err != nil {
+		return nil
+	}
+	image, err := reference.ParseNormalizedNamed(engine.Image)
+	if err != nil {
+		return nil
+	}
+	return image
+}
@@ -0,0 +1,79 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	dockermodels "github.com/bacalhau-project/bacalhau/pkg/executor/docker/models"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+const (
+	cachedDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	otherDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+type ImageLocalityNodeRankerSuite struct {
+	suite.Suite
+	ranker *ImageLocalityNodeRanker
+	nodes  []models.NodeInfo
+}
+
+func TestImageLocalityNodeRankerSuite(t *testing.T) {
+	suite.Run(t, new(ImageLocalityNodeRankerSuite))
+}
+
+func (s *ImageLocalityNodeRankerSuite) SetupTest() {
+	s.ranker = NewImageLocalityNodeRanker()
+	s.nodes = []models.NodeInfo{
+		{
+			NodeID: "cached",
+			ComputeNodeInfo: models.ComputeNodeInfo{
+				CachedImages: []string{"docker.io/library/ubuntu@" + cachedDigest},
+			},
+		},
+		{
+			NodeID: "other",
+			ComputeNodeInfo: models.ComputeNodeInfo{
+				CachedImages: []string{"docker.io/library/python@" + cachedDigest},
+			},
+		},
+		{NodeID: "empty"},
+	}
+}
+
+func (s *ImageLocalityNodeRankerSuite) dockerJob(image string) *models.Job {
+	job := mock.Job()
+	job.Task().Engine = dockermodels.NewDockerEngineBuilder(image).MustBuild()
+	return job
+}
+
+func (s *ImageLocalityNodeRankerSuite) TestDigestCached() {
+	ranks, err := s.ranker.RankNodes(context.Background(), *s.dockerJob("ubuntu@" + cachedDigest), s.nodes)
+	s.Require().NoError(err)
+	assertEquals(s.T(), ranks, "cached", 10, "image cached")
+	assertEquals(s.T(), ranks, "other", 0)
+	assertEquals(s.T(), ranks, "empty", 0)
+}
+
+func (s *ImageLocalityNodeRankerSuite) TestRepositoryCached() {
+	for _, image := range []string{"ubuntu:24.04", "ubuntu@" + otherDigest} {
+		ranks, err := s.ranker.RankNodes(context.Background(), *s.dockerJob(image), s.nodes)
+		s.Require().NoError(err)
+		assertEquals(s.T(), ranks, "cached", 5, "image repository cached")
+		assertEquals(s.T(), ranks, "other", 0)
+		assertEquals(s.T(), ranks, "empty", 0)
+	}
+}
+
+func (s *ImageLocalityNodeRankerSuite) TestNonDockerJob() {
+	ranks, err := s.ranker.RankNodes(context.Background(), *mock.Job(), s.nodes)
+	s.Require().NoError(err)
+	assertEquals(s.T(), ranks, "cached", 0, "not a docker job")
+	assertEquals(s.T(), ranks, "empty", 0, "not a docker job")
+}
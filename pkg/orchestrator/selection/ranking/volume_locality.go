@@ -0,0 +1,65 @@
+package ranking
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/orchestrator"
+)
+
+type VolumeLocalityNodeRanker struct {
+}
+
+func NewVolumeLocalityNodeRanker() *VolumeLocalityNodeRanker {
+	return &VolumeLocalityNodeRanker{}
+}
+
+// RankNodes ranks nodes based on whether they already hold the persistent volumes of the job, so that
+// executions that replace previous ones, such as after a job update, keep the data of their volumes:
+// - Rank 10: Node holds all the volumes of the job.
+// - Rank 5: Node holds some of the volumes of the job.
+// - Rank 0: Node doesn't hold any volume of the job, or the job has no persistent volumes.
+func (s *VolumeLocalityNodeRanker) RankNodes(
+	ctx context.Context, job models.Job, nodes []models.NodeInfo) ([]orchestrator.NodeRank, error) {
+	ranks := make([]orchestrator.NodeRank, len(nodes))
+	var volumes []*models.PersistentVolume
+	if task := job.Task(); task != nil {
+		volumes = task.Volumes
+	}
+	for i, node := range nodes {
+		rank, reason := orchestrator.RankPossible, "no persistent volumes"
+		if len(volumes) > 0 {
+			rank, reason = rankHeldVolumes(job.ID, volumes, node.ComputeNodeInfo.Volumes)
+		}
+		ranks[i] = orchestrator.NodeRank{
+			NodeInfo:  node,
+			Rank:      rank,
+			Reason:    reason,
+			Retryable: true,
+		}
+		log.Ctx(ctx).Trace().Object("Rank", ranks[i]).Msg("Ranked node")
+	}
+	return ranks, nil
+}
+
+func rankHeldVolumes(jobID string, volumes []*models.PersistentVolume, heldVolumes []models.VolumeInfo) (int, string) {
+	held := 0
+	for _, volume := range volumes {
+		for _, heldVolume := range heldVolumes {
+			if heldVolume.JobID == jobID && heldVolume.Name == volume.Name {
+				held++
+				break
+			}
+		}
+	}
+	switch {
+	case held == len(volumes):
+		return orchestrator.RankPreferred, "persistent volumes held"
+	case held > 0:
+		return orchestrator.RankPreferred / 2, "some persistent volumes held"
+	default:
+		return orchestrator.RankPossible, "persistent volumes not held"
+	}
+}
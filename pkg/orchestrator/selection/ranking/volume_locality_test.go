@@ -0,0 +1,74 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+type VolumeLocalityNodeRankerSuite struct {
+	suite.Suite
+	ranker *VolumeLocalityNodeRanker
+	job    *models.Job
+	nodes  []models.NodeInfo
+}
+
+func TestVolumeLocalityNodeRankerSuite(t *testing.T) {
+	suite.Run(t, new(VolumeLocalityNodeRankerSuite))
+}
+
+func (s *VolumeLocalityNodeRankerSuite) SetupTest() {
+	s.ranker = NewVolumeLocalityNodeRanker()
+	s.job = mock.Job()
+	s.job.Type = models.JobTypeService
+	s.job.Task().Volumes = []*models.PersistentVolume{
+		{Name: "data", Target: "/data"},
+		{Name: "cache", Target: "/cache"},
+	}
+	s.nodes = []models.NodeInfo{
+		{
+			NodeID: "all",
+			ComputeNodeInfo: models.ComputeNodeInfo{
+				Volumes: []models.VolumeInfo{
+					{JobID: s.job.ID, Name: "data"},
+					{JobID: s.job.ID, Name: "cache"},
+				},
+			},
+		},
+		{
+			NodeID: "some",
+			ComputeNodeInfo: models.ComputeNodeInfo{
+				Volumes: []models.VolumeInfo{{JobID: s.job.ID, Name: "data"}},
+			},
+		},
+		{
+			NodeID: "other",
+			ComputeNodeInfo: models.ComputeNodeInfo{
+				Volumes: []models.VolumeInfo{{JobID: "other-job", Name: "data"}},
+			},
+		},
+		{NodeID: "empty"},
+	}
+}
+
+func (s *VolumeLocalityNodeRankerSuite) TestVolumesHeld() {
+	ranks, err := s.ranker.RankNodes(context.Background(), *s.job, s.nodes)
+	s.Require().NoError(err)
+	assertEquals(s.T(), ranks, "all", 10, "persistent volumes held")
+	assertEquals(s.T(), ranks, "some", 5, "some persistent volumes held")
+	assertEquals(s.T(), ranks, "other", 0, "persistent volumes not held")
+	assertEquals(s.T(), ranks, "empty", 0, "persistent volumes not held")
+}
+
+func (s *VolumeLocalityNodeRankerSuite) TestNoVolumes() {
+	ranks, err := s.ranker.RankNodes(context.Background(), *mock.Job(), s.nodes)
+	s.Require().NoError(err)
+	assertEquals(s.T(), ranks, "all", 0, "no persistent volumes")
+	assertEquals(s.T(), ranks, "empty", 0, "no persistent volumes")
+}
@@ -0,0 +1,212 @@
+package transformer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	s3helper "github.com/bacalhau-project/bacalhau/pkg/s3"
+	storage_job "github.com/bacalhau-project/bacalhau/pkg/storage/job"
+)
+
+// partitionDirFormat is the directory, relative to the input target, where the results of a partition are mounted
+const partitionDirFormat = "partition-%d"
+
+// JobLookup looks up jobs and their executions
+type JobLookup interface {
+	GetJobByIDOrName(ctx context.Context, idOrName, namespace string) (models.Job, error)
+	GetExecutions(ctx context.Context, options jobstore.GetExecutionsOptions) ([]models.Execution, error)
+}
+
+// JobInputsResolver is a transformer that resolves inputs referencing the results of other jobs
+// into the results published by the selected executions of those jobs, so that compute nodes
+// fetch them with their existing storages. Jobs referencing upstream jobs that did not complete,
+// or whose results cannot be fetched as inputs, are rejected.
+func JobInputsResolver(lookup JobLookup) JobTransformer {
+	f := func(ctx context.Context, job *models.Job) error {
+		for _, task := range job.Tasks {
+			inputs := make([]*models.InputSource, 0, len(task.InputSources))
+			for _, input := range task.InputSources {
+				if input == nil || !input.Source.IsType(models.StorageSourceJob) {
+					inputs = append(inputs, input)
+					continue
+				}
+				resolved, err := resolveJobInput(ctx, lookup, job.Namespace, input)
+				if err != nil {
+					return err
+				}
+				inputs = append(inputs, resolved...)
+			}
+			task.InputSources = inputs
+		}
+		return nil
+	}
+	return JobFn(f)
+}
+
+func resolveJobInput(
+	ctx context.Context, lookup JobLookup, namespace string, input *models.InputSource) ([]*models.InputSource, error) {
+	source, err := storage_job.DecodeSpec(input.Source)
+	if err != nil {
+		return nil, err
+	}
+	upstream, err := lookup.GetJobByIDOrName(ctx, source.JobID, namespace)
+	if err != nil {
+		return nil, bacerrors.Wrapf(err, "failed to find job %s referenced by input %s", source.JobID, input.Target).
+			WithCode(bacerrors.ValidationError)
+	}
+	executions, err := lookup.GetExecutions(ctx, jobstore.GetExecutionsOptions{
+		JobID:     upstream.ID,
+		Namespace: upstream.Namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the latest completed execution of each partition, which is the only one that published results
+	latest := make(map[int]models.Execution)
+	for _, execution := range executions {
+		if execution.ComputeState.StateType != models.ExecutionStateCompleted {
+			continue
+		}
+		if current, ok := latest[execution.PartitionIndex]; !ok || execution.ModifyTime > current.ModifyTime {
+			latest[execution.PartitionIndex] = execution
+		}
+	}
+
+	switch selector := source.Selector(); selector {
+	case storage_job.SelectLatest:
+		if err = checkJobCompleted(upstream); err != nil {
+			return nil, err
+		}
+		var selected *models.Execution
+		for _, execution := range latest {
+			if selected == nil || execution.ModifyTime > selected.ModifyTime {
+				selected = &execution
+			}
+		}
+		if selected == nil {
+			return nil, newJobInputError("job %s has no completed executions", upstream.ID)
+		}
+		result, err := publishedResult(upstream, *selected, source)
+		if err != nil {
+			return nil, err
+		}
+		return []*models.InputSource{{Source: result, Alias: input.Alias, Target: input.Target}}, nil
+
+	case storage_job.SelectAll:
+		if err = checkJobCompleted(upstream); err != nil {
+			return nil, err
+		}
+		partitions := make([]int, 0, len(latest))
+		for partition := range latest {
+			partitions = append(partitions, partition)
+		}
+		slices.Sort(partitions)
+		for partition := 0; partition < upstream.Count; partition++ {
+			if _, ok := latest[partition]; !ok {
+				return nil, newJobInputError("partition %d of job %s has no completed execution", partition, upstream.ID)
+			}
+		}
+		if len(partitions) == 0 {
+			return nil, newJobInputError("job %s has no completed executions", upstream.ID)
+		}
+		resolved := make([]*models.InputSource, 0, len(partitions))
+		for _, partition := range partitions {
+			result, err := publishedResult(upstream, latest[partition], source)
+			if err != nil {
+				return nil, err
+			}
+			partitionDir := fmt.Sprintf(partitionDirFormat, partition)
+			partitionInput := &models.InputSource{
+				Source: result,
+				Target: filepath.Join(input.Target, partitionDir),
+			}
+			if input.Alias != "" {
+				// aliases must be unique within the task
+				partitionInput.Alias = input.Alias + "/" + partitionDir
+			}
+			resolved = append(resolved, partitionInput)
+		}
+		return resolved, nil
+
+	default:
+		// the execution may be selected by its ID or a prefix of it
+		for _, execution := range executions {
+			if !strings.HasPrefix(execution.ID, selector) {
+				continue
+			}
+			if execution.ComputeState.StateType != models.ExecutionStateCompleted {
+				return nil, newJobInputError("execution %s of job %s is %s, and its results are only available once it completes",
+					execution.ID, upstream.ID, execution.ComputeState.StateType)
+			}
+			result, err := publishedResult(upstream, execution, source)
+			if err != nil {
+				return nil, err
+			}
+			return []*models.InputSource{{Source: result, Alias: input.Alias, Target: input.Target}}, nil
+		}
+		return nil, newJobInputError("execution %s not found in job %s", selector, upstream.ID)
+	}
+}
+
+// checkJobCompleted returns an error if the job did not complete, as its results may still change or be missing
+func checkJobCompleted(job models.Job) error {
+	if job.State.StateType == models.JobStateTypeCompleted {
+		return nil
+	}
+	if job.IsTerminal() {
+		return newJobInputError("job %s is %s, and has no results to use as input", job.ID, job.State.StateType).
+			WithHint("Only the results of completed jobs can be used as inputs")
+	}
+	return newJobInputError("job %s is %s, and its results are only available once it completes",
+		job.ID, job.State.StateType).
+		WithHint("Wait for the job to complete, or select one of its completed executions by ID")
+}
+
+// publishedResult returns the results published by the execution, limited to the path of the source if set
+func publishedResult(upstream models.Job, execution models.Execution, source storage_job.SourceSpec) (
+	*models.SpecConfig, error) {
+	if execution.PublishedResult.IsEmpty() {
+		return nil, newJobInputError("execution %s of job %s did not publish results", execution.ID, upstream.ID).
+			WithHint("Set a publisher in the upstream job to use its results as input")
+	}
+	result := execution.PublishedResult.Copy()
+
+	switch result.Type {
+	case models.StorageSourceS3Managed, models.StorageSourceWebhook:
+		// these results can only be downloaded by clients, and not fetched by compute nodes
+		return nil, newJobInputError("results of job %s published with %s cannot be used as input", upstream.ID, result.Type)
+	}
+
+	if source.Path != "" {
+		if !result.IsType(models.StorageSourceS3) {
+			return nil, newJobInputError("a path cannot be selected in results of job %s published with %s",
+				upstream.ID, result.Type)
+		}
+		s3Source, err := s3helper.DecodeSourceSpec(result)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasSuffix(s3Source.Key, "/") {
+			return nil, newJobInputError("a path cannot be selected in archived results of job %s", upstream.ID).
+				WithHint("Publish the results of the upstream job with the plain encoding to select paths in them")
+		}
+		s3Source.Key += strings.TrimPrefix(source.Path, "/")
+		// checksums and versions apply to the archive object only
+		s3Source.ChecksumSHA256 = ""
+		s3Source.VersionID = ""
+		result.Params = s3Source.ToMap()
+	}
+
+	return result, nil
+}
+
+func newJobInputError(format string, args ...any) bacerrors.Error {
+	return bacerrors.Newf(format, args...).WithCode(bacerrors.ValidationError)
+}
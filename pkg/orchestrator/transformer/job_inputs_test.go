@@ -0,0 +1,196 @@
+//go:build unit || !integration
+
+package transformer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	s3helper "github.com/bacalhau-project/bacalhau/pkg/s3"
+	storage_job "github.com/bacalhau-project/bacalhau/pkg/storage/job"
+)
+
+type JobInputsResolverSuite struct {
+	suite.Suite
+	ctx    context.Context
+	lookup *fakeJobLookup
+}
+
+func TestJobInputsResolverSuite(t *testing.T) {
+	suite.Run(t, new(JobInputsResolverSuite))
+}
+
+func (s *JobInputsResolverSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.lookup = &fakeJobLookup{executions: make(map[string][]models.Execution)}
+}
+
+// addJob adds an upstream job with the state and count
+func (s *JobInputsResolverSuite) addJob(id string, state models.JobStateType, count int) {
+	s.lookup.jobs = append(s.lookup.jobs, models.Job{
+		ID:        id,
+		Name:      id + "-name",
+		Namespace: "default",
+		Count:     count,
+		State:     models.NewJobState(state),
+	})
+}
+
+// addExecution adds an execution of the job, which published results to the key if set
+func (s *JobInputsResolverSuite) addExecution(
+	jobID, id string, partition int, state models.ExecutionStateType, modifyTime int64, key string) {
+	execution := models.Execution{
+		ID:             id,
+		JobID:          jobID,
+		PartitionIndex: partition,
+		ComputeState:   models.NewExecutionState(state),
+		ModifyTime:     modifyTime,
+	}
+	if key != "" {
+		execution.PublishedResult = &models.SpecConfig{
+			Type:   models.StorageSourceS3,
+			Params: s3helper.SourceSpec{Bucket: "results", Key: key, VersionID: "v1"}.ToMap(),
+		}
+	}
+	s.lookup.executions[jobID] = append(s.lookup.executions[jobID], execution)
+}
+
+// resolve resolves a job input and returns the resolved inputs of the job
+func (s *JobInputsResolverSuite) resolve(source storage_job.SourceSpec, alias string) ([]*models.InputSource, error) {
+	spec, err := storage_job.NewSpecConfig(source)
+	s.Require().NoError(err)
+	job := &models.Job{
+		Namespace: "default",
+		Tasks: []*models.Task{{
+			Name: "main",
+			InputSources: []*models.InputSource{
+				{Source: &models.SpecConfig{Type: models.StorageSourceInline}, Target: "/other"},
+				{Source: spec, Alias: alias, Target: "/inputs"},
+			},
+		}},
+	}
+	if err = JobInputsResolver(s.lookup).Transform(s.ctx, job); err != nil {
+		return nil, err
+	}
+	s.Require().Equal(models.StorageSourceInline, job.Task().InputSources[0].Source.Type)
+	return job.Task().InputSources[1:], nil
+}
+
+func (s *JobInputsResolverSuite) key(input *models.InputSource) string {
+	source, err := s3helper.DecodeSourceSpec(input.Source)
+	s.Require().NoError(err)
+	return source.Key
+}
+
+func (s *JobInputsResolverSuite) TestResolveLatest() {
+	s.addJob("j-1", models.JobStateTypeCompleted, 1)
+	s.addExecution("j-1", "e-old", 0, models.ExecutionStateCompleted, 1, "old.tar.gz")
+	s.addExecution("j-1", "e-new", 0, models.ExecutionStateCompleted, 2, "new.tar.gz")
+	s.addExecution("j-1", "e-failed", 0, models.ExecutionStateFailed, 3, "")
+
+	for _, jobID := range []string{"j-1", "j-1-name"} {
+		inputs, err := s.resolve(storage_job.SourceSpec{JobID: jobID}, "upstream")
+		s.Require().NoError(err)
+		s.Require().Len(inputs, 1)
+		s.Equal(models.StorageSourceS3, inputs[0].Source.Type)
+		s.Equal("new.tar.gz", s.key(inputs[0]))
+		s.Equal("/inputs", inputs[0].Target)
+		s.Equal("upstream", inputs[0].Alias)
+	}
+}
+
+func (s *JobInputsResolverSuite) TestResolveExecution() {
+	s.addJob("j-1", models.JobStateTypeRunning, 1)
+	s.addExecution("j-1", "e-123456", 0, models.ExecutionStateCompleted, 1, "first.tar.gz")
+	s.addExecution("j-1", "e-789012", 0, models.ExecutionStateBidAccepted, 2, "")
+
+	// completed executions can be used before the job completes
+	inputs, err := s.resolve(storage_job.SourceSpec{JobID: "j-1", ExecutionSelector: "e-123"}, "")
+	s.Require().NoError(err)
+	s.Require().Len(inputs, 1)
+	s.Equal("first.tar.gz", s.key(inputs[0]))
+
+	_, err = s.resolve(storage_job.SourceSpec{JobID: "j-1", ExecutionSelector: "e-789012"}, "")
+	s.requireValidationError(err)
+	_, err = s.resolve(storage_job.SourceSpec{JobID: "j-1", ExecutionSelector: "e-missing"}, "")
+	s.requireValidationError(err)
+}
+
+func (s *JobInputsResolverSuite) TestResolveAllPartitions() {
+	s.addJob("j-1", models.JobStateTypeCompleted, 2)
+	s.addExecution("j-1", "e-1", 1, models.ExecutionStateCompleted, 1, "part-1/")
+	s.addExecution("j-1", "e-0", 0, models.ExecutionStateCompleted, 2, "part-0/")
+
+	inputs, err := s.resolve(storage_job.SourceSpec{JobID: "j-1", ExecutionSelector: storage_job.SelectAll, Path: "outputs/"}, "data")
+	s.Require().NoError(err)
+	s.Require().Len(inputs, 2)
+	for i, input := range inputs {
+		partitionDir := []string{"partition-0", "partition-1"}[i]
+		s.Equal("/inputs/"+partitionDir, input.Target)
+		s.Equal("data/"+partitionDir, input.Alias)
+		s.Equal([]string{"part-0/outputs/", "part-1/outputs/"}[i], s.key(input))
+
+		// the version of the published archive does not apply to paths within the results
+		source, err := s3helper.DecodeSourceSpec(input.Source)
+		s.Require().NoError(err)
+		s.Empty(source.VersionID)
+	}
+}
+
+func (s *JobInputsResolverSuite) TestResolveAllWithMissingPartition() {
+	s.addJob("j-1", models.JobStateTypeCompleted, 2)
+	s.addExecution("j-1", "e-0", 0, models.ExecutionStateCompleted, 1, "part-0/")
+	s.addExecution("j-1", "e-1", 1, models.ExecutionStateFailed, 2, "")
+
+	_, err := s.resolve(storage_job.SourceSpec{JobID: "j-1", ExecutionSelector: storage_job.SelectAll}, "")
+	s.requireValidationError(err)
+}
+
+func (s *JobInputsResolverSuite) TestRejectsUnusableUpstreams() {
+	s.addJob("j-running", models.JobStateTypeRunning, 1)
+	s.addExecution("j-running", "e-1", 0, models.ExecutionStateCompleted, 1, "results.tar.gz")
+	s.addJob("j-failed", models.JobStateTypeFailed, 1)
+	s.addJob("j-unpublished", models.JobStateTypeCompleted, 1)
+	s.addExecution("j-unpublished", "e-2", 0, models.ExecutionStateCompleted, 1, "")
+	s.addJob("j-archived", models.JobStateTypeCompleted, 1)
+	s.addExecution("j-archived", "e-3", 0, models.ExecutionStateCompleted, 1, "results.tar.gz")
+
+	for _, source := range []storage_job.SourceSpec{
+		{JobID: "j-missing"},
+		{JobID: "j-running"},
+		{JobID: "j-failed"},
+		{JobID: "j-unpublished"},
+		{JobID: "j-archived", Path: "outputs"},
+	} {
+		_, err := s.resolve(source, "")
+		s.requireValidationError(err)
+	}
+}
+
+func (s *JobInputsResolverSuite) requireValidationError(err error) {
+	s.Require().Error(err)
+	s.True(bacerrors.IsErrorWithCode(err, bacerrors.ValidationError), err.Error())
+}
+
+type fakeJobLookup struct {
+	jobs       []models.Job
+	executions map[string][]models.Execution
+}
+
+func (l *fakeJobLookup) GetJobByIDOrName(_ context.Context, idOrName, namespace string) (models.Job, error) {
+	for _, job := range l.jobs {
+		if (job.ID == idOrName || job.Name == idOrName) && job.Namespace == namespace {
+			return job, nil
+		}
+	}
+	return models.Job{}, jobstore.NewErrJobNotFound(idOrName)
+}
+
+func (l *fakeJobLookup) GetExecutions(_ context.Context, options jobstore.GetExecutionsOptions) ([]models.Execution, error) {
+	return l.executions[options.JobID], nil
+}
@@ -0,0 +1,48 @@
+package transformer
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+// SecretLookup looks up secrets held by the orchestrator
+type SecretLookup interface {
+	Get(name string) (string, error)
+}
+
+// OrchestratorSecretsValidator is a transformer that rejects jobs referencing orchestrator
+// secrets ("secret:orchestrator/<name>") that do not exist, so that such jobs fail at
+// submission instead of when executions are assigned to nodes. References are checked in
+// environment variables, and in the params of publishers and input sources.
+// A nil store means orchestrator secrets are disabled.
+func OrchestratorSecretsValidator(store SecretLookup) JobTransformer {
+	f := func(ctx context.Context, job *models.Job) error {
+		for _, task := range job.Tasks {
+			// references are only checked, and returned unchanged
+			err := task.ReplaceSecretRefs(func(location string, value models.EnvVarValue) (models.EnvVarValue, error) {
+				backend, key, _ := value.SecretRef()
+				if backend != models.SecretBackendOrchestrator {
+					return value, nil
+				}
+				if store == nil {
+					return "", bacerrors.Newf("%s references an orchestrator secret, "+
+						"but orchestrator secrets are not enabled", location).
+						WithCode(bacerrors.ValidationError).
+						WithHint("Enable Orchestrator.Secrets in the orchestrator's configuration")
+				}
+				if _, err := store.Get(key); err != nil {
+					return "", bacerrors.Wrapf(err, "%s references an unknown secret", location).
+						WithCode(bacerrors.ValidationError)
+				}
+				return value, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return JobFn(f)
+}
@@ -0,0 +1,107 @@
+//go:build unit || !integration
+
+package transformer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+// mapSecretLookup is an in-memory SecretLookup
+type mapSecretLookup map[string]string
+
+func (m mapSecretLookup) Get(name string) (string, error) {
+	value, ok := m[name]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return value, nil
+}
+
+type OrchestratorSecretsValidatorSuite struct {
+	suite.Suite
+	ctx context.Context
+}
+
+func TestOrchestratorSecretsValidatorSuite(t *testing.T) {
+	suite.Run(t, new(OrchestratorSecretsValidatorSuite))
+}
+
+func (s *OrchestratorSecretsValidatorSuite) SetupTest() {
+	s.ctx = context.Background()
+}
+
+func (s *OrchestratorSecretsValidatorSuite) TestValidatesReferences() {
+	store := mapSecretLookup{"token": "hunter2"}
+	for _, tc := range []struct {
+		name     string
+		task     func(task *models.Task, ref string)
+		location string
+	}{
+		{
+			name: "environment variable",
+			task: func(task *models.Task, ref string) {
+				task.Env = map[string]models.EnvVarValue{"TOKEN": models.EnvVarValue(ref)}
+			},
+			location: "environment variable TOKEN",
+		},
+		{
+			name: "webhook header",
+			task: func(task *models.Task, ref string) {
+				task.Publisher = &models.SpecConfig{Type: models.PublisherWebhook, Params: map[string]any{
+					"Headers": map[string]any{"Authorization": ref},
+				}}
+			},
+			location: "publisher param Headers.Authorization",
+		},
+		{
+			name: "git token",
+			task: func(task *models.Task, ref string) {
+				task.InputSources = []*models.InputSource{{
+					Source: &models.SpecConfig{Type: models.StorageSourceGit, Params: map[string]any{"Token": ref}},
+					Target: "/repo",
+				}}
+			},
+			location: "input source 0 param Token",
+		},
+		{
+			name: "url header",
+			task: func(task *models.Task, ref string) {
+				task.InputSources = []*models.InputSource{{
+					Source: &models.SpecConfig{Type: models.StorageSourceURL, Params: map[string]any{
+						"Headers": map[string]string{"X-Key": ref},
+					}},
+					Target: "/data",
+				}}
+			},
+			location: "input source 0 param Headers.X-Key",
+		},
+	} {
+		s.Run(tc.name, func() {
+			job := mock.Job()
+			tc.task(job.Task(), "secret:orchestrator/token")
+			s.Require().NoError(OrchestratorSecretsValidator(store).Transform(s.ctx, job))
+
+			// other backends are resolved by the compute nodes
+			tc.task(job.Task(), "secret:vault/kv/data/app#token")
+			s.Require().NoError(OrchestratorSecretsValidator(store).Transform(s.ctx, job))
+
+			tc.task(job.Task(), "secret:orchestrator/missing")
+			err := OrchestratorSecretsValidator(store).Transform(s.ctx, job)
+			s.Require().ErrorContains(err, tc.location+" references an unknown secret")
+			s.True(bacerrors.IsErrorWithCode(err, bacerrors.ValidationError))
+
+			tc.task(job.Task(), "secret:orchestrator/token")
+			err = OrchestratorSecretsValidator(nil).Transform(s.ctx, job)
+			s.Require().ErrorContains(err, tc.location+" references an orchestrator secret")
+			s.True(bacerrors.IsErrorWithCode(err, bacerrors.ValidationError))
+		})
+	}
+}
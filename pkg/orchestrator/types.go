@@ -1,6 +1,9 @@
 package orchestrator
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/rs/zerolog"
 
 	"github.com/bacalhau-project/bacalhau/pkg/models"
@@ -68,13 +71,44 @@ type ReadLogsResponse struct {
 	ExecutionComplete bool
 }
 
+type ExecRequest struct {
+	JobID       string
+	Namespace   string
+	ExecutionID string
+	Command     []string
+	Attach      bool
+	TTY         bool
+	Stdin       bool
+	Height      uint
+	Width       uint
+}
+
 type GetResultsRequest struct {
 	JobID     string
 	Namespace string
+	// ExecutionIDs selects the results of the executions whose IDs start with any of the prefixes. Optional.
+	ExecutionIDs []string
+	// Partitions selects the results of the executions of the partitions. Optional.
+	Partitions []int
+}
+
+// Selects returns true if the execution is selected by the request
+func (r *GetResultsRequest) Selects(execution models.Execution) bool {
+	if len(r.Partitions) > 0 && !slices.Contains(r.Partitions, execution.PartitionIndex) {
+		return false
+	}
+	if len(r.ExecutionIDs) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(r.ExecutionIDs, func(prefix string) bool {
+		return strings.HasPrefix(execution.ID, prefix)
+	})
 }
 
 type GetResultsResponse struct {
 	Results []*models.SpecConfig
+	// Origins are the executions that published the results, in the order of Results
+	Origins []models.ResultOrigin
 }
 
 // NodeRank represents a node and its rank. The higher the rank, the more preferable a node is to execute the job.
@@ -0,0 +1,138 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+const (
+	// DefaultVolumeDeletionRequestTTL is how long requests to delete volumes are kept for nodes
+	// that still advertise the volumes, such as nodes that are disconnected
+	DefaultVolumeDeletionRequestTTL = 24 * time.Hour
+)
+
+type VolumeJanitorParams struct {
+	JobStore jobstore.Store
+	// RequestTTL is how long requests to delete volumes are kept. Defaults to DefaultVolumeDeletionRequestTTL.
+	RequestTTL time.Duration
+	Clock      clock.Clock
+}
+
+// VolumeJanitor decides which of the persistent volumes advertised by compute nodes they should delete,
+// which are the volumes of stopped or deleted jobs and the volumes whose deletion was requested.
+// Compute nodes are told to delete the volumes in heartbeat responses until they no longer advertise them,
+// which makes the deletion of volumes of stopped jobs survive orchestrator restarts and disconnected nodes.
+type VolumeJanitor struct {
+	jobStore   jobstore.Store
+	requestTTL time.Duration
+	clock      clock.Clock
+
+	mu sync.Mutex
+	// requests are the requested volume deletions per node, with the time they were requested
+	requests map[string]map[models.VolumeRef]time.Time
+}
+
+// NewVolumeJanitor creates a new VolumeJanitor instance
+func NewVolumeJanitor(params VolumeJanitorParams) *VolumeJanitor {
+	if params.RequestTTL <= 0 {
+		params.RequestTTL = DefaultVolumeDeletionRequestTTL
+	}
+	if params.Clock == nil {
+		params.Clock = clock.New()
+	}
+	return &VolumeJanitor{
+		jobStore:   params.JobStore,
+		requestTTL: params.RequestTTL,
+		clock:      params.Clock,
+		requests:   make(map[string]map[models.VolumeRef]time.Time),
+	}
+}
+
+// RequestDeletion requests the node to delete the referenced volumes
+func (j *VolumeJanitor) RequestDeletion(nodeID string, refs ...models.VolumeRef) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.requests[nodeID] == nil {
+		j.requests[nodeID] = make(map[models.VolumeRef]time.Time)
+	}
+	for _, ref := range refs {
+		j.requests[nodeID][ref] = j.clock.Now()
+	}
+}
+
+// VolumeDeletions returns the volumes advertised by the node that it should delete
+func (j *VolumeJanitor) VolumeDeletions(ctx context.Context, nodeInfo models.NodeInfo) []models.VolumeRef {
+	volumes := nodeInfo.ComputeNodeInfo.Volumes
+	requested := j.pendingRequests(nodeInfo.ID(), volumes)
+
+	var deletions []models.VolumeRef
+	jobDeleted := make(map[string]bool)
+	for _, volume := range volumes {
+		deleted, checked := jobDeleted[volume.JobID]
+		if !checked {
+			deleted = j.isJobDeleted(ctx, volume.JobID)
+			jobDeleted[volume.JobID] = deleted
+		}
+		if deleted || matchesAnyVolume(requested, volume) {
+			deletions = append(deletions, volume.Ref())
+		}
+	}
+	return deletions
+}
+
+// pendingRequests returns the requested deletions of the node, and forgets the requests
+// of volumes that the node no longer advertises or that expired
+func (j *VolumeJanitor) pendingRequests(nodeID string, volumes []models.VolumeInfo) []models.VolumeRef {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	requests := j.requests[nodeID]
+	var pending []models.VolumeRef
+	for ref, requestTime := range requests {
+		advertised := false
+		for _, volume := range volumes {
+			if ref.Matches(volume.JobID, volume.Name) {
+				advertised = true
+				break
+			}
+		}
+		if !advertised || j.clock.Since(requestTime) > j.requestTTL {
+			delete(requests, ref)
+			continue
+		}
+		pending = append(pending, ref)
+	}
+	if len(requests) == 0 {
+		delete(j.requests, nodeID)
+	}
+	return pending
+}
+
+// isJobDeleted returns true if the job was stopped by the user or no longer exists
+func (j *VolumeJanitor) isJobDeleted(ctx context.Context, jobID string) bool {
+	job, err := j.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		if bacerrors.IsErrorWithCode(err, bacerrors.NotFoundError) {
+			return true
+		}
+		log.Ctx(ctx).Warn().Err(err).Str("job", jobID).Msg("Failed to get job of persistent volume")
+		return false
+	}
+	return job.State.StateType == models.JobStateTypeStopped
+}
+
+func matchesAnyVolume(refs []models.VolumeRef, volume models.VolumeInfo) bool {
+	for _, ref := range refs {
+		if ref.Matches(volume.JobID, volume.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+//go:build unit || !integration
+
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+type VolumeJanitorTestSuite struct {
+	suite.Suite
+	ctx          context.Context
+	clock        *clock.Mock
+	mockJobStore *jobstore.MockStore
+	janitor      *VolumeJanitor
+	runningJob   *models.Job
+	stoppedJob   *models.Job
+	nodeInfo     models.NodeInfo
+}
+
+func TestVolumeJanitorTestSuite(t *testing.T) {
+	suite.Run(t, new(VolumeJanitorTestSuite))
+}
+
+func (s *VolumeJanitorTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.clock = clock.NewMock()
+	s.mockJobStore = jobstore.NewMockStore(gomock.NewController(s.T()))
+	s.janitor = NewVolumeJanitor(VolumeJanitorParams{
+		JobStore:   s.mockJobStore,
+		RequestTTL: time.Hour,
+		Clock:      s.clock,
+	})
+
+	s.runningJob = mock.Job()
+	s.runningJob.State = models.NewJobState(models.JobStateTypeRunning)
+	s.stoppedJob = mock.Job()
+	s.stoppedJob.State = models.NewJobState(models.JobStateTypeStopped)
+	s.mockJobStore.EXPECT().GetJob(gomock.Any(), s.runningJob.ID).Return(*s.runningJob, nil).AnyTimes()
+	s.mockJobStore.EXPECT().GetJob(gomock.Any(), s.stoppedJob.ID).Return(*s.stoppedJob, nil).AnyTimes()
+	s.mockJobStore.EXPECT().GetJob(gomock.Any(), "deleted-job").
+		Return(models.Job{}, jobstore.NewErrJobNotFound("deleted-job")).AnyTimes()
+
+	s.nodeInfo = models.NodeInfo{
+		NodeID: "node-1",
+		ComputeNodeInfo: models.ComputeNodeInfo{
+			Volumes: []models.VolumeInfo{
+				{JobID: s.runningJob.ID, Name: "data"},
+				{JobID: s.runningJob.ID, Name: "cache"},
+				{JobID: s.stoppedJob.ID, Name: "data"},
+				{JobID: "deleted-job", Name: "data"},
+			},
+		},
+	}
+}
+
+func (s *VolumeJanitorTestSuite) TestVolumesOfStoppedAndDeletedJobs() {
+	s.ElementsMatch([]models.VolumeRef{
+		{JobID: s.stoppedJob.ID, Name: "data"},
+		{JobID: "deleted-job", Name: "data"},
+	}, s.janitor.VolumeDeletions(s.ctx, s.nodeInfo))
+}
+
+func (s *VolumeJanitorTestSuite) TestRequestedDeletions() {
+	s.janitor.RequestDeletion("node-1", models.VolumeRef{JobID: s.runningJob.ID, Name: "cache"})
+	s.janitor.RequestDeletion("node-2", models.VolumeRef{JobID: s.runningJob.ID})
+
+	// requests are repeated until the node stops advertising the volumes
+	for i := 0; i < 2; i++ {
+		s.Contains(s.janitor.VolumeDeletions(s.ctx, s.nodeInfo), models.VolumeRef{JobID: s.runningJob.ID, Name: "cache"})
+	}
+	s.nodeInfo.ComputeNodeInfo.Volumes = s.nodeInfo.ComputeNodeInfo.Volumes[:1]
+	s.Empty(s.janitor.VolumeDeletions(s.ctx, s.nodeInfo))
+	s.nodeInfo.ComputeNodeInfo.Volumes = append(s.nodeInfo.ComputeNodeInfo.Volumes,
+		models.VolumeInfo{JobID: s.runningJob.ID, Name: "cache"})
+	s.Empty(s.janitor.VolumeDeletions(s.ctx, s.nodeInfo), "request should be forgotten once satisfied")
+}
+
+func (s *VolumeJanitorTestSuite) TestRequestsExpire() {
+	s.janitor.RequestDeletion("node-1", models.VolumeRef{JobID: s.runningJob.ID})
+	s.clock.Add(2 * time.Hour)
+	s.NotContains(s.janitor.VolumeDeletions(s.ctx, s.nodeInfo), models.VolumeRef{JobID: s.runningJob.ID, Name: "data"})
+}
@@ -0,0 +1,153 @@
+package watchers
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/distribution/reference"
+	"github.com/rs/zerolog/log"
+
+	dockermodels "github.com/bacalhau-project/bacalhau/pkg/executor/docker/models"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/watcher"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+const (
+	// DefaultImagePopularityWindow is how long new executions count towards the popularity of their image
+	DefaultImagePopularityWindow = 24 * time.Hour
+	// DefaultMaxImageHints is the number of most popular images hinted to compute nodes
+	DefaultMaxImageHints = 10
+
+	// imagePopularityBuckets is the number of buckets executions are counted in over the window,
+	// so that old executions expire without tracking each of them
+	imagePopularityBuckets = 24
+)
+
+type ImagePopularityParams struct {
+	// Window is how long new executions count towards the popularity of their image.
+	// Defaults to DefaultImagePopularityWindow.
+	Window time.Duration
+	// MaxHints is the number of most popular images hinted to compute nodes. Defaults to DefaultMaxImageHints.
+	MaxHints int
+	Clock    clock.Clock
+}
+
+// ImagePopularity counts the docker images of recently created executions, and provides the most popular
+// images as hints for compute nodes to pull ahead of time, so that executions don't wait for cold image pulls.
+type ImagePopularity struct {
+	bucketSize time.Duration
+	maxHints   int
+	clock      clock.Clock
+
+	mu sync.Mutex
+	// buckets counts the executions of each image per time bucket, keyed by the bucket index
+	buckets map[int64]map[string]int
+}
+
+// NewImagePopularity creates a new ImagePopularity instance
+func NewImagePopularity(params ImagePopularityParams) *ImagePopularity {
+	if params.Window <= 0 {
+		params.Window = DefaultImagePopularityWindow
+	}
+	if params.MaxHints <= 0 {
+		params.MaxHints = DefaultMaxImageHints
+	}
+	if params.Clock == nil {
+		params.Clock = clock.New()
+	}
+	return &ImagePopularity{
+		bucketSize: params.Window / imagePopularityBuckets,
+		maxHints:   params.MaxHints,
+		clock:      params.Clock,
+		buckets:    make(map[int64]map[string]int),
+	}
+}
+
+// HandleEvent counts the image of newly created docker executions
+func (p *ImagePopularity) HandleEvent(ctx context.Context, event watcher.Event) error {
+	if event.ObjectType != jobstore.EventObjectExecutionUpsert {
+		return nil
+	}
+	upsert, ok := event.Object.(models.ExecutionUpsert)
+	if !ok || upsert.Previous != nil || upsert.Current == nil || upsert.Current.Job == nil {
+		return nil
+	}
+	task := upsert.Current.Job.Task()
+	if task == nil || task.Engine == nil || task.Engine.Type != models.EngineDocker {
+		return nil
+	}
+	engine, err := dockermodels.DecodeSpec(task.Engine)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("execution_id", upsert.Current.ID).Msg("Skipping invalid docker engine spec")
+		return nil
+	}
+	image := normalizeImage(engine.Image)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	current := p.bucket(p.clock.Now())
+	p.expire(current)
+	if p.buckets[current] == nil {
+		p.buckets[current] = make(map[string]int)
+	}
+	p.buckets[current][image]++
+	return nil
+}
+
+// ImageHints returns the most popular images of recent executions, most popular first
+func (p *ImagePopularity) ImageHints() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.expire(p.bucket(p.clock.Now()))
+
+	counts := make(map[string]int)
+	for _, bucket := range p.buckets {
+		for image, count := range bucket {
+			counts[image] += count
+		}
+	}
+	images := make([]string, 0, len(counts))
+	for image := range counts {
+		images = append(images, image)
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if counts[images[i]] != counts[images[j]] {
+			return counts[images[i]] > counts[images[j]]
+		}
+		return images[i] < images[j]
+	})
+	if len(images) > p.maxHints {
+		images = images[:p.maxHints]
+	}
+	return images
+}
+
+func (p *ImagePopularity) bucket(t time.Time) int64 {
+	return t.UnixNano() / int64(p.bucketSize)
+}
+
+// expire removes the buckets that fell out of the window. It must be called with mu held.
+func (p *ImagePopularity) expire(current int64) {
+	for index := range p.buckets {
+		if current-index >= imagePopularityBuckets {
+			delete(p.buckets, index)
+		}
+	}
+}
+
+// normalizeImage normalizes the image so that references to the same image count together,
+// e.g. "ubuntu" and "docker.io/library/ubuntu:latest"
+func normalizeImage(image string) string {
+	ref, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image
+	}
+	return reference.FamiliarString(reference.TagNameOnly(ref))
+}
+
+// compile-time check that ImagePopularity implements watcher.EventHandler
+var _ watcher.EventHandler = (*ImagePopularity)(nil)
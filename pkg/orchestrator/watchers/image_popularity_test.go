@@ -0,0 +1,90 @@
+//go:build unit || !integration
+
+package watchers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/suite"
+
+	dockermodels "github.com/bacalhau-project/bacalhau/pkg/executor/docker/models"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/watcher"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+type ImagePopularityTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	clock      *clock.Mock
+	popularity *ImagePopularity
+}
+
+func TestImagePopularityTestSuite(t *testing.T) {
+	suite.Run(t, new(ImagePopularityTestSuite))
+}
+
+func (s *ImagePopularityTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.clock = clock.NewMock()
+	s.popularity = NewImagePopularity(ImagePopularityParams{
+		Window:   24 * time.Hour,
+		MaxHints: 2,
+		Clock:    s.clock,
+	})
+}
+
+// createExecution records a new execution of a docker job running the image
+func (s *ImagePopularityTestSuite) createExecution(image string) {
+	job := mock.Job()
+	job.Task().Engine = dockermodels.NewDockerEngineBuilder(image).MustBuild()
+	s.Require().NoError(s.popularity.HandleEvent(s.ctx, watcher.Event{
+		ObjectType: jobstore.EventObjectExecutionUpsert,
+		Object:     models.ExecutionUpsert{Current: mock.ExecutionForJob(job)},
+	}))
+}
+
+func (s *ImagePopularityTestSuite) TestMostPopularImagesAreHinted() {
+	s.createExecution("ubuntu:24.04")
+	s.createExecution("docker.io/library/ubuntu:24.04")
+	s.createExecution("python")
+	s.createExecution("python:latest")
+	s.createExecution("python:latest")
+	s.createExecution("alpine")
+
+	s.Equal([]string{"python:latest", "ubuntu:24.04"}, s.popularity.ImageHints())
+}
+
+func (s *ImagePopularityTestSuite) TestOldExecutionsExpire() {
+	s.createExecution("ubuntu")
+	s.createExecution("ubuntu")
+	s.clock.Add(20 * time.Hour)
+	s.createExecution("python")
+	s.Equal([]string{"ubuntu:latest", "python:latest"}, s.popularity.ImageHints())
+
+	s.clock.Add(5 * time.Hour)
+	s.Equal([]string{"python:latest"}, s.popularity.ImageHints())
+}
+
+func (s *ImagePopularityTestSuite) TestOnlyNewDockerExecutionsAreCounted() {
+	// updates of existing executions
+	upsert := setupStateTransition(
+		models.ExecutionDesiredStatePending, models.ExecutionStateNew,
+		models.ExecutionDesiredStateRunning, models.ExecutionStateBidAccepted)
+	s.Require().NoError(s.popularity.HandleEvent(s.ctx, watcher.Event{
+		ObjectType: jobstore.EventObjectExecutionUpsert,
+		Object:     upsert,
+	}))
+
+	// executions of jobs with other engines
+	s.Require().NoError(s.popularity.HandleEvent(s.ctx, watcher.Event{
+		ObjectType: jobstore.EventObjectExecutionUpsert,
+		Object:     setupNewExecution(models.ExecutionDesiredStatePending, models.ExecutionStateNew),
+	}))
+
+	s.Empty(s.popularity.ImageHints())
+}
@@ -0,0 +1,36 @@
+package apimodels
+
+type GetBlobRequest struct {
+	BaseGetRequest
+	Digest string
+}
+
+type GetBlobResponse struct {
+	BaseGetResponse
+	Digest string `json:"Digest"`
+	Size   int64  `json:"Size"`
+}
+
+type PutBlobChunkRequest struct {
+	BasePutRequest
+	Digest string `json:"-"`
+	Index  int    `json:"-"`
+	Data   []byte `json:"Data"`
+}
+
+type PutBlobChunkResponse struct {
+	BasePutResponse
+}
+
+type CommitBlobRequest struct {
+	BasePutRequest
+	Digest string `json:"-"`
+	// Chunks is the number of chunks the blob was uploaded in
+	Chunks int `json:"Chunks"`
+}
+
+type CommitBlobResponse struct {
+	BasePutResponse
+	Digest string `json:"Digest"`
+	Size   int64  `json:"Size"`
+}
@@ -176,11 +176,31 @@ type ListJobVersionsResponse struct {
 type ListJobResultsRequest struct {
 	BaseListRequest
 	JobID string `query:"-"`
+	// ExecutionIDs selects the results of the executions whose IDs start with any of the prefixes
+	ExecutionIDs []string `query:"execution_id" validate:"omitempty"`
+	// Partitions selects the results of the executions of the partitions
+	Partitions []int `query:"partition" validate:"omitempty"`
+}
+
+// ToHTTPRequest is used to convert the request to an HTTP request
+func (o *ListJobResultsRequest) ToHTTPRequest() *HTTPRequest {
+	r := o.BaseListRequest.ToHTTPRequest()
+
+	for _, executionID := range o.ExecutionIDs {
+		r.Params.Add("execution_id", executionID)
+	}
+	for _, partition := range o.Partitions {
+		r.Params.Add("partition", strconv.Itoa(partition))
+	}
+	return r
 }
 
 type ListJobResultsResponse struct {
 	BaseListResponse
 	Items []*models.SpecConfig `json:"Items"`
+	// Origins are the executions that published the results, in the order of Items.
+	// Downloads verify that the manifests of the results were signed for them.
+	Origins []models.ResultOrigin `json:"Origins,omitempty"`
 }
 
 type StopJobRequest struct {
@@ -240,3 +260,40 @@ func (o *GetLogsRequest) ToHTTPRequest() *HTTPRequest {
 	}
 	return r
 }
+
+type ExecRequest struct {
+	BaseGetRequest
+	JobID       string   `query:"-"`
+	ExecutionID string   `query:"-"`
+	Command     []string `query:"command"`
+	Attach      bool     `query:"attach"`
+	TTY         bool     `query:"tty"`
+	Stdin       bool     `query:"stdin"`
+	Height      uint     `query:"height"`
+	Width       uint     `query:"width"`
+}
+
+// ToHTTPRequest is used to convert the request to an HTTP request
+func (o *ExecRequest) ToHTTPRequest() *HTTPRequest {
+	r := o.BaseGetRequest.ToHTTPRequest()
+
+	for _, arg := range o.Command {
+		r.Params.Add("command", arg)
+	}
+	if o.Attach {
+		r.Params.Set("attach", "true")
+	}
+	if o.TTY {
+		r.Params.Set("tty", "true")
+	}
+	if o.Stdin {
+		r.Params.Set("stdin", "true")
+	}
+	if o.Height != 0 {
+		r.Params.Set("height", strconv.FormatUint(uint64(o.Height), 10))
+	}
+	if o.Width != 0 {
+		r.Params.Set("width", strconv.FormatUint(uint64(o.Width), 10))
+	}
+	return r
+}
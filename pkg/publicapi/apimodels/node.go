@@ -83,3 +83,28 @@ func (n NodeAction) Description() string {
 func (n NodeAction) IsValid() bool {
 	return n == NodeActionApprove || n == NodeActionReject || n == NodeActionDelete
 }
+
+type ListNodeVolumesRequest struct {
+	BaseGetRequest
+	NodeID string
+}
+
+type ListNodeVolumesResponse struct {
+	BaseGetResponse
+	Volumes []models.VolumeInfo `json:"Volumes"`
+}
+
+// DeleteNodeVolumesRequest requests a compute node to delete the persistent volumes of a job.
+// All the volumes of the job are deleted if Name is empty.
+type DeleteNodeVolumesRequest struct {
+	BasePutRequest
+	NodeID string `json:"-"`
+	JobID  string `json:"JobID"`
+	Name   string `json:"Name,omitempty"`
+}
+
+type DeleteNodeVolumesResponse struct {
+	BasePutResponse
+	// Volumes are the volumes whose deletion was requested, which the node deletes on its next heartbeat
+	Volumes []models.VolumeRef `json:"Volumes"`
+}
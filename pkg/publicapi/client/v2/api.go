@@ -6,6 +6,7 @@ package client
 type API interface {
 	Agent() *Agent
 	Auth() *Auth
+	Blobs() *Blobs
 	Jobs() *Jobs
 	Nodes() *Nodes
 }
@@ -22,6 +23,10 @@ func (c *api) Auth() *Auth {
 	return &Auth{client: c.Client}
 }
 
+func (c *api) Blobs() *Blobs {
+	return &Blobs{client: c.Client}
+}
+
 func (c *api) Jobs() *Jobs {
 	return &Jobs{client: c.Client}
 }
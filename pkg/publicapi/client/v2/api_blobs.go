@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/apimodels"
+)
+
+const blobsPath = "/api/v1/orchestrator/blobs"
+
+// Blobs uploads content-addressed blobs, such as archives of local job inputs, to the orchestrator
+type Blobs struct {
+	client Client
+}
+
+// Get is used to get the info of a committed blob by digest.
+func (c *Blobs) Get(ctx context.Context, r *apimodels.GetBlobRequest) (*apimodels.GetBlobResponse, error) {
+	var resp apimodels.GetBlobResponse
+	if err := c.client.Get(ctx, blobsPath+"/"+r.Digest, r, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PutChunk is used to upload a chunk of a blob.
+func (c *Blobs) PutChunk(ctx context.Context, r *apimodels.PutBlobChunkRequest) (*apimodels.PutBlobChunkResponse, error) {
+	var resp apimodels.PutBlobChunkResponse
+	if err := c.client.Put(ctx, blobsPath+"/"+r.Digest+"/chunks/"+strconv.Itoa(r.Index), r, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Commit is used to assemble the uploaded chunks of a blob into the blob.
+func (c *Blobs) Commit(ctx context.Context, r *apimodels.CommitBlobRequest) (*apimodels.CommitBlobResponse, error) {
+	var resp apimodels.CommitBlobResponse
+	if err := c.client.Post(ctx, blobsPath+"/"+r.Digest, r, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -116,3 +116,13 @@ func (j *Jobs) Rerun(ctx context.Context, r *apimodels.RerunJobRequest) (*apimod
 func (j *Jobs) Logs(ctx context.Context, r *apimodels.GetLogsRequest) (<-chan *concurrency.AsyncResult[models.ExecutionLog], error) {
 	return DialAsyncResult[*apimodels.GetLogsRequest, models.ExecutionLog](ctx, j.client, jobsPath+"/"+r.JobID+"/logs", r)
 }
+
+// Exec opens an interactive session into a running execution of a job.
+func (j *Jobs) Exec(ctx context.Context, r *apimodels.ExecRequest) (*ExecSession, error) {
+	conn, err := j.client.DialConn(ctx,
+		jobsPath+"/"+url.PathEscape(r.JobID)+"/executions/"+url.PathEscape(r.ExecutionID)+"/exec", r)
+	if err != nil {
+		return nil, err
+	}
+	return newExecSession(conn), nil
+}
@@ -38,3 +38,23 @@ func (c *Nodes) Put(ctx context.Context, r *apimodels.PutNodeRequest) (*apimodel
 	}
 	return &resp, nil
 }
+
+// ListVolumes is used to list the persistent volumes held by a compute node.
+func (c *Nodes) ListVolumes(
+	ctx context.Context, r *apimodels.ListNodeVolumesRequest) (*apimodels.ListNodeVolumesResponse, error) {
+	var resp apimodels.ListNodeVolumesResponse
+	if err := c.client.Get(ctx, nodesPath+"/"+r.NodeID+"/volumes", r, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteVolumes is used to request a compute node to delete the persistent volumes of a job.
+func (c *Nodes) DeleteVolumes(
+	ctx context.Context, r *apimodels.DeleteNodeVolumesRequest) (*apimodels.DeleteNodeVolumesResponse, error) {
+	var resp apimodels.DeleteNodeVolumesResponse
+	if err := c.client.Delete(ctx, nodesPath+"/"+r.NodeID+"/volumes", r, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
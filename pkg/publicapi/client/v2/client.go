@@ -34,6 +34,7 @@ type Client interface {
 	Post(context.Context, string, apimodels.PutRequest, apimodels.PutResponse) error
 	Delete(context.Context, string, apimodels.PutRequest, apimodels.Response) error
 	Dial(context.Context, string, apimodels.Request) (<-chan *concurrency.AsyncResult[[]byte], error)
+	DialConn(context.Context, string, apimodels.Request) (*websocket.Conn, error)
 }
 
 // New creates a new transport.
@@ -152,28 +153,11 @@ func (c *httpClient) Delete(ctx context.Context, endpoint string, in apimodels.P
 // successfully dialed, from which point on the returned channel will contain
 // every received message.
 func (c *httpClient) Dial(ctx context.Context, endpoint string, in apimodels.Request) (<-chan *concurrency.AsyncResult[[]byte], error) {
-	r := in.ToHTTPRequest()
-	httpR, err := c.toHTTP(ctx, http.MethodGet, endpoint, r)
+	conn, err := c.DialConn(ctx, endpoint, in)
 	if err != nil {
 		return nil, err
 	}
 
-	dialer := *websocket.DefaultDialer
-	httpR.URL.Scheme = "ws"
-
-	// if we are using TLS create a TLS config
-	if c.config.TLS.UseTLS {
-		httpR.URL.Scheme = "wss"
-		dialer.TLSClientConfig = getTLSTransport(&c.config).TLSClientConfig
-	}
-
-	// Connect to the server
-	conn, resp, err := dialer.Dial(httpR.URL.String(), httpR.Header)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = resp.Body.Close() }()
-
 	// Read messages from the server, and send them until the conn is closed or
 	// the context is cancelled. We have to read them here because the reader
 	// will be discarded upon the next call to NextReader.
@@ -213,6 +197,34 @@ func (c *httpClient) Dial(ctx context.Context, endpoint string, in apimodels.Req
 	return output, nil
 }
 
+// DialConn is used to upgrade to a Websocket connection with an endpoint, and
+// returns the connection for bidirectional use. The caller is responsible for
+// closing the connection.
+func (c *httpClient) DialConn(ctx context.Context, endpoint string, in apimodels.Request) (*websocket.Conn, error) {
+	r := in.ToHTTPRequest()
+	httpR, err := c.toHTTP(ctx, http.MethodGet, endpoint, r)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := *websocket.DefaultDialer
+	httpR.URL.Scheme = "ws"
+
+	// if we are using TLS create a TLS config
+	if c.config.TLS.UseTLS {
+		httpR.URL.Scheme = "wss"
+		dialer.TLSClientConfig = getTLSTransport(&c.config).TLSClientConfig
+	}
+
+	// Connect to the server
+	conn, resp, err := dialer.DialContext(ctx, httpR.URL.String(), httpR.Header)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	return conn, nil
+}
+
 // doRequest runs a request with our client
 func (c *httpClient) doRequest(
 	ctx context.Context,
@@ -531,6 +543,19 @@ func (t *AuthenticatingClient) Dial(
 	return output, err
 }
 
+func (t *AuthenticatingClient) DialConn(
+	ctx context.Context,
+	path string,
+	in apimodels.Request,
+) (*websocket.Conn, error) {
+	var conn *websocket.Conn
+	err := doRequest(ctx, t, in, func(req apimodels.Request) (err error) {
+		conn, err = t.Client.DialConn(ctx, path, req)
+		return
+	})
+	return conn, err
+}
+
 func doRequest[R apimodels.Request](ctx context.Context, t *AuthenticatingClient, request R, runRequest func(R) error) (err error) {
 	if t.NewAuthenticationFlowEnabled {
 		// Skip all legacy credential flow
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/concurrency"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+// ExecSession is an interactive session into a running execution.
+// Input is sent with Send, and the output of the session is received from Output
+// until the channel is closed.
+type ExecSession struct {
+	conn      *websocket.Conn
+	output    chan *concurrency.AsyncResult[models.ExecOutput]
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+}
+
+func newExecSession(conn *websocket.Conn) *ExecSession {
+	s := &ExecSession{
+		conn:   conn,
+		output: make(chan *concurrency.AsyncResult[models.ExecOutput]),
+	}
+	go s.read()
+	return s
+}
+
+// Output returns the stream of output frames of the session
+func (s *ExecSession) Output() <-chan *concurrency.AsyncResult[models.ExecOutput] {
+	return s.output
+}
+
+// Send sends a frame of input to the session
+func (s *ExecSession) Send(input models.ExecInput) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteJSON(input)
+}
+
+// Close ends the session
+func (s *ExecSession) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		s.writeMu.Lock()
+		_ = s.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		s.writeMu.Unlock()
+		err = s.conn.Close()
+	})
+	return err
+}
+
+func (s *ExecSession) read() {
+	defer close(s.output)
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure) {
+				s.output <- &concurrency.AsyncResult[models.ExecOutput]{Err: err}
+			}
+			return
+		}
+		result := new(concurrency.AsyncResult[models.ExecOutput])
+		if err = json.Unmarshal(data, result); err != nil {
+			result.Err = err
+		}
+		s.output <- result
+	}
+}
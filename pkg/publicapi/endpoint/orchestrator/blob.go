@@ -0,0 +1,96 @@
+package orchestrator
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/apimodels"
+)
+
+// godoc for Orchestrator GetBlob
+//
+//	@ID				orchestrator/getBlob
+//	@Summary		Returns the info of a blob.
+//	@Description	Returns the info of a committed blob, such as an uploaded job input.
+//	@Tags			Orchestrator
+//	@Produce		json
+//	@Param			digest	path		string	true	"Digest of the blob, such as sha256:<hex>"
+//	@Success		200		{object}	apimodels.GetBlobResponse
+//	@Failure		400		{object}	string
+//	@Failure		404		{object}	string
+//	@Failure		500		{object}	string
+//	@Router			/api/v1/orchestrator/blobs/{digest} [get]
+func (e *Endpoint) getBlob(c echo.Context) error {
+	ctx := c.Request().Context()
+	info, err := e.blobStore.Stat(ctx, c.Param("digest"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, apimodels.GetBlobResponse{
+		Digest: info.Digest,
+		Size:   info.Size,
+	})
+}
+
+// godoc for Orchestrator PutBlobChunk
+//
+//	@ID				orchestrator/putBlobChunk
+//	@Summary		Uploads a chunk of a blob.
+//	@Description	Uploads a chunk of a blob. The blob is not visible until it is committed.
+//	@Tags			Orchestrator
+//	@Accept			json
+//	@Produce		json
+//	@Param			digest				path		string							true	"Digest of the blob, such as sha256:<hex>"
+//	@Param			index				path		int								true	"Index of the chunk, from 0"
+//	@Param			putBlobChunkRequest	body		apimodels.PutBlobChunkRequest	true	"Chunk to upload"
+//	@Success		200					{object}	apimodels.PutBlobChunkResponse
+//	@Failure		400					{object}	string
+//	@Failure		500					{object}	string
+//	@Router			/api/v1/orchestrator/blobs/{digest}/chunks/{index} [put]
+func (e *Endpoint) putBlobChunk(c echo.Context) error {
+	ctx := c.Request().Context()
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid chunk index")
+	}
+	var args apimodels.PutBlobChunkRequest
+	if err = c.Bind(&args); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err = e.blobStore.PutChunk(ctx, c.Param("digest"), index, args.Data); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, apimodels.PutBlobChunkResponse{})
+}
+
+// godoc for Orchestrator CommitBlob
+//
+//	@ID				orchestrator/commitBlob
+//	@Summary		Commits an uploaded blob.
+//	@Description	Assembles the uploaded chunks of a blob and verifies its digest. Committing an existing blob is a no-op.
+//	@Tags			Orchestrator
+//	@Accept			json
+//	@Produce		json
+//	@Param			digest				path		string						true	"Digest of the blob, such as sha256:<hex>"
+//	@Param			commitBlobRequest	body		apimodels.CommitBlobRequest	true	"Commit Blob Request"
+//	@Success		200					{object}	apimodels.CommitBlobResponse
+//	@Failure		400					{object}	string
+//	@Failure		500					{object}	string
+//	@Router			/api/v1/orchestrator/blobs/{digest} [post]
+func (e *Endpoint) commitBlob(c echo.Context) error {
+	ctx := c.Request().Context()
+	var args apimodels.CommitBlobRequest
+	if err := c.Bind(&args); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	info, err := e.blobStore.Commit(ctx, c.Param("digest"), args.Chunks)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, apimodels.CommitBlobResponse{
+		Digest: info.Digest,
+		Size:   info.Size,
+	})
+}
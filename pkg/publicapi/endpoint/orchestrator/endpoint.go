@@ -4,6 +4,7 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/blobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/orchestrator"
 	"github.com/bacalhau-project/bacalhau/pkg/orchestrator/nodes"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi/middleware"
@@ -14,21 +15,31 @@ type EndpointParams struct {
 	Orchestrator *orchestrator.BaseEndpoint
 	JobStore     jobstore.Store
 	NodeManager  nodes.Manager
+	// BlobStore stores the job inputs uploaded by clients.
+	// Optional. Uploads are not supported if nil.
+	BlobStore blobstore.Store
+	// VolumeJanitor deletes the persistent volumes of compute nodes.
+	// Optional. Deleting volumes is not supported if nil.
+	VolumeJanitor *orchestrator.VolumeJanitor
 }
 
 type Endpoint struct {
-	router       *echo.Echo
-	orchestrator *orchestrator.BaseEndpoint
-	store        jobstore.Store
-	nodeManager  nodes.Manager
+	router        *echo.Echo
+	orchestrator  *orchestrator.BaseEndpoint
+	store         jobstore.Store
+	nodeManager   nodes.Manager
+	blobStore     blobstore.Store
+	volumeJanitor *orchestrator.VolumeJanitor
 }
 
 func NewEndpoint(params EndpointParams) *Endpoint {
 	e := &Endpoint{
-		router:       params.Router,
-		orchestrator: params.Orchestrator,
-		store:        params.JobStore,
-		nodeManager:  params.NodeManager,
+		router:        params.Router,
+		orchestrator:  params.Orchestrator,
+		store:         params.JobStore,
+		nodeManager:   params.NodeManager,
+		blobStore:     params.BlobStore,
+		volumeJanitor: params.VolumeJanitor,
 	}
 
 	// JSON group
@@ -46,8 +57,18 @@ func NewEndpoint(params EndpointParams) *Endpoint {
 	g.GET("/jobs/:id/versions", e.jobVersions)
 	g.GET("/jobs/:id/results", e.jobResults)
 	g.GET("/jobs/:id/logs", e.logs)
+	g.GET("/jobs/:id/executions/:eid/exec", e.exec)
 	g.GET("/nodes", e.listNodes)
 	g.GET("/nodes/:id", e.getNode)
 	g.PUT("/nodes/:id", e.updateNode)
+	g.GET("/nodes/:id/volumes", e.listNodeVolumes)
+	if e.volumeJanitor != nil {
+		g.DELETE("/nodes/:id/volumes", e.deleteNodeVolumes)
+	}
+	if e.blobStore != nil {
+		g.GET("/blobs/:digest", e.getBlob)
+		g.PUT("/blobs/:digest/chunks/:index", e.putBlobChunk)
+		g.POST("/blobs/:digest", e.commitBlob)
+	}
 	return e
 }
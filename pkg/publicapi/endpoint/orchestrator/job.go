@@ -1,6 +1,7 @@
 package orchestrator
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -136,7 +137,7 @@ func (e *Endpoint) getJob(c echo.Context) error {
 	}
 
 	response := apimodels.GetJobResponse{
-		Job: &job,
+		Job: job.Redacted(),
 	}
 
 	for _, include := range strings.Split(args.Include, ",") {
@@ -181,7 +182,7 @@ func (e *Endpoint) getJob(c echo.Context) error {
 				Items: make([]*models.Execution, len(executions)),
 			}
 			for i := range executions {
-				response.Executions.Items[i] = &executions[i]
+				response.Executions.Items[i] = executions[i].Redacted()
 			}
 		}
 	}
@@ -275,7 +276,7 @@ func (e *Endpoint) listJobs(c echo.Context) error {
 
 	res := &apimodels.ListJobsResponse{
 		Items: lo.Map[models.Job, *models.Job](response.Jobs, func(item models.Job, _ int) *models.Job {
-			return &item
+			return item.Redacted()
 		}),
 		BaseListResponse: apimodels.BaseListResponse{
 			NextToken: nextToken,
@@ -487,7 +488,7 @@ func (e *Endpoint) jobExecutions(c echo.Context) error {
 		Items: make([]*models.Execution, len(executions)),
 	}
 	for i := range executions {
-		res.Items[i] = &executions[i]
+		res.Items[i] = executions[i].Redacted()
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -538,7 +539,7 @@ func (e *Endpoint) jobVersions(c echo.Context) error {
 		Items: make([]*models.Job, len(jobVersions)),
 	}
 	for i := range jobVersions {
-		res.Items[i] = &jobVersions[i]
+		res.Items[i] = jobVersions[i].Redacted()
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -552,8 +553,10 @@ func (e *Endpoint) jobVersions(c echo.Context) error {
 //	@Tags			Orchestrator
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		string	true	"ID to get the job results for"
-//	@Success		200	{object}	apimodels.ListJobResultsResponse
+//	@Param			id				path		string	true	"ID to get the job results for"
+//	@Param			execution_id	query		string	false	"Select the results of executions by ID prefix"
+//	@Param			partition		query		int		false	"Select the results of executions of a partition"
+//	@Success		200				{object}	apimodels.ListJobResultsResponse
 //	@Failure		400	{object}	string
 //	@Failure		500	{object}	string
 //	@Router			/api/v1/orchestrator/jobs/{id}/results [get]
@@ -574,14 +577,16 @@ func (e *Endpoint) jobResults(c echo.Context) error {
 	}
 
 	resp, err := e.orchestrator.GetResults(ctx, &orchestrator.GetResultsRequest{
-		JobID:     job.ID,
-		Namespace: args.Namespace,
+		JobID:        job.ID,
+		Namespace:    args.Namespace,
+		ExecutionIDs: args.ExecutionIDs,
+		Partitions:   args.Partitions,
 	})
 	if err != nil {
 		return err
 	}
 
-	result := &apimodels.ListJobResultsResponse{Items: resp.Results}
+	result := &apimodels.ListJobResultsResponse{Items: resp.Results, Origins: resp.Origins}
 
 	return publicapi.UnescapedJSON(c, http.StatusOK, result)
 }
@@ -658,3 +663,100 @@ func (e *Endpoint) logsWS(c echo.Context, ws *websocket.Conn) error {
 	}
 	return nil
 }
+
+// godoc for Orchestrator ExecJob
+//
+//	@ID				orchestrator/execJob
+//	@Summary		Opens an interactive session into a running execution of a job.
+//	@Description	Opens an interactive session into a running execution of a job over a websocket.
+//	@Description	The client sends models.ExecInput messages, and receives the session output as models.ExecOutput messages.
+//	@Tags			Orchestrator
+//	@Produce		json
+//	@Param			id			path		string				true	"ID or name of the job"
+//	@Param			eid			path		string				true	"ID of the execution"
+//	@Param			namespace	query		string				false	"Namespace of the job"
+//	@Param			command		query		[]string			false	"Command to run, repeated for each argument"
+//	@Param			attach		query		bool				false	"Attach to the main process instead of running a command"
+//	@Param			tty			query		bool				false	"Allocate a terminal"
+//	@Param			stdin		query		bool				false	"Attach the standard input"
+//	@Param			height		query		int					false	"Initial terminal height"
+//	@Param			width		query		int					false	"Initial terminal width"
+//	@Success		101			{object}	models.ExecOutput	"Switching Protocols to WebSocket"
+//	@Failure		400			{object}	string				"Bad Request"
+//	@Failure		500			{object}	string				"Internal Server Error"
+//	@Router			/api/v1/orchestrator/jobs/{id}/executions/{eid}/exec [get]
+func (e *Endpoint) exec(c echo.Context) error {
+	ws, err := publicapi.WebsocketUpgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to upgrade websocket connection: %w", err)
+	}
+	defer func() { _ = ws.Close() }()
+
+	err = e.execWS(c, ws)
+	if err != nil {
+		log.Ctx(c.Request().Context()).Error().Err(err).Msg("websocket failure")
+		err = ws.WriteJSON(concurrency.AsyncResult[models.ExecOutput]{
+			Err: err,
+		})
+		if err != nil {
+			log.Ctx(c.Request().Context()).Error().Err(err).Msg("failed to write error to websocket")
+		}
+	}
+	_ = ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return nil
+}
+
+func (e *Endpoint) execWS(c echo.Context, ws *websocket.Conn) error {
+	var args apimodels.ExecRequest
+	if err := c.Bind(&args); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := c.Validate(&args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	// read the input of the session until the client closes the connection
+	input := make(chan models.ExecInput)
+	go func() {
+		defer close(input)
+		for {
+			var frame models.ExecInput
+			if err := ws.ReadJSON(&frame); err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					log.Ctx(ctx).Debug().Err(err).Msg("stopped reading session input")
+				}
+				return
+			}
+			select {
+			case input <- frame:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	outputCh, err := e.orchestrator.Exec(ctx, orchestrator.ExecRequest{
+		JobID:       c.Param("id"),
+		Namespace:   args.Namespace,
+		ExecutionID: c.Param("eid"),
+		Command:     args.Command,
+		Attach:      args.Attach,
+		TTY:         args.TTY,
+		Stdin:       args.Stdin,
+		Height:      args.Height,
+		Width:       args.Width,
+	}, input)
+	if err != nil {
+		return fmt.Errorf("failed to open session into execution %s: %w", c.Param("eid"), err)
+	}
+
+	for output := range outputCh {
+		if err = ws.WriteJSON(output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package orchestrator
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/apimodels"
+)
+
+// godoc for Orchestrator ListNodeVolumes
+//
+//	@ID				orchestrator/listNodeVolumes
+//	@Summary		List the persistent volumes of a compute node.
+//	@Description	List the persistent volumes of jobs held by a compute node, as last advertised by the node.
+//	@Tags			Orchestrator
+//	@Produce		json
+//	@Param			id	path		string	true	"ID of the compute node."
+//	@Success		200	{object}	apimodels.ListNodeVolumesResponse
+//	@Failure		400	{object}	string
+//	@Failure		404	{object}	string
+//	@Failure		500	{object}	string
+//	@Router			/api/v1/orchestrator/nodes/{id}/volumes [get]
+func (e *Endpoint) listNodeVolumes(c echo.Context) error {
+	ctx := c.Request().Context()
+	if c.Param("id") == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing node id")
+	}
+	nodeState, err := e.nodeManager.GetByPrefix(ctx, c.Param("id"))
+	if err != nil {
+		return err
+	}
+	volumes := nodeState.Info.ComputeNodeInfo.Volumes
+	if volumes == nil {
+		volumes = make([]models.VolumeInfo, 0)
+	}
+	return c.JSON(http.StatusOK, apimodels.ListNodeVolumesResponse{
+		Volumes: volumes,
+	})
+}
+
+// godoc for Orchestrator DeleteNodeVolumes
+//
+//	@ID				orchestrator/deleteNodeVolumes
+//	@Summary		Delete the persistent volumes of a job on a compute node.
+//	@Description	Request a compute node to delete the persistent volumes of a job, which it does on its next heartbeat.
+//	@Tags			Orchestrator
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		string								true	"ID of the compute node."
+//	@Param			deleteNodeVolumesRequest	body		apimodels.DeleteNodeVolumesRequest	true	"Delete Node Volumes Request"
+//	@Success		200							{object}	apimodels.DeleteNodeVolumesResponse
+//	@Failure		400							{object}	string
+//	@Failure		404							{object}	string
+//	@Failure		500							{object}	string
+//	@Router			/api/v1/orchestrator/nodes/{id}/volumes [delete]
+func (e *Endpoint) deleteNodeVolumes(c echo.Context) error {
+	ctx := c.Request().Context()
+	if c.Param("id") == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing node id")
+	}
+	var args apimodels.DeleteNodeVolumesRequest
+	if err := c.Bind(&args); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := c.Validate(&args); err != nil {
+		return err
+	}
+	if args.JobID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing job id")
+	}
+	nodeState, err := e.nodeManager.GetByPrefix(ctx, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	// the job can be referenced by a prefix of its ID, such as the short IDs shown when listing volumes
+	var refs []models.VolumeRef
+	jobIDs := make(map[string]bool)
+	for _, volume := range nodeState.Info.ComputeNodeInfo.Volumes {
+		if strings.HasPrefix(volume.JobID, args.JobID) && (args.Name == "" || volume.Name == args.Name) {
+			refs = append(refs, volume.Ref())
+			jobIDs[volume.JobID] = true
+		}
+	}
+	if len(refs) == 0 {
+		return bacerrors.Newf("no persistent volume of job %s found on node %s", args.JobID, nodeState.Info.ID()).
+			WithCode(bacerrors.NotFoundError)
+	}
+	if len(jobIDs) > 1 {
+		return bacerrors.Newf("job ID prefix %s matches the volumes of %d jobs", args.JobID, len(jobIDs)).
+			WithCode(bacerrors.ValidationError).
+			WithHint("Use the full job ID")
+	}
+
+	e.volumeJanitor.RequestDeletion(nodeState.Info.ID(), refs...)
+	return c.JSON(http.StatusOK, apimodels.DeleteNodeVolumesResponse{
+		Volumes: refs,
+	})
+}
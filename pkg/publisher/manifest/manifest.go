@@ -0,0 +1,251 @@
+package manifest
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	baccrypto "github.com/bacalhau-project/bacalhau/pkg/lib/crypto"
+)
+
+/*
+Manifests describe the results of an execution, and are written by compute nodes at the root of the
+results before they are published, so that every publisher carries them. They list every file of the
+results with its size and checksum, and record where the results come from: the job and execution,
+the engine spec, the inputs and the compute node. Manifests are signed with the key of the compute
+node, so that clients can verify that downloaded results are complete, unmodified and produced by
+the node advertising the key.
+*/
+
+const (
+	// FileName is the name of the manifest at the root of the results
+	FileName = "manifest.json"
+	// Version is the version of the manifest format
+	Version = 1
+
+	digestPrefix = "sha256:"
+	filePerm     = 0644
+)
+
+// ErrNotFound is returned when results carry no manifest
+var ErrNotFound = errors.New("results have no " + FileName)
+
+// File is a file of the results
+type File struct {
+	// Path is the slash separated path of the file relative to the root of the results
+	Path string `json:"Path"`
+	// Size is the size of the file in bytes
+	Size int64 `json:"Size"`
+	// SHA256 is the hex encoded SHA-256 checksum of the file
+	SHA256 string `json:"SHA256"`
+}
+
+// Input identifies an input of the execution
+type Input struct {
+	// Type is the storage source type of the input
+	Type string `json:"Type"`
+	// Alias is the alias of the input, if any
+	Alias string `json:"Alias,omitempty"`
+	// Target is the path where the input was mounted
+	Target string `json:"Target"`
+	// Digest is the digest of the input source spec
+	Digest string `json:"Digest"`
+	// Details records how the input was resolved by the storage, such as the commit of a git input
+	Details map[string]string `json:"Details,omitempty"`
+}
+
+// Manifest describes the results of an execution and their provenance
+type Manifest struct {
+	Version     int    `json:"Version"`
+	JobID       string `json:"JobID"`
+	ExecutionID string `json:"ExecutionID"`
+	// NodeID is the ID of the compute node that produced the results
+	NodeID string `json:"NodeID"`
+	// EngineSpecDigest is the digest of the engine spec of the task
+	EngineSpecDigest string  `json:"EngineSpecDigest"`
+	Inputs           []Input `json:"Inputs"`
+	Files            []File  `json:"Files"`
+	// PublicKey is the base64 encoded PKIX public key of the compute node that signed the manifest
+	PublicKey string `json:"PublicKey"`
+	// Signature is the base64 encoded signature of the manifest without its signature
+	Signature string `json:"Signature,omitempty"`
+}
+
+// Digest returns the SHA-256 digest of the JSON encoding of v
+func Digest(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return digestPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+// ListFiles lists the regular files under dir with their sizes and checksums, excluding the manifest
+func ListFiles(dir string) ([]File, error) {
+	files := make([]File, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == FileName {
+			return nil
+		}
+		file, err := newFile(path)
+		if err != nil {
+			return err
+		}
+		file.Path = filepath.ToSlash(rel)
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list result files: %w", err)
+	}
+	return files, nil
+}
+
+func newFile(path string) (File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// Sign records the public key of the signer in the manifest and signs it
+func (m *Manifest) Sign(key *rsa.PrivateKey) error {
+	publicKey, err := baccrypto.EncodePublicKey(&key.PublicKey)
+	if err != nil {
+		return err
+	}
+	m.PublicKey = publicKey
+	payload, err := m.payload()
+	if err != nil {
+		return err
+	}
+	m.Signature, err = baccrypto.Sign(key, payload)
+	return err
+}
+
+// VerifySignature verifies the manifest was signed by the private key matching its public key
+func (m *Manifest) VerifySignature() error {
+	if m.Signature == "" || m.PublicKey == "" {
+		return errors.New("manifest is not signed")
+	}
+	publicKey, err := baccrypto.DecodePublicKey(m.PublicKey)
+	if err != nil {
+		return err
+	}
+	payload, err := m.payload()
+	if err != nil {
+		return err
+	}
+	if err = baccrypto.Verify(publicKey, payload, m.Signature); err != nil {
+		return fmt.Errorf("manifest signature is invalid: %w", err)
+	}
+	return nil
+}
+
+// VerifyFiles verifies the results under dir are exactly the files listed in the manifest
+func (m *Manifest) VerifyFiles(dir string) error {
+	files, err := ListFiles(dir)
+	if err != nil {
+		return err
+	}
+	actual := make(map[string]File, len(files))
+	for _, file := range files {
+		actual[file.Path] = file
+	}
+	for _, expected := range m.Files {
+		file, ok := actual[expected.Path]
+		if !ok {
+			return fmt.Errorf("file %s listed in the manifest is missing", expected.Path)
+		}
+		if file.Size != expected.Size || file.SHA256 != expected.SHA256 {
+			return fmt.Errorf("file %s does not match the manifest", expected.Path)
+		}
+		delete(actual, expected.Path)
+	}
+	if len(actual) > 0 {
+		unlisted := slices.Sorted(maps.Keys(actual))
+		return fmt.Errorf("file %s is not listed in the manifest", unlisted[0])
+	}
+	return nil
+}
+
+// payload returns the signed content of the manifest, which is its JSON encoding without signature
+func (m *Manifest) payload() ([]byte, error) {
+	unsigned := *m
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// Write writes the manifest at the root of dir
+func (m *Manifest) Write(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(dir, FileName), data, filePerm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	return nil
+}
+
+// Read reads the manifest at the root of dir, and returns ErrNotFound if there is none
+func Read(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := new(Manifest)
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	if m.Version != Version {
+		return nil, fmt.Errorf("unsupported %s version %d", FileName, m.Version)
+	}
+	return m, nil
+}
+
+// Verify reads the manifest at the root of dir, and verifies its signature and the files it lists
+func Verify(dir string) (*Manifest, error) {
+	m, err := Read(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.VerifySignature(); err != nil {
+		return nil, err
+	}
+	if err = m.VerifyFiles(dir); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
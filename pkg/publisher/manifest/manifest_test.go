@@ -0,0 +1,134 @@
+//go:build unit || !integration
+
+package manifest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ManifestTestSuite struct {
+	suite.Suite
+	key *rsa.PrivateKey
+	dir string
+}
+
+func TestManifestTestSuite(t *testing.T) {
+	suite.Run(t, new(ManifestTestSuite))
+}
+
+func (s *ManifestTestSuite) SetupSuite() {
+	var err error
+	s.key, err = rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+}
+
+func (s *ManifestTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.writeFile("stdout", "hello")
+	s.writeFile("exitCode", "0")
+	s.writeFile("outputs/data/result.csv", "a,b")
+}
+
+func (s *ManifestTestSuite) writeFile(name, content string) {
+	path := filepath.Join(s.dir, name)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+}
+
+// write writes a signed manifest of the results
+func (s *ManifestTestSuite) write() *Manifest {
+	engineDigest, err := Digest(map[string]string{"Image": "ubuntu"})
+	s.Require().NoError(err)
+	m := &Manifest{
+		Version:          Version,
+		JobID:            "j-1",
+		ExecutionID:      "e-1",
+		NodeID:           "node-1",
+		EngineSpecDigest: engineDigest,
+		Inputs:           []Input{{Type: "git", Target: "/src", Digest: engineDigest, Details: map[string]string{"Commit": "abc"}}},
+	}
+	m.Files, err = ListFiles(s.dir)
+	s.Require().NoError(err)
+	s.Require().NoError(m.Sign(s.key))
+	s.Require().NoError(m.Write(s.dir))
+	return m
+}
+
+func (s *ManifestTestSuite) TestListFiles() {
+	files, err := ListFiles(s.dir)
+	s.Require().NoError(err)
+	s.Equal([]File{
+		{Path: "exitCode", Size: 1, SHA256: "5feceb66ffc86f38d952786c6d696c79c2dbc239dd4e91b46729d73a27fb57e9"},
+		{Path: "outputs/data/result.csv", Size: 3, SHA256: "1eb7c54d52831bbfe8942af0b1c56b7409523a59ed6ca99c1174fef7eb32c1b5"},
+		{Path: "stdout", Size: 5, SHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+	}, files)
+}
+
+func (s *ManifestTestSuite) TestVerify() {
+	written := s.write()
+
+	m, err := Verify(s.dir)
+	s.Require().NoError(err)
+	s.Equal(written, m)
+	s.Equal("node-1", m.NodeID)
+	s.Len(m.Files, 3)
+}
+
+func (s *ManifestTestSuite) TestVerifyDetectsModifiedResults() {
+	for name, modify := range map[string]func(){
+		"modified file": func() { s.writeFile("stdout", "HELLO") },
+		"missing file":  func() { s.Require().NoError(os.Remove(filepath.Join(s.dir, "exitCode"))) },
+		"unlisted file": func() { s.writeFile("outputs/extra", "x") },
+	} {
+		s.Run(name, func() {
+			s.SetupTest()
+			s.write()
+			modify()
+			_, err := Verify(s.dir)
+			s.Error(err)
+		})
+	}
+}
+
+func (s *ManifestTestSuite) TestVerifyDetectsModifiedManifest() {
+	m := s.write()
+	nodeKey := m.PublicKey
+
+	// changing the provenance invalidates the signature
+	m.NodeID = "node-2"
+	s.Require().NoError(m.Write(s.dir))
+	_, err := Verify(s.dir)
+	s.ErrorContains(err, "signature")
+
+	// re-signing with another key is only detected by comparing with the key of the node
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	s.Require().NoError(m.Sign(other))
+	s.Require().NoError(m.Write(s.dir))
+	verified, err := Verify(s.dir)
+	s.Require().NoError(err)
+	s.NotEqual(nodeKey, verified.PublicKey)
+
+	m.Signature = ""
+	s.Require().NoError(m.Write(s.dir))
+	_, err = Verify(s.dir)
+	s.ErrorContains(err, "not signed")
+}
+
+func (s *ManifestTestSuite) TestRead() {
+	_, err := Read(s.dir)
+	s.ErrorIs(err, ErrNotFound)
+
+	data, err := json.Marshal(Manifest{Version: Version + 1})
+	s.Require().NoError(err)
+	s.writeFile(FileName, string(data))
+	_, err = Read(s.dir)
+	s.ErrorContains(err, "unsupported")
+}
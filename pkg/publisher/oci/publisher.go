@@ -0,0 +1,143 @@
+package oci
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/distribution/reference"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher"
+)
+
+// layerTitle is the file name of the result archive in the artifact
+const layerTitle = "results.tar.gz"
+
+type PublisherParams struct {
+	LocalDir string
+	Client   *oci.Client
+}
+
+// Compile-time check that publisher implements the correct interface:
+var _ publisher.Publisher = (*Publisher)(nil)
+
+// Publisher pushes results to a registry as OCI artifacts
+type Publisher struct {
+	localDir string
+	client   *oci.Client
+}
+
+func NewPublisher(params PublisherParams) *Publisher {
+	return &Publisher{
+		localDir: params.LocalDir,
+		client:   params.Client,
+	}
+}
+
+// IsInstalled returns true as the publisher only needs network access
+func (publisher *Publisher) IsInstalled(_ context.Context) (bool, error) {
+	return true, nil
+}
+
+// ValidateJob validates the job spec and returns an error if the job is invalid.
+func (publisher *Publisher) ValidateJob(_ context.Context, j models.Job) error {
+	_, err := oci.DecodePublisherSpec(j.Task().Publisher)
+	return err
+}
+
+func (publisher *Publisher) PublishResult(
+	ctx context.Context,
+	execution *models.Execution,
+	resultPath string,
+) (models.SpecConfig, error) {
+	spec, err := oci.DecodePublisherSpec(execution.Job.Task().Publisher)
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+	ref, err := spec.Reference(execution)
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+
+	targetFile, err := os.CreateTemp(publisher.localDir, "bacalhau-archive-*.tar.gz")
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+	defer func() { _ = targetFile.Close() }()
+	defer func() { _ = os.Remove(targetFile.Name()) }()
+
+	if err = gzip.Compress(resultPath, targetFile); err != nil {
+		return models.SpecConfig{}, err
+	}
+	info, err := targetFile.Stat()
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+	uncompressedSize, err := dirSize(resultPath)
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+
+	annotations := make(map[string]string, len(spec.Annotations)+4) //nolint:mnd // the annotations set below
+	for k, v := range spec.Annotations {
+		annotations[k] = v
+	}
+	annotations[v1.AnnotationCreated] = time.Now().UTC().Format(time.RFC3339)
+	annotations[oci.AnnotationJobID] = execution.JobID
+	annotations[oci.AnnotationExecutionID] = execution.ID
+	annotations[oci.AnnotationNodeID] = execution.NodeID
+
+	dgst, err := publisher.client.Push(ctx, ref, oci.Artifact{
+		Layer:       targetFile,
+		LayerSize:   info.Size(),
+		Annotations: annotations,
+		LayerAnnotations: map[string]string{
+			v1.AnnotationTitle:             layerTitle,
+			oci.AnnotationUncompressedSize: strconv.FormatInt(uncompressedSize, 10),
+		},
+	})
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+	log.Ctx(ctx).Debug().Msgf("Pushed results to %s@%s", reference.FamiliarString(ref), dgst)
+
+	// the result is pinned to the digest, so that it can't be changed by pushing to the same tag
+	pinned, err := reference.WithDigest(reference.TrimNamed(ref), dgst)
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+	return models.SpecConfig{
+		Type: models.StorageSourceOCI,
+		Params: oci.SourceSpec{
+			Reference: pinned.String(),
+		}.ToMap(),
+	}, nil
+}
+
+// dirSize returns the total size of the files in the directory
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,93 @@
+//go:build unit || !integration
+
+package oci
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/distribution/reference"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+type PublisherTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	registry  *oci.TestRegistry
+	pub       *Publisher
+	resultDir string
+}
+
+func TestPublisherTestSuite(t *testing.T) {
+	suite.Run(t, new(PublisherTestSuite))
+}
+
+func (s *PublisherTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.registry = oci.NewTestRegistry(s.T())
+	s.pub = NewPublisher(PublisherParams{
+		LocalDir: s.T().TempDir(),
+		Client:   oci.NewClient(oci.ClientParams{InsecureRegistries: []string{s.registry.Host()}}),
+	})
+
+	s.resultDir = s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(s.resultDir, "stdout"), []byte("hello"), 0644))
+	s.Require().NoError(os.MkdirAll(filepath.Join(s.resultDir, "outputs"), 0755))
+	s.Require().NoError(os.WriteFile(filepath.Join(s.resultDir, "outputs", "data.txt"), []byte("data"), 0644))
+}
+
+func (s *PublisherTestSuite) TestPublishResult() {
+	execution := mock.Execution()
+	execution.Job.Task().Publisher = &models.SpecConfig{
+		Type: models.PublisherOCI,
+		Params: oci.PublisherSpec{
+			Repository:  s.registry.Host() + "/results",
+			Tag:         "{jobID}",
+			Annotations: map[string]string{"org.example.team": "data"},
+		}.ToMap(),
+	}
+
+	result, err := s.pub.PublishResult(s.ctx, execution, s.resultDir)
+	s.Require().NoError(err)
+	s.Equal(models.StorageSourceOCI, result.Type)
+
+	source, err := oci.DecodeSourceSpec(&result)
+	s.Require().NoError(err)
+	ref, err := oci.ParseReference(source.Reference)
+	s.Require().NoError(err)
+	digested, ok := ref.(reference.Digested)
+	s.Require().True(ok, "published result should be pinned to a digest")
+	s.Equal(s.registry.Host()+"/results", ref.Name())
+
+	manifest := s.registry.Manifest("results", execution.JobID)
+	s.Equal(manifest, s.registry.Manifest("results", digested.Digest().String()))
+	s.Equal(execution.JobID, manifest.Annotations[oci.AnnotationJobID])
+	s.Equal(execution.ID, manifest.Annotations[oci.AnnotationExecutionID])
+	s.Equal(execution.NodeID, manifest.Annotations[oci.AnnotationNodeID])
+	s.Equal("data", manifest.Annotations["org.example.team"])
+	s.NotEmpty(manifest.Annotations[v1.AnnotationCreated])
+	s.Require().Len(manifest.Layers, 1)
+	s.Equal("9", manifest.Layers[0].Annotations[oci.AnnotationUncompressedSize])
+}
+
+func (s *PublisherTestSuite) TestValidateJob() {
+	job := mock.Job()
+	job.Task().Publisher = &models.SpecConfig{
+		Type:   models.PublisherOCI,
+		Params: oci.PublisherSpec{Repository: "ghcr.io/org/results"}.ToMap(),
+	}
+	s.NoError(s.pub.ValidateJob(s.ctx, *job))
+
+	job.Task().Publisher = &models.SpecConfig{
+		Type:   models.PublisherOCI,
+		Params: oci.PublisherSpec{}.ToMap(),
+	}
+	s.Error(s.pub.ValidateJob(s.ctx, *job))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
@@ -18,22 +19,23 @@ import (
 )
 
 type PublisherParams struct {
-	LocalDir       string
 	ClientProvider *s3helper.ClientProvider
+	// Optional. Defaults are used for unset values.
+	Upload s3helper.UploadParams
 }
 
 // Compile-time check that publisher implements the correct interface:
 var _ publisher.Publisher = (*Publisher)(nil)
 
 type Publisher struct {
-	localDir       string
 	clientProvider *s3helper.ClientProvider
+	upload         s3helper.UploadParams
 }
 
 func NewPublisher(params PublisherParams) *Publisher {
 	return &Publisher{
-		localDir:       params.LocalDir,
 		clientProvider: params.ClientProvider,
+		upload:         params.Upload.WithDefaults(),
 	}
 }
 
@@ -66,6 +68,8 @@ func (publisher *Publisher) PublishResult(
 	return publisher.publishArchive(ctx, spec, execution, resultPath)
 }
 
+// publishArchive streams a tar+gzip archive of the results into a multipart upload,
+// without writing the archive to disk.
 func (publisher *Publisher) publishArchive(
 	ctx context.Context,
 	spec s3helper.PublisherSpec,
@@ -75,29 +79,18 @@ func (publisher *Publisher) publishArchive(
 	client := publisher.clientProvider.GetClient(spec.Endpoint, spec.Region)
 	key := ParsePublishedKey(spec.Key, execution, true)
 
-	// Create a new GZIP writer that writes to the file.
-	targetFile, err := os.CreateTemp(publisher.localDir, "bacalhau-archive-*.tar.gz")
-	if err != nil {
-		return models.SpecConfig{}, err
-	}
-	defer func() { _ = targetFile.Close() }()
-	defer func() { _ = os.Remove(targetFile.Name()) }()
-
-	err = gzip.Compress(resultPath, targetFile)
-	if err != nil {
-		return models.SpecConfig{}, err
-	}
-
-	// reset the archived file to read and upload it
-	_, err = targetFile.Seek(0, io.SeekStart)
-	if err != nil {
-		return models.SpecConfig{}, err
-	}
+	reader, writer := io.Pipe()
+	compressed := make(chan error, 1)
+	go func() {
+		err := gzip.CompressToWriter(resultPath, writer)
+		_ = writer.CloseWithError(err)
+		compressed <- err
+	}()
 
 	putObjectInput := &s3.PutObjectInput{
 		Bucket: aws.String(spec.Bucket),
 		Key:    aws.String(key),
-		Body:   targetFile,
+		Body:   reader,
 	}
 
 	// Only use SHA256 checksums if the endpoint is AWS, as it is
@@ -106,9 +99,15 @@ func (publisher *Publisher) publishArchive(
 		putObjectInput.ChecksumAlgorithm = types.ChecksumAlgorithmSha256
 	}
 
-	// Upload the GZIP archive to S3.
-	res, err := client.Uploader.Upload(ctx, putObjectInput)
+	// Upload the GZIP archive to S3 as it is compressed.
+	res, err := client.Uploader.Upload(ctx, putObjectInput, publisher.upload.ConfigureUploader())
+	// stop compressing if the upload failed before reading the whole archive
+	_ = reader.CloseWithError(err)
+	if compressErr := <-compressed; compressErr != nil && err == nil {
+		err = compressErr
+	}
 	if err != nil {
+		s3helper.AbortFailedUpload(ctx, client, spec.Bucket, key, err)
 		return models.SpecConfig{}, s3helper.NewS3PublisherServiceError(err)
 	}
 	log.Debug().Msgf("Uploaded s3://%s/%s", spec.Bucket, aws.ToString(res.Key))
@@ -135,7 +134,9 @@ func (publisher *Publisher) publishDirectory(
 	client := publisher.clientProvider.GetClient(spec.Endpoint, spec.Region)
 	key := ParsePublishedKey(spec.Key, execution, false)
 
-	// Walk the directory tree and upload each file to S3.
+	// Walk the directory tree and upload the files to S3 in parallel.
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(publisher.upload.Concurrency)
 	err := filepath.Walk(resultPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -143,39 +144,22 @@ func (publisher *Publisher) publishDirectory(
 		if info.IsDir() {
 			return nil // skip directories
 		}
-		// Read the file contents.
-		data, err := os.Open(path) //nolint:gosec // G304: path from local result storage, application controlled
-		if err != nil {
-			return err
-		}
-		defer func() { _ = data.Close() }()
-
 		relativePath, err := filepath.Rel(resultPath, path)
 		if err != nil {
 			return err
 		}
-
-		putObjectInput := &s3.PutObjectInput{
-			Bucket: aws.String(spec.Bucket),
-			Key:    aws.String(key + filepath.ToSlash(relativePath)),
-			Body:   data,
-		}
-
-		// Only use SHA256 checksums if the endpoint is AWS, as it is
-		// not supported by other S3-compatible providers, such as GCP buckets
-		if client.IsAWSEndpoint() {
-			putObjectInput.ChecksumAlgorithm = types.ChecksumAlgorithmSha256
-		}
-
-		// Upload the file to S3.
-		res, err := client.Uploader.Upload(ctx, putObjectInput)
-		if err != nil {
-			return err
+		// stop walking once an upload failed
+		if gctx.Err() != nil {
+			return gctx.Err()
 		}
-		log.Debug().Msgf("Uploaded s3://%s/%s", spec.Bucket, aws.ToString(res.Key))
+		g.Go(func() error {
+			return publisher.uploadFile(gctx, client, spec.Bucket, key+filepath.ToSlash(relativePath), path)
+		})
 		return nil
 	})
-
+	if waitErr := g.Wait(); waitErr != nil {
+		err = waitErr
+	}
 	if err != nil {
 		return models.SpecConfig{}, err
 	}
@@ -190,3 +174,33 @@ func (publisher *Publisher) publishDirectory(
 		}.ToMap(),
 	}, nil
 }
+
+// uploadFile uploads a file of the results, in parts if it is larger than the part size
+func (publisher *Publisher) uploadFile(
+	ctx context.Context, client *s3helper.ClientWrapper, bucket, key, path string) error {
+	data, err := os.Open(path) //nolint:gosec // G304: path from local result storage, application controlled
+	if err != nil {
+		return err
+	}
+	defer func() { _ = data.Close() }()
+
+	putObjectInput := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   data,
+	}
+
+	// Only use SHA256 checksums if the endpoint is AWS, as it is
+	// not supported by other S3-compatible providers, such as GCP buckets
+	if client.IsAWSEndpoint() {
+		putObjectInput.ChecksumAlgorithm = types.ChecksumAlgorithmSha256
+	}
+
+	res, err := client.Uploader.Upload(ctx, putObjectInput, publisher.upload.ConfigureUploader())
+	if err != nil {
+		s3helper.AbortFailedUpload(ctx, client, bucket, key, err)
+		return err
+	}
+	log.Debug().Msgf("Uploaded s3://%s/%s", bucket, aws.ToString(res.Key))
+	return nil
+}
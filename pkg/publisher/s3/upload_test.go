@@ -0,0 +1,162 @@
+//go:build unit || !integration
+
+package s3_test
+
+import (
+	"context"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	s3publisher "github.com/bacalhau-project/bacalhau/pkg/publisher/s3"
+	s3helper "github.com/bacalhau-project/bacalhau/pkg/s3"
+	s3test "github.com/bacalhau-project/bacalhau/pkg/s3/test"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+const testBucket = "results"
+
+// UploadTestSuite tests the uploads of the publisher against a fake S3 server
+type UploadTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	fake       *s3test.FakeS3
+	publisher  *s3publisher.Publisher
+	resultPath string
+}
+
+func TestUploadTestSuite(t *testing.T) {
+	suite.Run(t, new(UploadTestSuite))
+}
+
+func (s *UploadTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.fake = s3test.NewFakeS3(s.T())
+	s.publisher = s3publisher.NewPublisher(s3publisher.PublisherParams{
+		ClientProvider: s.fake.ClientProvider(),
+		Upload: s3helper.UploadParams{
+			PartSize:    s3helper.MinUploadPartSize,
+			Concurrency: 2,
+			MaxRetries:  3,
+		},
+	})
+
+	// random data does not compress, so the archive spans several parts
+	s.resultPath = s.T().TempDir()
+	s.writeResult("stdout", 1024)
+	s.writeResult("outputs/large.bin", 2*s3helper.MinUploadPartSize+1024)
+	s.writeResult("outputs/small.bin", 10)
+}
+
+func (s *UploadTestSuite) writeResult(name string, size int64) {
+	data := make([]byte, size)
+	_, err := rand.Read(data)
+	s.Require().NoError(err)
+	path := filepath.Join(s.resultPath, name)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	s.Require().NoError(os.WriteFile(path, data, 0644))
+}
+
+func (s *UploadTestSuite) execution(encoding s3helper.Encoding) *models.Execution {
+	execution := mock.ExecutionForJob(mock.Job())
+	execution.Job.Task().Publisher = &models.SpecConfig{
+		Type: models.PublisherS3,
+		Params: s3helper.PublisherSpec{
+			Bucket:   testBucket,
+			Key:      "{executionID}",
+			Endpoint: s.fake.Endpoint(),
+			Region:   s3test.FakeRegion,
+			Encoding: encoding,
+		}.ToMap(),
+	}
+	return execution
+}
+
+// requireArchivedResult checks that the object is an archive of the results
+func (s *UploadTestSuite) requireArchivedResult(key string) {
+	data, ok := s.fake.Object(testBucket, key)
+	s.Require().True(ok, "object %s was not published", key)
+
+	archivePath := filepath.Join(s.T().TempDir(), "results.tar.gz")
+	s.Require().NoError(os.WriteFile(archivePath, data, 0644))
+	extracted := filepath.Join(s.T().TempDir(), "extracted")
+	s.Require().NoError(gzip.Decompress(archivePath, extracted))
+	s3test.AssertEqualDirectories(s.T(), s.resultPath, extracted)
+}
+
+func (s *UploadTestSuite) TestPublishArchiveInParts() {
+	execution := s.execution(s3helper.EncodingGzip)
+	_, err := s.publisher.PublishResult(s.ctx, execution, s.resultPath)
+	s.Require().NoError(err)
+
+	s.requireArchivedResult(execution.ID + ".tar.gz")
+	s.Equal(3, s.fake.PartRequests())
+	s.Zero(s.fake.PendingUploads())
+}
+
+func (s *UploadTestSuite) TestPublishArchiveRetriesFailedParts() {
+	s.fake.FailUploadParts(2)
+
+	execution := s.execution(s3helper.EncodingGzip)
+	_, err := s.publisher.PublishResult(s.ctx, execution, s.resultPath)
+	s.Require().NoError(err)
+
+	s.requireArchivedResult(execution.ID + ".tar.gz")
+	s.Equal(5, s.fake.PartRequests())
+	s.Zero(s.fake.PendingUploads())
+}
+
+func (s *UploadTestSuite) TestPublishArchiveAbortsFailedUpload() {
+	s.fake.FailUploadParts(100)
+	publisher := s3publisher.NewPublisher(s3publisher.PublisherParams{
+		ClientProvider: s.fake.ClientProvider(),
+		Upload:         s3helper.UploadParams{PartSize: s3helper.MinUploadPartSize, MaxRetries: 1},
+	})
+
+	execution := s.execution(s3helper.EncodingGzip)
+	_, err := publisher.PublishResult(s.ctx, execution, s.resultPath)
+	s.Require().Error(err)
+
+	s.Empty(s.fake.Keys(testBucket))
+	s.Zero(s.fake.PendingUploads())
+}
+
+func (s *UploadTestSuite) TestPublishArchiveAbortsCancelledUpload() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	s.fake.OnUploadPart(func(int32) { cancel() })
+
+	execution := s.execution(s3helper.EncodingGzip)
+	_, err := s.publisher.PublishResult(ctx, execution, s.resultPath)
+	s.Require().Error(err)
+
+	s.Empty(s.fake.Keys(testBucket))
+	s.Zero(s.fake.PendingUploads())
+}
+
+func (s *UploadTestSuite) TestPublishDirectory() {
+	execution := s.execution(s3helper.EncodingPlain)
+	_, err := s.publisher.PublishResult(s.ctx, execution, s.resultPath)
+	s.Require().NoError(err)
+
+	prefix := execution.ID + "/"
+	s.Equal([]string{
+		prefix + "outputs/large.bin",
+		prefix + "outputs/small.bin",
+		prefix + "stdout",
+	}, s.fake.Keys(testBucket))
+	for _, name := range []string{"stdout", "outputs/large.bin", "outputs/small.bin"} {
+		expected, err := os.ReadFile(filepath.Join(s.resultPath, name))
+		s.Require().NoError(err)
+		actual, _ := s.fake.Object(testBucket, prefix+name)
+		s.Equal(expected, actual, name)
+	}
+	// the large file is uploaded in parts, the small ones with a single request
+	s.Equal(3, s.fake.PartRequests())
+	s.Equal(2, s.fake.PutObjectCalls())
+}
@@ -0,0 +1,196 @@
+package s3managed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/envelope"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/ncl"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/models/messages"
+	s3helper "github.com/bacalhau-project/bacalhau/pkg/s3"
+)
+
+// abortMultipartUploadTimeout bounds the abort of failed uploads, which are aborted even if publishing was cancelled
+const abortMultipartUploadTimeout = 30 * time.Second
+
+// publishMultipart streams a tar+gzip archive of the results in parts to pre-signed URLs of a multipart upload,
+// without writing the archive to disk. The upload is aborted if it fails or is cancelled.
+func (p Publisher) publishMultipart(ctx context.Context, execution *models.Execution, resultPath, uploadID string) error {
+	reader, writer := io.Pipe()
+	compressed := make(chan error, 1)
+	go func() {
+		err := gzip.CompressToWriter(resultPath, writer)
+		_ = writer.CloseWithError(err)
+		compressed <- err
+	}()
+
+	parts, err := p.uploadParts(ctx, execution, uploadID, reader)
+	// stop compressing if the upload failed before reading the whole archive
+	_ = reader.CloseWithError(err)
+	if compressErr := <-compressed; compressErr != nil && err == nil {
+		err = bacerrors.Wrap(compressErr, ResultCompressionErrorMessage)
+	}
+	if err == nil {
+		_, err = p.multipartRequest(ctx, messages.ManagedPublisherMultipartRequest{
+			JobID:       execution.Job.ID,
+			ExecutionID: execution.ID,
+			Operation:   messages.ManagedPublisherMultipartComplete,
+			UploadID:    uploadID,
+			Parts:       parts,
+		})
+	}
+	if err != nil {
+		p.abortMultipartUpload(ctx, execution, uploadID)
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("execution_id", execution.ID).
+			Str("job_id", execution.Job.ID).
+			Str("result_path", resultPath).
+			Msg("Failed to upload result to managed S3 bucket")
+		return bacerrors.Wrap(err, "failed to upload result file to managed S3 bucket")
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("execution_id", execution.ID).
+		Str("job_id", execution.Job.ID).
+		Int("parts", len(parts)).
+		Msg("published result to managed S3 bucket with a multipart upload")
+	return nil
+}
+
+// uploadParts reads the archive in parts of the configured size, and uploads them in parallel.
+// At most concurrency+1 parts are held in memory.
+func (p Publisher) uploadParts(
+	ctx context.Context, execution *models.Execution, uploadID string, archive io.Reader) (
+	[]messages.ManagedPublisherUploadedPart, error) {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(p.upload.Concurrency)
+
+	var mu sync.Mutex
+	var parts []messages.ManagedPublisherUploadedPart
+	var readErr error
+	for partNumber := int32(1); gctx.Err() == nil; partNumber++ {
+		part := make([]byte, p.upload.PartSize)
+		n, err := io.ReadFull(archive, part)
+		if errors.Is(err, io.EOF) && partNumber > 1 {
+			break
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			readErr = err
+			break
+		}
+		if partNumber > s3helper.MaxUploadParts {
+			readErr = fmt.Errorf("result is larger than %d parts of %d bytes", s3helper.MaxUploadParts, p.upload.PartSize)
+			break
+		}
+
+		g.Go(func() error {
+			response, err := p.multipartRequest(gctx, messages.ManagedPublisherMultipartRequest{
+				JobID:       execution.Job.ID,
+				ExecutionID: execution.ID,
+				Operation:   messages.ManagedPublisherMultipartPresignPart,
+				UploadID:    uploadID,
+				PartNumber:  partNumber,
+			})
+			if err != nil {
+				return err
+			}
+			etag, err := p.partUploader.UploadPart(gctx, response.PreSignedURL, part[:n])
+			if err != nil {
+				return fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			parts = append(parts, messages.ManagedPublisherUploadedPart{PartNumber: partNumber, ETag: etag})
+			return nil
+		})
+		if n < len(part) {
+			break
+		}
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(parts, func(a, b messages.ManagedPublisherUploadedPart) int {
+		return int(a.PartNumber - b.PartNumber)
+	})
+	return parts, nil
+}
+
+// createMultipartUpload starts a multipart upload of the results, and returns its upload ID
+func (p Publisher) createMultipartUpload(ctx context.Context, execution *models.Execution) (string, error) {
+	response, err := p.multipartRequest(ctx, messages.ManagedPublisherMultipartRequest{
+		JobID:       execution.Job.ID,
+		ExecutionID: execution.ID,
+		Operation:   messages.ManagedPublisherMultipartCreate,
+	})
+	if err != nil {
+		return "", err
+	}
+	if response.UploadID == "" {
+		return "", fmt.Errorf("orchestrator did not provide an upload ID")
+	}
+	return response.UploadID, nil
+}
+
+// abortMultipartUpload aborts the upload so that its parts are not left in the bucket, even if ctx was cancelled
+func (p Publisher) abortMultipartUpload(ctx context.Context, execution *models.Execution, uploadID string) {
+	abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortMultipartUploadTimeout)
+	defer cancel()
+	_, err := p.multipartRequest(abortCtx, messages.ManagedPublisherMultipartRequest{
+		JobID:       execution.Job.ID,
+		ExecutionID: execution.ID,
+		Operation:   messages.ManagedPublisherMultipartAbort,
+		UploadID:    uploadID,
+	})
+	if err != nil {
+		log.Ctx(ctx).Warn().
+			Err(err).
+			Str("execution_id", execution.ID).
+			Str("upload_id", uploadID).
+			Msg("Failed to abort multipart upload to managed S3 bucket")
+	}
+}
+
+// multipartRequest sends an operation of a multipart upload to the orchestrator
+func (p Publisher) multipartRequest(
+	ctx context.Context, request messages.ManagedPublisherMultipartRequest) (*messages.ManagedPublisherMultipartResponse, error) {
+	nclMessagePublisher, err := p.nclPublisher()
+	if err != nil {
+		return nil, err
+	}
+
+	message := envelope.NewMessage(request).
+		WithMetadataValue(envelope.KeyMessageType, messages.ManagedPublisherMultipartRequestType)
+
+	response, err := nclMessagePublisher.Request(ctx, ncl.NewPublishRequest(message))
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s multipart upload: %w", request.Operation, err)
+	}
+
+	responseMessage, ok := response.Payload.(*messages.ManagedPublisherMultipartResponse)
+	if !ok {
+		return nil, envelope.NewErrUnexpectedPayloadType(
+			"ManagedPublisherMultipartResponse", reflect.TypeOf(response.Payload).String())
+	}
+	return responseMessage, nil
+}
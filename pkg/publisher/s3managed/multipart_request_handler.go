@@ -0,0 +1,85 @@
+package s3managed
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/envelope"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/ncl"
+	"github.com/bacalhau-project/bacalhau/pkg/models/messages"
+)
+
+// MultipartUploadRequestHandler handles the operations of multipart uploads of results to the managed
+// S3 bucket, which compute nodes request to stream large results in parts to pre-signed URLs.
+type MultipartUploadRequestHandler struct {
+	urlGenerator *PreSignedURLGenerator
+}
+
+func NewMultipartUploadRequestHandler(urlGenerator *PreSignedURLGenerator) *MultipartUploadRequestHandler {
+	return &MultipartUploadRequestHandler{
+		urlGenerator: urlGenerator,
+	}
+}
+
+func (rh *MultipartUploadRequestHandler) HandleRequest(ctx context.Context, message *envelope.Message) (*envelope.Message, error) {
+	request, ok := message.Payload.(*messages.ManagedPublisherMultipartRequest)
+	if !ok {
+		return nil, envelope.NewErrUnexpectedPayloadType("ManagedPublisherMultipartRequest", reflect.TypeOf(message.Payload).String())
+	}
+
+	if !rh.urlGenerator.IsInstalled() {
+		return nil, bacerrors.New("Managed S3 publisher is not available").
+			WithCode(bacerrors.BadRequestError)
+	}
+
+	if request.JobID == "" || request.ExecutionID == "" {
+		return nil, envelope.NewErrBadPayload("JobID and ExecutionID must be provided")
+	}
+	if request.Operation != messages.ManagedPublisherMultipartCreate && request.UploadID == "" {
+		return nil, envelope.NewErrBadPayload("UploadID must be provided")
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("job_id", request.JobID).
+		Str("execution_id", request.ExecutionID).
+		Str("operation", string(request.Operation)).
+		Msg("Received a multipart upload request for S3 managed publisher")
+
+	response := messages.ManagedPublisherMultipartResponse{
+		JobID:       request.JobID,
+		ExecutionID: request.ExecutionID,
+		UploadID:    request.UploadID,
+	}
+	var err error
+	switch request.Operation {
+	case messages.ManagedPublisherMultipartCreate:
+		response.UploadID, err = rh.urlGenerator.CreateMultipartUpload(ctx, request.JobID, request.ExecutionID)
+	case messages.ManagedPublisherMultipartPresignPart:
+		response.PreSignedURL, err = rh.urlGenerator.GeneratePreSignedUploadPartURL(
+			ctx, request.JobID, request.ExecutionID, request.UploadID, request.PartNumber)
+	case messages.ManagedPublisherMultipartComplete:
+		parts := make([]s3types.CompletedPart, len(request.Parts))
+		for i, part := range request.Parts {
+			parts[i] = s3types.CompletedPart{PartNumber: aws.Int32(part.PartNumber), ETag: aws.String(part.ETag)}
+		}
+		err = rh.urlGenerator.CompleteMultipartUpload(ctx, request.JobID, request.ExecutionID, request.UploadID, parts)
+	case messages.ManagedPublisherMultipartAbort:
+		err = rh.urlGenerator.AbortMultipartUpload(ctx, request.JobID, request.ExecutionID, request.UploadID)
+	default:
+		return nil, envelope.NewErrBadPayload(fmt.Sprintf("unsupported multipart upload operation %q", request.Operation))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s multipart upload: %w", request.Operation, err)
+	}
+
+	return envelope.NewMessage(response).
+		WithMetadataValue(envelope.KeyMessageType, messages.ManagedPublisherMultipartResponseType), nil
+}
+
+var _ ncl.RequestHandler = (*MultipartUploadRequestHandler)(nil)
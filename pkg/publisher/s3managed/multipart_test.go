@@ -0,0 +1,184 @@
+//go:build unit || !integration
+
+package s3managed_test
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/config/types"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/envelope"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/ncl"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/models/messages"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/s3managed"
+	s3helper "github.com/bacalhau-project/bacalhau/pkg/s3"
+	s3test "github.com/bacalhau-project/bacalhau/pkg/s3/test"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+	"github.com/bacalhau-project/bacalhau/pkg/transport/nclprotocol"
+)
+
+const testBucket = "managed-results"
+
+// orchestratorPublisher delivers requests to the orchestrator's handlers, serializing messages
+// in both directions as NCL does
+type orchestratorPublisher struct {
+	registry *envelope.Registry
+	handlers map[string]ncl.RequestHandler
+}
+
+func (p *orchestratorPublisher) Request(ctx context.Context, req ncl.PublishRequest) (*envelope.Message, error) {
+	handler, ok := p.handlers[req.Message.Metadata.Get(envelope.KeyMessageType)]
+	if !ok {
+		return nil, fmt.Errorf("no handler for message type %s", req.Message.Metadata.Get(envelope.KeyMessageType))
+	}
+	request, err := p.roundTrip(req.Message)
+	if err != nil {
+		return nil, err
+	}
+	response, err := handler.HandleRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return p.roundTrip(response)
+}
+
+func (p *orchestratorPublisher) roundTrip(message *envelope.Message) (*envelope.Message, error) {
+	encoded, err := p.registry.Serialize(message)
+	if err != nil {
+		return nil, err
+	}
+	return p.registry.Deserialize(encoded)
+}
+
+func (p *orchestratorPublisher) Publish(ctx context.Context, req ncl.PublishRequest) error {
+	return nil
+}
+
+// MultipartUploadTestSuite tests the uploads of the managed publisher against a fake S3 server,
+// through the request handlers of the orchestrator
+type MultipartUploadTestSuite struct {
+	suite.Suite
+	ctx          context.Context
+	fake         *s3test.FakeS3
+	orchestrator *orchestratorPublisher
+	publisher    *s3managed.Publisher
+	resultPath   string
+}
+
+func TestMultipartUploadTestSuite(t *testing.T) {
+	suite.Run(t, new(MultipartUploadTestSuite))
+}
+
+func (s *MultipartUploadTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.fake = s3test.NewFakeS3(s.T())
+
+	generator, err := s3managed.NewPreSignedURLGenerator(s3managed.PreSignedURLGeneratorParams{
+		ClientProvider: s.fake.ClientProvider(),
+		PublisherConfig: types.S3ManagedPublisher{
+			Bucket:                 testBucket,
+			Region:                 s3test.FakeRegion,
+			Endpoint:               s.fake.Endpoint(),
+			PreSignedURLExpiration: types.Duration(time.Hour),
+		},
+	})
+	s.Require().NoError(err)
+	s.orchestrator = &orchestratorPublisher{
+		registry: nclprotocol.MustCreateMessageRegistry(),
+		handlers: map[string]ncl.RequestHandler{
+			messages.ManagedPublisherPreSignURLRequestType: s3managed.NewPreSignedURLRequestHandler(generator),
+			messages.ManagedPublisherMultipartRequestType:  s3managed.NewMultipartUploadRequestHandler(generator),
+		},
+	}
+
+	uploader := s3managed.NewS3PreSignedURLUploader(http.DefaultClient, 1)
+	s.publisher = s3managed.NewPublisher(s3managed.PublisherParams{
+		NCLPublisherProvider: &MockPublisherProvider{Publisher: s.orchestrator},
+		LocalDir:             s.T().TempDir(),
+		URLUploader:          uploader,
+		PartUploader:         uploader,
+		Upload: s3helper.UploadParams{
+			PartSize:    s3helper.MinUploadPartSize,
+			Concurrency: 2,
+		},
+	})
+
+	// random data does not compress, so the archive spans several parts
+	s.resultPath = s.T().TempDir()
+	data := make([]byte, 2*s3helper.MinUploadPartSize+1024)
+	_, err = rand.Read(data)
+	s.Require().NoError(err)
+	s.Require().NoError(os.WriteFile(filepath.Join(s.resultPath, "stdout"), data, 0644))
+}
+
+// requireArchivedResult checks that the result of the execution is an archive of the results
+func (s *MultipartUploadTestSuite) requireArchivedResult(execution *models.Execution) {
+	data, ok := s.fake.Object(testBucket, fmt.Sprintf("%s/%s.tar.gz", execution.Job.ID, execution.ID))
+	s.Require().True(ok, "result was not published")
+
+	archivePath := filepath.Join(s.T().TempDir(), "results.tar.gz")
+	s.Require().NoError(os.WriteFile(archivePath, data, 0644))
+	extracted := filepath.Join(s.T().TempDir(), "extracted")
+	s.Require().NoError(gzip.Decompress(archivePath, extracted))
+	s3test.AssertEqualDirectories(s.T(), s.resultPath, extracted)
+}
+
+func (s *MultipartUploadTestSuite) TestPublishInParts() {
+	execution := mock.ExecutionForJob(mock.Job())
+	result, err := s.publisher.PublishResult(s.ctx, execution, s.resultPath)
+	s.Require().NoError(err)
+	s.Equal(models.StorageSourceS3Managed, result.Type)
+
+	s.requireArchivedResult(execution)
+	s.Equal(3, s.fake.PartRequests())
+	s.Zero(s.fake.PutObjectCalls())
+	s.Zero(s.fake.PendingUploads())
+}
+
+func (s *MultipartUploadTestSuite) TestPublishRetriesFailedParts() {
+	s.fake.FailUploadParts(1)
+
+	execution := mock.ExecutionForJob(mock.Job())
+	_, err := s.publisher.PublishResult(s.ctx, execution, s.resultPath)
+	s.Require().NoError(err)
+
+	s.requireArchivedResult(execution)
+	s.Equal(4, s.fake.PartRequests())
+	s.Zero(s.fake.PendingUploads())
+}
+
+func (s *MultipartUploadTestSuite) TestPublishAbortsCancelledUpload() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	s.fake.OnUploadPart(func(int32) { cancel() })
+
+	execution := mock.ExecutionForJob(mock.Job())
+	_, err := s.publisher.PublishResult(ctx, execution, s.resultPath)
+	s.Require().Error(err)
+
+	s.Empty(s.fake.Keys(testBucket))
+	s.Zero(s.fake.PendingUploads())
+}
+
+func (s *MultipartUploadTestSuite) TestPublishWithoutMultipartSupport() {
+	// orchestrators that do not handle multipart uploads only provide pre-signed URLs of whole results
+	delete(s.orchestrator.handlers, messages.ManagedPublisherMultipartRequestType)
+
+	execution := mock.ExecutionForJob(mock.Job())
+	_, err := s.publisher.PublishResult(s.ctx, execution, s.resultPath)
+	s.Require().NoError(err)
+
+	s.requireArchivedResult(execution)
+	s.Zero(s.fake.PartRequests())
+	s.Equal(1, s.fake.PutObjectCalls())
+}
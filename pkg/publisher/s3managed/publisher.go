@@ -27,11 +27,17 @@ type PublisherParams struct {
 	NodeInfoProvider     models.BaseNodeInfoProvider
 	LocalDir             string
 	URLUploader          URLUploader
+	// Optional. Results are archived locally and uploaded with a single request if not set.
+	PartUploader PartUploader
+	// Optional. Defaults are used for unset values.
+	Upload s3helper.UploadParams
 }
 
 type Publisher struct {
 	localDir             string
 	uploader             URLUploader
+	partUploader         PartUploader
+	upload               s3helper.UploadParams
 	nclPublisherProvider ncl.PublisherProvider
 }
 
@@ -39,6 +45,8 @@ func NewPublisher(params PublisherParams) *Publisher {
 	return &Publisher{
 		localDir:             params.LocalDir,
 		uploader:             params.URLUploader,
+		partUploader:         params.PartUploader,
+		upload:               params.Upload.WithDefaults(),
 		nclPublisherProvider: params.NCLPublisherProvider,
 	}
 }
@@ -67,6 +75,20 @@ func (p Publisher) PublishResult(ctx context.Context, execution *models.Executio
 		Str("job_id", execution.Job.ID).
 		Msg("Publishing results to managed S3 bucket using pre-signed URL")
 
+	if p.partUploader != nil {
+		uploadID, err := p.createMultipartUpload(ctx, execution)
+		if err == nil {
+			if err = p.publishMultipart(ctx, execution, resultPath, uploadID); err != nil {
+				return models.SpecConfig{}, err
+			}
+			return p.resultSpec(execution), nil
+		}
+		// orchestrators that predate multipart uploads only provide a pre-signed URL for the whole result
+		log.Ctx(ctx).Debug().Err(err).
+			Str("execution_id", execution.ID).
+			Msg("Multipart upload to managed S3 bucket is not available, uploading the result as a single archive")
+	}
+
 	// Get the pre-signed URL for uploading the result file
 	preSignedURL, err := p.getUploadURL(ctx, execution)
 	if err != nil {
@@ -118,26 +140,26 @@ func (p Publisher) PublishResult(ctx context.Context, execution *models.Executio
 		Str("result_path", resultPath).
 		Msg("published result to managed S3 bucket")
 
+	return p.resultSpec(execution), nil
+}
+
+func (p Publisher) resultSpec(execution *models.Execution) models.SpecConfig {
 	return models.SpecConfig{
 		Type: models.StorageSourceS3Managed,
 		Params: SourceSpec{
 			JobID:       execution.Job.ID,
 			ExecutionID: execution.ID,
 		}.ToMap(),
-	}, nil
+	}
 }
 
 // getUploadURL retrieves a pre-signed URL for uploading the result file.
 // The URL is provided by the orchestrator via an NCL Publisher
 func (p Publisher) getUploadURL(ctx context.Context, execution *models.Execution) (string, error) {
 	// Use the NCL publisher provider to get the upload URL
-	nclMessagePublisher, err := p.nclPublisherProvider.GetPublisher()
+	nclMessagePublisher, err := p.nclPublisher()
 	if err != nil {
-		return "", fmt.Errorf("failed to get NCL publisher: %w", err)
-	}
-	if nclMessagePublisher == nil {
-		return "", fmt.Errorf("node is disconnected from the orchestrator, or the managed S3 publisher " +
-			"is not supported by the orchestrator")
+		return "", err
 	}
 
 	message := envelope.NewMessage(messages.ManagedPublisherPreSignURLRequest{
@@ -165,5 +187,18 @@ func (p Publisher) getUploadURL(ctx context.Context, execution *models.Execution
 	return responseMessage.PreSignedURL, nil
 }
 
+// nclPublisher returns the publisher of requests to the orchestrator
+func (p Publisher) nclPublisher() (ncl.Publisher, error) {
+	nclMessagePublisher, err := p.nclPublisherProvider.GetPublisher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NCL publisher: %w", err)
+	}
+	if nclMessagePublisher == nil {
+		return nil, fmt.Errorf("node is disconnected from the orchestrator, or the managed S3 publisher " +
+			"is not supported by the orchestrator")
+	}
+	return nclMessagePublisher, nil
+}
+
 // Compile-time check that publisher implements the correct interface:
 var _ publisher.Publisher = (*Publisher)(nil)
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/bacalhau-project/bacalhau/pkg/config/types"
 	s3helper "github.com/bacalhau-project/bacalhau/pkg/s3"
 	"github.com/rs/zerolog/log"
@@ -124,6 +126,112 @@ func (p *PreSignedURLGenerator) GeneratePresignedGetURL(
 	return resp.URL, nil
 }
 
+// CreateMultipartUpload starts a multipart upload of the results of an execution, and returns its upload ID.
+func (p *PreSignedURLGenerator) CreateMultipartUpload(ctx context.Context, jobID string, executionID string) (string, error) {
+	if jobID == "" || executionID == "" {
+		return "", fmt.Errorf("jobID and executionID must be provided")
+	}
+	key := p.generateObjectKey(jobID, executionID)
+
+	log.Ctx(ctx).Debug().
+		Str("job_id", jobID).
+		Str("execution_id", executionID).
+		Str("bucket", p.publisherConfig.Bucket).
+		Str("key", key).
+		Msgf("Creating multipart upload of S3 object")
+
+	client := p.clientProvider.GetClient(p.publisherConfig.Endpoint, p.publisherConfig.Region)
+	resp, err := client.S3.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: &p.publisherConfig.Bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(resp.UploadId), nil
+}
+
+// GeneratePreSignedUploadPartURL creates a pre-signed URL for uploading a part of a multipart upload.
+func (p *PreSignedURLGenerator) GeneratePreSignedUploadPartURL(
+	ctx context.Context,
+	jobID string,
+	executionID string,
+	uploadID string,
+	partNumber int32,
+) (string, error) {
+	if jobID == "" || executionID == "" || uploadID == "" {
+		return "", fmt.Errorf("jobID, executionID and uploadID must be provided")
+	}
+	if partNumber < 1 || partNumber > s3helper.MaxUploadParts {
+		return "", fmt.Errorf("part number must be between 1 and %d", s3helper.MaxUploadParts)
+	}
+	key := p.generateObjectKey(jobID, executionID)
+
+	// Do not provide a body because the part is uploaded by the compute node.
+	request := &s3.UploadPartInput{
+		Bucket:     &p.publisherConfig.Bucket,
+		Key:        &key,
+		UploadId:   &uploadID,
+		PartNumber: &partNumber,
+	}
+
+	client := p.clientProvider.GetClient(p.publisherConfig.Endpoint, p.publisherConfig.Region)
+	expiration := p.publisherConfig.PreSignedURLExpiration.AsTimeDuration()
+
+	resp, err := client.PresignClient().PresignUploadPart(ctx, request, s3.WithPresignExpires(expiration))
+	if err != nil {
+		return "", err
+	}
+	return resp.URL, nil
+}
+
+// CompleteMultipartUpload completes a multipart upload from its uploaded parts.
+func (p *PreSignedURLGenerator) CompleteMultipartUpload(
+	ctx context.Context,
+	jobID string,
+	executionID string,
+	uploadID string,
+	parts []s3types.CompletedPart,
+) error {
+	if jobID == "" || executionID == "" || uploadID == "" {
+		return fmt.Errorf("jobID, executionID and uploadID must be provided")
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("multipart upload must have at least one part")
+	}
+	key := p.generateObjectKey(jobID, executionID)
+
+	client := p.clientProvider.GetClient(p.publisherConfig.Endpoint, p.publisherConfig.Region)
+	_, err := client.S3.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          &p.publisherConfig.Bucket,
+		Key:             &key,
+		UploadId:        &uploadID,
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: parts},
+	})
+	return err
+}
+
+// AbortMultipartUpload aborts a multipart upload and deletes its uploaded parts.
+func (p *PreSignedURLGenerator) AbortMultipartUpload(
+	ctx context.Context,
+	jobID string,
+	executionID string,
+	uploadID string,
+) error {
+	if jobID == "" || executionID == "" || uploadID == "" {
+		return fmt.Errorf("jobID, executionID and uploadID must be provided")
+	}
+	key := p.generateObjectKey(jobID, executionID)
+
+	client := p.clientProvider.GetClient(p.publisherConfig.Endpoint, p.publisherConfig.Region)
+	_, err := client.S3.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   &p.publisherConfig.Bucket,
+		Key:      &key,
+		UploadId: &uploadID,
+	})
+	return err
+}
+
 // generateObjectKey constructs the S3 object key based on job and execution IDs.
 func (p *PreSignedURLGenerator) generateObjectKey(jobID, executionID string) string {
 	// Create key with prefix if available
@@ -1,6 +1,7 @@
 package s3managed
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net/http"
@@ -15,6 +16,11 @@ type URLUploader interface {
 	Upload(ctx context.Context, url string, filePath string) error
 }
 
+// PartUploader uploads a part of a multipart upload to a pre-signed URL, and returns the ETag of the part
+type PartUploader interface {
+	UploadPart(ctx context.Context, url string, part []byte) (string, error)
+}
+
 const (
 	PreSignedURLUploadRetryCount = 5
 )
@@ -24,16 +30,55 @@ type S3PreSignedURLUploader struct {
 	retryCount int
 }
 
-// NewS3PreSignedURLUploader creates a new uploader that uses pre-signed URLs to upload files to S3.
-func NewS3PreSignedURLUploader(httpClient *http.Client) URLUploader {
+// NewS3PreSignedURLUploader creates a new uploader that uses pre-signed URLs to upload files and parts to S3.
+// Failed uploads are retried retryCount times, or PreSignedURLUploadRetryCount times if it is not positive.
+func NewS3PreSignedURLUploader(httpClient *http.Client, retryCount int) *S3PreSignedURLUploader {
+	if retryCount <= 0 {
+		retryCount = PreSignedURLUploadRetryCount
+	}
 	return &S3PreSignedURLUploader{
 		httpClient: httpClient,
-		retryCount: PreSignedURLUploadRetryCount,
+		retryCount: retryCount,
 	}
 }
 
 func (u *S3PreSignedURLUploader) Upload(ctx context.Context, url string, filePath string) error {
-	return u.uploadWithRetry(ctx, url, filePath, u.retryCount)
+	return u.uploadWithRetry(ctx, filePath, func() error {
+		return u.uploadWithPreSignedURL(ctx, filePath, url)
+	})
+}
+
+// UploadPart uploads a part of a multipart upload, and returns its ETag which is needed to complete the upload
+func (u *S3PreSignedURLUploader) UploadPart(ctx context.Context, url string, part []byte) (string, error) {
+	var etag string
+	err := u.uploadWithRetry(ctx, "part", func() error {
+		var err error
+		etag, err = u.uploadPartWithPreSignedURL(ctx, url, part)
+		return err
+	})
+	return etag, err
+}
+
+func (u *S3PreSignedURLUploader) uploadPartWithPreSignedURL(ctx context.Context, presignedURL string, part []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, presignedURL, bytes.NewReader(part))
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload request: %w", err)
+	}
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("upload failed with status code: %d", resp.StatusCode)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("upload response has no ETag")
+	}
+	return etag, nil
 }
 
 func (u *S3PreSignedURLUploader) uploadWithPreSignedURL(
@@ -83,12 +128,13 @@ func (u *S3PreSignedURLUploader) uploadWithPreSignedURL(
 	return nil
 }
 
+// uploadWithRetry runs upload until it succeeds, with an exponential backoff between attempts
 func (u *S3PreSignedURLUploader) uploadWithRetry(
 	ctx context.Context,
-	url string,
 	filePath string,
-	retryCount int,
+	upload func() error,
 ) error {
+	retryCount := u.retryCount
 	var lastErr error
 	for attempt := 0; attempt <= retryCount; attempt++ {
 		if attempt > 0 {
@@ -120,7 +166,7 @@ func (u *S3PreSignedURLUploader) uploadWithRetry(
 		}
 
 		// Attempt upload
-		err := u.uploadWithPreSignedURL(ctx, filePath, url)
+		err := upload()
 		if err == nil {
 			// Success
 			if attempt > 0 {
@@ -138,3 +184,7 @@ func (u *S3PreSignedURLUploader) uploadWithRetry(
 
 	return fmt.Errorf("failed to upload after %d attempts: %w", retryCount+1, lastErr)
 }
+
+// compile-time check for interface implementation
+var _ URLUploader = (*S3PreSignedURLUploader)(nil)
+var _ PartUploader = (*S3PreSignedURLUploader)(nil)
@@ -12,13 +12,16 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/lib/ncl"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/provider"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher/ipfs"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher/local"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher/noop"
+	ocipublisher "github.com/bacalhau-project/bacalhau/pkg/publisher/oci"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher/s3"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher/s3managed"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher/tracing"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/webhook"
 	s3helper "github.com/bacalhau-project/bacalhau/pkg/s3"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
@@ -28,6 +31,7 @@ func NewPublisherProvider(
 	ctx context.Context,
 	cfg types.Bacalhau,
 	nclPublisherProvider ncl.PublisherProvider,
+	secretResolver webhook.SecretResolver,
 ) (publisher.PublisherProvider, error) {
 	storagePath, err := cfg.ResultsStorageDir()
 	if err != nil {
@@ -40,7 +44,7 @@ func NewPublisherProvider(
 	}
 
 	if cfg.Publishers.IsNotDisabled(models.PublisherS3) {
-		s3Publisher, err := configureS3Publisher(storagePath)
+		s3Publisher, err := configureS3Publisher(cfg.Publishers.Types.S3.Upload)
 		if err != nil {
 			return nil, err
 		}
@@ -48,7 +52,8 @@ func NewPublisherProvider(
 	}
 
 	if cfg.Publishers.IsNotDisabled(models.PublisherS3Managed) {
-		s3ManagedPublisher, err := configureS3ManagedPublisher(storagePath, nclPublisherProvider)
+		s3ManagedPublisher, err := configureS3ManagedPublisher(
+			storagePath, cfg.Publishers.Types.S3Managed.Upload, nclPublisherProvider)
 		if err != nil {
 			return nil, err
 		}
@@ -63,6 +68,22 @@ func NewPublisherProvider(
 		providers[models.PublisherLocal] = tracing.Wrap(localPublisher)
 	}
 
+	if cfg.Publishers.IsNotDisabled(models.PublisherWebhook) {
+		webhookPublisher, err := configureWebhookPublisher(storagePath, secretResolver)
+		if err != nil {
+			return nil, err
+		}
+		providers[models.PublisherWebhook] = tracing.Wrap(webhookPublisher)
+	}
+
+	if cfg.Publishers.IsNotDisabled(models.PublisherOCI) {
+		ociPublisher, err := configureOCIPublisher(cfg, storagePath)
+		if err != nil {
+			return nil, err
+		}
+		providers[models.PublisherOCI] = tracing.Wrap(ociPublisher)
+	}
+
 	if cfg.Publishers.IsNotDisabled(models.PublisherIPFS) {
 		if cfg.Publishers.Types.IPFS.Endpoint != "" {
 			ipfsClient, err := ipfs_client.NewClient(ctx, cfg.Publishers.Types.IPFS.Endpoint)
@@ -93,12 +114,11 @@ func configureLocalPublisher(ctx context.Context, cfg types.Bacalhau, storagePat
 	)
 }
 
-func configureS3Publisher(storagePath string) (*s3.Publisher, error) {
-	path := filepath.Join(storagePath, "s3-publisher")
-	if err := os.MkdirAll(path, util.OS_USER_RWX); err != nil {
+func configureS3Publisher(uploadConfig types.S3Upload) (*s3.Publisher, error) {
+	upload, err := s3helper.NewUploadParams(uploadConfig)
+	if err != nil {
 		return nil, err
 	}
-
 	cfg, err := s3helper.DefaultAWSConfig()
 	if err != nil {
 		return nil, err
@@ -107,28 +127,68 @@ func configureS3Publisher(storagePath string) (*s3.Publisher, error) {
 		AWSConfig: cfg,
 	})
 	return s3.NewPublisher(s3.PublisherParams{
-		LocalDir:       path,
 		ClientProvider: clientProvider,
+		Upload:         upload,
 	}), nil
 }
 
 func configureS3ManagedPublisher(
 	storagePath string,
+	uploadConfig types.S3Upload,
 	nclPublisherProvider ncl.PublisherProvider,
 ) (*s3managed.Publisher, error) {
 	if nclPublisherProvider == nil {
 		return nil, fmt.Errorf("S3Managed publisher requires an NCL publisher provider")
 	}
 
+	upload, err := s3helper.NewUploadParams(uploadConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	path := filepath.Join(storagePath, "s3managed-publisher")
 	if err := os.MkdirAll(path, util.OS_USER_RWX); err != nil {
 		return nil, err
 	}
 
+	uploader := s3managed.NewS3PreSignedURLUploader(http.DefaultClient, upload.MaxRetries)
 	return s3managed.NewPublisher(s3managed.PublisherParams{
 		NCLPublisherProvider: nclPublisherProvider,
 		LocalDir:             path,
-		URLUploader:          s3managed.NewS3PreSignedURLUploader(http.DefaultClient),
+		URLUploader:          uploader,
+		PartUploader:         uploader,
+		Upload:               upload,
+	}), nil
+}
+
+func configureWebhookPublisher(
+	storagePath string,
+	secretResolver webhook.SecretResolver,
+) (*webhook.Publisher, error) {
+	path := filepath.Join(storagePath, "webhook-publisher")
+	if err := os.MkdirAll(path, util.OS_USER_RWX); err != nil {
+		return nil, err
+	}
+
+	return webhook.NewPublisher(webhook.PublisherParams{
+		LocalDir:       path,
+		HTTPClient:     http.DefaultClient,
+		SecretResolver: secretResolver,
+	}), nil
+}
+
+func configureOCIPublisher(cfg types.Bacalhau, storagePath string) (*ocipublisher.Publisher, error) {
+	path := filepath.Join(storagePath, "oci-publisher")
+	if err := os.MkdirAll(path, util.OS_USER_RWX); err != nil {
+		return nil, err
+	}
+
+	return ocipublisher.NewPublisher(ocipublisher.PublisherParams{
+		LocalDir: path,
+		Client: oci.NewClient(oci.ClientParams{
+			Credentials:        oci.GetCredentials(),
+			InsecureRegistries: cfg.Publishers.Types.OCI.InsecureRegistries,
+		}),
 	}), nil
 }
 
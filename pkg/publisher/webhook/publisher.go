@@ -0,0 +1,275 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/backoff"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher"
+)
+
+const (
+	defaultBaseBackoff = 1 * time.Second
+	defaultMaxBackoff  = 30 * time.Second
+)
+
+// SecretResolver resolves secret references found in header values
+type SecretResolver interface {
+	// Value returns the secret referenced by value, which has the form "<backend>/<key>"
+	Value(value string) (string, error)
+}
+
+type PublisherParams struct {
+	LocalDir   string
+	HTTPClient *http.Client
+	// SecretResolver resolves secret references in headers. Optional.
+	SecretResolver SecretResolver
+	// Backoff between retries of failed upload requests. Optional.
+	Backoff backoff.Backoff
+}
+
+// Compile-time check that publisher implements the correct interface:
+var _ publisher.Publisher = (*Publisher)(nil)
+
+// Publisher uploads results to an HTTP endpoint
+type Publisher struct {
+	localDir       string
+	httpClient     *http.Client
+	secretResolver SecretResolver
+	backoff        backoff.Backoff
+}
+
+func NewPublisher(params PublisherParams) *Publisher {
+	p := &Publisher{
+		localDir:       params.LocalDir,
+		httpClient:     params.HTTPClient,
+		secretResolver: params.SecretResolver,
+		backoff:        params.Backoff,
+	}
+	if p.httpClient == nil {
+		p.httpClient = http.DefaultClient
+	}
+	if p.backoff == nil {
+		p.backoff = backoff.NewExponential(defaultBaseBackoff, defaultMaxBackoff)
+	}
+	return p
+}
+
+// IsInstalled returns true as the publisher only needs network access
+func (publisher *Publisher) IsInstalled(_ context.Context) (bool, error) {
+	return true, nil
+}
+
+// ValidateJob validates the job spec and returns an error if the job is invalid.
+func (publisher *Publisher) ValidateJob(_ context.Context, j models.Job) error {
+	_, err := DecodePublisherSpec(j.Task().Publisher)
+	return err
+}
+
+func (publisher *Publisher) PublishResult(
+	ctx context.Context,
+	execution *models.Execution,
+	resultPath string,
+) (models.SpecConfig, error) {
+	spec, err := DecodePublisherSpec(execution.Job.Task().Publisher)
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+
+	u, err := publisher.newUploader(spec)
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+
+	if spec.GetEncoding() == EncodingPlain {
+		return publisher.publishDirectory(ctx, u, spec, execution, resultPath)
+	}
+	return publisher.publishArchive(ctx, u, spec, execution, resultPath)
+}
+
+func (publisher *Publisher) newUploader(spec PublisherSpec) (*uploader, error) {
+	headers, err := publisher.resolveHeaders(spec.Headers)
+	if err != nil {
+		return nil, err
+	}
+	chunkSize, err := spec.GetChunkSize()
+	if err != nil {
+		return nil, err
+	}
+	return &uploader{
+		client:     publisher.httpClient,
+		backoff:    publisher.backoff,
+		method:     spec.GetMethod(),
+		headers:    headers,
+		chunkSize:  chunkSize,
+		maxRetries: spec.GetMaxRetries(),
+	}, nil
+}
+
+// resolveHeaders returns the upload headers with secret references replaced by their values
+func (publisher *Publisher) resolveHeaders(headers map[string]string) (http.Header, error) {
+	resolved := make(http.Header, len(headers))
+	for name, value := range headers {
+		if models.EnvVarValue(value).IsSecret() {
+			if publisher.secretResolver == nil {
+				return nil, bacerrors.Newf("header %s references a secret, but secrets are not supported by this node", name).
+					WithComponent(errComponent).
+					WithCode(bacerrors.ConfigurationError)
+			}
+			secret, err := publisher.secretResolver.Value(strings.TrimPrefix(value, models.EnvVarSecretScheme+":"))
+			if err != nil {
+				// the value is not included as it may carry a sealed secret
+				return nil, bacerrors.Wrapf(err, "failed to resolve secret for header %s", name).
+					WithComponent(errComponent)
+			}
+			value = secret
+		}
+		resolved.Set(name, value)
+	}
+	return resolved, nil
+}
+
+func (publisher *Publisher) publishArchive(
+	ctx context.Context,
+	u *uploader,
+	spec PublisherSpec,
+	execution *models.Execution,
+	resultPath string,
+) (models.SpecConfig, error) {
+	targetURL, err := ParsePublishedURL(spec.URL, execution, true)
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+
+	targetFile, err := os.CreateTemp(publisher.localDir, "bacalhau-archive-*.tar.gz")
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+	defer func() { _ = targetFile.Close() }()
+	defer func() { _ = os.Remove(targetFile.Name()) }()
+
+	if err = gzip.Compress(resultPath, targetFile); err != nil {
+		return models.SpecConfig{}, err
+	}
+	info, err := targetFile.Stat()
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+
+	u.contentType = "application/gzip"
+	if err = u.upload(ctx, targetURL, targetFile, info.Size()); err != nil {
+		return models.SpecConfig{}, bacerrors.Wrap(err, "failed to upload results").
+			WithComponent(errComponent)
+	}
+	log.Ctx(ctx).Debug().Msgf("Uploaded results to %s", targetURL)
+
+	return models.SpecConfig{
+		Type: models.StorageSourceWebhook,
+		Params: ResultSpec{
+			URL:      targetURL,
+			Encoding: EncodingGzip,
+		}.ToMap(),
+	}, nil
+}
+
+func (publisher *Publisher) publishDirectory(
+	ctx context.Context,
+	u *uploader,
+	spec PublisherSpec,
+	execution *models.Execution,
+	resultPath string,
+) (models.SpecConfig, error) {
+	prefixURL, err := ParsePublishedURL(spec.URL, execution, false)
+	if err != nil {
+		return models.SpecConfig{}, err
+	}
+
+	u.contentType = "application/octet-stream"
+	var files []string
+	err = filepath.Walk(resultPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil // skip directories
+		}
+		relativePath, err := filepath.Rel(resultPath, path)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		fileURL, err := JoinURL(prefixURL, relativePath)
+		if err != nil {
+			return err
+		}
+
+		data, err := os.Open(path) //nolint:gosec // G304: path from local result storage, application controlled
+		if err != nil {
+			return err
+		}
+		defer func() { _ = data.Close() }()
+
+		if err = u.upload(ctx, fileURL, data, info.Size()); err != nil {
+			return err
+		}
+		log.Ctx(ctx).Debug().Msgf("Uploaded %s", fileURL)
+		files = append(files, relativePath)
+		return nil
+	})
+	if err != nil {
+		return models.SpecConfig{}, bacerrors.Wrap(err, "failed to upload results").
+			WithComponent(errComponent)
+	}
+
+	return models.SpecConfig{
+		Type: models.StorageSourceWebhook,
+		Params: ResultSpec{
+			URL:      prefixURL,
+			Encoding: EncodingPlain,
+			Files:    files,
+		}.ToMap(),
+	}, nil
+}
+
+// ParsePublishedURL replaces the placeholders in the URL template with the execution's values,
+// and appends .tar.gz to the path of archives, or a trailing slash to the path prefix of plain uploads.
+func ParsePublishedURL(template string, execution *models.Execution, archive bool) (string, error) {
+	replacer := strings.NewReplacer(
+		"{nodeID}", execution.NodeID,
+		"{executionID}", execution.ID,
+		"{jobID}", execution.JobID,
+		"{date}", time.Now().Format("20060102"),
+		"{time}", time.Now().Format("150405"),
+	)
+	u, err := url.Parse(replacer.Replace(template))
+	if err != nil {
+		return "", fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if archive && !strings.HasSuffix(u.Path, ".tar.gz") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + ".tar.gz"
+	}
+	if !archive && !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u.String(), nil
+}
+
+// JoinURL returns the URL of a file uploaded under the prefix URL with plain encoding
+func JoinURL(prefixURL string, relativePath string) (string, error) {
+	u, err := url.Parse(prefixURL)
+	if err != nil {
+		return "", err
+	}
+	return u.JoinPath(strings.Split(relativePath, "/")...).String(), nil
+}
@@ -0,0 +1,324 @@
+//go:build unit || !integration
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/backoff"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+// uploadServer stores uploaded content by path, supporting chunked uploads with Content-Range
+type uploadServer struct {
+	mu       sync.Mutex
+	content  map[string][]byte
+	requests []*http.Request
+	// failChunk fails the first upload of the chunk starting at this offset, after storing half of it
+	failChunk int64
+	failed    bool
+	// failures is the number of requests that fail with failStatus before succeeding
+	failures   int
+	failStatus int
+}
+
+func newUploadServer() *uploadServer {
+	return &uploadServer{content: make(map[string][]byte), failChunk: -1}
+}
+
+func (s *uploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+	body, _ := io.ReadAll(r.Body)
+
+	if s.failures > 0 {
+		s.failures--
+		http.Error(w, "failed", s.failStatus)
+		return
+	}
+
+	contentRange := r.Header.Get("Content-Range")
+	if contentRange == "" {
+		s.content[r.URL.Path] = body
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	// upload status query
+	if total, ok := strings.CutPrefix(contentRange, "bytes */"); ok {
+		received := len(s.content[r.URL.Path])
+		if strconv.Itoa(received) == total {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if received > 0 {
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", received-1))
+		}
+		w.WriteHeader(statusResumeIncomplete)
+		return
+	}
+
+	var start, end, total int64
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &total); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if start != int64(len(s.content[r.URL.Path])) {
+		http.Error(w, "unexpected offset", http.StatusBadRequest)
+		return
+	}
+	if start == s.failChunk && !s.failed {
+		s.failed = true
+		s.content[r.URL.Path] = append(s.content[r.URL.Path], body[:len(body)/2]...)
+		http.Error(w, "interrupted", http.StatusBadGateway)
+		return
+	}
+	s.content[r.URL.Path] = append(s.content[r.URL.Path], body...)
+	if end+1 < total {
+		w.WriteHeader(statusResumeIncomplete)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+type PublisherTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	server    *uploadServer
+	httpSrv   *httptest.Server
+	pub       *Publisher
+	resultDir string
+	execution *models.Execution
+}
+
+func TestPublisherTestSuite(t *testing.T) {
+	suite.Run(t, new(PublisherTestSuite))
+}
+
+func (s *PublisherTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.server = newUploadServer()
+	s.httpSrv = httptest.NewServer(s.server)
+	s.T().Cleanup(s.httpSrv.Close)
+
+	s.pub = NewPublisher(PublisherParams{
+		LocalDir:       s.T().TempDir(),
+		HTTPClient:     s.httpSrv.Client(),
+		SecretResolver: fakeSecretResolver{"file/token": "s3cr3t"},
+		Backoff:        backoff.NewNoop(),
+	})
+
+	s.resultDir = s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(s.resultDir, "stdout"), []byte("hello"), 0644))
+	s.Require().NoError(os.MkdirAll(filepath.Join(s.resultDir, "outputs", "sub dir"), 0755))
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(s.resultDir, "outputs", "sub dir", "data.bin"), []byte(strings.Repeat("x", 100)), 0644))
+
+	s.execution = mock.Execution()
+}
+
+func (s *PublisherTestSuite) publish(params map[string]interface{}) (ResultSpec, error) {
+	s.execution.Job.Task().Publisher = &models.SpecConfig{Type: models.PublisherWebhook, Params: params}
+	result, err := s.pub.PublishResult(s.ctx, s.execution, s.resultDir)
+	if err != nil {
+		return ResultSpec{}, err
+	}
+	return DecodeResultSpec(&result)
+}
+
+func (s *PublisherTestSuite) TestPublishArchive() {
+	result, err := s.publish(map[string]interface{}{
+		"URL": s.httpSrv.URL + "/results/{jobID}/{executionID}",
+	})
+	s.Require().NoError(err)
+
+	expectedPath := fmt.Sprintf("/results/%s/%s.tar.gz", s.execution.JobID, s.execution.ID)
+	s.Equal(s.httpSrv.URL+expectedPath, result.URL)
+	s.Equal(EncodingGzip, result.Encoding)
+	s.Require().Len(s.server.requests, 1)
+	s.Equal(http.MethodPut, s.server.requests[0].Method)
+	s.Equal("application/gzip", s.server.requests[0].Header.Get("Content-Type"))
+	s.assertArchive(s.server.content[expectedPath])
+}
+
+func (s *PublisherTestSuite) TestPublishDirectory() {
+	result, err := s.publish(map[string]interface{}{
+		"URL":      s.httpSrv.URL + "/results/{jobID}",
+		"Method":   "post",
+		"Encoding": "plain",
+	})
+	s.Require().NoError(err)
+
+	prefix := fmt.Sprintf("/results/%s/", s.execution.JobID)
+	s.Equal(s.httpSrv.URL+prefix, result.URL)
+	s.Equal(EncodingPlain, result.Encoding)
+	s.ElementsMatch([]string{"stdout", "outputs/sub dir/data.bin"}, result.Files)
+	s.Equal("hello", string(s.server.content[prefix+"stdout"]))
+	s.Equal(strings.Repeat("x", 100), string(s.server.content[prefix+"outputs/sub dir/data.bin"]))
+	for _, r := range s.server.requests {
+		s.Equal(http.MethodPost, r.Method)
+	}
+}
+
+func (s *PublisherTestSuite) TestPublishWithHeaders() {
+	_, err := s.publish(map[string]interface{}{
+		"URL": s.httpSrv.URL + "/results",
+		"Headers": map[string]interface{}{
+			"Authorization": "secret:file/token",
+			"X-Team":        "data",
+		},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(s.server.requests, 1)
+	s.Equal("s3cr3t", s.server.requests[0].Header.Get("Authorization"))
+	s.Equal("data", s.server.requests[0].Header.Get("X-Team"))
+}
+
+func (s *PublisherTestSuite) TestPublishWithUnknownSecret() {
+	_, err := s.publish(map[string]interface{}{
+		"URL":     s.httpSrv.URL + "/results",
+		"Headers": map[string]interface{}{"Authorization": "secret:file/missing"},
+	})
+	s.Require().Error(err)
+	s.Empty(s.server.requests)
+}
+
+func (s *PublisherTestSuite) TestPublishSecretsWithoutResolver() {
+	s.pub.secretResolver = nil
+	_, err := s.publish(map[string]interface{}{
+		"URL":     s.httpSrv.URL + "/results",
+		"Headers": map[string]interface{}{"Authorization": "secret:file/token"},
+	})
+	s.Require().Error(err)
+	s.Empty(s.server.requests)
+}
+
+func (s *PublisherTestSuite) TestPublishChunked() {
+	result, err := s.publish(map[string]interface{}{
+		"URL":       s.httpSrv.URL + "/results",
+		"ChunkSize": "16B",
+	})
+	s.Require().NoError(err)
+	s.Greater(len(s.server.requests), 1)
+	s.assertArchive(s.server.content["/results.tar.gz"])
+	s.Equal(s.httpSrv.URL+"/results.tar.gz", result.URL)
+}
+
+func (s *PublisherTestSuite) TestPublishChunkedResumes() {
+	s.server.failChunk = 32
+	_, err := s.publish(map[string]interface{}{
+		"URL":       s.httpSrv.URL + "/results",
+		"ChunkSize": "16B",
+	})
+	s.Require().NoError(err)
+	s.True(s.server.failed)
+	s.assertArchive(s.server.content["/results.tar.gz"])
+
+	var statusQueries int
+	for _, r := range s.server.requests {
+		if strings.HasPrefix(r.Header.Get("Content-Range"), "bytes */") {
+			statusQueries++
+		}
+	}
+	s.Equal(1, statusQueries)
+}
+
+func (s *PublisherTestSuite) TestPublishRetries() {
+	s.server.failures = 2
+	s.server.failStatus = http.StatusServiceUnavailable
+	_, err := s.publish(map[string]interface{}{
+		"URL": s.httpSrv.URL + "/results",
+	})
+	s.Require().NoError(err)
+	s.Len(s.server.requests, 3)
+	s.assertArchive(s.server.content["/results.tar.gz"])
+}
+
+func (s *PublisherTestSuite) TestPublishRetriesExhausted() {
+	s.server.failures = 3
+	s.server.failStatus = http.StatusServiceUnavailable
+	_, err := s.publish(map[string]interface{}{
+		"URL":        s.httpSrv.URL + "/results",
+		"MaxRetries": "2",
+	})
+	s.Require().Error(err)
+	s.Len(s.server.requests, 3)
+}
+
+func (s *PublisherTestSuite) TestPublishDoesNotRetryClientErrors() {
+	s.server.failures = 1
+	s.server.failStatus = http.StatusForbidden
+	_, err := s.publish(map[string]interface{}{
+		"URL": s.httpSrv.URL + "/results",
+	})
+	s.Require().Error(err)
+	s.Len(s.server.requests, 1)
+}
+
+func (s *PublisherTestSuite) TestValidateJob() {
+	for _, tc := range []struct {
+		name   string
+		params map[string]interface{}
+		valid  bool
+	}{
+		{name: "valid", params: map[string]interface{}{"URL": "https://example.com/results"}, valid: true},
+		{name: "missing url", params: map[string]interface{}{}},
+		{name: "invalid scheme", params: map[string]interface{}{"URL": "ftp://example.com/results"}},
+		{name: "invalid method", params: map[string]interface{}{"URL": "https://example.com", "Method": "GET"}},
+		{name: "invalid encoding", params: map[string]interface{}{"URL": "https://example.com", "Encoding": "zip"}},
+		{name: "invalid chunk size", params: map[string]interface{}{"URL": "https://example.com", "ChunkSize": "big"}},
+		{name: "negative retries", params: map[string]interface{}{"URL": "https://example.com", "MaxRetries": -1}},
+	} {
+		s.Run(tc.name, func() {
+			job := mock.Job()
+			job.Task().Publisher = &models.SpecConfig{Type: models.PublisherWebhook, Params: tc.params}
+			err := s.pub.ValidateJob(s.ctx, *job)
+			if tc.valid {
+				s.NoError(err)
+			} else {
+				s.Error(err)
+			}
+		})
+	}
+}
+
+func (s *PublisherTestSuite) assertArchive(archive []byte) {
+	s.Require().NotEmpty(archive)
+	archivePath := filepath.Join(s.T().TempDir(), "results.tar.gz")
+	s.Require().NoError(os.WriteFile(archivePath, archive, 0644))
+	target := s.T().TempDir()
+	s.Require().NoError(gzip.Decompress(archivePath, target))
+
+	stdout, err := os.ReadFile(filepath.Join(target, "stdout"))
+	s.Require().NoError(err)
+	s.Equal("hello", string(stdout))
+	data, err := os.ReadFile(filepath.Join(target, "outputs", "sub dir", "data.bin"))
+	s.Require().NoError(err)
+	s.Equal(strings.Repeat("x", 100), string(data))
+}
+
+type fakeSecretResolver map[string]string
+
+func (r fakeSecretResolver) Value(value string) (string, error) {
+	secret, ok := r[value]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return secret, nil
+}
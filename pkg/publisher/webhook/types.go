@@ -0,0 +1,224 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/structs"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+const errComponent = "WebhookPublisher"
+
+const (
+	// DefaultMaxRetries is the number of times a failed upload request is retried
+	DefaultMaxRetries = 3
+)
+
+type Encoding string
+
+const (
+	// EncodingGzip uploads the results as a single tar.gz archive
+	EncodingGzip Encoding = "gzip"
+	// EncodingPlain uploads each result file individually
+	EncodingPlain Encoding = "plain"
+)
+
+func (e Encoding) IsValid() bool {
+	return e == EncodingGzip || e == EncodingPlain
+}
+
+// PublisherSpec is the configuration of the webhook publisher in a job spec
+type PublisherSpec struct {
+	// URL is where the results are uploaded. The archive is uploaded to URL with gzip encoding,
+	// and each file is uploaded to URL joined with its path relative to the results directory with
+	// plain encoding. It supports the {jobID}, {executionID}, {nodeID}, {date} and {time} placeholders.
+	URL string `json:"URL"`
+	// Method is the HTTP method used to upload results, PUT or POST. Defaults to PUT.
+	Method string `json:"Method,omitempty"`
+	// Encoding is how the results are uploaded. Defaults to gzip.
+	Encoding Encoding `json:"Encoding,omitempty"`
+	// Headers are added to upload requests. Values can reference secrets resolved by the compute
+	// node with the form "secret:<backend>/<key>", so that credentials are not stored in the job spec.
+	Headers map[string]string `json:"Headers,omitempty"`
+	// ChunkSize is the size of the chunks uploads are split into, e.g. "8MiB". Each chunk is sent with a
+	// Content-Range header, and interrupted uploads are resumed instead of restarted. Uploads are sent in a
+	// single request if empty.
+	ChunkSize string `json:"ChunkSize,omitempty"`
+	// MaxRetries is the number of times a failed upload request is retried with backoff.
+	// Defaults to DefaultMaxRetries.
+	MaxRetries *int `json:"MaxRetries,omitempty"`
+}
+
+func (c PublisherSpec) Validate() error {
+	var mErr error
+	if c.URL == "" {
+		mErr = errors.Join(mErr, errors.New("url cannot be empty"))
+	} else if err := validateURL(c.URL); err != nil {
+		mErr = errors.Join(mErr, err)
+	}
+	if c.Method != "" && c.GetMethod() != http.MethodPut && c.GetMethod() != http.MethodPost {
+		mErr = errors.Join(mErr, fmt.Errorf("method must be either PUT or POST, but received: %s", c.Method))
+	}
+	if c.Encoding != "" && !c.Encoding.IsValid() {
+		mErr = errors.Join(mErr, errors.New("encoding must be either 'plain' or 'gzip'"))
+	}
+	if _, err := c.GetChunkSize(); err != nil {
+		mErr = errors.Join(mErr, err)
+	}
+	if c.MaxRetries != nil && *c.MaxRetries < 0 {
+		mErr = errors.Join(mErr, fmt.Errorf("max retries cannot be negative: %d", *c.MaxRetries))
+	}
+	for name := range c.Headers {
+		if strings.TrimSpace(name) == "" {
+			mErr = errors.Join(mErr, errors.New("header name cannot be empty"))
+		}
+	}
+	if mErr != nil {
+		return bacerrors.Wrap(mErr, "invalid webhook publisher params").
+			WithComponent(errComponent).
+			WithCode(bacerrors.ValidationError)
+	}
+	return nil
+}
+
+// GetMethod returns the HTTP method used to upload results
+func (c PublisherSpec) GetMethod() string {
+	if c.Method == "" {
+		return http.MethodPut
+	}
+	return strings.ToUpper(c.Method)
+}
+
+// GetEncoding returns how the results are uploaded
+func (c PublisherSpec) GetEncoding() Encoding {
+	if c.Encoding == "" {
+		return EncodingGzip
+	}
+	return c.Encoding
+}
+
+// GetChunkSize returns the size of upload chunks in bytes, or zero if uploads are not chunked
+func (c PublisherSpec) GetChunkSize() (int64, error) {
+	if c.ChunkSize == "" {
+		return 0, nil
+	}
+	size, err := humanize.ParseBytes(c.ChunkSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chunk size %q: %w", c.ChunkSize, err)
+	}
+	return int64(size), nil //nolint:gosec // G115: chunk sizes are far below the int64 limit
+}
+
+// GetMaxRetries returns the number of times a failed upload request is retried
+func (c PublisherSpec) GetMaxRetries() int {
+	if c.MaxRetries == nil {
+		return DefaultMaxRetries
+	}
+	return *c.MaxRetries
+}
+
+func (c PublisherSpec) ToMap() map[string]interface{} {
+	return structs.Map(c)
+}
+
+func DecodePublisherSpec(spec *models.SpecConfig) (PublisherSpec, error) {
+	if !spec.IsType(models.PublisherWebhook) {
+		return PublisherSpec{}, bacerrors.Newf("invalid publisher type. expected %s, but received: %s",
+			models.PublisherWebhook, spec.Type).
+			WithComponent(errComponent).
+			WithCode(bacerrors.ValidationError)
+	}
+	if spec.Params == nil {
+		return PublisherSpec{}, bacerrors.New("invalid publisher params. cannot be nil").
+			WithComponent(errComponent).
+			WithCode(bacerrors.ValidationError)
+	}
+
+	var c PublisherSpec
+	// params set from the CLI are strings, so they are weakly decoded
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &c,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return c, err
+	}
+	if err = decoder.Decode(spec.Params); err != nil {
+		return c, err
+	}
+	return c, c.Validate()
+}
+
+// NewSpecConfig returns the spec of a webhook publisher uploading results to the url
+func NewSpecConfig(url string) *models.SpecConfig {
+	return &models.SpecConfig{
+		Type:   models.PublisherWebhook,
+		Params: PublisherSpec{URL: url}.ToMap(),
+	}
+}
+
+// ResultSpec describes results published by the webhook publisher, so they can be downloaded
+type ResultSpec struct {
+	// URL is where the archive was uploaded with gzip encoding, or the prefix of the uploaded files
+	// with plain encoding
+	URL string
+	// Encoding is how the results were uploaded
+	Encoding Encoding
+	// Files are the paths of the uploaded files relative to URL, with plain encoding
+	Files []string
+}
+
+func (c ResultSpec) Validate() error {
+	if c.URL == "" {
+		return errors.New("invalid webhook result params: url cannot be empty")
+	}
+	if err := validateURL(c.URL); err != nil {
+		return fmt.Errorf("invalid webhook result params: %w", err)
+	}
+	if !c.Encoding.IsValid() {
+		return errors.New("invalid webhook result params: encoding must be either 'plain' or 'gzip'")
+	}
+	return nil
+}
+
+func (c ResultSpec) ToMap() map[string]interface{} {
+	return structs.Map(c)
+}
+
+func DecodeResultSpec(spec *models.SpecConfig) (ResultSpec, error) {
+	if !spec.IsType(models.StorageSourceWebhook) {
+		return ResultSpec{}, errors.New(
+			"invalid storage source type. expected " + models.StorageSourceWebhook + ", but received: " + spec.Type)
+	}
+	if spec.Params == nil {
+		return ResultSpec{}, errors.New("invalid storage source params. cannot be nil")
+	}
+
+	var c ResultSpec
+	if err := mapstructure.Decode(spec.Params, &c); err != nil {
+		return c, err
+	}
+	return c, c.Validate()
+}
+
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url scheme must be http or https, but received: %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("url %q has no host", rawURL)
+	}
+	return nil
+}
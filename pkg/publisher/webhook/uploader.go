@@ -0,0 +1,209 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/backoff"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+)
+
+// statusResumeIncomplete is returned by servers supporting resumable uploads when a
+// chunk was accepted but the upload is not complete yet
+const statusResumeIncomplete = http.StatusPermanentRedirect
+
+// maxErrorBodySize limits how much of an error response body is kept for context
+const maxErrorBodySize = 1 << 10
+
+// rangePattern matches the Range header returned by servers supporting resumable uploads
+var rangePattern = regexp.MustCompile(`^bytes=0-(\d+)$`)
+
+// statusError is returned when the server responds to an upload request with an unexpected status
+type statusError struct {
+	url        string
+	statusCode int
+	body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("upload to %s failed with status code %d: %s", e.url, e.statusCode, e.body)
+}
+
+// retryable returns true if the request might succeed when retried
+func (e *statusError) retryable() bool {
+	return e.statusCode == http.StatusRequestTimeout ||
+		e.statusCode == http.StatusTooManyRequests ||
+		e.statusCode >= http.StatusInternalServerError
+}
+
+// isRetryable returns true if the upload request failed with a transient error
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var sErr *statusError
+	if errors.As(err, &sErr) {
+		return sErr.retryable()
+	}
+	// any other error happened while sending the request, such as a connection reset
+	return true
+}
+
+// uploader uploads content to a URL, retrying failed requests with backoff.
+// When a chunk size is set, content is uploaded in chunks with a Content-Range header,
+// and interrupted uploads are resumed from the last chunk the server received.
+type uploader struct {
+	client      *http.Client
+	backoff     backoff.Backoff
+	method      string
+	headers     http.Header
+	chunkSize   int64
+	maxRetries  int
+	contentType string
+}
+
+// upload uploads size bytes of content to url
+func (u *uploader) upload(ctx context.Context, url string, content io.ReaderAt, size int64) error {
+	if u.chunkSize <= 0 || size <= u.chunkSize {
+		return u.withRetries(ctx, func() error {
+			return u.send(ctx, url, io.NewSectionReader(content, 0, size), size, "")
+		})
+	}
+	return u.uploadChunks(ctx, url, content, size)
+}
+
+func (u *uploader) withRetries(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= u.maxRetries; attempt++ {
+		u.backoff.Backoff(ctx, attempt)
+		if err = fn(); err == nil || !isRetryable(ctx, err) {
+			return err
+		}
+		log.Ctx(ctx).Debug().Err(err).Int("attempt", attempt+1).Msg("Upload request failed")
+	}
+	return err
+}
+
+func (u *uploader) uploadChunks(ctx context.Context, url string, content io.ReaderAt, size int64) error {
+	var offset int64
+	attempt := 0
+	for offset < size {
+		end := min(offset+u.chunkSize, size)
+		contentRange := fmt.Sprintf("bytes %d-%d/%d", offset, end-1, size)
+		err := u.send(ctx, url, io.NewSectionReader(content, offset, end-offset), end-offset, contentRange)
+		if err == nil {
+			offset = end
+			attempt = 0
+			continue
+		}
+		if !isRetryable(ctx, err) || attempt >= u.maxRetries {
+			return err
+		}
+		attempt++
+		log.Ctx(ctx).Debug().Err(err).Int("attempt", attempt).Msgf("Upload of %s failed", contentRange)
+		u.backoff.Backoff(ctx, attempt)
+
+		// ask the server how much it received, and resend the failed chunk if it can't tell
+		received, complete, statusErr := u.status(ctx, url, size)
+		if statusErr != nil {
+			log.Ctx(ctx).Debug().Err(statusErr).Msg("Failed to query upload status")
+			continue
+		}
+		if complete {
+			return nil
+		}
+		offset = received
+	}
+	return nil
+}
+
+// send uploads a single request. Partial chunks may be acknowledged with 308 Resume Incomplete.
+func (u *uploader) send(ctx context.Context, url string, body io.Reader, size int64, contentRange string) error {
+	req, err := u.newRequest(ctx, url, body, size)
+	if err != nil {
+		return err
+	}
+	if contentRange != "" {
+		req.Header.Set("Content-Range", contentRange)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "http response", resp.Body)
+
+	if isSuccess(resp.StatusCode) || (contentRange != "" && resp.StatusCode == statusResumeIncomplete) {
+		return nil
+	}
+	return newStatusError(url, resp)
+}
+
+// status queries how many bytes of an interrupted upload the server received.
+// complete is true if the server already has the whole content.
+func (u *uploader) status(ctx context.Context, url string, size int64) (received int64, complete bool, err error) {
+	req, err := u.newRequest(ctx, url, http.NoBody, 0)
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "http response", resp.Body)
+
+	switch {
+	case isSuccess(resp.StatusCode):
+		return size, true, nil
+	case resp.StatusCode == statusResumeIncomplete:
+		rangeHeader := resp.Header.Get("Range")
+		if rangeHeader == "" {
+			// nothing was received yet
+			return 0, false, nil
+		}
+		matches := rangePattern.FindStringSubmatch(rangeHeader)
+		if matches == nil {
+			return 0, false, fmt.Errorf("invalid range header in upload status response: %q", rangeHeader)
+		}
+		last, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil || last >= size {
+			return 0, false, fmt.Errorf("invalid range header in upload status response: %q", rangeHeader)
+		}
+		return last + 1, false, nil
+	default:
+		return 0, false, newStatusError(url, resp)
+	}
+}
+
+func (u *uploader) newRequest(ctx context.Context, url string, body io.Reader, size int64) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, u.method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	for name, values := range u.headers {
+		req.Header[name] = values
+	}
+	if u.contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", u.contentType)
+	}
+	return req, nil
+}
+
+func isSuccess(statusCode int) bool {
+	return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+}
+
+func newStatusError(url string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	return &statusError{url: url, statusCode: resp.StatusCode, body: string(body)}
+}
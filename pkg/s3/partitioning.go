@@ -1,9 +1,17 @@
 package s3
 
 import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"hash/fnv"
+	"io"
+	"path"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -28,6 +36,14 @@ const (
 	PartitionKeyTypeRegex     PartitionKeyType = "regex"
 	PartitionKeyTypeSubstring PartitionKeyType = "substring"
 	PartitionKeyTypeDate      PartitionKeyType = "date"
+	PartitionKeyTypeSize      PartitionKeyType = "size"
+	PartitionKeyTypeRange     PartitionKeyType = "range"
+	PartitionKeyTypeManifest  PartitionKeyType = "manifest"
+)
+
+const (
+	manifestExtensionCSV  = ".csv"
+	manifestExtensionJSON = ".json"
 )
 
 // PartitionConfig defines how to generate partition keys from object paths
@@ -43,13 +59,18 @@ type PartitionConfig struct {
 
 	// For date partitioning
 	DateFormat string
+
+	// For manifest partitioning, the key of a CSV or JSON object in the bucket
+	// that assigns object keys to partitions
+	ManifestKey string
 }
 
 func (c *PartitionConfig) Validate() error {
 	// First validate the partition type itself
 	switch c.Type {
 	case PartitionKeyTypeNone, PartitionKeyTypeObject, PartitionKeyTypeRegex,
-		PartitionKeyTypeSubstring, PartitionKeyTypeDate:
+		PartitionKeyTypeSubstring, PartitionKeyTypeDate, PartitionKeyTypeSize,
+		PartitionKeyTypeRange, PartitionKeyTypeManifest:
 		// Valid types
 	default:
 		if c.Type != "" {
@@ -83,6 +104,20 @@ func (c *PartitionConfig) Validate() error {
 		if err := validateDateFormat(c.DateFormat); err != nil {
 			return err
 		}
+
+	case PartitionKeyTypeManifest:
+		if c.ManifestKey == "" {
+			return NewS3InputSourceError(BadRequestErrorCode, "manifest key cannot be empty")
+		}
+		if strings.HasSuffix(c.ManifestKey, "/") || strings.Contains(c.ManifestKey, "*") {
+			return NewS3InputSourceError(BadRequestErrorCode, "manifest key must be the key of a single object")
+		}
+		switch path.Ext(c.ManifestKey) {
+		case manifestExtensionCSV, manifestExtensionJSON:
+		default:
+			return NewS3InputSourceError(BadRequestErrorCode,
+				fmt.Sprintf("manifest key must have a %s or %s extension", manifestExtensionCSV, manifestExtensionJSON))
+		}
 	}
 	return nil
 }
@@ -105,12 +140,28 @@ func validateDateFormat(layout string) error {
 	return nil
 }
 
-// PartitionObjects applies the configured partitioning strategy to a slice of objects
+// PartitionAssignments maps object keys to the index of their partition, as read from a partition manifest
+type PartitionAssignments map[string]int
+
+// PartitionObjects applies the configured partitioning strategy to a slice of objects.
+// Manifest partitioning requires the assignments of the manifest, see PartitionObjectsWithAssignments.
 func PartitionObjects(
 	objects []ObjectSummary,
 	totalPartitions int,
 	partitionIndex int,
 	source SourceSpec,
+) ([]ObjectSummary, error) {
+	return PartitionObjectsWithAssignments(objects, totalPartitions, partitionIndex, source, nil)
+}
+
+// PartitionObjectsWithAssignments applies the configured partitioning strategy to a slice of objects,
+// using the assignments read from the partition manifest for manifest partitioning.
+func PartitionObjectsWithAssignments(
+	objects []ObjectSummary,
+	totalPartitions int,
+	partitionIndex int,
+	source SourceSpec,
+	assignments PartitionAssignments,
 ) ([]ObjectSummary, error) {
 	if err := source.Partition.Validate(); err != nil {
 		return nil, err
@@ -126,6 +177,11 @@ func PartitionObjects(
 	// filter out directories
 	objects = filterDirectories(objects)
 
+	// the partition manifest is not an input of any partition, including the only one
+	if source.Partition.Type == PartitionKeyTypeManifest {
+		objects = filterObject(objects, source.Partition.ManifestKey)
+	}
+
 	// If there is only 1 partition, just return the entire set
 	if totalPartitions == 1 {
 		return objects, nil
@@ -152,6 +208,12 @@ func PartitionObjects(
 	case PartitionKeyTypeDate:
 		return partitionByDate(
 			objects, totalPartitions, partitionIndex, prefix, source.Partition.DateFormat)
+	case PartitionKeyTypeSize:
+		return partitionBySize(objects, totalPartitions, partitionIndex)
+	case PartitionKeyTypeRange:
+		return partitionByRange(objects, totalPartitions, partitionIndex)
+	case PartitionKeyTypeManifest:
+		return partitionByManifest(objects, totalPartitions, partitionIndex, assignments)
 	default:
 		return nil, NewS3InputSourceError(BadRequestErrorCode, fmt.Sprintf("unsupported partition key type: %s", source.Partition.Type))
 	}
@@ -266,6 +328,119 @@ func partitionByDate(
 	return result, nil
 }
 
+// partitionBySize bin-packs objects into partitions of balanced total sizes. Objects are assigned
+// from the largest to the smallest to the partition with the smallest total size so far, with ties
+// broken by key and by partition index so that every partition computes the same assignment.
+func partitionBySize(objects []ObjectSummary, totalPartitions, partitionIndex int) ([]ObjectSummary, error) {
+	sorted := slices.SortedStableFunc(slices.Values(objects), func(a, b ObjectSummary) int {
+		if c := cmp.Compare(b.Size, a.Size); c != 0 {
+			return c
+		}
+		return strings.Compare(*a.Key, *b.Key)
+	})
+
+	sizes := make([]int64, totalPartitions)
+	var result []ObjectSummary
+	for _, obj := range sorted {
+		pIndex := 0
+		for i := range sizes {
+			if sizes[i] < sizes[pIndex] {
+				pIndex = i
+			}
+		}
+		sizes[pIndex] += obj.Size
+		if pIndex == partitionIndex {
+			result = append(result, obj)
+		}
+	}
+	return result, nil
+}
+
+// partitionByRange splits the objects sorted by key into contiguous ranges of the same number
+// of objects, with the first partitions holding one more object when they cannot be equal.
+func partitionByRange(objects []ObjectSummary, totalPartitions, partitionIndex int) ([]ObjectSummary, error) {
+	sorted := slices.SortedStableFunc(slices.Values(objects), func(a, b ObjectSummary) int {
+		return strings.Compare(*a.Key, *b.Key)
+	})
+
+	size, remainder := len(sorted)/totalPartitions, len(sorted)%totalPartitions
+	start := partitionIndex*size + min(partitionIndex, remainder)
+	end := start + size
+	if partitionIndex < remainder {
+		end++
+	}
+	if start == end {
+		return nil, nil
+	}
+	return sorted[start:end], nil
+}
+
+// partitionByManifest partitions objects as assigned by the partition manifest. Objects missing from
+// the manifest are assigned to partition 0.
+func partitionByManifest(
+	objects []ObjectSummary, totalPartitions, partitionIndex int, assignments PartitionAssignments) (
+	[]ObjectSummary, error) {
+	if assignments == nil {
+		return nil, NewS3InputSourceError(BadRequestErrorCode, "partition manifest has not been read")
+	}
+	for key, pIndex := range assignments {
+		if pIndex < 0 || pIndex >= totalPartitions {
+			return nil, NewS3InputSourceError(BadRequestErrorCode, fmt.Sprintf(
+				"partition manifest assigns %s to partition %d, which is not between 0 and %d", key, pIndex, totalPartitions-1))
+		}
+	}
+
+	var result []ObjectSummary
+	for _, obj := range objects {
+		pIndex, ok := assignments[*obj.Key]
+		if !ok {
+			pIndex = fallbackPartitionIndex
+		}
+		if pIndex == partitionIndex {
+			result = append(result, obj)
+		}
+	}
+	return result, nil
+}
+
+// ParsePartitionManifest parses a partition manifest, which assigns full object keys to partition indices.
+// CSV manifests have a key and a partition index per row, with an optional "key,partition" header.
+// JSON manifests are an object mapping keys to partition indices.
+func ParsePartitionManifest(manifestKey string, r io.Reader) (PartitionAssignments, error) {
+	assignments := make(PartitionAssignments)
+	switch path.Ext(manifestKey) {
+	case manifestExtensionJSON:
+		if err := json.NewDecoder(r).Decode(&assignments); err != nil {
+			return nil, NewS3InputSourceError(BadRequestErrorCode, fmt.Sprintf("invalid partition manifest %s: %s", manifestKey, err))
+		}
+	case manifestExtensionCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = 2
+		reader.TrimLeadingSpace = true
+		for row := 1; ; row++ {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, NewS3InputSourceError(BadRequestErrorCode, fmt.Sprintf("invalid partition manifest %s: %s", manifestKey, err))
+			}
+			if row == 1 && record[0] == "key" && record[1] == "partition" {
+				continue
+			}
+			pIndex, err := strconv.Atoi(strings.TrimSpace(record[1]))
+			if err != nil {
+				return nil, NewS3InputSourceError(BadRequestErrorCode,
+					fmt.Sprintf("invalid partition index %q at row %d of partition manifest %s", record[1], row, manifestKey))
+			}
+			assignments[record[0]] = pIndex
+		}
+	default:
+		return nil, NewS3InputSourceError(BadRequestErrorCode, fmt.Sprintf("unsupported partition manifest format %s", manifestKey))
+	}
+	return assignments, nil
+}
+
 // sanitizeKeyForPatternMatching returns the relative path after the prefix
 func sanitizeKeyForPatternMatching(objectKey string, prefix string) string {
 	key := strings.TrimPrefix(objectKey, prefix)
@@ -298,3 +473,14 @@ func filterDirectories(objects []ObjectSummary) []ObjectSummary {
 	}
 	return result
 }
+
+// filterObject removes the object with the key from the objects
+func filterObject(objects []ObjectSummary, key string) []ObjectSummary {
+	var result []ObjectSummary
+	for _, obj := range objects {
+		if *obj.Key != key {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
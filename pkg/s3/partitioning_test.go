@@ -3,6 +3,7 @@ package s3
 /* spell-checker: disable */
 
 import (
+	"slices"
 	"strings"
 	"testing"
 
@@ -142,6 +143,57 @@ func (s *PartitionTestSuite) TestConfigValidation() {
 			expectedErr: "date format cannot be empty",
 		},
 
+		// Size, range and manifest partitioning
+		{
+			name: "valid size",
+			config: PartitionConfig{
+				Type: PartitionKeyTypeSize,
+			},
+		},
+		{
+			name: "valid range",
+			config: PartitionConfig{
+				Type: PartitionKeyTypeRange,
+			},
+		},
+		{
+			name: "valid manifest - csv",
+			config: PartitionConfig{
+				Type:        PartitionKeyTypeManifest,
+				ManifestKey: "manifests/partitions.csv",
+			},
+		},
+		{
+			name: "valid manifest - json",
+			config: PartitionConfig{
+				Type:        PartitionKeyTypeManifest,
+				ManifestKey: "partitions.json",
+			},
+		},
+		{
+			name: "empty manifest key",
+			config: PartitionConfig{
+				Type: PartitionKeyTypeManifest,
+			},
+			expectedErr: "manifest key cannot be empty",
+		},
+		{
+			name: "manifest key with wildcard",
+			config: PartitionConfig{
+				Type:        PartitionKeyTypeManifest,
+				ManifestKey: "manifests/*.csv",
+			},
+			expectedErr: "manifest key must be the key of a single object",
+		},
+		{
+			name: "manifest key with unsupported extension",
+			config: PartitionConfig{
+				Type:        PartitionKeyTypeManifest,
+				ManifestKey: "partitions.txt",
+			},
+			expectedErr: "manifest key must have a .csv or .json extension",
+		},
+
 		{
 			name: "empty partition type",
 			config: PartitionConfig{
@@ -1170,6 +1222,166 @@ func (s *PartitionTestSuite) TestEdgeCases() {
 	}
 }
 
+func (s *PartitionTestSuite) TestPartitionBySize() {
+	objects := []ObjectSummary{
+		createSizedObjectSummary("data/a.csv", 100),
+		createSizedObjectSummary("data/b.csv", 60),
+		createSizedObjectSummary("data/c.csv", 50),
+		createSizedObjectSummary("data/d.csv", 40),
+		createSizedObjectSummary("data/e.csv", 10),
+		createSizedObjectSummary("data/f.csv", 10),
+		createObjectSummary("data/", true),
+	}
+	spec := SourceSpec{Key: "data/", Partition: PartitionConfig{Type: PartitionKeyTypeSize}}
+
+	// objects are assigned from the largest to the smallest to the partition with the smallest total size
+	s.verifyPartitioning(spec, objects, 2, [][]string{
+		{"data/a.csv", "data/d.csv"},
+		{"data/b.csv", "data/c.csv", "data/e.csv", "data/f.csv"},
+	})
+	s.verifyPartitioning(spec, objects, 3, [][]string{
+		{"data/a.csv"},
+		{"data/b.csv", "data/e.csv", "data/f.csv"},
+		{"data/c.csv", "data/d.csv"},
+	})
+
+	// the assignment does not depend on the listing order
+	reversed := slices.Clone(objects)
+	slices.Reverse(reversed)
+	for i := 0; i < 3; i++ {
+		expected, err := PartitionObjects(objects, 3, i, spec)
+		s.Require().NoError(err)
+		actual, err := PartitionObjects(reversed, 3, i, spec)
+		s.Require().NoError(err)
+		s.Equal(expected, actual)
+	}
+}
+
+func (s *PartitionTestSuite) TestPartitionByRange() {
+	spec := SourceSpec{Key: "logs/", Partition: PartitionConfig{Type: PartitionKeyTypeRange}}
+	objects := createObjectsFromStrings([]string{
+		"logs/e.log", "logs/a.log", "logs/d.log", "logs/", "logs/c.log", "logs/b.log",
+	})
+
+	s.verifyPartitioning(spec, objects, 2, [][]string{
+		{"logs/a.log", "logs/b.log", "logs/c.log"},
+		{"logs/d.log", "logs/e.log"},
+	})
+	s.verifyPartitioning(spec, objects, 4, [][]string{
+		{"logs/a.log", "logs/b.log"},
+		{"logs/c.log"},
+		{"logs/d.log"},
+		{"logs/e.log"},
+	})
+	s.verifyPartitioning(spec, objects, 7, [][]string{
+		{"logs/a.log"}, {"logs/b.log"}, {"logs/c.log"}, {"logs/d.log"}, {"logs/e.log"}, {}, {},
+	})
+
+	// partitions keep the key order
+	partition, err := PartitionObjects(objects, 2, 0, spec)
+	s.Require().NoError(err)
+	s.Equal("logs/a.log", *partition[0].Key)
+	s.Equal("logs/c.log", *partition[2].Key)
+}
+
+func (s *PartitionTestSuite) TestPartitionByManifest() {
+	spec := SourceSpec{
+		Key:       "data/",
+		Partition: PartitionConfig{Type: PartitionKeyTypeManifest, ManifestKey: "data/partitions.csv"},
+	}
+	objects := createObjectsFromStrings([]string{
+		"data/a.csv", "data/b.csv", "data/c.csv", "data/unlisted.csv", "data/partitions.csv", "data/",
+	})
+	assignments, err := ParsePartitionManifest(spec.Partition.ManifestKey, strings.NewReader(
+		"key,partition\ndata/a.csv,1\ndata/b.csv,2\ndata/c.csv, 1\n"))
+	s.Require().NoError(err)
+
+	partitions := make([][]ObjectSummary, 3)
+	for i := range partitions {
+		partitions[i], err = PartitionObjectsWithAssignments(objects, 3, i, spec, assignments)
+		s.Require().NoError(err)
+	}
+	// unlisted objects fall back to partition 0, and the manifest is not part of any partition
+	s.verifyPartitionContents(partitions, [][]string{
+		{"data/unlisted.csv"},
+		{"data/a.csv", "data/c.csv"},
+		{"data/b.csv"},
+	})
+
+	_, err = PartitionObjectsWithAssignments(objects, 2, 0, spec, assignments)
+	s.ErrorContains(err, "assigns data/b.csv to partition 2")
+
+	_, err = PartitionObjects(objects, 3, 0, spec)
+	s.ErrorContains(err, "partition manifest has not been read")
+
+	// a single partition holds every object but the manifest, which is not read
+	partition, err := PartitionObjects(objects, 1, 0, spec)
+	s.Require().NoError(err)
+	s.verifyPartitionContents([][]ObjectSummary{partition}, [][]string{
+		{"data/a.csv", "data/b.csv", "data/c.csv", "data/unlisted.csv"},
+	})
+}
+
+func (s *PartitionTestSuite) TestParsePartitionManifest() {
+	tests := []struct {
+		name        string
+		manifestKey string
+		content     string
+		expected    PartitionAssignments
+		expectedErr string
+	}{
+		{
+			name:        "csv without header",
+			manifestKey: "partitions.csv",
+			content:     "a.csv,0\nb.csv,1\n",
+			expected:    PartitionAssignments{"a.csv": 0, "b.csv": 1},
+		},
+		{
+			name:        "csv with header",
+			manifestKey: "partitions.csv",
+			content:     "key,partition\na.csv,0\n",
+			expected:    PartitionAssignments{"a.csv": 0},
+		},
+		{
+			name:        "csv with invalid partition index",
+			manifestKey: "partitions.csv",
+			content:     "a.csv,first\n",
+			expectedErr: "invalid partition index \"first\" at row 1",
+		},
+		{
+			name:        "csv with missing column",
+			manifestKey: "partitions.csv",
+			content:     "a.csv\n",
+			expectedErr: "invalid partition manifest",
+		},
+		{
+			name:        "json",
+			manifestKey: "partitions.json",
+			content:     `{"a.csv": 0, "b.csv": 1}`,
+			expected:    PartitionAssignments{"a.csv": 0, "b.csv": 1},
+		},
+		{
+			name:        "invalid json",
+			manifestKey: "partitions.json",
+			content:     `["a.csv"]`,
+			expectedErr: "invalid partition manifest",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			assignments, err := ParsePartitionManifest(tt.manifestKey, strings.NewReader(tt.content))
+			if tt.expectedErr != "" {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tt.expectedErr)
+			} else {
+				s.Require().NoError(err)
+				s.Equal(tt.expected, assignments)
+			}
+		})
+	}
+}
+
 func createObjectSummary(key string, isDir bool) ObjectSummary {
 	return ObjectSummary{
 		Key:   &key,
@@ -1177,6 +1389,13 @@ func createObjectSummary(key string, isDir bool) ObjectSummary {
 	}
 }
 
+func createSizedObjectSummary(key string, size int64) ObjectSummary {
+	return ObjectSummary{
+		Key:  &key,
+		Size: size,
+	}
+}
+
 // createObjectsFromStrings creates ObjectSummary slices from strings, treating paths with / suffix as directories
 func createObjectsFromStrings(paths []string) []ObjectSummary {
 	objects := make([]ObjectSummary, len(paths))
@@ -0,0 +1,306 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // G501: S3 ETags are MD5 digests
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	s3helper "github.com/bacalhau-project/bacalhau/pkg/s3"
+)
+
+const FakeRegion = "us-east-1"
+
+// FakeS3 is an in-memory stand-in for an S3-compatible service, serving path-style requests to any bucket.
+// It supports the object and multipart upload operations used to publish results, and can inject
+// failures of part uploads.
+type FakeS3 struct {
+	server *httptest.Server
+
+	mu             sync.Mutex
+	objects        map[string][]byte
+	uploads        map[string]*fakeUpload
+	nextUploadID   int
+	partRequests   int
+	failParts      int
+	onUploadPart   func(partNumber int32)
+	putObjectCalls int
+}
+
+type fakeUpload struct {
+	key   string
+	parts map[int32][]byte
+}
+
+// NewFakeS3 starts a fake S3 server that is stopped when the test ends
+func NewFakeS3(t *testing.T) *FakeS3 {
+	f := &FakeS3{
+		objects: make(map[string][]byte),
+		uploads: make(map[string]*fakeUpload),
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// Endpoint returns the endpoint of the fake server
+func (f *FakeS3) Endpoint() string {
+	return f.server.URL
+}
+
+// ClientProvider returns a client provider with static credentials, whose clients target the fake
+// server when created with its endpoint
+func (f *FakeS3) ClientProvider() *s3helper.ClientProvider {
+	return s3helper.NewClientProvider(s3helper.ClientProviderParams{
+		AWSConfig: aws.Config{
+			Region: FakeRegion,
+			Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: "fake", SecretAccessKey: "fake"}, nil
+			}),
+			RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		},
+	})
+}
+
+// Object returns the content of an object, and whether it exists
+func (f *FakeS3) Object(bucket, key string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[bucket+"/"+key]
+	return data, ok
+}
+
+// Keys returns the sorted keys of the objects in a bucket
+func (f *FakeS3) Keys(bucket string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for name := range f.objects {
+		if key, ok := strings.CutPrefix(name, bucket+"/"); ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// PendingUploads returns the number of multipart uploads that were neither completed nor aborted
+func (f *FakeS3) PendingUploads() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.uploads)
+}
+
+// PartRequests returns the number of part upload requests received, including failed ones
+func (f *FakeS3) PartRequests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.partRequests
+}
+
+// PutObjectCalls returns the number of objects uploaded with a single request
+func (f *FakeS3) PutObjectCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.putObjectCalls
+}
+
+// FailUploadParts makes the next n part uploads fail with an internal error
+func (f *FakeS3) FailUploadParts(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failParts = n
+}
+
+// OnUploadPart sets a hook called when a part upload is received, before it is stored
+func (f *FakeS3) OnUploadPart(hook func(partNumber int32)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onUploadPart = hook
+}
+
+func (f *FakeS3) handle(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.createMultipartUpload(w, bucket, key)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		f.uploadPart(w, r, query.Get("uploadId"), query.Get("partNumber"))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		f.completeMultipartUpload(w, r, bucket, key, query.Get("uploadId"))
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		f.abortMultipartUpload(w, query.Get("uploadId"))
+	case r.Method == http.MethodPut:
+		f.putObject(w, r, bucket, key)
+	case r.Method == http.MethodGet:
+		f.getObject(w, bucket, key)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented", r.Method+" is not supported")
+	}
+}
+
+func (f *FakeS3) putObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+	f.mu.Lock()
+	f.objects[bucket+"/"+key] = data
+	f.putObjectCalls++
+	f.mu.Unlock()
+	w.Header().Set("ETag", etag(data))
+}
+
+func (f *FakeS3) getObject(w http.ResponseWriter, bucket, key string) {
+	data, ok := f.Object(bucket, key)
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("ETag", etag(data))
+	_, _ = w.Write(data)
+}
+
+func (f *FakeS3) createMultipartUpload(w http.ResponseWriter, bucket, key string) {
+	f.mu.Lock()
+	f.nextUploadID++
+	uploadID := fmt.Sprintf("upload-%d", f.nextUploadID)
+	f.uploads[uploadID] = &fakeUpload{key: bucket + "/" + key, parts: make(map[int32][]byte)}
+	f.mu.Unlock()
+
+	writeXML(w, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Bucket   string
+		Key      string
+		UploadID string `xml:"UploadId"`
+	}{Bucket: bucket, Key: key, UploadID: uploadID})
+}
+
+func (f *FakeS3) uploadPart(w http.ResponseWriter, r *http.Request, uploadID, partNumberParam string) {
+	partNumber, err := strconv.ParseInt(partNumberParam, 10, 32)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", "invalid part number")
+		return
+	}
+
+	f.mu.Lock()
+	f.partRequests++
+	hook := f.onUploadPart
+	fail := f.failParts > 0
+	if fail {
+		f.failParts--
+	}
+	f.mu.Unlock()
+
+	if hook != nil {
+		hook(int32(partNumber))
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+	if fail {
+		writeError(w, http.StatusInternalServerError, "InternalError", "injected part upload failure")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	upload, ok := f.uploads[uploadID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+	upload.parts[int32(partNumber)] = data
+	w.Header().Set("ETag", etag(data))
+}
+
+func (f *FakeS3) completeMultipartUpload(w http.ResponseWriter, r *http.Request, bucket, key, uploadID string) {
+	var request struct {
+		Parts []struct {
+			PartNumber int32
+			ETag       string
+		} `xml:"Part"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	upload, ok := f.uploads[uploadID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+	var data bytes.Buffer
+	for i, part := range request.Parts {
+		content, ok := upload.parts[part.PartNumber]
+		if !ok || etag(content) != part.ETag {
+			writeError(w, http.StatusBadRequest, "InvalidPart", fmt.Sprintf("part %d was not uploaded", part.PartNumber))
+			return
+		}
+		if i > 0 && part.PartNumber <= request.Parts[i-1].PartNumber {
+			writeError(w, http.StatusBadRequest, "InvalidPartOrder", "parts must be in ascending order")
+			return
+		}
+		data.Write(content)
+	}
+	f.objects[upload.key] = data.Bytes()
+	delete(f.uploads, uploadID)
+
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Bucket  string
+		Key     string
+		ETag    string
+	}{Bucket: bucket, Key: key, ETag: etag(data.Bytes())})
+}
+
+func (f *FakeS3) abortMultipartUpload(w http.ResponseWriter, uploadID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.uploads[uploadID]; !ok {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+	delete(f.uploads, uploadID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data) //nolint:gosec // G401: S3 ETags are MD5 digests
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func writeXML(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+		Message string
+	}{Code: code, Message: message})
+}
@@ -0,0 +1,129 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/dustin/go-humanize"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/config/types"
+)
+
+const (
+	// DefaultUploadPartSize is the default size of the parts of multipart uploads
+	DefaultUploadPartSize = 16 * 1024 * 1024
+	// DefaultUploadConcurrency is the default number of parts uploaded in parallel
+	DefaultUploadConcurrency = 4
+	// DefaultUploadMaxRetries is the default number of times a failed part is retried
+	DefaultUploadMaxRetries = 5
+
+	// MinUploadPartSize is the smallest part size accepted by S3, except for the last part
+	MinUploadPartSize = manager.MinUploadPartSize
+	// MaxUploadParts is the maximum number of parts of a multipart upload
+	MaxUploadParts = manager.MaxUploadParts
+
+	abortUploadTimeout = 30 * time.Second
+)
+
+// UploadParams configures multipart uploads
+type UploadParams struct {
+	// PartSize is the size of the parts of multipart uploads
+	PartSize int64
+	// Concurrency is the number of parts, or files, uploaded in parallel
+	Concurrency int
+	// MaxRetries is the number of times a failed part is retried
+	MaxRetries int
+}
+
+// DefaultUploadParams returns the default upload params
+func DefaultUploadParams() UploadParams {
+	return UploadParams{
+		PartSize:    DefaultUploadPartSize,
+		Concurrency: DefaultUploadConcurrency,
+		MaxRetries:  DefaultUploadMaxRetries,
+	}
+}
+
+// NewUploadParams returns the upload params of the config, with defaults for unset values
+func NewUploadParams(cfg types.S3Upload) (UploadParams, error) {
+	params := DefaultUploadParams()
+	if cfg.PartSize != "" {
+		partSize, err := humanize.ParseBytes(cfg.PartSize)
+		if err != nil {
+			return UploadParams{}, fmt.Errorf("invalid S3 upload part size %q: %w", cfg.PartSize, err)
+		}
+		if partSize < uint64(MinUploadPartSize) {
+			return UploadParams{}, fmt.Errorf("S3 upload part size %q is smaller than the minimum of 5Mi", cfg.PartSize)
+		}
+		params.PartSize = int64(partSize) //nolint:gosec // G115: part sizes are far below the int64 limit
+	}
+	if cfg.Concurrency < 0 {
+		return UploadParams{}, fmt.Errorf("S3 upload concurrency cannot be negative")
+	}
+	if cfg.Concurrency > 0 {
+		params.Concurrency = cfg.Concurrency
+	}
+	if cfg.MaxRetries < 0 {
+		return UploadParams{}, fmt.Errorf("S3 upload max retries cannot be negative")
+	}
+	if cfg.MaxRetries > 0 {
+		params.MaxRetries = cfg.MaxRetries
+	}
+	return params, nil
+}
+
+// WithDefaults returns the params with defaults for unset values
+func (p UploadParams) WithDefaults() UploadParams {
+	defaults := DefaultUploadParams()
+	if p.PartSize <= 0 {
+		p.PartSize = defaults.PartSize
+	}
+	if p.Concurrency <= 0 {
+		p.Concurrency = defaults.Concurrency
+	}
+	if p.MaxRetries <= 0 {
+		p.MaxRetries = defaults.MaxRetries
+	}
+	return p
+}
+
+// ConfigureUploader returns an option of the upload manager that applies the params.
+// Parts are retried by the S3 client, and incomplete uploads are left to AbortFailedUpload,
+// as the upload manager aborts them with the upload context, which fails once cancelled.
+func (p UploadParams) ConfigureUploader() func(*manager.Uploader) {
+	return func(u *manager.Uploader) {
+		u.PartSize = p.PartSize
+		u.Concurrency = p.Concurrency
+		u.LeavePartsOnError = true
+		u.ClientOptions = append(u.ClientOptions, func(o *s3.Options) {
+			o.RetryMaxAttempts = p.MaxRetries + 1
+		})
+	}
+}
+
+// AbortFailedUpload aborts the multipart upload that failed with err, if any, so that its parts
+// are not left in the bucket. The upload is aborted even if ctx was cancelled.
+func AbortFailedUpload(ctx context.Context, client *ClientWrapper, bucket, key string, err error) {
+	var failure manager.MultiUploadFailure
+	if !errors.As(err, &failure) {
+		return
+	}
+	abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortUploadTimeout)
+	defer cancel()
+	_, abortErr := client.S3.AbortMultipartUpload(abortCtx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(failure.UploadID()),
+	})
+	if abortErr != nil {
+		log.Ctx(ctx).Warn().Err(abortErr).
+			Str("UploadID", failure.UploadID()).
+			Msgf("Failed to abort multipart upload to s3://%s/%s", bucket, key)
+	}
+}
@@ -0,0 +1,75 @@
+//go:build unit || !integration
+
+package s3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/config/types"
+)
+
+type UploadParamsTestSuite struct {
+	suite.Suite
+}
+
+func TestUploadParamsTestSuite(t *testing.T) {
+	suite.Run(t, new(UploadParamsTestSuite))
+}
+
+func (s *UploadParamsTestSuite) TestNewUploadParams() {
+	for _, tc := range []struct {
+		name     string
+		config   types.S3Upload
+		expected UploadParams
+		invalid  bool
+	}{
+		{
+			name:     "defaults",
+			expected: DefaultUploadParams(),
+		},
+		{
+			name:     "configured",
+			config:   types.S3Upload{PartSize: "64Mi", Concurrency: 8, MaxRetries: 2},
+			expected: UploadParams{PartSize: 64 * 1024 * 1024, Concurrency: 8, MaxRetries: 2},
+		},
+		{
+			name:    "part size below minimum",
+			config:  types.S3Upload{PartSize: "1Mi"},
+			invalid: true,
+		},
+		{
+			name:    "invalid part size",
+			config:  types.S3Upload{PartSize: "large"},
+			invalid: true,
+		},
+		{
+			name:    "negative concurrency",
+			config:  types.S3Upload{Concurrency: -1},
+			invalid: true,
+		},
+		{
+			name:    "negative max retries",
+			config:  types.S3Upload{MaxRetries: -1},
+			invalid: true,
+		},
+	} {
+		s.Run(tc.name, func() {
+			params, err := NewUploadParams(tc.config)
+			if tc.invalid {
+				s.Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(tc.expected, params)
+		})
+	}
+}
+
+func (s *UploadParamsTestSuite) TestWithDefaults() {
+	s.Equal(DefaultUploadParams(), UploadParams{}.WithDefaults())
+	s.Equal(
+		UploadParams{PartSize: MinUploadPartSize, Concurrency: DefaultUploadConcurrency, MaxRetries: DefaultUploadMaxRetries},
+		UploadParams{PartSize: MinUploadPartSize}.WithDefaults())
+}
@@ -0,0 +1,135 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/blobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+)
+
+/*
+Storage provider that fetches inputs uploaded by clients to the blob store of the orchestrator, such as
+local directories passed to `bacalhau job run --input ./dir:/inputs`. Inputs are gzipped tar archives
+referenced by the digest of the archive, which is verified when they are fetched, and are extracted into
+the input volume.
+*/
+
+// DetailDigest is the volume detail recording the digest of the fetched input
+const DetailDigest = "Digest"
+
+type StorageProviderParams struct {
+	// Store is the blob store of the orchestrator
+	Store blobstore.Store
+}
+
+type StorageProvider struct {
+	store blobstore.Store
+}
+
+func NewStorage(params StorageProviderParams) *StorageProvider {
+	return &StorageProvider{store: params.Store}
+}
+
+// IsInstalled returns true, as blobs are fetched through the connection to the orchestrator
+func (s *StorageProvider) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+// HasStorageLocally returns false, as blobs are always fetched from the orchestrator
+func (s *StorageProvider) HasStorageLocally(context.Context, models.InputSource) (bool, error) {
+	return false, nil
+}
+
+// GetVolumeSize returns the uncompressed size of the input, as this is how much disk the extracted input takes up
+func (s *StorageProvider) GetVolumeSize(_ context.Context, _ *models.Execution, input models.InputSource) (uint64, error) {
+	source, err := DecodeSpec(input.Source)
+	if err != nil {
+		return 0, err
+	}
+	return source.Size, nil
+}
+
+// PrepareStorage downloads the archive of the input, verifies its digest and extracts it into a
+// temporary directory
+func (s *StorageProvider) PrepareStorage(
+	ctx context.Context,
+	storageDirectory string,
+	_ *models.Execution,
+	input models.InputSource) (storage.StorageVolume, error) {
+	source, err := DecodeSpec(input.Source)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	archive, err := os.CreateTemp(storageDirectory, "blob-*.tar.gz")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	defer func() { _ = os.Remove(archive.Name()) }()
+
+	hash := sha256.New()
+	_, err = s.store.Get(ctx, source.Digest, io.MultiWriter(archive, hash))
+	err = errors.Join(err, archive.Close())
+	if err != nil {
+		return storage.StorageVolume{}, fmt.Errorf("failed to fetch blob %s: %w", source.Digest, err)
+	}
+	if actual := blobstore.FormatDigest(hash.Sum(nil)); actual != source.Digest {
+		return storage.StorageVolume{}, newValidationError(
+			fmt.Sprintf("fetched blob has digest %s instead of %s", actual, source.Digest))
+	}
+
+	dir, err := os.MkdirTemp(storageDirectory, "blob-storage")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	// the extracted files cannot take up more disk than was reserved for the input
+	maxSize := int64(min(source.Size, math.MaxInt64)) //nolint:gosec // G115: bounded by math.MaxInt64
+	if err = gzip.DecompressWithMaxTotalBytes(archive.Name(), dir, maxSize); err != nil {
+		if errors.Is(err, gzip.ErrMaxSizeExceeded) {
+			err = newValidationError(fmt.Sprintf("extracted files exceed the input size of %d bytes", source.Size))
+		}
+		return storage.StorageVolume{}, errors.Join(
+			fmt.Errorf("failed to extract blob %s: %w", source.Digest, err), os.RemoveAll(dir))
+	}
+
+	volumeSource := dir
+	if source.FileName != "" {
+		volumeSource = filepath.Join(dir, source.FileName)
+	}
+	return storage.StorageVolume{
+		Type:     storage.StorageVolumeConnectorBind,
+		ReadOnly: true,
+		Source:   volumeSource,
+		Target:   input.Target,
+		Details:  map[string]string{DetailDigest: source.Digest},
+	}, nil
+}
+
+// CleanupStorage removes the directory the input was extracted into
+func (s *StorageProvider) CleanupStorage(_ context.Context, input models.InputSource, volume storage.StorageVolume) error {
+	source, err := DecodeSpec(input.Source)
+	if err != nil {
+		return err
+	}
+	if source.FileName != "" {
+		return os.RemoveAll(filepath.Dir(volume.Source))
+	}
+	return os.RemoveAll(volume.Source)
+}
+
+// Upload is not supported, as clients upload blobs through the API of the orchestrator
+func (s *StorageProvider) Upload(context.Context, string) (models.SpecConfig, error) {
+	return models.SpecConfig{}, fmt.Errorf("not implemented")
+}
+
+// compile-time check that we implement the interface
+var _ storage.Storage = (*StorageProvider)(nil)
@@ -0,0 +1,186 @@
+//go:build unit || !integration
+
+package blob_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/blobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/blob"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+// memoryStore is a blob store that serves blobs from memory
+type memoryStore struct {
+	blobs map[string][]byte
+}
+
+func (s *memoryStore) Stat(_ context.Context, digest string) (blobstore.Info, error) {
+	data, ok := s.blobs[digest]
+	if !ok {
+		return blobstore.Info{}, blobstore.NewErrBlobNotFound(digest)
+	}
+	return blobstore.Info{Digest: digest, Size: int64(len(data))}, nil
+}
+
+func (s *memoryStore) PutChunk(context.Context, string, int, []byte) error {
+	return nil
+}
+
+func (s *memoryStore) Commit(ctx context.Context, digest string, _ int) (blobstore.Info, error) {
+	return s.Stat(ctx, digest)
+}
+
+func (s *memoryStore) Get(ctx context.Context, digest string, w io.Writer) (blobstore.Info, error) {
+	info, err := s.Stat(ctx, digest)
+	if err != nil {
+		return info, err
+	}
+	_, err = w.Write(s.blobs[digest])
+	return info, err
+}
+
+func (s *memoryStore) List(ctx context.Context) ([]blobstore.Info, error) {
+	var infos []blobstore.Info
+	for digest := range s.blobs {
+		info, _ := s.Stat(ctx, digest)
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, digest string) error {
+	delete(s.blobs, digest)
+	return nil
+}
+
+type StorageSuite struct {
+	suite.Suite
+	ctx     context.Context
+	store   *memoryStore
+	storage *blob.StorageProvider
+}
+
+func TestStorageSuite(t *testing.T) {
+	suite.Run(t, new(StorageSuite))
+}
+
+func (s *StorageSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.store = &memoryStore{blobs: make(map[string][]byte)}
+	s.storage = blob.NewStorage(blob.StorageProviderParams{Store: s.store})
+}
+
+// archive stores the archive of path as a blob, and returns its digest
+func (s *StorageSuite) archive(path string) string {
+	var buf bytes.Buffer
+	s.Require().NoError(gzip.CompressToWriter(path, &buf))
+	digest, _, err := blobstore.Digest(bytes.NewReader(buf.Bytes()))
+	s.Require().NoError(err)
+	s.store.blobs[digest] = buf.Bytes()
+	return digest
+}
+
+func (s *StorageSuite) input(source blob.SourceSpec) models.InputSource {
+	spec, err := blob.NewSpecConfig(source)
+	s.Require().NoError(err)
+	return models.InputSource{Source: spec, Target: "/inputs"}
+}
+
+func (s *StorageSuite) TestPrepareDirectory() {
+	dir := s.T().TempDir()
+	s.Require().NoError(os.MkdirAll(filepath.Join(dir, "nested"), 0755))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "nested", "b.txt"), []byte("world!"), 0644))
+	input := s.input(blob.SourceSpec{Digest: s.archive(dir), Size: 11})
+
+	size, err := s.storage.GetVolumeSize(s.ctx, mock.Execution(), input)
+	s.Require().NoError(err)
+	s.Equal(uint64(11), size)
+
+	volume, err := s.storage.PrepareStorage(s.ctx, s.T().TempDir(), mock.Execution(), input)
+	s.Require().NoError(err)
+	s.True(volume.ReadOnly)
+	s.Equal("/inputs", volume.Target)
+	s.Equal(input.Source.Params["Digest"], volume.Details[blob.DetailDigest])
+	for name, expected := range map[string]string{"a.txt": "hello", "nested/b.txt": "world!"} {
+		data, err := os.ReadFile(filepath.Join(volume.Source, name))
+		s.Require().NoError(err)
+		s.Equal(expected, string(data))
+	}
+
+	s.Require().NoError(s.storage.CleanupStorage(s.ctx, input, volume))
+	s.NoDirExists(volume.Source)
+}
+
+func (s *StorageSuite) TestPrepareFile() {
+	path := filepath.Join(s.T().TempDir(), "data.csv")
+	s.Require().NoError(os.WriteFile(path, []byte("a,b"), 0644))
+	input := s.input(blob.SourceSpec{Digest: s.archive(path), Size: 3, FileName: "data.csv"})
+
+	volume, err := s.storage.PrepareStorage(s.ctx, s.T().TempDir(), mock.Execution(), input)
+	s.Require().NoError(err)
+	data, err := os.ReadFile(volume.Source)
+	s.Require().NoError(err)
+	s.Equal("a,b", string(data))
+
+	s.Require().NoError(s.storage.CleanupStorage(s.ctx, input, volume))
+	s.NoDirExists(filepath.Dir(volume.Source))
+}
+
+func (s *StorageSuite) TestPrepareLimitsExtractedSize() {
+	dir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "b.txt"), []byte("world!"), 0644))
+
+	// each file is within the declared size, but not both of them
+	storageDirectory := s.T().TempDir()
+	_, err := s.storage.PrepareStorage(s.ctx, storageDirectory, mock.Execution(),
+		s.input(blob.SourceSpec{Digest: s.archive(dir), Size: 6}))
+	s.Require().Error(err)
+	s.True(bacerrors.IsErrorWithCode(err, bacerrors.ValidationError))
+	entries, err := os.ReadDir(storageDirectory)
+	s.Require().NoError(err)
+	s.Empty(entries, "the partially extracted files should be removed")
+}
+
+func (s *StorageSuite) TestPrepareTamperedBlob() {
+	path := filepath.Join(s.T().TempDir(), "data.csv")
+	s.Require().NoError(os.WriteFile(path, []byte("a,b"), 0644))
+	digest := s.archive(path)
+	s.store.blobs[digest] = []byte("tampered")
+
+	_, err := s.storage.PrepareStorage(s.ctx, s.T().TempDir(), mock.Execution(),
+		s.input(blob.SourceSpec{Digest: digest, Size: 3}))
+	s.Require().Error(err)
+	s.True(bacerrors.IsErrorWithCode(err, bacerrors.ValidationError))
+}
+
+func (s *StorageSuite) TestPrepareMissingBlob() {
+	digest, _, err := blobstore.Digest(bytes.NewReader([]byte("missing")))
+	s.Require().NoError(err)
+	_, err = s.storage.PrepareStorage(s.ctx, s.T().TempDir(), mock.Execution(),
+		s.input(blob.SourceSpec{Digest: digest}))
+	s.Require().Error(err)
+	s.True(bacerrors.IsErrorWithCode(err, bacerrors.NotFoundError))
+}
+
+func (s *StorageSuite) TestInvalidSpec() {
+	_, err := blob.NewSpecConfig(blob.SourceSpec{Digest: "sha256:abc"})
+	s.Require().Error(err)
+
+	digest, _, err := blobstore.Digest(bytes.NewReader([]byte("x")))
+	s.Require().NoError(err)
+	_, err = blob.NewSpecConfig(blob.SourceSpec{Digest: digest, FileName: "../escape"})
+	s.Require().Error(err)
+}
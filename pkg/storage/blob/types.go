@@ -0,0 +1,71 @@
+package blob
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/fatih/structs"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/lib/blobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+const errComponent = "BlobInput"
+
+type SourceSpec struct {
+	// Digest is the digest of the gzipped tar archive of the input, such as sha256:<hex>
+	Digest string
+	// Size is the uncompressed size of the input in bytes
+	Size uint64
+	// FileName is the name of the file in the archive when the input is a single file.
+	// Optional. The input is the directory extracted from the archive if empty.
+	FileName string
+}
+
+func (c SourceSpec) Validate() error {
+	if err := blobstore.ValidateDigest(c.Digest); err != nil {
+		return newValidationError(fmt.Sprintf("invalid blob input params: %s", err))
+	}
+	if c.FileName != "" && (c.FileName != filepath.Base(c.FileName) || c.FileName == ".." || c.FileName == ".") {
+		return newValidationError(fmt.Sprintf("invalid blob input params: file name %q cannot be a path", c.FileName))
+	}
+	return nil
+}
+
+func (c SourceSpec) ToMap() map[string]interface{} {
+	return structs.Map(c)
+}
+
+func DecodeSpec(spec *models.SpecConfig) (SourceSpec, error) {
+	if !spec.IsType(models.StorageSourceBlob) {
+		return SourceSpec{}, newValidationError(
+			fmt.Sprintf("invalid storage source type. expected %s, but received: %s", models.StorageSourceBlob, spec.Type))
+	}
+	if spec.Params == nil {
+		return SourceSpec{}, newValidationError("invalid storage source params. cannot be nil")
+	}
+
+	var c SourceSpec
+	if err := mapstructure.Decode(spec.Params, &c); err != nil {
+		return c, err
+	}
+	return c, c.Validate()
+}
+
+func NewSpecConfig(source SourceSpec) (*models.SpecConfig, error) {
+	if err := source.Validate(); err != nil {
+		return nil, err
+	}
+	return &models.SpecConfig{
+		Type:   models.StorageSourceBlob,
+		Params: source.ToMap(),
+	}, nil
+}
+
+func newValidationError(message string) bacerrors.Error {
+	return bacerrors.New(message).
+		WithComponent(errComponent).
+		WithCode(bacerrors.ValidationError)
+}
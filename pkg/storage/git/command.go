@@ -0,0 +1,100 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// tokenUsername is the username sent with access tokens when the repository URL has none,
+	// which is accepted by the common git hosting services
+	tokenUsername = "x-access-token"
+
+	sshKeyFileName = "id_key"
+)
+
+// command runs git with an environment that carries the repository credentials,
+// so that they are neither passed as arguments nor persisted in the repository configuration.
+type command struct {
+	env []string
+}
+
+// run runs git with the arguments and returns its trimmed output
+func (c command) run(ctx context.Context, args ...string) (string, error) {
+	//nolint:gosec // G204: the arguments are validated by the source spec
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), c.env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// newCommand creates a command with the credentials of the source. The returned function removes
+// any credential written to disk, and must be called once the command is no longer used.
+func (s *StorageProvider) newCommand(source SourceSpec) (command, func(), error) {
+	env := []string{
+		// fail instead of prompting for credentials
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ASKPASS=",
+		"SSH_ASKPASS=",
+	}
+	noop := func() {}
+
+	switch {
+	case source.Token != "":
+		token, err := s.resolveSecret(source.Token)
+		if err != nil {
+			return command{}, noop, fmt.Errorf("failed to resolve git token: %w", err)
+		}
+		username := tokenUsername
+		if u := repositoryURL(source.URL); u != nil && u.User.Username() != "" {
+			username = u.User.Username()
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + token))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+		return command{env: env}, noop, nil
+	case source.SSHKey != "":
+		key, err := s.resolveSecret(source.SSHKey)
+		if err != nil {
+			return command{}, noop, fmt.Errorf("failed to resolve git ssh key: %w", err)
+		}
+		keyDir, err := os.MkdirTemp("", "git-ssh-*")
+		if err != nil {
+			return command{}, noop, err
+		}
+		cleanup := func() { _ = os.RemoveAll(keyDir) }
+		keyPath := filepath.Join(keyDir, sshKeyFileName)
+		// ssh rejects keys without a trailing newline
+		if err = os.WriteFile(keyPath, []byte(strings.TrimSpace(key)+"\n"), 0o600); err != nil {
+			cleanup()
+			return command{}, noop, err
+		}
+		env = append(env, fmt.Sprintf(
+			"GIT_SSH_COMMAND=ssh -i '%s' -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new -o BatchMode=yes",
+			keyPath))
+		return command{env: env}, cleanup, nil
+	default:
+		return command{env: env}, noop, nil
+	}
+}
+
+func (s *StorageProvider) resolveSecret(value string) (string, error) {
+	if s.secretResolver == nil {
+		return "", fmt.Errorf("secrets are not supported by this node")
+	}
+	return s.secretResolver.Value(strings.TrimPrefix(value, secretPrefix))
+}
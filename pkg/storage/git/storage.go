@@ -0,0 +1,261 @@
+package git
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+)
+
+/*
+Storage provider that checks out git repositories at a ref or commit, e.g.
+https://github.com/my-org/repo.git at main, or ssh://git@github.com/my-org/repo.git at a given commit.
+
+Fetched objects are cached in a bare mirror of each repository, which is shared across executions,
+so that a commit already fetched is checked out without network access. Each execution gets its
+own checkout, which is mounted read only and carries the git metadata of the checked out commit,
+but no credentials.
+*/
+
+const (
+	// Volume details recording how the input was resolved
+	DetailRepository = "Repository"
+	DetailRef        = "Ref"
+	DetailCommit     = "Commit"
+
+	// pinnedRefPrefix is the namespace of refs keeping fetched commits from being garbage collected in mirrors
+	pinnedRefPrefix = "refs/bacalhau/"
+
+	secretPrefix = models.EnvVarSecretScheme + ":"
+)
+
+// SecretResolver resolves the secrets referenced by the token and ssh key of sources
+type SecretResolver interface {
+	// Value returns the secret referenced by value, which has the form "<backend>/<key>"
+	Value(value string) (string, error)
+}
+
+type StorageProviderParams struct {
+	// MirrorDir is the directory where repository mirrors are cached across executions
+	MirrorDir string
+	// SecretResolver resolves the token and ssh key secrets. Optional.
+	SecretResolver SecretResolver
+}
+
+type StorageProvider struct {
+	mirrorDir      string
+	secretResolver SecretResolver
+
+	// mirrorLocks serializes the fetches into each mirror, keyed by mirror path
+	mirrorLocks sync.Map
+}
+
+func NewStorage(params StorageProviderParams) (*StorageProvider, error) {
+	if params.MirrorDir == "" {
+		return nil, errors.New("git mirror directory is required")
+	}
+	if err := os.MkdirAll(params.MirrorDir, models.DownloadFolderPerm); err != nil {
+		return nil, fmt.Errorf("failed to create git mirror directory: %w", err)
+	}
+	return &StorageProvider{
+		mirrorDir:      params.MirrorDir,
+		secretResolver: params.SecretResolver,
+	}, nil
+}
+
+// IsInstalled checks if git is available on the host
+func (s *StorageProvider) IsInstalled(_ context.Context) (bool, error) {
+	_, err := exec.LookPath("git")
+	return err == nil, nil
+}
+
+// HasStorageLocally returns true if the source is pinned to a commit that is already in the mirror.
+// It is only a locality hint: the commit is still served to a job only once it authenticated against the remote.
+func (s *StorageProvider) HasStorageLocally(ctx context.Context, input models.InputSource) (bool, error) {
+	source, err := DecodeSpec(input.Source)
+	if err != nil {
+		return false, err
+	}
+	if source.Commit == "" {
+		return false, nil
+	}
+	mirror := s.mirrorPath(source.URL)
+	if _, err = os.Stat(mirror); err != nil {
+		return false, nil
+	}
+	_, err = command{}.run(ctx, "--git-dir", mirror, "cat-file", "-e", source.Commit+"^{commit}")
+	return err == nil, nil
+}
+
+// GetVolumeSize returns zero as the size of a checkout is only known once it is fetched
+func (s *StorageProvider) GetVolumeSize(_ context.Context, _ *models.Execution, input models.InputSource) (uint64, error) {
+	_, err := DecodeSpec(input.Source)
+	return 0, err
+}
+
+func (s *StorageProvider) PrepareStorage(
+	ctx context.Context,
+	storageDirectory string,
+	_ *models.Execution,
+	input models.InputSource) (storage.StorageVolume, error) {
+	source, err := DecodeSpec(input.Source)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	cmd, cleanup, err := s.newCommand(source)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	defer cleanup()
+
+	mirror := s.mirrorPath(source.URL)
+	commit, err := s.fetch(ctx, cmd, mirror, source)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	log.Ctx(ctx).Debug().Msgf("Resolved %s at %s to commit %s", source.URL, source.revision(), commit)
+
+	outputDir, err := os.MkdirTemp(storageDirectory, "git-input-*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	checkoutDir := filepath.Join(outputDir, "checkout")
+	if err = checkout(ctx, mirror, checkoutDir, commit, source); err != nil {
+		_ = os.RemoveAll(outputDir)
+		return storage.StorageVolume{}, err
+	}
+
+	details := map[string]string{
+		DetailRepository: source.URL,
+		DetailCommit:     commit,
+	}
+	if source.Ref != "" {
+		details[DetailRef] = source.Ref
+	}
+	return storage.StorageVolume{
+		Type:     storage.StorageVolumeConnectorBind,
+		ReadOnly: true,
+		Source:   checkoutDir,
+		Target:   input.Target,
+		Details:  details,
+	}, nil
+}
+
+// fetch fetches the revision of the source into the mirror, unless it is a commit already
+// in the mirror, and returns the hash of the resolved commit. Mirrors are shared by all jobs,
+// so cached commits are only served after checking that the job's credentials can access the remote.
+func (s *StorageProvider) fetch(ctx context.Context, cmd command, mirror string, source SourceSpec) (string, error) {
+	lock, _ := s.mirrorLocks.LoadOrStore(mirror, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	if _, err := os.Stat(mirror); os.IsNotExist(err) {
+		if _, err = cmd.run(ctx, "init", "--quiet", "--bare", mirror); err != nil {
+			return "", err
+		}
+	}
+
+	resolve := func(revision string) (string, error) {
+		return cmd.run(ctx, "--git-dir", mirror, "rev-parse", "--verify", "--quiet", revision+"^{commit}")
+	}
+
+	var commit string
+	if source.Commit != "" {
+		// commits already in the mirror are not fetched again, but the remote is queried with the
+		// job's credentials so that jobs without access to the repository cannot read the mirror
+		if commit, _ = resolve(source.Commit); commit != "" {
+			if _, err := cmd.run(ctx, "ls-remote", "--quiet", source.URL, "HEAD"); err != nil {
+				return "", fmt.Errorf("failed to access %s: %w", source.URL, err)
+			}
+		}
+	}
+	if commit == "" {
+		revision := source.revision()
+		args := []string{"--git-dir", mirror, "fetch", "--quiet", "--no-tags"}
+		if source.Depth > 0 {
+			args = append(args, "--depth", strconv.Itoa(source.Depth))
+		}
+		if _, err := cmd.run(ctx, append(args, source.URL, revision)...); err != nil {
+			return "", err
+		}
+		var err error
+		if source.Commit != "" {
+			if commit, err = resolve(source.Commit); err != nil {
+				return "", fmt.Errorf("commit %s not found in %s of %s", source.Commit, revision, source.URL)
+			}
+		} else if commit, err = resolve("FETCH_HEAD"); err != nil {
+			return "", fmt.Errorf("%s of %s does not point to a commit", revision, source.URL)
+		}
+	}
+
+	if _, err := cmd.run(ctx, "--git-dir", mirror, "update-ref", pinnedRefPrefix+commit, commit); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// checkout checks out the commit from the mirror into a new repository at dir,
+// limited to the sparse paths of the source if any
+func checkout(ctx context.Context, mirror string, dir string, commit string, source SourceSpec) error {
+	cmd := command{}
+	if _, err := cmd.run(ctx, "init", "--quiet", dir); err != nil {
+		return err
+	}
+	fetchArgs := []string{"-C", dir, "fetch", "--quiet", "--no-tags"}
+	if source.Depth > 0 {
+		fetchArgs = append(fetchArgs, "--depth", strconv.Itoa(source.Depth))
+	}
+	if _, err := cmd.run(ctx, append(fetchArgs, mirror, commit)...); err != nil {
+		return err
+	}
+	if len(source.SparsePaths) > 0 {
+		if _, err := cmd.run(ctx, append([]string{"-C", dir, "sparse-checkout", "set"}, source.SparsePaths...)...); err != nil {
+			return err
+		}
+	}
+	_, err := cmd.run(ctx, "-C", dir, "checkout", "--quiet", "--detach", commit)
+	return err
+}
+
+func (s *StorageProvider) CleanupStorage(_ context.Context, _ models.InputSource, volume storage.StorageVolume) error {
+	// the volume source is the checkout directory within the directory created for the input
+	return os.RemoveAll(filepath.Dir(volume.Source))
+}
+
+func (s *StorageProvider) Upload(_ context.Context, _ string) (models.SpecConfig, error) {
+	return models.SpecConfig{}, fmt.Errorf("not implemented")
+}
+
+// mirrorPath returns the path of the mirror of the repository
+func (s *StorageProvider) mirrorPath(repository string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSuffix(repository, "/")))
+	return filepath.Join(s.mirrorDir, hex.EncodeToString(hash[:])+".git")
+}
+
+// repositoryURL returns the parsed repository URL, or nil for scp-like addresses
+func repositoryURL(repository string) *url.URL {
+	if scpPattern.MatchString(repository) {
+		return nil
+	}
+	u, err := url.Parse(repository)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+var _ storage.Storage = (*StorageProvider)(nil)
@@ -0,0 +1,138 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/structs"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+const errComponent = "Git"
+
+// commitPattern matches full and abbreviated SHA-1 and SHA-256 commit hashes
+var commitPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,64}$`)
+
+// scpPattern matches scp-like SSH repository addresses, e.g. git@github.com:my-org/repo.git
+var scpPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[^/].*$`)
+
+type SourceSpec struct {
+	// URL of the repository, e.g. https://github.com/my-org/repo.git or git@github.com:my-org/repo.git
+	URL string
+	// Ref is the branch or tag to check out. It defaults to the default branch of the repository.
+	Ref string
+	// Commit is the hash of the commit to check out. It takes precedence over Ref, which
+	// is then only used to fetch the commit.
+	Commit string
+	// Depth limits the fetched history to the given number of commits. The full history is fetched if zero.
+	Depth int
+	// SparsePaths limits the checkout to the given directories of the repository.
+	SparsePaths []string
+	// Token references the secret holding an access token for HTTP repositories,
+	// with the form "secret:<backend>/<key>".
+	Token string
+	// SSHKey references the secret holding a private key for SSH repositories,
+	// with the form "secret:<backend>/<key>".
+	SSHKey string
+}
+
+func (c SourceSpec) Validate() error {
+	if c.URL == "" {
+		return newValidationError("invalid git storage params: url cannot be empty")
+	}
+	if !scpPattern.MatchString(c.URL) {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			return newValidationError(fmt.Sprintf("invalid git storage params: invalid url: %s", err))
+		}
+		switch u.Scheme {
+		// file URLs are not supported, as they would give jobs access to any repository on the node
+		case "http", "https", "ssh", "git":
+		default:
+			return newValidationError(fmt.Sprintf("invalid git storage params: unsupported url scheme %q", u.Scheme))
+		}
+		if _, hasPassword := u.User.Password(); hasPassword {
+			return newValidationError("invalid git storage params: url cannot contain a password. Use a token secret instead")
+		}
+	}
+	if c.Commit != "" && !commitPattern.MatchString(c.Commit) {
+		return newValidationError(fmt.Sprintf("invalid git storage params: invalid commit %q", c.Commit))
+	}
+	if strings.HasPrefix(c.Ref, "-") {
+		return newValidationError(fmt.Sprintf("invalid git storage params: invalid ref %q", c.Ref))
+	}
+	if c.Depth < 0 {
+		return newValidationError("invalid git storage params: depth cannot be negative")
+	}
+	for _, path := range c.SparsePaths {
+		if path == "" || strings.HasPrefix(path, "-") {
+			return newValidationError(fmt.Sprintf("invalid git storage params: invalid sparse path %q", path))
+		}
+	}
+	if c.Token != "" && c.SSHKey != "" {
+		return newValidationError("invalid git storage params: token and ssh key cannot be both set")
+	}
+	for name, value := range map[string]string{"token": c.Token, "ssh key": c.SSHKey} {
+		if value != "" && !models.EnvVarValue(value).IsSecret() {
+			return newValidationError(fmt.Sprintf(
+				"invalid git storage params: %s must reference a secret with the form %s:<backend>/<key>",
+				name, models.EnvVarSecretScheme))
+		}
+	}
+	return nil
+}
+
+func (c SourceSpec) ToMap() map[string]interface{} {
+	return structs.Map(c)
+}
+
+// revision returns the revision fetched from the repository, which is the ref if set,
+// the commit otherwise, and HEAD if neither is set. Servers may not allow fetching commits directly,
+// in which case the ref holding the commit must be set.
+func (c SourceSpec) revision() string {
+	switch {
+	case c.Ref != "":
+		return c.Ref
+	case c.Commit != "":
+		return c.Commit
+	default:
+		return "HEAD"
+	}
+}
+
+func DecodeSpec(spec *models.SpecConfig) (SourceSpec, error) {
+	if !spec.IsType(models.StorageSourceGit) {
+		return SourceSpec{}, newValidationError(
+			fmt.Sprintf("invalid storage source type. expected %s, but received: %s", models.StorageSourceGit, spec.Type))
+	}
+	if spec.Params == nil {
+		return SourceSpec{}, newValidationError("invalid storage source params. cannot be nil")
+	}
+
+	var c SourceSpec
+	if err := mapstructure.Decode(spec.Params, &c); err != nil {
+		return c, err
+	}
+	return c, c.Validate()
+}
+
+func NewSpecConfig(source SourceSpec) (*models.SpecConfig, error) {
+	if err := source.Validate(); err != nil {
+		return nil, err
+	}
+	return &models.SpecConfig{
+		Type:   models.StorageSourceGit,
+		Params: source.ToMap(),
+	}, nil
+}
+
+func newValidationError(message string) bacerrors.Error {
+	return bacerrors.New(message).
+		WithComponent(errComponent).
+		WithCode(bacerrors.ValidationError)
+}
@@ -0,0 +1,94 @@
+package job
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fatih/structs"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+)
+
+/*
+Job inputs reference the results of another job, and are resolved by the orchestrator when the job is
+submitted into the results published by the upstream executions. They are then fetched by compute nodes
+with the storage matching the publisher of the upstream job, and are never fetched as job inputs.
+*/
+
+const (
+	// SelectLatest selects the most recently completed execution of the upstream job
+	SelectLatest = "latest"
+	// SelectAll selects the latest completed execution of each partition of the upstream job,
+	// which are mounted in one directory per partition
+	SelectAll = "all"
+
+	errComponent = "JobInput"
+)
+
+type SourceSpec struct {
+	// JobID is the ID or name of the upstream job, which must be in the same namespace
+	JobID string
+	// ExecutionSelector selects the executions whose results are used. It is either SelectLatest,
+	// SelectAll or the ID of an execution, and defaults to SelectLatest.
+	ExecutionSelector string
+	// Path selects a path within the results. Only supported for results published as
+	// plain S3 objects.
+	Path string
+}
+
+func (c SourceSpec) Validate() error {
+	if c.JobID == "" {
+		return newValidationError("invalid job input params: job id cannot be empty")
+	}
+	if slices.Contains(strings.Split(c.Path, "/"), "..") {
+		return newValidationError(fmt.Sprintf("invalid job input params: path %q cannot contain parent references", c.Path))
+	}
+	return nil
+}
+
+func (c SourceSpec) ToMap() map[string]interface{} {
+	return structs.Map(c)
+}
+
+// Selector returns the execution selector, defaulting to SelectLatest
+func (c SourceSpec) Selector() string {
+	if c.ExecutionSelector == "" {
+		return SelectLatest
+	}
+	return c.ExecutionSelector
+}
+
+func DecodeSpec(spec *models.SpecConfig) (SourceSpec, error) {
+	if !spec.IsType(models.StorageSourceJob) {
+		return SourceSpec{}, newValidationError(
+			fmt.Sprintf("invalid storage source type. expected %s, but received: %s", models.StorageSourceJob, spec.Type))
+	}
+	if spec.Params == nil {
+		return SourceSpec{}, newValidationError("invalid storage source params. cannot be nil")
+	}
+
+	var c SourceSpec
+	if err := mapstructure.Decode(spec.Params, &c); err != nil {
+		return c, err
+	}
+	return c, c.Validate()
+}
+
+func NewSpecConfig(source SourceSpec) (*models.SpecConfig, error) {
+	if err := source.Validate(); err != nil {
+		return nil, err
+	}
+	return &models.SpecConfig{
+		Type:   models.StorageSourceJob,
+		Params: source.ToMap(),
+	}, nil
+}
+
+func newValidationError(message string) bacerrors.Error {
+	return bacerrors.New(message).
+		WithComponent(errComponent).
+		WithCode(bacerrors.ValidationError)
+}
@@ -60,6 +60,9 @@ func (driver *StorageProvider) GetVolumeSize(_ context.Context, _ *models.Execut
 	if err != nil {
 		return 0, err
 	}
+	if err = validateOverlay(source, volume); err != nil {
+		return 0, err
+	}
 
 	_, err = driver.matchAllowedPath(source)
 	if err != nil {
@@ -94,6 +97,9 @@ func (driver *StorageProvider) PrepareStorage(
 	if err != nil {
 		return storage.StorageVolume{}, err
 	}
+	if err = validateOverlay(source, input); err != nil {
+		return storage.StorageVolume{}, err
+	}
 
 	_, err = driver.matchAllowedPath(source)
 	if err != nil {
@@ -153,6 +159,17 @@ func (driver *StorageProvider) matchAllowedPath(storageSpec Source) (*AllowedPat
 	return nil, err
 }
 
+// validateOverlay rejects read-write local inputs mounted with an overlay. Writes to an overlay go to a private
+// layer of the execution, so the local path only needs to be allowlisted for read access.
+func validateOverlay(source Source, input models.InputSource) error {
+	if input.Overlay == nil || !source.ReadWrite {
+		return nil
+	}
+	return bacerrors.Newf("volume %s cannot be both read-write and mounted with an overlay", source.SourcePath).
+		WithHint("Remove ReadWrite from the input source, as writes to an overlay never reach the local path").
+		WithCode(bacerrors.ValidationError)
+}
+
 func (driver *StorageProvider) createVolumeIfNotExists(source Source) error {
 	_, err := os.Stat(source.SourcePath)
 	if err == nil {
@@ -394,6 +394,31 @@ func (s *LocalStorageSuite) TestPrepareStorage_VolumeExists() {
 	}
 }
 
+func (s *LocalStorageSuite) TestPrepareStorage_Overlay() {
+	tmpDir := s.T().TempDir()
+	existingDir := filepath.Join(tmpDir, "sub", "path")
+	s.Require().NoError(os.MkdirAll(existingDir, 0755))
+
+	// overlays only need read access to the local path
+	allowedPaths := []string{filepath.Join(tmpDir, "**:ro")}
+	storageProvider, err := NewStorageProvider(StorageProviderParams{AllowedPaths: ParseAllowPaths(allowedPaths)})
+	s.Require().NoError(err)
+
+	spec := s.prepareStorageSpec(existingDir, "")
+	spec.Overlay = &models.InputOverlay{}
+	volume, err := storageProvider.PrepareStorage(context.Background(), s.T().TempDir(), mock.Execution(), spec)
+	s.Require().NoError(err)
+	s.Equal(existingDir, volume.Source)
+	s.True(volume.ReadOnly)
+
+	spec = s.prepareStorageSpec(existingDir+":rw", "")
+	spec.Overlay = &models.InputOverlay{}
+	_, err = storageProvider.PrepareStorage(context.Background(), s.T().TempDir(), mock.Execution(), spec)
+	s.Require().ErrorContains(err, "cannot be both read-write and mounted with an overlay")
+	_, err = storageProvider.GetVolumeSize(context.Background(), mock.Execution(), spec)
+	s.Require().ErrorContains(err, "cannot be both read-write and mounted with an overlay")
+}
+
 func (s *LocalStorageSuite) TestPrepareStorage_VolumeDoesNotExist_CreateAsFile() {
 	tmpDir := s.T().TempDir()
 	nonExistingPath := filepath.Join(tmpDir, "sub", "nonexisting", "path")
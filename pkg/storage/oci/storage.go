@@ -0,0 +1,165 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/distribution/reference"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+)
+
+/*
+Storage provider that pulls OCI artifacts from container registries, referenced by tag or digest,
+e.g. ghcr.io/my-org/dataset:v1 or ghcr.io/my-org/dataset@sha256:...
+
+The gzipped tar layers of the artifact are unpacked in order into the input directory, which
+supports the results pushed by the oci publisher as well as other artifacts and image layers.
+*/
+
+type StorageProviderParams struct {
+	Client *oci.Client
+	// Timeout of the requests made when computing the volume size
+	Timeout time.Duration
+}
+
+type StorageProvider struct {
+	client  *oci.Client
+	timeout time.Duration
+}
+
+func NewStorage(params StorageProviderParams) *StorageProvider {
+	return &StorageProvider{
+		client:  params.Client,
+		timeout: params.Timeout,
+	}
+}
+
+// IsInstalled returns true as the storage only needs network access
+func (s *StorageProvider) IsInstalled(_ context.Context) (bool, error) {
+	return true, nil
+}
+
+func (s *StorageProvider) HasStorageLocally(_ context.Context, _ models.InputSource) (bool, error) {
+	return false, nil
+}
+
+// GetVolumeSize returns the unpacked size of the artifact layers if they are annotated with it,
+// and their compressed size otherwise
+func (s *StorageProvider) GetVolumeSize(ctx context.Context, _ *models.Execution, input models.InputSource) (uint64, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	ref, err := s.reference(input)
+	if err != nil {
+		return 0, err
+	}
+	manifest, _, err := s.client.Manifest(ctx, ref)
+	if err != nil {
+		return 0, err
+	}
+
+	var size uint64
+	for _, layer := range oci.ArchiveLayers(manifest) {
+		layerSize := layer.Size
+		if annotated, err := strconv.ParseInt(layer.Annotations[oci.AnnotationUncompressedSize], 10, 64); err == nil {
+			layerSize = annotated
+		}
+		if layerSize < 0 || uint64(layerSize) > math.MaxUint64-size {
+			return 0, fmt.Errorf("invalid layer size in %s", reference.FamiliarString(ref))
+		}
+		size += uint64(layerSize)
+	}
+	return size, nil
+}
+
+func (s *StorageProvider) PrepareStorage(
+	ctx context.Context,
+	storageDirectory string,
+	_ *models.Execution,
+	input models.InputSource) (storage.StorageVolume, error) {
+	ref, err := s.reference(input)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	manifest, dgst, err := s.client.Manifest(ctx, ref)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	layers := oci.ArchiveLayers(manifest)
+	if len(layers) == 0 {
+		return storage.StorageVolume{}, fmt.Errorf("artifact %s has no tar+gzip layers", reference.FamiliarString(ref))
+	}
+	log.Ctx(ctx).Debug().Msgf("Preparing storage for %s@%s", reference.FamiliarString(ref), dgst)
+
+	outputDir, err := os.MkdirTemp(storageDirectory, "oci-input-*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	contentDir := filepath.Join(outputDir, "content")
+	if err = os.Mkdir(contentDir, models.DownloadFolderPerm); err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	for i, layer := range layers {
+		layerPath := filepath.Join(outputDir, fmt.Sprintf("layer-%d.tar.gz", i))
+		if err = s.fetchLayer(ctx, ref, layer, layerPath); err != nil {
+			return storage.StorageVolume{}, err
+		}
+		if err = gzip.Decompress(layerPath, contentDir); err != nil {
+			return storage.StorageVolume{}, fmt.Errorf("failed to unpack layer %s: %w", layer.Digest, err)
+		}
+		if err = os.Remove(layerPath); err != nil {
+			return storage.StorageVolume{}, err
+		}
+	}
+
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: contentDir,
+		Target: input.Target,
+	}, nil
+}
+
+func (s *StorageProvider) fetchLayer(
+	ctx context.Context, ref reference.Named, layer v1.Descriptor, path string) error {
+	//nolint:gosec // G304: path within the storage directory, application controlled
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, models.DownloadFilePerm)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	return s.client.FetchBlob(ctx, ref, layer, file)
+}
+
+func (s *StorageProvider) CleanupStorage(_ context.Context, _ models.InputSource, volume storage.StorageVolume) error {
+	// the volume source is the content directory within the directory created for the input
+	return os.RemoveAll(filepath.Dir(volume.Source))
+}
+
+func (s *StorageProvider) Upload(_ context.Context, _ string) (models.SpecConfig, error) {
+	return models.SpecConfig{}, fmt.Errorf("not implemented")
+}
+
+func (s *StorageProvider) reference(input models.InputSource) (reference.Named, error) {
+	source, err := oci.DecodeSourceSpec(input.Source)
+	if err != nil {
+		return nil, err
+	}
+	return oci.ParseReference(source.Reference)
+}
+
+var _ storage.Storage = (*StorageProvider)(nil)
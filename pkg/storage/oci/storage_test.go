@@ -0,0 +1,129 @@
+//go:build unit || !integration
+
+package oci
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/distribution/reference"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/oci"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
+)
+
+type StorageTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	registry *oci.TestRegistry
+	client   *oci.Client
+	storage  *StorageProvider
+}
+
+func TestStorageTestSuite(t *testing.T) {
+	suite.Run(t, new(StorageTestSuite))
+}
+
+func (s *StorageTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.registry = oci.NewTestRegistry(s.T())
+	s.client = oci.NewClient(oci.ClientParams{InsecureRegistries: []string{s.registry.Host()}})
+	s.storage = NewStorage(StorageProviderParams{Client: s.client})
+}
+
+// pushDirectory pushes the files as an artifact under the tag, and returns the digest reference
+func (s *StorageTestSuite) pushDirectory(tag string, files map[string]string, annotations map[string]string) string {
+	dir := s.T().TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		s.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+	}
+	archive, err := os.CreateTemp(s.T().TempDir(), "*.tar.gz")
+	s.Require().NoError(err)
+	defer func() { _ = archive.Close() }()
+	s.Require().NoError(gzip.Compress(dir, archive))
+	info, err := archive.Stat()
+	s.Require().NoError(err)
+
+	named, err := oci.ParseRepository(s.registry.Host() + "/dataset")
+	s.Require().NoError(err)
+	tagged, err := reference.WithTag(named, tag)
+	s.Require().NoError(err)
+	dgst, err := s.client.Push(s.ctx, tagged, oci.Artifact{
+		Layer:            archive,
+		LayerSize:        info.Size(),
+		LayerAnnotations: annotations,
+	})
+	s.Require().NoError(err)
+	return named.String() + "@" + dgst.String()
+}
+
+func (s *StorageTestSuite) input(ref string) models.InputSource {
+	spec, err := oci.NewSourceSpecConfig(ref)
+	s.Require().NoError(err)
+	return models.InputSource{Source: spec, Target: "/inputs"}
+}
+
+func (s *StorageTestSuite) TestPrepareStorage() {
+	pinned := s.pushDirectory("v1", map[string]string{"a.txt": "a", "dir/b.txt": "bb"}, nil)
+
+	for _, ref := range []string{s.registry.Host() + "/dataset:v1", pinned} {
+		input := s.input(ref)
+		volume, err := s.storage.PrepareStorage(s.ctx, s.T().TempDir(), mock.Execution(), input)
+		s.Require().NoError(err)
+		s.Equal(storage.StorageVolumeConnectorBind, volume.Type)
+		s.Equal("/inputs", volume.Target)
+
+		content, err := os.ReadFile(filepath.Join(volume.Source, "a.txt"))
+		s.Require().NoError(err)
+		s.Equal("a", string(content))
+		content, err = os.ReadFile(filepath.Join(volume.Source, "dir", "b.txt"))
+		s.Require().NoError(err)
+		s.Equal("bb", string(content))
+
+		s.Require().NoError(s.storage.CleanupStorage(s.ctx, input, volume))
+		s.NoDirExists(filepath.Dir(volume.Source))
+	}
+}
+
+func (s *StorageTestSuite) TestPrepareStorageWithoutArchiveLayers() {
+	config := s.registry.PushBlob("dataset", v1.MediaTypeImageConfig, []byte("{}"))
+	s.registry.PushManifest("dataset", "empty", v1.Manifest{
+		MediaType: v1.MediaTypeImageManifest,
+		Config:    config,
+	})
+	_, err := s.storage.PrepareStorage(s.ctx, s.T().TempDir(), mock.Execution(),
+		s.input(s.registry.Host()+"/dataset:empty"))
+	s.Require().Error(err)
+}
+
+func (s *StorageTestSuite) TestGetVolumeSize() {
+	s.pushDirectory("annotated", map[string]string{"a.txt": "a"},
+		map[string]string{oci.AnnotationUncompressedSize: "1234"})
+	size, err := s.storage.GetVolumeSize(s.ctx, mock.Execution(), s.input(s.registry.Host()+"/dataset:annotated"))
+	s.Require().NoError(err)
+	s.Equal(uint64(1234), size)
+
+	// the compressed size is used if the uncompressed size is unknown
+	s.pushDirectory("plain", map[string]string{"a.txt": "a"}, nil)
+	manifest := s.registry.Manifest("dataset", "plain")
+	size, err = s.storage.GetVolumeSize(s.ctx, mock.Execution(), s.input(s.registry.Host()+"/dataset:plain"))
+	s.Require().NoError(err)
+	s.Equal(uint64(manifest.Layers[0].Size), size)
+}
+
+func (s *StorageTestSuite) TestPullMissingArtifact() {
+	_, err := s.storage.GetVolumeSize(s.ctx, mock.Execution(), s.input(s.registry.Host()+"/dataset:missing"))
+	s.Require().Error(err)
+	_, err = s.storage.PrepareStorage(s.ctx, s.T().TempDir(), mock.Execution(),
+		s.input(s.registry.Host()+"/dataset:missing"))
+	s.Require().Error(err)
+}
@@ -39,7 +39,7 @@ func (s *ParallelStorageSuite) SetupSuite() {
 	s.cfg = cfg
 
 	var err error
-	s.provider, err = executor_util.NewStandardStorageProvider(cfg)
+	s.provider, err = executor_util.NewStandardStorageProvider(cfg, nil, nil)
 	s.Require().NoError(err)
 }
 
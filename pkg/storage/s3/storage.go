@@ -77,7 +77,7 @@ func (s *StorageProvider) GetVolumeSize(ctx context.Context, execution *models.E
 		return 0, err
 	}
 
-	objects, err = s3helper.PartitionObjects(objects, execution.Job.Count, execution.PartitionIndex, source)
+	objects, err = s.partitionObjects(ctx, client, source, execution, objects)
 	if err != nil {
 		return 0, err
 	}
@@ -127,7 +127,7 @@ func (s *StorageProvider) PrepareStorage(
 		return storage.StorageVolume{}, err
 	}
 
-	objects, err = s3helper.PartitionObjects(objects, execution.Job.Count, execution.PartitionIndex, source)
+	objects, err = s.partitionObjects(ctx, client, source, execution, objects)
 	if err != nil {
 		return storage.StorageVolume{}, err
 	}
@@ -294,6 +294,32 @@ func (s *StorageProvider) explodeKey(
 	return res, nil
 }
 
+// partitionObjects returns the objects of the partition of the execution,
+// reading the partition manifest from the bucket if the source is partitioned by manifest
+func (s *StorageProvider) partitionObjects(
+	ctx context.Context,
+	client *s3helper.ClientWrapper,
+	source s3helper.SourceSpec,
+	execution *models.Execution,
+	objects []s3helper.ObjectSummary) ([]s3helper.ObjectSummary, error) {
+	var assignments s3helper.PartitionAssignments
+	if source.Partition.Type == s3helper.PartitionKeyTypeManifest && execution.Job.Count > 1 {
+		resp, err := client.S3.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(source.Bucket),
+			Key:    aws.String(source.Partition.ManifestKey),
+		})
+		if err != nil {
+			return nil, s3helper.NewS3InputSourceServiceError(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		assignments, err = s3helper.ParsePartitionManifest(source.Partition.ManifestKey, resp.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s3helper.PartitionObjectsWithAssignments(objects, execution.Job.Count, execution.PartitionIndex, source, assignments)
+}
+
 func (s *StorageProvider) sanitizeKey(key string) string {
 	key = strings.TrimSpace(key)
 	key = strings.TrimSuffix(key, "*")
@@ -44,4 +44,19 @@ type StorageVolume struct {
 	ReadOnly bool                       `json:"readOnly"`
 	Source   string                     `json:"source"`
 	Target   string                     `json:"target"`
+	// Details describe how the input was resolved, such as the commit a git ref resolved to,
+	// and are recorded in the execution's events
+	Details map[string]string `json:"details,omitempty"`
+	// Overlay is set when the volume is mounted writable with a private copy-on-write layer over
+	// the read-only Source, instead of mounting Source directly
+	Overlay *OverlayLayer `json:"overlay,omitempty"`
+}
+
+// OverlayLayer is the private writable layer of a volume mounted with an overlay
+type OverlayLayer struct {
+	// UpperDir holds the files written by the execution, which shadow the files of the volume's Source
+	UpperDir string `json:"upperDir"`
+	// WorkDir is an empty directory on the same filesystem as UpperDir, used by overlay filesystems
+	// to prepare files before moving them into UpperDir
+	WorkDir string `json:"workDir"`
 }
@@ -0,0 +1,153 @@
+package urldownload
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	libgzip "github.com/bacalhau-project/bacalhau/pkg/lib/gzip"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+)
+
+// Archive formats are detected from the first bytes of the downloaded file rather than its name,
+// as URLs often don't end with the extension of the archive.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	zipMagic  = []byte("PK\x03\x04")
+	tarMagic  = []byte("ustar")
+)
+
+// tarMagicOffset is the offset of the magic string in the header of tar archives
+const tarMagicOffset = 257
+
+func isTar(header []byte) bool {
+	return len(header) >= tarMagicOffset+len(tarMagic) &&
+		bytes.Equal(header[tarMagicOffset:tarMagicOffset+len(tarMagic)], tarMagic)
+}
+
+// extract extracts the archive into dir, limiting the total size of the extracted files to maxBytes.
+// Gzip and zstd compressed files that are not tar archives are decompressed into a single file named
+// after the archive without its extension.
+func extract(archivePath, dir string, maxBytes int64) error {
+	file, err := os.Open(archivePath) //nolint:gosec // G304: archivePath is the downloaded file
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	if err = os.MkdirAll(dir, models.DownloadFolderPerm); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(file)
+	header, _ := reader.Peek(tarMagicOffset + len(tarMagic))
+	switch {
+	case bytes.HasPrefix(header, zipMagic):
+		stat, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		err = extractZip(file, stat.Size(), dir, maxBytes)
+		return extractError(err, maxBytes)
+	case bytes.HasPrefix(header, gzipMagic):
+		decompressed, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = decompressed.Close() }()
+		return extractError(extractCompressed(decompressed, archivePath, dir, maxBytes), maxBytes)
+	case bytes.HasPrefix(header, zstdMagic):
+		decompressed, err := zstd.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer decompressed.Close()
+		return extractError(extractCompressed(decompressed, archivePath, dir, maxBytes), maxBytes)
+	case isTar(header):
+		return extractError(libgzip.ExtractTar(reader, dir, maxBytes), maxBytes)
+	default:
+		return bacerrors.Newf("%s is not a tar, gzip, zstd or zip archive", filepath.Base(archivePath)).
+			WithComponent(errComponent).
+			WithCode(bacerrors.ValidationError).
+			WithHint("Only set extract for archives, or download the file without extracting it")
+	}
+}
+
+// extractError reports archives exceeding the size limit as validation errors
+func extractError(err error, maxBytes int64) error {
+	if errors.Is(err, libgzip.ErrMaxSizeExceeded) {
+		return bacerrors.Newf("extracted files exceed the limit of %d bytes", maxBytes).
+			WithComponent(errComponent).
+			WithCode(bacerrors.ValidationError).
+			WithHint("Set extractedSize to the total size of the files in the archive")
+	}
+	return err
+}
+
+// extractCompressed extracts a compressed tar archive, or decompresses a single compressed file
+func extractCompressed(decompressed io.Reader, archivePath, dir string, maxBytes int64) error {
+	reader := bufio.NewReader(decompressed)
+	header, _ := reader.Peek(tarMagicOffset + len(tarMagic))
+	if isTar(header) {
+		return libgzip.ExtractTar(reader, dir, maxBytes)
+	}
+	name := filepath.Base(archivePath)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if name == "" {
+		name = filepath.Base(archivePath)
+	}
+	_, err := libgzip.WriteFileWithMaxBytes(filepath.Join(dir, name), reader, models.DownloadFilePerm, maxBytes)
+	return err
+}
+
+func extractZip(r io.ReaderAt, size int64, dir string, maxBytes int64) error {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("failed to read zip archive: %w", err)
+	}
+	remaining := maxBytes
+	for _, entry := range reader.File {
+		target, err := libgzip.ExtractPath(dir, entry.Name)
+		if err != nil {
+			return err
+		}
+		mode := entry.Mode()
+		switch {
+		case mode.IsDir():
+			if err = os.MkdirAll(target, models.DownloadFolderPerm); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			written, err := extractZipFile(entry, target, remaining)
+			if err != nil {
+				return err
+			}
+			remaining -= written
+		default:
+			log.Debug().Str("Name", entry.Name).Msg("Skipping unsupported zip entry")
+		}
+	}
+	return nil
+}
+
+func extractZipFile(entry *zip.File, target string, maxBytes int64) (int64, error) {
+	content, err := entry.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = content.Close() }()
+	return libgzip.WriteFileWithMaxBytes(target, content, entry.Mode().Perm()|util.OS_USER_RW, maxBytes)
+}
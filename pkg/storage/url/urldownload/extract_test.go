@@ -0,0 +1,151 @@
+//go:build unit || !integration
+
+package urldownload
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func tarArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		require.NoError(t, writer.WriteHeader(&tar.Header{
+			Name: name, Mode: 0o644, Size: int64(len(files[name])), Typeflag: tar.TypeReg,
+		}))
+		_, err := writer.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func tarGzip(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(tarArchive(t, files))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func zstdCompress(t *testing.T, content []byte) []byte {
+	var buf bytes.Buffer
+	writer, err := zstd.NewWriter(&buf)
+	require.NoError(t, err)
+	_, err = writer.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestExtract(t *testing.T) {
+	files := map[string]string{"a.txt": "a", "dir/b.txt": "b"}
+	gzipped := func(content string) []byte {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		_, _ = writer.Write([]byte(content))
+		_ = writer.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		fileName string
+		archive  []byte
+		expected map[string]string
+	}{
+		{name: "tar", fileName: "archive.tar", archive: tarArchive(t, files), expected: files},
+		{name: "tgz", fileName: "archive.tgz", archive: tarGzip(t, files), expected: files},
+		{name: "tar.zst", fileName: "archive", archive: zstdCompress(t, tarArchive(t, files)), expected: files},
+		{name: "zip", fileName: "archive.zip", archive: zipArchive(t, files), expected: files},
+		{
+			name:     "compressed file",
+			fileName: "data.csv.gz",
+			archive:  gzipped("a,b"),
+			expected: map[string]string{"data.csv": "a,b"},
+		},
+		{
+			name:     "compressed file with zstd",
+			fileName: "data.csv.zst",
+			archive:  zstdCompress(t, []byte("a,b")),
+			expected: map[string]string{"data.csv": "a,b"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			archivePath := filepath.Join(t.TempDir(), tc.fileName)
+			require.NoError(t, os.WriteFile(archivePath, tc.archive, 0o600))
+			dir := filepath.Join(t.TempDir(), extractedDirName)
+
+			require.NoError(t, extract(archivePath, dir, 1<<20))
+			for name, content := range tc.expected {
+				actual, err := os.ReadFile(filepath.Join(dir, name))
+				require.NoError(t, err)
+				require.Equal(t, content, string(actual))
+			}
+		})
+	}
+}
+
+func TestExtract_RejectsInvalidArchives(t *testing.T) {
+	dir := t.TempDir()
+
+	notArchive := filepath.Join(dir, "file.txt")
+	require.NoError(t, os.WriteFile(notArchive, []byte("hello"), 0o600))
+	require.ErrorContains(t, extract(notArchive, filepath.Join(dir, "not-archive"), 1<<20), "is not a tar, gzip, zstd or zip archive")
+
+	traversal := filepath.Join(dir, "traversal.tar")
+	require.NoError(t, os.WriteFile(traversal, tarArchive(t, map[string]string{"../escaped.txt": "x"}), 0o600))
+	require.ErrorContains(t, extract(traversal, filepath.Join(dir, "traversal"), 1<<20), "outside of the extracted directory")
+	require.NoFileExists(t, filepath.Join(dir, "escaped.txt"))
+}
+
+func TestExtract_SkipsSymlinksOutsideArchive(t *testing.T) {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	require.NoError(t, writer.WriteHeader(&tar.Header{Name: "inside", Linkname: "dir/file", Typeflag: tar.TypeSymlink}))
+	require.NoError(t, writer.WriteHeader(&tar.Header{Name: "outside", Linkname: "../file", Typeflag: tar.TypeSymlink}))
+	require.NoError(t, writer.WriteHeader(&tar.Header{Name: "absolute", Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink}))
+	require.NoError(t, writer.Close())
+
+	archivePath := filepath.Join(t.TempDir(), "links.tar")
+	require.NoError(t, os.WriteFile(archivePath, buf.Bytes(), 0o600))
+	dir := filepath.Join(t.TempDir(), extractedDirName)
+	require.NoError(t, extract(archivePath, dir, 1<<20))
+
+	link, err := os.Readlink(filepath.Join(dir, "inside"))
+	require.NoError(t, err)
+	require.Equal(t, "dir/file", link)
+	require.NoFileExists(t, filepath.Join(dir, "outside"))
+	require.NoFileExists(t, filepath.Join(dir, "absolute"))
+}
@@ -1,10 +1,14 @@
 package urldownload
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"mime"
 	"net/http"
 	"net/url"
@@ -22,23 +26,42 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
 	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
 )
 
+const (
+	// partialFileName is the name of the file being downloaded, until its name is known
+	partialFileName = ".download"
+	// extractedDirName is the directory of the files extracted from downloaded archives
+	extractedDirName = ".extracted"
+
+	secretPrefix = models.EnvVarSecretScheme + ":"
+)
+
 var (
 	ErrNoContentLengthFound = errors.New("content-length not provided by the server")
 )
 
+// SecretResolver resolves the secrets referenced by the values of request headers
+type SecretResolver interface {
+	// Value returns the secret referenced by value, which has the form "<backend>/<key>"
+	Value(value string) (string, error)
+}
+
 // StorageProvider downloads data on request from a URL to a local
 // directory.
 
 type StorageProvider struct {
-	client *retryablehttp.Client
+	client         *retryablehttp.Client
+	maxRetries     int
+	secretResolver SecretResolver
 }
 
-func NewStorage(timeout time.Duration, maxRetries int) *StorageProvider {
+// NewStorage creates a URL storage provider. Header secrets are not supported if secretResolver is nil.
+func NewStorage(timeout time.Duration, maxRetries int, secretResolver SecretResolver) *StorageProvider {
 	log.Debug().Msg("URL download driver created")
 
 	client := retryablehttp.NewClient()
@@ -61,6 +84,10 @@ func NewStorage(timeout time.Duration, maxRetries int) *StorageProvider {
 			if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusBadRequest {
 				return false, nil
 			}
+			// the partial download is restarted instead
+			if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+				return false, nil
+			}
 			return true, nil
 		}
 
@@ -68,7 +95,9 @@ func NewStorage(timeout time.Duration, maxRetries int) *StorageProvider {
 	}
 
 	return &StorageProvider{
-		client: client,
+		client:         client,
+		maxRetries:     maxRetries,
+		secretResolver: secretResolver,
 	}
 }
 
@@ -85,8 +114,28 @@ func (sp *StorageProvider) GetVolumeSize(ctx context.Context, _ *models.Executio
 	if err != nil {
 		return 0, err
 	}
+	headers, err := sp.resolveHeaders(source.Headers)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs error
+	for _, rawURL := range source.urls() {
+		size, err := sp.getSize(ctx, rawURL, source.headersFor(rawURL, headers))
+		if err == nil {
+			// the archive is kept on disk until its files are extracted
+			if source.Extract {
+				size += source.maxExtractedSize(size)
+			}
+			return size, nil
+		}
+		errs = errors.Join(errs, err)
+	}
+	return 0, errs
+}
 
-	u, err := IsURLSupported(source.URL)
+func (sp *StorageProvider) getSize(ctx context.Context, rawURL string, headers http.Header) (uint64, error) {
+	u, err := IsURLSupported(rawURL)
 	if err != nil {
 		return 0, err
 	}
@@ -95,6 +144,7 @@ func (sp *StorageProvider) GetVolumeSize(ctx context.Context, _ *models.Executio
 	if err != nil {
 		return 0, err
 	}
+	addHeaders(req, headers)
 
 	res, err := sp.client.Do(req) //nolint:bodyclose // this is being closed - golangci-lint is wrong again
 	if err != nil {
@@ -115,7 +165,8 @@ func (sp *StorageProvider) GetVolumeSize(ctx context.Context, _ *models.Executio
 	return uint64(res.ContentLength), nil
 }
 
-// PrepareStorage will download the file from the URL
+// PrepareStorage will download the file from the URL, or from its mirrors if it cannot be downloaded
+// from the URL, and verify its checksum
 func (sp *StorageProvider) PrepareStorage(
 	ctx context.Context,
 	storageDirectory string,
@@ -125,7 +176,7 @@ func (sp *StorageProvider) PrepareStorage(
 	if err != nil {
 		return storage.StorageVolume{}, err
 	}
-	u, err := IsURLSupported(source.URL)
+	headers, err := sp.resolveHeaders(source.Headers)
 	if err != nil {
 		return storage.StorageVolume{}, err
 	}
@@ -136,38 +187,195 @@ func (sp *StorageProvider) PrepareStorage(
 		return storage.StorageVolume{}, err
 	}
 
+	// checksum mismatches are only reported if all the sources served a mismatching file,
+	// as other nodes may be able to download the file from the sources that failed
+	var filePath string
+	var mismatch, errs error
+	for _, rawURL := range source.urls() {
+		filePath, err = sp.download(ctx, rawURL, outputPath, source.headersFor(rawURL, headers))
+		if err == nil {
+			err = verifyChecksum(rawURL, filePath, source.SHA256)
+		}
+		if err == nil {
+			break
+		}
+		log.Ctx(ctx).Warn().Err(err).Str("url", rawURL).Msg("Failed to download file")
+		if filePath != "" {
+			_ = os.Remove(filePath)
+		}
+		if bacerrors.IsErrorWithCode(err, bacerrors.ChecksumMismatch) {
+			mismatch = cmp.Or(mismatch, err)
+		} else {
+			errs = errors.Join(errs, err)
+		}
+	}
+	if err != nil {
+		_ = os.RemoveAll(outputPath)
+		return storage.StorageVolume{}, cmp.Or(errs, mismatch)
+	}
+
+	fileName := filepath.Base(filePath)
+	volume := storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: filePath,                              // The source is the full path to the file
+		Target: filepath.Join(input.Target, fileName), // So we should alter the target to include the file name
+	}
+
+	if source.Extract {
+		extractedPath := filepath.Join(outputPath, extractedDirName)
+		if err = extract(filePath, extractedPath, maxExtractedBytes(source, filePath)); err != nil {
+			_ = os.RemoveAll(outputPath)
+			return storage.StorageVolume{}, fmt.Errorf("failed to extract %s: %w", fileName, err)
+		}
+		// the archive is no longer needed once extracted
+		if err = os.Remove(filePath); err != nil {
+			return storage.StorageVolume{}, err
+		}
+		volume.Source = extractedPath
+		volume.Target = input.Target
+	}
+
+	return volume, nil
+}
+
+// maxExtractedBytes returns the limit of the files extracted from the archive at archivePath,
+// which is the disk space reserved for them by GetVolumeSize
+func maxExtractedBytes(source Source, archivePath string) int64 {
+	var archiveSize uint64
+	if stat, err := os.Stat(archivePath); err == nil {
+		archiveSize = uint64(stat.Size()) //nolint:gosec // G115: file sizes are not negative
+	}
+	return int64(min(source.maxExtractedSize(archiveSize), math.MaxInt64)) //nolint:gosec // G115: bounded by math.MaxInt64
+}
+
+// download downloads the file at rawURL into outputPath, and returns the path of the downloaded file.
+// Downloads interrupted while reading the response are resumed from where they stopped, if the server
+// supports range requests.
+func (sp *StorageProvider) download(
+	ctx context.Context, rawURL string, outputPath string, headers http.Header) (string, error) {
+	u, err := IsURLSupported(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	partialPath := filepath.Join(outputPath, partialFileName)
+	defer func() { _ = os.Remove(partialPath) }()
+
+	var fileName string
+	for attempt := 0; ; attempt++ {
+		var offset int64
+		if info, statErr := os.Stat(partialPath); statErr == nil {
+			offset = info.Size()
+		}
+		var res *http.Response
+		res, err = sp.get(ctx, u, headers, partialPath, offset)
+		if err != nil {
+			return "", err
+		}
+		if fileName == "" {
+			fileName = responseFileName(u, res)
+		}
+		err = appendBody(partialPath, res.Body)
+		closer.DrainAndCloseWithLogOnError(ctx, "response", res.Body)
+		if err == nil {
+			break
+		}
+		if attempt >= sp.maxRetries || ctx.Err() != nil {
+			return "", fmt.Errorf("failed to download from url %s: %w", u, err)
+		}
+		log.Ctx(ctx).Debug().Err(err).Stringer("url", u).Msg("Download interrupted, resuming")
+	}
+
+	filePath := filepath.Join(outputPath, fileName)
+	if err = os.Rename(partialPath, filePath); err != nil {
+		return "", err
+	}
+
+	log.Ctx(ctx).Debug().
+		Stringer("url", u).
+		Str("file", filePath).
+		Msg("Downloaded file")
+	return filePath, nil
+}
+
+// get requests the file from the offset, and returns a response whose body is the rest of the file.
+// The partial file is truncated if the server sends the whole file instead.
+func (sp *StorageProvider) get(
+	ctx context.Context, u *url.URL, headers http.Header, partialPath string, offset int64) (*http.Response, error) {
 	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return storage.StorageVolume{}, err
+		return nil, err
+	}
+	addHeaders(req, headers)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
 	}
 
-	requestDidRedirect := false
+	res, err := sp.client.Do(req) //nolint:bodyclose // closed by the caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin download from url %s: %w", u, err)
+	}
 
-	// Install handler which can recognize whether we have performed a redirect or not.
-	previousRedirect := sp.client.HTTPClient.CheckRedirect
-	sp.client.HTTPClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-		requestDidRedirect = true
-		return nil
+	switch {
+	case offset > 0 && res.StatusCode == http.StatusPartialContent && rangeStart(res) == offset:
+		log.Ctx(ctx).Debug().Stringer("url", u).Int64("offset", offset).Msg("Resuming download")
+		return res, nil
+	case offset > 0 && res.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// the partial file is not a prefix of the file, which is downloaded again
+		closer.DrainAndCloseWithLogOnError(ctx, "response", res.Body)
+		if err = os.Remove(partialPath); err != nil {
+			return nil, err
+		}
+		return sp.get(ctx, u, headers, partialPath, 0)
+	case res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices:
+		closer.DrainAndCloseWithLogOnError(ctx, "response", res.Body)
+		return nil, fmt.Errorf("non-200 response from URL (%s): %s", u, res.Status)
+	default:
+		// servers ignoring the range send the whole file
+		if err = os.Truncate(partialPath, 0); err != nil && !os.IsNotExist(err) {
+			closer.DrainAndCloseWithLogOnError(ctx, "response", res.Body)
+			return nil, err
+		}
+		return res, nil
 	}
+}
 
-	res, err := sp.client.Do(req) //nolint:bodyclose // this is being closed - golangci-lint is wrong again
+// appendBody appends the body of a response to the partial file
+func appendBody(partialPath string, body io.Reader) error {
+	//nolint:gosec // G304: partialPath is within the storage directory
+	w, err := os.OpenFile(partialPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, models.DownloadFilePerm)
 	if err != nil {
-		return storage.StorageVolume{}, fmt.Errorf("failed to begin download from url %s: %w", u, err)
+		return fmt.Errorf("failed to create file %s: %w", partialPath, err)
 	}
-	defer closer.DrainAndCloseWithLogOnError(ctx, "response", res.Body)
+	defer closer.CloseWithLogOnError("file", w)
 
-	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
-		return storage.StorageVolume{}, fmt.Errorf("non-200 response from URL (%s): %s", source.URL, res.Status)
+	// stream the body to the file without fully loading it into memory
+	if _, err = io.Copy(w, body); err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", partialPath, err)
+	}
+	if err = w.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file %s: %w", partialPath, err)
 	}
+	return nil
+}
 
-	// Reset previous redirect handler
-	sp.client.HTTPClient.CheckRedirect = previousRedirect
+// rangeStart returns the first byte of the content range of a partial response, or -1 if it is invalid
+func rangeStart(res *http.Response) int64 {
+	var start int64
+	if _, err := fmt.Sscanf(res.Header.Get("Content-Range"), "bytes %d-", &start); err != nil {
+		return -1
+	}
+	return start
+}
 
+// responseFileName returns the name of the downloaded file, which is the name of the file of the
+// final URL, or the name given by the server after a redirect. It is a random name if neither is set.
+func responseFileName(u *url.URL, res *http.Response) string {
 	var fileName string
 	baseName := path.Base(res.Request.URL.Path)
 
 	// Check whether content-disposition is set, but only after a redirect
-	if requestDidRedirect {
+	if res.Request.URL.String() != u.String() {
 		fileName = filenameFromDisposition(res.Header.Get("content-disposition"))
 	}
 
@@ -179,40 +387,60 @@ func (sp *StorageProvider) PrepareStorage(
 	} else if fileName == "" {
 		fileName = baseName
 	}
+	return fileName
+}
 
-	filePath := filepath.Join(outputPath, fileName)
-	w, err := os.Create(filePath) //nolint:gosec // G304: filePath validated by caller
+// verifyChecksum checks the SHA-256 checksum of the downloaded file, if one is expected
+func verifyChecksum(rawURL string, filePath string, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	file, err := os.Open(filePath) //nolint:gosec // G304: filePath is the downloaded file
 	if err != nil {
-		return storage.StorageVolume{}, fmt.Errorf("failed to create file %s: %s", filePath, err)
+		return err
 	}
+	defer func() { _ = file.Close() }()
 
-	defer closer.CloseWithLogOnError("file", w)
-
-	// stream the body to the client without fully loading it into memory
-	if _, err := io.Copy(w, res.Body); err != nil {
-		return storage.StorageVolume{}, fmt.Errorf("failed to write to file %s: %s", filePath, err)
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return fmt.Errorf("failed to compute checksum of %s: %w", filePath, err)
 	}
-
-	if err := w.Sync(); err != nil {
-		return storage.StorageVolume{}, fmt.Errorf("failed to sync file %s: %w", filePath, err)
+	actual := hex.EncodeToString(hash.Sum(nil))
+	if !strings.EqualFold(actual, expected) {
+		return bacerrors.Newf("checksum of the file downloaded from %s does not match: expected sha256 %s, got %s",
+			rawURL, strings.ToLower(expected), actual).
+			WithComponent(errComponent).
+			WithCode(bacerrors.ChecksumMismatch).
+			WithHint("The file has changed or is corrupted at its source. Update the expected checksum if the change is intended")
 	}
+	return nil
+}
 
-	targetPath := filepath.Join(input.Target, fileName)
-
-	log.Ctx(ctx).Debug().
-		Stringer("url", u).
-		Stringer("final-url", res.Request.URL).
-		Str("file", filePath).
-		Str("targetFile", targetPath).
-		Msg("Downloaded file")
-
-	volume := storage.StorageVolume{
-		Type:   storage.StorageVolumeConnectorBind,
-		Source: filePath,   // The source is the full path to the file
-		Target: targetPath, // So we should alter the target to include the file name
+// resolveHeaders returns the headers of the requests, with their secret references resolved
+func (sp *StorageProvider) resolveHeaders(headers map[string]string) (http.Header, error) {
+	resolved := make(http.Header, len(headers))
+	for name, value := range headers {
+		if models.EnvVarValue(value).IsSecret() {
+			if sp.secretResolver == nil {
+				return nil, bacerrors.Newf("header %s references a secret, but secrets are not supported by this node", name).
+					WithComponent(errComponent).
+					WithCode(bacerrors.NotImplemented)
+			}
+			secret, err := sp.secretResolver.Value(strings.TrimPrefix(value, secretPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve the secret of header %s: %w", name, err)
+			}
+			value = secret
+		}
+		resolved.Set(name, value)
 	}
+	return resolved, nil
+}
 
-	return volume, nil
+func addHeaders(req *retryablehttp.Request, headers http.Header) {
+	for name, values := range headers {
+		req.Header[name] = values
+	}
 }
 
 func filenameFromDisposition(contentDispositionHdr string) string {
@@ -4,20 +4,27 @@ package urldownload
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
 	"github.com/bacalhau-project/bacalhau/pkg/config"
 	"github.com/bacalhau-project/bacalhau/pkg/logger"
 	"github.com/bacalhau-project/bacalhau/pkg/models"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
 	"github.com/bacalhau-project/bacalhau/pkg/test/mock"
 )
 
@@ -42,7 +49,7 @@ func (s *StorageSuite) TestHasStorageLocally() {
 	testConfig, err := config.NewTestConfig()
 	s.Require().NoError(err)
 
-	sp := NewStorage(time.Duration(testConfig.InputSources.ReadTimeout), testConfig.InputSources.MaxRetryCount)
+	sp := NewStorage(time.Duration(testConfig.InputSources.ReadTimeout), testConfig.InputSources.MaxRetryCount, nil)
 
 	spec := models.InputSource{
 		Source: &models.SpecConfig{
@@ -308,7 +315,7 @@ func (s *StorageSuite) TestPrepareStorageURL() {
 			testConfig, err := config.NewTestConfig()
 			s.Require().NoError(err)
 
-			sp := NewStorage(time.Duration(testConfig.InputSources.ReadTimeout), testConfig.InputSources.MaxRetryCount)
+			sp := NewStorage(time.Duration(testConfig.InputSources.ReadTimeout), testConfig.InputSources.MaxRetryCount, nil)
 
 			url := fmt.Sprintf("%s%s", ts.URL, test.requests[0].path)
 			spec := models.InputSource{
@@ -374,7 +381,7 @@ func (s *StorageSuite) TestGetVolumeSize_WithServerReturningValidSize() {
 	testConfig, err := config.NewTestConfig()
 	s.Require().NoError(err)
 
-	sp := NewStorage(time.Duration(testConfig.InputSources.ReadTimeout), testConfig.InputSources.MaxRetryCount)
+	sp := NewStorage(time.Duration(testConfig.InputSources.ReadTimeout), testConfig.InputSources.MaxRetryCount, nil)
 
 	url := fmt.Sprintf("%s%s", ts.URL, path)
 	spec := models.InputSource{
@@ -392,6 +399,16 @@ func (s *StorageSuite) TestGetVolumeSize_WithServerReturningValidSize() {
 
 	s.Equal(uint64(500), vs, "content-length does not match")
 
+	// the archive and its extracted files are both reserved
+	spec.Source.Params = Source{URL: url, Extract: true}.ToMap()
+	vs, err = sp.GetVolumeSize(context.Background(), mock.Execution(), spec)
+	s.Require().NoError(err)
+	s.Equal(uint64(500+500*defaultExtractRatio), vs)
+
+	spec.Source.Params = Source{URL: url, Extract: true, ExtractedSize: 2000}.ToMap()
+	vs, err = sp.GetVolumeSize(context.Background(), mock.Execution(), spec)
+	s.Require().NoError(err)
+	s.Equal(uint64(2500), vs)
 }
 
 func (s *StorageSuite) TestGetVolumeSize_WithServerReturningInvalidSize() {
@@ -415,7 +432,7 @@ func (s *StorageSuite) TestGetVolumeSize_WithServerReturningInvalidSize() {
 	testConfig, err := config.NewTestConfig()
 	s.Require().NoError(err)
 
-	sp := NewStorage(time.Duration(testConfig.InputSources.ReadTimeout), testConfig.InputSources.MaxRetryCount)
+	sp := NewStorage(time.Duration(testConfig.InputSources.ReadTimeout), testConfig.InputSources.MaxRetryCount, nil)
 
 	url := fmt.Sprintf("%s%s", ts.URL, path)
 	spec := models.InputSource{
@@ -432,3 +449,229 @@ func (s *StorageSuite) TestGetVolumeSize_WithServerReturningInvalidSize() {
 	s.Require().ErrorIs(err, ErrNoContentLengthFound)
 
 }
+
+// newTestStorage creates a storage provider that doesn't retry failed requests
+func (s *StorageSuite) newTestStorage(secrets map[string]string) *StorageProvider {
+	return NewStorage(10*time.Second, 0, secretResolver(secrets))
+}
+
+func (s *StorageSuite) prepare(sp *StorageProvider, source Source) (string, storage.StorageVolume, error) {
+	storageDirectory := s.T().TempDir()
+	spec := models.InputSource{
+		Source: &models.SpecConfig{Type: models.StorageSourceURL, Params: source.ToMap()},
+		Target: "/inputs",
+	}
+	vol, err := sp.PrepareStorage(context.Background(), storageDirectory, mock.Execution(), spec)
+	return storageDirectory, vol, err
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *StorageSuite) TestPrepareStorage_Checksum() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	s.T().Cleanup(ts.Close)
+	sp := s.newTestStorage(nil)
+
+	_, vol, err := s.prepare(sp, Source{URL: ts.URL + "/file.txt", SHA256: strings.ToUpper(sha256Hex("hello"))})
+	s.Require().NoError(err)
+	s.FileExists(vol.Source)
+
+	storageDirectory, _, err := s.prepare(sp, Source{URL: ts.URL + "/file.txt", SHA256: sha256Hex("goodbye")})
+	s.Require().Error(err)
+	s.True(bacerrors.IsErrorWithCode(err, bacerrors.ChecksumMismatch), "unexpected error: %s", err)
+	entries, err := os.ReadDir(storageDirectory)
+	s.Require().NoError(err)
+	s.Empty(entries, "the mismatching download should be removed")
+}
+
+func (s *StorageSuite) TestPrepareStorage_Mirrors() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing/file.txt":
+			w.WriteHeader(http.StatusNotFound)
+		case "/corrupted/file.txt":
+			_, _ = w.Write([]byte("corrupted"))
+		default:
+			_, _ = w.Write([]byte("hello"))
+		}
+	}))
+	s.T().Cleanup(ts.Close)
+	sp := s.newTestStorage(nil)
+
+	_, vol, err := s.prepare(sp, Source{
+		URL:     ts.URL + "/missing/file.txt",
+		Mirrors: []string{ts.URL + "/corrupted/file.txt", ts.URL + "/mirror/file.txt"},
+		SHA256:  sha256Hex("hello"),
+	})
+	s.Require().NoError(err)
+	content, err := os.ReadFile(vol.Source)
+	s.Require().NoError(err)
+	s.Equal("hello", string(content))
+
+	// other nodes may be able to download from the missing source, so the mismatch is not reported
+	_, _, err = s.prepare(sp, Source{
+		URL:     ts.URL + "/missing/file.txt",
+		Mirrors: []string{ts.URL + "/corrupted/file.txt"},
+		SHA256:  sha256Hex("hello"),
+	})
+	s.Require().Error(err)
+	s.False(bacerrors.IsErrorWithCode(err, bacerrors.ChecksumMismatch))
+}
+
+func (s *StorageSuite) TestPrepareStorage_Headers() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer my-token" || r.Header.Get("X-Custom") != "value" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	s.T().Cleanup(ts.Close)
+	source := Source{
+		URL:     ts.URL + "/file.txt",
+		Headers: map[string]string{"Authorization": "secret:vault/token", "X-Custom": "value"},
+	}
+
+	_, _, err := s.prepare(s.newTestStorage(map[string]string{"vault/token": "Bearer my-token"}), source)
+	s.Require().NoError(err)
+
+	_, _, err = s.prepare(s.newTestStorage(map[string]string{}), source)
+	s.Require().ErrorContains(err, "failed to resolve the secret of header Authorization")
+
+	_, _, err = s.prepare(NewStorage(10*time.Second, 0, nil), source)
+	s.Require().ErrorContains(err, "secrets are not supported by this node")
+}
+
+func (s *StorageSuite) TestPrepareStorage_HeadersOnlySentToSameHost() {
+	var mirrorAuthorization []string
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrorAuthorization = append(mirrorAuthorization, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte("hello"))
+	}))
+	s.T().Cleanup(mirror.Close)
+	var primaryAuthorization []string
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryAuthorization = append(primaryAuthorization, r.Header.Get("Authorization"))
+		if r.URL.Path == "/missing/file.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	s.T().Cleanup(primary.Close)
+
+	_, _, err := s.prepare(s.newTestStorage(map[string]string{"vault/token": "Bearer my-token"}), Source{
+		URL:     primary.URL + "/missing/file.txt",
+		Mirrors: []string{mirror.URL + "/file.txt"},
+		Headers: map[string]string{"Authorization": "secret:vault/token"},
+	})
+	s.Require().NoError(err)
+	s.Equal([]string{"Bearer my-token"}, primaryAuthorization)
+	s.Equal([]string{""}, mirrorAuthorization, "the credentials of the URL should not be sent to other hosts")
+
+	primaryAuthorization = nil
+	_, _, err = s.prepare(s.newTestStorage(map[string]string{"vault/token": "Bearer my-token"}), Source{
+		URL:     primary.URL + "/missing/file.txt",
+		Mirrors: []string{primary.URL + "/mirror/file.txt"},
+		Headers: map[string]string{"Authorization": "secret:vault/token"},
+	})
+	s.Require().NoError(err)
+	s.Equal([]string{"Bearer my-token", "Bearer my-token"}, primaryAuthorization)
+}
+
+func (s *StorageSuite) TestPrepareStorage_ResumesInterruptedDownload() {
+	content := strings.Repeat("0123456789", 100)
+	var ranges []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		var start int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start); err == nil {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte(content[start:]))
+			return
+		}
+		// the connection is closed before the whole content is sent
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		_, _ = w.Write([]byte(content[:300]))
+	}))
+	s.T().Cleanup(ts.Close)
+
+	_, vol, err := s.prepare(NewStorage(10*time.Second, 1, nil), Source{URL: ts.URL + "/file.txt", SHA256: sha256Hex(content)})
+	s.Require().NoError(err)
+	s.Equal([]string{"", "bytes=300-"}, ranges)
+	s.Equal(filepath.Join("/inputs", "file.txt"), vol.Target)
+	actual, err := os.ReadFile(vol.Source)
+	s.Require().NoError(err)
+	s.Equal(content, string(actual))
+}
+
+func (s *StorageSuite) TestPrepareStorage_Extract() {
+	archive := tarGzip(s.T(), map[string]string{"data/a.txt": "a", "b.txt": "b"})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive)
+	}))
+	s.T().Cleanup(ts.Close)
+	sp := s.newTestStorage(nil)
+
+	_, vol, err := s.prepare(sp, Source{URL: ts.URL + "/archive.tgz", Extract: true})
+	s.Require().NoError(err)
+	s.Equal("/inputs", vol.Target)
+	content, err := os.ReadFile(filepath.Join(vol.Source, "data", "a.txt"))
+	s.Require().NoError(err)
+	s.Equal("a", string(content))
+	s.NoFileExists(filepath.Join(filepath.Dir(vol.Source), "archive.tgz"), "the archive should be removed")
+
+	s.Require().NoError(sp.CleanupStorage(context.Background(), models.InputSource{}, vol))
+	s.NoDirExists(vol.Source)
+}
+
+func (s *StorageSuite) TestPrepareStorage_ExtractLimitsExtractedSize() {
+	archive := tarGzip(s.T(), map[string]string{"a.txt": strings.Repeat("a", 1000), "b.txt": strings.Repeat("b", 1000)})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive)
+	}))
+	s.T().Cleanup(ts.Close)
+	sp := s.newTestStorage(nil)
+
+	_, _, err := s.prepare(sp, Source{URL: ts.URL + "/archive.tgz", Extract: true, ExtractedSize: 2000})
+	s.Require().NoError(err)
+
+	// each file is within the limit, but not both of them
+	storageDirectory, _, err := s.prepare(sp, Source{URL: ts.URL + "/archive.tgz", Extract: true, ExtractedSize: 1500})
+	s.Require().Error(err)
+	s.True(bacerrors.IsErrorWithCode(err, bacerrors.ValidationError), "unexpected error: %s", err)
+	entries, err := os.ReadDir(storageDirectory)
+	s.Require().NoError(err)
+	s.Empty(entries, "the partially extracted files should be removed")
+}
+
+func (s *StorageSuite) TestSourceValidate() {
+	valid := Source{URL: "https://example.com/file.txt"}
+	s.NoError(valid.Validate())
+
+	for name, source := range map[string]Source{
+		"invalid sha256":  {URL: valid.URL, SHA256: "abc"},
+		"invalid mirror":  {URL: valid.URL, Mirrors: []string{"ftp://example.com/file.txt"}},
+		"invalid header":  {URL: valid.URL, Headers: map[string]string{"Bad Header": "value"}},
+		"reserved header": {URL: valid.URL, Headers: map[string]string{"range": "bytes=0-"}},
+		"extracted size":  {URL: valid.URL, ExtractedSize: 1024},
+	} {
+		s.Error(source.Validate(), name)
+	}
+}
+
+type secretResolver map[string]string
+
+func (r secretResolver) Value(value string) (string, error) {
+	secret, ok := r[value]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return secret, nil
+}
@@ -1,8 +1,13 @@
 package urldownload
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
 
 	"github.com/fatih/structs"
 	"github.com/mitchellh/mapstructure"
@@ -10,10 +15,30 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/models"
 )
 
+const errComponent = "URLDownload"
+
 type Source struct {
 	URL string
+	// SHA256 is the expected hex encoded SHA-256 checksum of the downloaded file. Optional.
+	// Preparing the input fails if the downloaded file does not match it.
+	SHA256 string `structs:",omitempty"`
+	// Headers are sent with the requests for the file. Values can reference secrets
+	// with the form "secret:<backend>/<key>", such as for Authorization headers.
+	Headers map[string]string `structs:",omitempty"`
+	// Mirrors serve the same file as URL, and are tried in order when it cannot be downloaded from URL.
+	// Headers are only sent to the mirrors on the same host as URL.
+	Mirrors []string `structs:",omitempty"`
+	// Extract the downloaded file, which must be a tar, gzip or zstd compressed tar, or zip archive.
+	// The input is then the directory of the extracted files instead of the file.
+	Extract bool `structs:",omitempty"`
+	// ExtractedSize is the maximum total size in bytes of the extracted files, which is reserved on
+	// the disk of the node. Defaults to defaultExtractRatio times the size of the archive.
+	ExtractedSize uint64 `structs:",omitempty"`
 }
 
+// defaultExtractRatio is the compression ratio assumed for archives without an ExtractedSize
+const defaultExtractRatio = 10
+
 func (c Source) Validate() error {
 	if c.URL == "" {
 		return errors.New("invalid url storage params: url cannot be empty")
@@ -21,6 +46,28 @@ func (c Source) Validate() error {
 	if _, err := IsURLSupported(c.URL); err != nil {
 		return fmt.Errorf("invalid url storage params: %w", err)
 	}
+	for _, mirror := range c.Mirrors {
+		if _, err := IsURLSupported(mirror); err != nil {
+			return fmt.Errorf("invalid url storage params: invalid mirror %q: %w", mirror, err)
+		}
+	}
+	if c.SHA256 != "" {
+		if checksum, err := hex.DecodeString(c.SHA256); err != nil || len(checksum) != 32 {
+			return fmt.Errorf("invalid url storage params: sha256 must be 64 hex characters, got %q", c.SHA256)
+		}
+	}
+	if c.ExtractedSize > 0 && !c.Extract {
+		return errors.New("invalid url storage params: extracted size can only be set when extracting")
+	}
+	for name := range c.Headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return fmt.Errorf("invalid url storage params: invalid header name %q", name)
+		}
+		switch textproto.CanonicalMIMEHeaderKey(name) {
+		case "Range", "Host", "Content-Length":
+			return fmt.Errorf("invalid url storage params: header %s cannot be set", name)
+		}
+	}
 	return nil
 }
 
@@ -28,6 +75,36 @@ func (c Source) ToMap() map[string]interface{} {
 	return structs.Map(c)
 }
 
+// urls returns the URL of the file followed by its mirrors
+func (c Source) urls() []string {
+	return append([]string{c.URL}, c.Mirrors...)
+}
+
+// headersFor returns the headers to send to rawURL. Headers can hold credentials for URL,
+// so they are not sent to mirrors on other hosts.
+func (c Source) headersFor(rawURL string, headers http.Header) http.Header {
+	if rawURL == c.URL {
+		return headers
+	}
+	primary, err := url.Parse(c.URL)
+	if err != nil {
+		return nil
+	}
+	mirror, err := url.Parse(rawURL)
+	if err != nil || !strings.EqualFold(mirror.Host, primary.Host) {
+		return nil
+	}
+	return headers
+}
+
+// maxExtractedSize returns the maximum total size of the files extracted from an archive of archiveSize bytes
+func (c Source) maxExtractedSize(archiveSize uint64) uint64 {
+	if c.ExtractedSize > 0 {
+		return c.ExtractedSize
+	}
+	return archiveSize * defaultExtractRatio
+}
+
 func DecodeSpec(spec *models.SpecConfig) (Source, error) {
 	if !spec.IsType(models.StorageSourceURL) {
 		return Source{}, errors.New("invalid storage source type. expected " + models.StorageSourceURL + ", but received: " + spec.Type)
@@ -46,7 +123,11 @@ func DecodeSpec(spec *models.SpecConfig) (Source, error) {
 }
 
 func NewSpecConfig(url string) (*models.SpecConfig, error) {
-	s := Source{URL: url}
+	return NewSpecConfigFromSource(Source{URL: url})
+}
+
+// NewSpecConfigFromSource creates the spec of a URL input from its parameters
+func NewSpecConfigFromSource(s Source) (*models.SpecConfig, error) {
 	if err := s.Validate(); err != nil {
 		return nil, fmt.Errorf("creating %s storage spec: %w", models.StorageSourceURL, err)
 	}
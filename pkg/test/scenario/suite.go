@@ -156,13 +156,14 @@ func (s *ScenarioRunner) RunScenario(scenario Scenario) string {
 		downloaderSettings := &downloader.DownloaderSettings{
 			Timeout:   time.Second * 10,
 			OutputDir: resultsDir,
+			Verify:    true,
 		}
 
 		downloaderProvider := provider.NewMappedProvider(map[string]downloader.Downloader{
 			models.StorageSourceURL: http.NewHTTPDownloader(),
 		})
 
-		err = downloader.DownloadResults(s.Ctx, results.Items, downloaderProvider, downloaderSettings)
+		err = downloader.DownloadResults(s.Ctx, results.Items, results.Origins, downloaderProvider, downloaderSettings)
 		s.Require().NoError(err)
 
 		err = scenario.ResultsChecker(resultsDir)
@@ -1,11 +1,13 @@
 package compute
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/benbjohnson/clock"
 
+	"github.com/bacalhau-project/bacalhau/pkg/compute/execstream"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/logstream"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/backoff"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/envelope"
@@ -40,6 +42,9 @@ type Config struct {
 	EventStore              watcher.EventStore
 	DispatcherConfig        dispatcher.Config
 	LogStreamServer         logstream.Server
+	ExecServer              execstream.Server // Optional. Serves interactive sessions into executions.
+	ImageWarmer             ImageWarmer       // Optional. Pulls the images hinted by the orchestrator.
+	VolumeDeleter           VolumeDeleter     // Optional. Deletes the volumes requested by the orchestrator.
 
 	// Checkpoint config
 	Checkpointer       nclprotocol.Checkpointer
@@ -48,6 +53,20 @@ type Config struct {
 	Clock clock.Clock
 }
 
+// ImageWarmer pulls images ahead of time, such as the images of recently popular jobs
+// hinted by the orchestrator in heartbeat responses
+type ImageWarmer interface {
+	// WarmImages pulls the images in the background without blocking
+	WarmImages(ctx context.Context, images []string)
+}
+
+// VolumeDeleter deletes the persistent volumes of jobs that the orchestrator requests
+// in heartbeat responses, such as the volumes of stopped jobs
+type VolumeDeleter interface {
+	// DeleteVolumes deletes the referenced volumes, skipping volumes in use by running executions
+	DeleteVolumes(ctx context.Context, refs []models.VolumeRef)
+}
+
 // Validate checks if the config is valid
 func (c *Config) Validate() error {
 	return errors.Join(
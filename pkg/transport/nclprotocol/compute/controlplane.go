@@ -156,6 +156,13 @@ func (cp *ControlPlane) heartbeat(ctx context.Context) error {
 	}
 
 	cp.healthTracker.HeartbeatSuccess()
+	heartbeatResponse := payload.(messages.HeartbeatResponse)
+	if cp.cfg.ImageWarmer != nil && len(heartbeatResponse.ImageHints) > 0 {
+		cp.cfg.ImageWarmer.WarmImages(ctx, heartbeatResponse.ImageHints)
+	}
+	if cp.cfg.VolumeDeleter != nil && len(heartbeatResponse.DeleteVolumes) > 0 {
+		cp.cfg.VolumeDeleter.DeleteVolumes(ctx, heartbeatResponse.DeleteVolumes)
+	}
 	return nil
 }
 
@@ -180,6 +180,48 @@ func (s *ControlPlaneTestSuite) TestHeartbeat() {
 	}, 100*time.Millisecond, 10*time.Millisecond, "Heartbeat did not succeed")
 }
 
+// imageWarmer records the images it is asked to warm
+type imageWarmer struct {
+	images chan []string
+}
+
+func (w *imageWarmer) WarmImages(_ context.Context, images []string) {
+	select {
+	case w.images <- images:
+	default:
+	}
+}
+
+func (s *ControlPlaneTestSuite) TestHeartbeatWarmsHintedImages() {
+	warmer := &imageWarmer{images: make(chan []string, 1)}
+	config := s.config
+	config.NodeInfoUpdateInterval = time.Hour
+	config.CheckpointInterval = time.Hour
+	config.ImageWarmer = warmer
+	controlPlane, err := nclprotocolcompute.NewControlPlane(nclprotocolcompute.ControlPlaneParams{
+		Config:             config,
+		Requester:          s.requester,
+		HealthTracker:      s.healthTracker,
+		IncomingSeqTracker: s.seqTracker,
+		CheckpointName:     "test-checkpoint",
+	})
+	s.Require().NoError(err)
+	defer s.Require().NoError(controlPlane.Stop(s.ctx))
+
+	s.requester.EXPECT().
+		Request(gomock.Any(), gomock.Any()).
+		Return(envelope.NewMessage(messages.HeartbeatResponse{ImageHints: []string{"ubuntu:24.04"}}), nil).
+		MinTimes(1)
+
+	s.Require().NoError(controlPlane.Start(s.ctx))
+	select {
+	case images := <-warmer.images:
+		s.Equal([]string{"ubuntu:24.04"}, images)
+	case <-time.After(time.Second):
+		s.Fail("hinted images were not warmed")
+	}
+}
+
 func (s *ControlPlaneTestSuite) TestHeartbeatFailFastOnHandshakeRequired() {
 	// Create control plane with only heartbeat enabled and short intervals
 	controlPlane := s.createControlPlane(
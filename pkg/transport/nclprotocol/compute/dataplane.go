@@ -23,6 +23,7 @@ const watcherID = "compute-ncl-dispatcher"
 // DataPlane manages the data transfer operations between a compute node and the orchestrator.
 // It is responsible for:
 // - Setting up and managing the log streaming server
+// - Serving interactive sessions into running executions
 // - Reliable message publishing through ordered publisher
 // - Event watching and dispatching
 // - Maintaining message sequence ordering
@@ -107,6 +108,19 @@ func (dp *DataPlane) Start(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to set up log stream handler: %w", err)
 	}
+
+	// Set up interactive sessions into running executions
+	if dp.config.ExecServer != nil {
+		_, err = proxy.NewExecHandler(ctx, proxy.ExecHandlerParams{
+			Name:       dp.config.NodeID,
+			Conn:       dp.Client,
+			ExecServer: dp.config.ExecServer,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set up exec handler: %w", err)
+		}
+	}
+
 	// Initialize ordered publisher for reliable message delivery
 	dp.Publisher, err = ncl.NewOrderedPublisher(dp.Client, ncl.OrderedPublisherConfig{
 		Name:              dp.config.NodeID,
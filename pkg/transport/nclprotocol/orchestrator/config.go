@@ -1,6 +1,7 @@
 package orchestrator
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/lib/ncl"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/validate"
 	"github.com/bacalhau-project/bacalhau/pkg/lib/watcher"
+	"github.com/bacalhau-project/bacalhau/pkg/models"
 	natsutil "github.com/bacalhau-project/bacalhau/pkg/nats"
 	"github.com/bacalhau-project/bacalhau/pkg/orchestrator/nodes"
 	"github.com/bacalhau-project/bacalhau/pkg/transport/nclprotocol"
@@ -41,6 +43,24 @@ type Config struct {
 	DataPlaneMessageCreatorFactory nclprotocol.MessageCreatorFactory // Creates message creators for outgoing messages
 	EventStore                     watcher.EventStore                // Store for watching and dispatching events
 	DispatcherConfig               dispatcher.Config                 // Configuration for the event dispatcher
+
+	// ImageHintProvider provides the images hinted to compute nodes in heartbeat responses. Optional.
+	ImageHintProvider ImageHintProvider
+
+	// VolumeDeletionProvider provides the volumes compute nodes should delete in heartbeat responses. Optional.
+	VolumeDeletionProvider VolumeDeletionProvider
+}
+
+// ImageHintProvider provides the images, such as the images of recently popular jobs,
+// that compute nodes can pull ahead of time
+type ImageHintProvider interface {
+	ImageHints() []string
+}
+
+// VolumeDeletionProvider provides the persistent volumes advertised by a compute node that it
+// should delete, such as the volumes of stopped jobs
+type VolumeDeletionProvider interface {
+	VolumeDeletions(ctx context.Context, nodeInfo models.NodeInfo) []models.VolumeRef
 }
 
 // Validate checks if the configuration is valid by verifying:
@@ -292,6 +292,14 @@ func (cm *ComputeManager) handleHeartbeatRequest(ctx context.Context, msg *envel
 	if err != nil {
 		return nil, err
 	}
+	if cm.config.ImageHintProvider != nil {
+		response.ImageHints = cm.config.ImageHintProvider.ImageHints()
+	}
+	if cm.config.VolumeDeletionProvider != nil {
+		if state, err := cm.nodeManager.Get(ctx, request.NodeID); err == nil {
+			response.DeleteVolumes = cm.config.VolumeDeletionProvider.VolumeDeletions(ctx, state.Info)
+		}
+	}
 
 	return envelope.NewMessage(response).WithMetadataValue(envelope.KeyMessageType, messages.HeartbeatResponseType), nil
 }
@@ -17,6 +17,7 @@ func CreateMessageRegistry() (*envelope.Registry, error) {
 		reg.Register(messages.CancelExecutionMessageType, messages.CancelExecutionRequest{}),
 		reg.Register(messages.BidResultMessageType, messages.BidResult{}),
 		reg.Register(messages.RunResultMessageType, messages.RunResult{}),
+		reg.Register(messages.CheckpointMessageType, messages.CheckpointResult{}),
 		reg.Register(messages.ComputeErrorMessageType, messages.ComputeError{}),
 
 		// Control plane messages
@@ -34,6 +35,8 @@ func CreateMessageRegistry() (*envelope.Registry, error) {
 		// Managed publisher messages
 		reg.Register(messages.ManagedPublisherPreSignURLRequestType, messages.ManagedPublisherPreSignURLRequest{}),
 		reg.Register(messages.ManagedPublisherPreSignURLResponseType, messages.ManagedPublisherPreSignURLResponse{}),
+		reg.Register(messages.ManagedPublisherMultipartRequestType, messages.ManagedPublisherMultipartRequest{}),
+		reg.Register(messages.ManagedPublisherMultipartResponseType, messages.ManagedPublisherMultipartResponse{}),
 	)
 	return reg, err
 }
@@ -0,0 +1,60 @@
+use std::collections::HashMap;
+use std::env;
+use std::fs;
+use std::io;
+use std::io::Write;
+use std::process;
+use std::time::SystemTime;
+
+// Exercises the WASI 0.2 interfaces the standard library uses, with one command per invocation:
+//
+//   echo ARGS...       prints the arguments
+//   env NAME           prints the value of the environment variable
+//   cat PATH           prints the contents of the file
+//   write PATH TEXT    writes the text to the file
+//   ls PATH            prints the sorted names of the entries of the directory
+//   time               prints ok if the wall clock is after 2020
+//   random             prints ok once the random seed of a hash map was generated
+//   exit CODE          prints to stderr and exits with the code
+fn run(args: &[String]) -> Result<(), Box<dyn std::error::Error>> {
+    let mut stdout = io::stdout();
+    match args.first().map(String::as_str) {
+        Some("echo") => writeln!(stdout, "{}", args[1..].join(" "))?,
+        Some("env") => writeln!(stdout, "{}", env::var(&args[1])?)?,
+        Some("cat") => stdout.write_all(&fs::read(&args[1])?)?,
+        Some("write") => fs::write(&args[1], &args[2])?,
+        Some("ls") => {
+            let mut names = fs::read_dir(&args[1])?
+                .map(|entry| entry.map(|e| e.file_name().to_string_lossy().into_owned()))
+                .collect::<Result<Vec<_>, _>>()?;
+            names.sort();
+            writeln!(stdout, "{}", names.join(" "))?
+        }
+        Some("time") => {
+            let since_epoch = SystemTime::now().duration_since(SystemTime::UNIX_EPOCH)?;
+            if since_epoch.as_secs() < 1_577_836_800 {
+                return Err("the wall clock is before 2020".into());
+            }
+            writeln!(stdout, "ok")?
+        }
+        Some("random") => {
+            let mut values = HashMap::new();
+            values.insert("key", "value");
+            writeln!(stdout, "ok")?
+        }
+        Some("exit") => {
+            eprintln!("exiting");
+            process::exit(args[1].parse()?);
+        }
+        _ => return Err("unknown command".into()),
+    }
+    Ok(())
+}
+
+fn main() {
+    let args: Vec<String> = env::args().skip(1).collect();
+    if let Err(err) = run(&args) {
+        eprintln!("{}", err);
+        process::exit(1);
+    }
+}